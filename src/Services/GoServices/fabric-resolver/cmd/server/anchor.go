@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"fabric-resolver/internal/domain"
+)
+
+// runAnchor runs `anchor get <hash>`, printing the anchor as JSON.
+func runAnchor(ctx context.Context, args []string, stdout io.Writer) error {
+	if len(args) == 0 || args[0] != "get" {
+		return usageError("anchor needs a subcommand: get")
+	}
+
+	fs, settings := newFlagSet("anchor get")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError(fmt.Sprintf("anchor get takes a hash, got %d arguments", fs.NArg()))
+	}
+	hash := domain.NormalizeHash(fs.Arg(0))
+
+	client, _, err := openLedger(settings)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	anchor, err := client.GetAnchor(ctx, hash)
+	if err != nil {
+		return fmt.Errorf("anchor %s: %w", hash, err)
+	}
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(anchor)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+func TestAnchorGet(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	path := newFileLedger(t, hash)
+
+	var out strings.Builder
+	if err := runAnchor(t.Context(), []string{"get", "-ledger-file", path, strings.ToUpper(hash)}, &out); err != nil {
+		t.Fatalf("anchor get failed: %v", err)
+	}
+	var anchor domain.Anchor
+	if err := json.Unmarshal([]byte(out.String()), &anchor); err != nil {
+		t.Fatalf("expected the anchor as JSON, got %q", out.String())
+	}
+	if anchor.Hash != hash || anchor.BlockNumber != 1 || anchor.TxID == "" {
+		t.Errorf("expected the stored anchor, got %+v", anchor)
+	}
+
+	err := runAnchor(t.Context(), []string{"get", "-ledger-file", path, strings.Repeat("cd", 32)}, &out)
+	if !errors.Is(err, fabric.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown hash, got %v", err)
+	}
+
+	var usage usageError
+	for _, args := range [][]string{{}, {"list"}, {"get"}} {
+		if err := runAnchor(t.Context(), args, &out); !errors.As(err, &usage) {
+			t.Errorf("anchor %v: expected a usage error, got %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"fabric-resolver/internal/apikeys"
+	"fabric-resolver/internal/commitments"
+	"fabric-resolver/internal/health"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/jwtauth"
+	"fabric-resolver/internal/pkg/receipt"
+)
+
+// checkTimeout bounds each check of --check, generous enough for a Fabric gateway
+// or identity provider that is slow to answer the first request.
+const checkTimeout = 10 * time.Second
+
+// runCheck verifies a deployment without serving it: the configuration loads, the
+// TLS and auth material it names can be read, and the configured ledger passes the
+// readiness probe. It prints a line per check to out and reports whether all passed.
+func runCheck(ctx context.Context, settings *settingFlags, out io.Writer) bool {
+	cfg, err := settings.load()
+	if err != nil {
+		fmt.Fprintf(out, "FAIL  config: %v\n", err)
+		return false
+	}
+	fmt.Fprintln(out, "ok    config")
+
+	checks := []health.Check{
+		{Name: "tls", Probe: func(context.Context) error {
+			_, err := cfg.Server.TLSConfig()
+			return err
+		}},
+		{Name: "api keys", Probe: func(context.Context) error {
+			_, err := apikeys.Load(cfg.Server.APIKeysFile, cfg.Server.APIKeys)
+			return err
+		}},
+		{Name: "commitment keys", Probe: func(context.Context) error {
+			_, err := commitments.Load(cfg.Server.CommitmentKeysFile, cfg.Server.CommitmentKeys)
+			return err
+		}},
+		{Name: "receipt key", Probe: func(context.Context) error {
+			// A missing key is generated at startup, next to the ledger checked below
+			if _, err := os.Stat(cfg.Server.ReceiptKeyPath); errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			_, err := receipt.LoadOrCreateKey(cfg.Server.ReceiptKeyPath)
+			return err
+		}},
+	}
+	if cfg.Server.JWTJWKSURL != "" {
+		jwks := jwtauth.NewJWKS(cfg.Server.JWTJWKSURL, &http.Client{}, cfg.Server.JWTJWKSRefresh)
+		checks = append(checks, health.Check{Name: "jwks", Probe: func(ctx context.Context) error {
+			// No key has an empty id, so a fetched and parsed JWKS answers ErrUnknownKey
+			if _, err := jwks.Key(ctx, ""); err != nil && !errors.Is(err, jwtauth.ErrUnknownKey) {
+				return err
+			}
+			return nil
+		}})
+	}
+
+	cfg.Ledger.Logger = slog.Default()
+	ledgerClient, err := fabric.NewLedgerClient(cfg.Ledger)
+	if err != nil {
+		checks = append(checks, health.Check{Name: "ledger", Probe: func(context.Context) error { return err }})
+	} else {
+		defer ledgerClient.Close()
+		checks = append(checks, health.Ledger(ledgerClient))
+	}
+
+	results := health.Run(ctx, checkTimeout, checks...)
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(out, "FAIL  %s: %v\n", result.Name, result.Err)
+		} else {
+			fmt.Fprintf(out, "ok    %s (%s)\n", result.Name, result.Duration.Round(time.Millisecond))
+		}
+	}
+	if failed := health.Failed(results); len(failed) > 0 {
+		fmt.Fprintf(out, "%d of %d checks failed\n", len(failed), len(results)+1)
+		return false
+	}
+	fmt.Fprintf(out, "all %d checks passed\n", len(results)+1)
+	return true
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// makeUnwritable denies writes to dir until the test ends. Root ignores
+// permission bits, so there the directory is swapped for a regular file.
+func makeUnwritable(t *testing.T, dir string) {
+	t.Helper()
+	if os.Geteuid() != 0 {
+		if err := os.Chmod(dir, 0o500); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.Chmod(dir, 0o700) })
+		return
+	}
+	moved := dir + ".moved"
+	if err := os.Rename(dir, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Remove(dir)
+		os.Rename(moved, dir)
+	})
+}
+
+// checkEnv points the ledger, and the files kept next to it, at dir.
+func checkEnv(t *testing.T, dir string) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("LEDGER_MODE", "file")
+	t.Setenv("LEDGER_FILE_PATH", filepath.Join(dir, "ledger.json"))
+}
+
+func TestCheck_PassesWithValidConfig(t *testing.T) {
+	dir := t.TempDir()
+	checkEnv(t, dir)
+
+	var out strings.Builder
+	if !runCheck(t.Context(), &settingFlags{}, &out) {
+		t.Fatalf("expected the check to pass:\n%s", out.String())
+	}
+	for _, line := range []string{"ok    config", "ok    tls", "ok    api keys", "ok    ledger", "all 6 checks passed"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected %q in the summary:\n%s", line, out.String())
+		}
+	}
+
+	// The probe cleans up after itself, and nothing is served or generated
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".ping-") || entry.Name() == "receipt-key.pem" {
+			t.Errorf("expected the check to leave no %s behind", entry.Name())
+		}
+	}
+}
+
+func TestCheck_FailsOnReadOnlyLedgerDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	checkEnv(t, dir)
+	makeUnwritable(t, dir)
+
+	var out strings.Builder
+	if runCheck(t.Context(), &settingFlags{}, &out) {
+		t.Fatalf("expected the check to fail:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "FAIL  ledger: ") || !strings.Contains(out.String(), "ok    api keys") {
+		t.Errorf("expected the ledger named as failing:\n%s", out.String())
+	}
+}
+
+func TestCheck_ReportsBadSettings(t *testing.T) {
+	for name, tc := range map[string]struct {
+		env  map[string]string
+		want string
+	}{
+		"malformed config": {map[string]string{"SERVER_PORT": "eighty"}, "FAIL  config: invalid SERVER_PORT"},
+		"short API key":    {map[string]string{"API_KEYS": "tooshort"}, "FAIL  api keys: "},
+		"missing JWKS":     {map[string]string{"JWT_JWKS_URL": "http://127.0.0.1:1/jwks", "JWT_ISSUER": "https://idp.example.com", "JWT_AUDIENCE": "fabric-resolver"}, "FAIL  jwks: "},
+	} {
+		t.Run(name, func(t *testing.T) {
+			checkEnv(t, t.TempDir())
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			var out strings.Builder
+			if runCheck(t.Context(), &settingFlags{}, &out) {
+				t.Fatalf("expected the check to fail:\n%s", out.String())
+			}
+			if !strings.Contains(out.String(), tc.want) {
+				t.Errorf("expected %q in the summary:\n%s", tc.want, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+// runLedger runs `ledger export|import|verify` against the configured ledger,
+// which must be a file ledger.
+func runLedger(ctx context.Context, args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return usageError("ledger needs a subcommand: export, import or verify")
+	}
+	command, args := args[0], args[1:]
+
+	fs, settings := newFlagSet("ledger " + command)
+	var replace bool
+	var wantArgs int
+	switch command {
+	case "export":
+		wantArgs = 1
+	case "import":
+		wantArgs = 1
+		fs.BoolVar(&replace, "replace", false, "discard the ledger's records instead of requiring an empty ledger")
+	case "verify":
+	default:
+		return usageError(fmt.Sprintf("unknown ledger subcommand %q", command))
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != wantArgs {
+		return usageError(fmt.Sprintf("ledger %s takes %d file arguments, got %d", command, wantArgs, fs.NArg()))
+	}
+
+	client, cfg, err := openLedger(settings)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	snapshots, ok := fabric.SnapshotterOf(client)
+	if !ok {
+		return fmt.Errorf("ledger %s needs a file ledger, not LEDGER_MODE=%s", command, cfg.Ledger.Mode)
+	}
+
+	switch command {
+	case "export":
+		return exportLedger(ctx, snapshots, fs.Arg(0), stdout)
+	case "import":
+		return importLedger(ctx, snapshots, fs.Arg(0), replace, stdout)
+	default:
+		if err := snapshots.Verify(ctx); err != nil {
+			return fmt.Errorf("ledger %s is inconsistent:\n%w", cfg.Ledger.FilePath, err)
+		}
+		fmt.Fprintf(stdout, "ledger %s is consistent\n", cfg.Ledger.FilePath)
+		return nil
+	}
+}
+
+// exportLedger writes the ledger to path, or stdout for "-". A failed export
+// removes the partial file.
+func exportLedger(ctx context.Context, snapshots fabric.Snapshotter, path string, stdout io.Writer) error {
+	if path == "-" {
+		_, err := snapshots.Export(ctx, stdout)
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+	n, err := snapshots.Export(ctx, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	fmt.Fprintf(stdout, "exported %d records to %s\n", n, path)
+	return nil
+}
+
+// importLedger loads the export at path, or stdin for "-", into the ledger.
+func importLedger(ctx context.Context, snapshots fabric.Snapshotter, path string, replace bool, stdout io.Writer) error {
+	f, err := openFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to open export: %w", err)
+	}
+	defer f.Close()
+
+	n, err := snapshots.Import(ctx, f, replace)
+	if err != nil {
+		return fmt.Errorf("failed to import %s: %w", path, err)
+	}
+	fmt.Fprintf(stdout, "imported %d records from %s\n", n, path)
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+// newFileLedger creates a file ledger in a temp dir holding anchors of hashes and
+// returns its path, with the environment pointed at another, unused ledger.
+func newFileLedger(t *testing.T, hashes ...string) string {
+	t.Helper()
+	checkEnv(t, t.TempDir())
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	client, err := fabric.NewFileLedgerClient(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	for _, hash := range hashes {
+		if _, _, err := client.CreateAnchor(t.Context(), &domain.Anchor{Hash: hash}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func runLedgerArgs(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out strings.Builder
+	err := runLedger(t.Context(), args, &out)
+	return out.String(), err
+}
+
+func TestLedgerExportImport(t *testing.T) {
+	source := newFileLedger(t, strings.Repeat("a", 64), strings.Repeat("b", 64))
+	export := filepath.Join(t.TempDir(), "export.json")
+
+	out, err := runLedgerArgs(t, "export", "-ledger-file", source, export)
+	if err != nil || !strings.Contains(out, "exported 2 records") {
+		t.Fatalf("export: got %q (%v)", out, err)
+	}
+	if _, err := runLedgerArgs(t, "export", "-ledger-file", source, export); err == nil {
+		t.Error("expected an existing export not to be overwritten")
+	}
+
+	target := filepath.Join(t.TempDir(), "ledger.json")
+	out, err = runLedgerArgs(t, "import", "-ledger-file", target, export)
+	if err != nil || !strings.Contains(out, "imported 2 records") {
+		t.Fatalf("import: got %q (%v)", out, err)
+	}
+	if out, err := runLedgerArgs(t, "verify", "-ledger-file", target); err != nil || !strings.Contains(out, "is consistent") {
+		t.Errorf("verify: got %q (%v)", out, err)
+	}
+
+	// A ledger with records is only overwritten on request
+	if _, err := runLedgerArgs(t, "import", "-ledger-file", target, export); !errors.Is(err, fabric.ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists importing into a non-empty ledger, got %v", err)
+	}
+	if _, err := runLedgerArgs(t, "import", "-replace", "-ledger-file", target, export); err != nil {
+		t.Errorf("expected -replace to overwrite the ledger, got %v", err)
+	}
+
+	client, err := fabric.NewFileLedgerClient(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if _, err := client.GetAnchor(t.Context(), strings.Repeat("b", 64)); err != nil {
+		t.Errorf("expected the imported anchor in the target ledger: %v", err)
+	}
+}
+
+func TestLedger_FlagsOverrideEnvironment(t *testing.T) {
+	source := newFileLedger(t, strings.Repeat("c", 64))
+	envLedger := os.Getenv("LEDGER_FILE_PATH")
+
+	out, err := runLedgerArgs(t, "export", "-ledger-file", source, "-")
+	if err != nil || !strings.Contains(out, strings.Repeat("c", 64)) {
+		t.Fatalf("expected the flag's ledger on stdout, got %q (%v)", out, err)
+	}
+	if _, err := os.Stat(envLedger); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the environment's ledger to be left alone, got %v", err)
+	}
+}
+
+func TestLedgerVerify_ReportsInconsistencies(t *testing.T) {
+	path := newFileLedger(t)
+	broken := `{"version":1,"nextBlock":2,"records":{"a":{"commitment":"a","docType":"anchor","blockNumber":5,"txId":"t1"}}}`
+	if err := os.WriteFile(path, []byte(broken), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := runLedgerArgs(t, "verify", "-ledger-file", path)
+	if err == nil || !strings.Contains(err.Error(), "is inconsistent") || !strings.Contains(err.Error(), `record "a": block 5`) {
+		t.Errorf("expected the out-of-range block to be reported, got %v", err)
+	}
+}
+
+func TestLedger_UsageErrors(t *testing.T) {
+	checkEnv(t, t.TempDir())
+	for _, args := range [][]string{
+		{},
+		{"compact"},
+		{"export"},
+		{"import", "a.json", "b.json"},
+		{"verify", "extra"},
+	} {
+		var usage usageError
+		if _, err := runLedgerArgs(t, args...); !errors.As(err, &usage) {
+			t.Errorf("ledger %v: expected a usage error, got %v", args, err)
+		}
+	}
+}
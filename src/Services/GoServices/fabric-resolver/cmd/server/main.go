@@ -1,67 +1,197 @@
+// The resolver binary serves the API, and works on the configured ledger directly
+// for operational tasks that need no running server:
+//
+//	fabric-resolver [serve] [-check] [flags]
+//	fabric-resolver ledger export [flags] <file>
+//	fabric-resolver ledger import [-replace] [flags] <file>
+//	fabric-resolver ledger verify [flags]
+//	fabric-resolver anchor get [flags] <hash>
+//
+// Every subcommand reads the configuration as the server does; its flags override
+// the environment and the config file. A file of "-" is stdout or stdin.
+//
+// The server exits 0 once SIGINT or SIGTERM has shut it down, 3 on an invalid
+// configuration, 4 when the ledger cannot be opened, 5 when a port or socket cannot be bound
+// and 1 when serving fails; a wrong command line exits 2.
 package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
-	"net/http"
+	"io"
+	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
-	"time"
 
-	"fabric-resolver/internal/api"
 	"fabric-resolver/internal/config"
 	"fabric-resolver/internal/infrastructure/fabric"
 )
 
+const usage = `Usage:
+  fabric-resolver [serve] [-check] [flags]      serve the API (the default)
+  fabric-resolver ledger export [flags] <file>  write every ledger record to file
+  fabric-resolver ledger import [flags] <file>  load an exported ledger (-replace to overwrite)
+  fabric-resolver ledger verify [flags]         check the ledger's records are consistent
+  fabric-resolver anchor get [flags] <hash>     print an anchor
+
+Run a subcommand with -h for its flags. Stop the server before importing into its ledger.
+`
+
+// Exit codes, so an orchestrator can tell a failure that retrying cannot fix
+// from one that may pass on the next start.
+const (
+	exitFailure  = 1 // serving failed, a -check failed or a subcommand failed
+	exitUsage    = 2 // the command line is wrong
+	exitConfig   = 3 // the configuration, or a file it names, is invalid; do not retry
+	exitLedger   = 4 // the ledger could not be opened; it may be locked or unreachable for now
+	exitListener = 5 // a port or socket could not be bound
+)
+
 func main() {
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+	// JSON logs for the log pipeline; the level is raised or lowered once LOG_LEVEL is read
+	var logLevel slog.LevelVar
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: &logLevel}))
+	slog.SetDefault(logger)
+
+	// SIGINT and SIGTERM shut the server down gracefully, which exits 0
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	err := run(ctx, os.Args[1:], &logLevel, logger, os.Stdout)
+	stop()
+
+	var usageErr usageError
+	var exitErr *exitError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(os.Stderr, "%s\n\n%s", err, usage)
+		os.Exit(exitUsage)
+	case errors.As(err, &exitErr):
+		if exitErr.err != nil {
+			slog.Error(exitErr.msg, "err", exitErr.err, "exit_code", exitErr.code)
+		}
+		os.Exit(exitErr.code)
+	default:
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitFailure)
+	}
+}
+
+// run runs the subcommand named by args until it completes, or for serve until
+// ctx is cancelled. Server failures are returned as *exitError.
+func run(ctx context.Context, args []string, logLevel *slog.LevelVar, logger *slog.Logger, stdout io.Writer) error {
+	// Without a subcommand the binary serves, as it always has
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	if command == "serve" {
+		return serve(ctx, args, logLevel, logger, stdout)
 	}
 
-	// Initialize Ledger client
-	ledgerConfig := fabric.LoadConfigFromEnv()
-	ledgerClient, err := fabric.NewLedgerClient(ledgerConfig)
-	if err != nil {
-		log.Fatalf("Failed to initialize Ledger client: %v", err)
+	// The other subcommands print their results; only problems are logged alongside
+	logLevel.Set(slog.LevelWarn)
+	switch command {
+	case "ledger":
+		return runLedger(ctx, args, stdout)
+	case "anchor":
+		return runAnchor(ctx, args, stdout)
+	case "help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		return usageError(fmt.Sprintf("unknown command %q", command))
 	}
-	defer ledgerClient.Close()
+}
 
-	// Setup HTTP server
-	router := api.NewRouter(ledgerClient)
+// usageError is a command line that names no command or lacks its arguments.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// exitError is a failure of the server, logged as msg and exiting with code.
+// A nil err has been reported already and exits silently.
+type exitError struct {
+	code int
+	msg  string
+	err  error
+}
 
-	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
-		Handler:      router,
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
-		IdleTimeout:  cfg.Server.IdleTimeout,
+func exitWith(code int, msg string, err error) *exitError {
+	return &exitError{code: code, msg: msg, err: err}
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
 	}
+	return e.msg + ": " + e.err.Error()
+}
 
-	// Start server in goroutine
-	go func() {
-		log.Printf("Starting Fabric Resolver on port %d", cfg.Server.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed: %v", err)
-		}
-	}()
+func (e *exitError) Unwrap() error {
+	return e.err
+}
 
-	// Graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+// settingFlags are the flags every subcommand shares: the config file, and flags
+// overriding the setting of an environment variable.
+type settingFlags struct {
+	configFile string
+	overrides  map[string]*string
+}
 
-	log.Println("Shutting down server...")
+// newFlagSet returns the flags of subcommand name, with the config file and ledger
+// settings bound. Parse errors are returned rather than exiting.
+func newFlagSet(name string) (*flag.FlagSet, *settingFlags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	settings := &settingFlags{}
+	fs.StringVar(&settings.configFile, "config", os.Getenv("CONFIG_FILE"), "YAML or JSON config file")
+	settings.bind(fs, "ledger-mode", "LEDGER_MODE", "ledger backend, file or fabric")
+	settings.bind(fs, "ledger-file", "LEDGER_FILE_PATH", "file ledger path")
+	return fs, settings
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+// bind adds flag name overriding the environment variable key.
+func (s *settingFlags) bind(fs *flag.FlagSet, name, key, usage string) {
+	if s.overrides == nil {
+		s.overrides = make(map[string]*string)
+	}
+	s.overrides[key] = fs.String(name, "", usage+"; overrides "+key)
+}
 
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+// load reads the configuration with the flags that were set applied.
+func (s *settingFlags) load() (*config.Config, error) {
+	overrides := make(map[string]string, len(s.overrides))
+	for key, value := range s.overrides {
+		overrides[key] = *value
 	}
+	return config.LoadWithOverrides(s.configFile, overrides)
+}
+
+// openLedger connects to the ledger of the configuration loaded by settings, for
+// a subcommand. Expired anchors are left for the server to prune.
+func openLedger(settings *settingFlags) (fabric.LedgerClient, *config.Config, error) {
+	cfg, err := settings.load()
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg.Ledger.Logger = slog.Default()
+	cfg.Ledger.ReapInterval = 0
+	client, err := fabric.NewLedgerClient(cfg.Ledger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open ledger: %w", err)
+	}
+	return client, cfg, nil
+}
 
-	log.Println("Server exited")
+// openFile opens path for a subcommand to read, or stdin for "-".
+func openFile(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
 }
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"fabric-resolver/internal/api"
+	"fabric-resolver/internal/apikeys"
+	"fabric-resolver/internal/audit"
+	"fabric-resolver/internal/buildinfo"
+	"fabric-resolver/internal/commitments"
+	"fabric-resolver/internal/config"
+	"fabric-resolver/internal/grpcapi"
+	"fabric-resolver/internal/idempotency"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/jwtauth"
+	"fabric-resolver/internal/pkg/didweb"
+	"fabric-resolver/internal/pkg/receipt"
+	"fabric-resolver/internal/ratelimit"
+	"fabric-resolver/internal/tracing"
+	"fabric-resolver/internal/webhooks"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+)
+
+// serve runs the HTTP and gRPC servers until ctx is cancelled, then shuts down
+// gracefully and returns nil. Startup failures are classified by exit code.
+func serve(ctx context.Context, args []string, logLevel *slog.LevelVar, logger *slog.Logger, stdout io.Writer) error {
+	fs, settings := newFlagSet("serve")
+	check := fs.Bool("check", false, "check the configuration, TLS and auth material and the ledger, then exit without serving")
+	settings.bind(fs, "port", "SERVER_PORT", "HTTP port")
+	settings.bind(fs, "listen", "SERVER_LISTEN", "HTTP listener, tcp://host:port or unix:///path")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		// The flag package has printed the error and the flags
+		return exitWith(exitUsage, "", nil)
+	}
+	if fs.NArg() > 0 {
+		return usageError(fmt.Sprintf("serve takes no arguments, got %q", fs.Args()))
+	}
+	if *check {
+		if !runCheck(ctx, settings, stdout) {
+			return exitWith(exitFailure, "", nil)
+		}
+		return nil
+	}
+
+	// Flags override environment variables, which override the config file
+	cfg, err := settings.load()
+	if err != nil {
+		return exitWith(exitConfig, "Failed to load configuration", err)
+	}
+	logLevel.Set(cfg.Server.LogLevel)
+	slog.Info("Configuration loaded", "config_file", settings.configFile, "config", cfg.Redacted())
+
+	// Spans are exported over OTLP when OTEL_EXPORTER_OTLP_ENDPOINT is set
+	shutdownTracing, err := tracing.Setup(ctx)
+	if err != nil {
+		return exitWith(exitConfig, "Failed to set up tracing", err)
+	}
+
+	// Everything the configuration names is loaded before the ledger is opened, so a
+	// bad file fails as a configuration error with nothing to release
+	idempotencyStore, err := idempotency.NewStore(cfg.Server.IdempotencyFilePath, cfg.Server.IdempotencyRetention)
+	if err != nil {
+		return exitWith(exitConfig, "Failed to open idempotency store", err)
+	}
+
+	commitmentKeys, err := commitments.Load(cfg.Server.CommitmentKeysFile, cfg.Server.CommitmentKeys)
+	if err != nil {
+		return exitWith(exitConfig, "Failed to load commitment keys", err)
+	}
+	slog.Info("Commitment keys loaded", "tenants", len(commitmentKeys.Tenants()))
+
+	apiKeys, err := apikeys.Load(cfg.Server.APIKeysFile, cfg.Server.APIKeys)
+	if err != nil {
+		return exitWith(exitConfig, "Failed to load API keys", err)
+	}
+	if apiKeys.Len() == 0 {
+		slog.Warn("No API keys configured; write endpoints are open to anyone who can reach the server")
+	} else {
+		slog.Info("API keys loaded", "ids", apiKeys.IDs())
+	}
+
+	var jwtVerifier *jwtauth.Verifier
+	if cfg.Server.JWTJWKSURL != "" {
+		jwks := jwtauth.NewJWKS(cfg.Server.JWTJWKSURL, nil, cfg.Server.JWTJWKSRefresh)
+		jwtVerifier = jwtauth.NewVerifier(jwks, jwtauth.Options{
+			Issuer:   cfg.Server.JWTIssuer,
+			Audience: cfg.Server.JWTAudience,
+			Leeway:   cfg.Server.JWTLeeway,
+		})
+		slog.Info("JWT authentication enabled", "issuer", cfg.Server.JWTIssuer, "jwks_url", cfg.Server.JWTJWKSURL)
+	}
+
+	receiptSigner, err := receipt.LoadOrCreateKey(cfg.Server.ReceiptKeyPath)
+	if err != nil {
+		return exitWith(exitConfig, "Failed to load receipt signing key", err)
+	}
+	slog.Info("Signing anchor receipts", "key_id", receiptSigner.KeyID())
+
+	var auditLog *audit.Log
+	if cfg.Server.AuditLogPath != "" {
+		auditLog, err = audit.Open(cfg.Server.AuditLogPath, audit.Options{MaxBytes: cfg.Server.AuditMaxBytes, MaxFiles: cfg.Server.AuditMaxFiles})
+		if err != nil {
+			return exitWith(exitConfig, "Failed to open audit log", err)
+		}
+		slog.Info("Auditing writes", "path", cfg.Server.AuditLogPath, "strict", cfg.Server.AuditStrict)
+	} else {
+		slog.Warn("Audit log disabled; writes are not recorded")
+	}
+
+	// Read-only mode guards every ledger client, whoever writes, and admins switch it at runtime
+	readOnly := fabric.NewReadOnlySwitch(cfg.Server.ReadOnly)
+	if readOnly.On() {
+		slog.Warn("Starting in read-only mode; writes are refused until it is switched off")
+	}
+
+	// Tenants' ledgers are opened on their first request, over HTTP or gRPC; webhooks are shared
+	cfg.Ledger.Logger = logger
+	cfg.Ledger.ReadOnly = readOnly
+	var tenantLedgers *fabric.TenantLedgerProvider
+	if len(cfg.Server.Tenants) > 0 {
+		tenantLedgers, err = fabric.NewTenantLedgerProvider(cfg.Ledger, cfg.Server.Tenants)
+		if err != nil {
+			return exitWith(exitConfig, "Failed to configure tenant ledgers", err)
+		}
+		slog.Info("Serving tenants", "tenants", tenantLedgers.Tenants(), "api_keys", len(cfg.Server.TenantAPIKeys))
+	}
+
+	// The handler is set once the ledger is open
+	server, err := api.NewServer(&cfg.Server, nil)
+	if err != nil {
+		return exitWith(exitConfig, "Failed to configure TLS", err)
+	}
+
+	// Ports are bound up front, so a port in use is reported as such rather than
+	// once the server is half started
+	listeners, err := listen(cfg)
+	if err != nil {
+		return exitWith(exitListener, "Failed to listen", err)
+	}
+
+	// Initialize Ledger client
+	ledgerClient, err := fabric.OpenLedgerClient(ctx, cfg.Ledger)
+	if err != nil {
+		listeners.close()
+		if ctx.Err() != nil {
+			slog.Info("Shutting down before the ledger was opened")
+			return nil
+		}
+		return exitWith(exitLedger, "Failed to initialize Ledger client", err)
+	}
+	// Ledger metrics are exported on /metrics whichever backend is configured, by
+	// tenant when there are tenants
+	metricsLedger := fabric.NewMetricsLedgerClient(ledgerClient)
+	if tenantLedgers != nil {
+		metricsLedger = fabric.NewTenantMetricsLedgerClient(ledgerClient, "")
+		prometheus.MustRegister(tenantLedgers)
+		defer prometheus.Unregister(tenantLedgers)
+	}
+	prometheus.MustRegister(metricsLedger)
+	defer prometheus.Unregister(metricsLedger)
+	ledgerClient = metricsLedger
+
+	// Webhook deliveries run until shutdown, off the request path
+	dispatcher := webhooks.NewDispatcher(ledgerClient, webhooks.Options{
+		Client:      &http.Client{Timeout: cfg.Server.WebhookTimeout},
+		MaxAttempts: cfg.Server.WebhookMaxAttempts,
+	})
+	dispatchCtx, stopDispatch := context.WithCancel(context.Background())
+	if tenantLedgers != nil {
+		tenantLedgers.OnOpen(func(tenant string, client fabric.LedgerClient) {
+			if err := dispatcher.Watch(dispatchCtx, client, tenant); err != nil {
+				slog.Error("Failed to watch tenant ledger for webhooks", "tenant", tenant, "err", err)
+			}
+		})
+	}
+	dispatchDone := make(chan struct{})
+	go func() {
+		defer close(dispatchDone)
+		if err := dispatcher.Run(dispatchCtx); err != nil {
+			slog.Error("Webhook dispatcher stopped", "err", err)
+		}
+	}()
+
+	// Setup HTTP server
+	routerOpts := api.RouterOptions{
+		AdminToken: cfg.Server.AdminToken,
+
+		APIKeys:             apiKeys,
+		APIKeysProtectReads: cfg.Server.APIKeysProtectReads,
+
+		JWT: jwtVerifier,
+
+		CORS: api.CORSOptions{
+			AllowedOrigins:   cfg.Server.CORSAllowedOrigins,
+			AllowedMethods:   cfg.Server.CORSAllowedMethods,
+			AllowedHeaders:   cfg.Server.CORSAllowedHeaders,
+			AllowCredentials: cfg.Server.CORSAllowCredentials,
+			MaxAge:           cfg.Server.CORSMaxAge,
+		},
+
+		RateLimitReads:  newRateLimiter(cfg.Server.RateLimitReadRPS, cfg.Server.RateLimitReadBurst, cfg.Server.RateLimitIdleTTL),
+		RateLimitWrites: newRateLimiter(cfg.Server.RateLimitWriteRPS, cfg.Server.RateLimitWriteBurst, cfg.Server.RateLimitIdleTTL),
+
+		DIDMethods: cfg.Server.DIDMethods,
+
+		DIDMaxVerificationMethods: cfg.Server.DIDMaxVerificationMethods,
+		DIDUpdateNonceTTL:         cfg.Server.DIDUpdateNonceTTL,
+
+		AnchorMetadataMaxBytes: cfg.Server.AnchorMetadataMaxBytes,
+		AnchorVerifyBatchMax:   cfg.Server.AnchorVerifyBatchMax,
+		AnchorDocumentMaxBytes: cfg.Server.AnchorDocumentMaxBytes,
+
+		BodyLimits: api.BodyLimits{
+			Default: cfg.Server.RequestMaxBytes,
+			Batch:   cfg.Server.RequestBatchMaxBytes,
+		},
+		Timeouts: api.Timeouts{
+			Default: cfg.Server.RequestTimeout,
+			Batch:   cfg.Server.RequestBatchTimeout,
+		},
+		StrictJSON: cfg.Server.RequestStrictJSON,
+
+		Webhooks:       dispatcher,
+		ReceiptSigner:  receiptSigner,
+		CommitmentKeys: commitmentKeys,
+		Idempotency:    idempotencyStore,
+
+		Audit:       auditLog,
+		AuditStrict: cfg.Server.AuditStrict,
+
+		ReadOnly:           readOnly,
+		ReadOnlyRetryAfter: cfg.Server.ReadOnlyRetryAfter,
+
+		Tenants:       tenantLedgers,
+		TenantAPIKeys: cfg.Server.TenantAPIKeys,
+
+		Logger: logger,
+		AccessLog: api.AccessLogOptions{
+			Exclude:     cfg.Server.AccessLogExclude,
+			SampleEvery: cfg.Server.AccessLogSample,
+		},
+
+		Pprof: cfg.Server.DebugPprof && cfg.Server.DebugPprofShared,
+	}
+	if cfg.Server.DIDWebResolution {
+		routerOpts.DIDWebResolver = didweb.NewResolver(&http.Client{Timeout: cfg.Server.DIDWebTimeout}, 0)
+	}
+	server.Handler = api.NewRouter(ledgerClient, routerOpts)
+
+	// Serve until ctx is cancelled, or a server fails
+	failed := make(chan error, 3)
+	build := buildinfo.Current()
+	addr := listeners.http.Addr()
+	starting := []any{"listen", addr.Network() + "://" + addr.String(), "version", build.Version, "commit", build.Commit, "build_date", build.BuildDate}
+	go func() {
+		var err error
+		switch tlsConfig := server.TLSConfig; {
+		case tlsConfig == nil:
+			slog.Info("Starting Fabric Resolver", starting...)
+			err = server.Serve(listeners.http)
+		case tlsConfig.ClientCAs != nil:
+			slog.Info("Starting Fabric Resolver", append(starting, "tls", true, "client_certs", true)...)
+			err = server.ServeTLS(listeners.http, "", "")
+		default:
+			slog.Info("Starting Fabric Resolver", append(starting, "tls", true)...)
+			err = server.ServeTLS(listeners.http, "", "")
+		}
+		if err != nil && err != http.ErrServerClosed {
+			failed <- fmt.Errorf("server: %w", err)
+		}
+	}()
+
+	// Profiles on a listener of their own, localhost-only by default
+	var pprofServer *http.Server
+	if listeners.pprof != nil {
+		pprofServer = &http.Server{
+			Handler:     api.PprofHandler(),
+			ReadTimeout: cfg.Server.ReadTimeout,
+			IdleTimeout: cfg.Server.IdleTimeout,
+			ErrorLog:    slog.NewLogLogger(logger.Handler(), slog.LevelError),
+		}
+		go func() {
+			slog.Warn("Serving pprof profiles", "addr", cfg.Server.DebugPprofAddr)
+			if err := pprofServer.Serve(listeners.pprof); err != nil && err != http.ErrServerClosed {
+				failed <- fmt.Errorf("pprof server: %w", err)
+			}
+		}()
+	} else if cfg.Server.DebugPprof {
+		slog.Warn("Serving pprof profiles to admins on the main listener")
+	}
+
+	// gRPC on its own port
+	var grpcServer *grpc.Server
+	if listeners.grpc != nil {
+		grpcServer = grpcapi.NewGRPCServer(ledgerClient, grpcapi.Options{
+			MaxMetadataBytes: cfg.Server.AnchorMetadataMaxBytes,
+			DIDMethods:       cfg.Server.DIDMethods,
+			Webhooks:         dispatcher,
+			TLS:              server.TLSConfig,
+
+			MaxVerificationMethods: cfg.Server.DIDMaxVerificationMethods,
+			DIDWebResolver:         routerOpts.DIDWebResolver,
+
+			APIKeys:             apiKeys,
+			APIKeysProtectReads: cfg.Server.APIKeysProtectReads,
+			JWT:                 jwtVerifier,
+			RateLimitReads:      routerOpts.RateLimitReads,
+			RateLimitWrites:     routerOpts.RateLimitWrites,
+			Audit:               auditLog,
+			AuditStrict:         cfg.Server.AuditStrict,
+			Tenants:             tenantLedgers,
+			TenantAPIKeys:       cfg.Server.TenantAPIKeys,
+			Logger:              logger,
+		})
+		go func() {
+			slog.Info("Starting gRPC server", "port", cfg.Server.GRPCPort, "tls", server.TLSConfig != nil)
+			if err := grpcServer.Serve(listeners.grpc); err != nil {
+				failed <- fmt.Errorf("gRPC server: %w", err)
+			}
+		}()
+	}
+
+	// Graceful shutdown
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("Shutting down")
+	case serveErr = <-failed:
+		slog.Error("Shutting down after a server failed", "err", serveErr)
+	}
+	gracefulShutdown(cfg.Server.ShutdownTimeout, server, pprofServer, grpcServer, func() {
+		stopDispatch()
+		<-dispatchDone
+	}, ledgerClient)
+	if tenantLedgers != nil {
+		if err := tenantLedgers.Close(); err != nil {
+			slog.Error("Shutdown: failed to close tenant ledgers", "err", err)
+		}
+	}
+	if auditLog != nil {
+		if err := auditLog.Close(); err != nil {
+			slog.Error("Shutdown: failed to close audit log", "err", err)
+		}
+	}
+
+	// Flush spans of the drained requests
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Warn("Shutdown: failed to flush traces", "err", err)
+	}
+	cancelFlush()
+
+	if serveErr != nil {
+		return exitWith(exitFailure, "Server failed", serveErr)
+	}
+	slog.Info("Server exited")
+	return nil
+}
+
+// serveListeners are the listeners of the server, bound before it starts. grpc and
+// pprof are nil when not served on a port of their own.
+type serveListeners struct {
+	http, grpc, pprof net.Listener
+}
+
+// listen binds the ports cfg serves on, releasing those already bound when one fails.
+func listen(cfg *config.Config) (*serveListeners, error) {
+	var l serveListeners
+	var err error
+	if l.http, err = api.Listen(&cfg.Server); err != nil {
+		return nil, fmt.Errorf("HTTP listener: %w", err)
+	}
+	if cfg.Server.GRPCPort > 0 {
+		if l.grpc, err = net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.GRPCPort)); err != nil {
+			l.close()
+			return nil, fmt.Errorf("gRPC port %d: %w", cfg.Server.GRPCPort, err)
+		}
+	}
+	if cfg.Server.DebugPprof && !cfg.Server.DebugPprofShared {
+		if l.pprof, err = net.Listen("tcp", cfg.Server.DebugPprofAddr); err != nil {
+			l.close()
+			return nil, fmt.Errorf("pprof address %s: %w", cfg.Server.DebugPprofAddr, err)
+		}
+	}
+	return &l, nil
+}
+
+func (l *serveListeners) close() {
+	for _, ln := range []net.Listener{l.http, l.grpc, l.pprof} {
+		if ln != nil {
+			ln.Close()
+		}
+	}
+}
+
+// newRateLimiter returns a per-client limiter, or nil when rps is 0 so the class is unlimited.
+func newRateLimiter(rps float64, burst int, idleTTL time.Duration) *ratelimit.Limiter {
+	if rps == 0 {
+		return nil
+	}
+	return ratelimit.New(ratelimit.Limit{Rate: rps, Burst: burst}, idleTTL)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+// serveEnv points the server at a ledger in dir and a free port, without gRPC,
+// and returns the port.
+func serveEnv(t *testing.T, dir string) int {
+	t.Helper()
+	checkEnv(t, dir)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	t.Setenv("SERVER_PORT", strconv.Itoa(port))
+	t.Setenv("GRPC_PORT", "0")
+	t.Setenv("LOG_LEVEL", "error")
+	return port
+}
+
+func runServe(ctx context.Context, args ...string) error {
+	var level slog.LevelVar
+	return run(ctx, append([]string{"serve"}, args...), &level, slog.New(slog.DiscardHandler), io.Discard)
+}
+
+func exitCodeOf(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return 0
+}
+
+// lockLedger holds the ledger of dir as another process would until the test ends.
+func lockLedger(t *testing.T, dir string) fabric.LedgerClient {
+	t.Helper()
+	client, err := fabric.NewLedgerClient(fabric.Config{Mode: "file", FilePath: filepath.Join(dir, "ledger.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// serveAndStop starts the server, waits for it to answer on port and cancels its
+// context as SIGTERM does in main, returning what run returned.
+func serveAndStop(t *testing.T, port int) error {
+	t.Helper()
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- runServe(ctx) }()
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/livez", port)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			break
+		}
+		select {
+		case err := <-done:
+			t.Fatalf("server exited before serving: %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not come up: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(10 * time.Second):
+		t.Fatal("server did not shut down")
+		return nil
+	}
+}
+
+func TestRun_ServesUntilCancelledAndExitsCleanly(t *testing.T) {
+	port := serveEnv(t, t.TempDir())
+	if err := serveAndStop(t, port); err != nil {
+		t.Errorf("expected a clean exit after shutdown, got %v", err)
+	}
+}
+
+func TestRun_ConfigErrorExits3(t *testing.T) {
+	serveEnv(t, t.TempDir())
+	t.Setenv("SERVER_PORT", "not-a-port")
+
+	if err := runServe(t.Context()); exitCodeOf(err) != exitConfig {
+		t.Errorf("expected exit code %d, got %v", exitConfig, err)
+	}
+}
+
+func TestRun_LockedLedgerExits4AfterRetrying(t *testing.T) {
+	dir := t.TempDir()
+	serveEnv(t, dir)
+	t.Setenv("LEDGER_OPEN_ATTEMPTS", "3")
+	t.Setenv("LEDGER_OPEN_RETRY_DELAY", "10ms")
+	lockLedger(t, dir)
+
+	start := time.Now()
+	err := runServe(t.Context())
+	if exitCodeOf(err) != exitLedger || !errors.Is(err, fabric.ErrLocked) {
+		t.Fatalf("expected exit code %d for a locked ledger, got %v", exitLedger, err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected two retry delays before giving up, returned after %s", elapsed)
+	}
+}
+
+func TestRun_WaitsForLedgerLockToBeReleased(t *testing.T) {
+	dir := t.TempDir()
+	port := serveEnv(t, dir)
+	t.Setenv("LEDGER_OPEN_ATTEMPTS", "100")
+	t.Setenv("LEDGER_OPEN_RETRY_DELAY", "10ms")
+	holder := lockLedger(t, dir)
+	time.AfterFunc(50*time.Millisecond, func() { holder.Close() })
+
+	if err := serveAndStop(t, port); err != nil {
+		t.Errorf("expected the server to start once the ledger was released, got %v", err)
+	}
+}
+
+func TestRun_PortInUseExits5(t *testing.T) {
+	dir := t.TempDir()
+	port := serveEnv(t, dir)
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	if err := runServe(t.Context()); exitCodeOf(err) != exitListener {
+		t.Errorf("expected exit code %d for a port in use, got %v", exitListener, err)
+	}
+	// The ledger was never opened, so it is free for the next start
+	lockLedger(t, dir)
+}
+
+func TestRun_UsageErrors(t *testing.T) {
+	checkEnv(t, t.TempDir())
+	var usage usageError
+	if err := run(t.Context(), []string{"frobnicate"}, new(slog.LevelVar), slog.New(slog.DiscardHandler), io.Discard); !errors.As(err, &usage) {
+		t.Errorf("expected a usage error for an unknown command, got %v", err)
+	}
+	if err := runServe(t.Context(), "extra"); !errors.As(err, &usage) {
+		t.Errorf("expected a usage error for a stray argument, got %v", err)
+	}
+}
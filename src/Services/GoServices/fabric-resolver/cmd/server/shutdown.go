@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"fabric-resolver/internal/infrastructure/fabric"
+
+	"google.golang.org/grpc"
+)
+
+// gracefulShutdown stops the resolver in dependency order. The HTTP and gRPC
+// servers stop accepting connections and in-flight requests get until timeout to
+// finish; only then are background deliveries stopped and the ledger closed, so
+// no request writes to the ledger after it has been flushed. pprofServer,
+// grpcServer and stopDispatch may be nil.
+func gracefulShutdown(timeout time.Duration, server, pprofServer *http.Server, grpcServer *grpc.Server, stopDispatch func(), ledger fabric.LedgerClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	slog.Info("Shutdown: draining HTTP requests", "timeout", timeout)
+	// Clients are told to reconnect elsewhere with the response of their last request
+	server.SetKeepAlivesEnabled(false)
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Warn("Shutdown: HTTP server forced to stop", "err", err)
+	} else {
+		slog.Info("Shutdown: HTTP requests drained")
+	}
+
+	if pprofServer != nil {
+		// Profiles in progress are cut off rather than holding up the shutdown
+		if err := pprofServer.Close(); err != nil {
+			slog.Warn("Shutdown: failed to close pprof listener", "err", err)
+		}
+	}
+
+	if grpcServer != nil {
+		slog.Info("Shutdown: draining gRPC calls")
+		stopGRPC(ctx, grpcServer)
+	}
+
+	if stopDispatch != nil {
+		slog.Info("Shutdown: stopping webhook delivery")
+		stopDispatch()
+	}
+
+	slog.Info("Shutdown: closing ledger")
+	if err := ledger.Close(); err != nil {
+		slog.Error("Shutdown: failed to close ledger client", "err", err)
+	}
+}
+
+// stopGRPC drains in-flight RPCs, cutting them off when ctx expires.
+func stopGRPC(ctx context.Context, s *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("Shutdown: gRPC server forced to stop", "err", ctx.Err())
+		s.Stop()
+	}
+}
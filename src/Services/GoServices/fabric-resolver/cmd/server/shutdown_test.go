@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/api"
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+func init() {
+	log.SetOutput(io.Discard)
+}
+
+// slowLedger holds CreateAnchor long enough for a shutdown to start while the
+// request is in flight, and records whether anything was written after Close.
+type slowLedger struct {
+	fabric.LedgerClient
+	entered     chan struct{}
+	closed      atomic.Bool
+	lateWrite   atomic.Bool
+	createDelay time.Duration
+}
+
+func (l *slowLedger) CreateAnchor(ctx context.Context, anchor *domain.Anchor) (string, uint64, error) {
+	close(l.entered)
+	time.Sleep(l.createDelay)
+	if l.closed.Load() {
+		l.lateWrite.Store(true)
+	}
+	return l.LedgerClient.CreateAnchor(ctx, anchor)
+}
+
+func (l *slowLedger) Close() error {
+	l.closed.Store(true)
+	return l.LedgerClient.Close()
+}
+
+func TestGracefulShutdown_DrainsInFlightCreateAnchor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	fileLedger, err := fabric.NewFileLedgerClient(path)
+	if err != nil {
+		t.Fatalf("failed to create ledger: %v", err)
+	}
+	ledger := &slowLedger{LedgerClient: fileLedger, entered: make(chan struct{}), createDelay: 300 * time.Millisecond}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	server := &http.Server{Handler: api.NewRouter(ledger, api.RouterOptions{})}
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(lis) }()
+
+	base := "http://" + lis.Addr().String()
+	hash := strings.Repeat("ab", 32)
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Post(base+"/anchors", "application/json", strings.NewReader(`{"hash":"`+hash+`"}`))
+		if err != nil {
+			t.Errorf("in-flight request failed: %v", err)
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+
+	select {
+	case <-ledger.entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request never reached the ledger")
+	}
+	gracefulShutdown(5*time.Second, server, nil, nil, nil, ledger)
+
+	if code := <-status; code != http.StatusCreated {
+		t.Errorf("expected the in-flight request to finish with 201, got %d", code)
+	}
+	if ledger.lateWrite.Load() {
+		t.Error("ledger closed before the in-flight write finished")
+	}
+	if err := <-served; err != http.ErrServerClosed {
+		t.Errorf("expected Serve to return ErrServerClosed, got %v", err)
+	}
+	if _, err := net.DialTimeout("tcp", lis.Addr().String(), time.Second); err == nil {
+		t.Error("expected new connections to be refused after shutdown")
+	}
+
+	reopened, err := fabric.NewFileLedgerClient(path)
+	if err != nil {
+		t.Fatalf("failed to reopen ledger: %v", err)
+	}
+	defer reopened.Close()
+	if _, err := reopened.GetAnchor(context.Background(), hash); err != nil {
+		t.Errorf("anchor written during shutdown was not persisted: %v", err)
+	}
+}
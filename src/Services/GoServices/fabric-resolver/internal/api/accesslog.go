@@ -0,0 +1,29 @@
+package api
+
+import "strings"
+
+// DefaultAccessLogExclude are the paths polled by probes and scrapers, whose
+// successful requests would otherwise drown the rest of the access log.
+var DefaultAccessLogExclude = []string{"/health", "/livez", "/readyz", "/metrics"}
+
+// AccessLogOptions thins out the access log of frequently polled paths. Requests
+// answered with anything but a 2xx are always logged.
+type AccessLogOptions struct {
+	// Exclude are the paths whose successful requests are not logged; a path
+	// ending in "/" excludes everything below it.
+	Exclude []string
+
+	// SampleEvery logs one in every SampleEvery successful requests to excluded
+	// paths, so probes still show up now and then. Zero logs none of them.
+	SampleEvery int
+}
+
+// excludes reports whether path is one of the excluded paths.
+func (o AccessLogOptions) excludes(path string) bool {
+	for _, excluded := range o.Exclude {
+		if path == excluded || (strings.HasSuffix(excluded, "/") && strings.HasPrefix(path, excluded)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/audit"
+	"fabric-resolver/internal/requestid"
+
+	"github.com/gorilla/mux"
+)
+
+// auditMiddleware appends an entry to log for every write request, queries with a
+// body aside, once its response status is known; requests refused by
+// authentication are recorded too. In strict mode an attempt entry is appended
+// first, and a request whose attempt cannot be recorded is answered with 503
+// before it is handled, so no write reaches the ledger unrecorded. A request
+// whose result entry cannot be appended is answered with 503 too; the write
+// itself may then have been made.
+func auditMiddleware(log *audit.Log, strict bool, logger *slog.Logger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if log == nil || !isWrite(r.Method) || queryRoutes[unversioned(routeTemplate(r))] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			record := &handlers.AuditRecord{}
+			appendEntry := func(entry audit.Entry) error {
+				err := log.Append(entry)
+				if err != nil {
+					logger.Error("Failed to append audit entry", "err", err, "strict", strict, "result", entry.Result,
+						"method", r.Method, "path", r.URL.Path, "request_id", requestid.FromContext(r.Context()))
+				}
+				return err
+			}
+			if strict {
+				attempt := auditEntry(r, record, 0)
+				attempt.Result = audit.ResultAttempt
+				if err := appendEntry(attempt); err != nil {
+					writeErrorCode(w, http.StatusServiceUnavailable, handlers.CodeAuditUnavailable, "The audit log cannot be written")
+					return
+				}
+			}
+
+			aw := &auditWriter{ResponseWriter: w, strict: strict}
+			aw.append = func(status int) error {
+				return appendEntry(auditEntry(r, record, status))
+			}
+			next.ServeHTTP(aw, r.WithContext(handlers.WithAuditRecord(r.Context(), record)))
+			if !aw.appended {
+				// net/http sends 200 when the handler wrote nothing
+				aw.WriteHeader(http.StatusOK)
+			}
+		})
+	}
+}
+
+// auditEntry builds the entry of request r answered with status.
+func auditEntry(r *http.Request, record *handlers.AuditRecord, status int) audit.Entry {
+	entry := audit.Entry{
+		RequestID: requestid.FromContext(r.Context()),
+		Principal: "anonymous",
+		Admin:     record.Admin,
+		Method:    r.Method,
+		Route:     routeTemplate(r),
+		Resource:  record.Resource,
+		Status:    status,
+		Result:    audit.ResultOf(status),
+		SourceIP:  r.RemoteAddr,
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		entry.SourceIP = host
+	}
+	if entry.Resource == "" {
+		vars := mux.Vars(r)
+		entry.Resource = firstNonEmpty(vars["hash"], vars["did"], vars["id"])
+	}
+
+	access, _ := r.Context().Value(accessLogKey{}).(*accessLog)
+	if access == nil {
+		access = &accessLog{}
+	}
+	entry.Tenant = access.tenant
+	switch subject := handlers.ClientSubject(r.Context()); {
+	case access.tokenSubject != "":
+		entry.Principal = "token:" + access.tokenSubject
+	case access.apiKeyID != "":
+		entry.Principal = "key:" + access.apiKeyID
+	case subject != "":
+		entry.Principal = "cert:" + subject
+	case record.Admin:
+		entry.Principal = "admin"
+	}
+	return entry
+}
+
+// markAdmin records in the audit entry of r, if any, that it was authorized as an admin.
+func markAdmin(r *http.Request) {
+	if record := handlers.AuditRecordFrom(r.Context()); record != nil {
+		record.Admin = true
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// auditWriter appends the audit entry when the response status is written. In
+// strict mode a failed append replaces the response with a 503.
+type auditWriter struct {
+	http.ResponseWriter
+	strict   bool
+	append   func(status int) error
+	appended bool
+	failed   bool
+}
+
+func (w *auditWriter) WriteHeader(status int) {
+	if w.failed {
+		return
+	}
+	if !w.appended {
+		w.appended = true
+		if err := w.append(status); err != nil && w.strict {
+			w.failed = true
+			// Drop what the handler meant to send along with its own response
+			for _, header := range []string{"Location", "Link", "ETag", "Content-Length"} {
+				w.Header().Del(header)
+			}
+			writeErrorCode(w.ResponseWriter, http.StatusServiceUnavailable, handlers.CodeAuditUnavailable, "The audit log cannot be written")
+			return
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *auditWriter) Write(b []byte) (int, error) {
+	if !w.appended {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.failed {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *auditWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/apikeys"
+	"fabric-resolver/internal/audit"
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+const (
+	auditHash = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	auditKey  = "0123456789abcdef0123"
+)
+
+func newAuditRouter(t *testing.T, strict bool) (http.Handler, *audit.Log) {
+	t.Helper()
+	dir := t.TempDir()
+	ledger, err := fabric.NewFileLedgerClient(dir + "/ledger.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ledger.Close() })
+	log, err := audit.Open(dir+"/audit.log", audit.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { log.Close() })
+	keys, err := apikeys.Load("", "ci="+auditKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewRouter(ledger, RouterOptions{AdminToken: "s3cret", APIKeys: keys, Audit: log, AuditStrict: strict}), log
+}
+
+func TestAudit_RecordsWrites(t *testing.T) {
+	router, log := newAuditRouter(t, false)
+
+	if rec := serveWithKey(router, "POST", "/v1/anchors", auditKey, `{"hash":"`+auditHash+`"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	// A rejected write, a query and a read, of which only the first is recorded
+	serveWithKey(router, "POST", "/v1/anchors", auditKey, `{"hash":"nothex"}`)
+	serveWithKey(router, "POST", "/v1/anchors/verify-batch", auditKey, `{"hashes":["`+auditHash+`"]}`)
+	serveWithKey(router, "GET", "/v1/anchors/"+auditHash, auditKey, "")
+	// Refused before reaching a handler
+	serveWithKey(router, "DELETE", "/v1/anchors/"+auditHash, auditKey, "")
+	// Made by an admin
+	req := httptest.NewRequest("PUT", "/admin/read-only", strings.NewReader(`{"readOnly":false}`))
+	req.Header.Set(APIKeyHeader, auditKey)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries, _, err := log.Read(time.Time{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d: %+v", len(entries), entries)
+	}
+
+	created := entries[0]
+	if created.Time.IsZero() || created.RequestID == "" {
+		t.Errorf("expected a time and request id, got %+v", created)
+	}
+	created.Time, created.RequestID = time.Time{}, ""
+	want := audit.Entry{
+		Principal: "key:ci", Method: "POST", Route: "/v1/anchors", Resource: auditHash,
+		Status: http.StatusCreated, Result: audit.ResultSuccess, SourceIP: "192.0.2.1",
+	}
+	if created != want {
+		t.Errorf("unexpected entry:\n got %+v\nwant %+v", created, want)
+	}
+
+	if e := entries[1]; e.Status != http.StatusBadRequest || e.Result != audit.ResultFailure {
+		t.Errorf("expected the rejected write recorded as a failure, got %+v", e)
+	}
+	if e := entries[2]; e.Route != "/v1/anchors/{hash}" || e.Resource != auditHash || e.Status != http.StatusUnauthorized || e.Result != audit.ResultDenied {
+		t.Errorf("expected the refused delete recorded as denied, got %+v", e)
+	}
+	if e := entries[3]; e.Principal != "key:ci" || !e.Admin || e.Route != "/admin/read-only" || e.Result != audit.ResultSuccess {
+		t.Errorf("expected the admin switch recorded, got %+v", e)
+	}
+}
+
+func TestAudit_StrictFailsUnrecordedWrites(t *testing.T) {
+	for _, strict := range []bool{true, false} {
+		router, log := newAuditRouter(t, strict)
+		log.Close()
+
+		rec := serveWithKey(router, "POST", "/v1/anchors", auditKey, `{"hash":"`+auditHash+`"}`)
+		var body handlers.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		switch {
+		case strict && (rec.Code != http.StatusServiceUnavailable || body.Code != handlers.CodeAuditUnavailable):
+			t.Errorf("strict: expected 503 audit_unavailable, got %d %s", rec.Code, rec.Body)
+		case strict && rec.Header().Get("Location") != "":
+			t.Errorf("strict: expected the handler's Location dropped, got %q", rec.Header().Get("Location"))
+		case !strict && rec.Code != http.StatusCreated:
+			t.Errorf("lenient: expected the write to succeed, got %d %s", rec.Code, rec.Body)
+		}
+		// Strict mode refuses the write before it reaches the ledger
+		if rec := serveWithKey(router, "GET", "/v1/anchors/"+auditHash, auditKey, ""); strict && rec.Code != http.StatusNotFound {
+			t.Errorf("strict: expected the unrecorded write not to be made, got %d", rec.Code)
+		}
+
+		// Reads do not depend on the audit log
+		if rec := serveWithKey(router, "GET", "/v1/anchors/"+auditHash, auditKey, ""); rec.Code == http.StatusServiceUnavailable {
+			t.Errorf("strict %v: expected reads served, got %d", strict, rec.Code)
+		}
+	}
+}
+
+func TestAudit_StrictRecordsAttemptFirst(t *testing.T) {
+	router, log := newAuditRouter(t, true)
+
+	if rec := serveWithKey(router, "POST", "/v1/anchors", auditKey, `{"hash":"`+auditHash+`"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", rec.Code, rec.Body)
+	}
+	entries, _, err := log.Read(time.Time{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected an attempt and a result entry, got %+v", entries)
+	}
+	attempt, result := entries[0], entries[1]
+	if attempt.Result != audit.ResultAttempt || attempt.Status != 0 || attempt.RequestID == "" || attempt.Route != "/v1/anchors" {
+		t.Errorf("unexpected attempt entry: %+v", attempt)
+	}
+	if result.Result != audit.ResultSuccess || result.Status != http.StatusCreated || result.RequestID != attempt.RequestID {
+		t.Errorf("unexpected result entry: %+v", result)
+	}
+}
+
+func TestAudit_ListEntries(t *testing.T) {
+	router, _ := newAuditRouter(t, false)
+	for _, hash := range []string{auditHash, strings.Repeat("a", 64), strings.Repeat("b", 64)} {
+		serveWithKey(router, "POST", "/v1/anchors", auditKey, `{"hash":"`+hash+`"}`)
+	}
+
+	if rec := serveWithKey(router, "GET", "/admin/audit", auditKey, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected the audit log closed to non-admins, got %d", rec.Code)
+	}
+
+	rec := serveAdmin(router, "GET", "/admin/audit?limit=2", "")
+	var page handlers.AuditPageResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("expected a page, got %d %s", rec.Code, rec.Body)
+	}
+	if len(page.Items) != 2 || page.NextCursor == "" || !strings.Contains(rec.Header().Get("Link"), `rel="next"`) {
+		t.Fatalf("expected 2 entries and a next page, got %+v (Link %q)", page, rec.Header().Get("Link"))
+	}
+
+	rec = serveAdmin(router, "GET", "/admin/audit?since="+page.NextCursor, "")
+	page = handlers.AuditPageResponse{}
+	json.Unmarshal(rec.Body.Bytes(), &page)
+	if len(page.Items) != 1 || page.Items[0].Resource != strings.Repeat("b", 64) || page.NextCursor != "" {
+		t.Errorf("expected the last entry alone, got %+v", page)
+	}
+
+	if rec := serveAdmin(router, "GET", "/admin/audit?since=yesterday", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected a malformed since rejected, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"fabric-resolver/internal/api/handlers"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// DefaultMaxBodyBytes caps request bodies of routes without a larger limit.
+	DefaultMaxBodyBytes = 1 << 20
+
+	// DefaultMaxBatchBodyBytes caps the batch endpoints, whose bodies hold up to
+	// fabric.MaxAnchorBatch anchors with metadata.
+	DefaultMaxBatchBodyBytes = 8 << 20
+)
+
+// BodyLimits caps request body sizes. Zero values use the defaults.
+type BodyLimits struct {
+	// Default applies to every route without a limit of its own (default 1 MiB).
+	Default int64
+
+	// Batch applies to POST /anchors/batch, /anchors/verify-batch and
+	// /anchors/merkle-batch (default 8 MiB).
+	Batch int64
+}
+
+// batchRoutes are the route templates limited by BodyLimits.Batch.
+var batchRoutes = []string{"/anchors/batch", "/anchors/verify-batch", "/anchors/merkle-batch"}
+
+func (l BodyLimits) withDefaults() BodyLimits {
+	if l.Default <= 0 {
+		l.Default = DefaultMaxBodyBytes
+	}
+	if l.Batch <= 0 {
+		l.Batch = DefaultMaxBatchBodyBytes
+	}
+	return l
+}
+
+// routes returns the limits of the routes that do not use the default.
+// POST /anchors/from-document gets at least maxDocumentBytes, so that the
+// handler's own document limit is the one that applies.
+func (l BodyLimits) routes(maxDocumentBytes int) map[string]int64 {
+	if maxDocumentBytes <= 0 {
+		maxDocumentBytes = handlers.DefaultMaxDocumentBytes
+	}
+	limits := map[string]int64{"/anchors/from-document": max(l.Default, int64(maxDocumentBytes))}
+	for _, route := range batchRoutes {
+		limits[route] = l.Batch
+	}
+	return limits
+}
+
+// bodyLimit caps the request body at the limit of the matched route, or at
+// fallback. Bodies that declare a larger Content-Length are rejected without
+// being read; others fail once the limit is crossed, which handlers answer with 413.
+func bodyLimit(routeLimits map[string]int64, fallback int64) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit, ok := routeLimits[unversioned(routeTemplate(r))]
+			if !ok {
+				limit = fallback
+			}
+			if r.ContentLength > limit {
+				writeErrorCode(w, http.StatusRequestEntityTooLarge, handlers.CodeTooLarge, "Request body exceeds "+strconv.FormatInt(limit, 10)+" bytes")
+				return
+			}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
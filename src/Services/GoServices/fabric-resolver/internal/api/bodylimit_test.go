@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/api/handlers"
+)
+
+// paddedAnchor is a POST /anchors body of exactly size bytes, padded with whitespace.
+func paddedAnchor(hash string, size int) string {
+	body := `{"hash":"` + hash + `"}`
+	return strings.Repeat(" ", size-len(body)) + body
+}
+
+func postBody(router http.Handler, path, body string, declareLength bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	if !declareLength {
+		req.ContentLength = -1 // chunked, so only reading finds the body too large
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func errorBody(t *testing.T, rec *httptest.ResponseRecorder) handlers.ErrorResponse {
+	t.Helper()
+	var body handlers.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected a JSON error body, got %q", rec.Body.String())
+	}
+	return body
+}
+
+func TestBodyLimit_RejectsOversizedBodies(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{BodyLimits: BodyLimits{Default: 1024}})
+
+	for _, declareLength := range []bool{true, false} {
+		rec := postBody(router, "/anchors", paddedAnchor(strings.Repeat("ab", 32), 1025), declareLength)
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("declared length %v: expected 413, got %d: %s", declareLength, rec.Code, rec.Body.String())
+		}
+		if body := errorBody(t, rec); body.Code != handlers.CodeTooLarge || !strings.Contains(body.Message, "1024 bytes") {
+			t.Errorf("declared length %v: expected a too_large error naming the limit, got %+v", declareLength, body)
+		}
+	}
+
+	// Just under the limit is read and decoded as usual
+	if rec := postBody(router, "/anchors", paddedAnchor(strings.Repeat("cd", 32), 1024), false); rec.Code != http.StatusCreated {
+		t.Errorf("expected a body at the limit to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestBodyLimit_BatchRoutesUseTheBatchLimit(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{BodyLimits: BodyLimits{Default: 1024, Batch: 4096}})
+
+	items := make([]string, 20)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"hash":"%064x"}`, i+1)
+	}
+	batch := "[" + strings.Join(items, ",") + "]"
+	if len(batch) <= 1024 || len(batch) > 4096 {
+		t.Fatalf("test batch of %d bytes must fall between the limits", len(batch))
+	}
+	for _, path := range []string{"/v1/anchors/batch", "/anchors/batch"} {
+		if rec := postBody(router, path, batch, true); rec.Code != http.StatusMultiStatus {
+			t.Errorf("%s: expected the batch limit to apply, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+		if rec := postBody(router, path, strings.Repeat(" ", 4096)+batch, false); rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: expected 413 above the batch limit, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestStrictJSON_RejectsUnknownFields(t *testing.T) {
+	anchor := `{"hash":"` + strings.Repeat("ef", 32) + `","issuer":"did:ewallet:typo"}`
+	did := `{"did":"did:ewallet:strict","verificationMethods":[]}`
+
+	lenient, _ := newTestRouter(t, RouterOptions{})
+	if rec := postBody(lenient, "/anchors", anchor, true); rec.Code != http.StatusCreated {
+		t.Fatalf("expected unknown fields to be ignored by default, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	strict, _ := newTestRouter(t, RouterOptions{StrictJSON: true})
+	for path, tc := range map[string]struct{ body, field string }{
+		"/anchors": {anchor, "issuer"},
+		"/dids":    {did, "verificationMethods"},
+	} {
+		rec := postBody(strict, path, tc.body, true)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400 in strict mode, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+		body := errorBody(t, rec)
+		if body.Code != handlers.CodeUnknownField || len(body.Details) != 1 || body.Details[0].Field != tc.field {
+			t.Errorf("%s: expected the unknown field %q to be named, got %+v", path, tc.field, body)
+		}
+	}
+}
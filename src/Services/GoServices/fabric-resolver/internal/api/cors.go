@@ -0,0 +1,139 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/requestid"
+
+	"github.com/gorilla/mux"
+)
+
+// CORSOptions configures cross-origin access from browsers. With no allowed
+// origins no CORS headers are sent, so browsers keep pages on other origins out.
+type CORSOptions struct {
+	// AllowedOrigins are exact origins ("https://wallet.example.com"), subdomain
+	// patterns ("*.example.com" for any scheme, "https://*.example.com"), or "*".
+	AllowedOrigins []string
+
+	// AllowedMethods and AllowedHeaders answer preflight requests.
+	// Empty uses DefaultCORSMethods and DefaultCORSHeaders.
+	AllowedMethods []string
+	AllowedHeaders []string
+
+	// AllowCredentials lets browsers send cookies and Authorization headers cross-origin.
+	AllowCredentials bool
+
+	// MaxAge is how long browsers may cache a preflight answer. Zero omits the header.
+	MaxAge time.Duration
+}
+
+var (
+	// DefaultCORSMethods are the methods the API serves.
+	DefaultCORSMethods = []string{"GET", "POST", "PUT", "DELETE"}
+	// DefaultCORSHeaders are the request headers the API reads.
+	DefaultCORSHeaders = []string{"Content-Type", "Authorization", APIKeyHeader, "Idempotency-Key", "If-None-Match", requestid.Header, handlers.TenantHeader}
+)
+
+// corsExposedHeaders are response headers browser code may read besides the safelisted ones.
+const corsExposedHeaders = "ETag, Location, Retry-After, " + requestid.Header
+
+// corsMiddleware reflects the request's Origin in Access-Control-Allow-Origin when
+// it is allowed, and answers preflight requests itself. Disallowed origins get no
+// CORS headers, which browsers treat as a refusal.
+func corsMiddleware(opts CORSOptions) mux.MiddlewareFunc {
+	methods := strings.Join(orDefault(opts.AllowedMethods, DefaultCORSMethods), ", ")
+	headers := strings.Join(orDefault(opts.AllowedHeaders, DefaultCORSHeaders), ", ")
+	maxAge := ""
+	if opts.MaxAge > 0 {
+		maxAge = strconv.Itoa(int(opts.MaxAge.Seconds()))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if len(opts.AllowedOrigins) > 0 {
+				// Responses differ by Origin, so shared caches must not mix them up
+				w.Header().Add("Vary", "Origin")
+			}
+
+			if origin != "" && originAllowed(opts.AllowedOrigins, origin) {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				if opts.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+				if preflight {
+					w.Header().Set("Access-Control-Allow-Methods", methods)
+					w.Header().Set("Access-Control-Allow-Headers", headers)
+					if maxAge != "" {
+						w.Header().Set("Access-Control-Max-Age", maxAge)
+					}
+				} else {
+					w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+				}
+			}
+
+			if preflight {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// preflightHandler serves OPTIONS requests that are not CORS preflights, so every
+// path answers OPTIONS instead of 405. It is routed for any path to make the router
+// run middleware, and with it corsMiddleware, on preflight requests.
+func preflightHandler(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// isOptions matches OPTIONS requests to any path.
+func isOptions(r *http.Request, _ *mux.RouteMatch) bool {
+	return r.Method == http.MethodOptions
+}
+
+// originAllowed reports whether origin matches one of patterns. Comparison ignores
+// case, as scheme and host are case-insensitive.
+func originAllowed(patterns []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	scheme, host, ok := strings.Cut(origin, "://")
+	if !ok || host == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSuffix(pattern, "/"))
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+
+		patternScheme, patternHost, hasScheme := strings.Cut(pattern, "://")
+		if !hasScheme {
+			patternScheme, patternHost = "", pattern
+		}
+		domain, isWildcard := strings.CutPrefix(patternHost, "*.")
+		if !isWildcard || (patternScheme != "" && patternScheme != scheme) {
+			continue
+		}
+		if patternScheme == "" && scheme != "http" && scheme != "https" {
+			continue
+		}
+		// *.example.com matches a.example.com and a.b.example.com, not example.com itself
+		if sub, ok := strings.CutSuffix(host, "."+domain); ok && sub != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func orDefault(values, defaults []string) []string {
+	if len(values) == 0 {
+		return defaults
+	}
+	return values
+}
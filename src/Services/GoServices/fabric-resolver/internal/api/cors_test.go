@@ -0,0 +1,155 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	patterns := []string{"https://wallet.example.com", "*.example.org", "https://*.example.net", "http://localhost:5174/"}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://wallet.example.com", true},
+		{"HTTPS://Wallet.Example.com", true},
+		{"http://wallet.example.com", false},
+		{"https://wallet.example.com:8443", false},
+		{"https://evil.wallet.example.com", false},
+		{"https://a.example.org", true},
+		{"http://a.b.example.org", true},
+		{"https://example.org", false},
+		{"https://evilexample.org", false},
+		{"https://example.org.evil.com", false},
+		{"ftp://a.example.org", false},
+		{"https://a.example.net", true},
+		{"http://a.example.net", false},
+		{"http://localhost:5174", true},
+		{"null", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := originAllowed(patterns, tt.origin); got != tt.want {
+			t.Errorf("originAllowed(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+
+	if !originAllowed([]string{"*"}, "https://anything.test") {
+		t.Error("expected * to allow any origin")
+	}
+}
+
+func corsRequest(handler http.Handler, method, origin string, preflight bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/anchors", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if preflight {
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "content-type, x-api-key")
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORS_ReflectsAllowedOrigins(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := corsMiddleware(CORSOptions{AllowedOrigins: []string{"*.example.com"}, AllowCredentials: true})(next)
+
+	rec := corsRequest(handler, "GET", "https://wallet.example.com", false)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the request to reach the handler, got %d", rec.Code)
+	}
+	h := rec.Header()
+	if h.Get("Access-Control-Allow-Origin") != "https://wallet.example.com" {
+		t.Errorf("expected the origin to be reflected, got %q", h.Get("Access-Control-Allow-Origin"))
+	}
+	if h.Get("Access-Control-Allow-Credentials") != "true" || h.Get("Vary") != "Origin" {
+		t.Errorf("expected credentials and Vary: Origin, got %v", h)
+	}
+	if h.Get("Access-Control-Expose-Headers") == "" {
+		t.Error("expected exposed headers on actual requests")
+	}
+
+	// Disallowed origins are served without CORS headers, so the browser blocks the response
+	rec = corsRequest(handler, "GET", "https://evil.test", false)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the request to reach the handler, got %d", rec.Code)
+	}
+	for _, name := range []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Credentials", "Access-Control-Expose-Headers"} {
+		if v := rec.Header().Get(name); v != "" {
+			t.Errorf("expected no %s for a disallowed origin, got %q", name, v)
+		}
+	}
+}
+
+func TestCORS_NoOriginsConfigured(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{})
+
+	rec := corsRequest(router, "GET", "https://wallet.example.com", false)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if v := rec.Header().Get("Access-Control-Allow-Origin"); v != "" {
+		t.Errorf("expected no wildcard origin by default, got %q", v)
+	}
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{CORS: CORSOptions{
+		AllowedOrigins: []string{"https://wallet.example.com"},
+		AllowedHeaders: []string{"Content-Type", "X-API-Key"},
+		MaxAge:         10 * time.Minute,
+	}})
+
+	rec := corsRequest(router, "OPTIONS", "https://wallet.example.com", true)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 for a preflight, got %d", rec.Code)
+	}
+	h := rec.Header()
+	if h.Get("Access-Control-Allow-Origin") != "https://wallet.example.com" {
+		t.Errorf("expected the origin to be reflected, got %q", h.Get("Access-Control-Allow-Origin"))
+	}
+	if h.Get("Access-Control-Allow-Methods") != "GET, POST, PUT, DELETE" {
+		t.Errorf("expected the default methods, got %q", h.Get("Access-Control-Allow-Methods"))
+	}
+	if h.Get("Access-Control-Allow-Headers") != "Content-Type, X-API-Key" {
+		t.Errorf("expected the configured headers, got %q", h.Get("Access-Control-Allow-Headers"))
+	}
+	if h.Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("expected Max-Age 600, got %q", h.Get("Access-Control-Max-Age"))
+	}
+	if h.Get("Access-Control-Allow-Credentials") != "" {
+		t.Error("expected no credentials header unless configured")
+	}
+
+	// Every path answers preflights, including DID paths with encoded characters
+	req := httptest.NewRequest("OPTIONS", "/dids/did:web:example.com%3A8443", nil)
+	req.Header.Set("Origin", "https://wallet.example.com")
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") == "" {
+		t.Errorf("expected a preflight answer for a DID path, got %d %v", rec.Code, rec.Header())
+	}
+
+	// Disallowed origins get an answer without CORS headers
+	rec = corsRequest(router, "OPTIONS", "https://evil.test", true)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if v := rec.Header().Get("Access-Control-Allow-Origin"); v != "" {
+		t.Errorf("expected no CORS headers for a disallowed origin, got %q", v)
+	}
+}
+
+func TestCORS_PreflightSkipsAPIKey(t *testing.T) {
+	router := newAPIKeyRouter(t, true)
+	if rec := corsRequest(router, "OPTIONS", "https://wallet.example.com", true); rec.Code != http.StatusNoContent {
+		t.Errorf("expected a preflight without a key to be answered, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"fabric-resolver/internal/pkg/canonicalizer"
+)
+
+// DefaultMaxDocumentBytes caps the body of POST /anchors/from-document when no limit is configured.
+const DefaultMaxDocumentBytes = 1 << 20
+
+// Headers carrying the anchor fields of POST /anchors/from-document, whose body is the
+// document itself. The issuerDid and metadata query parameters take precedence.
+const (
+	IssuerDIDHeader      = "X-Issuer-Did"
+	AnchorMetadataHeader = "X-Anchor-Metadata"
+)
+
+// DocumentAnchorResponse is the body of POST /anchors/from-document.
+type DocumentAnchorResponse struct {
+	// Hash is the SHA-256 of the canonical document, the hash that was anchored
+	Hash   string         `json:"hash"`
+	Anchor AnchorResponse `json:"anchor"`
+}
+
+// POST /anchors/from-document?issuerDid=&metadata=
+//
+// Canonicalizes the JSON body the same way canonicalizer.CanonicalizeAndHashJSON does
+// for every client, and anchors the resulting SHA-256. The body is hashed as a stream,
+// so multi-megabyte documents are never held whole. Callers need not reproduce the
+// canonicalization themselves to get a hash that verifies.
+func (h *AnchorHandler) CreateAnchorFromDocument(w http.ResponseWriter, r *http.Request) {
+	// The body is hashed as it is read, bounded by maxDocumentBytes rather than the canonicalizer's default
+	body := http.MaxBytesReader(w, r.Body, int64(h.maxDocumentBytes))
+	hash, err := canonicalizer.CanonicalizeAndHashReader(body, canonicalizer.Options{MaxBytes: -1})
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondErrorCode(w, http.StatusRequestEntityTooLarge, CodeTooLarge, "Document exceeds "+strconv.Itoa(h.maxDocumentBytes)+" bytes")
+			return
+		}
+		status, code := canonicalizeErrorStatus(err)
+		respondErrorCode(w, status, code, "Document must be a single JSON value: "+err.Error())
+		return
+	}
+	SetAuditResource(r.Context(), hash)
+
+	q := r.URL.Query()
+	req := CreateAnchorRequest{
+		Hash:      hash,
+		IssuerDID: firstNonEmpty(q.Get("issuerDid"), r.Header.Get(IssuerDIDHeader)),
+	}
+	if metadata := firstNonEmpty(q.Get("metadata"), r.Header.Get(AnchorMetadataHeader)); metadata != "" {
+		req.Metadata = json.RawMessage(metadata)
+	}
+
+	anchor, err := req.ToAnchor(h.maxMetadataBytes)
+	if err != nil {
+		respondAnchorRequestError(w, err)
+		return
+	}
+
+	txID, blockNumber, err := h.ledger(r.Context()).CreateAnchor(r.Context(), anchor)
+	if err != nil {
+		respondLedgerError(w, err, "Failed to create anchor")
+		return
+	}
+	anchor.TxID = txID
+	anchor.BlockNumber = blockNumber
+
+	respondJSON(w, http.StatusCreated, DocumentAnchorResponse{Hash: hash, Anchor: toAnchorResponse(anchor)})
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
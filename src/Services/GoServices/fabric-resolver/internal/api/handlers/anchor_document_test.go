@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCreateAnchorFromDocument_Verifies(t *testing.T) {
+	router := newAnchorRouter(newTestLedger(t))
+
+	// Key order, whitespace and escaping differ from the canonical form, whose
+	// SHA-256 is computed here without the canonicalizer
+	document := "{\n  \"type\": \"VerifiableCredential\",\n  \"amount\": 1.0,\n  \"issuer\": \"did:ewallet:issuer\", \"note\": \"a\\u003cb\"\n}"
+	want := hexHash(`{"amount":1.0,"issuer":"did:ewallet:issuer","note":"a<b","type":"VerifiableCredential"}`)
+
+	metadata := url.QueryEscape(`{"schema":"kyc"}`)
+	rec := doRequest(t, router, "POST", "/anchors/from-document?issuerDid=did:ewallet:issuer&metadata="+metadata, strings.NewReader(document))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	resp := decodeBody[DocumentAnchorResponse](t, rec)
+	if resp.Hash != want || resp.Anchor.Hash != want {
+		t.Fatalf("expected hash %s, got %+v", want, resp)
+	}
+	if resp.Anchor.IssuerDID != "did:ewallet:issuer" || string(resp.Anchor.Metadata) != `{"schema":"kyc"}` || resp.Anchor.TxID == "" {
+		t.Errorf("unexpected anchor: %+v", resp.Anchor)
+	}
+
+	verify := decodeBody[VerifyAnchorResponse](t, doRequest(t, router, "GET", "/anchors/"+want+"/verify", nil))
+	if !verify.Exists || !verify.Valid {
+		t.Errorf("expected the document hash to verify, got %+v", verify)
+	}
+}
+
+func TestCreateAnchorFromDocument_Headers(t *testing.T) {
+	router := newAnchorRouter(newTestLedger(t))
+
+	req := httptest.NewRequest("POST", "/anchors/from-document", strings.NewReader(`{"id":1}`))
+	req.Header.Set(IssuerDIDHeader, "did:ewallet:issuer")
+	req.Header.Set(AnchorMetadataHeader, `{"source":"header"}`)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	resp := decodeBody[DocumentAnchorResponse](t, rec)
+	if resp.Anchor.IssuerDID != "did:ewallet:issuer" || string(resp.Anchor.Metadata) != `{"source":"header"}` {
+		t.Errorf("expected the header fields to be anchored, got %+v", resp.Anchor)
+	}
+}
+
+func TestCreateAnchorFromDocument_Rejects(t *testing.T) {
+	h := NewAnchorHandler(newTestLedger(t), AnchorHandlerOptions{MaxDocumentBytes: 32})
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		status int
+		code   string
+	}{
+		{"trailing garbage", "/anchors/from-document", `{"a":1} {"b":2}`, http.StatusBadRequest, CodeInvalidJSON},
+		{"not JSON", "/anchors/from-document", `not json`, http.StatusBadRequest, CodeInvalidJSON},
+		{"empty", "/anchors/from-document", ``, http.StatusBadRequest, CodeInvalidJSON},
+		{"duplicate key", "/anchors/from-document", `{"a":1,"a":2}`, http.StatusBadRequest, CodeDuplicateKey},
+		{"too large", "/anchors/from-document", `{"padding":"` + strings.Repeat("x", 64) + `"}`, http.StatusRequestEntityTooLarge, CodeTooLarge},
+		{"invalid metadata", "/anchors/from-document?metadata=%7B", `{"a":1}`, http.StatusBadRequest, CodeInvalidMetadata},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, http.HandlerFunc(h.CreateAnchorFromDocument), "POST", tt.target, strings.NewReader(tt.body))
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if resp := decodeBody[ErrorResponse](t, rec); resp.Code != tt.code {
+				t.Errorf("expected code %q, got %q", tt.code, resp.Code)
+			}
+		})
+	}
+}
@@ -1,123 +1,735 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/idempotency"
 	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/webhooks"
 
 	"github.com/gorilla/mux"
 )
 
+// DefaultMaxVerifyBatch caps the hashes of one verify-batch request when no limit is configured.
+const DefaultMaxVerifyBatch = 256
+
 type AnchorHandler struct {
-	ledgerClient fabric.LedgerClient
+	ledgerClient     fabric.LedgerClient
+	maxMetadataBytes int
+	maxVerifyBatch   int
+	maxDocumentBytes int
+	webhooks         *webhooks.Dispatcher
+	streamKeepAlive  time.Duration
+	streamReplay     int
+	idempotency      *idempotency.Store
+	strictJSON       bool
+	now              func() time.Time // replaced in tests
 }
 
-func NewAnchorHandler(ledgerClient fabric.LedgerClient) *AnchorHandler {
+// AnchorHandlerOptions configures an AnchorHandler.
+type AnchorHandlerOptions struct {
+	// MaxMetadataBytes caps the canonical size of anchor metadata. Zero uses DefaultMaxMetadataBytes.
+	MaxMetadataBytes int
+
+	// MaxVerifyBatch caps the hashes of POST /anchors/verify-batch. Zero uses DefaultMaxVerifyBatch.
+	MaxVerifyBatch int
+
+	// MaxDocumentBytes caps the body of POST /anchors/from-document. Zero uses DefaultMaxDocumentBytes.
+	MaxDocumentBytes int
+
+	// Webhooks is notified of revocations. Nil sends no notifications.
+	Webhooks *webhooks.Dispatcher
+
+	// StreamKeepAlive is the comment interval of GET /anchors/stream. Zero uses DefaultStreamKeepAlive.
+	StreamKeepAlive time.Duration
+
+	// StreamReplayLimit caps the anchors GET /anchors/stream replays to one client
+	// after Last-Event-ID. Zero uses DefaultStreamReplayLimit.
+	StreamReplayLimit int
+
+	// Idempotency stores responses to POST /anchors sent with an Idempotency-Key. Nil ignores the header.
+	Idempotency *idempotency.Store
+
+	// StrictJSON rejects request bodies with fields the endpoint does not define.
+	StrictJSON bool
+}
+
+func NewAnchorHandler(ledgerClient fabric.LedgerClient, opts AnchorHandlerOptions) *AnchorHandler {
+	if opts.MaxMetadataBytes <= 0 {
+		opts.MaxMetadataBytes = DefaultMaxMetadataBytes
+	}
+	if opts.MaxVerifyBatch <= 0 {
+		opts.MaxVerifyBatch = DefaultMaxVerifyBatch
+	}
+	if opts.MaxDocumentBytes <= 0 {
+		opts.MaxDocumentBytes = DefaultMaxDocumentBytes
+	}
+	if opts.StreamKeepAlive <= 0 {
+		opts.StreamKeepAlive = DefaultStreamKeepAlive
+	}
+	if opts.StreamReplayLimit <= 0 {
+		opts.StreamReplayLimit = DefaultStreamReplayLimit
+	}
 	return &AnchorHandler{
-		ledgerClient: ledgerClient,
+		ledgerClient:     ledgerClient,
+		maxMetadataBytes: opts.MaxMetadataBytes,
+		maxVerifyBatch:   opts.MaxVerifyBatch,
+		maxDocumentBytes: opts.MaxDocumentBytes,
+		webhooks:         opts.Webhooks,
+		streamKeepAlive:  opts.StreamKeepAlive,
+		streamReplay:     opts.StreamReplayLimit,
+		idempotency:      opts.Idempotency,
+		strictJSON:       opts.StrictJSON,
+		now:              time.Now,
 	}
 }
 
+// ledger returns the ledger of the request's tenant, or the handler's own.
+func (h *AnchorHandler) ledger(ctx context.Context) fabric.LedgerClient {
+	return LedgerFrom(ctx, h.ledgerClient)
+}
+
 type CreateAnchorRequest struct {
-	Hash      string `json:"hash"`
-	IssuerDID string `json:"issuerDid,omitempty"`
-	Metadata  string `json:"metadata,omitempty"`
+	Hash      string          `json:"hash"`                // lowercase hex digest
+	Algorithm string          `json:"algorithm,omitempty"` // sha256 (default), sha512 or blake2b-256
+	IssuerDID string          `json:"issuerDid,omitempty"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`  // any JSON value, stored canonicalized
+	ExpiresAt string          `json:"expiresAt,omitempty"` // RFC3339, optional
+
+	// Proof optionally signs Hash with a key of IssuerDID
+	Proof *AnchorProof `json:"proof,omitempty"`
 }
 
 type AnchorResponse struct {
-	Hash        string `json:"hash"`
-	IssuerDID   string `json:"issuerDid"`
-	Timestamp   string `json:"timestamp"`
-	BlockNumber uint64 `json:"blockNumber"`
-	TxID        string `json:"txId"`
-	Metadata    string `json:"metadata,omitempty"`
+	Hash        string          `json:"hash"`
+	Algorithm   string          `json:"algorithm,omitempty"`
+	IssuerDID   string          `json:"issuerDid"`
+	Timestamp   string          `json:"timestamp"`
+	BlockNumber uint64          `json:"blockNumber"`
+	TxID        string          `json:"txId"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
+	ExpiresAt   string          `json:"expiresAt,omitempty"`
+
+	MetadataHash      string `json:"metadataHash,omitempty"`
+	SignatureVerified bool   `json:"signatureVerified"`
+
+	Revoked          bool   `json:"revoked,omitempty"`
+	RevokedAt        string `json:"revokedAt,omitempty"`
+	RevocationReason string `json:"revocationReason,omitempty"`
+
+	Tombstoned      bool   `json:"tombstoned,omitempty"`
+	TombstonedAt    string `json:"tombstonedAt,omitempty"`
+	TombstoneReason string `json:"tombstoneReason,omitempty"`
+}
+
+// AnchorPageResponse is one page of anchors from GET /anchors.
+type AnchorPageResponse struct {
+	Items      []AnchorResponse `json:"items"`
+	NextCursor string           `json:"nextCursor,omitempty"`
+	Total      int              `json:"total"`
+}
+
+// ToAnchor validates the request and converts it to the domain model.
+func (req CreateAnchorRequest) ToAnchor(maxMetadataBytes int) (*domain.Anchor, error) {
+	if req.Hash == "" {
+		return nil, fieldError(CodeMissingField, "hash", errors.New("Hash is required"))
+	}
+	algorithm, err := domain.ValidateHash(req.Hash, req.Algorithm)
+	if err != nil {
+		return nil, hashFieldError(err)
+	}
+
+	anchor := &domain.Anchor{
+		Hash:      req.Hash,
+		Algorithm: algorithm,
+		IssuerDID: req.IssuerDID,
+	}
+
+	if anchor.Metadata, anchor.MetadataHash, err = canonicalMetadata(req.Metadata, maxMetadataBytes); err != nil {
+		code := CodeInvalidMetadata
+		if errors.Is(err, errMetadataTooLarge) {
+			code = CodeMetadataTooLarge
+		}
+		return nil, fieldError(code, "metadata", err)
+	}
+
+	if req.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return nil, fieldError(CodeInvalidTimestamp, "expiresAt", errors.New("expiresAt must be an RFC3339 timestamp"))
+		}
+		if !expiresAt.After(time.Now()) {
+			return nil, fieldError(CodeInvalidTimestamp, "expiresAt", errors.New("expiresAt must be in the future"))
+		}
+		expiresAt = expiresAt.UTC()
+		anchor.ExpiresAt = &expiresAt
+	}
+
+	return anchor, nil
+}
+
+// hashFieldError attributes a ValidateHash error to the hash, or to the algorithm
+// when the algorithm itself is unsupported.
+func hashFieldError(err error) error {
+	var formatErr *domain.HashFormatError
+	if errors.As(err, &formatErr) && formatErr.ExpectedLength == 0 {
+		return fieldError(CodeUnsupportedAlgorithm, "algorithm", err)
+	}
+	return fieldError(CodeInvalidHashFormat, "hash", err)
+}
+
+// hashFormatErrorResponse is the 400 body for a hash that does not match its algorithm.
+type hashFormatErrorResponse struct {
+	ErrorResponse
+	Field          string `json:"field"`
+	Algorithm      string `json:"algorithm"`
+	ExpectedLength int    `json:"expectedLength,omitempty"`
+}
+
+// respondAnchorRequestError writes the response for a ToAnchor error.
+func respondAnchorRequestError(w http.ResponseWriter, err error) {
+	var formatErr *domain.HashFormatError
+	var reqErr *requestError
+	if errors.As(err, &formatErr) && errors.As(err, &reqErr) {
+		respondJSON(w, http.StatusBadRequest, hashFormatErrorResponse{
+			ErrorResponse:  WithRequestID(w, newErrorResponse(reqErr.code, err.Error(), ErrorDetail{Field: reqErr.field, Reason: reqErr.reason})),
+			Field:          reqErr.field,
+			Algorithm:      formatErr.Algorithm,
+			ExpectedLength: formatErr.ExpectedLength,
+		})
+		return
+	}
+	respondRequestError(w, anchorRequestStatus(err), err)
 }
 
 // POST /anchors
+//
+// A request with an Idempotency-Key header is answered once; repeating it with the
+// same body replays that answer.
 func (h *AnchorHandler) CreateAnchor(w http.ResponseWriter, r *http.Request) {
+	idempotent(h.idempotency, h.createAnchor)(w, r)
+}
+
+func (h *AnchorHandler) createAnchor(w http.ResponseWriter, r *http.Request) {
 	var req CreateAnchorRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body")
+	if err := decodeJSON(r.Body, &req, h.strictJSON); err != nil {
+		respondBodyError(w, err, CodeInvalidBody, "Invalid request body")
 		return
 	}
+	SetAuditResource(r.Context(), req.Hash)
 
-	if req.Hash == "" {
-		respondError(w, http.StatusBadRequest, "Hash is required")
+	anchor, err := req.ToAnchor(h.maxMetadataBytes)
+	if err != nil {
+		respondAnchorRequestError(w, err)
 		return
 	}
-
-	anchor := &domain.Anchor{
-		Hash:      req.Hash,
-		IssuerDID: req.IssuerDID,
-		Metadata:  req.Metadata,
+	if req.Proof != nil {
+		if err := h.verifyProof(r.Context(), req.IssuerDID, req.Proof, []byte(req.Hash)); err != nil {
+			respondError(w, proofErrorStatus(err), err.Error())
+			return
+		}
+		anchor.SignatureVerified = true
 	}
 
-	txID, blockNumber, err := h.ledgerClient.CreateAnchor(r.Context(), anchor)
+	txID, blockNumber, err := h.ledger(r.Context()).CreateAnchor(r.Context(), anchor)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to create anchor: "+err.Error())
+		respondLedgerError(w, err, "Failed to create anchor")
 		return
 	}
 
-	resp := AnchorResponse{
-		Hash:        anchor.Hash,
-		IssuerDID:   anchor.IssuerDID,
-		Timestamp:   anchor.Timestamp.UTC().Format("2006-01-02T15:04:05Z"),
-		BlockNumber: blockNumber,
-		TxID:        txID,
-		Metadata:    anchor.Metadata,
+	anchor.TxID = txID
+	anchor.BlockNumber = blockNumber
+
+	respondJSON(w, http.StatusCreated, toAnchorResponse(anchor))
+}
+
+// BatchAnchorResult is the outcome of one item of POST /anchors/batch.
+type BatchAnchorResult struct {
+	Index       int    `json:"index"`
+	Hash        string `json:"hash"`
+	Status      string `json:"status"` // created, already-existed or error
+	Code        int    `json:"code"`   // HTTP status the item would have had on its own
+	TxID        string `json:"txId,omitempty"`
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// BatchAnchorResponse is the multi-status body of POST /anchors/batch.
+type BatchAnchorResponse struct {
+	Created int                 `json:"created"`
+	Existed int                 `json:"existed"`
+	Failed  int                 `json:"failed"`
+	Results []BatchAnchorResult `json:"results"`
+}
+
+// POST /anchors/batch
+//
+// Accepts an array of CreateAnchorRequest and answers 207 Multi-Status with a result
+// per item, since items can succeed and fail independently.
+func (h *AnchorHandler) CreateAnchorsBatch(w http.ResponseWriter, r *http.Request) {
+	var reqs []CreateAnchorRequest
+	if err := decodeJSON(r.Body, &reqs, h.strictJSON); err != nil {
+		respondBodyError(w, err, CodeBadRequest, "Invalid request body: expected an array of anchors")
+		return
 	}
+	if len(reqs) == 0 || len(reqs) > fabric.MaxAnchorBatch {
+		respondError(w, http.StatusBadRequest, "Batch must contain between 1 and "+strconv.Itoa(fabric.MaxAnchorBatch)+" anchors")
+		return
+	}
+	SetAuditResource(r.Context(), strconv.Itoa(len(reqs))+" anchors")
 
-	respondJSON(w, http.StatusCreated, resp)
+	// Items that fail request validation never reach the ledger
+	resp := BatchAnchorResponse{Results: make([]BatchAnchorResult, len(reqs))}
+	anchors := make([]*domain.Anchor, 0, len(reqs))
+	indexes := make([]int, 0, len(reqs))
+	for i, req := range reqs {
+		resp.Results[i] = BatchAnchorResult{Index: i, Hash: req.Hash}
+		anchor, err := req.ToAnchor(h.maxMetadataBytes)
+		if err != nil {
+			resp.Results[i].Status = string(fabric.AnchorFailed)
+			resp.Results[i].Code = anchorRequestStatus(err)
+			resp.Results[i].Error = err.Error()
+			continue
+		}
+		if req.Proof != nil {
+			if err := h.verifyProof(r.Context(), req.IssuerDID, req.Proof, []byte(req.Hash)); err != nil {
+				resp.Results[i].Status = string(fabric.AnchorFailed)
+				resp.Results[i].Code = proofErrorStatus(err)
+				resp.Results[i].Error = err.Error()
+				continue
+			}
+			anchor.SignatureVerified = true
+		}
+		anchors = append(anchors, anchor)
+		indexes = append(indexes, i)
+	}
+
+	if len(anchors) > 0 {
+		results, err := h.ledger(r.Context()).CreateAnchors(r.Context(), anchors)
+		if err != nil {
+			respondLedgerError(w, err, "Failed to create anchors")
+			return
+		}
+		for j, res := range results {
+			item := &resp.Results[indexes[j]]
+			item.Status = string(res.Status)
+			item.TxID = res.TxID
+			item.BlockNumber = res.BlockNumber
+			switch res.Status {
+			case fabric.AnchorCreated:
+				item.Code = http.StatusCreated
+			case fabric.AnchorExisted:
+				item.Code = http.StatusOK
+			default:
+				item.Code = http.StatusBadRequest
+				if res.Err != nil {
+					item.Error = res.Err.Error()
+				}
+			}
+		}
+	}
+
+	for _, item := range resp.Results {
+		switch item.Status {
+		case string(fabric.AnchorCreated):
+			resp.Created++
+		case string(fabric.AnchorExisted):
+			resp.Existed++
+		default:
+			resp.Failed++
+		}
+	}
+
+	respondJSON(w, http.StatusMultiStatus, resp)
 }
 
 // GET /anchors/{hash}
 func (h *AnchorHandler) GetAnchor(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	hash := vars["hash"]
+	hash := domain.NormalizeHash(vars["hash"])
 
 	if hash == "" {
 		respondError(w, http.StatusBadRequest, "Hash is required")
 		return
 	}
 
-	anchor, err := h.ledgerClient.GetAnchor(r.Context(), hash)
+	anchor, err := h.ledger(r.Context()).GetAnchor(r.Context(), hash)
 	if err != nil {
-		respondError(w, http.StatusNotFound, "Anchor not found")
+		if errors.Is(err, fabric.ErrExpired) {
+			respondError(w, http.StatusGone, "Anchor expired")
+			return
+		}
+		if errors.Is(err, fabric.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "Anchor not found")
+			return
+		}
+		respondLedgerError(w, err, "Failed to read anchor")
 		return
 	}
 
+	respondJSON(w, http.StatusOK, toAnchorResponse(anchor))
+}
+
+func toAnchorResponse(anchor *domain.Anchor) AnchorResponse {
 	resp := AnchorResponse{
 		Hash:        anchor.Hash,
+		Algorithm:   anchor.Algorithm,
 		IssuerDID:   anchor.IssuerDID,
 		Timestamp:   anchor.Timestamp.UTC().Format("2006-01-02T15:04:05Z"),
 		BlockNumber: anchor.BlockNumber,
 		TxID:        anchor.TxID,
 		Metadata:    anchor.Metadata,
+
+		MetadataHash:      anchor.MetadataHash,
+		SignatureVerified: anchor.SignatureVerified,
+	}
+	if anchor.ExpiresAt != nil {
+		resp.ExpiresAt = anchor.ExpiresAt.UTC().Format(time.RFC3339)
 	}
+	if anchor.Revoked {
+		resp.Revoked = true
+		resp.RevocationReason = anchor.RevocationReason
+		if anchor.RevokedAt != nil {
+			resp.RevokedAt = anchor.RevokedAt.UTC().Format(time.RFC3339)
+		}
+	}
+	if anchor.Tombstoned {
+		resp.Tombstoned = true
+		resp.TombstoneReason = anchor.TombstoneReason
+		if anchor.TombstonedAt != nil {
+			resp.TombstonedAt = anchor.TombstonedAt.UTC().Format(time.RFC3339)
+		}
+	}
+	return resp
+}
 
-	respondJSON(w, http.StatusOK, resp)
+// VerifyAnchorResponse is the body of GET /anchors/{hash}/verify.
+type VerifyAnchorResponse struct {
+	Hash              string   `json:"hash"`
+	Exists            bool     `json:"exists"`
+	Valid             bool     `json:"valid"` // For kompatibilitet med .NET client forventning
+	Revoked           bool     `json:"revoked"`
+	SignatureVerified bool     `json:"signatureVerified"`
+	Timestamp         string   `json:"timestamp,omitempty"`
+	Reasons           []string `json:"reasons"` // why valid is false; empty when valid
 }
 
-// GET /anchors/{hash}/verify
+// GET /anchors/{hash}/verify?maxAge=&notBefore=&notAfter=
+//
+// Without parameters valid is true for existing anchors that are not revoked. The
+// freshness parameters additionally require the anchor timestamp to fall within the given bounds.
 func (h *AnchorHandler) VerifyAnchor(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	hash := vars["hash"]
+	hash := domain.NormalizeHash(vars["hash"])
 
 	if hash == "" {
 		respondError(w, http.StatusBadRequest, "Hash is required")
 		return
 	}
 
+	constraints, err := parseFreshness(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// VerifyAnchor returnerer nu bare bool (ikke error)
-	exists := h.ledgerClient.VerifyAnchor(r.Context(), hash)
+	exists := h.ledger(r.Context()).VerifyAnchor(r.Context(), hash)
+
+	resp := VerifyAnchorResponse{Hash: hash, Exists: exists, Reasons: []string{}}
+	var anchor *domain.Anchor
+	if exists {
+		if anchor, err = h.ledger(r.Context()).GetAnchor(r.Context(), hash); err != nil {
+			// Expired or removed between the two reads
+			resp.Exists = false
+		}
+	}
+
+	if !resp.Exists {
+		resp.Reasons = append(resp.Reasons, reasonNotFound)
+	} else {
+		resp.Revoked = anchor.Revoked
+		resp.SignatureVerified = anchor.SignatureVerified
+		if anchor.Revoked {
+			resp.Reasons = append(resp.Reasons, reasonRevoked)
+		}
+		resp.Timestamp = anchor.Timestamp.UTC().Format(time.RFC3339Nano)
+		resp.Reasons = append(resp.Reasons, constraints.check(anchor.Timestamp, h.now())...)
+	}
+	resp.Valid = len(resp.Reasons) == 0
+
+	respondJSON(w, http.StatusOK, resp)
+}
 
-	resp := map[string]interface{}{
+// VerifyBatchRequest is the body of POST /anchors/verify-batch.
+type VerifyBatchRequest struct {
+	Hashes []string `json:"hashes"`
+}
+
+// VerifyBatchResult is the state of one hash in a verify-batch response.
+type VerifyBatchResult struct {
+	Exists      bool   `json:"exists"`
+	Revoked     bool   `json:"revoked"`
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	Timestamp   string `json:"timestamp,omitempty"`
+}
+
+// VerifyBatchResponse maps every requested hash (lowercased) to its state.
+type VerifyBatchResponse struct {
+	Results map[string]VerifyBatchResult `json:"results"`
+}
+
+// POST /anchors/verify-batch
+//
+// Looks up many hashes in one ledger read; use it instead of a GET per hash.
+func (h *AnchorHandler) VerifyAnchorsBatch(w http.ResponseWriter, r *http.Request) {
+	var req VerifyBatchRequest
+	if err := decodeJSON(r.Body, &req, h.strictJSON); err != nil {
+		respondBodyError(w, err, CodeInvalidBody, "Invalid request body")
+		return
+	}
+	if len(req.Hashes) == 0 || len(req.Hashes) > h.maxVerifyBatch {
+		respondError(w, http.StatusBadRequest, "hashes must contain between 1 and "+strconv.Itoa(h.maxVerifyBatch)+" entries")
+		return
+	}
+
+	hashes := make([]string, len(req.Hashes))
+	for i, hash := range req.Hashes {
+		if hash == "" {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("hashes[%d] is empty", i))
+			return
+		}
+		hashes[i] = domain.NormalizeHash(hash)
+	}
+
+	found, err := h.ledger(r.Context()).VerifyAnchors(r.Context(), hashes)
+	if err != nil {
+		respondLedgerError(w, err, "Failed to verify anchors")
+		return
+	}
+
+	resp := VerifyBatchResponse{Results: make(map[string]VerifyBatchResult, len(found))}
+	for hash, v := range found {
+		result := VerifyBatchResult{Exists: v.Exists, Revoked: v.Revoked, BlockNumber: v.BlockNumber}
+		if v.Exists {
+			result.Timestamp = v.Timestamp.UTC().Format(time.RFC3339Nano)
+		}
+		resp.Results[hash] = result
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// TombstoneAnchorRequest is the body of DELETE /anchors/{hash}.
+type TombstoneAnchorRequest struct {
+	Reason string `json:"reason"`
+}
+
+// DELETE /anchors/{hash} (admin)
+func (h *AnchorHandler) TombstoneAnchor(w http.ResponseWriter, r *http.Request) {
+	hash := domain.NormalizeHash(mux.Vars(r)["hash"])
+
+	var req TombstoneAnchorRequest
+	if err := decodeJSON(r.Body, &req, h.strictJSON); err != nil {
+		respondBodyError(w, err, CodeInvalidBody, "Invalid request body")
+		return
+	}
+	if req.Reason == "" {
+		respondError(w, http.StatusBadRequest, "Reason is required")
+		return
+	}
+
+	if err := h.ledger(r.Context()).TombstoneAnchor(r.Context(), hash, req.Reason); err != nil {
+		respondLedgerError(w, err, "Failed to tombstone anchor")
+		return
+	}
+
+	response := map[string]interface{}{
 		"hash":   hash,
-		"exists": exists,
-		"valid":  exists, // For kompatibilitet med .NET client forventning
+		"status": "tombstoned",
+		"reason": req.Reason,
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// GET /transactions/{txId}/anchor
+func (h *AnchorHandler) GetAnchorByTxID(w http.ResponseWriter, r *http.Request) {
+	txID := mux.Vars(r)["txId"]
+
+	anchor, err := h.ledger(r.Context()).GetAnchorByTxID(r.Context(), txID)
+	if err != nil {
+		respondLedgerError(w, err, "Failed to get anchor for transaction")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, toAnchorResponse(anchor))
+}
+
+// GET /anchors?limit=&cursor=
+// GET /anchors?fromBlock=&toBlock=&from=&to=
+// GET /anchors?prefix=&limit=
+//
+// With any range parameter the matching anchors are returned in a single page.
+// With prefix, up to limit anchors whose hash starts with it are returned.
+func (h *AnchorHandler) ListAnchors(w http.ResponseWriter, r *http.Request) {
+	if prefix := r.URL.Query().Get("prefix"); prefix != "" {
+		h.findAnchorsByPrefix(w, r, domain.NormalizeHash(prefix))
+		return
+	}
+
+	filter, isQuery, err := parseAnchorFilter(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if isQuery {
+		h.queryAnchors(w, r, filter)
+		return
 	}
 
+	opts, ok := parseListOptions(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.ledger(r.Context()).ListAnchors(r.Context(), opts)
+	if err != nil {
+		if errors.Is(err, fabric.ErrValidation) {
+			respondError(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+		respondLedgerError(w, err, "Failed to list anchors")
+		return
+	}
+
+	resp := toAnchorPageResponse(page)
+	setNextLink(w, r, resp.NextCursor)
 	respondJSON(w, http.StatusOK, resp)
 }
+
+func (h *AnchorHandler) findAnchorsByPrefix(w http.ResponseWriter, r *http.Request, prefix string) {
+	if len(prefix) < fabric.MinAnchorPrefix || strings.Trim(prefix, "0123456789abcdef") != "" {
+		respondError(w, http.StatusBadRequest, "prefix must be at least "+strconv.Itoa(fabric.MinAnchorPrefix)+" hex characters")
+		return
+	}
+	opts, ok := parseListOptions(w, r)
+	if !ok {
+		return
+	}
+
+	anchors, err := h.ledger(r.Context()).FindAnchorsByPrefix(r.Context(), prefix, opts.Limit)
+	if err != nil {
+		if errors.Is(err, fabric.ErrValidation) {
+			respondErrorCode(w, http.StatusBadRequest, CodeValidation, err.Error())
+			return
+		}
+		respondLedgerError(w, err, "Failed to find anchors")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, toAnchorPageResponse(&fabric.AnchorPage{Items: anchors, Total: len(anchors)}))
+}
+
+func (h *AnchorHandler) queryAnchors(w http.ResponseWriter, r *http.Request, filter fabric.AnchorFilter) {
+	anchors, err := h.ledger(r.Context()).QueryAnchors(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, fabric.ErrValidation) {
+			respondErrorCode(w, http.StatusBadRequest, CodeValidation, err.Error())
+			return
+		}
+		respondLedgerError(w, err, "Failed to query anchors")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, toAnchorPageResponse(&fabric.AnchorPage{Items: anchors, Total: len(anchors)}))
+}
+
+// parseAnchorFilter reads the fromBlock/toBlock (block numbers) and from/to (RFC3339)
+// query parameters. isQuery is false when none of them are present.
+func parseAnchorFilter(r *http.Request) (filter fabric.AnchorFilter, isQuery bool, err error) {
+	q := r.URL.Query()
+
+	parseBlock := func(name string) (uint64, error) {
+		v := q.Get(name)
+		if v == "" {
+			return 0, nil
+		}
+		isQuery = true
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s must be a block number", name)
+		}
+		return n, nil
+	}
+	parseTime := func(name string) (time.Time, error) {
+		v := q.Get(name)
+		if v == "" {
+			return time.Time{}, nil
+		}
+		isQuery = true
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s must be RFC3339 format", name)
+		}
+		return t, nil
+	}
+
+	if filter.FromBlock, err = parseBlock("fromBlock"); err != nil {
+		return filter, isQuery, err
+	}
+	if filter.ToBlock, err = parseBlock("toBlock"); err != nil {
+		return filter, isQuery, err
+	}
+	if filter.FromTime, err = parseTime("from"); err != nil {
+		return filter, isQuery, err
+	}
+	if filter.ToTime, err = parseTime("to"); err != nil {
+		return filter, isQuery, err
+	}
+	return filter, isQuery, nil
+}
+
+// GET /issuers/{did}/anchors?limit=&cursor=
+func (h *AnchorHandler) ListAnchorsByIssuer(w http.ResponseWriter, r *http.Request) {
+	issuerDID := didFromPath(r)
+
+	opts, ok := parseListOptions(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.ledger(r.Context()).GetAnchorsByIssuer(r.Context(), issuerDID, opts)
+	if err != nil {
+		if errors.Is(err, fabric.ErrValidation) {
+			respondErrorCode(w, http.StatusBadRequest, CodeValidation, err.Error())
+			return
+		}
+		respondLedgerError(w, err, "Failed to list anchors")
+		return
+	}
+
+	resp := toAnchorPageResponse(page)
+	setNextLink(w, r, resp.NextCursor)
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// toAnchorPageResponse converts a page of domain anchors to the response DTO
+func toAnchorPageResponse(page *fabric.AnchorPage) AnchorPageResponse {
+	resp := AnchorPageResponse{
+		Items:      make([]AnchorResponse, len(page.Items)),
+		NextCursor: encodeCursor(page.NextCursor),
+		Total:      page.Total,
+	}
+	for i := range page.Items {
+		resp.Items[i] = toAnchorResponse(&page.Items[i])
+	}
+	return resp
+}
@@ -0,0 +1,531 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+
+	"github.com/gorilla/mux"
+)
+
+func init() {
+	log.SetOutput(io.Discard)
+}
+
+// newTestLedger returns a file ledger client in a temp dir that is closed after the test.
+func newTestLedger(t *testing.T) *fabric.FileLedgerClient {
+	t.Helper()
+	client, err := fabric.NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	if err != nil {
+		t.Fatalf("failed to create ledger: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// newAnchorRouter mounts the anchor routes the same way api.NewRouter does.
+func newAnchorRouter(ledger fabric.LedgerClient) *mux.Router {
+	h := NewAnchorHandler(ledger, AnchorHandlerOptions{})
+	r := mux.NewRouter().UseEncodedPath()
+	r.HandleFunc("/anchors", h.CreateAnchor).Methods("POST")
+	r.HandleFunc("/anchors", h.ListAnchors).Methods("GET")
+	r.HandleFunc("/anchors/batch", h.CreateAnchorsBatch).Methods("POST")
+	r.HandleFunc("/anchors/from-document", h.CreateAnchorFromDocument).Methods("POST")
+	r.HandleFunc("/anchors/verify-batch", h.VerifyAnchorsBatch).Methods("POST")
+	r.HandleFunc("/anchors/{hash}", h.GetAnchor).Methods("GET")
+	r.HandleFunc("/anchors/{hash}/verify", h.VerifyAnchor).Methods("GET")
+	r.HandleFunc("/anchors/{hash}/revoke", h.RevokeAnchor).Methods("POST")
+	r.HandleFunc("/issuers/{did}/anchors", h.ListAnchorsByIssuer).Methods("GET")
+	r.HandleFunc("/transactions/{txId}/anchor", h.GetAnchorByTxID).Methods("GET")
+	r.HandleFunc("/anchors/{hash}", h.TombstoneAnchor).Methods("DELETE")
+	return r
+}
+
+func doRequest(t *testing.T, h http.Handler, method, target string, body io.Reader) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, body)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeBody[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.NewDecoder(rec.Body).Decode(&v); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return v
+}
+
+// hexHash returns the SHA-256 of s as lowercase hex, a valid anchor hash.
+func hexHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func seedAnchors(t *testing.T, ledger fabric.LedgerClient, n int) {
+	t.Helper()
+	for i := 1; i <= n; i++ {
+		if _, _, err := ledger.CreateAnchor(context.Background(), &domain.Anchor{Hash: fmt.Sprintf("hash-%02d", i)}); err != nil {
+			t.Fatalf("seed failed: %v", err)
+		}
+	}
+}
+
+func TestListAnchors_Empty(t *testing.T) {
+	router := newAnchorRouter(newTestLedger(t))
+
+	rec := doRequest(t, router, "GET", "/anchors", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); body != "{\"items\":[],\"total\":0}\n" {
+		t.Errorf("unexpected empty page body: %s", body)
+	}
+}
+
+func TestListAnchors_ExactPageBoundaries(t *testing.T) {
+	ledger := newTestLedger(t)
+	seedAnchors(t, ledger, 4)
+	router := newAnchorRouter(ledger)
+
+	first := decodeBody[AnchorPageResponse](t, doRequest(t, router, "GET", "/anchors?limit=2", nil))
+	if len(first.Items) != 2 || first.Total != 4 || first.NextCursor == "" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+	if first.Items[0].Hash != "hash-01" || first.Items[1].Hash != "hash-02" {
+		t.Errorf("first page not ordered by block: %+v", first.Items)
+	}
+
+	second := decodeBody[AnchorPageResponse](t, doRequest(t, router, "GET", "/anchors?limit=2&cursor="+first.NextCursor, nil))
+	if len(second.Items) != 2 || second.Items[0].Hash != "hash-03" || second.Items[1].Hash != "hash-04" {
+		t.Fatalf("unexpected second page: %+v", second)
+	}
+	// The page ends exactly at the last item, so there is no next page
+	if second.NextCursor != "" {
+		t.Errorf("expected no next cursor on final page, got %q", second.NextCursor)
+	}
+}
+
+func TestListAnchors_InvalidLimit(t *testing.T) {
+	router := newAnchorRouter(newTestLedger(t))
+
+	for _, limit := range []string{"0", "-1", "abc"} {
+		rec := doRequest(t, router, "GET", "/anchors?limit="+limit, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("limit=%s: expected 400, got %d", limit, rec.Code)
+		}
+	}
+
+	if rec := doRequest(t, router, "GET", "/anchors?cursor=not-a-cursor", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid cursor: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestListAnchorsByIssuer(t *testing.T) {
+	ledger := newTestLedger(t)
+	ctx := context.Background()
+	for i := 1; i <= 2; i++ {
+		ledger.CreateAnchor(ctx, &domain.Anchor{Hash: fmt.Sprintf("a-%d", i), IssuerDID: "did:ewallet:a"})
+		ledger.CreateAnchor(ctx, &domain.Anchor{Hash: fmt.Sprintf("b-%d", i), IssuerDID: "did:ewallet:b"})
+	}
+	router := newAnchorRouter(ledger)
+
+	rec := doRequest(t, router, "GET", "/issuers/did:ewallet:b/anchors", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	page := decodeBody[AnchorPageResponse](t, rec)
+	if page.Total != 2 || len(page.Items) != 2 || page.Items[0].Hash != "b-1" || page.Items[1].Hash != "b-2" {
+		t.Errorf("unexpected page: %+v", page)
+	}
+
+	empty := decodeBody[AnchorPageResponse](t, doRequest(t, router, "GET", "/issuers/did:ewallet:nobody/anchors", nil))
+	if empty.Total != 0 || len(empty.Items) != 0 {
+		t.Errorf("expected empty page, got %+v", empty)
+	}
+
+	if rec := doRequest(t, router, "GET", "/issuers/did:ewallet:a/anchors?limit=0", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid limit, got %d", rec.Code)
+	}
+}
+
+func TestListAnchors_RangeQuery(t *testing.T) {
+	ledger := newTestLedger(t)
+	seedAnchors(t, ledger, 5)
+	router := newAnchorRouter(ledger)
+
+	page := decodeBody[AnchorPageResponse](t, doRequest(t, router, "GET", "/anchors?fromBlock=2&toBlock=4", nil))
+	if page.Total != 3 || len(page.Items) != 3 || page.Items[0].BlockNumber != 2 || page.Items[2].BlockNumber != 4 {
+		t.Errorf("unexpected block range result: %+v", page)
+	}
+
+	now := time.Now().UTC()
+	window := "&from=" + now.Add(-time.Hour).Format(time.RFC3339) + "&to=" + now.Add(time.Hour).Format(time.RFC3339)
+	page = decodeBody[AnchorPageResponse](t, doRequest(t, router, "GET", "/anchors?fromBlock=5"+window, nil))
+	if page.Total != 1 || page.Items[0].Hash != "hash-05" {
+		t.Errorf("unexpected combined range result: %+v", page)
+	}
+
+	page = decodeBody[AnchorPageResponse](t, doRequest(t, router, "GET", "/anchors?to="+now.Add(-time.Hour).Format(time.RFC3339), nil))
+	if page.Total != 0 || len(page.Items) != 0 {
+		t.Errorf("expected no anchors before the window, got %+v", page)
+	}
+}
+
+func TestListAnchors_InvalidRange(t *testing.T) {
+	router := newAnchorRouter(newTestLedger(t))
+
+	for _, query := range []string{
+		"fromBlock=5&toBlock=2",
+		"from=2024-03-31T00:00:00Z&to=2024-03-01T00:00:00Z",
+		"fromBlock=abc",
+		"from=yesterday",
+	} {
+		if rec := doRequest(t, router, "GET", "/anchors?"+query, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestGetAnchorByTxID(t *testing.T) {
+	ledger := newTestLedger(t)
+	txID, _, err := ledger.CreateAnchor(context.Background(), &domain.Anchor{Hash: "by-tx", IssuerDID: "did:ewallet:i"})
+	if err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	router := newAnchorRouter(ledger)
+
+	rec := doRequest(t, router, "GET", "/transactions/"+txID+"/anchor", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := decodeBody[AnchorResponse](t, rec); got.Hash != "by-tx" || got.TxID != txID {
+		t.Errorf("unexpected anchor: %+v", got)
+	}
+
+	rec = doRequest(t, router, "GET", "/transactions/unknown-tx/anchor", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+	if body := decodeBody[ErrorResponse](t, rec); body.Error == "" {
+		t.Error("expected structured error body")
+	}
+
+	// Ledger failures are not reported as a missing anchor
+	ledger.Close()
+	if rec := doRequest(t, router, "GET", "/transactions/"+txID+"/anchor", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 from a closed ledger, got %d", rec.Code)
+	}
+}
+
+func TestTombstoneAnchor(t *testing.T) {
+	ledger := newTestLedger(t)
+	seedAnchors(t, ledger, 1)
+	router := newAnchorRouter(ledger)
+
+	if rec := doRequest(t, router, "DELETE", "/anchors/hash-01", strings.NewReader(`{}`)); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without reason, got %d", rec.Code)
+	}
+	if rec := doRequest(t, router, "DELETE", "/anchors/unknown", strings.NewReader(`{"reason":"gdpr"}`)); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown anchor, got %d", rec.Code)
+	}
+
+	if rec := doRequest(t, router, "DELETE", "/anchors/HASH-01", strings.NewReader(`{"reason":"gdpr"}`)); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for the hash in upper case, got %d", rec.Code)
+	}
+
+	got := decodeBody[AnchorResponse](t, doRequest(t, router, "GET", "/anchors/hash-01", nil))
+	if !got.Tombstoned || got.TombstoneReason != "gdpr" || got.TombstonedAt == "" {
+		t.Errorf("tombstone not surfaced by GET: %+v", got)
+	}
+
+	verify := decodeBody[map[string]interface{}](t, doRequest(t, router, "GET", "/anchors/hash-01/verify", nil))
+	if verify["exists"] != true {
+		t.Errorf("tombstoned anchor should still verify: %v", verify)
+	}
+
+	// Ledger failures are not reported as a missing anchor
+	ledger.Close()
+	if rec := doRequest(t, router, "DELETE", "/anchors/hash-01", strings.NewReader(`{"reason":"gdpr"}`)); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 from a closed ledger, got %d", rec.Code)
+	}
+}
+
+func TestCreateAnchorsBatch(t *testing.T) {
+	ledger := newTestLedger(t)
+	if _, _, err := ledger.CreateAnchor(context.Background(), &domain.Anchor{Hash: hexHash("existing")}); err != nil {
+		t.Fatalf("seed failed: %v", err)
+	}
+	router := newAnchorRouter(ledger)
+
+	body := fmt.Sprintf(`[
+		{"hash": %[1]q, "issuerDid": "did:ewallet:i"},
+		{"hash": %[2]q},
+		{"hash": %[1]q},
+		{"hash": ""},
+		{"hash": %[3]q, "expiresAt": "not-a-time"}
+	]`, hexHash("b-1"), hexHash("existing"), hexHash("b-2"))
+	rec := doRequest(t, router, "POST", "/anchors/batch", strings.NewReader(body))
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("expected 207, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	resp := decodeBody[BatchAnchorResponse](t, rec)
+	if resp.Created != 1 || resp.Existed != 1 || resp.Failed != 3 {
+		t.Errorf("unexpected summary: %+v", resp)
+	}
+	wantCodes := []int{http.StatusCreated, http.StatusOK, http.StatusBadRequest, http.StatusBadRequest, http.StatusBadRequest}
+	for i, want := range wantCodes {
+		if got := resp.Results[i]; got.Index != i || got.Code != want {
+			t.Errorf("item %d: got %+v, want code %d", i, got, want)
+		}
+	}
+	if resp.Results[0].TxID == "" || resp.Results[2].Error == "" {
+		t.Errorf("expected txID on created item and error on duplicate: %+v", resp.Results)
+	}
+
+	for _, bad := range []string{`{}`, `[]`, `not json`} {
+		if rec := doRequest(t, router, "POST", "/anchors/batch", strings.NewReader(bad)); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", bad, rec.Code)
+		}
+	}
+}
+
+func TestCreateAnchor_HashFormat(t *testing.T) {
+	router := newAnchorRouter(newTestLedger(t))
+	sha512Hex := strings.Repeat("ab", 64)
+
+	tests := []struct {
+		name      string
+		body      string
+		field     string
+		algorithm string
+		length    int
+	}{
+		{"not hex", `{"hash":"hello"}`, "hash", "sha256", 64},
+		{"sha512 digest without algorithm", `{"hash":"` + sha512Hex + `"}`, "hash", "sha256", 64},
+		{"sha256 digest labelled sha512", `{"hash":"` + hexHash("x") + `","algorithm":"sha512"}`, "hash", "sha512", 128},
+		{"uppercase", `{"hash":"` + strings.ToUpper(hexHash("x")) + `"}`, "hash", "sha256", 64},
+		{"unsupported algorithm", `{"hash":"` + hexHash("x") + `","algorithm":"md5"}`, "algorithm", "md5", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, router, "POST", "/anchors", strings.NewReader(tt.body))
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", rec.Code)
+			}
+			got := decodeBody[hashFormatErrorResponse](t, rec)
+			if got.Field != tt.field || got.Algorithm != tt.algorithm || got.ExpectedLength != tt.length || got.Error == "" {
+				t.Errorf("unexpected error body: %+v", got)
+			}
+		})
+	}
+
+	rec := doRequest(t, router, "POST", "/anchors", strings.NewReader(`{"hash":"`+sha512Hex+`","algorithm":"sha512"}`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for a sha512 anchor, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := decodeBody[AnchorResponse](t, doRequest(t, router, "GET", "/anchors/"+sha512Hex, nil)); got.Algorithm != "sha512" {
+		t.Errorf("algorithm not stored: %+v", got)
+	}
+}
+
+func TestAnchorLookup_NormalizesCase(t *testing.T) {
+	router := newAnchorRouter(newTestLedger(t))
+	hash := hexHash("case")
+
+	if rec := doRequest(t, router, "POST", "/anchors", strings.NewReader(`{"hash":"`+hash+`"}`)); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+
+	upper := strings.ToUpper(hash)
+	rec := doRequest(t, router, "GET", "/anchors/"+upper, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected uppercase lookup to find the anchor, got %d", rec.Code)
+	}
+	if got := decodeBody[AnchorResponse](t, rec); got.Hash != hash || got.Algorithm != "sha256" {
+		t.Errorf("unexpected anchor: %+v", got)
+	}
+	if verify := decodeBody[VerifyAnchorResponse](t, doRequest(t, router, "GET", "/anchors/"+upper+"/verify", nil)); !verify.Exists || verify.Hash != hash {
+		t.Errorf("uppercase verify did not match: %+v", verify)
+	}
+}
+
+func TestVerifyAnchorsBatch(t *testing.T) {
+	ledger := newTestLedger(t)
+	router := newAnchorRouter(ledger)
+	present, absent, revoked := hexHash("present"), hexHash("absent"), hexHash("revoked")
+	ledger.CreateAnchor(t.Context(), &domain.Anchor{Hash: present})
+	ledger.CreateAnchor(t.Context(), &domain.Anchor{Hash: revoked})
+	ledger.RevokeAnchor(t.Context(), revoked, "superseded")
+
+	body := `{"hashes":["` + strings.ToUpper(present) + `","` + absent + `","` + revoked + `"]}`
+	rec := doRequest(t, router, "POST", "/anchors/verify-batch", strings.NewReader(body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	results := decodeBody[VerifyBatchResponse](t, rec).Results
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", results)
+	}
+	if got := results[present]; !got.Exists || got.Revoked || got.BlockNumber != 1 || got.Timestamp == "" {
+		t.Errorf("unexpected result for present hash: %+v", got)
+	}
+	if got := results[absent]; got.Exists || got.Revoked || got.Timestamp != "" {
+		t.Errorf("unexpected result for absent hash: %+v", got)
+	}
+	if got := results[revoked]; !got.Exists || !got.Revoked {
+		t.Errorf("unexpected result for revoked hash: %+v", got)
+	}
+}
+
+func TestVerifyAnchorsBatch_Limit(t *testing.T) {
+	h := NewAnchorHandler(newTestLedger(t), AnchorHandlerOptions{MaxVerifyBatch: 2})
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`{"hashes":["a","b"]}`, http.StatusOK},
+		{`{"hashes":["a","b","c"]}`, http.StatusBadRequest},
+		{`{"hashes":[]}`, http.StatusBadRequest},
+		{`{"hashes":[""]}`, http.StatusBadRequest},
+		{`not json`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := doRequest(t, http.HandlerFunc(h.VerifyAnchorsBatch), "POST", "/anchors/verify-batch", strings.NewReader(tt.body))
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.body, tt.want, rec.Code)
+		}
+		if tt.want == http.StatusBadRequest && strings.Contains(tt.body, `"c"`) && !strings.Contains(rec.Body.String(), "between 1 and 2") {
+			t.Errorf("error does not state the limit: %s", rec.Body.String())
+		}
+	}
+}
+
+func TestListAnchors_Prefix(t *testing.T) {
+	ledger := newTestLedger(t)
+	router := newAnchorRouter(ledger)
+
+	hashes := []string{
+		"abcd1234" + strings.Repeat("0", 56),
+		"abcd5678" + strings.Repeat("0", 56),
+		"abcd1299" + strings.Repeat("0", 56),
+		"abce0000" + strings.Repeat("0", 56),
+		"0bcd1234" + strings.Repeat("0", 56),
+	}
+	for _, hash := range hashes {
+		ledger.CreateAnchor(t.Context(), &domain.Anchor{Hash: hash})
+	}
+
+	tests := []struct {
+		query string
+		want  []string // in block order
+	}{
+		{"prefix=abcd", []string{hashes[0], hashes[1], hashes[2]}},
+		{"prefix=abcd12", []string{hashes[0], hashes[2]}},
+		{"prefix=ABCD1234", []string{hashes[0]}},
+		{"prefix=abc&limit=1", nil}, // too short
+		{"prefix=abcd&limit=2", []string{hashes[0], hashes[1]}},
+		{"prefix=ffff", []string{}},
+	}
+	for _, tt := range tests {
+		rec := doRequest(t, router, "GET", "/anchors?"+tt.query, nil)
+		if tt.want == nil {
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected 400, got %d", tt.query, rec.Code)
+			}
+			continue
+		}
+		page := decodeBody[AnchorPageResponse](t, rec)
+		got := make([]string, len(page.Items))
+		for i, item := range page.Items {
+			got[i] = item.Hash
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") || page.Total != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	if rec := doRequest(t, router, "GET", "/anchors?prefix=xyz12", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a non-hex prefix, got %d", rec.Code)
+	}
+}
+
+// failingLedger fails CreateAnchor with err and delegates everything else.
+type failingLedger struct {
+	fabric.LedgerClient
+	err error
+}
+
+func (l failingLedger) CreateAnchor(ctx context.Context, anchor *domain.Anchor) (string, uint64, error) {
+	return "", 0, l.err
+}
+
+func TestCreateAnchor_LedgerErrors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("anchor %w", fabric.ErrAlreadyExists), http.StatusConflict, CodeAlreadyExists},
+		{fmt.Errorf("bad hash: %w", fabric.ErrValidation), http.StatusBadRequest, CodeValidation},
+		{fmt.Errorf("issuer %w", fabric.ErrNotFound), http.StatusNotFound, CodeNotFound},
+		{fmt.Errorf("%w: endorsement timeout", fabric.ErrTransient), http.StatusServiceUnavailable, CodeLedgerUnavailable},
+		{fabric.ErrClientClosed, http.StatusServiceUnavailable, CodeLedgerUnavailable},
+		{fabric.ErrReadOnly, http.StatusServiceUnavailable, CodeReadOnly},
+		{fmt.Errorf("failed to persist anchor: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, CodeTimeout},
+		{errors.New("disk on fire"), http.StatusInternalServerError, CodeInternal},
+	}
+	for _, tt := range tests {
+		router := newAnchorRouter(failingLedger{LedgerClient: newTestLedger(t), err: tt.err})
+		rec := doRequest(t, router, "POST", "/anchors", strings.NewReader(`{"hash":"`+hexHash("a")+`"}`))
+		if rec.Code != tt.status {
+			t.Errorf("%v: expected %d, got %d", tt.err, tt.status, rec.Code)
+			continue
+		}
+		if resp := decodeBody[ErrorResponse](t, rec); resp.Code != tt.code || !strings.Contains(resp.Error, tt.err.Error()) {
+			t.Errorf("%v: unexpected body %+v", tt.err, resp)
+		}
+	}
+}
+
+func TestGetAnchor_NotFoundCode(t *testing.T) {
+	rec := doRequest(t, newAnchorRouter(newTestLedger(t)), "GET", "/anchors/"+hexHash("missing"), nil)
+	if resp := decodeBody[ErrorResponse](t, rec); rec.Code != http.StatusNotFound || resp.Code != CodeNotFound {
+		t.Errorf("expected 404 %s, got %d %+v", CodeNotFound, rec.Code, resp)
+	}
+}
+
+func TestGetAnchor_LedgerUnavailable(t *testing.T) {
+	ledger := newTestLedger(t)
+	hash := hexHash("closed")
+	ledger.CreateAnchor(t.Context(), &domain.Anchor{Hash: hash})
+	router := newAnchorRouter(ledger)
+	ledger.Close()
+
+	// A closed ledger is retried later, not cached as a missing anchor
+	for _, req := range []struct{ method, path string }{{"GET", "/anchors/" + hash}, {"POST", "/anchors/" + hash + "/revoke"}} {
+		rec := doRequest(t, router, req.method, req.path, nil)
+		if resp := decodeBody[ErrorResponse](t, rec); rec.Code != http.StatusServiceUnavailable || resp.Code != CodeLedgerUnavailable {
+			t.Errorf("%s %s: expected 503 %s, got %d %+v", req.method, req.path, CodeLedgerUnavailable, rec.Code, resp)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"fabric-resolver/internal/domain"
+)
+
+// errProofRejected marks proofs that are well-formed but do not prove the issuer signed the hash.
+var errProofRejected = errors.New("proof rejected")
+
+// AnchorProof is an issuer signature over the ASCII bytes of the anchor hash,
+// or of "revoke:" followed by the hash when revoking.
+type AnchorProof struct {
+	VerificationMethod string `json:"verificationMethod"` // key id in the issuer's DID document, full or "#fragment"
+	Signature          string `json:"signature"`          // base64url, unpadded
+	Created            string `json:"created"`            // RFC3339
+}
+
+// verifyProof checks that proof signs message with a key of issuerDID, using the
+// issuer's DID document on the ledger.
+// Errors wrapping errProofRejected should be answered with 401, others with 400.
+func (h *AnchorHandler) verifyProof(ctx context.Context, issuerDID string, proof *AnchorProof, message []byte) error {
+	if issuerDID == "" {
+		return errors.New("issuerDid is required with a proof")
+	}
+	if proof.VerificationMethod == "" || proof.Signature == "" {
+		return errors.New("proof requires verificationMethod and signature")
+	}
+	if _, err := time.Parse(time.RFC3339, proof.Created); err != nil {
+		return errors.New("proof.created must be an RFC3339 timestamp")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(proof.Signature, "="))
+	if err != nil {
+		return errors.New("proof.signature must be base64url encoded")
+	}
+
+	vmID := proof.VerificationMethod
+	if strings.HasPrefix(vmID, "#") {
+		vmID = issuerDID + vmID
+	}
+	if !strings.HasPrefix(vmID, issuerDID+"#") {
+		return fmt.Errorf("%w: verification method %s does not belong to %s", errProofRejected, vmID, issuerDID)
+	}
+
+	doc, err := h.ledger(ctx).GetDid(ctx, issuerDID)
+	if err != nil {
+		return fmt.Errorf("%w: issuer DID could not be resolved", errProofRejected)
+	}
+	if doc.Deactivated {
+		return fmt.Errorf("%w: issuer DID is deactivated", errProofRejected)
+	}
+
+	idx := slices.IndexFunc(doc.VerificationMethod, func(vm domain.VerificationMethod) bool { return vm.ID == vmID })
+	if idx < 0 {
+		return fmt.Errorf("%w: verification method %s not found", errProofRejected, vmID)
+	}
+	// Documents without explicit relationships allow every key
+	if len(doc.AssertionMethod) > 0 && !slices.Contains(doc.AssertionMethod, vmID) {
+		return fmt.Errorf("%w: %s is not an assertionMethod of the issuer", errProofRejected, vmID)
+	}
+
+	if err := doc.VerificationMethod[idx].VerifySignature(message, signature); err != nil {
+		return fmt.Errorf("%w: %v", errProofRejected, err)
+	}
+	return nil
+}
+
+// proofErrorStatus maps a verifyProof error to its HTTP status.
+func proofErrorStatus(err error) int {
+	if errors.Is(err, errProofRejected) {
+		return http.StatusUnauthorized
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+const proofHash = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+// registerEd25519Issuer creates did:ewallet:issuer with a fresh Ed25519 key and returns the private key.
+func registerEd25519Issuer(t *testing.T, ledger fabric.LedgerClient) ed25519.PrivateKey {
+	t.Helper()
+	return registerEd25519DID(t, ledger, "did:ewallet:issuer")
+}
+
+func anchorWithProof(hash, vm string, signature []byte) string {
+	body, _ := json.Marshal(CreateAnchorRequest{
+		Hash:      hash,
+		IssuerDID: "did:ewallet:issuer",
+		Proof: &AnchorProof{
+			VerificationMethod: vm,
+			Signature:          base64.RawURLEncoding.EncodeToString(signature),
+			Created:            time.Now().UTC().Format(time.RFC3339),
+		},
+	})
+	return string(body)
+}
+
+func TestCreateAnchor_Ed25519Proof(t *testing.T) {
+	ledger := newTestLedger(t)
+	priv := registerEd25519Issuer(t, ledger)
+	router := newAnchorRouter(ledger)
+
+	signature := ed25519.Sign(priv, []byte(proofHash))
+	rec := doRequest(t, router, "POST", "/anchors", strings.NewReader(anchorWithProof(proofHash, "#key-1", signature)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !decodeBody[AnchorResponse](t, rec).SignatureVerified {
+		t.Error("expected signatureVerified on the created anchor")
+	}
+
+	if got := decodeBody[AnchorResponse](t, doRequest(t, router, "GET", "/anchors/"+proofHash, nil)); !got.SignatureVerified {
+		t.Error("GetAnchor lost signatureVerified")
+	}
+	verify := decodeBody[map[string]interface{}](t, doRequest(t, router, "GET", "/anchors/"+proofHash+"/verify", nil))
+	if verify["signatureVerified"] != true {
+		t.Errorf("VerifyAnchor lost signatureVerified: %v", verify)
+	}
+
+	// Anchors without a proof are not verified
+	unsigned := doRequest(t, router, "POST", "/anchors", strings.NewReader(`{"hash":"`+hexHash("unsigned")+`","issuerDid":"did:ewallet:issuer"}`))
+	if decodeBody[AnchorResponse](t, unsigned).SignatureVerified {
+		t.Error("anchor without a proof must not be signatureVerified")
+	}
+}
+
+func TestCreateAnchor_RejectedProofs(t *testing.T) {
+	ledger := newTestLedger(t)
+	priv := registerEd25519Issuer(t, ledger)
+	router := newAnchorRouter(ledger)
+
+	valid := ed25519.Sign(priv, []byte(proofHash))
+	tampered := append([]byte(nil), valid...)
+	tampered[0] ^= 0xff
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"tampered signature", anchorWithProof(proofHash, "#key-1", tampered), http.StatusUnauthorized},
+		{"signature over another hash", anchorWithProof(hexHash("other-hash"), "#key-1", valid), http.StatusUnauthorized},
+		{"unknown key", anchorWithProof(proofHash, "#key-9", valid), http.StatusUnauthorized},
+		{"foreign key", anchorWithProof(proofHash, "did:ewallet:other#key-1", valid), http.StatusUnauthorized},
+		{"unknown issuer", strings.Replace(anchorWithProof(proofHash, "#key-1", valid), "did:ewallet:issuer", "did:ewallet:ghost", 1), http.StatusUnauthorized},
+		{"bad encoding", strings.Replace(anchorWithProof(proofHash, "#key-1", valid), `"signature":"`, `"signature":"!!`, 1), http.StatusBadRequest},
+		{"missing created", strings.Replace(anchorWithProof(proofHash, "#key-1", valid), `"created":"`, `"created":"x`, 1), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := doRequest(t, router, "POST", "/anchors", strings.NewReader(tt.body)); rec.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	if ledger.VerifyAnchor(context.Background(), proofHash) {
+		t.Error("rejected proofs must not anchor the hash")
+	}
+}
+
+func TestCreateAnchor_P256JwkProof(t *testing.T) {
+	ledger := newTestLedger(t)
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("key generation failed: %v", err)
+	}
+	seedDids(t, ledger, &domain.DIDDocument{
+		ID: "did:ewallet:issuer",
+		VerificationMethod: []domain.VerificationMethod{{
+			ID:   "did:ewallet:issuer#key-1",
+			Type: "JsonWebKey2020",
+			PublicKeyJwk: &domain.JWK{
+				Kty: "EC",
+				Crv: "P-256",
+				X:   base64.RawURLEncoding.EncodeToString(priv.X.FillBytes(make([]byte, 32))),
+				Y:   base64.RawURLEncoding.EncodeToString(priv.Y.FillBytes(make([]byte, 32))),
+			},
+		}},
+	})
+	router := newAnchorRouter(ledger)
+
+	digest := sha256.Sum256([]byte(proofHash))
+	r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+	if err != nil {
+		t.Fatalf("signing failed: %v", err)
+	}
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+
+	rec := doRequest(t, router, "POST", "/anchors/batch", strings.NewReader("["+anchorWithProof(proofHash, "did:ewallet:issuer#key-1", signature)+","+
+		anchorWithProof(hexHash("second-hash"), "#key-1", signature)+"]"))
+	resp := decodeBody[BatchAnchorResponse](t, rec)
+	if resp.Results[0].Code != http.StatusCreated || resp.Results[1].Code != http.StatusUnauthorized {
+		t.Fatalf("unexpected batch results: %+v", resp.Results)
+	}
+	if got, _ := ledger.GetAnchor(context.Background(), proofHash); got == nil || !got.SignatureVerified {
+		t.Errorf("expected the batch anchor to be signatureVerified: %+v", got)
+	}
+}
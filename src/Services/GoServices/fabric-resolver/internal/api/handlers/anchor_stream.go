@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+// DefaultStreamKeepAlive is how often GET /anchors/stream sends a comment on an idle connection.
+const DefaultStreamKeepAlive = 15 * time.Second
+
+// DefaultStreamReplayLimit caps the anchors replayed to one GET /anchors/stream client
+// when no limit is configured.
+const DefaultStreamReplayLimit = 1000
+
+// streamReplayPage is how many anchors are read from the ledger at a time during a replay.
+const streamReplayPage = 100
+
+// AnchorStreamEvent is the data of an "anchor" event on GET /anchors/stream.
+type AnchorStreamEvent struct {
+	Hash        string `json:"hash"`
+	BlockNumber uint64 `json:"blockNumber"`
+	TxID        string `json:"txId"`
+	Timestamp   string `json:"timestamp"`
+}
+
+// GET /anchors/stream
+//
+// Streams newly created anchors as server-sent events. Each event's ID is its block
+// number; a client reconnecting with Last-Event-ID first receives the anchors of
+// later blocks from the ledger, then the live stream. The replay is read a page at
+// a time and stops after the handler's replay limit with a "truncated" event, whose
+// ID is the last block sent, and the end of the stream; the client's reconnect
+// resumes from there.
+func (h *AnchorHandler) StreamAnchors(w http.ResponseWriter, r *http.Request) {
+	var (
+		replay    bool
+		lastBlock uint64
+	)
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		var err error
+		lastBlock, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Last-Event-ID must be a block number")
+			return
+		}
+		replay = true
+	}
+
+	rc := http.NewResponseController(w)
+	// The server's write timeout would end the stream; the keep-alives detect dead clients instead
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.Warn("Failed to clear write deadline for anchor stream", "err", err)
+	}
+
+	ledger := h.ledger(r.Context())
+	// Subscribe before replaying so anchors created in between are not missed
+	live, err := ledger.SubscribeAnchors(r.Context())
+	if err != nil {
+		respondLedgerError(w, err, "Failed to subscribe to anchors")
+		return
+	}
+
+	// The first page is read before the headers are sent so a failure still gets its status
+	var page *fabric.AnchorPage
+	if replay {
+		page, err = ledger.ListAnchors(r.Context(), fabric.ListOptions{
+			Limit:  min(streamReplayPage, h.streamReplay),
+			Cursor: strconv.FormatUint(lastBlock, 10),
+		})
+		if err != nil {
+			respondLedgerError(w, err, "Failed to replay anchors")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Live anchors up to the last replayed block were part of the replay
+	var replayedThrough uint64
+	for sent := 0; page != nil; {
+		for i := range page.Items {
+			if !writeAnchorEvent(w, &page.Items[i]) {
+				return
+			}
+			replayedThrough = page.Items[i].BlockNumber
+		}
+		sent += len(page.Items)
+		if page.NextCursor == "" {
+			break
+		}
+		if sent >= h.streamReplay {
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: truncated\ndata: {\"lastBlock\":%d}\n\n", replayedThrough, replayedThrough); err == nil {
+				_ = rc.Flush()
+			}
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+		page, err = ledger.ListAnchors(r.Context(), fabric.ListOptions{
+			Limit:  min(streamReplayPage, h.streamReplay-sent),
+			Cursor: page.NextCursor,
+		})
+		if err != nil {
+			// The status is already sent; ending the stream makes the client retry from its last event
+			slog.Warn("Failed to replay anchors", "err", err)
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(h.streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case anchor, ok := <-live:
+			if !ok {
+				// The ledger client was closed
+				return
+			}
+			if anchor.BlockNumber <= replayedThrough {
+				continue
+			}
+			if !writeAnchorEvent(w, &anchor) {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeAnchorEvent writes one SSE event. It returns false once the client is gone.
+func writeAnchorEvent(w http.ResponseWriter, anchor *domain.Anchor) bool {
+	data, err := json.Marshal(AnchorStreamEvent{
+		Hash:        anchor.Hash,
+		BlockNumber: anchor.BlockNumber,
+		TxID:        anchor.TxID,
+		Timestamp:   anchor.Timestamp.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		slog.Error("Failed to encode anchor event", "err", err)
+		return true
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: anchor\ndata: %s\n\n", anchor.BlockNumber, data)
+	return err == nil
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+type sseEvent struct {
+	id, event, data string
+}
+
+// openStream connects to GET /anchors/stream and returns a channel of the events
+// received. Comments are dropped. Cancelling the returned func disconnects.
+func openStream(t *testing.T, ledger fabric.LedgerClient, opts AnchorHandlerOptions, lastEventID string) (<-chan sseEvent, context.CancelFunc) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(NewAnchorHandler(ledger, opts).StreamAnchors))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	events := make(chan sseEvent, 16)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+		var ev sseEvent
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if ev != (sseEvent{}) {
+					events <- ev
+				}
+				ev = sseEvent{}
+			case strings.HasPrefix(line, ":"):
+				events <- sseEvent{event: "comment"}
+			case strings.HasPrefix(line, "id: "):
+				ev.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				ev.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				ev.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return events, cancel
+}
+
+// nextAnchor returns the next anchor event, skipping keep-alive comments.
+func nextAnchor(t *testing.T, events <-chan sseEvent) (string, AnchorStreamEvent) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				t.Fatal("stream closed")
+			}
+			if ev.event != "anchor" {
+				continue
+			}
+			var data AnchorStreamEvent
+			if err := json.Unmarshal([]byte(ev.data), &data); err != nil {
+				t.Fatalf("invalid event data %q: %v", ev.data, err)
+			}
+			return ev.id, data
+		case <-timeout:
+			t.Fatal("timed out waiting for an anchor event")
+		}
+	}
+}
+
+func TestStreamAnchors_LiveInOrder(t *testing.T) {
+	ledger := newTestLedger(t)
+	events, cancel := openStream(t, ledger, AnchorHandlerOptions{}, "")
+
+	seedAnchors(t, ledger, 2)
+
+	for i, want := range []string{"hash-01", "hash-02"} {
+		id, ev := nextAnchor(t, events)
+		if ev.Hash != want || ev.TxID == "" || ev.Timestamp == "" {
+			t.Errorf("event %d: unexpected %+v", i, ev)
+		}
+		if id != strconv.FormatUint(ev.BlockNumber, 10) {
+			t.Errorf("event %d: id %q does not match block %d", i, id, ev.BlockNumber)
+		}
+	}
+
+	// Disconnecting ends the handler and releases the subscription
+	cancel()
+	for range events {
+	}
+}
+
+func TestStreamAnchors_ReplaysAfterLastEventID(t *testing.T) {
+	ledger := newTestLedger(t)
+	seedAnchors(t, ledger, 3)
+	first, err := ledger.GetAnchor(context.Background(), "hash-01")
+	if err != nil {
+		t.Fatalf("GetAnchor failed: %v", err)
+	}
+
+	events, _ := openStream(t, ledger, AnchorHandlerOptions{}, strconv.FormatUint(first.BlockNumber, 10))
+	if _, _, err := ledger.CreateAnchor(context.Background(), &domain.Anchor{Hash: "hash-04"}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+
+	for _, want := range []string{"hash-02", "hash-03", "hash-04"} {
+		if _, ev := nextAnchor(t, events); ev.Hash != want {
+			t.Fatalf("expected %s, got %s", want, ev.Hash)
+		}
+	}
+}
+
+func TestStreamAnchors_ReplayTruncated(t *testing.T) {
+	ledger := newTestLedger(t)
+	seedAnchors(t, ledger, 5)
+	opts := AnchorHandlerOptions{StreamReplayLimit: 2}
+
+	// Each connection replays two anchors, then tells the client where it stopped
+	lastEventID := "0"
+	for _, want := range [][]string{{"hash-01", "hash-02"}, {"hash-03", "hash-04"}} {
+		events, _ := openStream(t, ledger, opts, lastEventID)
+		for _, hash := range want {
+			if _, ev := nextAnchor(t, events); ev.Hash != hash {
+				t.Fatalf("expected %s, got %s", hash, ev.Hash)
+			}
+		}
+		ev, ok := <-events
+		if !ok || ev.event != "truncated" {
+			t.Fatalf("expected a truncated event, got %+v", ev)
+		}
+		last, _ := ledger.GetAnchor(context.Background(), want[1])
+		if ev.id != strconv.FormatUint(last.BlockNumber, 10) {
+			t.Errorf("expected the truncated event to name block %d, got %q", last.BlockNumber, ev.id)
+		}
+		if _, ok := <-events; ok {
+			t.Error("expected the stream to end after a truncated replay")
+		}
+		lastEventID = ev.id
+	}
+
+	// The rest fits, and the stream goes on live without repeating it
+	events, _ := openStream(t, ledger, opts, lastEventID)
+	if _, ev := nextAnchor(t, events); ev.Hash != "hash-05" {
+		t.Fatalf("expected hash-05, got %s", ev.Hash)
+	}
+	if _, _, err := ledger.CreateAnchor(context.Background(), &domain.Anchor{Hash: "hash-06"}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	if _, ev := nextAnchor(t, events); ev.Hash != "hash-06" {
+		t.Fatalf("expected hash-06, got %s", ev.Hash)
+	}
+}
+
+func TestStreamAnchors_KeepAlive(t *testing.T) {
+	events, _ := openStream(t, newTestLedger(t), AnchorHandlerOptions{StreamKeepAlive: 10 * time.Millisecond}, "")
+
+	select {
+	case ev := <-events:
+		if ev.event != "comment" {
+			t.Errorf("expected a keep-alive comment, got %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no keep-alive received")
+	}
+}
+
+func TestStreamAnchors_InvalidLastEventID(t *testing.T) {
+	h := NewAnchorHandler(newTestLedger(t), AnchorHandlerOptions{})
+	req := httptest.NewRequest(http.MethodGet, "/anchors/stream", nil)
+	req.Header.Set("Last-Event-ID", "not-a-block")
+	rec := httptest.NewRecorder()
+	h.StreamAnchors(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fabric-resolver/internal/audit"
+)
+
+// AuditRecord collects what only the handling of a write learns, for its audit
+// entry: the resource written, and whether the caller was an admin.
+type AuditRecord struct {
+	Resource string
+	Admin    bool
+}
+
+type auditRecordContextKey struct{}
+
+// WithAuditRecord attaches the record the audit middleware completes its entry from.
+func WithAuditRecord(ctx context.Context, record *AuditRecord) context.Context {
+	return context.WithValue(ctx, auditRecordContextKey{}, record)
+}
+
+// AuditRecordFrom returns the record attached with WithAuditRecord, or nil when the
+// request is not audited.
+func AuditRecordFrom(ctx context.Context) *AuditRecord {
+	record, _ := ctx.Value(auditRecordContextKey{}).(*AuditRecord)
+	return record
+}
+
+// SetAuditResource names the resource a write created or changed in its audit
+// entry, where the route does not name it already.
+func SetAuditResource(ctx context.Context, resource string) {
+	if record := AuditRecordFrom(ctx); record != nil {
+		record.Resource = resource
+	}
+}
+
+// AuditHandler serves the audit log to admins.
+type AuditHandler struct {
+	log *audit.Log
+}
+
+func NewAuditHandler(log *audit.Log) *AuditHandler {
+	return &AuditHandler{log: log}
+}
+
+// AuditPageResponse is a page of audit entries. NextCursor is the since of the
+// next page, absent on the last.
+type AuditPageResponse struct {
+	Items      []audit.Entry `json:"items"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
+
+// GET /admin/audit?since=&limit=
+// Returns the audit entries written after since (RFC 3339, default: the first),
+// oldest first.
+func (h *AuditHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	if h.log == nil {
+		respondError(w, http.StatusNotFound, "Audit log is disabled")
+		return
+	}
+
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "since must be an RFC 3339 time")
+			return
+		}
+		since = t
+	}
+	limit := defaultListLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			respondError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = min(n, maxListLimit)
+	}
+
+	entries, more, err := h.log.Read(since, limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to read audit log: "+err.Error())
+		return
+	}
+	resp := AuditPageResponse{Items: entries}
+	if resp.Items == nil {
+		resp.Items = []audit.Entry{}
+	}
+	if more {
+		resp.NextCursor = entries[len(entries)-1].Time.Format(time.RFC3339Nano)
+		q := r.URL.Query()
+		q.Set("since", resp.NextCursor)
+		w.Header().Add("Link", "<"+r.URL.Path+"?"+q.Encode()+`>; rel="next"`)
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CodeUnknownField rejects a body field the endpoint does not define, in strict mode.
+const CodeUnknownField = "unknown_field"
+
+// decodeJSON decodes the JSON body in r into v. In strict mode fields that v does
+// not define are rejected, so a misspelled optional field is not silently dropped.
+func decodeJSON(r io.Reader, v interface{}, strict bool) error {
+	dec := json.NewDecoder(r)
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	return dec.Decode(v)
+}
+
+// respondBodyError answers a body that could not be read or decoded: 413 when it
+// exceeded the route's size limit, 400 with the unknown field named in strict
+// mode, and otherwise 400 with code and message.
+func respondBodyError(w http.ResponseWriter, err error, code, message string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondErrorCode(w, http.StatusRequestEntityTooLarge, CodeTooLarge, "Request body exceeds "+strconv.FormatInt(tooLarge.Limit, 10)+" bytes")
+		return
+	}
+	// encoding/json has no error type for unknown fields
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		field, _ = strconv.Unquote(field)
+		respondRequestError(w, http.StatusBadRequest, fieldError(CodeUnknownField, field, errors.New("unknown field "+strconv.Quote(field))))
+		return
+	}
+	respondErrorCode(w, http.StatusBadRequest, code, message)
+}
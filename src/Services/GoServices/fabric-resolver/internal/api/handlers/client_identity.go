@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+
+	"fabric-resolver/internal/jwtauth"
+)
+
+type clientSubjectContextKey struct{}
+
+// WithClientSubject records the subject of the verified client certificate the request was made with.
+func WithClientSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, clientSubjectContextKey{}, subject)
+}
+
+// ClientSubject returns the subject of the request's client certificate, or "" when the
+// server does not require mTLS.
+func ClientSubject(ctx context.Context) string {
+	subject, _ := ctx.Value(clientSubjectContextKey{}).(string)
+	return subject
+}
+
+type apiKeyIDContextKey struct{}
+
+// WithAPIKeyID records the identifier of the API key the request was authorized with.
+func WithAPIKeyID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, apiKeyIDContextKey{}, id)
+}
+
+// APIKeyID returns the identifier of the request's API key, or "" when it carried none.
+func APIKeyID(ctx context.Context) string {
+	id, _ := ctx.Value(apiKeyIDContextKey{}).(string)
+	return id
+}
+
+type tokenClaimsContextKey struct{}
+
+// WithTokenClaims records the verified claims of the request's bearer token.
+func WithTokenClaims(ctx context.Context, claims *jwtauth.Claims) context.Context {
+	return context.WithValue(ctx, tokenClaimsContextKey{}, claims)
+}
+
+// TokenClaims returns the claims of the request's bearer token, or nil when it carried
+// no JWT or JWT authentication is off.
+func TokenClaims(ctx context.Context) *jwtauth.Claims {
+	claims, _ := ctx.Value(tokenClaimsContextKey{}).(*jwtauth.Claims)
+	return claims
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"fabric-resolver/internal/commitments"
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+// CommitmentHandler anchors HMAC commitments to documents, computed with tenant keys
+// that never leave the service. Callers commit under their own tenant only: the
+// tenant of the request, or the one their API key is assigned to.
+type CommitmentHandler struct {
+	ledgerClient fabric.LedgerClient
+	keys         *commitments.Keyring
+	// apiKeys maps API key ids to the tenant they commit for
+	apiKeys map[string]string
+}
+
+// NewCommitmentHandler returns a handler committing with keys for the tenants that
+// apiKeys assigns API key ids to. A nil keyring treats every tenant as unknown.
+func NewCommitmentHandler(ledgerClient fabric.LedgerClient, keys *commitments.Keyring, apiKeys map[string]string) *CommitmentHandler {
+	return &CommitmentHandler{ledgerClient: ledgerClient, keys: keys, apiKeys: apiKeys}
+}
+
+// ledger returns the ledger of the request's tenant, or the handler's own.
+func (h *CommitmentHandler) ledger(ctx context.Context) fabric.LedgerClient {
+	return LedgerFrom(ctx, h.ledgerClient)
+}
+
+// CommitmentRequest is the body of POST /commitments and POST /commitments/verify.
+type CommitmentRequest struct {
+	// TenantID is optional; when given it must be the caller's tenant
+	TenantID string          `json:"tenantId,omitempty"`
+	Document json.RawMessage `json:"document"` // any JSON value, canonicalized before committing
+	// Commitment, on verify only, is a hex commitment held by the caller to check against the document
+	Commitment string `json:"commitment,omitempty"`
+}
+
+// CommitmentResponse is the body of POST /commitments.
+type CommitmentResponse struct {
+	TenantID   string         `json:"tenantId"`
+	Commitment string         `json:"commitment"` // hex HMAC-SHA256, the anchored hash
+	Anchor     AnchorResponse `json:"anchor"`
+}
+
+// VerifyCommitmentResponse is the body of POST /commitments/verify. It leaves out
+// the recomputed commitment, which would let callers compute HMACs under the key.
+type VerifyCommitmentResponse struct {
+	TenantID  string `json:"tenantId"`
+	Exists    bool   `json:"exists"`
+	Valid     bool   `json:"valid"` // exists, not revoked and, if given, matching the request's commitment
+	Revoked   bool   `json:"revoked"`
+	Matches   *bool  `json:"matches,omitempty"` // whether the request's commitment is the document's
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// tenant returns the tenant the caller commits for: that of the request, or of its
+// API key. It is "" when the caller is bound to none.
+func (h *CommitmentHandler) tenant(ctx context.Context) string {
+	if tenant := Tenant(ctx); tenant != "" {
+		return tenant
+	}
+	return h.apiKeys[APIKeyID(ctx)]
+}
+
+// commit decodes the request and computes its commitment under the caller's tenant,
+// which a tenantId in the body must not contradict. It writes the error response
+// and returns false on failure.
+func (h *CommitmentHandler) commit(w http.ResponseWriter, r *http.Request) (CommitmentRequest, string, bool) {
+	var req CommitmentRequest
+	if err := decodeJSON(r.Body, &req, false); err != nil {
+		respondBodyError(w, err, CodeInvalidBody, "Invalid request body")
+		return req, "", false
+	}
+	tenant := h.tenant(r.Context())
+	if tenant == "" {
+		respondErrorCode(w, http.StatusForbidden, CodeUnknownTenant, "Caller is not assigned to a tenant")
+		return req, "", false
+	}
+	if req.TenantID != "" && req.TenantID != tenant {
+		respondErrorCode(w, http.StatusForbidden, CodeUnknownTenant, "Caller is not assigned to tenant "+req.TenantID)
+		return req, "", false
+	}
+	req.TenantID = tenant
+	if len(req.Document) == 0 || string(req.Document) == "null" {
+		respondRequestError(w, http.StatusBadRequest, fieldError(CodeMissingField, "document", errors.New("document is required")))
+		return req, "", false
+	}
+
+	commitment, err := h.keys.Commit(req.TenantID, req.Document)
+	if err != nil {
+		if errors.Is(err, commitments.ErrUnknownTenant) {
+			respondError(w, http.StatusNotFound, "Unknown tenant: "+req.TenantID)
+			return req, "", false
+		}
+		status, code := canonicalizeErrorStatus(err)
+		respondRequestError(w, status, fieldError(code, "document", err))
+		return req, "", false
+	}
+	return req, commitment, true
+}
+
+// POST /commitments
+func (h *CommitmentHandler) CreateCommitment(w http.ResponseWriter, r *http.Request) {
+	req, commitment, ok := h.commit(w, r)
+	if !ok {
+		return
+	}
+
+	SetAuditResource(r.Context(), commitment)
+	anchor := &domain.Anchor{Hash: commitment, Algorithm: domain.HashSHA256}
+	txID, blockNumber, err := h.ledger(r.Context()).CreateAnchor(r.Context(), anchor)
+	if err != nil {
+		respondLedgerError(w, err, "Failed to anchor commitment")
+		return
+	}
+	anchor.TxID = txID
+	anchor.BlockNumber = blockNumber
+
+	respondJSON(w, http.StatusCreated, CommitmentResponse{
+		TenantID:   req.TenantID,
+		Commitment: commitment,
+		Anchor:     toAnchorResponse(anchor),
+	})
+}
+
+// POST /commitments/verify
+//
+// Recomputes the commitment with the tenant's current key and reports whether it is anchored.
+// A commitment in the request is compared against the document in constant time.
+func (h *CommitmentHandler) VerifyCommitment(w http.ResponseWriter, r *http.Request) {
+	req, commitment, ok := h.commit(w, r)
+	if !ok {
+		return
+	}
+
+	resp := VerifyCommitmentResponse{TenantID: req.TenantID}
+	if req.Commitment != "" {
+		matches, err := h.keys.Verify(req.TenantID, req.Document, req.Commitment)
+		if err != nil {
+			respondRequestError(w, http.StatusBadRequest, fieldError(CodeInvalidBody, "commitment", err))
+			return
+		}
+		resp.Matches = &matches
+	}
+	anchor, err := h.ledger(r.Context()).GetAnchor(r.Context(), commitment)
+	switch {
+	case errors.Is(err, fabric.ErrNotFound), errors.Is(err, fabric.ErrExpired):
+	case err != nil:
+		respondLedgerError(w, err, "Failed to read commitment")
+		return
+	default:
+		resp.Exists = true
+		resp.Revoked = anchor.Revoked
+		resp.Valid = !anchor.Revoked && (resp.Matches == nil || *resp.Matches)
+		resp.Timestamp = anchor.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/commitments"
+	"fabric-resolver/internal/infrastructure/fabric"
+
+	"github.com/gorilla/mux"
+)
+
+func newCommitmentRouter(t *testing.T, ledger fabric.LedgerClient, keys map[string][]byte) *mux.Router {
+	t.Helper()
+	keyring, err := commitments.NewKeyring(keys)
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	h := NewCommitmentHandler(ledger, keyring, map[string]string{"key-a": "tenant-a", "key-b": "tenant-b"})
+	r := mux.NewRouter()
+	r.HandleFunc("/commitments", h.CreateCommitment).Methods("POST")
+	r.HandleFunc("/commitments/verify", h.VerifyCommitment).Methods("POST")
+	return r
+}
+
+// postCommitment posts req as the holder of API key keyID, as apiKeyAuth would
+// have identified it.
+func postCommitment(t *testing.T, router http.Handler, keyID, path string, req CommitmentRequest) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	return postCommitmentBody(t, router, keyID, path, string(body))
+}
+
+func postCommitmentBody(t *testing.T, router http.Handler, keyID, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest("POST", path, strings.NewReader(body))
+	if keyID != "" {
+		r = r.WithContext(WithAPIKeyID(r.Context(), keyID))
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestCommitments_CreateAndVerify(t *testing.T) {
+	ledger := newTestLedger(t)
+	key := bytes.Repeat([]byte{0x42}, 32)
+	router := newCommitmentRouter(t, ledger, map[string][]byte{"tenant-a": key})
+	doc := json.RawMessage(`{"name":"Alice","dob":"1990-01-01"}`)
+
+	rec := postCommitment(t, router, "key-a", "/commitments", CommitmentRequest{TenantID: "tenant-a", Document: doc})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if body := rec.Body.String(); strings.Contains(body, hex.EncodeToString(key)) || strings.Contains(body, "QkJC") {
+		t.Fatalf("response leaks the key: %s", body)
+	}
+	created := decodeBody[CommitmentResponse](t, rec)
+	if len(created.Commitment) != 64 || created.Anchor.Hash != created.Commitment || created.Anchor.TxID == "" {
+		t.Fatalf("unexpected response %+v", created)
+	}
+	// The plain hash of the document is not what gets anchored
+	if created.Commitment == hexHash(string(doc)) {
+		t.Error("expected an HMAC commitment, not a plain hash")
+	}
+
+	// Field order does not matter after canonicalization
+	rec = postCommitment(t, router, "key-a", "/commitments/verify",
+		CommitmentRequest{Document: json.RawMessage(`{"dob":"1990-01-01","name":"Alice"}`)})
+	// Verifying must not hand out HMACs of arbitrary documents
+	if strings.Contains(rec.Body.String(), created.Commitment) {
+		t.Errorf("verify response leaks the commitment: %s", rec.Body.String())
+	}
+	if verify := decodeBody[VerifyCommitmentResponse](t, rec); !verify.Exists || !verify.Valid || verify.TenantID != "tenant-a" {
+		t.Errorf("expected the commitment to verify, got %+v", verify)
+	}
+
+	other := decodeBody[VerifyCommitmentResponse](t, postCommitment(t, router, "key-a", "/commitments/verify",
+		CommitmentRequest{TenantID: "tenant-a", Document: json.RawMessage(`{"name":"Bob"}`)}))
+	if other.Exists || other.Valid {
+		t.Errorf("expected another document not to verify, got %+v", other)
+	}
+}
+
+func TestCommitments_VerifyExpectedCommitment(t *testing.T) {
+	router := newCommitmentRouter(t, newTestLedger(t), map[string][]byte{"tenant-a": bytes.Repeat([]byte{0x42}, 32)})
+	doc := json.RawMessage(`{"id":7}`)
+	created := decodeBody[CommitmentResponse](t, postCommitment(t, router, "key-a", "/commitments", CommitmentRequest{TenantID: "tenant-a", Document: doc}))
+
+	v := decodeBody[VerifyCommitmentResponse](t, postCommitment(t, router, "key-a", "/commitments/verify",
+		CommitmentRequest{TenantID: "tenant-a", Document: doc, Commitment: strings.ToUpper(created.Commitment)}))
+	if v.Matches == nil || !*v.Matches || !v.Valid {
+		t.Errorf("expected the held commitment to match, got %+v", v)
+	}
+
+	// An anchored document does not verify against another commitment
+	v = decodeBody[VerifyCommitmentResponse](t, postCommitment(t, router, "key-a", "/commitments/verify",
+		CommitmentRequest{TenantID: "tenant-a", Document: doc, Commitment: hexHash("other")}))
+	if v.Matches == nil || *v.Matches || !v.Exists || v.Valid {
+		t.Errorf("expected a mismatch, got %+v", v)
+	}
+
+	rec := postCommitment(t, router, "key-a", "/commitments/verify", CommitmentRequest{TenantID: "tenant-a", Document: doc, Commitment: created.Commitment[:10]})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected a malformed commitment to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestCommitments_TenantIsolationAndRotation(t *testing.T) {
+	ledger := newTestLedger(t)
+	keyA := bytes.Repeat([]byte{0x0a}, 32)
+	router := newCommitmentRouter(t, ledger, map[string][]byte{"tenant-a": keyA, "tenant-b": bytes.Repeat([]byte{0x0b}, 32)})
+	doc := json.RawMessage(`{"id":7}`)
+
+	if rec := postCommitment(t, router, "key-a", "/commitments", CommitmentRequest{TenantID: "tenant-a", Document: doc}); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+
+	// Another tenant's key gives another commitment, which is not anchored
+	if v := decodeBody[VerifyCommitmentResponse](t, postCommitment(t, router, "key-b", "/commitments/verify",
+		CommitmentRequest{TenantID: "tenant-b", Document: doc})); v.Exists {
+		t.Errorf("expected tenant-b not to see tenant-a's commitment, got %+v", v)
+	}
+
+	// After rotating tenant-a's key the document commits to a new value
+	rotated := newCommitmentRouter(t, ledger, map[string][]byte{"tenant-a": bytes.Repeat([]byte{0x1a}, 32)})
+	if v := decodeBody[VerifyCommitmentResponse](t, postCommitment(t, rotated, "key-a", "/commitments/verify",
+		CommitmentRequest{TenantID: "tenant-a", Document: doc})); v.Exists {
+		t.Errorf("expected the rotated key to give a new commitment, got %+v", v)
+	}
+}
+
+func TestCommitments_TenantOfCaller(t *testing.T) {
+	keys := map[string][]byte{"tenant-a": bytes.Repeat([]byte{0x0a}, 32), "tenant-b": bytes.Repeat([]byte{0x0b}, 32)}
+	router := newCommitmentRouter(t, newTestLedger(t), keys)
+	doc := json.RawMessage(`{"id":7}`)
+
+	// A caller cannot commit or verify under another tenant's key
+	for _, path := range []string{"/commitments", "/commitments/verify"} {
+		rec := postCommitment(t, router, "key-a", path, CommitmentRequest{TenantID: "tenant-b", Document: doc})
+		if rec.Code != http.StatusForbidden || decodeBody[ErrorResponse](t, rec).Code != CodeUnknownTenant {
+			t.Errorf("%s: expected 403 for another tenant, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+	}
+
+	// The tenant of the request, as the tenancy middleware sets it, is used without an API key
+	body, _ := json.Marshal(CommitmentRequest{Document: doc})
+	req := httptest.NewRequest("POST", "/commitments", bytes.NewReader(body))
+	req = req.WithContext(WithTenant(req.Context(), "tenant-b"))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated || decodeBody[CommitmentResponse](t, rec).TenantID != "tenant-b" {
+		t.Errorf("expected a commitment for the request's tenant, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCommitments_Errors(t *testing.T) {
+	router := newCommitmentRouter(t, newTestLedger(t), map[string][]byte{"tenant-a": bytes.Repeat([]byte{0x01}, 32)})
+
+	tests := []struct {
+		name   string
+		keyID  string
+		body   string
+		status int
+		code   string
+	}{
+		{"unknown tenant", "key-b", `{"document":{}}`, http.StatusNotFound, CodeNotFound},
+		{"caller without tenant", "", `{"document":{}}`, http.StatusForbidden, CodeUnknownTenant},
+		{"another tenant", "key-a", `{"tenantId":"tenant-x","document":{}}`, http.StatusForbidden, CodeUnknownTenant},
+		{"missing document", "key-a", `{"tenantId":"tenant-a"}`, http.StatusBadRequest, CodeMissingField},
+		{"invalid body", "key-a", `{"tenantId":`, http.StatusBadRequest, CodeInvalidBody},
+		{"duplicate key", "key-a", `{"tenantId":"tenant-a","document":{"a":1,"a":2}}`, http.StatusBadRequest, CodeDuplicateKey},
+	}
+	for _, tt := range tests {
+		for _, path := range []string{"/commitments", "/commitments/verify"} {
+			rec := postCommitmentBody(t, router, tt.keyID, path, tt.body)
+			if rec.Code != tt.status {
+				t.Errorf("%s %s: expected %d, got %d", tt.name, path, tt.status, rec.Code)
+				continue
+			}
+			if resp := decodeBody[ErrorResponse](t, rec); resp.Code != tt.code {
+				t.Errorf("%s %s: expected code %q, got %q", tt.name, path, tt.code, resp.Code)
+			}
+		}
+	}
+}
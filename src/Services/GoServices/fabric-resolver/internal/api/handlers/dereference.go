@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"fabric-resolver/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// didURL is a DID URL that selects one part of a DID document: a verification
+// method or service by fragment, or a service by the service parameter.
+type didURL struct {
+	did      string
+	fragment string
+	service  string
+}
+
+// selects reports whether the URL names a part of the document rather than the whole.
+func (u didURL) selects() bool {
+	return u.fragment != "" || u.service != ""
+}
+
+// didURLFromPath returns the DID URL in the {did} path variable. The router
+// matches on the escaped path, so the variable is decoded here, exactly once:
+// a DID escaped as a whole (did%3Aweb%3A...) is unescaped, while a DID sent as
+// is keeps its percent-encodings, which belong to the DID (did:web:example.com%3A8443
+// names port 8443, not the path 8443). Only %25 and %23, the "#" of a fragment,
+// are decoded in that case.
+func didURLFromPath(r *http.Request) string {
+	raw := mux.Vars(r)["did"]
+	if len(raw) >= 6 && strings.EqualFold(raw[:6], "did%3a") {
+		if decoded, err := url.PathUnescape(raw); err == nil {
+			return decoded
+		}
+		return raw
+	}
+	return strings.NewReplacer("%25", "%", "%23", "#").Replace(raw)
+}
+
+// didFromPath returns the normalized DID in the {did} path variable.
+func didFromPath(r *http.Request) string {
+	return domain.NormalizeDID(didURLFromPath(r))
+}
+
+// parseDIDURL reads the DID URL of GET /dids/{did}. Since "#" is not sent over
+// HTTP, a fragment arrives either percent-encoded in the path (%23) or in the
+// fragment query parameter. The service parameter is dereferenced without a
+// relativeRef, which is rejected.
+func parseDIDURL(pathDID string, q url.Values) (didURL, error) {
+	did, fragment, _ := strings.Cut(pathDID, "#")
+	u := didURL{did: domain.NormalizeDID(did), fragment: fragment, service: q.Get("service")}
+
+	if param := strings.TrimPrefix(q.Get("fragment"), "#"); param != "" {
+		if u.fragment != "" && u.fragment != param {
+			return u, errors.New("fragment parameter does not match the fragment of the DID URL")
+		}
+		u.fragment = param
+	}
+	if q.Has("relativeRef") {
+		return u, errors.New("relativeRef is not supported; dereference the service and resolve the reference against its endpoint")
+	}
+	if u.fragment != "" && u.service != "" {
+		return u, errors.New("a DID URL can select either a fragment or a service, not both")
+	}
+	return u, nil
+}
+
+// dereferenceDid answers with the verification method or service u selects,
+// or 404 if the document has none with that id.
+func (h *DidHandler) dereferenceDid(w http.ResponseWriter, r *http.Request, u didURL) {
+	didDoc, source, err := h.lookupDid(r.Context(), u.did)
+	if err != nil {
+		respondLookupError(w, source, err)
+		return
+	}
+	doc := toDidDocumentResponse(didDoc)
+
+	if u.service != "" {
+		for _, svc := range doc.Service {
+			if matchesFragment(svc.ID, u.did, u.service) {
+				respondJSONWithETag(w, r, "application/json", svc)
+				return
+			}
+		}
+		respondError(w, http.StatusNotFound, "Service "+u.service+" not found in "+u.did)
+		return
+	}
+
+	for _, vm := range doc.VerificationMethod {
+		if matchesFragment(vm.ID, u.did, u.fragment) {
+			respondJSONWithETag(w, r, "application/json", vm)
+			return
+		}
+	}
+	for _, svc := range doc.Service {
+		if matchesFragment(svc.ID, u.did, u.fragment) {
+			respondJSONWithETag(w, r, "application/json", svc)
+			return
+		}
+	}
+	respondError(w, http.StatusNotFound, "Fragment #"+u.fragment+" not found in "+u.did)
+}
+
+// matchesFragment reports whether id, absolute or relative, is did#fragment.
+func matchesFragment(id, did, fragment string) bool {
+	return id == did+"#"+fragment || id == "#"+fragment
+}
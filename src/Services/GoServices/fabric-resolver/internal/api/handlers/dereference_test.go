@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"fabric-resolver/internal/domain"
+)
+
+func seedFragmentDid(t *testing.T) http.Handler {
+	t.Helper()
+	ledger := newTestLedger(t)
+	seedDids(t, ledger, &domain.DIDDocument{
+		ID: "did:ewallet:frag",
+		VerificationMethod: []domain.VerificationMethod{
+			{ID: "did:ewallet:frag#key-1", Type: "Ed25519VerificationKey2020", Controller: "did:ewallet:frag", PublicKeyBase58: "abc"},
+			{ID: "did:ewallet:frag#key-2", Type: "Ed25519VerificationKey2020", Controller: "did:ewallet:frag", PublicKeyBase58: "def"},
+		},
+		Service: []domain.Service{
+			{ID: "did:ewallet:frag#messaging", Type: "DIDCommMessaging", ServiceEndpoint: "https://wallet.example.com/didcomm"},
+		},
+	})
+	return newDidRouter(ledger)
+}
+
+func TestResolveDid_KeyFragment(t *testing.T) {
+	router := seedFragmentDid(t)
+
+	for _, path := range []string{
+		"/dids/did:ewallet:frag%23key-2",
+		"/dids/did:ewallet:frag?fragment=key-2",
+		"/dids/did:ewallet:frag?fragment=%23key-2",
+	} {
+		rec := doRequest(t, router, "GET", path, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+		vm := decodeBody[VerificationMethodDto](t, rec)
+		if vm.ID != "did:ewallet:frag#key-2" || vm.PublicKeyBase58 != "def" {
+			t.Errorf("%s: expected key-2, got %+v", path, vm)
+		}
+	}
+}
+
+func TestResolveDid_ServiceFragment(t *testing.T) {
+	router := seedFragmentDid(t)
+
+	for _, path := range []string{
+		"/dids/did:ewallet:frag%23messaging",
+		"/dids/did:ewallet:frag?fragment=messaging",
+		"/dids/did:ewallet:frag?service=messaging",
+	} {
+		rec := doRequest(t, router, "GET", path, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+		svc := decodeBody[ServiceDto](t, rec)
+		if svc.ID != "did:ewallet:frag#messaging" || svc.ServiceEndpoint != "https://wallet.example.com/didcomm" {
+			t.Errorf("%s: expected the messaging service, got %+v", path, svc)
+		}
+	}
+}
+
+func TestResolveDid_UnknownFragment(t *testing.T) {
+	router := seedFragmentDid(t)
+
+	for _, path := range []string{
+		"/dids/did:ewallet:frag%23key-9",
+		"/dids/did:ewallet:frag?fragment=key-9",
+		"/dids/did:ewallet:frag?service=key-1", // a key is not a service
+		"/dids/did:ewallet:missing%23key-1",
+	} {
+		if rec := doRequest(t, router, "GET", path, nil); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestResolveDid_InvalidDIDURL(t *testing.T) {
+	router := seedFragmentDid(t)
+
+	for _, path := range []string{
+		"/dids/did:ewallet:frag%23key-1?fragment=key-2",
+		"/dids/did:ewallet:frag?service=messaging&relativeRef=/inbox",
+		"/dids/did:ewallet:frag?service=messaging&fragment=key-1",
+	} {
+		if rec := doRequest(t, router, "GET", path, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+	}
+}
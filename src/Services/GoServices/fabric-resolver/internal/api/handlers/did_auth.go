@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/pkg/canonicalizer"
+)
+
+// DefaultUpdateNonceTTL is how long a nonce from GET /dids/{did}/update-nonce is accepted.
+const DefaultUpdateNonceTTL = 5 * time.Minute
+
+// Nonces are issued to anyone who asks, so the unspent ones are capped per DID and
+// in total; past a cap GET /dids/{did}/update-nonce answers 429 until some expire.
+const (
+	maxNoncesPerDID = 16
+	maxNonces       = 10000
+)
+
+// errTooManyNonces is returned by nonceStore.issue when a cap is reached.
+var errTooManyNonces = errors.New("too many outstanding nonces")
+
+// DidUpdateProof authorizes PUT and DELETE /dids/{did} with a key from the
+// authentication relationship of the DID's current document. It signs
+//
+//	update:<did>:<nonce>:<sha256 of the canonical request body without "proof">
+//	deactivate:<did>:<nonce>
+//
+// either as a raw signature or as a detached JWS (RFC 7515 appendix F) with alg
+// EdDSA or ES256.
+type DidUpdateProof struct {
+	VerificationMethod string `json:"verificationMethod"`  // key id in the DID document, full or "#fragment"
+	Nonce              string `json:"nonce"`               // from GET /dids/{did}/update-nonce; used once
+	Signature          string `json:"signature,omitempty"` // base64url, unpadded
+	JWS                string `json:"jws,omitempty"`       // detached JWS, "<header>..<signature>"
+}
+
+// UpdateNonceResponse is the body of GET /dids/{did}/update-nonce.
+type UpdateNonceResponse struct {
+	Did       string `json:"did"`
+	Nonce     string `json:"nonce"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// nonceStore issues single-use nonces bound to a DID, at most maxPerDID of them
+// unspent per DID and maxTotal in all.
+type nonceStore struct {
+	ttl       time.Duration
+	now       func() time.Time
+	maxPerDID int
+	maxTotal  int
+
+	mu     sync.Mutex
+	nonces map[string]issuedNonce
+	perDID map[string]int
+}
+
+type issuedNonce struct {
+	did     string
+	expires time.Time
+}
+
+func newNonceStore(ttl time.Duration) *nonceStore {
+	return &nonceStore{
+		ttl:       ttl,
+		now:       time.Now,
+		maxPerDID: maxNoncesPerDID,
+		maxTotal:  maxNonces,
+		nonces:    make(map[string]issuedNonce),
+		perDID:    make(map[string]int),
+	}
+}
+
+// issue returns a new nonce for did and when it expires. It fails with
+// errTooManyNonces while did, or the store, holds as many unspent nonces as allowed.
+func (s *nonceStore) issue(did string) (string, time.Time, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, err
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(buf)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for n, issued := range s.nonces {
+		if !now.Before(issued.expires) {
+			s.remove(n, issued)
+		}
+	}
+	if s.perDID[did] >= s.maxPerDID || len(s.nonces) >= s.maxTotal {
+		return "", time.Time{}, errTooManyNonces
+	}
+	expires := now.Add(s.ttl)
+	s.nonces[nonce] = issuedNonce{did: did, expires: expires}
+	s.perDID[did]++
+	return nonce, expires, nil
+}
+
+// remove forgets nonce; s.mu must be held.
+func (s *nonceStore) remove(nonce string, issued issuedNonce) {
+	delete(s.nonces, nonce)
+	if s.perDID[issued.did]--; s.perDID[issued.did] <= 0 {
+		delete(s.perDID, issued.did)
+	}
+}
+
+// consume removes nonce and reports whether it was issued for did and has not expired.
+// A nonce is spent by the first request presenting it, whether or not its signature verifies.
+func (s *nonceStore) consume(did, nonce string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issued, ok := s.nonces[nonce]
+	if ok {
+		s.remove(nonce, issued)
+	}
+	return ok && issued.did == did && s.now().Before(issued.expires)
+}
+
+// GetUpdateNonce issues the nonce that the proof of the next update or deactivation must sign.
+func (h *DidHandler) GetUpdateNonce(w http.ResponseWriter, r *http.Request) {
+	did := didFromPath(r)
+
+	if _, ok := h.currentDocument(w, r, did); !ok {
+		return
+	}
+
+	nonce, expires, err := h.nonces.issue(did)
+	if errors.Is(err, errTooManyNonces) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(h.nonces.ttl.Seconds())))
+		respondErrorCode(w, http.StatusTooManyRequests, CodeRateLimited, "Too many outstanding nonces; use one or wait for them to expire")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to issue nonce")
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	respondJSON(w, http.StatusOK, UpdateNonceResponse{
+		Did:       did,
+		Nonce:     nonce,
+		ExpiresAt: expires.UTC().Format(time.RFC3339),
+	})
+}
+
+// currentDocument reads the document an update or deactivation applies to. It
+// writes 404 or 409 and returns false if the DID is unknown or deactivated.
+func (h *DidHandler) currentDocument(w http.ResponseWriter, r *http.Request, did string) (*domain.DIDDocument, bool) {
+	if did == "" {
+		respondError(w, http.StatusBadRequest, "DID is required")
+		return nil, false
+	}
+	doc, err := h.ledger(r.Context()).GetDid(r.Context(), did)
+	switch {
+	case errors.Is(err, fabric.ErrNotFound):
+		respondError(w, http.StatusNotFound, "DID not found")
+		return nil, false
+	case err != nil:
+		respondLedgerError(w, err, "Failed to resolve DID")
+		return nil, false
+	case doc.Deactivated:
+		respondErrorCode(w, http.StatusConflict, CodeDeactivated, "DID is deactivated")
+		return nil, false
+	}
+	return doc, true
+}
+
+// updateMessage is what the controller signs to replace the document of did with body.
+func updateMessage(did, nonce string, body []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "proof")
+	unsigned, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := canonicalizer.CanonicalizeAndHashJSON(unsigned)
+	if err != nil {
+		return nil, err
+	}
+	return []byte("update:" + did + ":" + nonce + ":" + hash), nil
+}
+
+// deactivateMessage is what the controller signs to deactivate did.
+func deactivateMessage(did, nonce string) []byte {
+	return []byte("deactivate:" + did + ":" + nonce)
+}
+
+// verifyControllerProof checks that proof signs the message built from its nonce
+// with an authentication key of doc, and spends the nonce.
+// Errors wrapping errProofRejected should be answered with 401, others with 400.
+func (h *DidHandler) verifyControllerProof(doc *domain.DIDDocument, proof *DidUpdateProof, message func(nonce string) ([]byte, error)) error {
+	if proof == nil {
+		return fmt.Errorf("%w: a proof signed by an authentication key of %s is required", errProofRejected, doc.ID)
+	}
+	if proof.VerificationMethod == "" || proof.Nonce == "" || (proof.Signature == "") == (proof.JWS == "") {
+		return errors.New("proof requires verificationMethod, nonce and one of signature or jws")
+	}
+
+	vmID := proof.VerificationMethod
+	if strings.HasPrefix(vmID, "#") {
+		vmID = doc.ID + vmID
+	}
+	idx := slices.IndexFunc(doc.VerificationMethod, func(vm domain.VerificationMethod) bool { return vm.ID == vmID })
+	if idx < 0 {
+		return fmt.Errorf("%w: verification method %s not found", errProofRejected, vmID)
+	}
+	// Documents without explicit relationships allow every key
+	if len(doc.Authentication) > 0 && !slices.Contains(doc.Authentication, vmID) {
+		return fmt.Errorf("%w: %s is not an authentication method of %s", errProofRejected, vmID, doc.ID)
+	}
+
+	if !h.nonces.consume(doc.ID, proof.Nonce) {
+		return fmt.Errorf("%w: nonce is unknown, expired or already used", errProofRejected)
+	}
+	msg, err := message(proof.Nonce)
+	if err != nil {
+		return err
+	}
+
+	vm := &doc.VerificationMethod[idx]
+	if proof.JWS != "" {
+		return verifyDetachedJWS(vm, proof.JWS, msg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(proof.Signature, "="))
+	if err != nil {
+		return errors.New("proof.signature must be base64url encoded")
+	}
+	if err := vm.VerifySignature(msg, signature); err != nil {
+		return fmt.Errorf("%w: %v", errProofRejected, err)
+	}
+	return nil
+}
+
+// verifyDetachedJWS verifies a compact JWS with a detached, base64url-encoded payload.
+func verifyDetachedJWS(vm *domain.VerificationMethod, jws string, payload []byte) error {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return errors.New("proof.jws must be a detached JWS: <header>..<signature>")
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return errors.New("proof.jws header must be base64url encoded")
+	}
+	var header struct {
+		Alg  string   `json:"alg"`
+		B64  *bool    `json:"b64"`
+		Crit []string `json:"crit"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return errors.New("proof.jws header must be a JSON object")
+	}
+	if len(header.Crit) > 0 || (header.B64 != nil && !*header.B64) {
+		return errors.New("proof.jws must not use critical header parameters or an unencoded payload")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errors.New("proof.jws signature must be base64url encoded")
+	}
+
+	key, err := vm.PublicKey()
+	if err != nil {
+		return fmt.Errorf("%w: %v", errProofRejected, err)
+	}
+	switch key.(type) {
+	case ed25519.PublicKey:
+		if header.Alg != "EdDSA" {
+			return fmt.Errorf("%w: alg %q does not match the Ed25519 key %s", errProofRejected, header.Alg, vm.ID)
+		}
+	case *ecdsa.PublicKey:
+		if header.Alg != "ES256" {
+			return fmt.Errorf("%w: alg %q does not match the P-256 key %s", errProofRejected, header.Alg, vm.ID)
+		}
+	}
+
+	signingInput := parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload)
+	if err := vm.VerifySignature([]byte(signingInput), signature); err != nil {
+		return fmt.Errorf("%w: %v", errProofRejected, err)
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/pkg/multibase"
+)
+
+// registerEd25519DID creates did with a fresh Ed25519 key, did#key-1, and returns the private key.
+func registerEd25519DID(t *testing.T, ledger fabric.LedgerClient, did string) ed25519.PrivateKey {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("key generation failed: %v", err)
+	}
+	seedDids(t, ledger, &domain.DIDDocument{
+		ID: did,
+		VerificationMethod: []domain.VerificationMethod{{
+			ID:                 did + "#key-1",
+			Type:               "Ed25519VerificationKey2020",
+			Controller:         did,
+			PublicKeyMultibase: multibase.Encode(append([]byte{0xed, 0x01}, pub...)),
+		}},
+	})
+	return priv
+}
+
+func fetchNonce(t *testing.T, router http.Handler, did string) string {
+	t.Helper()
+	rec := doRequest(t, router, "GET", "/dids/"+did+"/update-nonce", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for the nonce, got %d: %s", rec.Code, rec.Body.String())
+	}
+	return decodeBody[UpdateNonceResponse](t, rec).Nonce
+}
+
+// withProof adds proof to body, a JSON object.
+func withProof(t *testing.T, body string, proof DidUpdateProof) string {
+	t.Helper()
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &fields); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	fields["proof"] = proof
+	signed, _ := json.Marshal(fields)
+	return string(signed)
+}
+
+// signUpdate returns body with a proof by priv (key-1) over the update of did, using a fresh nonce.
+func signUpdate(t *testing.T, router http.Handler, did, body string, priv ed25519.PrivateKey) string {
+	t.Helper()
+	nonce := fetchNonce(t, router, did)
+	msg, err := updateMessage(did, nonce, []byte(body))
+	if err != nil {
+		t.Fatalf("updateMessage failed: %v", err)
+	}
+	return withProof(t, body, DidUpdateProof{
+		VerificationMethod: "#key-1",
+		Nonce:              nonce,
+		Signature:          base64.RawURLEncoding.EncodeToString(ed25519.Sign(priv, msg)),
+	})
+}
+
+// signDeactivate returns a DELETE body with a proof by priv (key-1), using a fresh nonce.
+func signDeactivate(t *testing.T, router http.Handler, did string, priv ed25519.PrivateKey) string {
+	t.Helper()
+	nonce := fetchNonce(t, router, did)
+	return withProof(t, `{}`, DidUpdateProof{
+		VerificationMethod: "#key-1",
+		Nonce:              nonce,
+		Signature:          base64.RawURLEncoding.EncodeToString(ed25519.Sign(priv, deactivateMessage(did, nonce))),
+	})
+}
+
+func TestUpdateDid_ControllerProofFlow(t *testing.T) {
+	ledger := newTestLedger(t)
+	router := newDidRouter(ledger)
+
+	// Create through the API with an Ed25519 key used for authentication
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	create := `{"did":"did:ewallet:owned","verificationMethod":[{"type":"Ed25519VerificationKey2020","publicKeyMultibase":"` +
+		multibase.Encode(append([]byte{0xed, 0x01}, pub...)) + `"}],"authentication":[0]}`
+	if rec := doRequest(t, router, "POST", "/dids", strings.NewReader(create)); rec.Code != http.StatusCreated {
+		t.Fatalf("create failed: %d %s", rec.Code, rec.Body.String())
+	}
+
+	update := `{"controller":"did:ewallet:new-owner","verificationMethod":[{"type":"Ed25519VerificationKey2020","publicKeyMultibase":"` +
+		multibase.Encode(append([]byte{0xed, 0x01}, pub...)) + `"}]}`
+
+	// The right key is accepted
+	signed := signUpdate(t, router, "did:ewallet:owned", update, priv)
+	if rec := doRequest(t, router, "PUT", "/dids/did:ewallet:owned", strings.NewReader(signed)); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for the controller's update, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if doc := decodeBody[DidDocumentResponse](t, doRequest(t, router, "GET", "/dids/did:ewallet:owned", nil)); doc.Controller != "did:ewallet:new-owner" {
+		t.Errorf("update not applied: %+v", doc)
+	}
+
+	// Replaying the same request reuses a spent nonce
+	rec := doRequest(t, router, "PUT", "/dids/did:ewallet:owned", strings.NewReader(signed))
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "nonce") {
+		t.Errorf("expected 401 for a replayed nonce, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Another key is rejected
+	_, other, _ := ed25519.GenerateKey(rand.Reader)
+	forged := signUpdate(t, router, "did:ewallet:owned", `{"controller":"did:ewallet:attacker"}`, other)
+	if rec := doRequest(t, router, "PUT", "/dids/did:ewallet:owned", strings.NewReader(forged)); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a signature by another key, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// A signature over another body does not authorize this one
+	signedOther := signUpdate(t, router, "did:ewallet:owned", `{"controller":"did:ewallet:new-owner"}`, priv)
+	var tampered map[string]interface{}
+	json.Unmarshal([]byte(signedOther), &tampered)
+	tampered["controller"] = "did:ewallet:attacker"
+	body, _ := json.Marshal(tampered)
+	if rec := doRequest(t, router, "PUT", "/dids/did:ewallet:owned", strings.NewReader(string(body))); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a tampered body, got %d", rec.Code)
+	}
+
+	// Unsigned requests are rejected
+	if rec := doRequest(t, router, "PUT", "/dids/did:ewallet:owned", strings.NewReader(update)); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a proof, got %d", rec.Code)
+	}
+
+	// Deactivation needs a proof too, and a nonce of its own DID
+	if rec := doRequest(t, router, "DELETE", "/dids/did:ewallet:owned", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for an unsigned deactivation, got %d", rec.Code)
+	}
+	if rec := doRequest(t, router, "DELETE", "/dids/did:ewallet:owned", strings.NewReader(signDeactivate(t, router, "did:ewallet:owned", priv))); rec.Code != http.StatusOK {
+		t.Errorf("expected 200 for the controller's deactivation, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestUpdateDid_ProofRequiresAuthenticationKey(t *testing.T) {
+	ledger := newTestLedger(t)
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	authPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	seedDids(t, ledger, &domain.DIDDocument{
+		ID: "did:ewallet:split",
+		VerificationMethod: []domain.VerificationMethod{
+			{ID: "did:ewallet:split#key-1", Type: "Ed25519VerificationKey2020", PublicKeyMultibase: multibase.Encode(append([]byte{0xed, 0x01}, pub...))},
+			{ID: "did:ewallet:split#key-2", Type: "Ed25519VerificationKey2020", PublicKeyMultibase: multibase.Encode(append([]byte{0xed, 0x01}, authPub...))},
+		},
+		Authentication: []string{"did:ewallet:split#key-2"},
+	})
+	router := newDidRouter(ledger)
+
+	// key-1 signs correctly but is only an assertion key
+	rec := doRequest(t, router, "PUT", "/dids/did:ewallet:split", strings.NewReader(signUpdate(t, router, "did:ewallet:split", `{}`, priv)))
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "authentication") {
+		t.Errorf("expected 401 for a non-authentication key, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestUpdateDid_DetachedJWS(t *testing.T) {
+	ledger := newTestLedger(t)
+	priv := registerEd25519DID(t, ledger, "did:ewallet:jws")
+	router := newDidRouter(ledger)
+
+	sign := func(alg string) string {
+		nonce := fetchNonce(t, router, "did:ewallet:jws")
+		msg, _ := updateMessage("did:ewallet:jws", nonce, []byte(`{}`))
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"` + alg + `"}`))
+		signature := ed25519.Sign(priv, []byte(header+"."+base64.RawURLEncoding.EncodeToString(msg)))
+		return withProof(t, `{}`, DidUpdateProof{
+			VerificationMethod: "did:ewallet:jws#key-1",
+			Nonce:              nonce,
+			JWS:                header + ".." + base64.RawURLEncoding.EncodeToString(signature),
+		})
+	}
+
+	if rec := doRequest(t, router, "PUT", "/dids/did:ewallet:jws", strings.NewReader(sign("EdDSA"))); rec.Code != http.StatusOK {
+		t.Errorf("expected 200 for a detached JWS, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, router, "PUT", "/dids/did:ewallet:jws", strings.NewReader(sign("ES256"))); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for an alg that does not match the key, got %d", rec.Code)
+	}
+}
+
+func TestUpdateNonce(t *testing.T) {
+	ledger := newTestLedger(t)
+	registerEd25519DID(t, ledger, "did:ewallet:n")
+	router := newDidRouter(ledger)
+
+	rec := doRequest(t, router, "GET", "/dids/did:ewallet:n/update-nonce", nil)
+	resp := decodeBody[UpdateNonceResponse](t, rec)
+	if rec.Header().Get("Cache-Control") != "no-store" || resp.Did != "did:ewallet:n" || len(resp.Nonce) < 32 {
+		t.Errorf("unexpected nonce response %+v", resp)
+	}
+	if second := fetchNonce(t, router, "did:ewallet:n"); second == resp.Nonce {
+		t.Error("expected a new nonce per request")
+	}
+	if rec := doRequest(t, router, "GET", "/dids/did:ewallet:missing/update-nonce", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown DID, got %d", rec.Code)
+	}
+
+	// Unspent nonces are capped per DID
+	for range maxNoncesPerDID - 2 {
+		fetchNonce(t, router, "did:ewallet:n")
+	}
+	rec = doRequest(t, router, "GET", "/dids/did:ewallet:n/update-nonce", nil)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("expected 429 with Retry-After past the cap, got %d", rec.Code)
+	}
+}
+
+// staleDidLedger answers GetDid with doc, as read before a concurrent change.
+type staleDidLedger struct {
+	fabric.LedgerClient
+	doc *domain.DIDDocument
+}
+
+func (l staleDidLedger) GetDid(ctx context.Context, did string) (*domain.DIDDocument, error) {
+	doc := *l.doc
+	return &doc, nil
+}
+
+func TestUpdateDid_ConcurrentRotation(t *testing.T) {
+	ledger := newTestLedger(t)
+	const did = "did:ewallet:rotated"
+	priv := registerEd25519DID(t, ledger, did)
+	before, _ := ledger.GetDid(t.Context(), did)
+	router := newDidRouter(staleDidLedger{LedgerClient: ledger, doc: before})
+
+	update := signUpdate(t, router, did, `{"controller":"did:ewallet:attacker"}`, priv)
+	deactivate := signDeactivate(t, router, did, priv)
+
+	// key-1 is rotated out after the handler read the document it verifies against
+	rotated := &domain.DIDDocument{ID: did, VerificationMethod: []domain.VerificationMethod{{ID: did + "#key-2", Type: "Ed25519VerificationKey2020", Controller: did}}}
+	if err := ledger.UpdateDid(t.Context(), rotated, before.VersionID); err != nil {
+		t.Fatalf("rotation failed: %v", err)
+	}
+
+	for method, body := range map[string]string{"PUT": update, "DELETE": deactivate} {
+		rec := doRequest(t, router, method, "/dids/"+did, strings.NewReader(body))
+		if resp := decodeBody[ErrorResponse](t, rec); rec.Code != http.StatusConflict || resp.Code != CodeConflict {
+			t.Errorf("%s: expected 409 %s for a proof by a rotated key, got %d %+v", method, CodeConflict, rec.Code, resp)
+		}
+	}
+	if doc, _ := ledger.GetDid(t.Context(), did); doc.Controller != "" || doc.Deactivated {
+		t.Errorf("stale proof changed the document: %+v", doc)
+	}
+}
+
+func TestNonceStore_SingleUseAndExpiry(t *testing.T) {
+	store := newNonceStore(time.Minute)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	nonce, _, err := store.issue("did:ewallet:a")
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	if store.consume("did:ewallet:b", nonce) {
+		t.Error("expected a nonce of another DID to be rejected")
+	}
+	// The failed attempt spent it
+	if store.consume("did:ewallet:a", nonce) {
+		t.Error("expected a nonce to be usable once")
+	}
+
+	nonce, _, _ = store.issue("did:ewallet:a")
+	now = now.Add(time.Minute)
+	if store.consume("did:ewallet:a", nonce) {
+		t.Error("expected an expired nonce to be rejected")
+	}
+}
+
+func TestNonceStore_Caps(t *testing.T) {
+	store := newNonceStore(time.Minute)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	store.maxPerDID, store.maxTotal = 2, 3
+
+	first, _, _ := store.issue("did:ewallet:a")
+	store.issue("did:ewallet:a")
+	if _, _, err := store.issue("did:ewallet:a"); !errors.Is(err, errTooManyNonces) {
+		t.Fatalf("expected the per-DID cap, got %v", err)
+	}
+	if _, _, err := store.issue("did:ewallet:b"); err != nil {
+		t.Fatalf("expected another DID to get a nonce, got %v", err)
+	}
+	if _, _, err := store.issue("did:ewallet:c"); !errors.Is(err, errTooManyNonces) {
+		t.Fatalf("expected the total cap, got %v", err)
+	}
+
+	// Spending or expiring nonces makes room again
+	store.consume("did:ewallet:a", first)
+	if _, _, err := store.issue("did:ewallet:a"); err != nil {
+		t.Errorf("expected room after a nonce was spent, got %v", err)
+	}
+	now = now.Add(time.Minute)
+	if _, _, err := store.issue("did:ewallet:c"); err != nil || len(store.nonces) != 1 || len(store.perDID) != 1 {
+		t.Errorf("expected expired nonces to be forgotten, got %v with %d left", err, len(store.nonces))
+	}
+}
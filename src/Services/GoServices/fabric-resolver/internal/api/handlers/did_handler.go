@@ -1,35 +1,99 @@
 package handlers
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"fabric-resolver/internal/domain"
 	"fabric-resolver/internal/infrastructure/fabric"
-
-	"github.com/gorilla/mux"
+	"fabric-resolver/internal/pkg/didweb"
+	"fabric-resolver/internal/webhooks"
 )
 
 type DidHandler struct {
 	ledgerClient fabric.LedgerClient // Brug interface
+	validator    *domain.DIDValidator
+	webResolver  *didweb.Resolver
+	webhooks     *webhooks.Dispatcher
+	nonces       *nonceStore
+	strictJSON   bool
 }
 
-func NewDidHandler(ledgerClient fabric.LedgerClient) *DidHandler {
-	return &DidHandler{ledgerClient: ledgerClient}
+// DidHandlerOptions configures a DidHandler; the zero value is usable.
+type DidHandlerOptions struct {
+	// Validator checks DIDs on create. Nil accepts the default DID methods.
+	Validator *domain.DIDValidator
+
+	// WebResolver fetches did:web documents that are not on the ledger. Nil disables outbound resolution.
+	WebResolver *didweb.Resolver
+
+	// Webhooks is notified of created DIDs. Nil sends no notifications.
+	Webhooks *webhooks.Dispatcher
+
+	// UpdateNonceTTL is how long an update nonce is accepted. Zero uses DefaultUpdateNonceTTL.
+	UpdateNonceTTL time.Duration
+
+	// StrictJSON rejects request bodies with fields the endpoint does not define.
+	StrictJSON bool
+}
 
+func NewDidHandler(ledgerClient fabric.LedgerClient, opts DidHandlerOptions) *DidHandler {
+	if opts.Validator == nil {
+		opts.Validator = domain.NewDIDValidator(nil)
+	}
+	if opts.UpdateNonceTTL <= 0 {
+		opts.UpdateNonceTTL = DefaultUpdateNonceTTL
+	}
+	return &DidHandler{
+		ledgerClient: ledgerClient,
+		validator:    opts.Validator,
+		webResolver:  opts.WebResolver,
+		webhooks:     opts.Webhooks,
+		nonces:       newNonceStore(opts.UpdateNonceTTL),
+		strictJSON:   opts.StrictJSON,
+	}
+}
+
+// ledger returns the ledger of the request's tenant, or the handler's own.
+func (h *DidHandler) ledger(ctx context.Context) fabric.LedgerClient {
+	return LedgerFrom(ctx, h.ledgerClient)
 }
 
 type CreateDidRequest struct {
+	Context            []string                    `json:"@context,omitempty"` // extra JSON-LD contexts; the DID core context is always first
 	Did                string                      `json:"did"`
 	Controller         string                      `json:"controller,omitempty"`
 	VerificationMethod []VerificationMethodRequest `json:"verificationMethod"`
+	Service            []ServiceRequest            `json:"service,omitempty"`
+
+	// Authentication and AssertionMethod are 0-based indices into VerificationMethod.
+	// When omitted, every key is used for that relationship.
+	Authentication  []int `json:"authentication,omitempty"`
+	AssertionMethod []int `json:"assertionMethod,omitempty"`
 }
 
+// VerificationMethodRequest describes one key. PublicKeyJwk is a JWK object;
+// the older JSON-encoded string form is still accepted but deprecated.
 type VerificationMethodRequest struct {
+	Type               string      `json:"type"`
+	PublicKeyJwk       *domain.JWK `json:"publicKeyJwk,omitempty"`
+	PublicKeyBase58    string      `json:"publicKeyBase58,omitempty"`
+	PublicKeyMultibase string      `json:"publicKeyMultibase,omitempty"`
+}
+
+// ServiceRequest describes a service endpoint. ID is the fragment after "#";
+// it defaults to service-N.
+type ServiceRequest struct {
+	ID              string `json:"id,omitempty"`
 	Type            string `json:"type"`
-	PublicKeyJwk    string `json:"publicKeyJwk,omitempty"`
-	PublicKeyBase58 string `json:"publicKeyBase58,omitempty"`
+	ServiceEndpoint string `json:"serviceEndpoint"`
 }
 
 type DidDocumentResponse struct {
@@ -39,34 +103,41 @@ type DidDocumentResponse struct {
 	VerificationMethod []VerificationMethodDto `json:"verificationMethod"`
 	Authentication     []string                `json:"authentication,omitempty"`
 	AssertionMethod    []string                `json:"assertionMethod,omitempty"`
-	Created            string                  `json:"created"`
-	Updated            string                  `json:"updated"`
+	Service            []ServiceDto            `json:"service,omitempty"`
+	Created            string                  `json:"created,omitempty"`
+	Updated            string                  `json:"updated,omitempty"`
+	Deactivated        bool                    `json:"deactivated,omitempty"`
+	DeactivatedAt      string                  `json:"deactivatedAt,omitempty"`
+}
+
+// DidPageResponse is one page of DID documents from GET /dids.
+type DidPageResponse struct {
+	Items      []DidDocumentResponse `json:"items"`
+	NextCursor string                `json:"nextCursor,omitempty"`
+	Total      int                   `json:"total"`
 }
 
 type VerificationMethodDto struct {
+	ID                 string      `json:"id"`
+	Type               string      `json:"type"`
+	Controller         string      `json:"controller"`
+	PublicKeyJwk       *domain.JWK `json:"publicKeyJwk,omitempty"`
+	PublicKeyBase58    string      `json:"publicKeyBase58,omitempty"`
+	PublicKeyMultibase string      `json:"publicKeyMultibase,omitempty"`
+}
+
+type ServiceDto struct {
 	ID              string `json:"id"`
 	Type            string `json:"type"`
-	Controller      string `json:"controller"`
-	PublicKeyJwk    string `json:"publicKeyJwk,omitempty"`
-	PublicKeyBase58 string `json:"publicKeyBase58,omitempty"`
+	ServiceEndpoint string `json:"serviceEndpoint"`
 }
 
-// CreateDid registers a new DID on the blockchain
-func (h *DidHandler) CreateDid(w http.ResponseWriter, r *http.Request) {
-	var req CreateDidRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body")
-		return
-	}
-
-	if req.Did == "" {
-		respondError(w, http.StatusBadRequest, "DID is required")
-		return
-	}
-
-	// Convert to domain model
+// ToDIDDocument converts the request to the domain model, numbering keys as <did>#key-N
+// and services without an id as <did>#service-N. It fails if a relationship
+// references a verification method index that does not exist.
+func (req CreateDidRequest) ToDIDDocument() (*domain.DIDDocument, error) {
 	didDoc := &domain.DIDDocument{
-		Context:            []string{"https://www.w3.org/ns/did/v1"},
+		Context:            normalizeContext(req.Context),
 		ID:                 req.Did,
 		Controller:         req.Controller,
 		VerificationMethod: make([]domain.VerificationMethod, len(req.VerificationMethod)),
@@ -74,19 +145,79 @@ func (h *DidHandler) CreateDid(w http.ResponseWriter, r *http.Request) {
 
 	for i, vm := range req.VerificationMethod {
 		didDoc.VerificationMethod[i] = domain.VerificationMethod{
-			ID:              req.Did + "#key-" + strconv.Itoa(i+1),
-			Type:            vm.Type,
-			Controller:      req.Did,
-			PublicKeyJwk:    vm.PublicKeyJwk,
-			PublicKeyBase58: vm.PublicKeyBase58,
+			ID:                 req.Did + "#key-" + strconv.Itoa(i+1),
+			Type:               vm.Type,
+			Controller:         req.Did,
+			PublicKeyJwk:       vm.PublicKeyJwk,
+			PublicKeyBase58:    vm.PublicKeyBase58,
+			PublicKeyMultibase: vm.PublicKeyMultibase,
 		}
 	}
 
+	if len(req.Service) > 0 {
+		didDoc.Service = make([]domain.Service, len(req.Service))
+	}
+	for i, svc := range req.Service {
+		fragment := strings.TrimPrefix(svc.ID, "#")
+		if fragment == "" {
+			fragment = "service-" + strconv.Itoa(i+1)
+		}
+		didDoc.Service[i] = domain.Service{
+			ID:              req.Did + "#" + fragment,
+			Type:            svc.Type,
+			ServiceEndpoint: svc.ServiceEndpoint,
+		}
+	}
+
+	var err error
+	if didDoc.Authentication, err = relationshipIDs("authentication", req.Authentication, didDoc.VerificationMethod); err != nil {
+		return nil, err
+	}
+	if didDoc.AssertionMethod, err = relationshipIDs("assertionMethod", req.AssertionMethod, didDoc.VerificationMethod); err != nil {
+		return nil, err
+	}
+
+	return didDoc, nil
+}
+
+// relationshipIDs maps verification method indices to their IDs.
+func relationshipIDs(name string, indices []int, methods []domain.VerificationMethod) ([]string, error) {
+	if len(indices) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(indices))
+	for i, idx := range indices {
+		if idx < 0 || idx >= len(methods) {
+			return nil, fieldError(CodeInvalidRelationship, fmt.Sprintf("%s[%d]", name, i),
+				fmt.Errorf("%s[%d]: verification method index %d does not exist", name, i, idx))
+		}
+		ids[i] = methods[idx].ID
+	}
+	return ids, nil
+}
+
+// CreateDid registers a new DID on the blockchain
+func (h *DidHandler) CreateDid(w http.ResponseWriter, r *http.Request) {
+	var req CreateDidRequest
+	if err := decodeJSON(r.Body, &req, h.strictJSON); err != nil {
+		respondBodyError(w, err, CodeInvalidBody, "Invalid request body")
+		return
+	}
+
+	req.Did = domain.NormalizeDID(req.Did)
+	SetAuditResource(r.Context(), req.Did)
+	didDoc, err := NewDIDDocument(h.validator, req)
+	if err != nil {
+		respondRequestError(w, http.StatusBadRequest, err)
+		return
+	}
+
 	// Store on Fabric
-	if err := h.ledgerClient.CreateDid(r.Context(), didDoc); err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to create DID: "+err.Error())
+	if err := h.ledger(r.Context()).CreateDid(r.Context(), didDoc); err != nil {
+		respondLedgerError(w, err, "Failed to create DID")
 		return
 	}
+	h.webhooks.DIDCreated(r.Context(), Tenant(r.Context()), didDoc)
 
 	response := map[string]interface{}{
 		"did":     req.Did,
@@ -97,53 +228,269 @@ func (h *DidHandler) CreateDid(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusCreated, response)
 }
 
-// ResolveDid retrieves a DID Document from the blockchain
-func (h *DidHandler) ResolveDid(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	did := vars["did"]
+// UpdateDidRequest is the body of PUT /dids/{did}: the new document and the
+// controller's proof authorizing it.
+type UpdateDidRequest struct {
+	CreateDidRequest
+	Proof *DidUpdateProof `json:"proof,omitempty"`
+}
+
+// DeactivateDidRequest is the body of DELETE /dids/{did}.
+type DeactivateDidRequest struct {
+	Proof *DidUpdateProof `json:"proof,omitempty"`
+}
+
+// UpdateDid replaces the verification methods of an existing DID.
+// The DID is taken from the path; a did in the body must match it. The request
+// must be signed by an authentication key of the current document, and is answered
+// 409 if the document changes before it is written.
+func (h *DidHandler) UpdateDid(w http.ResponseWriter, r *http.Request) {
+	did := didFromPath(r)
+	SetAuditResource(r.Context(), did)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		respondBodyError(w, err, CodeInvalidBody, "Invalid request body")
+		return
+	}
+	var req UpdateDidRequest
+	if err := decodeJSON(bytes.NewReader(body), &req, h.strictJSON); err != nil {
+		respondBodyError(w, err, CodeInvalidBody, "Invalid request body")
+		return
+	}
+
+	if did == "" {
+		respondError(w, http.StatusBadRequest, "DID is required")
+		return
+	}
+	if req.Did != "" && domain.NormalizeDID(req.Did) != did {
+		respondError(w, http.StatusBadRequest, "DID in body does not match the URL")
+		return
+	}
+	req.Did = did
+
+	didDoc, err := req.ToDIDDocument()
+	if err != nil {
+		respondRequestError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := validateDIDDocument(h.validator, didDoc); err != nil {
+		respondRequestError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	current, ok := h.currentDocument(w, r, did)
+	if !ok {
+		return
+	}
+	err = h.verifyControllerProof(current, req.Proof, func(nonce string) ([]byte, error) {
+		return updateMessage(did, nonce, body)
+	})
+	if err != nil {
+		respondError(w, proofErrorStatus(err), err.Error())
+		return
+	}
+
+	// The proof was checked against current; a document changed since then may no longer trust its key
+	if err := h.ledger(r.Context()).UpdateDid(r.Context(), didDoc, current.VersionID); err != nil {
+		if errors.Is(err, fabric.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "DID not found")
+			return
+		}
+		if errors.Is(err, fabric.ErrDeactivated) {
+			respondErrorCode(w, http.StatusConflict, CodeDeactivated, "DID is deactivated")
+			return
+		}
+		if errors.Is(err, fabric.ErrAlreadyExists) {
+			respondErrorCode(w, http.StatusConflict, CodeConflict, "DID was changed concurrently; sign the update against its current document")
+			return
+		}
+		respondLedgerError(w, err, "Failed to update DID")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, toDidDocumentResponse(didDoc))
+}
+
+// DeactivateDid marks a DID as deactivated. It keeps resolving with "deactivated": true.
+// The request must be signed by an authentication key of the current document.
+func (h *DidHandler) DeactivateDid(w http.ResponseWriter, r *http.Request) {
+	did := didFromPath(r)
+	SetAuditResource(r.Context(), did)
+
+	var req DeactivateDidRequest
+	if err := decodeJSON(r.Body, &req, h.strictJSON); err != nil && !errors.Is(err, io.EOF) {
+		respondBodyError(w, err, CodeInvalidBody, "Invalid request body")
+		return
+	}
+
+	current, ok := h.currentDocument(w, r, did)
+	if !ok {
+		return
+	}
+	err := h.verifyControllerProof(current, req.Proof, func(nonce string) ([]byte, error) {
+		return deactivateMessage(did, nonce), nil
+	})
+	if err != nil {
+		respondError(w, proofErrorStatus(err), err.Error())
+		return
+	}
+
+	if err := h.ledger(r.Context()).DeactivateDid(r.Context(), did, current.VersionID); err != nil {
+		if errors.Is(err, fabric.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "DID not found")
+			return
+		}
+		if errors.Is(err, fabric.ErrDeactivated) {
+			respondErrorCode(w, http.StatusConflict, CodeDeactivated, "DID is already deactivated")
+			return
+		}
+		if errors.Is(err, fabric.ErrAlreadyExists) {
+			respondErrorCode(w, http.StatusConflict, CodeConflict, "DID was changed concurrently; sign the deactivation against its current document")
+			return
+		}
+		respondLedgerError(w, err, "Failed to deactivate DID")
+		return
+	}
+
+	response := map[string]interface{}{
+		"did":    did,
+		"status": "deactivated",
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
 
+// ResolveDid retrieves a DID Document from the blockchain. A DID URL with a
+// fragment or service parameter returns just the verification method or service it names.
+func (h *DidHandler) ResolveDid(w http.ResponseWriter, r *http.Request) {
+	u, err := parseDIDURL(didURLFromPath(r), r.URL.Query())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	did := u.did
 	if did == "" {
 		respondError(w, http.StatusBadRequest, "DID is required")
 		return
 	}
 
-	// Query from Fabric
-	didDoc, err := h.ledgerClient.GetDid(r.Context(), did)
+	if u.selects() {
+		h.dereferenceDid(w, r, u)
+		return
+	}
+
+	if wantsResolutionResult(r) {
+		h.resolveDidResult(w, r, did)
+		return
+	}
+
+	didDoc, source, err := h.lookupDid(r.Context(), did)
 	if err != nil {
-		respondError(w, http.StatusNotFound, "DID not found")
+		respondLookupError(w, source, err)
 		return
 	}
 
-	// Convert to response DTO
+	respondJSONWithETag(w, r, negotiateDidContentType(r), toDidDocumentResponse(didDoc))
+}
+
+// respondLookupError answers a failed lookupDid.
+func respondLookupError(w http.ResponseWriter, source string, err error) {
+	if source == SourceDidKey {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if source == SourceDidWeb && !IsDidNotFound(err) {
+		respondError(w, http.StatusBadGateway, "Failed to resolve did:web: "+err.Error())
+		return
+	}
+	respondError(w, http.StatusNotFound, "DID not found")
+}
+
+// GET /dids?controller=&limit=&cursor=
+func (h *DidHandler) ListDids(w http.ResponseWriter, r *http.Request) {
+	listOpts, ok := parseListOptions(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.ledger(r.Context()).ListDids(r.Context(), fabric.DidListOptions{
+		ListOptions: listOpts,
+		Controller:  r.URL.Query().Get("controller"),
+	})
+	if err != nil {
+		if errors.Is(err, fabric.ErrValidation) {
+			respondError(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+		respondLedgerError(w, err, "Failed to list DIDs")
+		return
+	}
+
+	resp := DidPageResponse{
+		Items:      make([]DidDocumentResponse, len(page.Items)),
+		NextCursor: encodeCursor(page.NextCursor),
+		Total:      page.Total,
+	}
+	for i := range page.Items {
+		resp.Items[i] = toDidDocumentResponse(&page.Items[i])
+	}
+
+	setNextLink(w, r, resp.NextCursor)
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// toDidDocumentResponse converts a domain DID document to the response DTO
+func toDidDocumentResponse(didDoc *domain.DIDDocument) DidDocumentResponse {
 	response := DidDocumentResponse{
-		Context:            didDoc.Context,
+		Context:            normalizeContext(didDoc.Context),
 		ID:                 didDoc.ID,
 		Controller:         didDoc.Controller,
 		VerificationMethod: make([]VerificationMethodDto, len(didDoc.VerificationMethod)),
-		Created:            didDoc.Created.Format("2006-01-02T15:04:05Z"),
-		Updated:            didDoc.Updated.Format("2006-01-02T15:04:05Z"),
+		Deactivated:        didDoc.Deactivated,
+	}
+	// Derived documents (did:key) have no creation time
+	if !didDoc.Created.IsZero() {
+		response.Created = didDoc.Created.Format("2006-01-02T15:04:05Z")
+		response.Updated = didDoc.Updated.Format("2006-01-02T15:04:05Z")
+	}
+	if didDoc.DeactivatedAt != nil {
+		response.DeactivatedAt = didDoc.DeactivatedAt.Format("2006-01-02T15:04:05Z")
 	}
 
-	// Build authentication and assertion method lists
-	authMethods := make([]string, 0, len(didDoc.VerificationMethod))
-	assertionMethods := make([]string, 0, len(didDoc.VerificationMethod))
+	// Documents stored without relationships (older records) use every key for both
+	authMethods := didDoc.Authentication
+	assertionMethods := didDoc.AssertionMethod
+	allKeys := make([]string, 0, len(didDoc.VerificationMethod))
 
 	for i, vm := range didDoc.VerificationMethod {
 		response.VerificationMethod[i] = VerificationMethodDto{
-			ID:              vm.ID,
-			Type:            vm.Type,
-			Controller:      vm.Controller,
-			PublicKeyJwk:    vm.PublicKeyJwk,
-			PublicKeyBase58: vm.PublicKeyBase58,
+			ID:                 vm.ID,
+			Type:               vm.Type,
+			Controller:         vm.Controller,
+			PublicKeyJwk:       vm.PublicKeyJwk,
+			PublicKeyBase58:    vm.PublicKeyBase58,
+			PublicKeyMultibase: vm.PublicKeyMultibase,
 		}
+		allKeys = append(allKeys, vm.ID)
+	}
+	if len(authMethods) == 0 {
+		authMethods = allKeys
+	}
+	if len(assertionMethods) == 0 {
+		assertionMethods = allKeys
+	}
 
-		// Add to authentication and assertion methods
-		authMethods = append(authMethods, vm.ID)
-		assertionMethods = append(assertionMethods, vm.ID)
+	for _, svc := range didDoc.Service {
+		response.Service = append(response.Service, ServiceDto{
+			ID:              svc.ID,
+			Type:            svc.Type,
+			ServiceEndpoint: svc.ServiceEndpoint,
+		})
 	}
 
 	response.Authentication = authMethods
 	response.AssertionMethod = assertionMethods
 
-	respondJSON(w, http.StatusOK, response)
+	return response
 }
@@ -0,0 +1,459 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+
+	"github.com/gorilla/mux"
+)
+
+// newDidRouter mounts the DID routes the same way api.NewRouter does.
+func newDidRouter(ledger fabric.LedgerClient) *mux.Router {
+	return newDidRouterWith(ledger, DidHandlerOptions{})
+}
+
+func newDidRouterWith(ledger fabric.LedgerClient, opts DidHandlerOptions) *mux.Router {
+	h := NewDidHandler(ledger, opts)
+	r := mux.NewRouter().UseEncodedPath()
+	r.HandleFunc("/dids", h.CreateDid).Methods("POST")
+	r.HandleFunc("/dids", h.ListDids).Methods("GET")
+	r.HandleFunc("/dids/{did:.*}/update-nonce", h.GetUpdateNonce).Methods("GET")
+	r.HandleFunc("/dids/{did:.*}", h.ResolveDid).Methods("GET")
+	r.HandleFunc("/dids/{did:.*}", h.UpdateDid).Methods("PUT")
+	r.HandleFunc("/dids/{did:.*}", h.DeactivateDid).Methods("DELETE")
+	return r
+}
+
+func seedDids(t *testing.T, ledger fabric.LedgerClient, docs ...*domain.DIDDocument) {
+	t.Helper()
+	for _, doc := range docs {
+		if err := ledger.CreateDid(context.Background(), doc); err != nil {
+			t.Fatalf("seed failed: %v", err)
+		}
+	}
+}
+
+func TestListDids_Empty(t *testing.T) {
+	router := newDidRouter(newTestLedger(t))
+
+	rec := doRequest(t, router, "GET", "/dids", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); body != "{\"items\":[],\"total\":0}\n" {
+		t.Errorf("unexpected empty page body: %s", body)
+	}
+}
+
+func TestListDids_FilterAndPaginate(t *testing.T) {
+	ledger := newTestLedger(t)
+	seedDids(t, ledger,
+		&domain.DIDDocument{ID: "did:ewallet:1", Controller: "did:ewallet:alice",
+			VerificationMethod: []domain.VerificationMethod{{ID: "did:ewallet:1#key-1", Type: "Ed25519VerificationKey2020"}}},
+		&domain.DIDDocument{ID: "did:ewallet:2", Controller: "did:ewallet:bob"},
+		&domain.DIDDocument{ID: "did:ewallet:3", Controller: "did:ewallet:alice"},
+	)
+	router := newDidRouter(ledger)
+
+	first := decodeBody[DidPageResponse](t, doRequest(t, router, "GET", "/dids?controller=did:ewallet:alice&limit=1", nil))
+	if len(first.Items) != 1 || first.Total != 2 || first.NextCursor == "" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+	// Items use the same DTO as ResolveDid
+	item := first.Items[0]
+	if item.ID != "did:ewallet:1" || len(item.VerificationMethod) != 1 || len(item.Authentication) != 1 {
+		t.Errorf("unexpected item: %+v", item)
+	}
+
+	second := decodeBody[DidPageResponse](t, doRequest(t, router, "GET", "/dids?controller=did:ewallet:alice&limit=1&cursor="+first.NextCursor, nil))
+	if len(second.Items) != 1 || second.Items[0].ID != "did:ewallet:3" || second.NextCursor != "" {
+		t.Fatalf("unexpected second page: %+v", second)
+	}
+}
+
+func TestListDids_InvalidParams(t *testing.T) {
+	router := newDidRouter(newTestLedger(t))
+
+	for _, query := range []string{"limit=0", "limit=abc", "cursor=garbage"} {
+		if rec := doRequest(t, router, "GET", "/dids?"+query, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestUpdateDid_KeyRotationSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	ledger, err := fabric.NewFileLedgerClient(path)
+	if err != nil {
+		t.Fatalf("failed to create ledger: %v", err)
+	}
+	priv := registerEd25519DID(t, ledger, "did:ewallet:rot")
+	router := newDidRouter(ledger)
+	before := decodeBody[DidDocumentResponse](t, doRequest(t, router, "GET", "/dids/did:ewallet:rot", nil))
+
+	rotate := `{"verificationMethod":[{"type":"Ed25519VerificationKey2018","publicKeyBase58":"newKey"},{"type":"JsonWebKey2020","publicKeyJwk":{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}}]}`
+	rec := doRequest(t, router, "PUT", "/dids/did:ewallet:rot", strings.NewReader(signUpdate(t, router, "did:ewallet:rot", rotate, priv)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	ledger.Close()
+
+	reopened, err := fabric.NewFileLedgerClient(path)
+	if err != nil {
+		t.Fatalf("failed to reopen ledger: %v", err)
+	}
+	defer reopened.Close()
+
+	after := decodeBody[DidDocumentResponse](t, doRequest(t, newDidRouter(reopened), "GET", "/dids/did:ewallet:rot", nil))
+	if len(after.VerificationMethod) != 2 || after.VerificationMethod[0].PublicKeyBase58 != "newKey" {
+		t.Errorf("rotated keys not resolved after restart: %+v", after.VerificationMethod)
+	}
+	if after.Created != before.Created {
+		t.Errorf("Created changed from %s to %s", before.Created, after.Created)
+	}
+}
+
+func TestUpdateDid_Errors(t *testing.T) {
+	router := newDidRouter(newTestLedger(t))
+
+	if rec := doRequest(t, router, "PUT", "/dids/did:ewallet:missing", strings.NewReader(`{"verificationMethod":[]}`)); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for missing DID, got %d", rec.Code)
+	}
+	if rec := doRequest(t, router, "PUT", "/dids/did:ewallet:a", strings.NewReader(`{"did":"did:ewallet:b"}`)); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for mismatched DID, got %d", rec.Code)
+	}
+}
+
+func TestDeactivateDid(t *testing.T) {
+	ledger := newTestLedger(t)
+	priv := registerEd25519DID(t, ledger, "did:ewallet:gone")
+	router := newDidRouter(ledger)
+
+	if rec := doRequest(t, router, "DELETE", "/dids/did:ewallet:gone", strings.NewReader(signDeactivate(t, router, "did:ewallet:gone", priv))); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Resolving still works and reports the deactivation
+	rec := doRequest(t, router, "GET", "/dids/did:ewallet:gone", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 when resolving a deactivated DID, got %d", rec.Code)
+	}
+	doc := decodeBody[DidDocumentResponse](t, rec)
+	if !doc.Deactivated || doc.DeactivatedAt == "" || len(doc.VerificationMethod) != 1 {
+		t.Errorf("unexpected deactivated document: %+v", doc)
+	}
+
+	rec = doRequest(t, router, "DELETE", "/dids/did:ewallet:gone", nil)
+	if rec.Code != http.StatusConflict || decodeBody[ErrorResponse](t, rec).Code != CodeDeactivated {
+		t.Errorf("expected 409 %s on double deactivate, got %d: %s", CodeDeactivated, rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, router, "PUT", "/dids/did:ewallet:gone", strings.NewReader(`{"verificationMethod":[]}`)); rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 when updating a deactivated DID, got %d", rec.Code)
+	}
+	if rec := doRequest(t, router, "DELETE", "/dids/did:ewallet:missing", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown DID, got %d", rec.Code)
+	}
+}
+
+func TestCreateDid_Duplicate(t *testing.T) {
+	ledger := newTestLedger(t)
+	seedDids(t, ledger, &domain.DIDDocument{ID: "did:ewallet:taken"})
+	router := newDidRouter(ledger)
+
+	rec := doRequest(t, router, "POST", "/dids", strings.NewReader(`{"did":"did:ewallet:taken"}`))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a duplicate DID, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if resp := decodeBody[ErrorResponse](t, rec); resp.Code != CodeAlreadyExists {
+		t.Errorf("expected code %s, got %q", CodeAlreadyExists, resp.Code)
+	}
+
+	rec = doRequest(t, router, "PUT", "/dids/did:ewallet:missing", strings.NewReader(`{"verificationMethod":[]}`))
+	if resp := decodeBody[ErrorResponse](t, rec); rec.Code != http.StatusNotFound || resp.Code != CodeNotFound {
+		t.Errorf("expected 404 %s, got %d %q", CodeNotFound, rec.Code, resp.Code)
+	}
+}
+
+func TestCreateDid_Validation(t *testing.T) {
+	router := newDidRouter(newTestLedger(t))
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"not a did", `{"did":"banana"}`, "scheme"},
+		{"method with dash", `{"did":"did:e-wallet:1"}`, "method-name"},
+		{"unsupported method", `{"did":"did:example:1"}`, "is not supported"},
+		{"empty method-specific-id", `{"did":"did:ewallet:"}`, "method-specific-id"},
+		{"bad percent-encoding", `{"did":"did:web:example.com%G1"}`, "percent-encoding"},
+		{"unknown key type", `{"did":"did:ewallet:1","verificationMethod":[{"type":"Foo","publicKeyBase58":"k"}]}`, "verificationMethod[0]"},
+		{"no key material", `{"did":"did:ewallet:1","verificationMethod":[{"type":"Ed25519VerificationKey2018"}]}`, "key material"},
+		{"empty type", `{"did":"did:ewallet:1","verificationMethod":[{"publicKeyBase58":"k"}]}`, "type is required"},
+		{"two key fields", `{"did":"did:ewallet:1","verificationMethod":[{"type":"Ed25519VerificationKey2018","publicKeyBase58":"k","publicKeyMultibase":"zk"}]}`, "only one of"},
+		{"unparseable base58", `{"did":"did:ewallet:1","verificationMethod":[{"type":"Ed25519VerificationKey2018","publicKeyBase58":"not-base58"}]}`, "not valid base58"},
+		{"unparseable multibase", `{"did":"did:ewallet:1","verificationMethod":[{"type":"Ed25519VerificationKey2020","publicKeyMultibase":"z0OIl"}]}`, "invalid encoding"},
+		{"incomplete jwk", `{"did":"did:ewallet:1","verificationMethod":[{"type":"JsonWebKey2020","publicKeyJwk":{"kty":"OKP"}}]}`, "publicKeyJwk"},
+		{"second method invalid", `{"did":"did:ewallet:1","verificationMethod":[{"type":"Ed25519VerificationKey2018","publicKeyBase58":"k"},{"type":""}]}`, "verificationMethod[1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, router, "POST", "/dids", strings.NewReader(tt.body))
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if body := rec.Body.String(); !strings.Contains(body, tt.wantErr) {
+				t.Errorf("error %s does not mention %q", body, tt.wantErr)
+			}
+		})
+	}
+
+	valid := `{"did":"did:web:example.com%3A8443","verificationMethod":[{"type":"Ed25519VerificationKey2020","publicKeyBase58":"k"}]}`
+	if rec := doRequest(t, router, "POST", "/dids", strings.NewReader(valid)); rec.Code != http.StatusCreated {
+		t.Errorf("expected 201 for a valid DID, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCreateDid_MaxVerificationMethods(t *testing.T) {
+	router := newDidRouterWith(newTestLedger(t), DidHandlerOptions{
+		Validator: domain.NewDIDValidator(nil).WithMaxVerificationMethods(2),
+	})
+	key := `{"type":"Ed25519VerificationKey2018","publicKeyBase58":"k"}`
+
+	rec := doRequest(t, router, "POST", "/dids", strings.NewReader(`{"did":"did:ewallet:3","verificationMethod":[`+key+`,`+key+`,`+key+`]}`))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 above the limit, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := decodeBody[ErrorResponse](t, rec); got.Code != CodeInvalidVerificationMethod || len(got.Details) != 1 || got.Details[0].Field != "verificationMethod" {
+		t.Errorf("expected a verificationMethod detail, got %+v", got)
+	}
+
+	rec = doRequest(t, router, "POST", "/dids", strings.NewReader(`{"did":"did:ewallet:2","verificationMethod":[`+key+`,`+key+`]}`))
+	if rec.Code != http.StatusCreated {
+		t.Errorf("expected 201 at the limit, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestUpdateDid_ValidatesVerificationMethods(t *testing.T) {
+	ledger := newTestLedger(t)
+	priv := registerEd25519DID(t, ledger, "did:ewallet:upd")
+	router := newDidRouter(ledger)
+
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		wantErr string
+	}{
+		{"empty type", `{"verificationMethod":[{"publicKeyBase58":"k"}]}`, http.StatusBadRequest, "verificationMethod[0]"},
+		{"no key material", `{"verificationMethod":[{"type":"Ed25519VerificationKey2018"}]}`, http.StatusBadRequest, "key material"},
+		{"two key fields", `{"verificationMethod":[{"type":"Ed25519VerificationKey2018","publicKeyBase58":"k","publicKeyMultibase":"zk"}]}`, http.StatusBadRequest, "only one of"},
+		{"unparseable base58", `{"verificationMethod":[{"type":"Ed25519VerificationKey2018","publicKeyBase58":"0OIl"}]}`, http.StatusBadRequest, "not valid base58"},
+		{"second method invalid", `{"verificationMethod":[{"type":"Ed25519VerificationKey2018","publicKeyBase58":"k"},{"type":"Foo","publicKeyBase58":"k"}]}`, http.StatusBadRequest, "verificationMethod[1]"},
+		{"valid", `{"verificationMethod":[{"type":"Ed25519VerificationKey2018","publicKeyBase58":"k"}]}`, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, router, "PUT", "/dids/did:ewallet:upd", strings.NewReader(signUpdate(t, router, "did:ewallet:upd", tt.body, priv)))
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if body := rec.Body.String(); !strings.Contains(body, tt.wantErr) {
+				t.Errorf("error %s does not mention %q", body, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateDid_ServicesRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	ledger, err := fabric.NewFileLedgerClient(path)
+	if err != nil {
+		t.Fatalf("failed to create ledger: %v", err)
+	}
+	router := newDidRouter(ledger)
+
+	create := `{"did":"did:ewallet:svc","verificationMethod":[{"type":"Ed25519VerificationKey2018","publicKeyBase58":"k"}],
+		"service":[{"type":"DIDCommMessaging","serviceEndpoint":"https://wallet.example.com/didcomm"},
+		           {"id":"linked","type":"LinkedDomains","serviceEndpoint":"https://example.com"}]}`
+	if rec := doRequest(t, router, "POST", "/dids", strings.NewReader(create)); rec.Code != http.StatusCreated {
+		t.Fatalf("create failed: %d %s", rec.Code, rec.Body.String())
+	}
+	ledger.Close()
+
+	reopened, err := fabric.NewFileLedgerClient(path)
+	if err != nil {
+		t.Fatalf("failed to reopen ledger: %v", err)
+	}
+	defer reopened.Close()
+
+	doc := decodeBody[DidDocumentResponse](t, doRequest(t, newDidRouter(reopened), "GET", "/dids/did:ewallet:svc", nil))
+	want := []ServiceDto{
+		{ID: "did:ewallet:svc#service-1", Type: "DIDCommMessaging", ServiceEndpoint: "https://wallet.example.com/didcomm"},
+		{ID: "did:ewallet:svc#linked", Type: "LinkedDomains", ServiceEndpoint: "https://example.com"},
+	}
+	if len(doc.Service) != len(want) {
+		t.Fatalf("expected %d services, got %+v", len(want), doc.Service)
+	}
+	for i := range want {
+		if doc.Service[i] != want[i] {
+			t.Errorf("service %d: expected %+v, got %+v", i, want[i], doc.Service[i])
+		}
+	}
+}
+
+func TestCreateDid_RejectsRelativeServiceEndpoint(t *testing.T) {
+	router := newDidRouter(newTestLedger(t))
+
+	body := `{"did":"did:ewallet:svc","service":[{"type":"DIDCommMessaging","serviceEndpoint":"/didcomm"}]}`
+	rec := doRequest(t, router, "POST", "/dids", strings.NewReader(body))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "service[0]") {
+		t.Errorf("expected 400 naming service[0], got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCreateDid_Relationships(t *testing.T) {
+	ledger := newTestLedger(t)
+	router := newDidRouter(ledger)
+
+	keys := `"verificationMethod":[{"type":"Ed25519VerificationKey2018","publicKeyBase58":"a"},
+		{"type":"Ed25519VerificationKey2018","publicKeyBase58":"b"},
+		{"type":"X25519KeyAgreementKey2019","publicKeyBase58":"c"}]`
+
+	tests := []struct {
+		name          string
+		relationships string
+		wantAuth      string
+		wantAssertion string
+	}{
+		{"partial", `"authentication":[0],"assertionMethod":[1]`, "#key-1", "#key-2"},
+		{"authentication only", `"authentication":[1,0]`, "#key-2,#key-1", "#key-1,#key-2,#key-3"},
+		{"omitted", ``, "#key-1,#key-2,#key-3", "#key-1,#key-2,#key-3"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			did := "did:ewallet:rel-" + strconv.Itoa(i)
+			body := `{"did":"` + did + `",` + keys
+			if tt.relationships != "" {
+				body += "," + tt.relationships
+			}
+			body += "}"
+			if rec := doRequest(t, router, "POST", "/dids", strings.NewReader(body)); rec.Code != http.StatusCreated {
+				t.Fatalf("create failed: %d %s", rec.Code, rec.Body.String())
+			}
+
+			doc := decodeBody[DidDocumentResponse](t, doRequest(t, router, "GET", "/dids/"+did, nil))
+			if got := strings.ReplaceAll(strings.Join(doc.Authentication, ","), did, ""); got != tt.wantAuth {
+				t.Errorf("authentication: expected %s, got %s", tt.wantAuth, got)
+			}
+			if got := strings.ReplaceAll(strings.Join(doc.AssertionMethod, ","), did, ""); got != tt.wantAssertion {
+				t.Errorf("assertionMethod: expected %s, got %s", tt.wantAssertion, got)
+			}
+		})
+	}
+}
+
+func TestCreateDid_RelationshipIndexOutOfRange(t *testing.T) {
+	router := newDidRouter(newTestLedger(t))
+
+	for _, rel := range []string{`"authentication":[1]`, `"assertionMethod":[-1]`} {
+		body := `{"did":"did:ewallet:rel","verificationMethod":[{"type":"Ed25519VerificationKey2018","publicKeyBase58":"a"}],` + rel + `}`
+		rec := doRequest(t, router, "POST", "/dids", strings.NewReader(body))
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "does not exist") {
+			t.Errorf("%s: expected 400, got %d: %s", rel, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestResolveDid_LegacyRelationshipFallback(t *testing.T) {
+	ledger := newTestLedger(t)
+	// Records written before relationships were stored have none
+	seedDids(t, ledger, &domain.DIDDocument{ID: "did:ewallet:legacy", VerificationMethod: []domain.VerificationMethod{
+		{ID: "did:ewallet:legacy#key-1", Type: "Ed25519VerificationKey2018"},
+		{ID: "did:ewallet:legacy#key-2", Type: "Ed25519VerificationKey2018"},
+	}})
+
+	doc := decodeBody[DidDocumentResponse](t, doRequest(t, newDidRouter(ledger), "GET", "/dids/did:ewallet:legacy", nil))
+	if len(doc.Authentication) != 2 || len(doc.AssertionMethod) != 2 {
+		t.Errorf("expected every key in both relationships, got %v / %v", doc.Authentication, doc.AssertionMethod)
+	}
+}
+
+func TestCreateDid_PublicKeyJwk(t *testing.T) {
+	router := newDidRouter(newTestLedger(t))
+
+	const (
+		p256 = `{"kty":"EC","crv":"P-256","x":"f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU","y":"x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0"}`
+		okp  = `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+	)
+	legacy, _ := json.Marshal(okp)
+
+	tests := []struct {
+		name    string
+		did     string
+		jwk     string
+		wantCrv string
+	}{
+		{"EC P-256 object", "did:ewallet:p256", p256, "P-256"},
+		{"OKP object", "did:ewallet:okp", okp, "Ed25519"},
+		{"legacy string", "did:ewallet:legacy-jwk", string(legacy), "Ed25519"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"did":"` + tt.did + `","verificationMethod":[{"type":"JsonWebKey2020","publicKeyJwk":` + tt.jwk + `}]}`
+			if rec := doRequest(t, router, "POST", "/dids", strings.NewReader(body)); rec.Code != http.StatusCreated {
+				t.Fatalf("create failed: %d %s", rec.Code, rec.Body.String())
+			}
+
+			rec := doRequest(t, router, "GET", "/dids/"+tt.did, nil)
+			var raw struct {
+				VerificationMethod []struct {
+					PublicKeyJwk json.RawMessage `json:"publicKeyJwk"`
+				} `json:"verificationMethod"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &raw); err != nil || len(raw.VerificationMethod) != 1 {
+				t.Fatalf("unexpected resolve body: %s", rec.Body.String())
+			}
+			// Resolvers expect an object, never a JSON string
+			if jwk := raw.VerificationMethod[0].PublicKeyJwk; len(jwk) == 0 || jwk[0] != '{' {
+				t.Errorf("publicKeyJwk is not an object: %s", jwk)
+			}
+
+			doc := decodeBody[DidDocumentResponse](t, rec)
+			if got := doc.VerificationMethod[0].PublicKeyJwk; got == nil || got.Crv != tt.wantCrv {
+				t.Errorf("expected crv %s, got %+v", tt.wantCrv, got)
+			}
+		})
+	}
+}
+
+func TestCreateDid_PublicKeyMultibase(t *testing.T) {
+	router := newDidRouter(newTestLedger(t))
+
+	const key = "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+	body := `{"did":"did:ewallet:mb","verificationMethod":[{"type":"Ed25519VerificationKey2020","publicKeyMultibase":"` + key + `"}]}`
+	if rec := doRequest(t, router, "POST", "/dids", strings.NewReader(body)); rec.Code != http.StatusCreated {
+		t.Fatalf("create failed: %d %s", rec.Code, rec.Body.String())
+	}
+
+	doc := decodeBody[DidDocumentResponse](t, doRequest(t, router, "GET", "/dids/did:ewallet:mb", nil))
+	if len(doc.VerificationMethod) != 1 || doc.VerificationMethod[0].PublicKeyMultibase != key {
+		t.Errorf("publicKeyMultibase not resolved: %+v", doc.VerificationMethod)
+	}
+
+	both := `{"did":"did:ewallet:mb2","verificationMethod":[{"type":"Ed25519VerificationKey2020","publicKeyMultibase":"` + key + `","publicKeyBase58":"k"}]}`
+	if rec := doRequest(t, router, "POST", "/dids", strings.NewReader(both)); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for two key fields, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestDidRouting_AwkwardDIDs creates DIDs that are easy to mangle in a URL and
+// resolves each through every path form a client might reasonably send.
+func TestDidRouting_AwkwardDIDs(t *testing.T) {
+	tests := []struct {
+		name   string
+		create string   // did in the create body
+		stored string   // normalized id the document is stored under
+		paths  []string // path forms that must resolve to it
+	}{
+		{
+			name:   "encoded port colon",
+			create: "did:web:example.com%3A8443",
+			stored: "did:web:example.com%3A8443",
+			paths: []string{
+				"/dids/did:web:example.com%3A8443",
+				"/dids/did:web:example.com%3a8443",
+				"/dids/did:web:example.com%253A8443",
+				"/dids/did%3Aweb%3Aexample.com%253A8443",
+				"/dids/did:web:example.com%3A8443/",
+			},
+		},
+		{
+			name:   "did:web path segments",
+			create: "did:web:example.com:users:alice",
+			stored: "did:web:example.com:users:alice",
+			paths: []string{
+				"/dids/did:web:example.com:users:alice",
+				"/dids/did%3Aweb%3Aexample.com%3Ausers%3Aalice",
+				"/dids/did:web:example.com:users:alice/",
+			},
+		},
+		{
+			name:   "encoded slash in did:web path",
+			create: "did:web:example.com:docs%2fteam",
+			stored: "did:web:example.com:docs%2Fteam",
+			paths: []string{
+				"/dids/did:web:example.com:docs%2Fteam",
+				"/dids/did:web:example.com:docs%252Fteam",
+				"/dids/did%3Aweb%3Aexample.com%3Adocs%252Fteam",
+			},
+		},
+		{
+			name:   "uppercase method",
+			create: "DID:EWALLET:MixedCase",
+			stored: "did:ewallet:MixedCase",
+			paths: []string{
+				"/dids/did:ewallet:MixedCase",
+				"/dids/DID:EWALLET:MixedCase",
+				"/dids/did:Ewallet:MixedCase/",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newDidRouter(newTestLedger(t))
+
+			body := `{"did":"` + tt.create + `","verificationMethod":[{"type":"Ed25519VerificationKey2020","publicKeyBase58":"k"}]}`
+			rec := doRequest(t, router, "POST", "/dids", strings.NewReader(body))
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("create: expected 201, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if got := decodeBody[map[string]string](t, rec)["did"]; got != tt.stored {
+				t.Errorf("create: expected the DID to be stored as %s, got %s", tt.stored, got)
+			}
+
+			for _, path := range tt.paths {
+				rec := doRequest(t, router, "GET", path, nil)
+				if rec.Code != http.StatusOK {
+					t.Errorf("%s: expected 200, got %d: %s", path, rec.Code, rec.Body.String())
+					continue
+				}
+				doc := decodeBody[DidDocumentResponse](t, rec)
+				if doc.ID != tt.stored {
+					t.Errorf("%s: expected %s, got %s", path, tt.stored, doc.ID)
+				}
+				if len(doc.VerificationMethod) != 1 || doc.VerificationMethod[0].ID != tt.stored+"#key-1" {
+					t.Errorf("%s: expected the key under %s#key-1, got %+v", path, tt.stored, doc.VerificationMethod)
+				}
+			}
+		})
+	}
+}
+
+func TestDidRouting_MethodSpecificIDKeepsCase(t *testing.T) {
+	router := newDidRouter(newTestLedger(t))
+	body := `{"did":"did:ewallet:MixedCase","verificationMethod":[{"type":"Ed25519VerificationKey2020","publicKeyBase58":"k"}]}`
+	if rec := doRequest(t, router, "POST", "/dids", strings.NewReader(body)); rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d", rec.Code)
+	}
+
+	if rec := doRequest(t, router, "GET", "/dids/did:ewallet:mixedcase", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected the method-specific id to be case-sensitive, got %d", rec.Code)
+	}
+}
+
+func TestDidRouting_UpdateNonceForEncodedDID(t *testing.T) {
+	ledger := newTestLedger(t)
+	registerEd25519DID(t, ledger, "did:web:example.com%3A8443")
+	router := newDidRouter(ledger)
+
+	rec := doRequest(t, router, "GET", "/dids/did:web:example.com%3A8443/update-nonce", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := decodeBody[UpdateNonceResponse](t, rec).Did; got != "did:web:example.com%3A8443" {
+		t.Errorf("expected the nonce to be bound to the encoded DID, got %s", got)
+	}
+}
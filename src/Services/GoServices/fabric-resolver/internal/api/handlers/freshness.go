@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reasons reported by GET /anchors/{hash}/verify when an anchor is not valid
+const (
+	reasonNotFound = "notFound"
+	reasonRevoked  = "revoked"
+	reasonTooOld   = "tooOld"   // anchored longer ago than maxAge
+	reasonTooEarly = "tooEarly" // anchored before notBefore
+	reasonTooLate  = "tooLate"  // anchored after notAfter
+)
+
+// freshness holds the optional time constraints of a verify request. All bounds are inclusive.
+type freshness struct {
+	MaxAge    time.Duration // zero means no limit
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// parseFreshness reads maxAge, notBefore and notAfter from the query string.
+// maxAge is a Go duration ("36h") or a whole number of days ("30d").
+func parseFreshness(r *http.Request) (freshness, error) {
+	q := r.URL.Query()
+	var f freshness
+
+	if v := q.Get("maxAge"); v != "" {
+		d, err := parseMaxAge(v)
+		if err != nil {
+			return f, err
+		}
+		f.MaxAge = d
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"notBefore", &f.NotBefore}, {"notAfter", &f.NotAfter}} {
+		if v := q.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return f, fmt.Errorf("%s must be RFC3339 format", p.name)
+			}
+			*p.dst = t
+		}
+	}
+
+	if !f.NotBefore.IsZero() && !f.NotAfter.IsZero() && f.NotAfter.Before(f.NotBefore) {
+		return f, errors.New("notAfter must not be before notBefore")
+	}
+	return f, nil
+}
+
+func parseMaxAge(v string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(v); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, errors.New(`maxAge must be a positive duration such as "36h" or "30d"`)
+}
+
+// check returns the constraints an anchor with the given timestamp fails, relative to now.
+func (f freshness) check(anchored, now time.Time) []string {
+	var reasons []string
+	if f.MaxAge > 0 && now.Sub(anchored) > f.MaxAge {
+		reasons = append(reasons, reasonTooOld)
+	}
+	if !f.NotBefore.IsZero() && anchored.Before(f.NotBefore) {
+		reasons = append(reasons, reasonTooEarly)
+	}
+	if !f.NotAfter.IsZero() && anchored.After(f.NotAfter) {
+		reasons = append(reasons, reasonTooLate)
+	}
+	return reasons
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+func TestVerifyAnchor_Freshness(t *testing.T) {
+	ledger := newTestLedger(t)
+	if _, _, err := ledger.CreateAnchor(context.Background(), &domain.Anchor{Hash: "fresh"}); err != nil {
+		t.Fatalf("seed failed: %v", err)
+	}
+	anchor, _ := ledger.GetAnchor(context.Background(), "fresh")
+	ts := anchor.Timestamp
+
+	h := NewAnchorHandler(ledger, AnchorHandlerOptions{})
+	now := ts.Add(48 * time.Hour)
+	h.now = func() time.Time { return now }
+	router := mux.NewRouter()
+	router.HandleFunc("/anchors/{hash}/verify", h.VerifyAnchor).Methods("GET")
+
+	rfc := func(t time.Time) string { return url.QueryEscape(t.Format(time.RFC3339Nano)) }
+
+	tests := []struct {
+		name    string
+		query   string
+		valid   bool
+		reasons []string
+	}{
+		{"no constraints", "", true, nil},
+		{"maxAge exactly the age", "maxAge=48h", true, nil},
+		{"maxAge one nanosecond short", "maxAge=47h59m59.999999999s", false, []string{"tooOld"}},
+		{"maxAge in days", "maxAge=2d", true, nil},
+		{"maxAge one day", "maxAge=1d", false, []string{"tooOld"}},
+		{"notBefore equal to timestamp", "notBefore=" + rfc(ts), true, nil},
+		{"notBefore just after", "notBefore=" + rfc(ts.Add(time.Nanosecond)), false, []string{"tooEarly"}},
+		{"notAfter equal to timestamp", "notAfter=" + rfc(ts), true, nil},
+		{"notAfter just before", "notAfter=" + rfc(ts.Add(-time.Nanosecond)), false, []string{"tooLate"}},
+		{"window around timestamp", "notBefore=" + rfc(ts.Add(-time.Hour)) + "&notAfter=" + rfc(ts.Add(time.Hour)), true, nil},
+		{"several failures", "maxAge=1h&notBefore=" + rfc(ts.Add(time.Hour)), false, []string{"tooOld", "tooEarly"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, router, "GET", "/anchors/fresh/verify?"+tt.query, nil)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			resp := decodeBody[VerifyAnchorResponse](t, rec)
+			if !resp.Exists || resp.Valid != tt.valid || !slices.Equal(resp.Reasons, tt.reasons) {
+				t.Errorf("expected valid=%v reasons=%v, got %+v", tt.valid, tt.reasons, resp)
+			}
+		})
+	}
+}
+
+func TestVerifyAnchor_FreshnessMissingAnchor(t *testing.T) {
+	router := newAnchorRouter(newTestLedger(t))
+
+	// Constraints are irrelevant when the anchor does not exist
+	resp := decodeBody[VerifyAnchorResponse](t, doRequest(t, router, "GET", "/anchors/missing/verify?maxAge=1h", nil))
+	if resp.Exists || resp.Valid || !slices.Equal(resp.Reasons, []string{"notFound"}) || resp.Timestamp != "" {
+		t.Errorf("unexpected response for missing anchor: %+v", resp)
+	}
+}
+
+func TestVerifyAnchor_InvalidFreshnessParams(t *testing.T) {
+	router := newAnchorRouter(newTestLedger(t))
+
+	for _, query := range []string{
+		"maxAge=abc",
+		"maxAge=-1h",
+		"maxAge=0s",
+		"maxAge=xd",
+		"maxAge=0d",
+		"notBefore=yesterday",
+		"notAfter=2025-13-01T00:00:00Z",
+		"notBefore=2025-02-01T00:00:00Z&notAfter=2025-01-01T00:00:00Z",
+	} {
+		if rec := doRequest(t, router, "GET", "/anchors/any/verify?"+query, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
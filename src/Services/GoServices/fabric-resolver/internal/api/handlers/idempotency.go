@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"fabric-resolver/internal/idempotency"
+	"fabric-resolver/internal/pkg/canonicalizer"
+
+	"github.com/gorilla/mux"
+)
+
+// IdempotencyKeyHeader lets clients retry a write safely.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotent replays the stored response when a request repeats an Idempotency-Key
+// with the same canonical body, and answers 409 when the body differs. Requests
+// without the header, and all requests when store is nil, go straight to next;
+// keyed bodies that are JSON without a canonical form, such as ones with
+// duplicate keys, are refused.
+// 5xx responses are not stored, nor are requests whose handler panics, so a retry
+// after a server failure runs again. Keys are per route, whichever path of it was called.
+func idempotent(store *idempotency.Store, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if store == nil || key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > idempotency.MaxKeyLength {
+			respondError(w, http.StatusBadRequest, IdempotencyKeyHeader+" must be at most "+strconv.Itoa(idempotency.MaxKeyLength)+" characters")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			respondBodyError(w, err, CodeInvalidBody, "Invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		requestHash, err := canonicalizer.CanonicalizeAndHashJSON(body)
+		switch {
+		case errors.Is(err, canonicalizer.ErrInvalidJSON), errors.Is(err, canonicalizer.ErrTrailingData):
+			// Not JSON; the handler rejects it and there is nothing worth storing
+			next(w, r)
+			return
+		case err != nil:
+			status, code := canonicalizeErrorStatus(err)
+			respondErrorCode(w, status, code, "Invalid request body: "+err.Error())
+			return
+		}
+
+		// Tenants pick their keys independently, so a key is only theirs
+		scopedKey := r.Method + " " + idempotencyRoute(r) + " " + key
+		if tenant := Tenant(r.Context()); tenant != "" {
+			scopedKey = tenant + " " + scopedKey
+		}
+		rec, err := store.Begin(scopedKey, requestHash)
+		switch {
+		case errors.Is(err, idempotency.ErrConflict):
+			respondError(w, http.StatusConflict, IdempotencyKeyHeader+" was already used with a different request body")
+			return
+		case errors.Is(err, idempotency.ErrInProgress):
+			respondError(w, http.StatusConflict, "A request with this "+IdempotencyKeyHeader+" is still in progress")
+			return
+		case rec != nil:
+			if rec.ContentType != "" {
+				w.Header().Set("Content-Type", rec.ContentType)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(rec.Status)
+			w.Write(rec.Body)
+			return
+		}
+
+		// The claim is released unless a response is stored, also when next panics
+		stored := false
+		defer func() {
+			if !stored {
+				store.Release(scopedKey)
+			}
+		}()
+
+		rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+		next(rw, r)
+
+		if rw.status >= 500 {
+			return
+		}
+		stored = true
+		if err := store.Complete(scopedKey, rw.status, rw.Header().Get("Content-Type"), rw.body.Bytes()); err != nil {
+			slog.Error("Failed to store idempotent response", "err", err)
+		}
+	}
+}
+
+// idempotencyRoute returns the route template of r without its /v1 prefix, so a retry
+// through the unversioned alias of a route replays the response to the first request.
+// Outside a router it is the path of r.
+func idempotencyRoute(r *http.Request) string {
+	current := mux.CurrentRoute(r)
+	if current == nil {
+		return r.URL.Path
+	}
+	template, err := current.GetPathTemplate()
+	if err != nil {
+		return r.URL.Path
+	}
+	if rest, ok := strings.CutPrefix(template, "/v1"); ok && strings.HasPrefix(rest, "/") {
+		return rest
+	}
+	return template
+}
+
+// recordingWriter passes a response through while keeping a copy of it.
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(p)
+	return rw.ResponseWriter.Write(p)
+}
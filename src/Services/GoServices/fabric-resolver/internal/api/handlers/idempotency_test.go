@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/idempotency"
+
+	"github.com/gorilla/mux"
+)
+
+func newIdempotentRouter(t *testing.T, retention time.Duration) *mux.Router {
+	t.Helper()
+	store, err := idempotency.NewStore(filepath.Join(t.TempDir(), "idempotency.json"), retention)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	h := NewAnchorHandler(newTestLedger(t), AnchorHandlerOptions{Idempotency: store})
+	r := mux.NewRouter()
+	r.HandleFunc("/v1/anchors", h.CreateAnchor).Methods("POST")
+	r.HandleFunc("/anchors", h.CreateAnchor).Methods("POST")
+	return r
+}
+
+func postWithKey(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	return postWithKeyTo(h, "/anchors", key, body)
+}
+
+func postWithKeyTo(h http.Handler, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCreateAnchor_IdempotencyReplay(t *testing.T) {
+	router := newIdempotentRouter(t, time.Hour)
+	hash := hexHash("idempotent")
+
+	first := postWithKey(router, "retry-1", `{"hash":"`+hash+`","issuerDid":"did:ewallet:issuer"}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", first.Code, first.Body.String())
+	}
+
+	// Same body with different key order and whitespace is the same request
+	replay := postWithKey(router, "retry-1", `{ "issuerDid": "did:ewallet:issuer", "hash": "`+hash+`" }`)
+	if replay.Code != http.StatusCreated || replay.Body.String() != first.Body.String() {
+		t.Errorf("expected the original response, got %d: %s", replay.Code, replay.Body.String())
+	}
+	if replay.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("expected the Idempotent-Replayed header on the replay")
+	}
+
+	// A retry through the versioned path is the same route
+	versioned := postWithKeyTo(router, "/v1/anchors", "retry-1", `{"hash":"`+hash+`","issuerDid":"did:ewallet:issuer"}`)
+	if versioned.Code != http.StatusCreated || versioned.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected the original response on /v1/anchors, got %d: %s", versioned.Code, versioned.Body.String())
+	}
+}
+
+func TestIdempotent_PanicReleasesKey(t *testing.T) {
+	store, err := idempotency.NewStore("", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	panicking := idempotent(store, func(http.ResponseWriter, *http.Request) { panic("handler failed") })
+	func() {
+		defer func() { recover() }()
+		postWithKey(panicking, "retry-1", `{"a":1}`)
+	}()
+
+	// The retry runs, rather than waiting on the panicked request forever
+	created := idempotent(store, func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusCreated) })
+	if rec := postWithKey(created, "retry-1", `{"a":1}`); rec.Code != http.StatusCreated {
+		t.Errorf("expected the retry to run, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCreateAnchor_IdempotencyConflict(t *testing.T) {
+	router := newIdempotentRouter(t, time.Hour)
+
+	if rec := postWithKey(router, "retry-1", `{"hash":"`+hexHash("a")+`"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	rec := postWithKey(router, "retry-1", `{"hash":"`+hexHash("b")+`"}`)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", rec.Code)
+	}
+	if resp := decodeBody[ErrorResponse](t, rec); !strings.Contains(resp.Error, IdempotencyKeyHeader) {
+		t.Errorf("unexpected error: %q", resp.Error)
+	}
+}
+
+func TestCreateAnchor_IdempotencyExpiry(t *testing.T) {
+	router := newIdempotentRouter(t, 10*time.Millisecond)
+
+	if rec := postWithKey(router, "retry-1", `{"hash":"`+hexHash("a")+`"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// The expired key is free for a new request
+	if rec := postWithKey(router, "retry-1", `{"hash":"`+hexHash("b")+`"}`); rec.Code != http.StatusCreated {
+		t.Errorf("expected 201 after expiry, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCreateAnchor_IdempotencyKeyTooLong(t *testing.T) {
+	router := newIdempotentRouter(t, time.Hour)
+	rec := postWithKey(router, strings.Repeat("k", idempotency.MaxKeyLength+1), `{"hash":"`+hexHash("a")+`"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestCreateAnchor_IdempotencyNonCanonicalBody(t *testing.T) {
+	router := newIdempotentRouter(t, time.Hour)
+
+	// Duplicate keys have no canonical form, so a retry could not be matched to the first request
+	rec := postWithKey(router, "retry-1", `{"hash":"`+hexHash("a")+`","hash":"`+hexHash("b")+`"}`)
+	if rec.Code != http.StatusBadRequest || decodeBody[ErrorResponse](t, rec).Code != CodeDuplicateKey {
+		t.Fatalf("expected 400 %s, got %d: %s", CodeDuplicateKey, rec.Code, rec.Body.String())
+	}
+
+	// Bodies that are not JSON are left to the handler to reject
+	if rec := postWithKey(router, "retry-1", `{"hash":`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+	if rec := postWithKey(router, "retry-1", `{"hash":"`+hexHash("a")+`"}`); rec.Code != http.StatusCreated {
+		t.Errorf("expected the key to be unused, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
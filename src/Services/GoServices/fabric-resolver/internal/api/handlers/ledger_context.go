@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+// TenantHeader names the tenant of a request when tenants share the resolver.
+const TenantHeader = "X-Tenant-ID"
+
+type ledgerContextKey struct{}
+
+// WithLedger makes handlers serve the request from client instead of the ledger
+// they were constructed with, as the tenancy middleware does with the ledger of
+// the request's tenant.
+func WithLedger(ctx context.Context, client fabric.LedgerClient) context.Context {
+	return context.WithValue(ctx, ledgerContextKey{}, client)
+}
+
+// LedgerFrom returns the ledger attached with WithLedger, or fallback when there is none.
+func LedgerFrom(ctx context.Context, fallback fabric.LedgerClient) fabric.LedgerClient {
+	if client, ok := ctx.Value(ledgerContextKey{}).(fabric.LedgerClient); ok {
+		return client
+	}
+	return fallback
+}
+
+type tenantContextKey struct{}
+
+// WithTenant records the tenant the request was made for.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// Tenant returns the tenant of the request, or "" when tenancy is off.
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenant
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/pkg/merkle"
+)
+
+// maxMerkleLeaves bounds the size of a single Merkle batch.
+const maxMerkleLeaves = 10000
+
+// sha256HexLen is the length of a hex-encoded SHA-256 digest.
+const sha256HexLen = 64
+
+type MerkleBatchRequest struct {
+	Leaves    []string        `json:"leaves"` // hex-encoded SHA-256 document hashes
+	IssuerDID string          `json:"issuerDid,omitempty"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+}
+
+type MerkleProofStepDto struct {
+	Hash     string `json:"hash"`
+	Position string `json:"position"` // "left" or "right" of the running hash
+}
+
+type MerkleLeafProof struct {
+	Leaf  string               `json:"leaf"`
+	Proof []MerkleProofStepDto `json:"proof"`
+}
+
+type MerkleBatchResponse struct {
+	Root        string            `json:"root"`
+	TxID        string            `json:"txId"`
+	BlockNumber uint64            `json:"blockNumber"`
+	Proofs      []MerkleLeafProof `json:"proofs"`
+}
+
+type MerkleVerifyRequest struct {
+	Leaf  string               `json:"leaf"`
+	Proof []MerkleProofStepDto `json:"proof"`
+	Root  string               `json:"root"`
+}
+
+type MerkleVerifyResponse struct {
+	Root       string `json:"root"`
+	Anchored   bool   `json:"anchored"`
+	ProofValid bool   `json:"proofValid"`
+	Valid      bool   `json:"valid"` // anchored && proofValid
+}
+
+// POST /anchors/merkle-batch
+//
+// Builds a Merkle tree over the leaves, anchors only the root and returns an inclusion proof per leaf.
+func (h *AnchorHandler) CreateMerkleBatch(w http.ResponseWriter, r *http.Request) {
+	var req MerkleBatchRequest
+	if err := decodeJSON(r.Body, &req, h.strictJSON); err != nil {
+		respondBodyError(w, err, CodeInvalidBody, "Invalid request body")
+		return
+	}
+	if len(req.Leaves) == 0 || len(req.Leaves) > maxMerkleLeaves {
+		respondError(w, http.StatusBadRequest, "leaves must contain between 1 and "+strconv.Itoa(maxMerkleLeaves)+" hashes")
+		return
+	}
+
+	leaves := make([][]byte, len(req.Leaves))
+	for i, leaf := range req.Leaves {
+		b, err := decodeSHA256Hex(leaf)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("leaves[%d]: %v", i, err))
+			return
+		}
+		leaves[i] = b
+	}
+
+	metadata, metadataHash, err := canonicalMetadata(req.Metadata, h.maxMetadataBytes)
+	if err != nil {
+		respondError(w, anchorRequestStatus(err), err.Error())
+		return
+	}
+
+	tree, err := merkle.New(leaves)
+	if err != nil {
+		if errors.Is(err, merkle.ErrDuplicateLeaf) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to build Merkle tree: "+err.Error())
+		return
+	}
+
+	SetAuditResource(r.Context(), hex.EncodeToString(tree.Root()))
+	anchor := &domain.Anchor{
+		Hash:      hex.EncodeToString(tree.Root()),
+		Algorithm: domain.HashSHA256,
+		IssuerDID: req.IssuerDID,
+
+		Metadata:     metadata,
+		MetadataHash: metadataHash,
+	}
+	txID, blockNumber, err := h.ledger(r.Context()).CreateAnchor(r.Context(), anchor)
+	if err != nil {
+		respondLedgerError(w, err, "Failed to anchor Merkle root")
+		return
+	}
+
+	resp := MerkleBatchResponse{
+		Root:        anchor.Hash,
+		TxID:        txID,
+		BlockNumber: blockNumber,
+		Proofs:      make([]MerkleLeafProof, len(leaves)),
+	}
+	for i := range leaves {
+		proof, err := tree.Proof(i)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to build proof: "+err.Error())
+			return
+		}
+		resp.Proofs[i] = MerkleLeafProof{
+			Leaf:  hex.EncodeToString(leaves[i]),
+			Proof: toProofDto(proof),
+		}
+	}
+
+	respondJSON(w, http.StatusCreated, resp)
+}
+
+// POST /anchors/merkle-verify
+func (h *AnchorHandler) VerifyMerkleProof(w http.ResponseWriter, r *http.Request) {
+	var req MerkleVerifyRequest
+	if err := decodeJSON(r.Body, &req, h.strictJSON); err != nil {
+		respondBodyError(w, err, CodeInvalidBody, "Invalid request body")
+		return
+	}
+
+	leaf, err := decodeSHA256Hex(req.Leaf)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "leaf: "+err.Error())
+		return
+	}
+	root, err := decodeSHA256Hex(req.Root)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "root: "+err.Error())
+		return
+	}
+	proof, err := fromProofDto(req.Proof)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := MerkleVerifyResponse{
+		Root:       hex.EncodeToString(root),
+		ProofValid: merkle.Verify(leaf, proof, root),
+	}
+	resp.Anchored = h.ledger(r.Context()).VerifyAnchor(r.Context(), resp.Root)
+	resp.Valid = resp.Anchored && resp.ProofValid
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// decodeSHA256Hex decodes a hex SHA-256 digest, accepting an optional 0x prefix.
+func decodeSHA256Hex(s string) ([]byte, error) {
+	b, err := merkle.DecodeHex(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != sha256HexLen/2 {
+		return nil, fmt.Errorf("expected a %d character hex SHA-256 hash", sha256HexLen)
+	}
+	return b, nil
+}
+
+func toProofDto(proof []merkle.ProofStep) []MerkleProofStepDto {
+	dto := make([]MerkleProofStepDto, len(proof))
+	for i, step := range proof {
+		dto[i] = MerkleProofStepDto{Hash: hex.EncodeToString(step.Hash), Position: "right"}
+		if step.Left {
+			dto[i].Position = "left"
+		}
+	}
+	return dto
+}
+
+func fromProofDto(dto []MerkleProofStepDto) ([]merkle.ProofStep, error) {
+	proof := make([]merkle.ProofStep, len(dto))
+	for i, step := range dto {
+		b, err := decodeSHA256Hex(step.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("proof[%d]: %v", i, err)
+		}
+		switch step.Position {
+		case "left":
+			proof[i] = merkle.ProofStep{Hash: b, Left: true}
+		case "right":
+			proof[i] = merkle.ProofStep{Hash: b}
+		default:
+			return nil, fmt.Errorf("proof[%d]: position must be \"left\" or \"right\"", i)
+		}
+	}
+	return proof, nil
+}
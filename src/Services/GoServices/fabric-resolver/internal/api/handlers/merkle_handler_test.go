@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/infrastructure/fabric"
+
+	"github.com/gorilla/mux"
+)
+
+func newMerkleRouter(ledger fabric.LedgerClient) *mux.Router {
+	h := NewAnchorHandler(ledger, AnchorHandlerOptions{})
+	r := mux.NewRouter()
+	r.HandleFunc("/anchors/merkle-batch", h.CreateMerkleBatch).Methods("POST")
+	r.HandleFunc("/anchors/merkle-verify", h.VerifyMerkleProof).Methods("POST")
+	return r
+}
+
+func docHashes(n int) []string {
+	hashes := make([]string, n)
+	for i := range hashes {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("credential-%d", i)))
+		hashes[i] = hex.EncodeToString(sum[:])
+	}
+	return hashes
+}
+
+func postJSON(t *testing.T, h http.Handler, target string, v interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	return doRequest(t, h, "POST", target, bytes.NewReader(body))
+}
+
+func TestMerkleBatchAndVerify(t *testing.T) {
+	ledger := newTestLedger(t)
+	router := newMerkleRouter(ledger)
+
+	// 1 leaf has an empty proof, 5 leaves leave an odd node at two levels
+	for _, n := range []int{1, 2, 5} {
+		leaves := docHashes(n)
+		rec := postJSON(t, router, "/anchors/merkle-batch", MerkleBatchRequest{Leaves: leaves})
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("n=%d: expected 201, got %d: %s", n, rec.Code, rec.Body.String())
+		}
+		batch := decodeBody[MerkleBatchResponse](t, rec)
+		if batch.TxID == "" || len(batch.Proofs) != n {
+			t.Fatalf("n=%d: unexpected response %+v", n, batch)
+		}
+		if !ledger.VerifyAnchor(t.Context(), batch.Root) {
+			t.Errorf("n=%d: root was not anchored", n)
+		}
+
+		for i, p := range batch.Proofs {
+			if p.Leaf != leaves[i] {
+				t.Errorf("n=%d: proof %d is for leaf %s, want %s", n, i, p.Leaf, leaves[i])
+			}
+			verify := decodeBody[MerkleVerifyResponse](t, postJSON(t, router, "/anchors/merkle-verify",
+				MerkleVerifyRequest{Leaf: p.Leaf, Proof: p.Proof, Root: batch.Root}))
+			if !verify.Valid || !verify.Anchored || !verify.ProofValid {
+				t.Errorf("n=%d: leaf %d did not verify: %+v", n, i, verify)
+			}
+		}
+	}
+}
+
+func TestMerkleVerify_Failures(t *testing.T) {
+	ledger := newTestLedger(t)
+	router := newMerkleRouter(ledger)
+
+	leaves := docHashes(4)
+	batch := decodeBody[MerkleBatchResponse](t, postJSON(t, router, "/anchors/merkle-batch", MerkleBatchRequest{Leaves: leaves}))
+
+	// Right proof, wrong leaf
+	wrongLeaf := decodeBody[MerkleVerifyResponse](t, postJSON(t, router, "/anchors/merkle-verify",
+		MerkleVerifyRequest{Leaf: leaves[1], Proof: batch.Proofs[0].Proof, Root: batch.Root}))
+	if wrongLeaf.Valid || wrongLeaf.ProofValid || !wrongLeaf.Anchored {
+		t.Errorf("unexpected result for wrong leaf: %+v", wrongLeaf)
+	}
+
+	// Consistent proof for a root that was never anchored
+	single := docHashes(5)[4]
+	sum := sha256.Sum256(append([]byte{0x00}, mustHex(t, single)...))
+	unanchored := decodeBody[MerkleVerifyResponse](t, postJSON(t, router, "/anchors/merkle-verify",
+		MerkleVerifyRequest{Leaf: single, Proof: []MerkleProofStepDto{}, Root: hex.EncodeToString(sum[:])}))
+	if unanchored.Valid || !unanchored.ProofValid || unanchored.Anchored {
+		t.Errorf("unexpected result for unanchored root: %+v", unanchored)
+	}
+
+	badPosition := MerkleVerifyRequest{Leaf: leaves[0], Proof: []MerkleProofStepDto{{Hash: leaves[1], Position: "up"}}, Root: batch.Root}
+	if rec := postJSON(t, router, "/anchors/merkle-verify", badPosition); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid position, got %d", rec.Code)
+	}
+}
+
+func TestMerkleBatch_InvalidLeaves(t *testing.T) {
+	router := newMerkleRouter(newTestLedger(t))
+	leaves := docHashes(2)
+
+	for name, req := range map[string]MerkleBatchRequest{
+		"empty":     {},
+		"duplicate": {Leaves: []string{leaves[0], leaves[1], leaves[0]}},
+		"not hex":   {Leaves: []string{"zz"}},
+		"too short": {Leaves: []string{strings.Repeat("ab", 16)}},
+	} {
+		if rec := postJSON(t, router, "/anchors/merkle-batch", req); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, rec.Code)
+		}
+	}
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
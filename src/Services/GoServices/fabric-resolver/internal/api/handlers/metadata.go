@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"fabric-resolver/internal/pkg/canonicalizer"
+)
+
+// DefaultMaxMetadataBytes caps the canonical size of anchor metadata when no limit is configured.
+const DefaultMaxMetadataBytes = 4 << 10
+
+// errMetadataTooLarge is answered with 413 Request Entity Too Large.
+var errMetadataTooLarge = errors.New("metadata too large")
+
+// canonicalMetadata canonicalizes metadata and returns it with its SHA-256 hash.
+// The canonical form follows the canonicalizer policy, so metadata that differs
+// only in key order or whitespace gets the same bytes and hash. Empty or null
+// metadata is treated as absent.
+func canonicalMetadata(raw json.RawMessage, maxBytes int) (json.RawMessage, string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, "", nil
+	}
+
+	canonical, err := canonicalizer.CanonicalizeJSON(raw)
+	if errors.Is(err, canonicalizer.ErrTooLarge) || errors.Is(err, canonicalizer.ErrTooDeep) {
+		return nil, "", fmt.Errorf("%w: %w", errMetadataTooLarge, err)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("metadata must be a single JSON value: %w", err)
+	}
+	if len(canonical) > maxBytes {
+		return nil, "", fmt.Errorf("%w: %d bytes exceeds the limit of %d", errMetadataTooLarge, len(canonical), maxBytes)
+	}
+
+	sum := sha256.Sum256(canonical)
+	hash := hex.EncodeToString(sum[:])
+	return canonical, hash, nil
+}
+
+// anchorRequestStatus maps a ToAnchor error to its HTTP status.
+func anchorRequestStatus(err error) int {
+	if errors.Is(err, errMetadataTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
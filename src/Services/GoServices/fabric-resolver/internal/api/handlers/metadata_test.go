@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCreateAnchor_MetadataHashIgnoresKeyOrder(t *testing.T) {
+	router := newAnchorRouter(newTestLedger(t))
+
+	first := doRequest(t, router, "POST", "/anchors",
+		strings.NewReader(`{"hash":"`+hexHash("meta-1")+`","metadata":{"type":"diploma","issued":{"year":2024,"month":6}}}`))
+	second := doRequest(t, router, "POST", "/anchors",
+		strings.NewReader(`{"hash":"`+hexHash("meta-2")+`","metadata":{ "issued": {"month":6, "year":2024}, "type":"diploma" }}`))
+	if first.Code != http.StatusCreated || second.Code != http.StatusCreated {
+		t.Fatalf("expected 201s, got %d and %d", first.Code, second.Code)
+	}
+
+	a := decodeBody[AnchorResponse](t, first)
+	b := decodeBody[AnchorResponse](t, second)
+	if a.MetadataHash == "" || a.MetadataHash != b.MetadataHash {
+		t.Errorf("expected equal metadata hashes, got %q and %q", a.MetadataHash, b.MetadataHash)
+	}
+	if want := `{"issued":{"month":6,"year":2024},"type":"diploma"}`; string(b.Metadata) != want {
+		t.Errorf("expected canonical metadata %s, got %s", want, b.Metadata)
+	}
+	sum := sha256.Sum256(b.Metadata)
+	if hex.EncodeToString(sum[:]) != b.MetadataHash {
+		t.Error("metadataHash is not the SHA-256 of the canonical metadata")
+	}
+}
+
+func TestGetAnchor_ReturnsMetadataAsJSON(t *testing.T) {
+	router := newAnchorRouter(newTestLedger(t))
+
+	doRequest(t, router, "POST", "/anchors", strings.NewReader(`{"hash":"`+hexHash("meta-get")+`","metadata":{"b":[1.0,"x"],"a":null}}`))
+
+	rec := doRequest(t, router, "GET", "/anchors/"+hexHash("meta-get"), nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"metadata":{"a":null,"b":[1.0,"x"]}`) {
+		t.Errorf("metadata not returned as JSON: %s", rec.Body.String())
+	}
+}
+
+func TestCreateAnchor_OversizedMetadata(t *testing.T) {
+	ledger := newTestLedger(t)
+	h := NewAnchorHandler(ledger, AnchorHandlerOptions{MaxMetadataBytes: 32})
+
+	body := `{"hash":"` + hexHash("too-big") + `","metadata":{"note":"` + strings.Repeat("x", 32) + `"}}`
+	rec := doRequest(t, http.HandlerFunc(h.CreateAnchor), "POST", "/anchors", strings.NewReader(body))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ledger.VerifyAnchor(t.Context(), hexHash("too-big")) {
+		t.Error("oversized anchor must not be written")
+	}
+
+	// Whitespace is not counted, only the canonical form
+	padded := `{"hash":"` + hexHash("fits") + `","metadata":{ "n" : "` + strings.Repeat("x", 20) + `"          }}`
+	if rec := doRequest(t, http.HandlerFunc(h.CreateAnchor), "POST", "/anchors", strings.NewReader(padded)); rec.Code != http.StatusCreated {
+		t.Errorf("expected 201 for metadata within the limit, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	batch := `[{"hash":"` + hexHash("batch-ok") + `"},{"hash":"` + hexHash("batch-big") + `","metadata":"` + strings.Repeat("y", 64) + `"}]`
+	res := decodeBody[BatchAnchorResponse](t, doRequest(t, http.HandlerFunc(h.CreateAnchorsBatch), "POST", "/anchors/batch", strings.NewReader(batch)))
+	if res.Created != 1 || res.Results[1].Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("unexpected batch result: %+v", res)
+	}
+
+	// Nesting beyond the canonicalizer's limit is too large whatever its size
+	deep := strings.Repeat("[", 200) + strings.Repeat("]", 200)
+	rec = doRequest(t, newAnchorRouter(ledger), "POST", "/anchors", strings.NewReader(`{"hash":"`+hexHash("too-deep")+`","metadata":`+deep+`}`))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for deep metadata, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if resp := decodeBody[ErrorResponse](t, rec); resp.Code != CodeMetadataTooLarge {
+		t.Errorf("expected code %q, got %q", CodeMetadataTooLarge, resp.Code)
+	}
+}
+
+func TestCreateAnchor_LegacyStringMetadata(t *testing.T) {
+	router := newAnchorRouter(newTestLedger(t))
+
+	rec := doRequest(t, router, "POST", "/anchors", strings.NewReader(`{"hash":"`+hexHash("meta-str")+`","metadata":"plain text"}`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	if got := decodeBody[AnchorResponse](t, rec); string(got.Metadata) != `"plain text"` {
+		t.Errorf("expected string metadata to round-trip, got %s", got.Metadata)
+	}
+
+	if rec := doRequest(t, router, "POST", "/anchors", strings.NewReader(`{"hash":"`+hexHash("meta-bad")+`","metadata":{"a":}}`)); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for malformed metadata, got %d", rec.Code)
+	}
+}
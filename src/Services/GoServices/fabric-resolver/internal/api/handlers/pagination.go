@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// List endpoints share one envelope, {items, nextCursor, total}, and announce the
+// next page with a Link header (RFC 8288) as well as in nextCursor.
+//
+// Cursors are the ledger's cursor (a block number or a creation time and DID)
+// encoded as unpadded base64url, so clients treat them as opaque tokens.
+
+// encodeCursor turns a ledger cursor into the cursor handed to clients.
+func encodeCursor(ledgerCursor string) string {
+	if ledgerCursor == "" {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(ledgerCursor))
+}
+
+// decodeCursor reverses encodeCursor. ok is false if cursor is not base64url.
+func decodeCursor(cursor string) (ledgerCursor string, ok bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", false
+	}
+	return string(raw), true
+}
+
+// parseListOptions reads the limit and cursor query parameters shared by list endpoints.
+// Limits above maxListLimit are clamped to it. It writes a 400 response and returns
+// false if the limit is not a positive integer or the cursor is malformed.
+func parseListOptions(w http.ResponseWriter, r *http.Request) (fabric.ListOptions, bool) {
+	opts := fabric.ListOptions{Limit: defaultListLimit}
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			respondError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return opts, false
+		}
+		opts.Limit = min(n, maxListLimit)
+	}
+
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		cursor, ok := decodeCursor(v)
+		if !ok {
+			respondError(w, http.StatusBadRequest, "Invalid cursor")
+			return opts, false
+		}
+		opts.Cursor = cursor
+	}
+	return opts, true
+}
+
+// setNextLink adds a Link header pointing at the page after the current one: the
+// request URL with its cursor replaced by nextCursor. The last page has none.
+func setNextLink(w http.ResponseWriter, r *http.Request, nextCursor string) {
+	if nextCursor == "" {
+		return
+	}
+	q := r.URL.Query()
+	q.Set("cursor", nextCursor)
+	next := r.URL.Path + "?" + q.Encode()
+	w.Header().Add("Link", "<"+next+`>; rel="next"`)
+}
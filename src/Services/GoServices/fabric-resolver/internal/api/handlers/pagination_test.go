@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	for _, ledgerCursor := range []string{"42", "1700000000000000000:did:ewallet:1"} {
+		cursor := encodeCursor(ledgerCursor)
+		if cursor == ledgerCursor || strings.ContainsAny(cursor, "+/=:") {
+			t.Errorf("%q: cursor %q is not opaque and URL-safe", ledgerCursor, cursor)
+		}
+
+		req := httptest.NewRequest("GET", "/anchors?cursor="+cursor, nil)
+		opts, ok := parseListOptions(httptest.NewRecorder(), req)
+		if !ok || opts.Cursor != ledgerCursor {
+			t.Errorf("%q: decoded to %q (ok=%v)", ledgerCursor, opts.Cursor, ok)
+		}
+	}
+
+	if encodeCursor("") != "" {
+		t.Error("expected an empty ledger cursor to stay empty")
+	}
+
+	rec := httptest.NewRecorder()
+	if _, ok := parseListOptions(rec, httptest.NewRequest("GET", "/anchors?cursor=%25%25", nil)); ok || rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a non-base64 cursor, got %d", rec.Code)
+	}
+}
+
+func TestParseListOptions_Limit(t *testing.T) {
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", defaultListLimit},
+		{"limit=1", 1},
+		{"limit=500", maxListLimit},
+		{"limit=501", maxListLimit},
+		{"limit=100000", maxListLimit},
+	}
+	for _, tt := range tests {
+		opts, ok := parseListOptions(httptest.NewRecorder(), httptest.NewRequest("GET", "/anchors?"+tt.query, nil))
+		if !ok || opts.Limit != tt.want {
+			t.Errorf("%q: expected limit %d, got %d (ok=%v)", tt.query, tt.want, opts.Limit, ok)
+		}
+	}
+}
+
+func TestListAnchors_LinkHeader(t *testing.T) {
+	ledger := newTestLedger(t)
+	seedAnchors(t, ledger, 3)
+	router := newAnchorRouter(ledger)
+
+	rec := doRequest(t, router, "GET", "/anchors?limit=2", nil)
+	first := decodeBody[AnchorPageResponse](t, rec)
+	link := rec.Header().Get("Link")
+	if !strings.HasSuffix(link, `>; rel="next"`) || !strings.HasPrefix(link, "</anchors?") {
+		t.Fatalf("unexpected Link header %q", link)
+	}
+
+	next, err := url.Parse(strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`))
+	if err != nil {
+		t.Fatalf("invalid next link: %v", err)
+	}
+	if next.Query().Get("cursor") != first.NextCursor || next.Query().Get("limit") != "2" {
+		t.Errorf("next link %q does not carry the cursor and limit", next)
+	}
+
+	// Following the link reaches the final page, which has neither a cursor nor a link
+	rec = doRequest(t, router, "GET", next.String(), nil)
+	last := decodeBody[AnchorPageResponse](t, rec)
+	if len(last.Items) != 1 || last.Items[0].Hash != "hash-03" || last.NextCursor != "" {
+		t.Fatalf("unexpected final page: %+v", last)
+	}
+	if link := rec.Header().Get("Link"); link != "" {
+		t.Errorf("expected no Link header on the final page, got %q", link)
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/pkg/multibase"
+	"fabric-resolver/internal/pkg/receipt"
+
+	"github.com/gorilla/mux"
+)
+
+// ReceiptHandler issues signed anchor receipts, which auditors verify offline with
+// receipt.VerifyReceipt or any Ed25519 implementation.
+type ReceiptHandler struct {
+	ledgerClient fabric.LedgerClient
+	signer       *receipt.Signer
+	now          func() time.Time // replaced in tests
+}
+
+// NewReceiptHandler returns a handler signing with signer. A nil signer answers 404.
+func NewReceiptHandler(ledgerClient fabric.LedgerClient, signer *receipt.Signer) *ReceiptHandler {
+	return &ReceiptHandler{ledgerClient: ledgerClient, signer: signer, now: time.Now}
+}
+
+// ledger returns the ledger of the request's tenant, or the handler's own.
+func (h *ReceiptHandler) ledger(ctx context.Context) fabric.LedgerClient {
+	return LedgerFrom(ctx, h.ledgerClient)
+}
+
+// ReceiptPublicKeyResponse is the body of GET /receipts/public-key.
+type ReceiptPublicKeyResponse struct {
+	KeyID              string      `json:"keyId"`
+	Type               string      `json:"type"`
+	PublicKeyMultibase string      `json:"publicKeyMultibase"`
+	PublicKeyJwk       *domain.JWK `json:"publicKeyJwk"`
+}
+
+// GET /anchors/{hash}/receipt
+func (h *ReceiptHandler) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	if h.signer == nil {
+		respondError(w, http.StatusNotFound, "Receipts are not enabled")
+		return
+	}
+	hash := domain.NormalizeHash(mux.Vars(r)["hash"])
+	if hash == "" {
+		respondError(w, http.StatusBadRequest, "Hash is required")
+		return
+	}
+
+	anchor, err := h.ledger(r.Context()).GetAnchor(r.Context(), hash)
+	if err != nil {
+		if errors.Is(err, fabric.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "Anchor not found")
+			return
+		}
+		respondLedgerError(w, err, "Failed to read anchor")
+		return
+	}
+
+	signed, err := h.signer.Sign(receipt.Receipt{
+		Hash:        anchor.Hash,
+		Algorithm:   anchor.Algorithm,
+		TxID:        anchor.TxID,
+		BlockNumber: anchor.BlockNumber,
+		Timestamp:   anchor.Timestamp.UTC().Format(time.RFC3339Nano),
+		IssuerDID:   anchor.IssuerDID,
+		IssuedAt:    h.now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		slog.Error("Failed to sign receipt", "hash", hash, "err", err)
+		respondError(w, http.StatusInternalServerError, "Failed to sign receipt")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, signed)
+}
+
+// GET /receipts/public-key
+func (h *ReceiptHandler) GetPublicKey(w http.ResponseWriter, r *http.Request) {
+	if h.signer == nil {
+		respondError(w, http.StatusNotFound, "Receipts are not enabled")
+		return
+	}
+	pub := h.signer.PublicKey()
+	respondJSON(w, http.StatusOK, ReceiptPublicKeyResponse{
+		KeyID:              h.signer.KeyID(),
+		Type:               "Ed25519VerificationKey2020",
+		PublicKeyMultibase: multibase.Encode(append([]byte{0xed, 0x01}, pub...)),
+		PublicKeyJwk:       &domain.JWK{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(pub)},
+	})
+}
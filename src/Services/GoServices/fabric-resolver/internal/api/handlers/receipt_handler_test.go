@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/pkg/receipt"
+
+	"github.com/gorilla/mux"
+)
+
+func newReceiptRouter(t *testing.T, ledger fabric.LedgerClient, signer *receipt.Signer) *mux.Router {
+	t.Helper()
+	h := NewReceiptHandler(ledger, signer)
+	r := mux.NewRouter()
+	r.HandleFunc("/anchors/{hash}/receipt", h.GetReceipt).Methods("GET")
+	r.HandleFunc("/receipts/public-key", h.GetPublicKey).Methods("GET")
+	return r
+}
+
+func TestGetReceipt_VerifiesWithPublishedKey(t *testing.T) {
+	ledger := newTestLedger(t)
+	seedAnchors(t, ledger, 1)
+	signer, err := receipt.LoadOrCreateKey(filepath.Join(t.TempDir(), "receipt-key.pem"))
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey failed: %v", err)
+	}
+	router := newReceiptRouter(t, ledger, signer)
+
+	rec := doRequest(t, router, "GET", "/anchors/hash-01/receipt", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	signed := decodeBody[receipt.SignedReceipt](t, rec)
+	if signed.Receipt.Hash != "hash-01" || signed.Receipt.TxID == "" || signed.Receipt.BlockNumber == 0 || signed.Receipt.IssuedAt == "" {
+		t.Errorf("unexpected receipt %+v", signed.Receipt)
+	}
+
+	// A verifier only needs the published key
+	key := decodeBody[ReceiptPublicKeyResponse](t, doRequest(t, router, "GET", "/receipts/public-key", nil))
+	pub, err := base64.RawURLEncoding.DecodeString(key.PublicKeyJwk.X)
+	if err != nil || key.KeyID != signed.KeyID {
+		t.Fatalf("unexpected public key %+v", key)
+	}
+	if err := receipt.VerifyReceipt(&signed, ed25519.PublicKey(pub)); err != nil {
+		t.Errorf("receipt does not verify with the published key: %v", err)
+	}
+
+	if rec := doRequest(t, router, "GET", "/anchors/unknown/receipt", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown anchor, got %d", rec.Code)
+	}
+}
+
+func TestGetReceipt_Disabled(t *testing.T) {
+	router := newReceiptRouter(t, newTestLedger(t), nil)
+	for _, path := range []string{"/anchors/hash-01/receipt", "/receipts/public-key"} {
+		if rec := doRequest(t, router, "GET", path, nil); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404 without a signer, got %d", path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/pkg/didkey"
+	"fabric-resolver/internal/pkg/didweb"
+)
+
+const (
+	// resolutionProfile marks an Accept header asking for the DID Resolution result envelope.
+	resolutionProfile     = "https://w3id.org/did-resolution"
+	resolutionContentType = `application/ld+json;profile="` + resolutionProfile + `"`
+	didLDContentType      = "application/did+ld+json"
+	didJSONContentType    = "application/did+json"
+)
+
+// didCoreContext must be the first @context entry of every DID document.
+const didCoreContext = "https://www.w3.org/ns/did/v1"
+
+// Error codes defined by the DID Resolution spec
+const (
+	resolutionErrNotFound   = "notFound"
+	resolutionErrInvalidDid = "invalidDid"
+	resolutionErrInternal   = "internalError"
+)
+
+// ResolutionResult is the W3C DID Resolution result envelope.
+type ResolutionResult struct {
+	Context               string                `json:"@context"`
+	DidDocument           *DidDocumentResponse  `json:"didDocument"`
+	DidResolutionMetadata DidResolutionMetadata `json:"didResolutionMetadata"`
+	DidDocumentMetadata   DidDocumentMetadata   `json:"didDocumentMetadata"`
+}
+
+type DidResolutionMetadata struct {
+	ContentType string `json:"contentType,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Source      string `json:"source,omitempty"` // where the document came from: "ledger", "did:web" or "did:key"
+}
+
+// Sources of a resolved DID document, as LookupDid reports them
+const (
+	SourceLedger = "ledger"
+	SourceDidWeb = "did:web"
+	SourceDidKey = "did:key"
+)
+
+// lookupDid finds the document for did with LookupDid.
+func (h *DidHandler) lookupDid(ctx context.Context, did string) (*domain.DIDDocument, string, error) {
+	return LookupDid(ctx, h.ledger(ctx), h.webResolver, did)
+}
+
+// LookupDid finds the document for did as GET /dids/{did} and the gRPC ResolveDid
+// both do. did:key documents are derived from the key without touching ledger;
+// did:web documents that are not stored are fetched with web, unless it is nil.
+// The returned source says which of them answered (or failed).
+func LookupDid(ctx context.Context, ledger fabric.LedgerClient, web *didweb.Resolver, did string) (*domain.DIDDocument, string, error) {
+	if didkey.IsDIDKey(did) {
+		doc, err := didkey.Resolve(did)
+		return doc, SourceDidKey, err
+	}
+
+	doc, err := ledger.GetDid(ctx, did)
+	if err == nil || !errors.Is(err, fabric.ErrNotFound) || web == nil || !strings.HasPrefix(did, "did:web:") {
+		return doc, SourceLedger, err
+	}
+
+	doc, err = web.Resolve(ctx, did)
+	return doc, SourceDidWeb, err
+}
+
+// IsDidNotFound reports whether err means the DID does not exist, on the ledger or on the web.
+func IsDidNotFound(err error) bool {
+	return errors.Is(err, fabric.ErrNotFound) || errors.Is(err, didweb.ErrNotFound)
+}
+
+type DidDocumentMetadata struct {
+	Created     string `json:"created,omitempty"`
+	Updated     string `json:"updated,omitempty"`
+	Deactivated bool   `json:"deactivated,omitempty"`
+	VersionID   string `json:"versionId,omitempty"`
+}
+
+// wantsResolutionResult reports whether the caller asked for the resolution envelope,
+// either with ?envelope=true or an Accept header carrying the did-resolution profile.
+func wantsResolutionResult(r *http.Request) bool {
+	if v, err := strconv.ParseBool(r.URL.Query().Get("envelope")); err == nil && v {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), resolutionProfile)
+}
+
+// negotiateDidContentType picks the media type for a bare DID document from the
+// Accept header. Unknown or missing values fall back to application/json.
+func negotiateDidContentType(r *http.Request) string {
+	best, bestQ := "application/json", 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if mediaType != didLDContentType && mediaType != didJSONContentType {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ {
+			best, bestQ = mediaType, q
+		}
+	}
+	return best
+}
+
+// normalizeContext returns contexts with the DID core context first and duplicates removed.
+func normalizeContext(contexts []string) []string {
+	out := []string{didCoreContext}
+	for _, c := range contexts {
+		if c != "" && !slices.Contains(out, c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// resolveDidResult answers GET /dids/{did} with a ResolutionResult.
+func (h *DidHandler) resolveDidResult(w http.ResponseWriter, r *http.Request, did string) {
+	if _, _, err := domain.ParseDID(did); err != nil {
+		respondResolutionError(w, http.StatusBadRequest, resolutionErrInvalidDid)
+		return
+	}
+
+	didDoc, source, err := h.lookupDid(r.Context(), did)
+	if err != nil {
+		switch {
+		case source == SourceDidKey:
+			respondResolutionError(w, http.StatusBadRequest, resolutionErrInvalidDid)
+		case IsDidNotFound(err):
+			respondResolutionError(w, http.StatusNotFound, resolutionErrNotFound)
+		case source == SourceDidWeb:
+			respondResolutionError(w, http.StatusBadGateway, resolutionErrInternal)
+		default:
+			respondResolutionError(w, http.StatusInternalServerError, resolutionErrInternal)
+		}
+		return
+	}
+
+	doc := toDidDocumentResponse(didDoc)
+	respondJSONWithETag(w, r, resolutionContentType, ResolutionResult{
+		Context:               resolutionProfile + "/v1",
+		DidDocument:           &doc,
+		DidResolutionMetadata: DidResolutionMetadata{ContentType: didLDContentType, Source: source},
+		DidDocumentMetadata: DidDocumentMetadata{
+			Created:     doc.Created,
+			Updated:     doc.Updated,
+			Deactivated: didDoc.Deactivated,
+			VersionID:   strconv.FormatUint(max(didDoc.VersionID, 1), 10),
+		},
+	})
+}
+
+func respondResolutionError(w http.ResponseWriter, status int, code string) {
+	respondJSONAs(w, status, resolutionContentType, ResolutionResult{
+		Context:               resolutionProfile + "/v1",
+		DidResolutionMetadata: DidResolutionMetadata{Error: code},
+	})
+}
@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/pkg/didweb"
+)
+
+func TestResolveDid_BareDocumentByDefault(t *testing.T) {
+	ledger := newTestLedger(t)
+	seedDids(t, ledger, &domain.DIDDocument{ID: "did:ewallet:bare"})
+
+	rec := doRequest(t, newDidRouter(ledger), "GET", "/dids/did:ewallet:bare", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected response: %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if strings.Contains(rec.Body.String(), "didDocument") {
+		t.Errorf("default response should be the bare document: %s", rec.Body.String())
+	}
+	if doc := decodeBody[DidDocumentResponse](t, rec); doc.ID != "did:ewallet:bare" {
+		t.Errorf("unexpected document: %+v", doc)
+	}
+}
+
+func TestResolveDid_ResolutionResult(t *testing.T) {
+	ledger := newTestLedger(t)
+	seedDids(t, ledger, &domain.DIDDocument{ID: "did:ewallet:env"})
+	router := newDidRouter(ledger)
+
+	if err := ledger.UpdateDid(context.Background(), &domain.DIDDocument{ID: "did:ewallet:env"}, 1); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		path   string
+		accept string
+	}{
+		{"query parameter", "/dids/did:ewallet:env?envelope=true", ""},
+		{"accept profile", "/dids/did:ewallet:env", resolutionContentType},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.path, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != resolutionContentType {
+				t.Errorf("unexpected Content-Type %q", ct)
+			}
+
+			res := decodeBody[ResolutionResult](t, rec)
+			if res.DidDocument == nil || res.DidDocument.ID != "did:ewallet:env" {
+				t.Fatalf("missing didDocument: %+v", res)
+			}
+			if res.DidResolutionMetadata.ContentType != didLDContentType || res.DidResolutionMetadata.Error != "" {
+				t.Errorf("unexpected resolution metadata: %+v", res.DidResolutionMetadata)
+			}
+			meta := res.DidDocumentMetadata
+			if meta.VersionID != "2" || meta.Created == "" || meta.Updated == "" || meta.Deactivated {
+				t.Errorf("unexpected document metadata: %+v", meta)
+			}
+		})
+	}
+}
+
+func TestResolveDid_ResolutionErrors(t *testing.T) {
+	router := newDidRouter(newTestLedger(t))
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		code   string
+	}{
+		{"not found", "/dids/did:ewallet:missing?envelope=true", http.StatusNotFound, "notFound"},
+		{"invalid DID", "/dids/banana?envelope=true", http.StatusBadRequest, "invalidDid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, router, "GET", tt.path, nil)
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, rec.Code)
+			}
+			res := decodeBody[ResolutionResult](t, rec)
+			if res.DidResolutionMetadata.Error != tt.code || res.DidDocument != nil {
+				t.Errorf("unexpected result: %+v", res)
+			}
+		})
+	}
+}
+
+func TestResolveDid_ContentNegotiation(t *testing.T) {
+	ledger := newTestLedger(t)
+	seedDids(t, ledger, &domain.DIDDocument{ID: "did:ewallet:neg"})
+	router := newDidRouter(ledger)
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", "application/json"},
+		{"application/json", "application/json"},
+		{"application/did+ld+json", "application/did+ld+json"},
+		{"application/did+json", "application/did+json"},
+		{"application/json, application/did+ld+json;q=0.9", "application/did+ld+json"},
+		{"application/did+json;q=0.5, application/did+ld+json;q=0.8", "application/did+ld+json"},
+		{"text/html", "application/json"},
+		{"not a media type", "application/json"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/dids/did:ewallet:neg", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Accept %q: expected 200, got %d", tt.accept, rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != tt.want {
+			t.Errorf("Accept %q: expected Content-Type %s, got %s", tt.accept, tt.want, ct)
+		}
+	}
+}
+
+func TestResolveDid_ContextPassthrough(t *testing.T) {
+	ledger := newTestLedger(t)
+	router := newDidRouter(ledger)
+
+	body := `{"@context":["https://w3id.org/security/suites/ed25519-2020/v1","https://www.w3.org/ns/did/v1"],
+		"did":"did:ewallet:ctx","verificationMethod":[{"type":"Ed25519VerificationKey2020","publicKeyMultibase":"z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}]}`
+	if rec := doRequest(t, router, "POST", "/dids", strings.NewReader(body)); rec.Code != http.StatusCreated {
+		t.Fatalf("create failed: %d %s", rec.Code, rec.Body.String())
+	}
+	// Documents stored without a context still get the core context
+	seedDids(t, ledger, &domain.DIDDocument{ID: "did:ewallet:noctx"})
+
+	tests := []struct {
+		did  string
+		want string
+	}{
+		{"did:ewallet:ctx", "https://www.w3.org/ns/did/v1,https://w3id.org/security/suites/ed25519-2020/v1"},
+		{"did:ewallet:noctx", "https://www.w3.org/ns/did/v1"},
+	}
+	for _, tt := range tests {
+		doc := decodeBody[DidDocumentResponse](t, doRequest(t, router, "GET", "/dids/"+tt.did, nil))
+		if got := strings.Join(doc.Context, ","); got != tt.want {
+			t.Errorf("%s: expected @context %s, got %s", tt.did, tt.want, got)
+		}
+	}
+}
+
+func TestResolveDid_DidWebFallback(t *testing.T) {
+	var did string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/did.json":
+			w.Write([]byte(`{"id":"` + did + `"}`))
+		case "/bad/did.json":
+			w.Write([]byte(`{"id":"did:web:someone-else"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	did = "did:web:example.com"
+
+	// Route every host to the test server; its certificate is valid for example.com
+	client := srv.Client()
+	transport := client.Transport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+	}
+	client.Transport = transport
+
+	ledger := newTestLedger(t)
+	router := newDidRouterWith(ledger, DidHandlerOptions{WebResolver: didweb.NewResolver(client, 0)})
+
+	res := decodeBody[ResolutionResult](t, doRequest(t, router, "GET", "/dids/"+did+"?envelope=true", nil))
+	if res.DidDocument == nil || res.DidDocument.ID != did || res.DidResolutionMetadata.Source != "did:web" {
+		t.Fatalf("unexpected did:web resolution: %+v", res)
+	}
+	if rec := doRequest(t, router, "GET", "/dids/"+did, nil); rec.Code != http.StatusOK {
+		t.Errorf("bare resolve: expected 200, got %d", rec.Code)
+	}
+
+	if rec := doRequest(t, router, "GET", "/dids/"+did+":missing?envelope=true", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("missing remote document: expected 404, got %d", rec.Code)
+	}
+	if rec := doRequest(t, router, "GET", "/dids/"+did+":bad", nil); rec.Code != http.StatusBadGateway {
+		t.Errorf("mismatched id: expected 502, got %d", rec.Code)
+	}
+
+	// Disabled resolution never leaves the ledger
+	if rec := doRequest(t, newDidRouter(ledger), "GET", "/dids/"+did, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("disabled resolution: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestResolveDid_DidKey(t *testing.T) {
+	router := newDidRouter(newTestLedger(t))
+	const did = "did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"
+
+	rec := doRequest(t, router, "GET", "/dids/"+did, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	doc := decodeBody[DidDocumentResponse](t, rec)
+	if doc.ID != did || len(doc.VerificationMethod) != 1 || doc.VerificationMethod[0].PublicKeyMultibase == "" ||
+		len(doc.Authentication) != 1 || doc.Created != "" {
+		t.Errorf("unexpected derived document: %+v", doc)
+	}
+
+	res := decodeBody[ResolutionResult](t, doRequest(t, router, "GET", "/dids/"+did+"?envelope=true", nil))
+	if res.DidResolutionMetadata.Source != "did:key" {
+		t.Errorf("expected source did:key, got %+v", res.DidResolutionMetadata)
+	}
+
+	rec = doRequest(t, router, "GET", "/dids/did:key:z0OIl?envelope=true", nil)
+	if rec.Code != http.StatusBadRequest || decodeBody[ResolutionResult](t, rec).DidResolutionMetadata.Error != "invalidDid" {
+		t.Errorf("expected invalidDid for a malformed did:key, got %d", rec.Code)
+	}
+}
+
+func TestCreateDid_RejectsDidKey(t *testing.T) {
+	router := newDidRouter(newTestLedger(t))
+
+	body := `{"did":"did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"}`
+	rec := doRequest(t, router, "POST", "/dids", strings.NewReader(body))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "derived from the key") {
+		t.Errorf("expected 400 explaining did:key, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestResolveDid_ETag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	ledger, err := fabric.NewFileLedgerClient(path)
+	if err != nil {
+		t.Fatalf("failed to create ledger: %v", err)
+	}
+	seedDids(t, ledger, &domain.DIDDocument{ID: "did:ewallet:etag"})
+	router := newDidRouter(ledger)
+
+	get := func(router http.Handler, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/dids/did:ewallet:etag", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get(router, "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || len(etag) < 3 || etag[0] != '"' || strings.HasPrefix(etag, "W/") {
+		t.Fatalf("expected 200 with a strong ETag, got %d %q", first.Code, etag)
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{"match", etag, http.StatusNotModified},
+		{"match in list", `"other", ` + etag, http.StatusNotModified},
+		{"weak match", "W/" + etag, http.StatusNotModified},
+		{"wildcard", "*", http.StatusNotModified},
+		{"mismatch", `"stale"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := get(router, tt.ifNoneMatch)
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, rec.Code)
+		}
+		if tt.want == http.StatusNotModified && rec.Body.Len() != 0 {
+			t.Errorf("%s: 304 must not have a body, got %q", tt.name, rec.Body.String())
+		}
+		if rec.Header().Get("ETag") != etag {
+			t.Errorf("%s: ETag changed to %q", tt.name, rec.Header().Get("ETag"))
+		}
+	}
+
+	// Stable across restarts
+	ledger.Close()
+	reopened, err := fabric.NewFileLedgerClient(path)
+	if err != nil {
+		t.Fatalf("failed to reopen ledger: %v", err)
+	}
+	defer reopened.Close()
+	router = newDidRouter(reopened)
+	if rec := get(router, etag); rec.Code != http.StatusNotModified {
+		t.Errorf("expected ETag to survive a restart, got %d", rec.Code)
+	}
+
+	// Changes after an update
+	if err := reopened.UpdateDid(context.Background(), &domain.DIDDocument{ID: "did:ewallet:etag", Controller: "did:ewallet:new-owner"}, 1); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	rec := get(router, etag)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("expected a new ETag after update, got %d %q", rec.Code, rec.Header().Get("ETag"))
+	}
+}
@@ -1,32 +1,219 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
+
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/pkg/canonicalizer"
+	"fabric-resolver/internal/requestid"
+)
+
+// Error codes are stable identifiers of the error in ErrorResponse.Code; clients
+// should branch on them rather than on the message.
+const (
+	CodeBadRequest        = "bad_request"
+	CodeUnauthorized      = "unauthorized"
+	CodeForbidden         = "forbidden"
+	CodeNotFound          = "not_found"
+	CodeAlreadyExists     = "already_exists"
+	CodeConflict          = "conflict"
+	CodeDeactivated       = "deactivated"
+	CodeGone              = "gone"
+	CodeTooLarge          = "too_large"
+	CodeValidation        = "validation_failed"
+	CodeLedgerUnavailable = "ledger_unavailable"
+	CodeReadOnly          = "read_only"
+	CodeAuditUnavailable  = "audit_unavailable"
+	CodeRateLimited       = "rate_limited"
+	CodeTimeout           = "timeout"
+	CodeInternal          = "internal_error"
+
+	// Bearer token failures, so clients can tell a token to refresh from one to replace
+	CodeInvalidToken      = "invalid_token"
+	CodeTokenExpired      = "token_expired"
+	CodeInvalidAudience   = "invalid_audience"
+	CodeInsufficientScope = "insufficient_scope"
+
+	// Tenancy failures: no tenant named, or one the caller may not use
+	CodeTenantRequired = "tenant_required"
+	CodeUnknownTenant  = "unknown_tenant"
+
+	// Documents the canonicalizer rejects: malformed JSON, or JSON naming a member twice
+	CodeInvalidJSON  = "invalid_json"
+	CodeDuplicateKey = "duplicate_key"
 )
 
-type errorResponse struct {
-	Error string `json:"error"`
+// ErrorResponse is the body of every error answered with respondError.
+// Error repeats Message for clients written before codes were introduced.
+// RequestID is the X-Request-ID of the response, for correlating logs.
+type ErrorResponse struct {
+	Error     string        `json:"error"`
+	Code      string        `json:"code"`
+	Message   string        `json:"message"`
+	Details   []ErrorDetail `json:"details,omitempty"`
+	RequestID string        `json:"requestId,omitempty"`
+}
+
+// ErrorDetail names a request field that failed validation and why.
+type ErrorDetail struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// NewErrorResponse builds the error body for status, using the generic code of the status.
+func NewErrorResponse(status int, message string) ErrorResponse {
+	return newErrorResponse(statusCode(status), message)
+}
+
+func newErrorResponse(code, message string, details ...ErrorDetail) ErrorResponse {
+	return ErrorResponse{
+		Error:   message,
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+// statusCodes is the code of errors that have no more specific one.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusConflict:              CodeConflict,
+	http.StatusGone:                  CodeGone,
+	http.StatusRequestEntityTooLarge: CodeTooLarge,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusServiceUnavailable:    CodeLedgerUnavailable,
+	http.StatusGatewayTimeout:        CodeTimeout,
+	http.StatusInternalServerError:   CodeInternal,
+}
+
+// statusCode returns the generic code of status, e.g. "method_not_allowed" for 405.
+func statusCode(status int) string {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	return strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
 }
 
 // respondError sends a JSON error with given status code
 func respondError(w http.ResponseWriter, status int, message string) {
-	resp := errorResponse{
-		Error: message,
+	respondErrorCode(w, status, statusCode(status), message)
+}
+
+// respondErrorCode sends a JSON error with a specific error code.
+func respondErrorCode(w http.ResponseWriter, status int, code, message string) {
+	respondJSON(w, status, WithRequestID(w, newErrorResponse(code, message)))
+}
+
+// WithRequestID returns body carrying the request id the middleware set on the
+// response headers, so error bodies can be matched to log lines.
+func WithRequestID(w http.ResponseWriter, body ErrorResponse) ErrorResponse {
+	body.RequestID = w.Header().Get(requestid.Header)
+	return body
+}
+
+// ledgerErrorStatus maps the ledger's sentinel errors to an HTTP status and error code.
+func ledgerErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, fabric.ErrAlreadyExists):
+		return http.StatusConflict, CodeAlreadyExists
+	case errors.Is(err, fabric.ErrNotFound):
+		return http.StatusNotFound, CodeNotFound
+	case errors.Is(err, fabric.ErrExpired):
+		return http.StatusGone, CodeGone
+	case errors.Is(err, fabric.ErrDeactivated):
+		return http.StatusConflict, CodeDeactivated
+	case errors.Is(err, fabric.ErrValidation):
+		return http.StatusBadRequest, CodeValidation
+	case errors.Is(err, fabric.ErrClientClosed), errors.Is(err, fabric.ErrTransient):
+		return http.StatusServiceUnavailable, CodeLedgerUnavailable
+	case errors.Is(err, fabric.ErrReadOnly):
+		return http.StatusServiceUnavailable, CodeReadOnly
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, CodeTimeout
+	}
+	return http.StatusInternalServerError, CodeInternal
+}
+
+// canonicalizeErrorStatus maps a canonicalizer error about a client's document to its
+// HTTP status and error code. A key too short is the server's configuration, a 500.
+func canonicalizeErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, canonicalizer.ErrTooLarge), errors.Is(err, canonicalizer.ErrTooDeep):
+		return http.StatusRequestEntityTooLarge, CodeTooLarge
+	case errors.Is(err, canonicalizer.ErrDuplicateKey):
+		return http.StatusBadRequest, CodeDuplicateKey
+	case errors.Is(err, canonicalizer.ErrInvalidJSON), errors.Is(err, canonicalizer.ErrTrailingData):
+		return http.StatusBadRequest, CodeInvalidJSON
+	case errors.Is(err, canonicalizer.ErrKeyTooShort):
+		return http.StatusInternalServerError, CodeInternal
 	}
-	respondJSON(w, status, resp)
+	return http.StatusBadRequest, CodeInvalidBody
+}
+
+// respondLedgerError answers a failed ledger call. Only unclassified errors are
+// 500s, so clients can tell a duplicate or a bad request from a failure worth retrying.
+func respondLedgerError(w http.ResponseWriter, err error, message string) {
+	status, code := ledgerErrorStatus(err)
+	respondErrorCode(w, status, code, message+": "+err.Error())
 }
 
 // respondJSON sends a JSON response with given status code
 func respondJSON(w http.ResponseWriter, status int, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
+	respondJSONAs(w, status, "application/json", payload)
+}
+
+// respondJSONAs sends a JSON-encoded response with a specific media type
+func respondJSONAs(w http.ResponseWriter, status int, contentType string, payload interface{}) {
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(status)
 
 	// Proper error handling for JSON encoding
 	if err := json.NewEncoder(w).Encode(payload); err != nil {
-		log.Printf("ERROR: Failed to encode JSON response: %v (payload: %+v)", err, payload)
+		slog.Error("Failed to encode JSON response", "err", err, "payload", fmt.Sprintf("%+v", payload))
 		// At this point headers are already written, so we can't change the response
 		// But at least we've logged the error
 	}
 }
+
+// respondJSONWithETag sends payload with a strong ETag computed from its canonical hash.
+// If the request's If-None-Match matches, it answers 304 without a body instead.
+func respondJSONWithETag(w http.ResponseWriter, r *http.Request, contentType string, payload interface{}) {
+	hash, err := canonicalizer.CanonicalizeAndHash(payload)
+	if err != nil {
+		slog.Error("Failed to compute ETag", "err", err)
+		respondJSONAs(w, http.StatusOK, contentType, payload)
+		return
+	}
+
+	etag := `"` + hash + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Add("Vary", "Accept")
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	respondJSONAs(w, http.StatusOK, contentType, payload)
+}
+
+// etagMatches implements the weak comparison If-None-Match uses (RFC 9110 13.1.2).
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
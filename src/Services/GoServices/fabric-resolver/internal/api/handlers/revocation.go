@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+
+	"github.com/gorilla/mux"
+)
+
+type adminContextKey struct{}
+
+// WithAdmin marks the request context as authenticated with the admin token.
+func WithAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminContextKey{}, true)
+}
+
+func isAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminContextKey{}).(bool)
+	return admin
+}
+
+// RevokeAnchorRequest is the body of POST /anchors/{hash}/revoke.
+// Proof is required unless the request carries the admin token.
+type RevokeAnchorRequest struct {
+	Reason string       `json:"reason,omitempty"`
+	Proof  *AnchorProof `json:"proof,omitempty"`
+}
+
+// revocationMessage is what an issuer signs to revoke hash. It differs from
+// the anchoring message so a creation proof cannot be replayed as a revocation.
+func revocationMessage(hash string) []byte {
+	return []byte("revoke:" + hash)
+}
+
+// POST /anchors/{hash}/revoke
+//
+// Allowed for administrators, and for the issuer of an anchor whose signature was
+// verified on creation, with a proof over "revoke:<hash>". Anchors are first writer
+// wins, so an unverified anchor's IssuerDID may belong to whoever anchored someone
+// else's hash; only an administrator can revoke those.
+// Revoking an already revoked anchor succeeds and keeps the original revocation.
+func (h *AnchorHandler) RevokeAnchor(w http.ResponseWriter, r *http.Request) {
+	hash := domain.NormalizeHash(mux.Vars(r)["hash"])
+
+	var req RevokeAnchorRequest
+	if err := decodeJSON(r.Body, &req, h.strictJSON); err != nil && !errors.Is(err, io.EOF) {
+		respondBodyError(w, err, CodeInvalidBody, "Invalid request body")
+		return
+	}
+
+	anchor, err := h.ledger(r.Context()).GetAnchor(r.Context(), hash)
+	if err != nil {
+		if errors.Is(err, fabric.ErrExpired) {
+			respondError(w, http.StatusGone, "Anchor expired")
+			return
+		}
+		if errors.Is(err, fabric.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "Anchor not found")
+			return
+		}
+		respondLedgerError(w, err, "Failed to read anchor")
+		return
+	}
+
+	if !isAdmin(r.Context()) {
+		if req.Proof == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			respondError(w, http.StatusUnauthorized, "Admin token or issuer proof required")
+			return
+		}
+		if anchor.IssuerDID == "" {
+			respondError(w, http.StatusForbidden, "Anchor has no issuer; only an administrator can revoke it")
+			return
+		}
+		if !anchor.SignatureVerified {
+			respondError(w, http.StatusForbidden, "Anchor issuer was not verified; only an administrator can revoke it")
+			return
+		}
+		if err := h.verifyProof(r.Context(), anchor.IssuerDID, req.Proof, revocationMessage(hash)); err != nil {
+			respondError(w, proofErrorStatus(err), err.Error())
+			return
+		}
+	}
+
+	wasRevoked := anchor.Revoked
+	if err := h.ledger(r.Context()).RevokeAnchor(r.Context(), hash, req.Reason); err != nil {
+		if errors.Is(err, fabric.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "Anchor not found")
+			return
+		}
+		respondLedgerError(w, err, "Failed to revoke anchor")
+		return
+	}
+
+	if anchor, err = h.ledger(r.Context()).GetAnchor(r.Context(), hash); err != nil {
+		respondLedgerError(w, err, "Failed to read revoked anchor")
+		return
+	}
+	if !wasRevoked {
+		h.webhooks.AnchorRevoked(r.Context(), Tenant(r.Context()), anchor)
+	}
+	respondJSON(w, http.StatusOK, toAnchorResponse(anchor))
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/domain"
+)
+
+func revokeWithProof(t *testing.T, reason string, priv ed25519.PrivateKey, message string) string {
+	t.Helper()
+	body, _ := json.Marshal(RevokeAnchorRequest{
+		Reason: reason,
+		Proof: &AnchorProof{
+			VerificationMethod: "#key-1",
+			Signature:          base64.RawURLEncoding.EncodeToString(ed25519.Sign(priv, []byte(message))),
+			Created:            time.Now().UTC().Format(time.RFC3339),
+		},
+	})
+	return string(body)
+}
+
+func TestRevokeAnchor(t *testing.T) {
+	ledger := newTestLedger(t)
+	priv := registerEd25519Issuer(t, ledger)
+	router := newAnchorRouter(ledger)
+	ledger.CreateAnchor(t.Context(), &domain.Anchor{Hash: proofHash, IssuerDID: "did:ewallet:issuer", SignatureVerified: true})
+	target := "/anchors/" + proofHash + "/revoke"
+
+	// A creation proof signs the bare hash and must not be accepted as a revocation
+	if rec := doRequest(t, router, "POST", target, strings.NewReader(revokeWithProof(t, "replay", priv, proofHash))); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a replayed creation proof, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, router, "POST", target, strings.NewReader(`{"reason":"no proof"}`)); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a proof, got %d", rec.Code)
+	}
+
+	rec := doRequest(t, router, "POST", target, strings.NewReader(revokeWithProof(t, "superseded", priv, "revoke:"+proofHash)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := decodeBody[AnchorResponse](t, rec); !got.Revoked || got.RevocationReason != "superseded" || got.RevokedAt == "" {
+		t.Errorf("unexpected revoked anchor: %+v", got)
+	}
+
+	verify := decodeBody[VerifyAnchorResponse](t, doRequest(t, router, "GET", "/anchors/"+proofHash+"/verify", nil))
+	if !verify.Exists || verify.Valid || !verify.Revoked || len(verify.Reasons) != 1 || verify.Reasons[0] != reasonRevoked {
+		t.Errorf("unexpected verify after revoke: %+v", verify)
+	}
+}
+
+func TestRevokeAnchor_Twice(t *testing.T) {
+	ledger := newTestLedger(t)
+	priv := registerEd25519Issuer(t, ledger)
+	router := newAnchorRouter(ledger)
+	ledger.CreateAnchor(t.Context(), &domain.Anchor{Hash: proofHash, IssuerDID: "did:ewallet:issuer", SignatureVerified: true})
+	target := "/anchors/" + proofHash + "/revoke"
+
+	doRequest(t, router, "POST", target, strings.NewReader(revokeWithProof(t, "first", priv, "revoke:"+proofHash)))
+	rec := doRequest(t, router, "POST", target, strings.NewReader(revokeWithProof(t, "second", priv, "revoke:"+proofHash)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for a repeated revocation, got %d", rec.Code)
+	}
+	if got := decodeBody[AnchorResponse](t, rec); got.RevocationReason != "first" {
+		t.Errorf("second revocation overwrote the first: %+v", got)
+	}
+}
+
+func TestRevokeAnchor_NotFound(t *testing.T) {
+	ledger := newTestLedger(t)
+	priv := registerEd25519Issuer(t, ledger)
+	router := newAnchorRouter(ledger)
+
+	hash := hexHash("never-anchored")
+	rec := doRequest(t, router, "POST", "/anchors/"+hash+"/revoke", strings.NewReader(revokeWithProof(t, "", priv, "revoke:"+hash)))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestRevokeAnchor_Admin(t *testing.T) {
+	ledger := newTestLedger(t)
+	router := newAnchorRouter(ledger)
+	hash := hexHash("no-issuer")
+	ledger.CreateAnchor(t.Context(), &domain.Anchor{Hash: hash})
+
+	// Anchors without an issuer can only be revoked by an administrator
+	body := `{"reason":"x","proof":{"verificationMethod":"#key-1","signature":"AA","created":"2025-01-01T00:00:00Z"}}`
+	if rec := doRequest(t, router, "POST", "/anchors/"+hash+"/revoke", strings.NewReader(body)); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an anchor without issuer, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest("POST", "/anchors/"+hash+"/revoke", nil)
+	req = req.WithContext(WithAdmin(req.Context()))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !decodeBody[AnchorResponse](t, rec).Revoked {
+		t.Errorf("expected admin revocation to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRevokeAnchor_UnverifiedIssuer(t *testing.T) {
+	ledger := newTestLedger(t)
+	priv := registerEd25519Issuer(t, ledger)
+	router := newAnchorRouter(ledger)
+	// Anyone can anchor a hash under a DID they control without a proof
+	ledger.CreateAnchor(t.Context(), &domain.Anchor{Hash: proofHash, IssuerDID: "did:ewallet:issuer"})
+
+	rec := doRequest(t, router, "POST", "/anchors/"+proofHash+"/revoke", strings.NewReader(revokeWithProof(t, "hijack", priv, "revoke:"+proofHash)))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an unverified anchor's issuer, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if anchor, _ := ledger.GetAnchor(t.Context(), proofHash); anchor.Revoked {
+		t.Error("unverified issuer revoked the anchor")
+	}
+}
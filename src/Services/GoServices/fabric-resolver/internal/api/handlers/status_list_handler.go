@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/pkg/canonicalizer"
+	"fabric-resolver/internal/pkg/statuslist"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	statusListPurpose = "revocation"
+	statusListContext = "https://w3id.org/vc/status-list/2021/v1"
+	credentialContext = "https://www.w3.org/2018/credentials/v1"
+)
+
+var statusListIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// StatusListHandler serves Status List 2021 revocation lists stored on the ledger.
+// Every change to a list's bitstring anchors the hash of the new list document.
+type StatusListHandler struct {
+	ledgerClient fabric.LedgerClient
+	mu           sync.Mutex       // serializes read-modify-write cycles on lists
+	now          func() time.Time // replaced in tests
+}
+
+func NewStatusListHandler(ledgerClient fabric.LedgerClient) *StatusListHandler {
+	return &StatusListHandler{
+		ledgerClient: ledgerClient,
+		now:          time.Now,
+	}
+}
+
+// ledger returns the ledger of the request's tenant, or the handler's own.
+func (h *StatusListHandler) ledger(ctx context.Context) fabric.LedgerClient {
+	return LedgerFrom(ctx, h.ledgerClient)
+}
+
+// StatusListCredential is the list document served by GET /status-lists/{id}.
+// It is not signed; its integrity is established by its anchored canonical hash,
+// which is also its ETag.
+type StatusListCredential struct {
+	Context           []string          `json:"@context"`
+	ID                string            `json:"id"`
+	Type              []string          `json:"type"`
+	IssuanceDate      string            `json:"issuanceDate"`
+	CredentialSubject StatusListSubject `json:"credentialSubject"`
+}
+
+type StatusListSubject struct {
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	StatusPurpose string `json:"statusPurpose"`
+	EncodedList   string `json:"encodedList"`
+}
+
+// StatusListEntry is a credentialStatus value referencing one index of a list.
+type StatusListEntry struct {
+	ID                   string `json:"id"`
+	Type                 string `json:"type"`
+	StatusPurpose        string `json:"statusPurpose"`
+	StatusListIndex      string `json:"statusListIndex"`
+	StatusListCredential string `json:"statusListCredential"`
+}
+
+// StatusListRevokeResponse is the body of POST /status-lists/{id}/entries/{index}/revoke.
+type StatusListRevokeResponse struct {
+	StatusListIndex string `json:"statusListIndex"`
+	Revoked         bool   `json:"revoked"`
+	AnchorHash      string `json:"anchorHash"`
+}
+
+// POST /status-lists/{id}/entries
+//
+// Allocates the next index of the list, creating the list on first use and growing
+// it when it is full. Answers 201 with the credentialStatus entry to embed.
+func (h *StatusListHandler) AllocateEntry(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if !statusListIDPattern.MatchString(id) {
+		respondError(w, http.StatusBadRequest, "Status list id must be 1-64 letters, digits, '.', '_' or '-'")
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	list, err := h.ledger(r.Context()).GetStatusList(r.Context(), id)
+	var bits *statuslist.Bitstring
+	switch {
+	case errors.Is(err, fabric.ErrNotFound):
+		list = &domain.StatusList{ID: id, URL: statusListURL(r, id), Purpose: statusListPurpose}
+		bits = statuslist.New(0)
+	case err != nil:
+		respondLedgerError(w, err, "Failed to read status list")
+		return
+	default:
+		if bits, err = statuslist.Decode(list.EncodedList); err != nil {
+			respondError(w, http.StatusInternalServerError, "Stored status list is corrupt: "+err.Error())
+			return
+		}
+	}
+
+	index := list.NextIndex
+	SetAuditResource(r.Context(), id+"#"+strconv.Itoa(index))
+	changed := list.EncodedList == ""
+	if index >= bits.Len() {
+		if err := bits.Grow(index + 1); err != nil {
+			respondError(w, http.StatusConflict, "Status list is full")
+			return
+		}
+		changed = true
+	}
+	list.NextIndex++
+
+	if err := h.save(r, list, bits, changed); err != nil {
+		respondLedgerError(w, err, "Failed to save status list")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, StatusListEntry{
+		ID:                   list.URL + "#" + strconv.Itoa(index),
+		Type:                 "StatusList2021Entry",
+		StatusPurpose:        list.Purpose,
+		StatusListIndex:      strconv.Itoa(index),
+		StatusListCredential: list.URL,
+	})
+}
+
+// POST /status-lists/{id}/entries/{index}/revoke
+//
+// Sets the bit of an allocated index. Revoking twice is a no-op.
+func (h *StatusListHandler) RevokeEntry(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	index, err := strconv.Atoi(vars["index"])
+	if err != nil || index < 0 {
+		respondError(w, http.StatusBadRequest, "Index must be a non-negative integer")
+		return
+	}
+	SetAuditResource(r.Context(), vars["id"]+"#"+strconv.Itoa(index))
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	list, err := h.ledger(r.Context()).GetStatusList(r.Context(), vars["id"])
+	if err != nil {
+		if errors.Is(err, fabric.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "Status list not found")
+			return
+		}
+		respondLedgerError(w, err, "Failed to read status list")
+		return
+	}
+	if index >= list.NextIndex {
+		respondError(w, http.StatusNotFound, "Index "+strconv.Itoa(index)+" has not been allocated")
+		return
+	}
+
+	bits, err := statuslist.Decode(list.EncodedList)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Stored status list is corrupt: "+err.Error())
+		return
+	}
+	revoked, err := bits.Get(index)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !revoked {
+		bits.Set(index, true)
+		if err := h.save(r, list, bits, true); err != nil {
+			respondLedgerError(w, err, "Failed to save status list")
+			return
+		}
+	}
+
+	respondJSON(w, http.StatusOK, StatusListRevokeResponse{
+		StatusListIndex: strconv.Itoa(index),
+		Revoked:         true,
+		AnchorHash:      list.AnchorHash,
+	})
+}
+
+// GET /status-lists/{id}
+func (h *StatusListHandler) GetStatusList(w http.ResponseWriter, r *http.Request) {
+	list, err := h.ledger(r.Context()).GetStatusList(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, fabric.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "Status list not found")
+			return
+		}
+		respondLedgerError(w, err, "Failed to read status list")
+		return
+	}
+
+	respondJSONWithETag(w, r, "application/json", toStatusListCredential(list))
+}
+
+// save stores list with bits. When the bitstring changed, the new list document
+// is anchored first and its hash recorded on the list.
+func (h *StatusListHandler) save(r *http.Request, list *domain.StatusList, bits *statuslist.Bitstring, changed bool) error {
+	if changed {
+		encoded, err := bits.Encode()
+		if err != nil {
+			return err
+		}
+		list.EncodedList = encoded
+		list.Updated = h.now().UTC().Truncate(time.Second)
+
+		hash, err := canonicalizer.CanonicalizeAndHash(toStatusListCredential(list))
+		if err != nil {
+			return err
+		}
+		metadata, metadataHash, err := canonicalMetadata(json.RawMessage(fmt.Sprintf(`{"statusList":%q}`, list.ID)), DefaultMaxMetadataBytes)
+		if err != nil {
+			return err
+		}
+		anchor := &domain.Anchor{
+			Hash:         hash,
+			Algorithm:    domain.HashSHA256,
+			Metadata:     metadata,
+			MetadataHash: metadataHash,
+		}
+		if _, _, err := h.ledger(r.Context()).CreateAnchor(r.Context(), anchor); err != nil {
+			return fmt.Errorf("anchor status list: %w", err)
+		}
+		list.AnchorHash = hash
+	}
+	return h.ledger(r.Context()).SaveStatusList(r.Context(), list)
+}
+
+func toStatusListCredential(list *domain.StatusList) StatusListCredential {
+	return StatusListCredential{
+		Context:      []string{credentialContext, statusListContext},
+		ID:           list.URL,
+		Type:         []string{"VerifiableCredential", "StatusList2021Credential"},
+		IssuanceDate: list.Updated.UTC().Format(time.RFC3339),
+		CredentialSubject: StatusListSubject{
+			ID:            list.URL + "#list",
+			Type:          "StatusList2021",
+			StatusPurpose: list.Purpose,
+			EncodedList:   list.EncodedList,
+		},
+	}
+}
+
+// statusListURL is the URL the list is served at, as seen by the client creating it, under
+// the same API version. It is fixed when the list is created so the anchored document does
+// not depend on later requests.
+func statusListURL(r *http.Request, id string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	version, _, _ := strings.Cut(r.URL.Path, "/status-lists/")
+	return scheme + "://" + r.Host + version + "/status-lists/" + id
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/pkg/canonicalizer"
+	"fabric-resolver/internal/pkg/statuslist"
+
+	"github.com/gorilla/mux"
+)
+
+func newStatusListRouter(ledger fabric.LedgerClient) *mux.Router {
+	h := NewStatusListHandler(ledger)
+	r := mux.NewRouter()
+	r.HandleFunc("/status-lists/{id}", h.GetStatusList).Methods("GET")
+	r.HandleFunc("/status-lists/{id}/entries", h.AllocateEntry).Methods("POST")
+	r.HandleFunc("/status-lists/{id}/entries/{index}/revoke", h.RevokeEntry).Methods("POST")
+	return r
+}
+
+func TestStatusList_AllocateAndRevoke(t *testing.T) {
+	ledger := newTestLedger(t)
+	router := newStatusListRouter(ledger)
+
+	for i := 0; i < 3; i++ {
+		rec := doRequest(t, router, "POST", "/status-lists/creds/entries", nil)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+		entry := decodeBody[StatusListEntry](t, rec)
+		if entry.StatusListIndex != strconv.Itoa(i) || entry.StatusListCredential != "http://example.com/status-lists/creds" || entry.Type != "StatusList2021Entry" {
+			t.Errorf("unexpected entry %d: %+v", i, entry)
+		}
+	}
+
+	before := doRequest(t, router, "GET", "/status-lists/creds", nil)
+	if before.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", before.Code)
+	}
+	beforeETag := before.Header().Get("ETag")
+	doc := decodeBody[StatusListCredential](t, before)
+	hash, _ := canonicalizer.CanonicalizeAndHash(doc)
+	if beforeETag != `"`+hash+`"` || !ledger.VerifyAnchor(t.Context(), hash) {
+		t.Errorf("list document is not anchored under its canonical hash %s", hash)
+	}
+
+	rec := doRequest(t, router, "POST", "/status-lists/creds/entries/1/revoke", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	revoked := decodeBody[StatusListRevokeResponse](t, rec)
+	if !revoked.Revoked || revoked.AnchorHash == hash || !ledger.VerifyAnchor(t.Context(), revoked.AnchorHash) {
+		t.Errorf("revocation did not anchor a new list hash: %+v", revoked)
+	}
+
+	after := doRequest(t, router, "GET", "/status-lists/creds", nil)
+	if after.Header().Get("ETag") != `"`+revoked.AnchorHash+`"` {
+		t.Errorf("ETag %s does not match the anchored hash %s", after.Header().Get("ETag"), revoked.AnchorHash)
+	}
+	bits, err := statuslist.Decode(decodeBody[StatusListCredential](t, after).CredentialSubject.EncodedList)
+	if err != nil {
+		t.Fatalf("failed to decode list: %v", err)
+	}
+	for i, want := range []bool{false, true, false} {
+		if got, _ := bits.Get(i); got != want {
+			t.Errorf("bit %d: expected %v, got %v", i, want, got)
+		}
+	}
+
+	// Revoking again changes nothing
+	again := decodeBody[StatusListRevokeResponse](t, doRequest(t, router, "POST", "/status-lists/creds/entries/1/revoke", nil))
+	if again.AnchorHash != revoked.AnchorHash {
+		t.Errorf("repeated revocation re-anchored the list: %+v", again)
+	}
+}
+
+func TestStatusList_Errors(t *testing.T) {
+	router := newStatusListRouter(newTestLedger(t))
+	doRequest(t, router, "POST", "/status-lists/creds/entries", nil)
+
+	tests := []struct {
+		method, target string
+		want           int
+	}{
+		{"GET", "/status-lists/missing", http.StatusNotFound},
+		{"POST", "/status-lists/missing/entries/0/revoke", http.StatusNotFound},
+		{"POST", "/status-lists/creds/entries/1/revoke", http.StatusNotFound}, // not allocated yet
+		{"POST", "/status-lists/creds/entries/x/revoke", http.StatusBadRequest},
+		{"POST", "/status-lists/bad%20id/entries", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rec := doRequest(t, router, tt.method, tt.target, nil); rec.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.target, tt.want, rec.Code)
+		}
+	}
+}
+
+func TestStatusList_Growth(t *testing.T) {
+	ledger := newTestLedger(t)
+	router := newStatusListRouter(ledger)
+
+	// Fill the first block of the list, then allocate past it
+	full, _ := statuslist.New(0).Encode()
+	ledger.SaveStatusList(t.Context(), &domain.StatusList{
+		ID:          "full",
+		URL:         "http://example.com/status-lists/full",
+		Purpose:     "revocation",
+		EncodedList: full,
+		NextIndex:   statuslist.MinBits,
+	})
+
+	rec := doRequest(t, router, "POST", "/status-lists/full/entries", nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if entry := decodeBody[StatusListEntry](t, rec); entry.StatusListIndex != strconv.Itoa(statuslist.MinBits) {
+		t.Errorf("unexpected index %s", entry.StatusListIndex)
+	}
+
+	list, _ := ledger.GetStatusList(t.Context(), "full")
+	bits, _ := statuslist.Decode(list.EncodedList)
+	if bits.Len() != 2*statuslist.MinBits || list.AnchorHash == "" {
+		t.Errorf("list did not grow and re-anchor: %d bits, anchor %q", bits.Len(), list.AnchorHash)
+	}
+	if rec := doRequest(t, router, "POST", "/status-lists/full/entries/"+strconv.Itoa(statuslist.MinBits)+"/revoke", nil); rec.Code != http.StatusOK {
+		t.Errorf("expected the grown index to be revocable, got %d", rec.Code)
+	}
+}
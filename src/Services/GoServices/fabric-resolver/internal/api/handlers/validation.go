@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/pkg/didkey"
+)
+
+// Validation error codes, sent with a detail naming the offending field.
+const (
+	CodeInvalidBody               = "invalid_body"
+	CodeMissingField              = "missing_field"
+	CodeInvalidHashFormat         = "invalid_hash_format"
+	CodeUnsupportedAlgorithm      = "unsupported_algorithm"
+	CodeInvalidMetadata           = "invalid_metadata"
+	CodeMetadataTooLarge          = "metadata_too_large"
+	CodeInvalidTimestamp          = "invalid_timestamp"
+	CodeInvalidDIDSyntax          = "invalid_did_syntax"
+	CodeUnsupportedDIDMethod      = "unsupported_did_method"
+	CodeInvalidVerificationMethod = "invalid_verification_method"
+	CodeInvalidService            = "invalid_service"
+	CodeInvalidRelationship       = "invalid_relationship"
+)
+
+// requestError is a validation failure of one request field. Its message is that of err.
+type requestError struct {
+	code   string
+	field  string
+	reason string
+	err    error
+}
+
+func (e *requestError) Error() string { return e.err.Error() }
+func (e *requestError) Unwrap() error { return e.err }
+
+// fieldError ties err to a request field and an error code.
+func fieldError(code, field string, err error) error {
+	return &requestError{code: code, field: field, reason: err.Error(), err: err}
+}
+
+// respondRequestError answers a validation failure. Errors made by fieldError
+// carry their code and field; others get the generic code of status.
+func respondRequestError(w http.ResponseWriter, status int, err error) {
+	var reqErr *requestError
+	if !errors.As(err, &reqErr) {
+		respondError(w, status, err.Error())
+		return
+	}
+	respondJSON(w, status, WithRequestID(w, newErrorResponse(reqErr.code, err.Error(), ErrorDetail{Field: reqErr.field, Reason: reqErr.reason})))
+}
+
+// NewDIDDocument returns the document req creates, checked with v as POST /dids
+// and the gRPC CreateDid both do: the DID is normalized, and did:key DIDs, whose
+// documents are derived from the key, are refused. Errors name the offending field.
+func NewDIDDocument(v *domain.DIDValidator, req CreateDidRequest) (*domain.DIDDocument, error) {
+	if req.Did == "" {
+		return nil, fieldError(CodeMissingField, "did", errors.New("DID is required"))
+	}
+	req.Did = domain.NormalizeDID(req.Did)
+	if didkey.IsDIDKey(req.Did) {
+		return nil, fieldError(CodeUnsupportedDIDMethod, "did",
+			errors.New("did:key documents are derived from the key; resolve them directly instead of creating them"))
+	}
+
+	didDoc, err := req.ToDIDDocument()
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDIDDocument(v, didDoc); err != nil {
+		return nil, err
+	}
+	return didDoc, nil
+}
+
+// validateDIDDocument runs the validator and attributes its failure to a field:
+// the DID itself, or the verification method or service it names.
+func validateDIDDocument(v *domain.DIDValidator, doc *domain.DIDDocument) error {
+	if _, _, err := domain.ParseDID(doc.ID); err != nil {
+		return fieldError(CodeInvalidDIDSyntax, "did", err)
+	}
+	if err := v.ValidateDID(doc.ID); err != nil {
+		return fieldError(CodeUnsupportedDIDMethod, "did", err)
+	}
+
+	err := v.ValidateDocument(doc)
+	var fieldErr *domain.FieldError
+	if !errors.As(err, &fieldErr) {
+		return err
+	}
+	code := CodeInvalidVerificationMethod
+	if strings.HasPrefix(fieldErr.Field, "service") {
+		code = CodeInvalidService
+	}
+	return &requestError{code: code, field: fieldErr.Field, reason: fieldErr.Err.Error(), err: err}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestValidationErrorCodes(t *testing.T) {
+	ledger := newTestLedger(t)
+	anchors, dids := newAnchorRouter(ledger), newDidRouter(ledger)
+	bigMetadata := `{"blob":"` + strings.Repeat("x", DefaultMaxMetadataBytes) + `"}`
+
+	tests := []struct {
+		name   string
+		router *mux.Router
+		path   string
+		body   string
+		status int
+		code   string
+		field  string
+	}{
+		{"missing hash", anchors, "/anchors", `{"issuerDid":"did:ewallet:a"}`, http.StatusBadRequest, CodeMissingField, "hash"},
+		{"non-hex hash", anchors, "/anchors", `{"hash":"hello"}`, http.StatusBadRequest, CodeInvalidHashFormat, "hash"},
+		{"unsupported algorithm", anchors, "/anchors", `{"hash":"` + hexHash("x") + `","algorithm":"md5"}`, http.StatusBadRequest, CodeUnsupportedAlgorithm, "algorithm"},
+		{"expiresAt in the past", anchors, "/anchors", `{"hash":"` + hexHash("x") + `","expiresAt":"2001-01-01T00:00:00Z"}`, http.StatusBadRequest, CodeInvalidTimestamp, "expiresAt"},
+		{"metadata too large", anchors, "/anchors", `{"hash":"` + hexHash("x") + `","metadata":` + bigMetadata + `}`, http.StatusRequestEntityTooLarge, CodeMetadataTooLarge, "metadata"},
+		{"expiresAt not RFC3339", anchors, "/anchors", `{"hash":"` + hexHash("x") + `","expiresAt":"tomorrow"}`, http.StatusBadRequest, CodeInvalidTimestamp, "expiresAt"},
+		{"missing did", dids, "/dids", `{}`, http.StatusBadRequest, CodeMissingField, "did"},
+		{"malformed did", dids, "/dids", `{"did":"did:e-wallet:1"}`, http.StatusBadRequest, CodeInvalidDIDSyntax, "did"},
+		{"unsupported method", dids, "/dids", `{"did":"did:example:1"}`, http.StatusBadRequest, CodeUnsupportedDIDMethod, "did"},
+		{"bad verification method", dids, "/dids", `{"did":"did:ewallet:1","verificationMethod":[{"type":"Foo","publicKeyBase58":"k"}]}`, http.StatusBadRequest, CodeInvalidVerificationMethod, "verificationMethod[0]"},
+		{"relative service endpoint", dids, "/dids", `{"did":"did:ewallet:1","service":[{"type":"Hub","serviceEndpoint":"/hub"}]}`, http.StatusBadRequest, CodeInvalidService, "service[0]"},
+		{"relationship out of range", dids, "/dids", `{"did":"did:ewallet:1","authentication":[2]}`, http.StatusBadRequest, CodeInvalidRelationship, "authentication[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(t, tt.router, "POST", tt.path, strings.NewReader(tt.body))
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			got := decodeBody[ErrorResponse](t, rec)
+			if got.Code != tt.code {
+				t.Errorf("expected code %s, got %q", tt.code, got.Code)
+			}
+			if len(got.Details) != 1 || got.Details[0].Field != tt.field || got.Details[0].Reason == "" {
+				t.Errorf("expected a detail for %s, got %+v", tt.field, got.Details)
+			}
+			if got.Error == "" || got.Error != got.Message {
+				t.Errorf("expected error and message to carry the same text, got %q / %q", got.Error, got.Message)
+			}
+		})
+	}
+}
+
+func TestErrorResponse_GenericCodes(t *testing.T) {
+	router := newAnchorRouter(newTestLedger(t))
+
+	rec := doRequest(t, router, "POST", "/anchors", strings.NewReader(`{not json`))
+	if got := decodeBody[ErrorResponse](t, rec); got.Code != CodeInvalidBody || got.Details != nil {
+		t.Errorf("unexpected body for malformed JSON: %+v", got)
+	}
+
+	rec = doRequest(t, router, "GET", "/anchors?limit=0", nil)
+	if got := decodeBody[ErrorResponse](t, rec); got.Code != CodeBadRequest || got.Message != got.Error {
+		t.Errorf("unexpected body for a bad limit: %+v", got)
+	}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+
+	"github.com/gorilla/mux"
+)
+
+// WebhookHandler manages webhook registrations. Deliveries are made by webhooks.Dispatcher.
+type WebhookHandler struct {
+	ledgerClient fabric.LedgerClient
+}
+
+func NewWebhookHandler(ledgerClient fabric.LedgerClient) *WebhookHandler {
+	return &WebhookHandler{ledgerClient: ledgerClient}
+}
+
+// CreateWebhookRequest is the body of POST /webhooks.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"` // signs deliveries with HMAC-SHA256 in X-Signature
+	Events []string `json:"events,omitempty"` // anchor.created, anchor.revoked, did.created; empty means all
+}
+
+// WebhookResponse describes a registration. The secret is never returned.
+type WebhookResponse struct {
+	ID      string   `json:"id"`
+	URL     string   `json:"url"`
+	Events  []string `json:"events"`
+	Signed  bool     `json:"signed"`
+	Created string   `json:"created"`
+}
+
+// WebhookListResponse is the body of GET /webhooks.
+type WebhookListResponse struct {
+	Items []WebhookResponse `json:"items"`
+}
+
+// validate checks the URL and event filter.
+func (req CreateWebhookRequest) validate() error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	for _, event := range req.Events {
+		if !slices.Contains(domain.WebhookEvents, event) {
+			return errors.New("unknown event " + event + "; expected one of " + strings.Join(domain.WebhookEvents, ", "))
+		}
+	}
+	return nil
+}
+
+// POST /webhooks (admin)
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req CreateWebhookRequest
+	if err := decodeJSON(r.Body, &req, false); err != nil {
+		respondBodyError(w, err, CodeInvalidBody, "Invalid request body")
+		return
+	}
+	if err := req.validate(); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id := make([]byte, 8)
+	rand.Read(id)
+	hook := &domain.Webhook{
+		ID:      "wh_" + hex.EncodeToString(id),
+		URL:     req.URL,
+		Secret:  req.Secret,
+		Events:  slices.Compact(slices.Sorted(slices.Values(req.Events))),
+		Created: time.Now().UTC(),
+	}
+	SetAuditResource(r.Context(), hook.ID)
+	if err := h.ledgerClient.SaveWebhook(r.Context(), hook); err != nil {
+		respondLedgerError(w, err, "Failed to save webhook")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, toWebhookResponse(hook))
+}
+
+// GET /webhooks (admin)
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.ledgerClient.ListWebhooks(r.Context())
+	if err != nil {
+		respondLedgerError(w, err, "Failed to list webhooks")
+		return
+	}
+
+	resp := WebhookListResponse{Items: make([]WebhookResponse, len(hooks))}
+	for i := range hooks {
+		resp.Items[i] = toWebhookResponse(&hooks[i])
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// DELETE /webhooks/{id} (admin)
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := h.ledgerClient.DeleteWebhook(r.Context(), mux.Vars(r)["id"]); err != nil {
+		if errors.Is(err, fabric.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "Webhook not found")
+			return
+		}
+		respondLedgerError(w, err, "Failed to delete webhook")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toWebhookResponse(hook *domain.Webhook) WebhookResponse {
+	events := hook.Events
+	if len(events) == 0 {
+		events = domain.WebhookEvents
+	}
+	return WebhookResponse{
+		ID:      hook.ID,
+		URL:     hook.URL,
+		Events:  events,
+		Signed:  hook.Secret != "",
+		Created: hook.Created.Format(time.RFC3339),
+	}
+}
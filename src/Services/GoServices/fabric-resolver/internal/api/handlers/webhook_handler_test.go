@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+
+	"github.com/gorilla/mux"
+)
+
+func newWebhookRouter(ledger fabric.LedgerClient) *mux.Router {
+	h := NewWebhookHandler(ledger)
+	r := mux.NewRouter()
+	r.HandleFunc("/webhooks", h.CreateWebhook).Methods("POST")
+	r.HandleFunc("/webhooks", h.ListWebhooks).Methods("GET")
+	r.HandleFunc("/webhooks/{id}", h.DeleteWebhook).Methods("DELETE")
+	return r
+}
+
+func TestWebhooks_RegisterListDelete(t *testing.T) {
+	ledger := newTestLedger(t)
+	router := newWebhookRouter(ledger)
+
+	rec := doRequest(t, router, "POST", "/webhooks", strings.NewReader(
+		`{"url":"https://indexer.example/hook","secret":"s3cret","events":["did.created","anchor.created","did.created"]}`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	created := decodeBody[WebhookResponse](t, rec)
+	if created.ID == "" || !created.Signed || strings.Join(created.Events, ",") != "anchor.created,did.created" {
+		t.Errorf("unexpected registration: %+v", created)
+	}
+	if strings.Contains(rec.Body.String(), "s3cret") {
+		t.Error("the secret must not be returned")
+	}
+
+	// Without a filter a webhook receives every event
+	doRequest(t, router, "POST", "/webhooks", strings.NewReader(`{"url":"http://localhost:9000/all"}`))
+
+	list := decodeBody[WebhookListResponse](t, doRequest(t, router, "GET", "/webhooks", nil))
+	if len(list.Items) != 2 || list.Items[0].ID != created.ID || len(list.Items[1].Events) != len(domain.WebhookEvents) || list.Items[1].Signed {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+
+	if rec := doRequest(t, router, "DELETE", "/webhooks/"+created.ID, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, router, "DELETE", "/webhooks/"+created.ID, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 deleting twice, got %d", rec.Code)
+	}
+}
+
+func TestWebhooks_RejectsInvalidRegistrations(t *testing.T) {
+	router := newWebhookRouter(newTestLedger(t))
+
+	for name, body := range map[string]string{
+		"relative url":  `{"url":"/hook"}`,
+		"ftp url":       `{"url":"ftp://example.com/hook"}`,
+		"unknown event": `{"url":"https://example.com/hook","events":["anchor.deleted"]}`,
+		"bad json":      `{"url":`,
+	} {
+		if rec := doRequest(t, router, "POST", "/webhooks", strings.NewReader(body)); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/buildinfo"
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// makeUnwritable denies writes to dir until the test ends. Root ignores
+// permission bits, so there the directory is swapped for a regular file.
+func makeUnwritable(t *testing.T, dir string) {
+	t.Helper()
+	if os.Geteuid() != 0 {
+		if err := os.Chmod(dir, 0o500); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.Chmod(dir, 0o700) })
+		return
+	}
+	moved := dir + ".moved"
+	if err := os.Rename(dir, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Remove(dir)
+		os.Rename(moved, dir)
+	})
+}
+
+func TestProbes_ReadinessFailsOnUnwritableLedger(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	ledger, err := fabric.NewFileLedgerClient(filepath.Join(dir, "ledger.json"))
+	if err != nil {
+		t.Fatalf("failed to create ledger: %v", err)
+	}
+	t.Cleanup(func() { ledger.Close() })
+	router := NewRouter(ledger, RouterOptions{Logger: discardLogger})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+	for _, path := range []string{"/livez", "/readyz", "/health"} {
+		if rec := get(path); rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200 while the ledger is writable, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+	}
+
+	makeUnwritable(t, dir)
+
+	for _, path := range []string{"/readyz", "/health"} {
+		rec := get(path)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%s: expected 503, got %d", path, rec.Code)
+		}
+		var body handlers.ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: expected a JSON error, got %q", path, rec.Body.String())
+		}
+		if body.Code != handlers.CodeLedgerUnavailable || len(body.Details) != 1 || body.Details[0].Field != "ledger" {
+			t.Errorf("%s: expected the ledger named as the failing component, got %+v", path, body)
+		}
+	}
+	// The process itself is fine; restarting it would not help
+	if rec := get("/livez"); rec.Code != http.StatusOK {
+		t.Errorf("/livez: expected 200, got %d", rec.Code)
+	}
+}
+
+func TestProbes_ReadinessFailsOnClosedLedger(t *testing.T) {
+	router, ledger := newTestRouter(t, RouterOptions{Logger: discardLogger})
+	ledger.Close()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestVersion_ReportedByEndpointHeaderAndProbes(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{})
+	want := buildinfo.Build{Version: "dev", Commit: "dev", BuildDate: "dev"}
+
+	for _, path := range []string{"/version", "/livez", "/readyz", "/health"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, rec.Code)
+		}
+		if got := rec.Header().Get(VersionHeader); got != "dev" {
+			t.Errorf("%s: expected %s: dev, got %q", path, VersionHeader, got)
+		}
+
+		var build buildinfo.Build
+		if path != "/version" {
+			var body struct {
+				Build buildinfo.Build `json:"build"`
+			}
+			json.Unmarshal(rec.Body.Bytes(), &body)
+			build = body.Build
+		} else {
+			json.Unmarshal(rec.Body.Bytes(), &build)
+		}
+		if build != want {
+			t.Errorf("%s: expected the build %+v, got %s", path, want, rec.Body.String())
+		}
+	}
+
+	// Every response names the version, not only those of the build endpoints
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/anchors/"+strings.Repeat("ab", 32), nil))
+	if rec.Header().Get(VersionHeader) != "dev" {
+		t.Errorf("expected %s on an API response, got %v", VersionHeader, rec.Header())
+	}
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/jwtauth/jwttest"
+)
+
+var testIdP = jwttest.NewIssuer()
+
+func serveWithBearer(router http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected a JSON error body, got %q", rec.Body.String())
+	}
+	return body.Code
+}
+
+func TestJWT_ScopesPerRouteGroup(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{JWT: testIdP.Verifier()})
+	anchor := `{"hash":"` + strings.Repeat("ab", 32) + `"}`
+	did := `{"did":"did:ewallet:jwt","verificationMethod":[{"type":"Ed25519VerificationKey2020","publicKeyBase58":"k"}]}`
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		scopes []string
+		want   int
+	}{
+		{"anchor with anchors:write", "POST", "/anchors", anchor, []string{ScopeAnchorsWrite}, http.StatusCreated},
+		{"anchor with dids:write", "POST", "/anchors/batch", `{"anchors":[` + anchor + `]}`, []string{ScopeDIDsWrite}, http.StatusForbidden},
+		{"did with dids:write", "POST", "/dids", did, []string{ScopeDIDsWrite}, http.StatusCreated},
+		{"did with anchors:write", "PUT", "/dids/did:ewallet:jwt", did, []string{ScopeAnchorsWrite}, http.StatusForbidden},
+		{"commitment with dids:write", "POST", "/commitments", `{}`, []string{ScopeDIDsWrite}, http.StatusForbidden},
+		{"commitment verify with dids:write", "POST", "/commitments/verify", `{}`, []string{ScopeDIDsWrite}, http.StatusForbidden},
+		{"webhooks with anchors:write", "GET", "/webhooks", "", []string{ScopeAnchorsWrite}, http.StatusForbidden},
+		{"webhooks with admin", "GET", "/webhooks", "", []string{ScopeAdmin}, http.StatusOK},
+		{"admin implies anchors:write", "POST", "/anchors/merkle-batch", `{"leaves":["` + strings.Repeat("cd", 32) + `"]}`, []string{ScopeAdmin}, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveWithBearer(router, tt.method, tt.path, testIdP.Token(tt.scopes...), tt.body)
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+			if tt.want == http.StatusForbidden {
+				if code := errorCode(t, rec); code != "insufficient_scope" {
+					t.Errorf("expected code insufficient_scope, got %q", code)
+				}
+				if !strings.Contains(rec.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`) {
+					t.Errorf("expected an insufficient_scope challenge, got %q", rec.Header().Get("WWW-Authenticate"))
+				}
+			}
+		})
+	}
+
+	// Reads stay open
+	if rec := serveWithBearer(router, "GET", "/anchors", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected reads without a token to be served, got %d", rec.Code)
+	}
+}
+
+func TestJWT_DistinctErrorCodes(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{JWT: testIdP.Verifier()})
+	anchor := `{"hash":"` + strings.Repeat("ab", 32) + `"}`
+
+	expired := jwttest.Claims(ScopeAnchorsWrite)
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	otherAudience := jwttest.Claims(ScopeAnchorsWrite)
+	otherAudience["aud"] = "another-service"
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode string
+		status   int
+	}{
+		{"no token", "", "unauthorized", http.StatusUnauthorized},
+		{"expired", testIdP.Sign("ES256", expired), "token_expired", http.StatusUnauthorized},
+		{"wrong audience", testIdP.Sign("RS256", otherAudience), "invalid_audience", http.StatusUnauthorized},
+		{"forged", jwttest.NewIssuer().Token(ScopeAnchorsWrite), "invalid_token", http.StatusUnauthorized},
+		{"missing scope", testIdP.Token(), "insufficient_scope", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveWithBearer(router, "POST", "/anchors", tt.token, anchor)
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if code := errorCode(t, rec); code != tt.wantCode {
+				t.Errorf("expected code %s, got %s", tt.wantCode, code)
+			}
+			if rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate challenge")
+			}
+		})
+	}
+
+	// An invalid token is rejected even where no scope is needed
+	if rec := serveWithBearer(router, "GET", "/anchors", testIdP.Sign("ES256", expired), ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected an expired token to be rejected on reads, got %d", rec.Code)
+	}
+}
+
+func TestJWT_AdminTokenStillWorks(t *testing.T) {
+	router, ledger := newTestRouter(t, RouterOptions{AdminToken: "s3cret", JWT: testIdP.Verifier()})
+	ledger.CreateAnchor(t.Context(), &domain.Anchor{Hash: "h1", Metadata: json.RawMessage(`"m"`)})
+	ledger.CreateAnchor(t.Context(), &domain.Anchor{Hash: "h2", Metadata: json.RawMessage(`"m"`)})
+
+	if rec := serveWithBearer(router, "DELETE", "/anchors/h1", "s3cret", `{"reason":"gdpr"}`); rec.Code != http.StatusOK {
+		t.Errorf("expected the static admin token to be accepted, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveWithBearer(router, "DELETE", "/anchors/h2", testIdP.Token(ScopeAnchorsWrite), `{"reason":"gdpr"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("expected a token without the admin scope to be refused, got %d", rec.Code)
+	}
+	if rec := serveWithBearer(router, "DELETE", "/anchors/h2", testIdP.Token(ScopeAdmin), `{"reason":"gdpr"}`); rec.Code != http.StatusOK {
+		t.Errorf("expected an admin-scoped token to be accepted, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Revocation with an admin-scoped token needs no issuer proof
+	ledger.CreateAnchor(t.Context(), &domain.Anchor{Hash: "h3", Metadata: json.RawMessage(`"m"`)})
+	if rec := serveWithBearer(router, "POST", "/anchors/h3/revoke", testIdP.Token(ScopeAdmin), `{}`); rec.Code != http.StatusOK {
+		t.Errorf("expected an admin-scoped revoke to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveWithBearer(router, "POST", "/anchors/h3/revoke", testIdP.Token(ScopeAnchorsWrite), `{}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected a non-admin revoke without proof to need one, got %d", rec.Code)
+	}
+}
+
+func TestJWT_LogsSubject(t *testing.T) {
+	var logs accessLogs
+	router, _ := newTestRouter(t, RouterOptions{JWT: testIdP.Verifier(), Logger: logs.logger()})
+
+	serveWithBearer(router, "POST", "/anchors", testIdP.Token(ScopeAnchorsWrite), `{"hash":"`+strings.Repeat("ef", 32)+`"}`)
+	if records := logs.requests(t); len(records) != 1 || records[0]["sub"] != "test-client" {
+		t.Errorf("expected the access log to name the token subject, got %v", records)
+	}
+}
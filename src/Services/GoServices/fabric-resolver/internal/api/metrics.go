@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// httpRequestDuration is labeled with the route template, e.g. /anchors/{hash},
+// so the series do not multiply with every hash and DID requested.
+var httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "fabric_resolver_http_request_duration_seconds",
+	Help:    "HTTP request latency by method, route template and status.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"method", "route", "status"})
+
+// metricsMiddleware observes the latency and status of every routed request.
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+
+		httpRequestDuration.WithLabelValues(r.Method, routeTemplate(r), strconv.Itoa(sw.Status())).Observe(time.Since(start).Seconds())
+	})
+}
+
+// routeTemplate returns the template of the route r matched, or "unknown".
+func routeTemplate(r *http.Request) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if template, err := current.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/infrastructure/fabric"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetrics_ExportsRequestAndLedgerSeries(t *testing.T) {
+	inner, err := fabric.NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	if err != nil {
+		t.Fatalf("failed to create ledger: %v", err)
+	}
+	ledger := fabric.NewMetricsLedgerClient(inner)
+	t.Cleanup(func() { ledger.Close() })
+	prometheus.MustRegister(ledger)
+	t.Cleanup(func() { prometheus.Unregister(ledger) })
+	router := NewRouter(ledger, RouterOptions{})
+
+	hash := strings.Repeat("ab", 32)
+	requests := []struct{ method, path, body string }{
+		{"POST", "/anchors", `{"hash":"` + hash + `"}`},
+		{"POST", "/dids", `{"did":"did:ewallet:metrics","verificationMethod":[{"type":"Ed25519VerificationKey2020","publicKeyBase58":"k"}]}`},
+		{"GET", "/anchors/" + hash + "/verify", ""},
+		{"GET", "/anchors/" + strings.Repeat("cd", 32) + "/verify", ""},
+		{"GET", "/anchors/" + strings.Repeat("ef", 32), ""},
+	}
+	for _, req := range requests {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, strings.NewReader(req.body)))
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 from /metrics, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+
+	for _, series := range []string{
+		`fabric_resolver_http_request_duration_seconds_count{method="POST",route="/anchors",status="201"} `,
+		`fabric_resolver_http_request_duration_seconds_count{method="GET",route="/anchors/{hash}/verify",status="200"} `,
+		`fabric_resolver_http_request_duration_seconds_count{method="GET",route="/anchors/{hash}",status="404"} `,
+		`fabric_resolver_anchors_created_total 1`,
+		`fabric_resolver_dids_created_total 1`,
+		`fabric_resolver_anchor_verifications_total{result="hit"} 1`,
+		`fabric_resolver_ledger_errors_total{operation="GetAnchor",type="not_found"} 1`,
+		`fabric_resolver_ledger_records 2`,
+		`fabric_resolver_ledger_file_size_bytes `,
+	} {
+		if !strings.Contains(body, series) {
+			t.Errorf("expected series %s in /metrics", series)
+		}
+	}
+	// Routes are labeled by template, never by the hash requested
+	if strings.Contains(body, hash) {
+		t.Error("expected no per-hash series")
+	}
+}
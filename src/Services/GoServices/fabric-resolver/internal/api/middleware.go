@@ -0,0 +1,361 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/apikeys"
+	"fabric-resolver/internal/buildinfo"
+	"fabric-resolver/internal/jwtauth"
+	"fabric-resolver/internal/ratelimit"
+	"fabric-resolver/internal/requestid"
+
+	"github.com/gorilla/mux"
+)
+
+// APIKeyHeader carries the API key of write requests.
+const APIKeyHeader = "X-API-Key"
+
+// VersionHeader carries the version of the binary on every response.
+const VersionHeader = "X-Service-Version"
+
+// Scopes a bearer token needs per route group when JWT authentication is on.
+// ScopeAdmin grants every other scope as well.
+const (
+	ScopeAnchorsWrite = "anchors:write"
+	ScopeDIDsWrite    = "dids:write"
+	ScopeAdmin        = "admin"
+)
+
+// adminAuth guards administrative endpoints with a static bearer token, or a JWT
+// with the admin scope when JWT authentication is on. When neither is configured
+// the endpoints are disabled entirely.
+func adminAuth(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if claims := handlers.TokenClaims(r.Context()); claims != nil {
+			if !claims.HasScope(ScopeAdmin) {
+				writeScopeError(w, ScopeAdmin)
+				return
+			}
+			markAdmin(r)
+			next.ServeHTTP(w, r.WithContext(handlers.WithAdmin(r.Context())))
+			return
+		}
+		if token == "" {
+			writeError(w, http.StatusForbidden, "Admin API is disabled")
+			return
+		}
+
+		presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeError(w, http.StatusUnauthorized, "Admin token required")
+			return
+		}
+
+		markAdmin(r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// adminOrIssuerAuth marks requests bearing the admin token, or a JWT with the
+// admin scope, as admin requests. Requests without an Authorization header, or
+// with a JWT lacking that scope, are passed on so the handler can authorize them
+// by an issuer signature instead; any other token is rejected.
+func adminOrIssuerAuth(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if claims := handlers.TokenClaims(r.Context()); claims != nil {
+			if claims.HasScope(ScopeAdmin) {
+				markAdmin(r)
+				r = r.WithContext(handlers.WithAdmin(r.Context()))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		auth := r.Header.Get("Authorization")
+		if auth == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		presented, ok := strings.CutPrefix(auth, "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeError(w, http.StatusUnauthorized, "Invalid admin token")
+			return
+		}
+
+		markAdmin(r)
+		next.ServeHTTP(w, r.WithContext(handlers.WithAdmin(r.Context())))
+	})
+}
+
+// apiKeyAuth requires a valid API key on write requests, and on reads too when
+// protectReads is set; /health stays open for probes. The identifier of the key is
+// attached to the request context and the access log, never the key itself.
+func apiKeyAuth(keys *apikeys.Keys, protectReads bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !requiresAPIKey(r, protectReads) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			presented := r.Header.Get(APIKeyHeader)
+			id, ok := keys.Lookup(presented)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `APIKey header="`+APIKeyHeader+`"`)
+				if presented == "" {
+					writeError(w, http.StatusUnauthorized, "API key required")
+				} else {
+					writeError(w, http.StatusUnauthorized, "Invalid API key")
+				}
+				return
+			}
+
+			if entry, ok := r.Context().Value(accessLogKey{}).(*accessLog); ok {
+				entry.apiKeyID = id
+			}
+			next.ServeHTTP(w, r.WithContext(handlers.WithAPIKeyID(r.Context(), id)))
+		})
+	}
+}
+
+// bearerAuth verifies JWT bearer tokens and attaches their claims to the request
+// context; routes then demand scopes with requireScope. Requests without a JWT are
+// passed on, so a static admin token still reaches adminAuth, but a JWT that fails
+// verification is rejected here with a code telling expiry and a wrong audience
+// apart from other failures.
+func bearerAuth(verifier *jwtauth.Verifier) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || !jwtauth.IsJWT(token) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := verifier.Verify(r.Context(), token)
+			if err != nil {
+				writeTokenError(w, err)
+				return
+			}
+
+			if entry, ok := r.Context().Value(accessLogKey{}).(*accessLog); ok {
+				entry.tokenSubject = claims.Subject
+			}
+			next.ServeHTTP(w, r.WithContext(handlers.WithTokenClaims(r.Context(), claims)))
+		})
+	}
+}
+
+// requireScope rejects requests whose bearer token lacks scope, or that carry no
+// token at all.
+func requireScope(scope string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := handlers.TokenClaims(r.Context())
+		if claims == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer scope="`+scope+`"`)
+			writeErrorCode(w, http.StatusUnauthorized, handlers.CodeUnauthorized, "Bearer token required")
+			return
+		}
+		if !claims.HasScope(scope) && !claims.HasScope(ScopeAdmin) {
+			writeScopeError(w, scope)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeTokenError(w http.ResponseWriter, err error) {
+	code, message := handlers.CodeInvalidToken, "Invalid token: "+strings.TrimPrefix(err.Error(), jwtauth.ErrInvalidToken.Error()+": ")
+	switch {
+	case errors.Is(err, jwtauth.ErrExpired):
+		code, message = handlers.CodeTokenExpired, "Token expired"
+	case errors.Is(err, jwtauth.ErrInvalidAudience):
+		code, message = handlers.CodeInvalidAudience, "Token was issued for another audience"
+	}
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	writeErrorCode(w, http.StatusUnauthorized, code, message)
+}
+
+func writeScopeError(w http.ResponseWriter, scope string) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+	writeErrorCode(w, http.StatusForbidden, handlers.CodeInsufficientScope, "Token lacks the "+scope+" scope")
+}
+
+func requiresAPIKey(r *http.Request, protectReads bool) bool {
+	if isWrite(r.Method) {
+		return true
+	}
+	return protectReads && r.Method != http.MethodOptions && !isProbe(r.URL.Path)
+}
+
+// isProbe reports whether path is a liveness or readiness probe, which stay open
+// and unlimited so orchestrators can always reach them.
+func isProbe(path string) bool {
+	return path == "/health" || path == "/livez" || path == "/readyz"
+}
+
+func isWrite(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// rateLimit applies the reads or writes limiter to each request, keyed by client.
+// Probes, metrics scrapes and CORS preflights are not limited; a nil limiter
+// leaves its class unlimited.
+func rateLimit(reads, writes *ratelimit.Limiter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limiter := limiterFor(r, reads, writes)
+			if limiter == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if ok, wait := limiter.Allow(rateLimitKey(r)); !ok {
+				writeRateLimited(w, wait)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authFailureLimit throttles failed authentication by IP ahead of the checks that
+// rateLimit follows, since a caller that never authenticates has no other key to
+// be limited by. Each request answered 401 takes a token from the bucket of its
+// IP, and an IP whose bucket is empty is answered 429 before its credentials are
+// looked at. Requests that authenticate take nothing from it.
+func authFailureLimit(reads, writes *ratelimit.Limiter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limiter := limiterFor(r, reads, writes)
+			if limiter == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := "auth-failures:ip:" + remoteIP(r)
+			if ok, wait := limiter.Peek(key); !ok {
+				writeRateLimited(w, wait)
+				return
+			}
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+			if sw.Status() == http.StatusUnauthorized {
+				limiter.Allow(key)
+			}
+		})
+	}
+}
+
+// limiterFor returns the limiter of the class of r, or nil when r is not limited.
+func limiterFor(r *http.Request, reads, writes *ratelimit.Limiter) *ratelimit.Limiter {
+	if r.Method == http.MethodOptions || isProbe(r.URL.Path) || r.URL.Path == "/metrics" {
+		return nil
+	}
+	if isWrite(r.Method) {
+		return writes
+	}
+	return reads
+}
+
+func writeRateLimited(w http.ResponseWriter, wait time.Duration) {
+	retryAfter := max(1, int(math.Ceil(wait.Seconds())))
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	writeErrorCode(w, http.StatusTooManyRequests, handlers.CodeRateLimited,
+		fmt.Sprintf("Rate limit exceeded; retry after %d seconds", retryAfter))
+}
+
+// rateLimitKey identifies the client of r by its API key, else its bearer token
+// subject, else its IP.
+func rateLimitKey(r *http.Request) string {
+	if id := handlers.APIKeyID(r.Context()); id != "" {
+		return "key:" + id
+	}
+	if claims := handlers.TokenClaims(r.Context()); claims != nil && claims.Subject != "" {
+		return "sub:" + claims.Subject
+	}
+	return "ip:" + remoteIP(r)
+}
+
+// remoteIP returns the IP of the connection's peer; forwarding headers are ignored
+// since any client can set them.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// requestIDMiddleware adopts the caller's X-Request-ID, or generates one when it is
+// missing or unsafe to log, and echoes it on the response. Handlers and outbound
+// calls read it from the context; error bodies read it from the response header.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.WithID(r.Context(), id)))
+	})
+}
+
+// versionMiddleware names the version of the binary on every response, so a
+// caller can tell which build of a rollout answered.
+func versionMiddleware(next http.Handler) http.Handler {
+	version := buildinfo.Current().Version
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(VersionHeader, version)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientCertMiddleware exposes the subject of a verified client certificate to
+// handlers through handlers.ClientSubject, so writes can be attributed under mTLS.
+func clientCertMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			subject := r.TLS.VerifiedChains[0][0].Subject.String()
+			r = r.WithContext(handlers.WithClientSubject(r.Context(), subject))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeError writes the same error body the handlers use.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeErrorResponse(w, status, handlers.NewErrorResponse(status, message))
+}
+
+// writeErrorCode writes an error body with a code more specific than the status.
+func writeErrorCode(w http.ResponseWriter, status int, code, message string) {
+	writeErrorResponse(w, status, handlers.ErrorResponse{Error: message, Code: code, Message: message})
+}
+
+func writeErrorResponse(w http.ResponseWriter, status int, body handlers.ErrorResponse) {
+	body = handlers.WithRequestID(w, body)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Error("Failed to encode error response", "err", err)
+	}
+}
@@ -0,0 +1,201 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// roundTrip encodes the document and decodes it again, as a client would see it.
+func roundTrip(t *testing.T) *Document {
+	t.Helper()
+	body, err := json.Marshal(Build())
+	if err != nil {
+		t.Fatalf("failed to encode document: %v", err)
+	}
+	var doc Document
+	if err := json.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("failed to decode document: %v", err)
+	}
+	return &doc
+}
+
+func TestExamplesMatchSchemas(t *testing.T) {
+	doc := roundTrip(t)
+
+	for path, item := range doc.Paths {
+		for method, op := range *item {
+			name := strings.ToUpper(method) + " " + path
+			if op.RequestBody != nil {
+				for mediaType, content := range op.RequestBody.Content {
+					checkExample(t, doc, name+" request "+mediaType, content)
+				}
+			}
+			for status, resp := range op.Responses {
+				for mediaType, content := range resp.Content {
+					checkExample(t, doc, name+" "+status+" "+mediaType, content)
+				}
+			}
+		}
+	}
+}
+
+func checkExample(t *testing.T, doc *Document, name string, content *MediaType) {
+	t.Helper()
+	if content.Example == nil {
+		return
+	}
+	for _, err := range validate(doc, content.Schema, content.Example, "$") {
+		t.Errorf("%s: %v", name, err)
+	}
+}
+
+func TestEveryOperationDocumentsErrors(t *testing.T) {
+	doc := roundTrip(t)
+	errorRef := "#/components/schemas/ErrorResponse"
+	if _, ok := doc.Components.Schemas["ErrorResponse"]; !ok {
+		t.Fatal("ErrorResponse schema is missing")
+	}
+
+	ids := make(map[string]bool)
+	for path, item := range doc.Paths {
+		for method, op := range *item {
+			name := strings.ToUpper(method) + " " + path
+			if ids[op.OperationID] {
+				t.Errorf("%s: duplicate operationId %q", name, op.OperationID)
+			}
+			ids[op.OperationID] = true
+
+			for status, resp := range op.Responses {
+				if status != "default" && status[0] < '4' {
+					continue
+				}
+				if got := resp.Content["application/json"]; got == nil || got.Schema.Ref != errorRef {
+					t.Errorf("%s: %s response does not use ErrorResponse", name, status)
+				}
+			}
+			if op.Responses["default"] == nil {
+				t.Errorf("%s: no default error response", name)
+			}
+		}
+	}
+}
+
+func TestSchemaFollowsJSONTags(t *testing.T) {
+	type inner struct {
+		Value int `json:"value"`
+	}
+	type embedded struct {
+		Promoted string `json:"promoted"`
+	}
+	type sample struct {
+		embedded
+		Name     string            `json:"name"`
+		Optional string            `json:"optional,omitempty"`
+		Skipped  string            `json:"-"`
+		Inner    *inner            `json:"inner"`
+		Tags     map[string]string `json:"tags"`
+		hidden   string
+	}
+
+	g := newSchemaGenerator()
+	ref := g.schemaFor(sample{})
+	s := g.components[strings.TrimPrefix(ref.Ref, "#/components/schemas/")]
+	if s == nil {
+		t.Fatalf("sample was not registered: %+v", ref)
+	}
+
+	var props []string
+	for name := range s.Properties {
+		props = append(props, name)
+	}
+	sort.Strings(props)
+	if got := strings.Join(props, ","); got != "inner,name,optional,promoted,tags" {
+		t.Errorf("unexpected properties %s", got)
+	}
+	sort.Strings(s.Required)
+	if got := strings.Join(s.Required, ","); got != "inner,name,promoted,tags" {
+		t.Errorf("unexpected required %s", got)
+	}
+	if inner := s.Properties["inner"]; !inner.Nullable || len(inner.AllOf) != 1 || inner.AllOf[0].Ref != "#/components/schemas/Inner" {
+		t.Errorf("pointer field should be a nullable reference: %+v", inner)
+	}
+}
+
+// validate checks a decoded JSON value against schema. Objects may not carry
+// properties the schema does not declare, so an undocumented DTO field fails.
+func validate(doc *Document, schema *Schema, value interface{}, at string) []error {
+	if schema.Ref != "" {
+		target := doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+		if target == nil {
+			return []error{fmt.Errorf("%s: unresolved %s", at, schema.Ref)}
+		}
+		schema = target
+	}
+	if value == nil {
+		if schema.Nullable {
+			return nil
+		}
+		return []error{fmt.Errorf("%s: null is not allowed", at)}
+	}
+
+	var errs []error
+	for _, sub := range schema.AllOf {
+		errs = append(errs, validate(doc, sub, value, at)...)
+	}
+
+	switch schema.Type {
+	case "":
+		// Any value
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return append(errs, fmt.Errorf("%s: expected object, got %T", at, value))
+		}
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				errs = append(errs, fmt.Errorf("%s: missing required %q", at, name))
+			}
+		}
+		for name, v := range obj {
+			switch prop, ok := schema.Properties[name]; {
+			case ok:
+				errs = append(errs, validate(doc, prop, v, at+"."+name)...)
+			case schema.AdditionalProperties != nil:
+				errs = append(errs, validate(doc, schema.AdditionalProperties, v, at+"."+name)...)
+			default:
+				errs = append(errs, fmt.Errorf("%s: undocumented property %q", at, name))
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return append(errs, fmt.Errorf("%s: expected array, got %T", at, value))
+		}
+		for i, v := range arr {
+			errs = append(errs, validate(doc, schema.Items, v, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			errs = append(errs, fmt.Errorf("%s: expected string, got %T", at, value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs = append(errs, fmt.Errorf("%s: expected boolean, got %T", at, value))
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			errs = append(errs, fmt.Errorf("%s: expected integer, got %v", at, value))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			errs = append(errs, fmt.Errorf("%s: expected number, got %T", at, value))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s: unknown type %q", at, schema.Type))
+	}
+	return errs
+}
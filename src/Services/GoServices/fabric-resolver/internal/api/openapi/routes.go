@@ -0,0 +1,819 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/audit"
+	"fabric-resolver/internal/buildinfo"
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/pkg/receipt"
+	"fabric-resolver/internal/ratelimit"
+	"fabric-resolver/internal/requestid"
+)
+
+// route describes one operation of the router. request and response are example
+// values; their types also provide the schemas.
+type route struct {
+	method   string
+	path     string // OpenAPI path template, without mux regexps
+	id       string
+	summary  string
+	tag      string
+	query    []Parameter
+	headers  []Parameter
+	request  interface{}
+	status   int
+	response interface{}
+	// contentType of the success response; empty means application/json.
+	// A nil response has no content.
+	contentType string
+	errors      []int
+	admin       bool
+	// scope a JWT bearer token needs for the route; admin routes need "admin"
+	scope string
+	// shared routes are served alike to every tenant; the others of the API take
+	// the tenant from X-Tenant-ID when the server has tenants
+	shared bool
+}
+
+// The payloads below are built from maps in the handlers; these types document their shape.
+
+type healthResponse struct {
+	Status    string            `json:"status"`
+	Timestamp string            `json:"timestamp"`
+	Service   string            `json:"service"`
+	Checks    map[string]string `json:"checks,omitempty"`
+	Build     buildinfo.Build   `json:"build"`
+}
+
+type statsResponse struct {
+	fabric.Stats
+	ReadOnly   bool                       `json:"readOnly"`
+	Process    buildinfo.Info             `json:"process"`
+	RateLimits map[string]ratelimit.Stats `json:"rateLimits,omitempty"`
+	Tenants    map[string]fabric.Stats    `json:"tenants,omitempty"`
+	Timestamp  string                     `json:"timestamp"`
+}
+
+type readOnlyState struct {
+	ReadOnly bool `json:"readOnly"`
+}
+
+type basicStatsResponse struct {
+	Anchors   int            `json:"anchors"`
+	DIDs      int            `json:"dids"`
+	DocTypes  map[string]int `json:"docTypes,omitempty"`
+	Timestamp string         `json:"timestamp"`
+}
+
+type tombstoneResponse struct {
+	Hash   string `json:"hash"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+type deactivateDidResponse struct {
+	Did    string `json:"did"`
+	Status string `json:"status"`
+}
+
+const (
+	exampleHash   = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	exampleRoot   = "a1fce4363854ff888cff4b8e7875d600c2682390412a8cf79b37d0b11148b0fa"
+	exampleIssuer = "did:ewallet:issuer"
+	exampleTxID   = "3f1c0d2e9b8a7f6e5d4c3b2a1908f7e6d5c4b3a29180f7e6d5c4b3a291807f6e"
+	exampleTime   = "2025-01-01T12:00:00Z"
+	exampleList   = "http://localhost:8080/status-lists/revocation-1"
+
+	exampleReceiptKeyID = "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+)
+
+var (
+	exampleMetadata  = json.RawMessage(`{"credentialType":"diploma"}`)
+	exampleTimestamp = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	exampleBuild     = buildinfo.Build{Version: "v1.4.0", Commit: "3f9c2ab", BuildDate: "2025-01-01T10:00:00Z"}
+
+	exampleAnchor = handlers.AnchorResponse{
+		Hash:              exampleHash,
+		Algorithm:         domain.HashSHA256,
+		IssuerDID:         exampleIssuer,
+		Timestamp:         exampleTime,
+		BlockNumber:       42,
+		TxID:              exampleTxID,
+		Metadata:          exampleMetadata,
+		MetadataHash:      exampleRoot,
+		SignatureVerified: true,
+	}
+
+	exampleProof = &handlers.AnchorProof{
+		VerificationMethod: "#key-1",
+		Signature:          "p5sUeqBo1bXuT5bHWyPuHjLoF3GZqDkkBStXxTJp5IwBxlpkAKg9qqWY6N2aZ4hNbqRJXlb9Q6nfXqCdW2UhAg",
+		Created:            exampleTime,
+	}
+
+	exampleDidDocument = handlers.DidDocumentResponse{
+		Context: []string{"https://www.w3.org/ns/did/v1"},
+		ID:      exampleIssuer,
+		VerificationMethod: []handlers.VerificationMethodDto{{
+			ID:                 exampleIssuer + "#key-1",
+			Type:               "Ed25519VerificationKey2020",
+			Controller:         exampleIssuer,
+			PublicKeyMultibase: "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK",
+		}},
+		Authentication:  []string{exampleIssuer + "#key-1"},
+		AssertionMethod: []string{exampleIssuer + "#key-1"},
+		Created:         exampleTime,
+		Updated:         exampleTime,
+	}
+
+	exampleCreateDid = handlers.CreateDidRequest{
+		Did: exampleIssuer,
+		VerificationMethod: []handlers.VerificationMethodRequest{{
+			Type:         "JsonWebKey2020",
+			PublicKeyJwk: &domain.JWK{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+		}},
+		Service: []handlers.ServiceRequest{{Type: "LinkedDomains", ServiceEndpoint: "https://issuer.example"}},
+	}
+
+	exampleWebhook = handlers.WebhookResponse{
+		ID:      "wh_3f1c0d2e9b8a7f6e",
+		URL:     "https://indexer.example/hooks/ledger",
+		Events:  []string{domain.EventAnchorCreated},
+		Signed:  true,
+		Created: exampleTime,
+	}
+
+	exampleNonce = "q0b1x3Xk6Vt1eE4QWZ2b8r9sYp7mNfA5cD0hJgLkUoI"
+
+	exampleDidProof = &handlers.DidUpdateProof{
+		VerificationMethod: "#key-1",
+		Nonce:              exampleNonce,
+		Signature:          exampleProof.Signature,
+	}
+
+	exampleCommitmentRequest = handlers.CommitmentRequest{
+		TenantID: "tenant-a",
+		Document: json.RawMessage(`{"name":"Alice","dob":"1990-01-01"}`),
+	}
+
+	exampleMerkleProof = []handlers.MerkleProofStepDto{{Hash: exampleRoot, Position: "right"}}
+
+	pageParams = []Parameter{
+		queryParam("limit", "integer", "Page size (default 50); values above 500 are clamped to 500"),
+		queryParam("cursor", "string", "Opaque nextCursor of the previous page, also sent in the Link header"),
+	}
+)
+
+// routes lists every operation registered by api.NewRouter.
+var routes = []route{
+	{
+		method: "GET", path: "/livez", id: "getLiveness", tag: "ops",
+		summary:  "Report that the process is up (liveness probe)",
+		status:   http.StatusOK,
+		response: healthResponse{Status: "healthy", Timestamp: exampleTime, Service: "fabric-resolver", Build: exampleBuild},
+	},
+	{
+		method: "GET", path: "/readyz", id: "getReadiness", tag: "ops",
+		summary:  "Report whether the ledger can serve requests (readiness probe); 503 names the failing component",
+		status:   http.StatusOK,
+		response: healthResponse{Status: "healthy", Timestamp: exampleTime, Service: "fabric-resolver", Checks: map[string]string{"ledger": "ok"}, Build: exampleBuild},
+		errors:   []int{http.StatusServiceUnavailable},
+	},
+	{
+		method: "GET", path: "/health", id: "getHealth", tag: "ops",
+		summary:  "Alias of /readyz",
+		status:   http.StatusOK,
+		response: healthResponse{Status: "healthy", Timestamp: exampleTime, Service: "fabric-resolver", Checks: map[string]string{"ledger": "ok"}, Build: exampleBuild},
+		errors:   []int{http.StatusServiceUnavailable},
+	},
+	{
+		method: "GET", path: "/version", id: "getVersion", tag: "ops",
+		summary:  "Version, commit and build date of the binary, also sent as X-Service-Version on every response",
+		status:   http.StatusOK,
+		response: exampleBuild,
+	},
+	{
+		method: "GET", path: "/stats", id: "getStats", tag: "ops",
+		summary: "Ledger, process and rate limit statistics",
+		status:  http.StatusOK,
+		response: statsResponse{
+			Stats: fabric.Stats{
+				Anchors: 10, DIDs: 2, NextBlock: 13, Mode: "file-persistent", Path: "data/ledger.json", FileSizeBytes: 5120,
+				DocTypes:   map[string]int{"anchor": 10, "did": 2},
+				LastWrites: map[string]time.Time{"anchor": exampleTimestamp, "did": exampleTimestamp},
+			},
+			Process: buildinfo.Info{Build: exampleBuild, GoVersion: "go1.24.0", StartedAt: exampleTimestamp, UptimeSeconds: 86400},
+			RateLimits: map[string]ratelimit.Stats{
+				"reads":  {Rate: 50, Burst: 100, Clients: 4, Allowed: 1200, Limited: 0},
+				"writes": {Rate: 10, Burst: 20, Clients: 2, Allowed: 310, Limited: 12},
+			},
+			Timestamp: exampleTime,
+		},
+		errors: []int{http.StatusUnauthorized, http.StatusForbidden},
+		admin:  true,
+	},
+	{
+		method: "GET", path: "/stats/basic", id: "getBasicStats", tag: "ops",
+		summary:  "Record counts of the ledger",
+		status:   http.StatusOK,
+		response: basicStatsResponse{Anchors: 10, DIDs: 2, DocTypes: map[string]int{"anchor": 10, "did": 2}, Timestamp: exampleTime},
+	},
+	{
+		method: "GET", path: "/admin/read-only", id: "getReadOnly", tag: "ops",
+		summary:  "Whether the server is in read-only mode",
+		status:   http.StatusOK,
+		response: readOnlyState{ReadOnly: false},
+		errors:   []int{http.StatusUnauthorized, http.StatusForbidden},
+		admin:    true,
+	},
+	{
+		method: "PUT", path: "/admin/read-only", id: "setReadOnly", tag: "ops",
+		summary:  "Switch read-only mode, in which writes are answered 503 with the read_only code and Retry-After while reads are served",
+		request:  readOnlyState{ReadOnly: true},
+		status:   http.StatusOK,
+		response: readOnlyState{ReadOnly: true},
+		errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusServiceUnavailable},
+		admin:    true,
+	},
+	{
+		method: "GET", path: "/admin/audit", id: "listAuditEntries", tag: "ops",
+		summary: "Audit entries of write requests, oldest first",
+		query: []Parameter{
+			queryParam("since", "string", "RFC 3339 time; only entries after it, such as the nextCursor of the previous page"),
+			queryParam("limit", "integer", "Page size (default 50); values above 500 are clamped to 500"),
+		},
+		status: http.StatusOK,
+		response: handlers.AuditPageResponse{Items: []audit.Entry{{
+			Time: exampleTimestamp, RequestID: "4f9c1d2e8a7b6c5d", Principal: "key:ci", Method: "POST", Route: "/v1/anchors",
+			Resource: exampleHash, Status: http.StatusCreated, Result: audit.ResultSuccess, SourceIP: "10.0.0.7",
+		}}},
+		errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound},
+		admin:  true,
+	},
+	{
+		method: "GET", path: "/metrics", id: "getMetrics", tag: "ops",
+		summary:     "Prometheus metrics",
+		status:      http.StatusOK,
+		response:    "# TYPE go_goroutines gauge\ngo_goroutines 12\n",
+		contentType: "text/plain",
+	},
+	{
+		method: "GET", path: "/openapi.json", id: "getOpenAPI", tag: "ops",
+		summary:  "This document",
+		status:   http.StatusOK,
+		response: map[string]interface{}{"openapi": Version},
+	},
+	{
+		method: "GET", path: "/docs", id: "getDocs", tag: "ops",
+		summary:     "Swagger UI for this document",
+		status:      http.StatusOK,
+		response:    "<!DOCTYPE html>",
+		contentType: "text/html",
+	},
+
+	{
+		method: "POST", path: "/anchors", id: "createAnchor", tag: "anchors",
+		scope:   "anchors:write",
+		summary: "Anchor a document hash",
+		headers: []Parameter{headerParam(handlers.IdempotencyKeyHeader, "Replays the first response to requests repeating this key with the same body")},
+		request: handlers.CreateAnchorRequest{
+			Hash:      exampleHash,
+			Algorithm: domain.HashSHA256,
+			IssuerDID: exampleIssuer,
+			Metadata:  exampleMetadata,
+			Proof:     exampleProof,
+		},
+		status:   http.StatusCreated,
+		response: exampleAnchor,
+		errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusConflict, http.StatusRequestEntityTooLarge, http.StatusInternalServerError},
+	},
+	{
+		method: "GET", path: "/anchors", id: "listAnchors", tag: "anchors",
+		summary: "List anchors by page, block or time range, or hash prefix",
+		query: append(append([]Parameter{}, pageParams...),
+			queryParam("fromBlock", "integer", "First block, inclusive"),
+			queryParam("toBlock", "integer", "Last block, inclusive"),
+			queryParam("from", "string", "Earliest timestamp, RFC3339"),
+			queryParam("to", "string", "Latest timestamp, RFC3339"),
+			queryParam("prefix", "string", "Hash prefix of at least 4 hex characters"),
+		),
+		status:   http.StatusOK,
+		response: handlers.AnchorPageResponse{Items: []handlers.AnchorResponse{exampleAnchor}, Total: 1},
+		errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	{
+		method: "POST", path: "/anchors/batch", id: "createAnchorsBatch", tag: "anchors",
+		scope:   "anchors:write",
+		summary: "Anchor several hashes; each item succeeds or fails on its own",
+		request: []handlers.CreateAnchorRequest{{Hash: exampleHash}, {Hash: exampleRoot, IssuerDID: exampleIssuer}},
+		status:  http.StatusMultiStatus,
+		response: handlers.BatchAnchorResponse{
+			Created: 1,
+			Existed: 1,
+			Results: []handlers.BatchAnchorResult{
+				{Index: 0, Hash: exampleHash, Status: "already-existed", Code: http.StatusConflict},
+				{Index: 1, Hash: exampleRoot, Status: "created", Code: http.StatusCreated, TxID: exampleTxID, BlockNumber: 43},
+			},
+		},
+		errors: []int{http.StatusBadRequest},
+	},
+	{
+		method: "POST", path: "/anchors/from-document", id: "createAnchorFromDocument", tag: "anchors",
+		scope:   "anchors:write",
+		summary: "Canonicalize a JSON document and anchor its SHA-256",
+		query: []Parameter{
+			queryParam("issuerDid", "string", "Issuer DID of the anchor"),
+			queryParam("metadata", "string", "Anchor metadata as a JSON value"),
+		},
+		headers: []Parameter{
+			headerParam(handlers.IssuerDIDHeader, "Issuer DID, when the issuerDid parameter is absent"),
+			headerParam(handlers.AnchorMetadataHeader, "Anchor metadata, when the metadata parameter is absent"),
+		},
+		request: map[string]interface{}{
+			"type":   "VerifiableCredential",
+			"issuer": exampleIssuer,
+		},
+		status:   http.StatusCreated,
+		response: handlers.DocumentAnchorResponse{Hash: exampleHash, Anchor: exampleAnchor},
+		errors:   []int{http.StatusBadRequest, http.StatusConflict, http.StatusRequestEntityTooLarge, http.StatusInternalServerError},
+	},
+	{
+		method: "POST", path: "/anchors/verify-batch", id: "verifyAnchorsBatch", tag: "anchors",
+		summary: "Look up the state of several hashes in one ledger read",
+		request: handlers.VerifyBatchRequest{Hashes: []string{exampleHash, exampleRoot}},
+		status:  http.StatusOK,
+		response: handlers.VerifyBatchResponse{Results: map[string]handlers.VerifyBatchResult{
+			exampleHash: {Exists: true, BlockNumber: 42, Timestamp: exampleTime},
+			exampleRoot: {},
+		}},
+		errors: []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	{
+		method: "POST", path: "/anchors/merkle-batch", id: "createMerkleBatch", tag: "anchors",
+		scope:   "anchors:write",
+		summary: "Anchor the Merkle root of many hashes",
+		request: handlers.MerkleBatchRequest{Leaves: []string{exampleHash, exampleRoot}, IssuerDID: exampleIssuer},
+		status:  http.StatusCreated,
+		response: handlers.MerkleBatchResponse{
+			Root:        exampleRoot,
+			TxID:        exampleTxID,
+			BlockNumber: 44,
+			Proofs:      []handlers.MerkleLeafProof{{Leaf: exampleHash, Proof: exampleMerkleProof}},
+		},
+		errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusRequestEntityTooLarge, http.StatusInternalServerError},
+	},
+	{
+		method: "POST", path: "/anchors/merkle-verify", id: "verifyMerkleProof", tag: "anchors",
+		summary:  "Check a Merkle inclusion proof against an anchored root",
+		request:  handlers.MerkleVerifyRequest{Leaf: exampleHash, Proof: exampleMerkleProof, Root: exampleRoot},
+		status:   http.StatusOK,
+		response: handlers.MerkleVerifyResponse{Root: exampleRoot, Anchored: true, ProofValid: true, Valid: true},
+		errors:   []int{http.StatusBadRequest},
+	},
+	{
+		method: "GET", path: "/anchors/stream", id: "streamAnchors", tag: "anchors",
+		summary: "Stream new anchors as server-sent events. A replay after Last-Event-ID stops after " + strconv.Itoa(handlers.DefaultStreamReplayLimit) + " anchors with a truncated event and the end of the stream; reconnecting with its ID continues it",
+		headers: []Parameter{headerParam("Last-Event-ID", "Block number; anchors of later blocks are replayed first")},
+		status:  http.StatusOK,
+		response: "id: 42\nevent: anchor\ndata: " + mustJSON(handlers.AnchorStreamEvent{
+			Hash: exampleHash, BlockNumber: 42, TxID: exampleTxID, Timestamp: exampleTime,
+		}) + "\n\n",
+		contentType: "text/event-stream",
+		errors:      []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError, http.StatusServiceUnavailable},
+		admin:       true,
+	},
+	{
+		method: "GET", path: "/anchors/{hash}", id: "getAnchor", tag: "anchors",
+		summary:  "Get an anchor",
+		status:   http.StatusOK,
+		response: exampleAnchor,
+		errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusGone, http.StatusServiceUnavailable},
+	},
+	{
+		method: "GET", path: "/anchors/{hash}/verify", id: "verifyAnchor", tag: "anchors",
+		summary: "Check that a hash is anchored, not revoked and fresh enough",
+		query: []Parameter{
+			queryParam("maxAge", "string", "Maximum age as a Go duration (36h) or days (30d)"),
+			queryParam("notBefore", "string", "Earliest accepted timestamp, RFC3339"),
+			queryParam("notAfter", "string", "Latest accepted timestamp, RFC3339"),
+		},
+		status: http.StatusOK,
+		response: handlers.VerifyAnchorResponse{
+			Hash: exampleHash, Exists: true, Valid: true, SignatureVerified: true, Timestamp: exampleTime, Reasons: []string{},
+		},
+		errors: []int{http.StatusBadRequest},
+	},
+	{
+		method: "POST", path: "/anchors/{hash}/revoke", id: "revokeAnchor", tag: "anchors",
+		scope:   "anchors:write",
+		summary: "Revoke an anchor with the admin token or, if its issuer was verified, an issuer proof over revoke:<hash>",
+		request: handlers.RevokeAnchorRequest{Reason: "superseded", Proof: exampleProof},
+		status:  http.StatusOK,
+		response: func() handlers.AnchorResponse {
+			a := exampleAnchor
+			a.Revoked, a.RevokedAt, a.RevocationReason = true, exampleTime, "superseded"
+			return a
+		}(),
+		errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError},
+		admin:  true,
+	},
+	{
+		method: "DELETE", path: "/anchors/{hash}", id: "tombstoneAnchor", tag: "anchors",
+		summary:  "Tombstone an anchor",
+		request:  handlers.TombstoneAnchorRequest{Reason: "GDPR erasure request"},
+		status:   http.StatusOK,
+		response: tombstoneResponse{Hash: exampleHash, Status: "tombstoned", Reason: "GDPR erasure request"},
+		errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+		admin:    true,
+	},
+	{
+		method: "GET", path: "/anchors/{hash}/receipt", id: "getAnchorReceipt", tag: "receipts",
+		summary: "Get a receipt for an anchor, signed with Ed25519 over the SHA-256 of its canonical JSON",
+		status:  http.StatusOK,
+		response: receipt.SignedReceipt{
+			Receipt: receipt.Receipt{
+				Hash:        exampleHash,
+				Algorithm:   domain.HashSHA256,
+				TxID:        exampleTxID,
+				BlockNumber: 42,
+				Timestamp:   exampleTime,
+				IssuerDID:   exampleIssuer,
+				IssuedAt:    exampleTime,
+			},
+			KeyID:     exampleReceiptKeyID,
+			Signature: exampleProof.Signature,
+		},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusGone, http.StatusInternalServerError},
+	},
+	{
+		method: "GET", path: "/receipts/public-key", id: "getReceiptPublicKey", tag: "receipts", shared: true,
+		summary: "Get the key that verifies anchor receipts",
+		status:  http.StatusOK,
+		response: handlers.ReceiptPublicKeyResponse{
+			KeyID:              exampleReceiptKeyID,
+			Type:               "Ed25519VerificationKey2020",
+			PublicKeyMultibase: "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK",
+			PublicKeyJwk:       &domain.JWK{Kty: "OKP", Crv: "Ed25519", X: "Lm_M42cB3HkUiODQsXRcweM6TByfzEHGO9ND274JcOY"},
+		},
+		errors: []int{http.StatusNotFound},
+	},
+	{
+		method: "GET", path: "/issuers/{did}/anchors", id: "listAnchorsByIssuer", tag: "anchors",
+		summary:  "List the anchors of an issuer",
+		query:    pageParams,
+		status:   http.StatusOK,
+		response: handlers.AnchorPageResponse{Items: []handlers.AnchorResponse{exampleAnchor}, Total: 1},
+		errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	{
+		method: "GET", path: "/transactions/{txId}/anchor", id: "getAnchorByTxId", tag: "anchors",
+		summary:  "Get the anchor written by a transaction",
+		status:   http.StatusOK,
+		response: exampleAnchor,
+		errors:   []int{http.StatusNotFound, http.StatusGone, http.StatusServiceUnavailable},
+	},
+
+	{
+		method: "POST", path: "/commitments", id: "createCommitment", tag: "commitments",
+		scope:    "anchors:write",
+		summary:  "Anchor the HMAC commitment of a document under the caller's tenant key",
+		request:  exampleCommitmentRequest,
+		status:   http.StatusCreated,
+		response: handlers.CommitmentResponse{TenantID: "tenant-a", Commitment: exampleHash, Anchor: handlers.AnchorResponse{Hash: exampleHash, Algorithm: domain.HashSHA256, Timestamp: exampleTime, BlockNumber: 42, TxID: exampleTxID}},
+		errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		method: "POST", path: "/commitments/verify", id: "verifyCommitment", tag: "commitments",
+		scope:    "anchors:write",
+		summary:  "Recompute a document's commitment under the caller's tenant key and check that it is anchored",
+		request:  exampleCommitmentRequest,
+		status:   http.StatusOK,
+		response: handlers.VerifyCommitmentResponse{TenantID: "tenant-a", Exists: true, Valid: true, Timestamp: exampleTime},
+		errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusInternalServerError},
+	},
+
+	{
+		method: "POST", path: "/dids", id: "createDid", tag: "dids",
+		scope:    "dids:write",
+		summary:  "Register a DID document",
+		request:  exampleCreateDid,
+		status:   http.StatusCreated,
+		response: exampleDidDocument,
+		errors:   []int{http.StatusBadRequest, http.StatusConflict, http.StatusInternalServerError},
+	},
+	{
+		method: "GET", path: "/dids", id: "listDids", tag: "dids",
+		summary:  "List DID documents",
+		query:    append(append([]Parameter{}, pageParams...), queryParam("controller", "string", "Only DIDs with this controller")),
+		status:   http.StatusOK,
+		response: handlers.DidPageResponse{Items: []handlers.DidDocumentResponse{exampleDidDocument}, Total: 1},
+		errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	{
+		method: "GET", path: "/dids/{did}", id: "resolveDid", tag: "dids",
+		summary: "Resolve a DID document; ?envelope=true returns a W3C resolution result instead. " +
+			"A fragment (%23key-1 in the path or ?fragment=key-1) or ?service= returns just that verification method or service",
+		query: []Parameter{
+			queryParam("envelope", "boolean", "Wrap the document in a DID resolution result"),
+			queryParam("fragment", "string", "Return only the verification method or service with this fragment"),
+			queryParam("service", "string", "Return only the service with this fragment; relativeRef is not supported"),
+		},
+		status:   http.StatusOK,
+		response: exampleDidDocument,
+		errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusBadGateway, http.StatusInternalServerError},
+	},
+	{
+		method: "GET", path: "/dids/{did}/update-nonce", id: "getDidUpdateNonce", tag: "dids",
+		summary:  "Issue the single-use nonce the proof of the next update or deactivation signs; unspent nonces are capped per DID",
+		status:   http.StatusOK,
+		response: handlers.UpdateNonceResponse{Did: exampleIssuer, Nonce: exampleNonce, ExpiresAt: exampleTime},
+		errors:   []int{http.StatusNotFound, http.StatusConflict, http.StatusTooManyRequests, http.StatusInternalServerError},
+	},
+	{
+		method: "PUT", path: "/dids/{did}", id: "updateDid", tag: "dids",
+		scope:    "dids:write",
+		summary:  "Replace a DID document; the proof signs update:<did>:<nonce>:<sha256 of the canonical body without proof>",
+		request:  handlers.UpdateDidRequest{CreateDidRequest: exampleCreateDid, Proof: exampleDidProof},
+		status:   http.StatusOK,
+		response: exampleDidDocument,
+		errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError},
+	},
+	{
+		method: "DELETE", path: "/dids/{did}", id: "deactivateDid", tag: "dids",
+		scope:    "dids:write",
+		summary:  "Deactivate a DID; the proof signs deactivate:<did>:<nonce>",
+		request:  handlers.DeactivateDidRequest{Proof: exampleDidProof},
+		status:   http.StatusOK,
+		response: deactivateDidResponse{Did: exampleIssuer, Status: "deactivated"},
+		errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError},
+	},
+
+	{
+		method: "GET", path: "/status-lists/{id}", id: "getStatusList", tag: "status-lists",
+		summary: "Get a StatusList2021 credential",
+		status:  http.StatusOK,
+		response: handlers.StatusListCredential{
+			Context:      []string{"https://www.w3.org/2018/credentials/v1", "https://w3id.org/vc/status-list/2021/v1"},
+			ID:           exampleList,
+			Type:         []string{"VerifiableCredential", "StatusList2021Credential"},
+			IssuanceDate: exampleTime,
+			CredentialSubject: handlers.StatusListSubject{
+				ID:            exampleList + "#list",
+				Type:          "StatusList2021",
+				StatusPurpose: "revocation",
+				EncodedList:   "H4sIAAAAAAAA_-zAMQEAAADCoPVPbQwfoAAAAAAAAAAAAAAAAAAAAIC3AYbSVKsAQAAA",
+			},
+		},
+		errors: []int{http.StatusNotFound, http.StatusInternalServerError},
+	},
+	{
+		method: "POST", path: "/status-lists/{id}/entries", id: "allocateStatusListEntry", tag: "status-lists",
+		summary: "Allocate the next index of a status list",
+		status:  http.StatusCreated,
+		response: handlers.StatusListEntry{
+			ID:                   exampleList + "#7",
+			Type:                 "StatusList2021Entry",
+			StatusPurpose:        "revocation",
+			StatusListIndex:      "7",
+			StatusListCredential: exampleList,
+		},
+		errors: []int{http.StatusUnauthorized, http.StatusConflict, http.StatusInternalServerError},
+		admin:  true,
+	},
+	{
+		method: "POST", path: "/status-lists/{id}/entries/{index}/revoke", id: "revokeStatusListEntry", tag: "status-lists",
+		summary:  "Set the bit of an allocated index",
+		status:   http.StatusOK,
+		response: handlers.StatusListRevokeResponse{StatusListIndex: "7", Revoked: true, AnchorHash: exampleRoot},
+		errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+		admin:    true,
+	},
+
+	{
+		method: "POST", path: "/webhooks", id: "createWebhook", tag: "webhooks", shared: true,
+		summary: "Register a webhook for anchor.created, anchor.revoked or did.created events",
+		request: handlers.CreateWebhookRequest{
+			URL:    "https://indexer.example/hooks/ledger",
+			Secret: "whsec-change-me",
+			Events: []string{domain.EventAnchorCreated},
+		},
+		status:   http.StatusCreated,
+		response: exampleWebhook,
+		errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError},
+		admin:    true,
+	},
+	{
+		method: "GET", path: "/webhooks", id: "listWebhooks", tag: "webhooks", shared: true,
+		summary:  "List webhook registrations",
+		status:   http.StatusOK,
+		response: handlers.WebhookListResponse{Items: []handlers.WebhookResponse{exampleWebhook}},
+		errors:   []int{http.StatusUnauthorized, http.StatusInternalServerError},
+		admin:    true,
+	},
+	{
+		method: "DELETE", path: "/webhooks/{id}", id: "deleteWebhook", tag: "webhooks", shared: true,
+		summary: "Remove a webhook registration",
+		status:  http.StatusNoContent,
+		errors:  []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+		admin:   true,
+	},
+}
+
+func mustJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+func queryParam(name, typ, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: typ}}
+}
+
+func headerParam(name, description string) Parameter {
+	return Parameter{Name: name, In: "header", Description: description, Schema: &Schema{Type: "string"}}
+}
+
+// readOnlyExempt are the routes taking a body that do not write, and so are
+// served in read-only mode.
+var readOnlyExempt = map[string]bool{
+	"/anchors/verify-batch":  true,
+	"/anchors/merkle-verify": true,
+	"/commitments/verify":    true,
+	"/admin/read-only":       true,
+}
+
+// versionPrefix is the path prefix of the API version the routes describe. Routes
+// tagged ops are served unversioned.
+const versionPrefix = "/v1"
+
+// Build returns the document for every route registered by api.NewRouter, leaving
+// out the deprecated unprefixed aliases of the versioned routes.
+func Build() *Document {
+	g := newSchemaGenerator()
+	errorSchema := g.schemaFor(handlers.ErrorResponse{})
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       "fabric-resolver",
+			Version:     "1.0.0",
+			Description: "Anchors document hashes and resolves DID documents on the ledger.",
+		},
+		Paths: make(map[string]*PathItem),
+		Components: Components{
+			SecuritySchemes: map[string]*SecurityScheme{
+				"adminToken": {Type: "http", Scheme: "bearer", Description: "ADMIN_TOKEN of the server"},
+				"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key",
+					Description: "One of the server's API_KEYS; required on writes when keys are configured, and on reads with API_KEYS_PROTECT_READS"},
+				"bearerToken": {Type: "http", Scheme: "bearer", BearerFormat: "JWT",
+					Description: "RS256 or ES256 token from the JWT_ISSUER identity provider when JWT_JWKS_URL is configured; the admin scope grants every other scope"},
+			},
+		},
+	}
+
+	// Every request may carry its own id; the response echoes it, or a generated one
+	requestIDParam := headerParam(requestid.Header, "Correlates the request with log lines and outbound calls; echoed in the response and error bodies, and generated when absent")
+	// Servers with TENANTS keep each tenant's records apart and require the tenant
+	tenantParam := headerParam(handlers.TenantHeader, "The tenant whose ledger serves the request, when the server has TENANTS; implied by an API key assigned to a tenant")
+
+	for _, rt := range routes {
+		op := &Operation{
+			OperationID: rt.id,
+			Summary:     rt.summary,
+			Tags:        []string{rt.tag},
+			Parameters:  append(append(append(pathParams(rt.path), rt.query...), rt.headers...), requestIDParam),
+			Responses:   make(map[string]*Response),
+		}
+		tenanted := (rt.tag != "ops" || rt.path == "/stats/basic") && !rt.shared
+		if tenanted {
+			op.Parameters = append(op.Parameters, tenantParam)
+		}
+		if rt.request != nil {
+			op.RequestBody = &RequestBody{Required: true, Content: jsonContent(g, rt.request)}
+		}
+
+		success := &Response{Description: http.StatusText(rt.status)}
+		switch {
+		case rt.response == nil:
+			// No content
+		case rt.contentType != "":
+			success.Content = map[string]*MediaType{rt.contentType: {Schema: &Schema{Type: "string"}, Example: rt.response}}
+		default:
+			success.Content = jsonContent(g, rt.response)
+		}
+		op.Responses[strconv.Itoa(rt.status)] = success
+
+		scope := rt.scope
+		if scope == "" && rt.admin {
+			scope = "admin"
+		}
+		statuses := slices.Clone(rt.errors)
+		if rt.request != nil && !slices.Contains(statuses, http.StatusRequestEntityTooLarge) {
+			statuses = append(statuses, http.StatusRequestEntityTooLarge)
+		}
+		if rt.method != http.MethodGet && !slices.Contains(statuses, http.StatusUnauthorized) {
+			statuses = append(statuses, http.StatusUnauthorized)
+		}
+		if tenanted && !slices.Contains(statuses, http.StatusBadRequest) {
+			statuses = append(statuses, http.StatusBadRequest)
+		}
+		if (scope != "" || tenanted) && !slices.Contains(statuses, http.StatusForbidden) {
+			statuses = append(statuses, http.StatusForbidden)
+		}
+		if rt.path != "/health" && rt.path != "/livez" && rt.path != "/readyz" && rt.path != "/metrics" {
+			statuses = append(statuses, http.StatusTooManyRequests)
+		}
+		if rt.method != http.MethodGet && !readOnlyExempt[rt.path] && !slices.Contains(statuses, http.StatusServiceUnavailable) {
+			statuses = append(statuses, http.StatusServiceUnavailable)
+		}
+		if rt.path != "/anchors/stream" && !slices.Contains(statuses, http.StatusGatewayTimeout) {
+			statuses = append(statuses, http.StatusGatewayTimeout)
+		}
+		for _, status := range statuses {
+			op.Responses[strconv.Itoa(status)] = &Response{
+				Description: http.StatusText(status),
+				Content: map[string]*MediaType{"application/json": {
+					Schema:  errorSchema,
+					Example: handlers.NewErrorResponse(status, http.StatusText(status)),
+				}},
+			}
+		}
+		op.Responses["default"] = &Response{
+			Description: "Unexpected error",
+			Content:     map[string]*MediaType{"application/json": {Schema: errorSchema}},
+		}
+		// Either credential in Authorization satisfies an admin route; writes also need an API key
+		requirement := func(scheme string, scopes []string) map[string][]string {
+			req := map[string][]string{scheme: scopes}
+			if rt.method != http.MethodGet {
+				req["apiKey"] = []string{}
+			}
+			return req
+		}
+		if rt.admin {
+			op.Security = append(op.Security, requirement("adminToken", []string{}))
+		}
+		if scope != "" {
+			op.Security = append(op.Security, requirement("bearerToken", []string{scope}))
+		}
+		if op.Security == nil && rt.method != http.MethodGet {
+			op.Security = []map[string][]string{{"apiKey": {}}}
+		}
+
+		path := rt.path
+		if rt.tag != "ops" {
+			path = versionPrefix + path
+		}
+		item := doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(rt.method)] = op
+	}
+
+	doc.Components.Schemas = g.components
+	return doc
+}
+
+func jsonContent(g *schemaGenerator, example interface{}) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: g.schemaFor(example), Example: example}}
+}
+
+// pathParams declares the {name} segments of path as required string parameters.
+func pathParams(path string) []Parameter {
+	var params []Parameter
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params = append(params, Parameter{
+				Name:     strings.Trim(segment, "{}"),
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+	}
+	return params
+}
+
+// Operations returns "METHOD path" for every documented operation, sorted.
+func (d *Document) Operations() []string {
+	var ops []string
+	for path, item := range d.Paths {
+		for method := range *item {
+			ops = append(ops, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(ops)
+	return ops
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// gzipMagic starts every profile in the default (protobuf) format.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+func getHeapProfile(handler http.Handler, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/debug/pprof/heap", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPprof_NotFoundWhenDisabled(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{AdminToken: "s3cret"})
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, rec.Code)
+		}
+	}
+	if rec := getHeapProfile(router, "s3cret"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 even for admins, got %d", rec.Code)
+	}
+}
+
+func TestPprof_ServesHeapProfileToAdmins(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{AdminToken: "s3cret", Pprof: true})
+
+	if rec := getHeapProfile(router, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without the admin token, got %d", rec.Code)
+	}
+	rec := getHeapProfile(router, "s3cret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !bytes.HasPrefix(rec.Body.Bytes(), gzipMagic) {
+		t.Errorf("expected a gzipped heap profile, got %q", rec.Body.Bytes()[:min(rec.Body.Len(), 32)])
+	}
+}
+
+func TestPprofHandler_ServesHeapProfile(t *testing.T) {
+	rec := getHeapProfile(PprofHandler(), "")
+	if rec.Code != http.StatusOK || !bytes.HasPrefix(rec.Body.Bytes(), gzipMagic) {
+		t.Errorf("expected a heap profile from the dedicated listener's handler, got %d", rec.Code)
+	}
+}
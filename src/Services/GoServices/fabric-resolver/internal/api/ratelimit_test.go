@@ -0,0 +1,160 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/apikeys"
+	"fabric-resolver/internal/ratelimit"
+)
+
+func serveFrom(router http.Handler, method, path, remoteAddr, apiKey, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	if apiKey != "" {
+		req.Header.Set(APIKeyHeader, apiKey)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimit_BurstThenRecovery(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{
+		RateLimitWrites: ratelimit.New(ratelimit.Limit{Rate: 20, Burst: 3}, 0),
+	})
+	anchor := func(i int) string { return fmt.Sprintf(`{"hash":"%064x"}`, i) }
+
+	for i := range 3 {
+		if rec := serveFrom(router, "POST", "/anchors", "192.0.2.1:1000", "", anchor(i)); rec.Code != http.StatusCreated {
+			t.Fatalf("request %d within the burst: expected 201, got %d", i+1, rec.Code)
+		}
+	}
+
+	rec := serveFrom(router, "POST", "/anchors", "192.0.2.1:1001", "", anchor(3))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 after the burst, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("expected Retry-After rounded up to 1 second, got %q", got)
+	}
+	if code := errorCode(t, rec); code != "rate_limited" {
+		t.Errorf("expected code rate_limited, got %q", code)
+	}
+
+	// Reads are a separate, here unlimited, class, and other clients have their own bucket
+	if rec := serveFrom(router, "GET", "/anchors", "192.0.2.1:1002", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected reads to be unaffected, got %d", rec.Code)
+	}
+	if rec := serveFrom(router, "POST", "/anchors", "198.51.100.7:1000", "", anchor(4)); rec.Code != http.StatusCreated {
+		t.Errorf("expected another client to be unaffected, got %d", rec.Code)
+	}
+
+	// One token refills every 50ms at 20/s
+	time.Sleep(60 * time.Millisecond)
+	if rec := serveFrom(router, "POST", "/anchors", "192.0.2.1:1003", "", anchor(5)); rec.Code != http.StatusCreated {
+		t.Errorf("expected the client to recover after the window, got %d", rec.Code)
+	}
+}
+
+func TestRateLimit_KeyedByAPIKey(t *testing.T) {
+	keys, err := apikeys.NewKeys(map[string]string{"issuer-a": testAPIKey, "issuer-b": testAPIKey + "-b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router, _ := newTestRouter(t, RouterOptions{
+		APIKeys:         keys,
+		RateLimitWrites: ratelimit.New(ratelimit.Limit{Rate: 0.001, Burst: 1}, 0),
+	})
+	anchor := func(i int) string { return fmt.Sprintf(`{"hash":"%064x"}`, i) }
+
+	if rec := serveFrom(router, "POST", "/anchors", "192.0.2.1:1000", testAPIKey, anchor(1)); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	// The same key from another address shares the bucket
+	if rec := serveFrom(router, "POST", "/anchors", "198.51.100.7:1000", testAPIKey, anchor(2)); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the key's bucket to be exhausted, got %d", rec.Code)
+	}
+	// Another key from the same address does not
+	if rec := serveFrom(router, "POST", "/anchors", "192.0.2.1:1000", testAPIKey+"-b", anchor(3)); rec.Code != http.StatusCreated {
+		t.Errorf("expected another key to have its own bucket, got %d", rec.Code)
+	}
+}
+
+func TestRateLimit_ProbesAndStats(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{
+		AdminToken:     "s3cret",
+		RateLimitReads: ratelimit.New(ratelimit.Limit{Rate: 0.001, Burst: 2}, 0),
+	})
+	getStats := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/stats", nil)
+		req.RemoteAddr = "192.0.2.1:1000"
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for range 5 {
+		if rec := serveFrom(router, "GET", "/health", "192.0.2.1:1000", "", ""); rec.Code != http.StatusOK {
+			t.Fatalf("expected /health to be exempt, got %d", rec.Code)
+		}
+	}
+
+	rec := getStats()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var stats statsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	reads, ok := stats.RateLimits["reads"]
+	if !ok || reads.Clients != 1 || reads.Allowed != 1 || reads.Burst != 2 {
+		t.Errorf("expected the read limiter in /stats, got %+v", stats.RateLimits)
+	}
+	if _, ok := stats.RateLimits["writes"]; ok {
+		t.Error("expected no stats for the unlimited write class")
+	}
+
+	getStats()
+	if rec := getStats(); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the third read to be limited, got %d", rec.Code)
+	}
+}
+
+func TestRateLimit_FailedAuthByIP(t *testing.T) {
+	keys, err := apikeys.NewKeys(map[string]string{"issuer-a": testAPIKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router, _ := newTestRouter(t, RouterOptions{
+		APIKeys:         keys,
+		RateLimitWrites: ratelimit.New(ratelimit.Limit{Rate: 0.001, Burst: 2}, 0),
+	})
+	anchor := func(i int) string { return fmt.Sprintf(`{"hash":"%064x"}`, i) }
+
+	// Authenticated requests take nothing from the bucket of their IP
+	if rec := serveFrom(router, "POST", "/anchors", "192.0.2.1:1000", testAPIKey, anchor(1)); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	for i := range 2 {
+		if rec := serveFrom(router, "POST", "/anchors", "192.0.2.1:1000", "wrong-key-0123456789", anchor(2)); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("failed attempt %d: expected 401, got %d", i+1, rec.Code)
+		}
+	}
+
+	// Further attempts from that IP are refused before the key is checked
+	rec := serveFrom(router, "POST", "/anchors", "192.0.2.1:1001", "wrong-key-0123456789", anchor(2))
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 429 with Retry-After after the failed attempts, got %d", rec.Code)
+	}
+	if rec := serveFrom(router, "POST", "/anchors", "198.51.100.7:1000", "", anchor(2)); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected another IP to be unaffected, got %d", rec.Code)
+	}
+}
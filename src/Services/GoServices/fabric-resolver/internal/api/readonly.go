@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/requestid"
+
+	"github.com/gorilla/mux"
+)
+
+// DefaultReadOnlyRetryAfter is the Retry-After of writes refused in read-only mode.
+const DefaultReadOnlyRetryAfter = time.Minute
+
+// queryRoutes are the routes taking POST that only read: queries with a body.
+var queryRoutes = map[string]bool{
+	"/anchors/verify-batch":  true,
+	"/anchors/merkle-verify": true,
+	"/commitments/verify":    true,
+}
+
+// readOnlyExempt reports whether a write to the route is served in read-only mode:
+// queries, and the switch itself. Every other write is refused, including routes
+// added later.
+func readOnlyExempt(route string) bool {
+	return queryRoutes[route] || route == "/admin/read-only"
+}
+
+// readOnlyGuard answers write requests with 503 and a read_only code while mode is
+// on, telling clients to retry after retryAfter. Reads are served as usual.
+func readOnlyGuard(mode *fabric.ReadOnlySwitch, retryAfter time.Duration) mux.MiddlewareFunc {
+	if retryAfter <= 0 {
+		retryAfter = DefaultReadOnlyRetryAfter
+	}
+	seconds := strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if mode.On() && isWrite(r.Method) && !readOnlyExempt(unversioned(routeTemplate(r))) {
+				w.Header().Set("Retry-After", seconds)
+				writeErrorCode(w, http.StatusServiceUnavailable, handlers.CodeReadOnly, "The ledger is read-only for maintenance")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// readOnlyState is the body of /admin/read-only, both ways.
+type readOnlyState struct {
+	ReadOnly *bool `json:"readOnly"`
+}
+
+// readOnlyHandler reports read-only mode on GET and switches it on PUT. Every
+// switch is logged with the caller.
+func readOnlyHandler(mode *fabric.ReadOnlySwitch, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			var req readOnlyState
+			dec := json.NewDecoder(r.Body)
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&req); err != nil || req.ReadOnly == nil {
+				writeError(w, http.StatusBadRequest, `Expected {"readOnly": true} or {"readOnly": false}`)
+				return
+			}
+			if mode.On() != *req.ReadOnly {
+				mode.Set(*req.ReadOnly)
+				logger.Warn("Read-only mode switched", "read_only", *req.ReadOnly,
+					"client", handlers.ClientSubject(r.Context()), "request_id", requestid.FromContext(r.Context()))
+			}
+		}
+
+		on := mode.On()
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(readOnlyState{ReadOnly: &on}); err != nil {
+			slog.Error("Failed to encode read-only response", "err", err)
+		}
+	}
+}
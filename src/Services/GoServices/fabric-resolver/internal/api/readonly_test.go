@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/infrastructure/fabric"
+
+	"github.com/gorilla/mux"
+)
+
+func serveAdmin(router http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// pathVariable matches the {name} and {name:regexp} segments of route templates.
+var pathVariable = regexp.MustCompile(`\{[^/]+?\}`)
+
+func TestReadOnly_RefusesEveryWriteRoute(t *testing.T) {
+	ledger, err := fabric.NewFileLedgerClient(t.TempDir() + "/ledger.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ledger.Close()
+	router := NewRouter(ledger, RouterOptions{AdminToken: "s3cret", ReadOnly: fabric.NewReadOnlySwitch(true)})
+
+	writes := 0
+	err = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil || readOnlyExempt(unversioned(template)) {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		for _, method := range methods {
+			if !isWrite(method) {
+				continue
+			}
+			writes++
+			path := pathVariable.ReplaceAllString(template, "x")
+			rec := serveAdmin(router, method, path, `{}`)
+			var body handlers.ErrorResponse
+			json.Unmarshal(rec.Body.Bytes(), &body)
+			if rec.Code != http.StatusServiceUnavailable || body.Code != handlers.CodeReadOnly || rec.Header().Get("Retry-After") != "60" {
+				t.Errorf("%s %s: expected 503 read_only with Retry-After 60, got %d %s %q", method, template, rec.Code, body.Code, rec.Header().Get("Retry-After"))
+			}
+		}
+		return nil
+	})
+	if err != nil || writes == 0 {
+		t.Fatalf("expected write routes to check, walked %d (%v)", writes, err)
+	}
+}
+
+func TestReadOnly_ServesReadsAndSwitches(t *testing.T) {
+	mode := fabric.NewReadOnlySwitch(true)
+	router, _ := newTestRouter(t, RouterOptions{AdminToken: "s3cret", ReadOnly: mode, ReadOnlyRetryAfter: 90 * time.Second})
+	hash := strings.Repeat("ab", 32)
+
+	for _, path := range []string{"/v1/anchors", "/v1/dids", "/anchors", "/stats/basic", "/readyz"} {
+		if rec := serveAdmin(router, "GET", path, ""); rec.Code != http.StatusOK {
+			t.Errorf("GET %s: expected reads to be served, got %d", path, rec.Code)
+		}
+	}
+	// Queries with a body do not write
+	if rec := serveAdmin(router, "POST", "/v1/anchors/verify-batch", `{"hashes":["`+hash+`"]}`); rec.Code != http.StatusOK {
+		t.Errorf("expected verify-batch to be served, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec := serveAdmin(router, "POST", "/v1/anchors", `{"hash":"`+hash+`"}`)
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "90" {
+		t.Fatalf("expected the write to be refused with Retry-After 90, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	// Only admins switch the mode
+	if rec := serveWithKey(router, "PUT", "/admin/read-only", "", `{"readOnly":false}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected the switch to need the admin token, got %d", rec.Code)
+	}
+	if rec := serveAdmin(router, "PUT", "/admin/read-only", `{"enabled":false}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected a body without readOnly to be rejected, got %d", rec.Code)
+	}
+	if rec := serveAdmin(router, "PUT", "/admin/read-only", `{"readOnly":false}`); rec.Code != http.StatusOK || mode.On() {
+		t.Fatalf("expected read-only mode to be switched off, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveAdmin(router, "GET", "/admin/read-only", ""); strings.TrimSpace(rec.Body.String()) != `{"readOnly":false}` {
+		t.Errorf("expected the mode to be reported, got %s", rec.Body.String())
+	}
+	if rec := serveAdmin(router, "POST", "/v1/anchors", `{"hash":"`+hash+`"}`); rec.Code != http.StatusCreated {
+		t.Errorf("expected writes once read-only mode is off, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/requestid"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// panicsTotal counts handler panics caught by recoverMiddleware.
+var panicsTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "fabric_resolver_http_panics_total",
+	Help: "HTTP handler panics recovered with a 500 response or an aborted connection.",
+})
+
+// recoverMiddleware turns a panic in a handler, or in middleware after it, into a
+// logged stack trace and a JSON 500, so one bad request neither drops its
+// connection silently nor goes unnoticed. When the response has already started
+// the connection is aborted instead, since a 500 can no longer be sent.
+// http.ErrAbortHandler is passed through: it is how handlers abort on purpose.
+func recoverMiddleware(logger *slog.Logger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &recoveryWriter{ResponseWriter: w}
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				panicsTotal.Inc()
+				// The request id is set on the response by requestIDMiddleware, which runs inside this one
+				logger.Error("Recovered from handler panic",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"request_id", w.Header().Get(requestid.Header),
+					"panic", fmt.Sprint(rec),
+					"stack", string(debug.Stack()))
+				if rw.started {
+					panic(http.ErrAbortHandler)
+				}
+				writeErrorCode(w, http.StatusInternalServerError, handlers.CodeInternal, "Internal server error")
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// recoveryWriter records whether the response has started.
+type recoveryWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *recoveryWriter) WriteHeader(status int) {
+	w.started = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recoveryWriter) Write(b []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(b)
+}
+
+// FlushError is used by http.ResponseController to flush streams; flushing starts the response.
+func (w *recoveryWriter) FlushError() error {
+	w.started = true
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer for its other controls.
+func (w *recoveryWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// newPanicServer serves the router with routes that panic in different ways.
+func newPanicServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	handler, _ := newTestRouter(t, RouterOptions{})
+	router := handler.(*mux.Router)
+	router.HandleFunc("/test/panic", func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+	router.HandleFunc("/test/panic-after-write", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, "partial")
+		panic("boom after write")
+	})
+	router.HandleFunc("/test/abort", func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// panicCount scrapes the panic counter from /metrics.
+func panicCount(t *testing.T, srv *httptest.Server) int {
+	t.Helper()
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+	defer resp.Body.Close()
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "fabric_resolver_http_panics_total "); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				t.Fatalf("unexpected counter value %q", value)
+			}
+			return n
+		}
+	}
+	t.Fatal("panic counter not exported")
+	return 0
+}
+
+func TestRecover_RespondsWithJSON500(t *testing.T) {
+	srv := newPanicServer(t)
+	before := panicCount(t, srv)
+
+	resp, err := http.Get(srv.URL + "/test/panic")
+	if err != nil {
+		t.Fatalf("expected a response instead of a dropped connection, got %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected a JSON body, got %q", ct)
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if body["code"] != "internal_error" || body["error"] != "Internal server error" || body["message"] != "Internal server error" {
+		t.Errorf("unexpected body %v", body)
+	}
+	if strings.Contains(body["error"].(string), "boom") {
+		t.Error("expected the panic value to stay out of the response")
+	}
+
+	if got := panicCount(t, srv); got != before+1 {
+		t.Errorf("expected the panic counter to go from %d to %d, got %d", before, before+1, got)
+	}
+
+	// The server keeps serving
+	for range 3 {
+		resp, err := http.Get(srv.URL + "/health")
+		if err != nil {
+			t.Fatalf("expected the server to keep serving, got %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("expected 200, got %d", resp.StatusCode)
+		}
+	}
+}
+
+func TestRecover_AbortsStartedResponses(t *testing.T) {
+	srv := newPanicServer(t)
+	before := panicCount(t, srv)
+
+	// Headers are out, so the connection is cut rather than the 200 completing normally.
+	// POST, as the client would retry a GET on the cut connection.
+	resp, err := http.Post(srv.URL+"/test/panic-after-write", "application/json", nil)
+	if err == nil {
+		_, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	if err == nil {
+		t.Error("expected the started response to be aborted")
+	}
+	if got := panicCount(t, srv); got != before+1 {
+		t.Errorf("expected the panic to be counted, got %d after %d", got, before)
+	}
+}
+
+func TestRecover_PassesErrAbortHandlerThrough(t *testing.T) {
+	srv := newPanicServer(t)
+	before := panicCount(t, srv)
+
+	if resp, err := http.Get(srv.URL + "/test/abort"); err == nil {
+		resp.Body.Close()
+		t.Errorf("expected the connection to be aborted, got %d", resp.StatusCode)
+	}
+	if got := panicCount(t, srv); got != before {
+		t.Errorf("expected a deliberate abort not to be counted, got %d after %d", got, before)
+	}
+}
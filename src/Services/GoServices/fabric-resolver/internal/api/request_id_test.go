@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/requestid"
+)
+
+var uuidV4 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func serveWithRequestID(router http.Handler, method, path, id, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func errorRequestID(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		RequestID string `json:"requestId"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected a JSON error body, got %q", rec.Body.String())
+	}
+	return body.RequestID
+}
+
+func TestRequestID_EchoesProvidedID(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{})
+
+	rec := serveWithRequestID(router, "GET", "/health", "client-req-1", "")
+	if got := rec.Header().Get(requestid.Header); got != "client-req-1" {
+		t.Errorf("expected the provided id to be echoed, got %q", got)
+	}
+}
+
+func TestRequestID_GeneratesMissingOrInvalidID(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{})
+
+	first := serveWithRequestID(router, "GET", "/health", "", "").Header().Get(requestid.Header)
+	second := serveWithRequestID(router, "GET", "/health", "", "").Header().Get(requestid.Header)
+	if !uuidV4.MatchString(first) || !uuidV4.MatchString(second) {
+		t.Fatalf("expected generated UUIDv4 ids, got %q and %q", first, second)
+	}
+	if first == second {
+		t.Error("expected a fresh id per request")
+	}
+
+	// Ids that cannot be logged verbatim are replaced
+	for _, id := range []string{`with "quotes"`, strings.Repeat("x", 129)} {
+		if got := serveWithRequestID(router, "GET", "/health", id, "").Header().Get(requestid.Header); !uuidV4.MatchString(got) {
+			t.Errorf("expected %q to be replaced, got %q", id, got)
+		}
+	}
+}
+
+func TestRequestID_InErrorBodies(t *testing.T) {
+	router := newAPIKeyRouter(t, false)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"handler error", "GET", "/anchors/" + strings.Repeat("ab", 32), "", http.StatusNotFound},
+		{"middleware error", "POST", "/anchors", `{"hash":"` + strings.Repeat("ab", 32) + `"}`, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		rec := serveWithRequestID(router, tt.method, tt.path, "req-"+tt.method, tt.body)
+		if rec.Code != tt.status {
+			t.Fatalf("%s: expected %d, got %d", tt.name, tt.status, rec.Code)
+		}
+		if got := errorRequestID(t, rec); got != "req-"+tt.method {
+			t.Errorf("%s: expected the request id in the body, got %q", tt.name, got)
+		}
+	}
+
+	// Validation errors and generated ids too
+	router, _ = newTestRouter(t, RouterOptions{})
+	rec := serveWithRequestID(router, "POST", "/anchors", "", `{"hash":"nothex"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	if got := errorRequestID(t, rec); got == "" || got != rec.Header().Get(requestid.Header) {
+		t.Errorf("expected the generated id in the body, got %q", got)
+	}
+}
+
+func TestRequestID_InAccessLog(t *testing.T) {
+	var logs accessLogs
+	router, _ := newTestRouter(t, RouterOptions{Logger: logs.logger()})
+
+	serveWithRequestID(router, "GET", "/health", "req-logged", "")
+	if records := logs.requests(t); len(records) != 1 || records[0]["request_id"] != "req-logged" {
+		t.Errorf("expected the access log to carry the request id, got %v", records)
+	}
+}
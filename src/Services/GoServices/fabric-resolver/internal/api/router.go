@@ -1,102 +1,534 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/api/openapi"
+	"fabric-resolver/internal/apikeys"
+	"fabric-resolver/internal/audit"
+	"fabric-resolver/internal/buildinfo"
+	"fabric-resolver/internal/commitments"
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/health"
+	"fabric-resolver/internal/idempotency"
 	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/jwtauth"
+	"fabric-resolver/internal/pkg/didweb"
+	"fabric-resolver/internal/pkg/receipt"
+	"fabric-resolver/internal/ratelimit"
+	"fabric-resolver/internal/requestid"
+	"fabric-resolver/internal/webhooks"
 
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// RouterOptions holds the settings that change how routes are served.
+type RouterOptions struct {
+	// AdminToken is the bearer token required by administrative endpoints.
+	// Empty disables those endpoints.
+	AdminToken string
+
+	// APIKeys authorizes write requests with the X-API-Key header. Empty leaves the API open.
+	APIKeys *apikeys.Keys
+
+	// APIKeysProtectReads requires an API key on reads as well; /health stays open.
+	APIKeysProtectReads bool
+
+	// CORS configures which browser origins may call the API. Zero sends no CORS headers.
+	CORS CORSOptions
+
+	// RateLimitReads and RateLimitWrites limit GET and POST/PUT/DELETE requests per client
+	// (API key, token subject or IP). Nil leaves that class unlimited.
+	RateLimitReads  *ratelimit.Limiter
+	RateLimitWrites *ratelimit.Limiter
+
+	// JWT verifies bearer tokens, and write and admin routes then require the scope of
+	// their group. Nil leaves routes unscoped.
+	JWT *jwtauth.Verifier
+
+	// DIDMethods is the allow-list of DID methods accepted by POST /dids.
+	// Empty uses domain.DefaultDIDMethods.
+	DIDMethods []string
+
+	// DIDMaxVerificationMethods caps the verification methods of a created or updated DID document.
+	// Zero uses domain.DefaultMaxVerificationMethods.
+	DIDMaxVerificationMethods int
+
+	// AnchorMetadataMaxBytes caps the canonical size of anchor metadata.
+	// Zero uses handlers.DefaultMaxMetadataBytes.
+	AnchorMetadataMaxBytes int
+
+	// AnchorVerifyBatchMax caps the hashes of one verify-batch request.
+	// Zero uses handlers.DefaultMaxVerifyBatch.
+	AnchorVerifyBatchMax int
+
+	// AnchorDocumentMaxBytes caps the body of POST /anchors/from-document.
+	// Zero uses handlers.DefaultMaxDocumentBytes.
+	AnchorDocumentMaxBytes int
+
+	// DIDUpdateNonceTTL is how long a nonce from GET /dids/{did}/update-nonce is accepted.
+	// Zero uses handlers.DefaultUpdateNonceTTL.
+	DIDUpdateNonceTTL time.Duration
+
+	// DIDWebResolver resolves did:web DIDs that are not on the ledger. Nil disables outbound resolution.
+	DIDWebResolver *didweb.Resolver
+
+	// Webhooks is notified of DID creations and anchor revocations. Nil sends no notifications.
+	Webhooks *webhooks.Dispatcher
+
+	// ReceiptSigner signs anchor receipts. Nil disables the receipt endpoints.
+	ReceiptSigner *receipt.Signer
+
+	// CommitmentKeys holds the tenant keys of POST /commitments. Nil treats every tenant as unknown.
+	CommitmentKeys *commitments.Keyring
+
+	// Tenants serves the API and /stats/basic from a ledger per tenant, named by the
+	// X-Tenant-ID header or by TenantAPIKeys; webhooks, probes and /stats keep the
+	// ledger passed to NewRouter. Nil serves every request from that ledger.
+	Tenants *fabric.TenantLedgerProvider
+
+	// TenantAPIKeys maps API key ids to the tenant their requests are held to, and
+	// whose commitment key they use; without Tenants it binds commitments only.
+	TenantAPIKeys map[string]string
+
+	// ReadOnly refuses writes with 503 while on, and is switched by admins at
+	// /admin/read-only; pass the switch guarding the ledger clients too. Nil uses
+	// a switch of the router's own.
+	ReadOnly *fabric.ReadOnlySwitch
+
+	// ReadOnlyRetryAfter is the Retry-After of writes refused in read-only mode.
+	// Zero uses DefaultReadOnlyRetryAfter.
+	ReadOnlyRetryAfter time.Duration
+
+	// Audit records every write request. Nil records none.
+	Audit *audit.Log
+
+	// AuditStrict answers writes whose audit entry cannot be appended with 503.
+	AuditStrict bool
+
+	// Idempotency stores responses to POST /anchors requests with an Idempotency-Key.
+	// Nil ignores the header.
+	Idempotency *idempotency.Store
+
+	// Logger receives the access log and recovered panics. Nil uses slog.Default().
+	Logger *slog.Logger
+
+	// AccessLog leaves successful requests to probe paths out of the access log.
+	// Zero logs every request.
+	AccessLog AccessLogOptions
+
+	// BodyLimits caps request bodies. Zero values use DefaultMaxBodyBytes and
+	// DefaultMaxBatchBodyBytes.
+	BodyLimits BodyLimits
+
+	// StrictJSON rejects anchor and DID request bodies with fields the endpoint does not define.
+	StrictJSON bool
+
+	// Timeouts bounds how long handlers may take before the request is answered with 504.
+	// Zero values use DefaultRequestTimeout.
+	Timeouts Timeouts
+
+	// Pprof serves the runtime profiles under /debug/pprof/ to admins. Leave it off
+	// when pprof has a listener of its own.
+	Pprof bool
+}
+
 // NewRouter creates and configures the HTTP router
-func NewRouter(ledgerClient fabric.LedgerClient) *mux.Router {
-	r := mux.NewRouter()
+func NewRouter(ledgerClient fabric.LedgerClient, opts RouterOptions) *mux.Router {
+	// Match on the escaped path so percent-encodings inside DIDs reach the handlers intact
+	r := mux.NewRouter().UseEncodedPath()
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
 
 	// Middleware
-	r.Use(loggingMiddleware)
-	r.Use(corsMiddleware)
+	r.Use(recoverMiddleware(logger))
+	r.Use(versionMiddleware)
+	r.Use(requestIDMiddleware)
+	r.Use(tracingMiddleware)
+	r.Use(metricsMiddleware)
+	r.Use(clientCertMiddleware)
+	r.Use(loggingMiddleware(logger, opts.AccessLog))
+	r.Use(auditMiddleware(opts.Audit, opts.AuditStrict, logger))
+	r.Use(corsMiddleware(opts.CORS))
+	bodyLimits := opts.BodyLimits.withDefaults()
+	r.Use(bodyLimit(bodyLimits.routes(opts.AnchorDocumentMaxBytes), bodyLimits.Default))
+	timeouts := opts.Timeouts.withDefaults()
+	r.Use(requestTimeout(timeouts.routes(), timeouts.Default))
+	if opts.RateLimitReads != nil || opts.RateLimitWrites != nil {
+		r.Use(authFailureLimit(opts.RateLimitReads, opts.RateLimitWrites))
+	}
+	if opts.APIKeys.Len() > 0 {
+		r.Use(apiKeyAuth(opts.APIKeys, opts.APIKeysProtectReads))
+	}
+	if opts.JWT != nil {
+		r.Use(bearerAuth(opts.JWT))
+	}
+	if opts.RateLimitReads != nil || opts.RateLimitWrites != nil {
+		r.Use(rateLimit(opts.RateLimitReads, opts.RateLimitWrites))
+	}
+	readOnly := opts.ReadOnly
+	if readOnly == nil {
+		readOnly = &fabric.ReadOnlySwitch{}
+	}
+	r.Use(readOnlyGuard(readOnly, opts.ReadOnlyRetryAfter))
+
+	// Preflight requests of any path, answered by corsMiddleware. Matched on the method
+	// alone, as a Methods matcher would turn every unknown path into a 405.
+	r.PathPrefix("/").MatcherFunc(isOptions).HandlerFunc(preflightHandler)
 
-	// Health check
-	r.HandleFunc("/health", healthHandler).Methods("GET")
+	// Probes: liveness only needs the process, readiness a working ledger.
+	// /health predates them and answers readiness.
+	r.HandleFunc("/livez", livenessHandler).Methods("GET")
+	r.HandleFunc("/readyz", readinessHandler(ledgerClient, logger)).Methods("GET")
+	r.HandleFunc("/health", readinessHandler(ledgerClient, logger)).Methods("GET")
 
-	// Stats endpoint for debugging
-	r.HandleFunc("/stats", statsHandler(ledgerClient)).Methods("GET")
+	// The build of the binary, which is also in the probes and /stats
+	r.HandleFunc("/version", versionHandler).Methods("GET")
 
-	// Anchor handlers
-	anchorHandler := handlers.NewAnchorHandler(ledgerClient)
-	r.HandleFunc("/anchors", anchorHandler.CreateAnchor).Methods("POST")
-	r.HandleFunc("/anchors/{hash}", anchorHandler.GetAnchor).Methods("GET")
-	r.HandleFunc("/anchors/{hash}/verify", anchorHandler.VerifyAnchor).Methods("GET")
+	// Stats for operators; the record counts alone are public
+	r.Handle("/stats", adminAuth(opts.AdminToken, statsHandler(ledgerClient, readOnly, opts))).Methods("GET")
+	tenants := tenancy{ledgers: opts.Tenants, apiKeys: opts.TenantAPIKeys, logger: logger}
+	r.Handle("/stats/basic", tenants.scoped(basicStatsHandler(ledgerClient))).Methods("GET")
 
-	// DID handlers
-	didHandler := handlers.NewDidHandler(ledgerClient)
-	r.HandleFunc("/dids", didHandler.CreateDid).Methods("POST")
-	r.HandleFunc("/dids/{did:.*}", didHandler.ResolveDid).Methods("GET")
+	// Read-only mode, for ledger migrations: reads are served and writes refused
+	r.Handle("/admin/read-only", adminAuth(opts.AdminToken, readOnlyHandler(readOnly, logger))).Methods("GET", "PUT")
+
+	// The audit log of writes
+	r.Handle("/admin/audit", adminAuth(opts.AdminToken, http.HandlerFunc(handlers.NewAuditHandler(opts.Audit).ListEntries))).Methods("GET")
+
+	// The API, under /v1. The unprefixed paths predate versioning and are kept as
+	// deprecated aliases until clients have moved.
+	v1 := apiVersion{prefix: apiV1, routes: v1Routes(ledgerClient, opts, tenants)}
+	v1.mount(r)
+	v1.mountLegacy(r, logger)
 
 	// Metrics
 	r.Handle("/metrics", promhttp.Handler()).Methods("GET")
 
+	// Profiles of the running process, for admins only
+	if opts.Pprof {
+		r.PathPrefix("/debug/pprof/").Handler(adminAuth(opts.AdminToken, PprofHandler()))
+	}
+
+	// API description; add new routes to openapi/routes.go as well
+	r.HandleFunc("/openapi.json", openapi.Handler()).Methods("GET")
+	r.HandleFunc("/docs", openapi.DocsHandler).Methods("GET")
+
 	return r
 }
 
-func loggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		next.ServeHTTP(w, r)
-		log.Printf(
-			"%s %s %s %v",
-			r.Method,
-			r.RequestURI,
-			r.RemoteAddr,
-			time.Since(start),
-		)
-	})
+// sharedRoutes are the paths of version 1 served alike to every tenant.
+var sharedRoutes = map[string]bool{
+	"/receipts/public-key": true,
+	"/webhooks":            true,
+	"/webhooks/{id}":       true,
 }
 
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Configure CORS properly for production
-		// For now using wildcard for development
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+// v1Routes returns the operations of version 1 of the API, in the order they are
+// matched, each served from the ledger of its tenant unless shared.
+func v1Routes(ledgerClient fabric.LedgerClient, opts RouterOptions, tenants tenancy) []apiRoute {
+	scoped := func(scope string, h http.HandlerFunc) http.Handler {
+		if opts.JWT == nil {
+			return h
 		}
+		return requireScope(scope, h)
+	}
 
-		next.ServeHTTP(w, r)
+	anchorHandler := handlers.NewAnchorHandler(ledgerClient, handlers.AnchorHandlerOptions{
+		MaxMetadataBytes: opts.AnchorMetadataMaxBytes,
+		MaxVerifyBatch:   opts.AnchorVerifyBatchMax,
+		MaxDocumentBytes: opts.AnchorDocumentMaxBytes,
+		Webhooks:         opts.Webhooks,
+		Idempotency:      opts.Idempotency,
+		StrictJSON:       opts.StrictJSON,
+	})
+	receiptHandler := handlers.NewReceiptHandler(ledgerClient, opts.ReceiptSigner)
+	commitmentHandler := handlers.NewCommitmentHandler(ledgerClient, opts.CommitmentKeys, opts.TenantAPIKeys)
+	didHandler := handlers.NewDidHandler(ledgerClient, handlers.DidHandlerOptions{
+		Validator:      domain.NewDIDValidator(opts.DIDMethods).WithMaxVerificationMethods(opts.DIDMaxVerificationMethods),
+		WebResolver:    opts.DIDWebResolver,
+		Webhooks:       opts.Webhooks,
+		UpdateNonceTTL: opts.DIDUpdateNonceTTL,
+		StrictJSON:     opts.StrictJSON,
 	})
+	statusListHandler := handlers.NewStatusListHandler(ledgerClient)
+	webhookHandler := handlers.NewWebhookHandler(ledgerClient)
+
+	routes := []apiRoute{
+		// Anchor handlers
+		{"POST", "/anchors", scoped(ScopeAnchorsWrite, anchorHandler.CreateAnchor)},
+		{"GET", "/anchors", http.HandlerFunc(anchorHandler.ListAnchors)},
+		{"POST", "/anchors/batch", scoped(ScopeAnchorsWrite, anchorHandler.CreateAnchorsBatch)},
+		{"POST", "/anchors/from-document", scoped(ScopeAnchorsWrite, anchorHandler.CreateAnchorFromDocument)},
+		{"POST", "/anchors/verify-batch", http.HandlerFunc(anchorHandler.VerifyAnchorsBatch)},
+		{"POST", "/anchors/merkle-batch", scoped(ScopeAnchorsWrite, anchorHandler.CreateMerkleBatch)},
+		{"POST", "/anchors/merkle-verify", http.HandlerFunc(anchorHandler.VerifyMerkleProof)},
+		{"GET", "/anchors/stream", adminAuth(opts.AdminToken, http.HandlerFunc(anchorHandler.StreamAnchors))},
+		{"GET", "/anchors/{hash}", http.HandlerFunc(anchorHandler.GetAnchor)},
+		{"GET", "/anchors/{hash}/verify", http.HandlerFunc(anchorHandler.VerifyAnchor)},
+		{"POST", "/anchors/{hash}/revoke", scoped(ScopeAnchorsWrite, adminOrIssuerAuth(opts.AdminToken, http.HandlerFunc(anchorHandler.RevokeAnchor)).ServeHTTP)},
+		{"DELETE", "/anchors/{hash}", adminAuth(opts.AdminToken, http.HandlerFunc(anchorHandler.TombstoneAnchor))},
+
+		// Receipts are signed statements of what the ledger held, verifiable offline
+		{"GET", "/anchors/{hash}/receipt", http.HandlerFunc(receiptHandler.GetReceipt)},
+		{"GET", "/receipts/public-key", http.HandlerFunc(receiptHandler.GetPublicKey)},
+
+		{"GET", "/issuers/{did}/anchors", http.HandlerFunc(anchorHandler.ListAnchorsByIssuer)},
+		{"GET", "/transactions/{txId}/anchor", http.HandlerFunc(anchorHandler.GetAnchorByTxID)},
+
+		// Commitments are anchors of tenant HMACs computed here, so tenant keys stay server-side;
+		// verifying computes one too and is held to the same callers
+		{"POST", "/commitments", scoped(ScopeAnchorsWrite, commitmentHandler.CreateCommitment)},
+		{"POST", "/commitments/verify", scoped(ScopeAnchorsWrite, commitmentHandler.VerifyCommitment)},
+
+		// DID handlers
+		{"POST", "/dids", scoped(ScopeDIDsWrite, didHandler.CreateDid)},
+		{"GET", "/dids", http.HandlerFunc(didHandler.ListDids)},
+		// Registered before /dids/{did:.*}, which would otherwise match it
+		{"GET", "/dids/{did:.*}/update-nonce", http.HandlerFunc(didHandler.GetUpdateNonce)},
+		{"GET", "/dids/{did:.*}", http.HandlerFunc(didHandler.ResolveDid)},
+		{"PUT", "/dids/{did:.*}", scoped(ScopeDIDsWrite, didHandler.UpdateDid)},
+		{"DELETE", "/dids/{did:.*}", scoped(ScopeDIDsWrite, didHandler.DeactivateDid)},
+
+		// Status list handlers; changing a list is an issuer operation and needs the admin token
+		{"GET", "/status-lists/{id}", http.HandlerFunc(statusListHandler.GetStatusList)},
+		{"POST", "/status-lists/{id}/entries", adminAuth(opts.AdminToken, http.HandlerFunc(statusListHandler.AllocateEntry))},
+		{"POST", "/status-lists/{id}/entries/{index}/revoke", adminAuth(opts.AdminToken, http.HandlerFunc(statusListHandler.RevokeEntry))},
+
+		// Webhook registrations; deliveries are made by the dispatcher
+		{"POST", "/webhooks", adminAuth(opts.AdminToken, http.HandlerFunc(webhookHandler.CreateWebhook))},
+		{"GET", "/webhooks", adminAuth(opts.AdminToken, http.HandlerFunc(webhookHandler.ListWebhooks))},
+		{"DELETE", "/webhooks/{id}", adminAuth(opts.AdminToken, http.HandlerFunc(webhookHandler.DeleteWebhook))},
+	}
+	for i, route := range routes {
+		if !sharedRoutes[route.path] {
+			routes[i].handler = tenants.scoped(route.handler)
+		}
+	}
+	return routes
+}
+
+// accessLog collects what inner middleware learns about the caller, for the log
+// line written once the request completes.
+type accessLog struct {
+	apiKeyID     string
+	tokenSubject string
+	tenant       string
+}
+
+type accessLogKey struct{}
+
+// loggingMiddleware writes one access log record per request, once it completes,
+// except for the successful requests to excluded paths that opts does not sample.
+func loggingMiddleware(logger *slog.Logger, opts AccessLogOptions) mux.MiddlewareFunc {
+	var sampled atomic.Uint64
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			entry := &accessLog{}
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), accessLogKey{}, entry)))
+
+			if status := sw.Status(); status >= 200 && status < 300 && opts.excludes(r.URL.Path) {
+				if opts.SampleEvery <= 0 || (sampled.Add(1)-1)%uint64(opts.SampleEvery) != 0 {
+					return
+				}
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", sw.Status()),
+				slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("request_id", requestid.FromContext(r.Context())),
+				slog.String("remote_addr", r.RemoteAddr),
+			}
+			if subject := handlers.ClientSubject(r.Context()); subject != "" {
+				attrs = append(attrs, slog.String("client", subject))
+			}
+			if entry.apiKeyID != "" {
+				attrs = append(attrs, slog.String("key", entry.apiKeyID))
+			}
+			if entry.tokenSubject != "" {
+				attrs = append(attrs, slog.String("sub", entry.tokenSubject))
+			}
+			if entry.tenant != "" {
+				attrs = append(attrs, slog.String("tenant", entry.tenant))
+			}
+			logger.LogAttrs(r.Context(), slog.LevelInfo, "request", attrs...)
+		})
+	}
+}
+
+// statusWriter records the status of the response for the access log.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
 }
 
-func healthHandler(w http.ResponseWriter, r *http.Request) {
-	response := map[string]interface{}{
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to flush streams.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Status returns the status sent, or 200 when the handler wrote nothing, as net/http then sends.
+func (w *statusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// readinessTimeout bounds the ledger probe of /readyz, below the usual probe timeouts.
+const readinessTimeout = 2 * time.Second
+
+// livenessHandler reports that the process is up and serving.
+func livenessHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, map[string]interface{}{
 		"status":    "healthy",
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
 		"service":   "fabric-resolver",
+	})
+}
+
+// readinessHandler runs the health checks of the ledger and answers 503, naming
+// the failing components, when it cannot serve requests.
+func readinessHandler(ledgerClient fabric.LedgerClient, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		results := health.Run(r.Context(), readinessTimeout, health.Ledger(ledgerClient))
+
+		if failed := health.Failed(results); len(failed) > 0 {
+			var reasons []string
+			body := handlers.ErrorResponse{Code: handlers.CodeLedgerUnavailable}
+			for _, result := range failed {
+				logger.Warn("Readiness probe failed", "component", result.Name, "err", result.Err)
+				reasons = append(reasons, result.Name+": "+result.Err.Error())
+				body.Details = append(body.Details, handlers.ErrorDetail{Field: result.Name, Reason: result.Err.Error()})
+			}
+			body.Error = "Not ready: " + strings.Join(reasons, "; ")
+			body.Message = body.Error
+			writeErrorResponse(w, http.StatusServiceUnavailable, body)
+			return
+		}
+
+		checks := make(map[string]string, len(results))
+		for _, result := range results {
+			checks[result.Name] = "ok"
+		}
+		writeHealth(w, map[string]interface{}{
+			"status":    "healthy",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"service":   "fabric-resolver",
+			"checks":    checks,
+		})
+	}
+}
+
+// versionHandler reports the build of the binary.
+func versionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(buildinfo.Current()); err != nil {
+		slog.Error("Failed to encode version response", "err", err)
 	}
+}
 
+// writeHealth writes a healthy probe response with the build of the binary added.
+func writeHealth(w http.ResponseWriter, response map[string]interface{}) {
+	response["build"] = buildinfo.Current()
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("ERROR: Failed to encode health response: %v", err)
+		slog.Error("Failed to encode health response", "err", err)
 	}
 }
 
-// statsHandler returns statistics from the Fabric client (for debugging)
-func statsHandler(ledgerClient fabric.LedgerClient) http.HandlerFunc {
+// statsResponse is the /stats payload: the ledger stats, whether it is read-only,
+// the build and uptime of the process and the time they were taken, the rate
+// limiters by route class when limiting is on, and the ledgers of the tenants
+// served so far.
+type statsResponse struct {
+	fabric.Stats
+	ReadOnly   bool                       `json:"readOnly"`
+	Process    buildinfo.Info             `json:"process"`
+	RateLimits map[string]ratelimit.Stats `json:"rateLimits,omitempty"`
+	Tenants    map[string]fabric.Stats    `json:"tenants,omitempty"`
+	Timestamp  string                     `json:"timestamp"`
+}
+
+// basicStatsResponse is the /stats/basic payload: record counts only.
+type basicStatsResponse struct {
+	Anchors   int            `json:"anchors"`
+	DIDs      int            `json:"dids"`
+	DocTypes  map[string]int `json:"docTypes,omitempty"`
+	Timestamp string         `json:"timestamp"`
+}
+
+// statsHandler returns statistics from the ledger client and the process, for admins
+func statsHandler(ledgerClient fabric.LedgerClient, readOnly *fabric.ReadOnlySwitch, opts RouterOptions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		stats := ledgerClient.GetStats()
-		stats["timestamp"] = time.Now().UTC().Format(time.RFC3339)
+		stats := statsResponse{
+			Stats:     ledgerClient.GetStats(),
+			ReadOnly:  readOnly.On(),
+			Process:   buildinfo.Get(),
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+		}
+		for class, limiter := range map[string]*ratelimit.Limiter{"reads": opts.RateLimitReads, "writes": opts.RateLimitWrites} {
+			if limiter == nil {
+				continue
+			}
+			if stats.RateLimits == nil {
+				stats.RateLimits = make(map[string]ratelimit.Stats)
+			}
+			stats.RateLimits[class] = limiter.Stats()
+		}
+		if opts.Tenants != nil {
+			stats.Tenants = opts.Tenants.Stats()
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(stats); err != nil {
-			log.Printf("ERROR: Failed to encode stats response: %v", err)
+			slog.Error("Failed to encode stats response", "err", err)
+		}
+	}
+}
+
+// basicStatsHandler returns the record counts of the ledger, or of the tenant's,
+// leaving out where and how it is stored.
+func basicStatsHandler(ledgerClient fabric.LedgerClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		stats := handlers.LedgerFrom(r.Context(), ledgerClient).GetStats()
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(basicStatsResponse{
+			Anchors:   stats.Anchors,
+			DIDs:      stats.DIDs,
+			DocTypes:  stats.DocTypes,
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			slog.Error("Failed to encode stats response", "err", err)
 		}
 	}
 }
@@ -0,0 +1,420 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/api/openapi"
+	"fabric-resolver/internal/apikeys"
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+
+	"github.com/gorilla/mux"
+)
+
+func init() {
+	log.SetOutput(io.Discard)
+}
+
+func newTestRouter(t *testing.T, opts RouterOptions) (http.Handler, fabric.LedgerClient) {
+	t.Helper()
+	ledger, err := fabric.NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	if err != nil {
+		t.Fatalf("failed to create ledger: %v", err)
+	}
+	t.Cleanup(func() { ledger.Close() })
+	return NewRouter(ledger, opts), ledger
+}
+
+// accessLogs captures the records of a JSON logger, for RouterOptions.Logger.
+type accessLogs struct {
+	buf bytes.Buffer
+}
+
+func (l *accessLogs) logger() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(&l.buf, nil))
+}
+
+// requests returns the access log records written so far.
+func (l *accessLogs) requests(t *testing.T) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	dec := json.NewDecoder(&l.buf)
+	for dec.More() {
+		var record map[string]interface{}
+		if err := dec.Decode(&record); err != nil {
+			t.Fatalf("log record is not JSON: %v", err)
+		}
+		if record["msg"] == "request" {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func TestAccessLog_RecordsStatusAndRequestID(t *testing.T) {
+	var logs accessLogs
+	router, _ := newTestRouter(t, RouterOptions{Logger: logs.logger()})
+
+	req := httptest.NewRequest("GET", "/anchors/"+strings.Repeat("ab", 32), nil)
+	req.Header.Set("X-Request-ID", "req-404")
+	req.RemoteAddr = "192.0.2.1:1234"
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/health", nil))
+
+	records := logs.requests(t)
+	if len(records) != 2 {
+		t.Fatalf("expected one record per request, got %v", records)
+	}
+	got := records[0]
+	want := map[string]interface{}{
+		"level":       "INFO",
+		"method":      "GET",
+		"path":        "/anchors/" + strings.Repeat("ab", 32),
+		"status":      float64(http.StatusNotFound),
+		"request_id":  "req-404",
+		"remote_addr": "192.0.2.1:1234",
+	}
+	for field, value := range want {
+		if got[field] != value {
+			t.Errorf("expected %s=%v, got %v", field, value, got[field])
+		}
+	}
+	if _, ok := got["duration_ms"].(float64); !ok {
+		t.Errorf("expected a numeric duration_ms, got %v", got["duration_ms"])
+	}
+
+	// Handlers that never call WriteHeader are logged with the implicit 200
+	if records[1]["status"] != float64(http.StatusOK) || records[1]["request_id"] == "" {
+		t.Errorf("unexpected record %v", records[1])
+	}
+}
+
+// brokenLedger fails every anchor creation with an unclassified error.
+type brokenLedger struct {
+	fabric.LedgerClient
+}
+
+func (brokenLedger) CreateAnchor(ctx context.Context, anchor *domain.Anchor) (string, uint64, error) {
+	return "", 0, errors.New("disk on fire")
+}
+
+func TestAccessLog_SkipsSuccessfulExcludedRequests(t *testing.T) {
+	var logs accessLogs
+	_, ledger := newTestRouter(t, RouterOptions{})
+	router := NewRouter(brokenLedger{ledger}, RouterOptions{
+		Logger:    logs.logger(),
+		AccessLog: AccessLogOptions{Exclude: append(slices.Clone(DefaultAccessLogExclude), "/anchors")},
+	})
+
+	for _, path := range []string{"/health", "/livez", "/readyz", "/metrics", "/stats/basic"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/anchors", strings.NewReader(`{"hash":"`+strings.Repeat("ab", 32)+`"}`)))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected the broken ledger to fail the request, got %d", rec.Code)
+	}
+
+	records := logs.requests(t)
+	var logged []string
+	for _, record := range records {
+		logged = append(logged, fmt.Sprint(record["method"], " ", record["path"], " ", record["status"]))
+	}
+	want := []string{"GET /stats/basic 200", "POST /anchors 500"}
+	if !slices.Equal(logged, want) {
+		t.Errorf("expected only the unexcluded and failed requests to be logged, got %v", logged)
+	}
+}
+
+func TestAccessLog_SamplesExcludedRequests(t *testing.T) {
+	var logs accessLogs
+	router, _ := newTestRouter(t, RouterOptions{
+		Logger:    logs.logger(),
+		AccessLog: AccessLogOptions{Exclude: DefaultAccessLogExclude, SampleEvery: 10},
+	})
+
+	for range 25 {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/livez", nil))
+	}
+	if records := logs.requests(t); len(records) != 3 {
+		t.Errorf("expected 1 in 10 of 25 probes to be logged, got %d", len(records))
+	}
+}
+
+func TestTombstoneRequiresAdminToken(t *testing.T) {
+	router, ledger := newTestRouter(t, RouterOptions{AdminToken: "s3cret"})
+	ledger.CreateAnchor(t.Context(), &domain.Anchor{Hash: "h1", Metadata: json.RawMessage(`"m"`)})
+
+	tombstone := func(auth string) int {
+		req := httptest.NewRequest("DELETE", "/anchors/h1", strings.NewReader(`{"reason":"gdpr"}`))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := tombstone(""); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", code)
+	}
+	if code := tombstone("Bearer wrong"); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with wrong token, got %d", code)
+	}
+	if anchor, _ := ledger.GetAnchor(t.Context(), "h1"); anchor.Tombstoned {
+		t.Fatal("anchor tombstoned without valid token")
+	}
+
+	if code := tombstone("Bearer s3cret"); code != http.StatusOK {
+		t.Fatalf("expected 200 with token, got %d", code)
+	}
+	if anchor, _ := ledger.GetAnchor(t.Context(), "h1"); !anchor.Tombstoned {
+		t.Error("anchor not tombstoned")
+	}
+}
+
+func TestAnchorStreamRequiresAdminToken(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{AdminToken: "s3cret"})
+
+	for _, auth := range []string{"", "Bearer wrong"} {
+		req := httptest.NewRequest("GET", "/anchors/stream", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 with %q, got %d", auth, rec.Code)
+		}
+	}
+}
+
+func TestAdminEndpointsDisabledWithoutToken(t *testing.T) {
+	router, ledger := newTestRouter(t, RouterOptions{})
+	ledger.CreateAnchor(t.Context(), &domain.Anchor{Hash: "h1"})
+
+	req := httptest.NewRequest("DELETE", "/anchors/h1", strings.NewReader(`{"reason":"gdpr"}`))
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 when no admin token is configured, got %d", rec.Code)
+	}
+}
+
+func TestRevokeAcceptsAdminToken(t *testing.T) {
+	router, ledger := newTestRouter(t, RouterOptions{AdminToken: "s3cret"})
+	hash := strings.Repeat("ab", 32)
+	ledger.CreateAnchor(t.Context(), &domain.Anchor{Hash: hash, IssuerDID: "did:ewallet:issuer"})
+
+	revoke := func(auth string) int {
+		req := httptest.NewRequest("POST", "/anchors/"+hash+"/revoke", strings.NewReader(`{"reason":"compromised"}`))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := revoke("Bearer wrong"); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with wrong token, got %d", code)
+	}
+	// Without a token the issuer must sign the revocation
+	if code := revoke(""); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token or proof, got %d", code)
+	}
+	if anchor, _ := ledger.GetAnchor(t.Context(), hash); anchor.Revoked {
+		t.Fatal("anchor revoked without authorization")
+	}
+
+	if code := revoke("Bearer s3cret"); code != http.StatusOK {
+		t.Fatalf("expected 200 with admin token, got %d", code)
+	}
+	if anchor, _ := ledger.GetAnchor(t.Context(), hash); !anchor.Revoked || anchor.RevocationReason != "compromised" {
+		t.Errorf("anchor not revoked: %+v", anchor)
+	}
+}
+
+func TestResolvesPercentEncodedDID(t *testing.T) {
+	router, ledger := newTestRouter(t, RouterOptions{})
+	ledger.CreateDid(t.Context(), &domain.DIDDocument{ID: "did:web:example.com%3A8443"})
+
+	// Without matching on the escaped path, %3A would reach the handler as ":"
+	req := httptest.NewRequest("GET", "/dids/did:web:example.com%3A8443", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{})
+
+	documented := make(map[string]bool)
+	versioned := make(map[string]bool)
+	for _, op := range openapi.Build().Operations() {
+		documented[op] = true
+		versioned[op] = true
+	}
+
+	// Mux variables may carry a regexp ({did:.*}); OpenAPI templates do not
+	varPattern := regexp.MustCompile(`\{([^:}]+):[^}]*\}`)
+	err := router.(*mux.Router).Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			if path == "/" {
+				return nil // CORS preflight of every path, not an operation
+			}
+			return err
+		}
+		path = varPattern.ReplaceAllString(path, "{$1}")
+		for _, method := range methods {
+			if versioned[method+" "+apiV1+path] {
+				continue // deprecated alias of a versioned route
+			}
+			if op := method + " " + path; !documented[op] {
+				t.Errorf("%s is not in the OpenAPI document", op)
+			}
+			delete(documented, method+" "+path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk failed: %v", err)
+	}
+	for op := range documented {
+		t.Errorf("%s is documented but not routed", op)
+	}
+
+	for path, contentType := range map[string]string{"/openapi.json": "application/json", "/docs": "text/html"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), contentType) {
+			t.Errorf("GET %s: got %d %q", path, rec.Code, rec.Header().Get("Content-Type"))
+		}
+	}
+}
+
+const testAPIKey = "0123456789abcdef0123"
+
+func newAPIKeyRouter(t *testing.T, protectReads bool) http.Handler {
+	t.Helper()
+	keys, err := apikeys.NewKeys(map[string]string{"issuer-a": testAPIKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router, _ := newTestRouter(t, RouterOptions{APIKeys: keys, APIKeysProtectReads: protectReads})
+	return router
+}
+
+func serveWithKey(router http.Handler, method, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(APIKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIKeys_ReadsOpenWritesRequireKey(t *testing.T) {
+	router := newAPIKeyRouter(t, false)
+	anchor := `{"hash":"` + strings.Repeat("cd", 32) + `"}`
+
+	for _, path := range []string{"/health", "/stats/basic", "/anchors", "/openapi.json"} {
+		if rec := serveWithKey(router, "GET", path, "", ""); rec.Code != http.StatusOK {
+			t.Errorf("GET %s: expected 200 without a key, got %d", path, rec.Code)
+		}
+	}
+
+	for _, key := range []string{"", "wrong-key-wrong-key", testAPIKey[:len(testAPIKey)-1]} {
+		rec := serveWithKey(router, "POST", "/anchors", key, anchor)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("key %q: expected 401, got %d", key, rec.Code)
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["code"] != "unauthorized" {
+			t.Errorf("expected the structured error body, got %s", rec.Body.String())
+		}
+		if rec.Header().Get("WWW-Authenticate") == "" {
+			t.Error("expected a WWW-Authenticate challenge")
+		}
+	}
+	if rec := serveWithKey(router, "POST", "/dids", "", `{"did":"did:ewallet:x"}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected DID registration to require a key, got %d", rec.Code)
+	}
+
+	if rec := serveWithKey(router, "POST", "/anchors", testAPIKey, anchor); rec.Code != http.StatusCreated {
+		t.Errorf("expected 201 with a valid key, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAPIKeys_ProtectReads(t *testing.T) {
+	router := newAPIKeyRouter(t, true)
+
+	if rec := serveWithKey(router, "GET", "/anchors", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected reads to require a key, got %d", rec.Code)
+	}
+	if rec := serveWithKey(router, "GET", "/anchors", testAPIKey, ""); rec.Code != http.StatusOK {
+		t.Errorf("expected 200 with a valid key, got %d", rec.Code)
+	}
+	if rec := serveWithKey(router, "GET", "/health", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected /health to stay open for probes, got %d", rec.Code)
+	}
+}
+
+func TestAPIKeys_LogsKeyIDNotKey(t *testing.T) {
+	keys, err := apikeys.NewKeys(map[string]string{"issuer-a": testAPIKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logs accessLogs
+	router, _ := newTestRouter(t, RouterOptions{APIKeys: keys, Logger: logs.logger()})
+
+	serveWithKey(router, "POST", "/anchors", testAPIKey, `{"hash":"`+strings.Repeat("ef", 32)+`"}`)
+	if strings.Contains(logs.buf.String(), testAPIKey) {
+		t.Error("expected the key itself to stay out of the log")
+	}
+	if records := logs.requests(t); len(records) != 1 || records[0]["key"] != "issuer-a" {
+		t.Errorf("expected the access log to name the key id, got %v", records)
+	}
+}
+
+func TestAccessLogOptions_Excludes(t *testing.T) {
+	opts := AccessLogOptions{Exclude: []string{"/health", "/debug/pprof/"}}
+	for path, want := range map[string]bool{
+		"/health":           true,
+		"/health/extra":     false,
+		"/healthz":          false,
+		"/debug/pprof/":     true,
+		"/debug/pprof/heap": true,
+		"/debug/pprofx":     false,
+		"/anchors/stream":   false,
+	} {
+		if got := opts.excludes(path); got != want {
+			t.Errorf("excludes(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"fabric-resolver/internal/config"
+)
+
+// NewServer builds the HTTP server of cfg around handler: its address, TLS,
+// timeouts, header limit and protocols. Server errors go to the default logger.
+func NewServer(cfg *config.ServerConfig, handler http.Handler) (*http.Server, error) {
+	tlsConfig, err := cfg.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+	}
+	if cfg.DisableHTTP2 {
+		// HTTP/2 is only ever negotiated over TLS here; HTTP/1.1 alone is left
+		var protocols http.Protocols
+		protocols.SetHTTP1(true)
+		server.Protocols = &protocols
+	}
+	return server, nil
+}
+
+// Listen binds the address of cfg for the server to Serve on. A unix socket left
+// behind by a server that is gone is replaced, one another server still answers
+// on is not; the socket gets cfg.ListenSocketMode and is removed again when the
+// listener is closed, as Shutdown does.
+func Listen(cfg *config.ServerConfig) (net.Listener, error) {
+	network, address, err := cfg.ListenAddr()
+	if err != nil {
+		return nil, err
+	}
+	if network != "unix" {
+		return net.Listen(network, address)
+	}
+
+	if err := removeStaleSocket(address); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(address, cfg.ListenSocketMode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return ln, nil
+}
+
+// removeStaleSocket removes the socket at path unless a server still accepts
+// connections on it. Anything other than a socket is left alone.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().Type() != os.ModeSocket {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("socket %s is in use by another server", path)
+	}
+	return os.Remove(path)
+}
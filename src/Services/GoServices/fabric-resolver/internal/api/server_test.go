@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/config"
+)
+
+func TestNewServer_AppliesConfig(t *testing.T) {
+	handler := http.NotFoundHandler()
+	cfg := &config.ServerConfig{
+		Port:              8443,
+		ReadTimeout:       11 * time.Second,
+		ReadHeaderTimeout: 3 * time.Second,
+		WriteTimeout:      17 * time.Second,
+		IdleTimeout:       90 * time.Second,
+		MaxHeaderBytes:    64 << 10,
+		DisableHTTP2:      true,
+	}
+
+	server, err := NewServer(cfg, handler)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	if server.Addr != ":8443" || server.Handler == nil || server.ErrorLog == nil {
+		t.Errorf("unexpected address, handler or error log: %q %v %v", server.Addr, server.Handler, server.ErrorLog)
+	}
+	if server.ReadTimeout != cfg.ReadTimeout || server.ReadHeaderTimeout != cfg.ReadHeaderTimeout ||
+		server.WriteTimeout != cfg.WriteTimeout || server.IdleTimeout != cfg.IdleTimeout {
+		t.Errorf("expected the configured timeouts, got read %s, header %s, write %s, idle %s",
+			server.ReadTimeout, server.ReadHeaderTimeout, server.WriteTimeout, server.IdleTimeout)
+	}
+	if server.MaxHeaderBytes != 64<<10 {
+		t.Errorf("expected the header limit, got %d", server.MaxHeaderBytes)
+	}
+	if server.TLSConfig != nil {
+		t.Error("expected no TLS without certificate files")
+	}
+	if p := server.Protocols; p == nil || !p.HTTP1() || p.HTTP2() || p.UnencryptedHTTP2() {
+		t.Errorf("expected HTTP/1.1 only, got %v", p)
+	}
+
+	cfg.DisableHTTP2 = false
+	if server, err = NewServer(cfg, handler); err != nil || server.Protocols != nil {
+		t.Errorf("expected the default protocols with HTTP/2 enabled, got %v, %v", server.Protocols, err)
+	}
+}
+
+func TestNewServer_TLS(t *testing.T) {
+	serverCert := newTestCert(t, "localhost", false, nil)
+	server, err := NewServer(&config.ServerConfig{TLSCertFile: serverCert.certFile, TLSKeyFile: serverCert.keyFile}, http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	if server.TLSConfig == nil || len(server.TLSConfig.Certificates) != 1 {
+		t.Errorf("expected the certificate to be loaded, got %+v", server.TLSConfig)
+	}
+
+	if _, err := NewServer(&config.ServerConfig{TLSCertFile: serverCert.certFile}, http.NotFoundHandler()); err == nil {
+		t.Error("expected a certificate without its key to be rejected")
+	}
+}
+
+func TestListen_UnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resolver.sock")
+	cfg := &config.ServerConfig{Listen: "unix://" + path, ListenSocketMode: 0o600, ShutdownTimeout: time.Second}
+
+	// A socket left behind by a server that crashed
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	ln, err := Listen(cfg)
+	if err != nil {
+		t.Fatalf("expected the stale socket to be replaced, got %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the socket with mode 0600, got %v (%v)", info.Mode(), err)
+	}
+
+	router, _ := newTestRouter(t, RouterOptions{})
+	server, err := NewServer(cfg, router)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(ln)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://resolver/livez")
+	if err != nil {
+		t.Fatalf("request over the socket failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "healthy") {
+		t.Errorf("expected a healthy probe, got %d: %s", resp.StatusCode, body)
+	}
+
+	// A second server must not take over a socket that is answering
+	if _, err := Listen(cfg); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Errorf("expected the live socket to be refused, got %v", err)
+	}
+
+	if err := server.Shutdown(t.Context()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the socket to be removed on shutdown, got %v", err)
+	}
+}
+
+func TestListen_LeavesOtherFilesAlone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resolver.sock")
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen(&config.ServerConfig{Listen: "unix://" + path}); err == nil {
+		t.Fatal("expected a regular file at the socket path to be refused")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "data" {
+		t.Errorf("expected the file to be left alone, got %q (%v)", data, err)
+	}
+}
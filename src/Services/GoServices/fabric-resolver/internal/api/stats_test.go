@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/domain"
+)
+
+func getAs(router http.Handler, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStats_RequiresAdmin(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{AdminToken: "s3cret"})
+
+	for token, want := range map[string]int{"": http.StatusUnauthorized, "wrong": http.StatusUnauthorized, "s3cret": http.StatusOK} {
+		if rec := getAs(router, "/stats", token); rec.Code != want {
+			t.Errorf("token %q: expected %d, got %d", token, want, rec.Code)
+		}
+	}
+
+	disabled, _ := newTestRouter(t, RouterOptions{})
+	if rec := getAs(disabled, "/stats", "anything"); rec.Code != http.StatusForbidden {
+		t.Errorf("expected /stats to be off without an admin token, got %d", rec.Code)
+	}
+}
+
+func TestStats_ReportsLedgerAndProcess(t *testing.T) {
+	router, ledger := newTestRouter(t, RouterOptions{AdminToken: "s3cret"})
+	if _, _, err := ledger.CreateAnchor(context.Background(), &domain.Anchor{Hash: strings.Repeat("ab", 32)}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+
+	rec := getAs(router, "/stats", "s3cret")
+	var stats statsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("expected a JSON body, got %q", rec.Body.String())
+	}
+	if stats.Anchors != 1 || stats.Mode != "file-persistent" || stats.Path == "" || stats.FileSizeBytes <= 0 {
+		t.Errorf("expected the ledger stats, got %+v", stats.Stats)
+	}
+	if _, ok := stats.LastWrites["anchor"]; !ok {
+		t.Errorf("expected the last anchor write, got %v", stats.LastWrites)
+	}
+	if stats.Process.Version != "dev" || stats.Process.Commit != "dev" || stats.Process.BuildDate != "dev" {
+		t.Errorf("expected the build to default to dev, got %+v", stats.Process.Build)
+	}
+	if stats.Process.Version == "" || stats.Process.GoVersion == "" || stats.Process.StartedAt.IsZero() || stats.Process.UptimeSeconds < 0 {
+		t.Errorf("expected the build and uptime of the process, got %+v", stats.Process)
+	}
+}
+
+func TestStats_BasicIsPublicCountsOnly(t *testing.T) {
+	router, ledger := newTestRouter(t, RouterOptions{AdminToken: "s3cret"})
+	if err := ledger.CreateDid(context.Background(), &domain.DIDDocument{ID: "did:ewallet:stats"}); err != nil {
+		t.Fatalf("CreateDid failed: %v", err)
+	}
+
+	rec := getAs(router, "/stats/basic", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 without a token, got %d", rec.Code)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["dids"] != float64(1) || body["anchors"] != float64(0) {
+		t.Errorf("expected the record counts, got %v", body)
+	}
+	for _, field := range []string{"path", "mode", "fileSizeBytes", "lastWrites", "process"} {
+		if _, ok := body[field]; ok {
+			t.Errorf("expected %s to be left out of /stats/basic, got %v", field, body)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+// tenancy serves each request from the ledger of its tenant: the tenant its API
+// key is assigned to, or else the one named in X-Tenant-ID.
+type tenancy struct {
+	ledgers *fabric.TenantLedgerProvider
+	// apiKeys maps API key ids to the tenant their requests are held to
+	apiKeys map[string]string
+	logger  *slog.Logger
+}
+
+// scoped wraps next to run with the ledger of the request's tenant. Requests
+// naming no tenant are answered 400, and those naming a tenant that is not
+// hosted, or not the one of their API key, 403. Without tenants next is
+// returned as is.
+func (t tenancy) scoped(next http.Handler) http.Handler {
+	if t.ledgers == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant := r.Header.Get(handlers.TenantHeader)
+		if keyTenant, ok := t.apiKeys[handlers.APIKeyID(r.Context())]; ok {
+			if tenant != "" && tenant != keyTenant {
+				writeErrorCode(w, http.StatusForbidden, handlers.CodeUnknownTenant, "API key is not valid for tenant "+tenant)
+				return
+			}
+			tenant = keyTenant
+		}
+		if tenant == "" {
+			writeErrorCode(w, http.StatusBadRequest, handlers.CodeTenantRequired, handlers.TenantHeader+" header required")
+			return
+		}
+
+		ledger, err := t.ledgers.Ledger(r.Context(), tenant)
+		switch {
+		case errors.Is(err, fabric.ErrUnknownTenant):
+			writeErrorCode(w, http.StatusForbidden, handlers.CodeUnknownTenant, "Unknown tenant")
+			return
+		case err != nil:
+			t.logger.Error("Failed to open tenant ledger", "tenant", tenant, "err", err)
+			writeErrorCode(w, http.StatusServiceUnavailable, handlers.CodeLedgerUnavailable, "Ledger unavailable")
+			return
+		}
+
+		if entry, ok := r.Context().Value(accessLogKey{}).(*accessLog); ok {
+			entry.tenant = tenant
+		}
+		ctx := handlers.WithLedger(handlers.WithTenant(r.Context(), tenant), ledger)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
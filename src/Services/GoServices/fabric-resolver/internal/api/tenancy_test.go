@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/apikeys"
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/webhooks"
+)
+
+func newTenantRouter(t *testing.T, opts RouterOptions) (http.Handler, *fabric.TenantLedgerProvider) {
+	t.Helper()
+	tenants, err := fabric.NewTenantLedgerProvider(fabric.Config{FilePath: filepath.Join(t.TempDir(), "ledger.json")}, []string{"acme", "globex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tenants.Close() })
+	opts.Tenants = tenants
+	router, _ := newTestRouter(t, opts)
+	return router, tenants
+}
+
+func serveAsTenant(router http.Handler, method, path, tenant, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if tenant != "" {
+		req.Header.Set(handlers.TenantHeader, tenant)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTenancy_TenantsAnchorIndependently(t *testing.T) {
+	router, _ := newTenantRouter(t, RouterOptions{})
+	hash := strings.Repeat("ab", 32)
+	anchor := `{"hash":"` + hash + `"}`
+
+	for _, tenant := range []string{"acme", "globex"} {
+		if rec := serveAsTenant(router, "POST", "/v1/anchors", tenant, anchor); rec.Code != http.StatusCreated {
+			t.Fatalf("%s: expected the anchor to be created, got %d: %s", tenant, rec.Code, rec.Body.String())
+		}
+	}
+	// Each tenant anchored the hash on a ledger of its own
+	for _, tenant := range []string{"acme", "globex"} {
+		rec := serveAsTenant(router, "GET", "/v1/anchors/"+hash, tenant, "")
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: expected its anchor, got %d", tenant, rec.Code)
+		}
+		var stats basicStatsResponse
+		if err := json.Unmarshal(serveAsTenant(router, "GET", "/stats/basic", tenant, "").Body.Bytes(), &stats); err != nil || stats.Anchors != 1 {
+			t.Errorf("%s: expected one anchor in its stats, got %+v (%v)", tenant, stats, err)
+		}
+	}
+}
+
+func TestTenancy_OtherTenantsAnchorsAreNotFound(t *testing.T) {
+	router, _ := newTenantRouter(t, RouterOptions{AdminToken: "s3cret"})
+	hash := strings.Repeat("cd", 32)
+	if rec := serveAsTenant(router, "POST", "/v1/anchors", "acme", `{"hash":"`+hash+`"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("expected the anchor to be created, got %d", rec.Code)
+	}
+
+	for _, path := range []string{"/v1/anchors/" + hash, "/anchors/" + hash} {
+		if rec := serveAsTenant(router, "GET", path, "globex", ""); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s as another tenant: expected 404, got %d", path, rec.Code)
+		}
+	}
+
+	var stats statsResponse
+	if err := json.Unmarshal(getAs(router, "/stats", "s3cret").Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Tenants["acme"].Anchors != 1 || stats.Tenants["globex"].Anchors != 0 || stats.Anchors != 0 {
+		t.Errorf("expected the anchor in the stats of acme alone, got %+v and %d shared", stats.Tenants, stats.Anchors)
+	}
+}
+
+func TestTenancy_RequiresKnownTenant(t *testing.T) {
+	router, _ := newTenantRouter(t, RouterOptions{})
+
+	for tenant, want := range map[string]struct {
+		status int
+		code   string
+	}{
+		"":        {http.StatusBadRequest, handlers.CodeTenantRequired},
+		"initech": {http.StatusForbidden, handlers.CodeUnknownTenant},
+		"../acme": {http.StatusForbidden, handlers.CodeUnknownTenant},
+	} {
+		rec := serveAsTenant(router, "GET", "/v1/anchors", tenant, "")
+		var body handlers.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		if rec.Code != want.status || body.Code != want.code {
+			t.Errorf("tenant %q: expected %d %s, got %d %s", tenant, want.status, want.code, rec.Code, body.Code)
+		}
+	}
+
+	// Routes shared by every tenant need none
+	for _, path := range []string{"/health", "/v1/receipts/public-key", "/openapi.json"} {
+		if rec := serveAsTenant(router, "GET", path, "", ""); rec.Code == http.StatusBadRequest || rec.Code == http.StatusForbidden {
+			t.Errorf("GET %s: expected no tenant to be required, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestTenancy_APIKeyHoldsRequestsToItsTenant(t *testing.T) {
+	keys, err := apikeys.NewKeys(map[string]string{"acme-ci": testAPIKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router, tenants := newTenantRouter(t, RouterOptions{APIKeys: keys, TenantAPIKeys: map[string]string{"acme-ci": "acme"}})
+	anchor := `{"hash":"` + strings.Repeat("ef", 32) + `"}`
+
+	post := func(tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v1/anchors", strings.NewReader(anchor))
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		if tenant != "" {
+			req.Header.Set(handlers.TenantHeader, tenant)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := post("globex"); rec.Code != http.StatusForbidden {
+		t.Errorf("expected another tenant than the key's to be refused, got %d", rec.Code)
+	}
+	if rec := post(""); rec.Code != http.StatusCreated {
+		t.Fatalf("expected the key to imply its tenant, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if stats := tenants.Stats(); stats["acme"].Anchors != 1 {
+		t.Errorf("expected the anchor on the ledger of acme, got %+v", stats)
+	}
+}
+
+func TestTenancy_RevocationWebhookNamesTenant(t *testing.T) {
+	delivered := make(chan webhooks.Event, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event webhooks.Event
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &event)
+		delivered <- event
+	}))
+	defer srv.Close()
+
+	ledger, err := fabric.NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ledger.Close() })
+	if err := ledger.SaveWebhook(t.Context(), &domain.Webhook{ID: "wh_1", URL: srv.URL, Events: []string{domain.EventAnchorRevoked}}); err != nil {
+		t.Fatalf("SaveWebhook failed: %v", err)
+	}
+	tenants, err := fabric.NewTenantLedgerProvider(fabric.Config{FilePath: filepath.Join(t.TempDir(), "tenants.json")}, []string{"acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tenants.Close() })
+
+	dispatcher := webhooks.NewDispatcher(ledger, webhooks.Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		dispatcher.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	router := NewRouter(ledger, RouterOptions{AdminToken: "s3cret", Tenants: tenants, Webhooks: dispatcher})
+
+	hash := strings.Repeat("ab", 32)
+	if rec := serveAsTenant(router, "POST", "/v1/anchors", "acme", `{"hash":"`+hash+`"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("expected the anchor to be created, got %d", rec.Code)
+	}
+	req := httptest.NewRequest("POST", "/v1/anchors/"+hash+"/revoke", strings.NewReader(`{"reason":"compromised"}`))
+	req.Header.Set(handlers.TenantHeader, "acme")
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the anchor to be revoked, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	select {
+	case event := <-delivered:
+		if event.Type != domain.EventAnchorRevoked || event.Tenant != "acme" {
+			t.Errorf("expected the revocation to be delivered as one of acme, got %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the revocation")
+	}
+}
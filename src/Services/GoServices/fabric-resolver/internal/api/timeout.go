@@ -0,0 +1,161 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"fabric-resolver/internal/api/handlers"
+
+	"github.com/gorilla/mux"
+)
+
+// DefaultRequestTimeout bounds the handling of requests without a deadline of their own.
+const DefaultRequestTimeout = 10 * time.Second
+
+// Timeouts bounds how long a handler may take before the request is answered
+// with 504 and its context is cancelled. Zero values use the defaults.
+type Timeouts struct {
+	// Default applies to every route without a timeout of its own (default 10s).
+	Default time.Duration
+
+	// Batch applies to the routes limited by BodyLimits.Batch, which write or
+	// verify many anchors in one request (default Default).
+	Batch time.Duration
+}
+
+// untimedRoutes are never cut short: event streams stay open by design, and
+// profiles run for as long as the caller asks.
+var untimedRoutes = []string{"/anchors/stream", "/debug/pprof/"}
+
+func (t Timeouts) withDefaults() Timeouts {
+	if t.Default <= 0 {
+		t.Default = DefaultRequestTimeout
+	}
+	if t.Batch <= 0 {
+		t.Batch = t.Default
+	}
+	return t
+}
+
+// routes returns the timeouts of the routes that do not use the default; zero
+// means no timeout.
+func (t Timeouts) routes() map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	for _, route := range batchRoutes {
+		timeouts[route] = t.Batch
+	}
+	for _, route := range untimedRoutes {
+		timeouts[route] = 0
+	}
+	return timeouts
+}
+
+// requestTimeout runs the handler with a context that expires after the timeout
+// of the matched route, or fallback. The response is buffered; if the handler
+// has not returned by the deadline, a 504 is sent instead and whatever the
+// handler writes afterwards is dropped. The handler keeps running until it
+// notices the cancelled context, so ledger calls must honor it.
+func requestTimeout(routeTimeouts map[string]time.Duration, fallback time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout, ok := routeTimeouts[unversioned(routeTemplate(r))]
+			if !ok {
+				timeout = fallback
+			}
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			// The handler gets its own copy of the headers set so far, as it may still
+			// be changing them after the 504 has been sent
+			tw := &timeoutWriter{header: w.Header().Clone()}
+			done := make(chan struct{})
+			panicked := make(chan interface{}, 1)
+			go func() {
+				defer func() {
+					if rec := recover(); rec != nil {
+						panicked <- rec
+					}
+				}()
+				next.ServeHTTP(tw, r)
+				close(done)
+			}()
+
+			select {
+			case rec := <-panicked:
+				// Re-raised here, where recoverMiddleware can answer it. What the
+				// handler wrote is sent first, so a started response is aborted as
+				// it would be unbuffered.
+				if tw.status != 0 {
+					tw.copyTo(w)
+				}
+				panic(rec)
+			case <-done:
+				tw.copyTo(w)
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+				if ctx.Err() == context.DeadlineExceeded {
+					writeErrorCode(w, http.StatusGatewayTimeout, handlers.CodeTimeout, "Request did not complete within "+timeout.String())
+				}
+			}
+		})
+	}
+}
+
+// timeoutWriter buffers the response of a handler run by requestTimeout.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(status int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status == 0 && !w.timedOut {
+		w.status = status
+	}
+}
+
+// Write fails with http.ErrHandlerTimeout once the 504 has been sent.
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+// copyTo sends the buffered response of a handler that has returned.
+func (w *timeoutWriter) copyTo(dst http.ResponseWriter) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for key, values := range w.header {
+		dst.Header()[key] = values
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	dst.WriteHeader(w.status)
+	dst.Write(w.body.Bytes())
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/requestid"
+)
+
+// slowLedger takes delay over GetAnchor and CreateAnchors, giving up early when
+// the request's context is done, and reports the context error it observed.
+type slowLedger struct {
+	fabric.LedgerClient
+	delay    time.Duration
+	observed chan error
+}
+
+func (l *slowLedger) wait(ctx context.Context) error {
+	var err error
+	select {
+	case <-time.After(l.delay):
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	select {
+	case l.observed <- err:
+	default:
+	}
+	return err
+}
+
+func (l *slowLedger) GetAnchor(ctx context.Context, hash string) (*domain.Anchor, error) {
+	if err := l.wait(ctx); err != nil {
+		return nil, err
+	}
+	return l.LedgerClient.GetAnchor(ctx, hash)
+}
+
+func (l *slowLedger) CreateAnchors(ctx context.Context, anchors []*domain.Anchor) ([]fabric.AnchorResult, error) {
+	if err := l.wait(ctx); err != nil {
+		return nil, err
+	}
+	return l.LedgerClient.CreateAnchors(ctx, anchors)
+}
+
+func newSlowRouter(t *testing.T, delay time.Duration, timeouts Timeouts) (http.Handler, *slowLedger) {
+	t.Helper()
+	inner, err := fabric.NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	if err != nil {
+		t.Fatalf("failed to create ledger: %v", err)
+	}
+	t.Cleanup(func() { inner.Close() })
+	ledger := &slowLedger{LedgerClient: inner, delay: delay, observed: make(chan error, 1)}
+	return NewRouter(ledger, RouterOptions{Timeouts: timeouts, Logger: discardLogger}), ledger
+}
+
+func TestRequestTimeout_AnswersGatewayTimeout(t *testing.T) {
+	router, ledger := newSlowRouter(t, time.Minute, Timeouts{Default: 50 * time.Millisecond})
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/anchors/"+strings.Repeat("ab", 32), nil))
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Fatalf("expected the request to be cut short, took %s", waited)
+	}
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504, got %d: %s", rec.Code, rec.Body.String())
+	}
+	body := errorBody(t, rec)
+	if body.Code != handlers.CodeTimeout || !strings.Contains(body.Message, "50ms") {
+		t.Errorf("expected a timeout error naming the limit, got %+v", body)
+	}
+	if body.RequestID == "" || body.RequestID != rec.Header().Get(requestid.Header) {
+		t.Errorf("expected the 504 to carry the request id, got %q", body.RequestID)
+	}
+
+	select {
+	case err := <-ledger.observed:
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected the handler to observe the deadline, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the handler's ledger call to be cancelled")
+	}
+}
+
+func TestRequestTimeout_FastRequestsPassThrough(t *testing.T) {
+	router, ledger := newSlowRouter(t, 0, Timeouts{Default: time.Second})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/anchors/"+strings.Repeat("ab", 32), nil))
+	if rec.Code != http.StatusNotFound || errorBody(t, rec).Code != handlers.CodeNotFound {
+		t.Errorf("expected the handler's own 404, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Type") != "application/json" || rec.Header().Get(requestid.Header) == "" {
+		t.Errorf("expected the handler's headers, got %v", rec.Header())
+	}
+	if err := <-ledger.observed; err != nil {
+		t.Errorf("expected the ledger call to complete, got %v", err)
+	}
+}
+
+func TestRequestTimeout_BatchRoutesUseTheBatchTimeout(t *testing.T) {
+	router, ledger := newSlowRouter(t, 100*time.Millisecond, Timeouts{Default: 20 * time.Millisecond, Batch: 5 * time.Second})
+
+	batch := `[{"hash":"` + strings.Repeat("cd", 32) + `"}]`
+	if rec := postBody(router, "/anchors/batch", batch, true); rec.Code != http.StatusMultiStatus {
+		t.Errorf("expected the batch to outlast the default timeout, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if err := <-ledger.observed; err != nil {
+		t.Errorf("expected the batch's ledger call to complete, got %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/anchors/"+strings.Repeat("cd", 32), nil))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected other routes to keep the default timeout, got %d", rec.Code)
+	}
+	<-ledger.observed
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/config"
+)
+
+// testCert is a certificate and key, in memory and written to PEM files.
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+func (c testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+// newTestCert issues a certificate for cn, signed by parent or self-signed when parent is nil.
+func newTestCert(t *testing.T, cn string, isCA bool, parent *testCert) testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn, Organization: []string{"EWallet"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	c := testCert{cert: cert, key: key, certFile: filepath.Join(dir, "cert.pem"), keyFile: filepath.Join(dir, "key.pem")}
+	writePEM(t, c.certFile, "CERTIFICATE", der)
+	writePEM(t, c.keyFile, "EC PRIVATE KEY", keyDER)
+	return c
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// startTLSServer serves handler with the TLS configuration built from cfg.
+func startTLSServer(t *testing.T, cfg config.ServerConfig, handler http.Handler) *httptest.Server {
+	t.Helper()
+	tlsConfig, err := cfg.TLSConfig()
+	if err != nil {
+		t.Fatalf("TLSConfig failed: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(handler)
+	srv.TLS = tlsConfig
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func tlsClient(roots *x509.Certificate, certs ...tls.Certificate) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AddCert(roots)
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, Certificates: certs}}}
+}
+
+func TestTLS_Handshake(t *testing.T) {
+	server := newTestCert(t, "resolver", true, nil)
+	router, _ := newTestRouter(t, RouterOptions{})
+	srv := startTLSServer(t, config.ServerConfig{TLSCertFile: server.certFile, TLSKeyFile: server.keyFile}, router)
+
+	resp, err := tlsClient(server.cert).Get(srv.URL + "/health")
+	if err != nil {
+		t.Fatalf("TLS request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+	if resp.TLS == nil || resp.TLS.Version < tls.VersionTLS12 {
+		t.Errorf("expected TLS 1.2 or later, got %+v", resp.TLS)
+	}
+
+	// TLS 1.1 is below the minimum
+	old := tlsClient(server.cert)
+	old.Transport.(*http.Transport).TLSClientConfig.MaxVersion = tls.VersionTLS11
+	if _, err := old.Get(srv.URL + "/health"); err == nil {
+		t.Error("expected a TLS 1.1 handshake to be refused")
+	}
+}
+
+func TestTLS_MutualHandshake(t *testing.T) {
+	ca := newTestCert(t, "clients-ca", true, nil)
+	server := newTestCert(t, "resolver", true, nil)
+	client := newTestCert(t, "issuer-service", false, &ca)
+	stranger := newTestCert(t, "stranger", false, nil)
+
+	// Echo the subject the middleware attributes the request to
+	echo := clientCertMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, handlers.ClientSubject(r.Context()))
+	}))
+	srv := startTLSServer(t, config.ServerConfig{
+		TLSCertFile: server.certFile,
+		TLSKeyFile:  server.keyFile,
+		TLSClientCA: ca.certFile,
+	}, echo)
+
+	resp, err := tlsClient(server.cert, client.tlsCertificate()).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("mTLS request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if got := string(body); got != "CN=issuer-service,O=EWallet" {
+		t.Errorf("expected the client subject in the request context, got %q", got)
+	}
+
+	if _, err := tlsClient(server.cert).Get(srv.URL); err == nil {
+		t.Error("expected a client without a certificate to be refused")
+	}
+	if _, err := tlsClient(server.cert, stranger.tlsCertificate()).Get(srv.URL); err == nil {
+		t.Error("expected a certificate from another CA to be refused")
+	}
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs an in-memory span recorder as the global tracer provider
+// for the duration of the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		provider.Shutdown(t.Context())
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return recorder
+}
+
+func TestTracing_CreateAnchorSpanHierarchy(t *testing.T) {
+	recorder := recordSpans(t)
+	router, _ := newTestRouter(t, RouterOptions{})
+
+	const (
+		traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanID = "00f067aa0ba902b7"
+	)
+	req := httptest.NewRequest("POST", "/anchors", strings.NewReader(`{"hash":"`+strings.Repeat("cd", 32)+`"}`))
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentSpanID+"-01")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	parentOf := func(name string) trace.SpanContext {
+		t.Helper()
+		span, ok := spans[name]
+		if !ok {
+			t.Fatalf("expected a %q span, got %v", name, spanNames(recorder.Ended()))
+		}
+		if got := span.SpanContext().TraceID().String(); got != traceID {
+			t.Errorf("%s: expected trace %s, got %s", name, traceID, got)
+		}
+		return span.Parent()
+	}
+
+	server := spans["POST /anchors"]
+	if parent := parentOf("POST /anchors"); !parent.IsRemote() || parent.SpanID().String() != parentSpanID {
+		t.Errorf("expected the server span to continue the incoming traceparent, got parent %v", parent)
+	}
+	if server.SpanKind() != trace.SpanKindServer {
+		t.Errorf("expected a server span, got %v", server.SpanKind())
+	}
+	if parent := parentOf("ledger.CreateAnchor"); parent.SpanID() != server.SpanContext().SpanID() {
+		t.Errorf("expected ledger.CreateAnchor under the server span, got parent %v", parent.SpanID())
+	}
+	ledger := spans["ledger.CreateAnchor"]
+	for _, phase := range []string{"ledger.lock_wait", "ledger.marshal", "ledger.persist"} {
+		if parent := parentOf(phase); parent.SpanID() != ledger.SpanContext().SpanID() {
+			t.Errorf("expected %s under ledger.CreateAnchor, got parent %v", phase, parent.SpanID())
+		}
+	}
+}
+
+func TestTracing_StartsRootSpanWithoutTraceparent(t *testing.T) {
+	recorder := recordSpans(t)
+	router, _ := newTestRouter(t, RouterOptions{})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/anchors/"+strings.Repeat("ab", 32), nil))
+
+	for _, span := range recorder.Ended() {
+		if span.Name() == "GET /anchors/{hash}" {
+			if span.Parent().IsValid() {
+				t.Errorf("expected a root span, got parent %v", span.Parent())
+			}
+			return
+		}
+	}
+	t.Fatalf("expected a GET /anchors/{hash} span, got %v", spanNames(recorder.Ended()))
+}
+
+func spanNames(spans []sdktrace.ReadOnlySpan) []string {
+	names := make([]string, len(spans))
+	for i, span := range spans {
+		names[i] = span.Name()
+	}
+	return names
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// apiV1 is the path prefix of the current version of the API. Probes, stats,
+// metrics and the API description are served unversioned.
+const apiV1 = "/v1"
+
+// apiRoute is one operation of a version of the API, at a path relative to its prefix.
+type apiRoute struct {
+	method  string
+	path    string
+	handler http.Handler
+}
+
+// apiVersion is a version of the API: its path prefix and the routes served under it.
+// A later version starts from the routes of the one before and overrides those whose
+// request or response bodies changed, so unchanged operations share their handlers.
+type apiVersion struct {
+	prefix string
+	routes []apiRoute
+}
+
+// override returns the version at prefix serving the routes of v, with routes replacing
+// those of the same method and path. Routes new to the version are appended.
+func (v apiVersion) override(prefix string, routes ...apiRoute) apiVersion {
+	next := apiVersion{prefix: prefix, routes: make([]apiRoute, len(v.routes), len(v.routes)+len(routes))}
+	copy(next.routes, v.routes)
+	for _, route := range routes {
+		i := 0
+		for i < len(next.routes) && (next.routes[i].method != route.method || next.routes[i].path != route.path) {
+			i++
+		}
+		if i == len(next.routes) {
+			next.routes = append(next.routes, route)
+		} else {
+			next.routes[i] = route
+		}
+	}
+	return next
+}
+
+// mount registers the routes of v on r in the order given, as mux serves the first
+// route that matches.
+func (v apiVersion) mount(r *mux.Router) {
+	for _, route := range v.routes {
+		r.Handle(v.prefix+route.path, route.handler).Methods(route.method)
+	}
+}
+
+// mountLegacy registers the routes of v without its prefix, as the API was served
+// before it was versioned. Responses point clients at the prefixed path.
+func (v apiVersion) mountLegacy(r *mux.Router, logger *slog.Logger) {
+	for _, route := range v.routes {
+		r.Handle(route.path, deprecated(v.prefix, logger, route.handler)).Methods(route.method)
+	}
+}
+
+// deprecated marks the responses of next as deprecated in favor of the same path under
+// prefix, and logs each request so operators can see who still calls the old paths.
+func deprecated(prefix string, logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		successor := prefix + r.URL.EscapedPath()
+		w.Header().Set("Deprecation", "true")
+		w.Header().Add("Link", "<"+successor+`>; rel="successor-version"`)
+		logger.WarnContext(r.Context(), "deprecated unversioned path",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("successor", successor),
+		)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// unversioned returns the route template without its version prefix, so limits keyed
+// by template apply to every version of a route.
+func unversioned(template string) string {
+	if rest, ok := strings.CutPrefix(template, apiV1); ok && strings.HasPrefix(rest, "/") {
+		return rest
+	}
+	return template
+}
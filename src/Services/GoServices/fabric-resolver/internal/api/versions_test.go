@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/api/handlers"
+
+	"github.com/gorilla/mux"
+)
+
+func TestVersions_LegacyPathsServeTheSameHandlers(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{Logger: discardLogger})
+
+	hashes := map[string]string{"/v1": strings.Repeat("ab", 32), "": strings.Repeat("cd", 32)}
+	for prefix, hash := range hashes {
+		if rec := postBody(router, prefix+"/anchors", `{"hash":"`+hash+`"}`, true); rec.Code != http.StatusCreated {
+			t.Fatalf("POST %s/anchors: expected 201, got %d: %s", prefix, rec.Code, rec.Body.String())
+		}
+	}
+
+	// An anchor created under either path reads back the same under both
+	for _, hash := range hashes {
+		versioned := getAs(router, "/v1/anchors/"+hash, "")
+		legacy := getAs(router, "/anchors/"+hash, "")
+		if versioned.Code != http.StatusOK || legacy.Code != http.StatusOK {
+			t.Fatalf("expected both paths to find %s, got %d and %d", hash, versioned.Code, legacy.Code)
+		}
+		if versioned.Body.String() != legacy.Body.String() {
+			t.Errorf("expected identical bodies, got %s and %s", versioned.Body.String(), legacy.Body.String())
+		}
+	}
+
+	var page handlers.AnchorPageResponse
+	if err := json.Unmarshal(getAs(router, "/v1/anchors", "").Body.Bytes(), &page); err != nil || page.Total != 2 {
+		t.Errorf("expected /v1/anchors to list both anchors, got %d (%v)", page.Total, err)
+	}
+}
+
+func TestVersions_DeprecationHeaderOnLegacyPathsOnly(t *testing.T) {
+	logs := &accessLogs{}
+	router, _ := newTestRouter(t, RouterOptions{Logger: logs.logger()})
+
+	for path, legacy := range map[string]bool{
+		"/anchors":             true,
+		"/receipts/public-key": true,
+		"/v1/anchors":          false,
+		"/v1/dids":             false,
+		"/health":              false,
+		"/metrics":             false,
+		"/stats/basic":         false,
+		"/openapi.json":        false,
+	} {
+		rec := getAs(router, path, "")
+		if got := rec.Header().Get("Deprecation"); (got == "true") != legacy {
+			t.Errorf("GET %s: unexpected Deprecation header %q", path, got)
+		}
+		if link := rec.Header().Get("Link"); legacy && link != "</v1"+path+`>; rel="successor-version"` {
+			t.Errorf("GET %s: expected a link to the versioned path, got %q", path, link)
+		}
+	}
+
+	warnings := 0
+	dec := json.NewDecoder(&logs.buf)
+	for dec.More() {
+		var record map[string]interface{}
+		if err := dec.Decode(&record); err != nil {
+			t.Fatalf("log record is not JSON: %v", err)
+		}
+		if record["msg"] == "deprecated unversioned path" {
+			warnings++
+			if record["level"] != "WARN" || !strings.HasPrefix(record["successor"].(string), "/v1/") {
+				t.Errorf("unexpected deprecation record %v", record)
+			}
+		}
+	}
+	if warnings != 2 {
+		t.Errorf("expected a warning per legacy request, got %d", warnings)
+	}
+}
+
+func TestAPIVersion_OverrideReusesUnchangedRoutes(t *testing.T) {
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.Write([]byte(name)) })
+	}
+	v1 := apiVersion{prefix: "/v1", routes: []apiRoute{
+		{"GET", "/anchors", handler("v1 list")},
+		{"POST", "/anchors", handler("v1 create")},
+	}}
+	v2 := v1.override("/v2",
+		apiRoute{"POST", "/anchors", handler("v2 create")},
+		apiRoute{"GET", "/proofs", handler("v2 proofs")},
+	)
+
+	router := mux.NewRouter()
+	v1.mount(router)
+	v2.mount(router)
+	for request, want := range map[string]string{
+		"GET /v1/anchors":  "v1 list",
+		"POST /v1/anchors": "v1 create",
+		"GET /v2/anchors":  "v1 list",
+		"POST /v2/anchors": "v2 create",
+		"GET /v2/proofs":   "v2 proofs",
+	} {
+		method, path, _ := strings.Cut(request, " ")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		if rec.Body.String() != want {
+			t.Errorf("%s: expected %q, got %d %q", request, want, rec.Code, rec.Body.String())
+		}
+	}
+	if len(v1.routes) != 2 || v1.routes[1].method != "POST" {
+		t.Error("override changed the routes of the version it started from")
+	}
+}
+
+func TestVersions_StatusListURLKeepsTheVersion(t *testing.T) {
+	router, _ := newTestRouter(t, RouterOptions{AdminToken: "secret", Logger: discardLogger})
+
+	for path, want := range map[string]string{
+		"/v1/status-lists/creds/entries": "http://example.com/v1/status-lists/creds",
+		"/status-lists/legacy/entries":   "http://example.com/status-lists/legacy",
+	} {
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var entry handlers.StatusListEntry
+		if err := json.Unmarshal(rec.Body.Bytes(), &entry); err != nil || entry.StatusListCredential != want {
+			t.Errorf("POST %s: expected the list at %s, got %d %s", path, want, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package apikeys holds the API keys that authorize write requests. Each key has an
+// identifier that is safe to log; the keys themselves are kept only as SHA-256
+// digests and are never returned.
+package apikeys
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// MinKeyLen is the shortest key accepted, so keys cannot be guessed by enumeration.
+const MinKeyLen = 16
+
+// Keys is a set of API keys by identifier.
+type Keys struct {
+	digests map[string][sha256.Size]byte
+}
+
+// NewKeys returns a set of keys, mapped from identifier to key. Every key must be at
+// least MinKeyLen bytes, and no key may be listed twice.
+func NewKeys(keys map[string]string) (*Keys, error) {
+	k := &Keys{digests: make(map[string][sha256.Size]byte, len(keys))}
+	seen := make(map[[sha256.Size]byte]string, len(keys))
+	for id, key := range keys {
+		if strings.TrimSpace(id) == "" {
+			return nil, errors.New("API key with an empty identifier")
+		}
+		if len(key) < MinKeyLen {
+			return nil, fmt.Errorf("API key %q is %d bytes; at least %d are required", id, len(key), MinKeyLen)
+		}
+		digest := sha256.Sum256([]byte(key))
+		if other, ok := seen[digest]; ok {
+			return nil, fmt.Errorf("API keys %q and %q are the same key", other, id)
+		}
+		seen[digest] = id
+		k.digests[id] = digest
+	}
+	return k, nil
+}
+
+// Load reads keys from a JSON file mapping identifiers to keys, and from env, a
+// comma-separated list of id=key pairs or bare keys. A bare key is identified by
+// a prefix of its digest. Keys in env replace those of the same identifier in the
+// file. Empty path and env give an empty set.
+func Load(path, env string) (*Keys, error) {
+	keys := make(map[string]string)
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API keys: %w", err)
+		}
+		if err := json.Unmarshal(data, &keys); err != nil {
+			// The error could quote the file; do not wrap it
+			return nil, fmt.Errorf("failed to parse API keys file %s: expected an object of identifiers to keys", path)
+		}
+	}
+
+	for _, entry := range strings.Split(env, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, key, ok := strings.Cut(entry, "=")
+		if !ok {
+			id, key = DefaultID(entry), entry
+		}
+		keys[strings.TrimSpace(id)] = strings.TrimSpace(key)
+	}
+	return NewKeys(keys)
+}
+
+// DefaultID identifies a key listed without an identifier.
+func DefaultID(key string) string {
+	digest := sha256.Sum256([]byte(key))
+	return "key-" + hex.EncodeToString(digest[:4])
+}
+
+// Len returns the number of keys. A nil set has none.
+func (k *Keys) Len() int {
+	if k == nil {
+		return 0
+	}
+	return len(k.digests)
+}
+
+// Lookup returns the identifier of presented. It compares against every key in
+// constant time, so the response time does not reveal how much of a key matched.
+func (k *Keys) Lookup(presented string) (id string, ok bool) {
+	if k == nil {
+		return "", false
+	}
+	digest := sha256.Sum256([]byte(presented))
+	for candidate, want := range k.digests {
+		if subtle.ConstantTimeCompare(digest[:], want[:]) == 1 {
+			id, ok = candidate, true
+		}
+	}
+	return id, ok
+}
+
+// IDs returns the key identifiers in order.
+func (k *Keys) IDs() []string {
+	if k == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(k.digests))
+	for id := range k.digests {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// String names the keys by identifier only, so a logged set leaks nothing.
+func (k *Keys) String() string {
+	return "apikeys.Keys" + fmt.Sprint(k.IDs())
+}
+
+// GoString keeps %#v from printing the digests.
+func (k *Keys) GoString() string {
+	return k.String()
+}
@@ -0,0 +1,97 @@
+package apikeys
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	keyA = "aaaaaaaaaaaaaaaaaaaaaaaa"
+	keyB = "bbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+func TestLookup(t *testing.T) {
+	k, err := NewKeys(map[string]string{"issuer-a": keyA, "issuer-b": keyB})
+	if err != nil {
+		t.Fatalf("NewKeys failed: %v", err)
+	}
+
+	if id, ok := k.Lookup(keyB); !ok || id != "issuer-b" {
+		t.Errorf("expected issuer-b, got %q, %v", id, ok)
+	}
+	for _, presented := range []string{"", keyA[:len(keyA)-1], keyA + "a", "issuer-a"} {
+		if id, ok := k.Lookup(presented); ok {
+			t.Errorf("expected %q to be rejected, got %s", presented, id)
+		}
+	}
+
+	var empty *Keys
+	if _, ok := empty.Lookup(keyA); ok || empty.Len() != 0 {
+		t.Error("expected a nil set to hold no keys")
+	}
+}
+
+func TestNewKeys_Rejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    map[string]string
+		wantErr string
+	}{
+		{"short key", map[string]string{"a": "short"}, "at least 16"},
+		{"empty id", map[string]string{" ": keyA}, "empty identifier"},
+		{"same key twice", map[string]string{"a": keyA, "b": keyA}, "same key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeys(tt.keys); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoad_FileAndEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-keys.json")
+	if err := os.WriteFile(path, []byte(`{"issuer-a":"`+keyA+`","issuer-b":"`+keyB+`"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := "cccccccccccccccccccccccc"
+	bare := "dddddddddddddddddddddddd"
+	k, err := Load(path, " issuer-b="+rotated+", "+bare+",")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if got := fmt.Sprint(k.IDs()); got != "[issuer-a issuer-b "+DefaultID(bare)+"]" {
+		t.Errorf("unexpected key ids %s", got)
+	}
+	if _, ok := k.Lookup(keyB); ok {
+		t.Error("expected the env key to replace the file key of issuer-b")
+	}
+	if id, ok := k.Lookup(rotated); !ok || id != "issuer-b" {
+		t.Errorf("expected the rotated key to belong to issuer-b, got %q", id)
+	}
+	if id, ok := k.Lookup(bare); !ok || !strings.HasPrefix(id, "key-") {
+		t.Errorf("expected a bare key to get a derived id, got %q", id)
+	}
+
+	if empty, err := Load("", ""); err != nil || empty.Len() != 0 {
+		t.Errorf("expected no keys, got %v, %v", empty, err)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json"), ""); err == nil {
+		t.Error("expected a missing file to fail")
+	}
+}
+
+func TestKeys_DoNotLeak(t *testing.T) {
+	k, _ := NewKeys(map[string]string{"issuer-a": keyA})
+	for _, s := range []string{fmt.Sprint(k), fmt.Sprintf("%v", k), fmt.Sprintf("%#v", k), fmt.Sprintf("%+v", k)} {
+		if strings.Contains(s, keyA) {
+			t.Errorf("formatted keys contain the key: %s", s)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+// Package audit keeps an append-only record of the writes made through the API:
+// who made which change to what, and how it ended, one JSON line per request.
+// The log is separate from the ledger, and rotated by size into files that are
+// never written again.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxBytes is the size past which the log is rotated.
+	DefaultMaxBytes = 10 << 20
+
+	// rotatedTimeFormat suffixes rotated files, sorting them oldest first.
+	rotatedTimeFormat = "20060102T150405.000000000Z"
+)
+
+// Results of an audited request.
+const (
+	ResultSuccess = "success" // the write was made
+	ResultDenied  = "denied"  // the caller was not authenticated or not allowed
+	ResultFailure = "failure" // the write was rejected or failed
+	ResultAttempt = "attempt" // the write was received; in strict mode, its result follows
+)
+
+// Entry is the record of one write request.
+type Entry struct {
+	// Time orders entries; it is unique within a log
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"requestId,omitempty"`
+	// Principal is who made the request: "key:<id>", "token:<subject>",
+	// "cert:<subject>", "admin" or "anonymous"
+	Principal string `json:"principal"`
+	// Admin is set when the request was authorized as an admin
+	Admin    bool   `json:"admin,omitempty"`
+	Tenant   string `json:"tenant,omitempty"`
+	Method   string `json:"method"`
+	Route    string `json:"route"`
+	Resource string `json:"resource,omitempty"`
+	Status   int    `json:"status"`
+	Result   string `json:"result"`
+	SourceIP string `json:"sourceIp"`
+}
+
+// ResultOf classifies the response status of a write.
+func ResultOf(status int) string {
+	switch {
+	case status < 400:
+		return ResultSuccess
+	case status == 401 || status == 403:
+		return ResultDenied
+	}
+	return ResultFailure
+}
+
+// Options bound the files of a Log.
+type Options struct {
+	// MaxBytes is the size past which the log is rotated (default DefaultMaxBytes).
+	MaxBytes int64
+	// MaxFiles is how many rotated files are kept; 0 keeps them all.
+	MaxFiles int
+}
+
+// Log appends entries to a file, rotating it past MaxBytes. Rotated files are
+// named after the log with the time of their last entry appended.
+type Log struct {
+	path string
+	opts Options
+	now  func() time.Time // replaced in tests
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	last time.Time // time of the latest entry, which the next one must follow
+}
+
+// Open opens the log at path for appending, creating it and its directory if missing.
+func Open(path string, opts Options) (*Log, error) {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	l := &Log{path: path, opts: opts, now: time.Now}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	// Entries keep increasing across restarts, so their times can serve as cursors
+	entries, complete, err := readFile(path)
+	if err != nil {
+		l.file.Close()
+		return nil, err
+	}
+	if len(entries) > 0 {
+		l.last = entries[len(entries)-1].Time
+	}
+	// A line cut short by a crash was never acknowledged; new entries start after the last whole one
+	if complete < l.size {
+		if err := l.file.Truncate(complete); err != nil {
+			l.file.Close()
+			return nil, fmt.Errorf("failed to drop partial audit entry: %w", err)
+		}
+		l.size = complete
+	}
+	return l, nil
+}
+
+func (l *Log) open() error {
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	l.file, l.size = f, info.Size()
+	return nil
+}
+
+// Append writes e to the log and syncs it to disk. Its Time is set, after that of
+// the previous entry; the error is returned to callers that must not proceed
+// without an audit record.
+func (l *Log) Append(e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return errors.New("audit log is closed")
+	}
+
+	e.Time = l.now().UTC()
+	if !e.Time.After(l.last) {
+		e.Time = l.last.Add(time.Nanosecond)
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	if l.size > 0 && l.size+int64(len(line)) > l.opts.MaxBytes {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	l.last = e.Time
+	return nil
+}
+
+// rotate moves the full log aside, starts a new one and drops the oldest rotated
+// files past MaxFiles.
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+	l.file = nil
+	rotated := l.path + "." + l.last.Format(rotatedTimeFormat)
+	if err := os.Rename(l.path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	// Rotated files are kept read-only; only the current file is appended to
+	os.Chmod(rotated, 0o400)
+	if err := l.open(); err != nil {
+		return err
+	}
+
+	if l.opts.MaxFiles > 0 {
+		files, err := l.rotatedFiles()
+		if err != nil {
+			return err
+		}
+		for len(files) > l.opts.MaxFiles {
+			if err := os.Remove(files[0]); err != nil {
+				return fmt.Errorf("failed to remove rotated audit log: %w", err)
+			}
+			files = files[1:]
+		}
+	}
+	return nil
+}
+
+// rotatedFiles returns the rotated files of the log, oldest first.
+func (l *Log) rotatedFiles() ([]string, error) {
+	files, err := filepath.Glob(l.path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	var rotated []string
+	for _, file := range files {
+		if _, err := time.Parse(rotatedTimeFormat, strings.TrimPrefix(file, l.path+".")); err == nil {
+			rotated = append(rotated, file)
+		}
+	}
+	sort.Strings(rotated)
+	return rotated, nil
+}
+
+// Read returns up to limit entries written after since, oldest first, and whether
+// more follow. Rotated files are read as well as the current one.
+func (l *Log) Read(since time.Time, limit int) ([]Entry, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	files, err := l.rotatedFiles()
+	if err != nil {
+		return nil, false, err
+	}
+	files = append(files, l.path)
+
+	var entries []Entry
+	for _, file := range files {
+		fileEntries, _, err := readFile(file)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, e := range fileEntries {
+			if !e.Time.After(since) {
+				continue
+			}
+			if len(entries) == limit {
+				return entries, true, nil
+			}
+			entries = append(entries, e)
+		}
+	}
+	return entries, false, nil
+}
+
+// readFile decodes the entries of one file of the log, and returns the length of
+// the whole lines read. A line cut short by a crash is left out; a missing file
+// has no entries.
+func readFile(path string) ([]Entry, int64, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	var complete int64
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			var e Entry
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, 0, fmt.Errorf("corrupt audit log %s: %w", path, err)
+			}
+			entries = append(entries, e)
+			complete += int64(len(line))
+		}
+		if err == io.EOF {
+			return entries, complete, nil
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read audit log: %w", err)
+		}
+	}
+}
+
+// Close closes the log; Append fails afterwards.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
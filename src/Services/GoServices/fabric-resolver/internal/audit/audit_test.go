@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLog_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	l, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer l.Close()
+
+	entry := Entry{RequestID: "req-1", Principal: "key:ci", Method: "POST", Route: "/v1/anchors",
+		Resource: strings.Repeat("ab", 32), Status: 201, Result: ResultSuccess, SourceIP: "192.0.2.1"}
+	if err := l.Append(entry); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil || !strings.HasSuffix(string(data), "}\n") {
+		t.Fatalf("expected one JSON line, got %q", data)
+	}
+	for _, field := range []string{"time", "requestId", "principal", "method", "route", "resource", "status", "result", "sourceIp"} {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("expected %s in the entry, got %s", field, data)
+		}
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the log to be private to the server, got %v", info.Mode())
+	}
+}
+
+func TestLog_ReadPagesBySince(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := Open(path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Entries made within the same clock tick still get distinct times
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	for _, id := range []string{"a", "b", "c"} {
+		if err := l.Append(Entry{RequestID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page, more, err := l.Read(time.Time{}, 2)
+	if err != nil || len(page) != 2 || !more || page[0].RequestID != "a" || !page[1].Time.After(page[0].Time) {
+		t.Fatalf("expected the first two entries and more, got %+v, %v, %v", page, more, err)
+	}
+	page, more, err = l.Read(page[1].Time, 2)
+	if err != nil || len(page) != 1 || more || page[0].RequestID != "c" {
+		t.Fatalf("expected the last entry, got %+v, %v, %v", page, more, err)
+	}
+
+	// Times keep increasing across a restart
+	last := page[0].Time
+	l.Close()
+	if l, err = Open(path, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	l.now = func() time.Time { return now }
+	l.Append(Entry{RequestID: "d"})
+	if page, _, _ := l.Read(last, 10); len(page) != 1 || page[0].RequestID != "d" {
+		t.Errorf("expected the entry after the restart to follow the others, got %+v", page)
+	}
+}
+
+func TestLog_RotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+	l, err := Open(path, Options{MaxBytes: 300, MaxFiles: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// About 190 bytes each, so every entry after the first rotates the log
+	for i := 0; i < 12; i++ {
+		if err := l.Append(Entry{RequestID: strings.Repeat("x", 60), Route: "/v1/anchors", Status: 201}); err != nil {
+			t.Fatalf("Append %d failed: %v", i, err)
+		}
+	}
+
+	rotated, _ := l.rotatedFiles()
+	if len(rotated) != 2 {
+		t.Fatalf("expected the two newest rotated files to be kept, got %v", rotated)
+	}
+	for _, file := range append(rotated, path) {
+		info, err := os.Stat(file)
+		if err != nil || info.Size() > 300 {
+			t.Errorf("expected %s to stay within 300 bytes, got %v", file, info)
+		}
+	}
+	if info, _ := os.Stat(rotated[0]); info.Mode().Perm() != 0o400 {
+		t.Errorf("expected rotated files to be read-only, got %v", info.Mode())
+	}
+
+	// Reads span the rotated files, oldest first
+	entries, _, err := l.Read(time.Time{}, 100)
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("expected the entries of the three files, got %d: %v", len(entries), err)
+	}
+	for i := 1; i < len(entries); i++ {
+		if !entries[i].Time.After(entries[i-1].Time) {
+			t.Fatalf("expected entries in order, got %v before %v", entries[i-1].Time, entries[i].Time)
+		}
+	}
+}
+
+func TestOpen_DropsPartialEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	if err := os.WriteFile(path, []byte(`{"requestId":"a","time":"2026-01-02T03:04:05Z"}`+"\n"+`{"requestId":"b","ti`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	l, err := Open(path, Options{})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer l.Close()
+	if err := l.Append(Entry{RequestID: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, _, err := l.Read(time.Time{}, 10)
+	if err != nil || len(entries) != 2 || entries[0].RequestID != "a" || entries[1].RequestID != "c" {
+		t.Errorf("expected the partial entry to be dropped, got %+v, %v", entries, err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package buildinfo describes the running binary and process: its version, the
+// commit it was built from and how long it has been up.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Version, Commit and BuildDate are set at build time:
+//
+//	go build -ldflags "-X fabric-resolver/internal/buildinfo.Version=v1.4.0 \
+//	  -X fabric-resolver/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X fabric-resolver/internal/buildinfo.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Without them Commit and BuildDate fall back to the revision and commit time the
+// go command stamps into binaries built from a checkout, and then to "dev".
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// started approximates the process start, as the package is initialized at startup.
+var started = time.Now()
+
+// Build identifies the binary.
+type Build struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+}
+
+// Info is the build and uptime of the process.
+type Info struct {
+	Build
+	GoVersion     string    `json:"goVersion"`
+	StartedAt     time.Time `json:"startedAt"`
+	UptimeSeconds float64   `json:"uptimeSeconds"`
+}
+
+// Current returns the build of the binary.
+func Current() Build {
+	return Build{
+		Version:   orDev(Version),
+		Commit:    orDev(stamped(Commit, "vcs.revision")),
+		BuildDate: orDev(stamped(BuildDate, "vcs.time")),
+	}
+}
+
+// Get returns the build of the binary and the uptime of the process so far.
+func Get() Info {
+	return Info{
+		Build:         Current(),
+		GoVersion:     runtime.Version(),
+		StartedAt:     started.UTC().Truncate(time.Second),
+		UptimeSeconds: time.Since(started).Truncate(time.Millisecond).Seconds(),
+	}
+}
+
+// stamped returns value, or the build setting key when value was not set.
+func stamped(value, key string) string {
+	if value != "" {
+		return value
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == key {
+				return setting.Value
+			}
+		}
+	}
+	return ""
+}
+
+func orDev(value string) string {
+	if value == "" {
+		return "dev"
+	}
+	return value
+}
@@ -0,0 +1,19 @@
+package buildinfo
+
+import "testing"
+
+func TestCurrent(t *testing.T) {
+	// Test binaries carry no VCS stamp, so nothing is injected here
+	if got := Current(); got != (Build{Version: "dev", Commit: "dev", BuildDate: "dev"}) {
+		t.Errorf("expected every field to default to dev, got %+v", got)
+	}
+
+	defer func(version, commit, date string) { Version, Commit, BuildDate = version, commit, date }(Version, Commit, BuildDate)
+	Version, Commit, BuildDate = "v1.4.0", "3f9c2ab", "2025-01-01T10:00:00Z"
+	if got := Current(); got != (Build{Version: "v1.4.0", Commit: "3f9c2ab", BuildDate: "2025-01-01T10:00:00Z"}) {
+		t.Errorf("expected the injected build, got %+v", got)
+	}
+	if info := Get(); info.Build != Current() || info.GoVersion == "" {
+		t.Errorf("expected Get to carry the build, got %+v", info)
+	}
+}
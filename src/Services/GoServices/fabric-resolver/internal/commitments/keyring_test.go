@@ -0,0 +1,132 @@
+package commitments
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	keyA = bytes.Repeat([]byte{0xa1}, 32)
+	keyB = bytes.Repeat([]byte{0xb2}, 32)
+)
+
+func TestKeyring_TenantIsolation(t *testing.T) {
+	k, err := NewKeyring(map[string][]byte{"tenant-a": keyA, "tenant-b": keyB})
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+
+	doc := []byte(`{"name":"Alice","age":30}`)
+	a, err := k.Commit("tenant-a", doc)
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	b, _ := k.Commit("tenant-b", doc)
+	if a == b {
+		t.Error("expected tenants to get different commitments to the same document")
+	}
+
+	// Key order does not change the commitment
+	if again, _ := k.Commit("tenant-a", []byte(`{"age":30, "name":"Alice"}`)); again != a {
+		t.Errorf("expected the canonical document to commit the same, got %s and %s", a, again)
+	}
+
+	if _, err := k.Commit("tenant-c", doc); !errors.Is(err, ErrUnknownTenant) {
+		t.Errorf("expected ErrUnknownTenant, got %v", err)
+	}
+	var nilKeyring *Keyring
+	if _, err := nilKeyring.Commit("tenant-a", doc); !errors.Is(err, ErrUnknownTenant) {
+		t.Errorf("expected a nil keyring to have no tenants, got %v", err)
+	}
+}
+
+func TestKeyring_RotationChangesCommitment(t *testing.T) {
+	doc := []byte(`{"id":1}`)
+	before, _ := NewKeyring(map[string][]byte{"tenant-a": keyA})
+	after, _ := NewKeyring(map[string][]byte{"tenant-a": keyB})
+
+	c1, _ := before.Commit("tenant-a", doc)
+	c2, _ := after.Commit("tenant-a", doc)
+	if c1 == "" || c1 == c2 {
+		t.Errorf("expected a rotated key to produce a new commitment, got %q and %q", c1, c2)
+	}
+}
+
+func TestKeyring_Verify(t *testing.T) {
+	doc := []byte(`{"id":1}`)
+	k, _ := NewKeyring(map[string][]byte{"tenant-a": keyA})
+	c, _ := k.Commit("tenant-a", doc)
+
+	if ok, err := k.Verify("tenant-a", doc, c); !ok || err != nil {
+		t.Errorf("expected the commitment to verify, got %v %v", ok, err)
+	}
+	if ok, err := k.Verify("tenant-a", []byte(`{"id":2}`), c); ok || err != nil {
+		t.Errorf("expected another document not to verify, got %v %v", ok, err)
+	}
+	if _, err := k.Verify("tenant-a", doc, "not-hex"); err == nil {
+		t.Error("expected a malformed commitment to be rejected")
+	}
+	if _, err := k.Verify("tenant-b", doc, c); !errors.Is(err, ErrUnknownTenant) {
+		t.Errorf("expected ErrUnknownTenant, got %v", err)
+	}
+}
+
+func TestKeyring_RejectsShortKeys(t *testing.T) {
+	short := bytes.Repeat([]byte{0x5e}, 31)
+	_, err := NewKeyring(map[string][]byte{"tenant-a": short})
+	if err == nil {
+		t.Fatal("expected a 31-byte key to be rejected")
+	}
+	if !strings.Contains(err.Error(), "tenant-a") || strings.Contains(err.Error(), string(short)) {
+		t.Errorf("expected the error to name the tenant but not the key: %v", err)
+	}
+
+	if _, err := Load("", "tenant-a="+base64.StdEncoding.EncodeToString(short)); err == nil {
+		t.Error("expected Load to reject a short key")
+	}
+}
+
+func TestLoad_FileAndEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	file := fmt.Sprintf(`{"tenant-a": %q, "tenant-b": %q}`, base64.StdEncoding.EncodeToString(keyA), base64.StdEncoding.EncodeToString(keyA))
+	if err := os.WriteFile(path, []byte(file), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The env entry replaces tenant-b's key from the file
+	k, err := Load(path, "tenant-b="+base64.StdEncoding.EncodeToString(keyB))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := k.Tenants(); len(got) != 2 || got[0] != "tenant-a" || got[1] != "tenant-b" {
+		t.Fatalf("unexpected tenants %v", got)
+	}
+
+	want, _ := NewKeyring(map[string][]byte{"tenant-b": keyB})
+	doc := []byte(`{}`)
+	got, _ := k.Commit("tenant-b", doc)
+	if expected, _ := want.Commit("tenant-b", doc); got != expected {
+		t.Error("expected the env key to override the file key")
+	}
+
+	for _, bad := range []string{"tenant-a", "tenant-a=not base64!"} {
+		if _, err := Load("", bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestKeyring_StringHidesKeys(t *testing.T) {
+	k, _ := NewKeyring(map[string][]byte{"tenant-a": keyA})
+	for _, s := range []string{fmt.Sprint(k), fmt.Sprintf("%v", k), fmt.Sprintf("%#v", k), fmt.Sprintf("%+v", k)} {
+		if !strings.Contains(s, "tenant-a") || strings.Contains(s, string(keyA)) || strings.Contains(s, "161") {
+			t.Errorf("unexpected keyring representation %q", s)
+		}
+	}
+}
@@ -2,102 +2,690 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
+
+	"fabric-resolver/internal/infrastructure/fabric"
 )
 
 type Config struct {
 	Server ServerConfig
-	Fabric FabricConfig
+	Ledger fabric.Config
 }
 
 type ServerConfig struct {
+	// LogLevel is the least severe level logged: debug, info, warn or error
+	LogLevel slog.Level
+
+	// AccessLogExclude are the paths whose successful requests are left out of the
+	// access log, one in every AccessLogSample of them excepted (0 logs none)
+	AccessLogExclude []string
+	AccessLogSample  int
+
+	// Listen is tcp://host:port or unix:///path; empty listens on Port on every
+	// interface. A unix socket is created with ListenSocketMode and removed on shutdown.
+	Listen           string
+	ListenSocketMode os.FileMode
+
 	Port         int
+	GRPCPort     int // 0, the default, disables the gRPC server
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
-	IdleTimeout  time.Duration
-}
+	IdleTimeout  time.Duration // how long keep-alive connections wait for their next request
+
+	// ReadHeaderTimeout bounds reading request headers; MaxHeaderBytes caps their size
+	ReadHeaderTimeout time.Duration
+	MaxHeaderBytes    int
+	// DisableHTTP2 serves HTTP/1.1 only, also over TLS
+	DisableHTTP2 bool
+
+	// TLSCertFile and TLSKeyFile make the HTTP server terminate TLS itself; empty serves plain HTTP.
+	// TLSClientCA additionally requires client certificates signed by that CA (mTLS).
+	TLSCertFile string
+	TLSKeyFile  string
+	TLSClientCA string
+
+	// ShutdownTimeout is how long in-flight requests may take to finish after SIGINT or SIGTERM
+	ShutdownTimeout time.Duration
+
+	// ReadOnly starts the server refusing writes, e.g. during a ledger migration, until
+	// an admin switches it off; refused writes are told to retry after ReadOnlyRetryAfter
+	ReadOnly           bool
+	ReadOnlyRetryAfter time.Duration
+
+	// AdminToken guards administrative endpoints; empty disables them
+	AdminToken string
+
+	// APIKeysFile is a JSON file of key ids to API keys; APIKeys are id=key pairs or bare
+	// keys that replace keys of the same id in the file. No keys leaves writes open.
+	APIKeysFile string
+	APIKeys     string
+	// APIKeysProtectReads requires an API key on reads as well
+	APIKeysProtectReads bool
+
+	// Tenants share the resolver with a file ledger each, named by requests in
+	// X-Tenant-ID; empty serves one ledger to every caller. TenantAPIKeys maps API
+	// key ids to the tenant their requests are held to, which also picks their
+	// commitment key.
+	Tenants       []string
+	TenantAPIKeys map[string]string
+
+	// JWTJWKSURL turns on JWT bearer authentication with keys from that JWKS; tokens must
+	// carry JWTIssuer and JWTAudience. Empty leaves routes unscoped.
+	JWTJWKSURL  string
+	JWTIssuer   string
+	JWTAudience string
+	// JWTJWKSRefresh is how long fetched JWKS keys are cached
+	JWTJWKSRefresh time.Duration
+	// JWTLeeway is the clock skew allowed on token expiry
+	JWTLeeway time.Duration
+
+	// DIDMethods is the allow-list of DID methods accepted on create; empty uses the defaults
+	DIDMethods []string
+
+	// DIDMaxVerificationMethods caps the verification methods of one DID document
+	DIDMaxVerificationMethods int
+
+	// AnchorMetadataMaxBytes caps the canonical size of anchor metadata
+	AnchorMetadataMaxBytes int
+
+	// AnchorVerifyBatchMax caps the hashes of one verify-batch request
+	AnchorVerifyBatchMax int
+
+	// AnchorDocumentMaxBytes caps the body of POST /anchors/from-document
+	AnchorDocumentMaxBytes int
+
+	// DIDUpdateNonceTTL is how long a DID update nonce is accepted
+	DIDUpdateNonceTTL time.Duration
+
+	// DIDWebResolution enables fetching did:web documents that are not on the ledger
+	DIDWebResolution bool
+	DIDWebTimeout    time.Duration
+
+	// WebhookMaxAttempts and WebhookTimeout bound the delivery of one webhook event
+	WebhookMaxAttempts int
+	WebhookTimeout     time.Duration
+
+	// ReceiptKeyPath is the Ed25519 key signing anchor receipts, created if absent;
+	// empty stores it next to the file ledger
+	ReceiptKeyPath string
 
-type FabricConfig struct {
-	NetworkConfig string
-	ChannelID     string
-	ChaincodeName string
-	OrgName       string
-	UserName      string
-	MspID         string
+	// CommitmentKeysFile is a JSON file of tenant ids to base64 HMAC keys
+	CommitmentKeysFile string
+	// CommitmentKeys are tenant=base64key pairs that replace keys of the same tenant in the file
+	CommitmentKeys string
+
+	// IdempotencyFilePath persists Idempotency-Key responses; empty stores them next to the file ledger
+	IdempotencyFilePath string
+	// IdempotencyRetention is how long a key replays its response
+	IdempotencyRetention time.Duration
+
+	// AuditLogPath is the append-only log of write requests; empty stores it next to
+	// the file ledger, "none" disables it
+	AuditLogPath string
+	// AuditMaxBytes is the size past which the audit log is rotated, and AuditMaxFiles
+	// how many rotated files are kept (0 keeps them all)
+	AuditMaxBytes int64
+	AuditMaxFiles int
+	// AuditStrict fails writes whose audit entry cannot be appended with 503
+	AuditStrict bool
+
+	// CORSAllowedOrigins are the browser origins allowed to call the API: exact origins,
+	// "*.example.com" subdomain patterns or "*"; empty sends no CORS headers
+	CORSAllowedOrigins []string
+	// CORSAllowedMethods and CORSAllowedHeaders answer preflights; empty uses the defaults
+	CORSAllowedMethods []string
+	CORSAllowedHeaders []string
+	// CORSAllowCredentials lets browsers send credentials cross-origin
+	CORSAllowCredentials bool
+	// CORSMaxAge is how long browsers cache a preflight answer
+	CORSMaxAge time.Duration
+
+	// RateLimitReadRPS and RateLimitWriteRPS are the sustained requests per second of
+	// one client on reads and writes, with bursts up to the matching Burst; a rate of 0
+	// turns limiting off for that class
+	RateLimitReadRPS    float64
+	RateLimitReadBurst  int
+	RateLimitWriteRPS   float64
+	RateLimitWriteBurst int
+	// RateLimitIdleTTL is how long an idle client's limiter state is kept
+	RateLimitIdleTTL time.Duration
+
+	// RequestMaxBytes caps request bodies; RequestBatchMaxBytes the batch endpoints
+	RequestMaxBytes      int64
+	RequestBatchMaxBytes int64
+	// RequestTimeout bounds the handling of a request; RequestBatchTimeout the batch
+	// endpoints, defaulting to RequestTimeout
+	RequestTimeout      time.Duration
+	RequestBatchTimeout time.Duration
+	// RequestStrictJSON rejects anchor and DID bodies with unknown fields
+	RequestStrictJSON bool
+
+	// DebugPprof serves net/http/pprof under /debug/pprof/, on its own listener at
+	// DebugPprofAddr (localhost by default) or, with DebugPprofShared, on the main
+	// listener behind the admin auth
+	DebugPprof       bool
+	DebugPprofAddr   string
+	DebugPprofShared bool
 }
 
+// Load reads the configuration from the environment and, when CONFIG_FILE names
+// one, a YAML or JSON config file.
 func Load() (*Config, error) {
+	return LoadFile(os.Getenv("CONFIG_FILE"))
+}
+
+// LoadFile reads the configuration from the config file at path, if not empty,
+// and the environment. Environment variables override the file, and defaults
+// fill in what neither sets. Malformed and invalid settings are all reported
+// together as Errors; CONFIG_LENIENT=true instead falls back to the default for
+// values that do not parse.
+func LoadFile(path string) (*Config, error) {
+	return LoadWithOverrides(path, nil)
+}
+
+// LoadWithOverrides is LoadFile with overrides, keyed by environment variable name,
+// taking precedence over the environment as command-line flags do. Empty values
+// leave the setting to the environment and the file.
+func LoadWithOverrides(path string, overrides map[string]string) (*Config, error) {
+	lenient, _ := strconv.ParseBool(os.Getenv("CONFIG_LENIENT"))
+	e := env{overrides: overrides, lenient: lenient}
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		e.file = values
+	}
+
 	cfg := &Config{
 		Server: ServerConfig{
-			Port:         getEnvAsInt("SERVER_PORT", 8080),
-			ReadTimeout:  getEnvAsDuration("SERVER_READ_TIMEOUT", 15*time.Second),
-			WriteTimeout: getEnvAsDuration("SERVER_WRITE_TIMEOUT", 15*time.Second),
-			IdleTimeout:  getEnvAsDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
-		},
-		Fabric: FabricConfig{
-			NetworkConfig: getEnv("FABRIC_NETWORK_CONFIG", "./config/network.yaml"),
-			ChannelID:     getEnv("FABRIC_CHANNEL_ID", "mychannel"),
-			ChaincodeName: getEnv("FABRIC_CHAINCODE_NAME", "verifiable-credentials"),
-			OrgName:       getEnv("FABRIC_ORG_NAME", "Org1"),
-			UserName:      getEnv("FABRIC_USER_NAME", "Admin"),
-			MspID:         getEnv("FABRIC_MSP_ID", "Org1MSP"),
+			AccessLogExclude: e.getEnvAsList("ACCESS_LOG_EXCLUDE"),
+			AccessLogSample:  e.getEnvAsInt("ACCESS_LOG_SAMPLE", 0),
+
+			Listen:           e.getEnv("SERVER_LISTEN", ""),
+			ListenSocketMode: e.getEnvAsFileMode("SERVER_LISTEN_SOCKET_MODE", 0o660),
+
+			Port:         e.getEnvAsInt("SERVER_PORT", 8080),
+			GRPCPort:     e.getEnvAsInt("GRPC_PORT", 0),
+			ReadTimeout:  e.getEnvAsDuration("SERVER_READ_TIMEOUT", 15*time.Second),
+			WriteTimeout: e.getEnvAsDuration("SERVER_WRITE_TIMEOUT", 15*time.Second),
+			IdleTimeout:  e.getEnvAsDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
+
+			ReadHeaderTimeout: e.getEnvAsDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+			MaxHeaderBytes:    e.getEnvAsInt("SERVER_MAX_HEADER_BYTES", 1<<20),
+			DisableHTTP2:      e.getEnvAsBool("SERVER_DISABLE_HTTP2", false),
+
+			TLSCertFile: e.getEnv("SERVER_TLS_CERT_FILE", ""),
+			TLSKeyFile:  e.getEnv("SERVER_TLS_KEY_FILE", ""),
+			TLSClientCA: e.getEnv("SERVER_TLS_CLIENT_CA", ""),
+
+			ShutdownTimeout: e.getEnvAsDuration("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+
+			ReadOnly:           e.getEnvAsBool("READ_ONLY", false),
+			ReadOnlyRetryAfter: e.getEnvAsDuration("READ_ONLY_RETRY_AFTER", time.Minute),
+
+			AdminToken: e.getEnv("ADMIN_TOKEN", ""),
+
+			APIKeysFile:         e.getEnv("API_KEYS_FILE", ""),
+			APIKeys:             e.getEnv("API_KEYS", ""),
+			APIKeysProtectReads: e.getEnvAsBool("API_KEYS_PROTECT_READS", false),
+
+			Tenants:       e.getEnvAsList("TENANTS"),
+			TenantAPIKeys: e.getEnvAsMap("TENANT_API_KEYS"),
+
+			JWTJWKSURL:     e.getEnv("JWT_JWKS_URL", ""),
+			JWTIssuer:      e.getEnv("JWT_ISSUER", ""),
+			JWTAudience:    e.getEnv("JWT_AUDIENCE", ""),
+			JWTJWKSRefresh: e.getEnvAsDuration("JWT_JWKS_REFRESH", 10*time.Minute),
+			JWTLeeway:      e.getEnvAsDuration("JWT_LEEWAY", 30*time.Second),
+
+			DIDMethods: e.getEnvAsList("DID_ALLOWED_METHODS"),
+
+			DIDMaxVerificationMethods: e.getEnvAsInt("DID_MAX_VERIFICATION_METHODS", 20),
+
+			AnchorMetadataMaxBytes: e.getEnvAsInt("ANCHOR_METADATA_MAX_BYTES", 4096),
+			AnchorVerifyBatchMax:   e.getEnvAsInt("ANCHOR_VERIFY_BATCH_MAX", 256),
+			AnchorDocumentMaxBytes: e.getEnvAsInt("ANCHOR_DOCUMENT_MAX_BYTES", 1<<20),
+
+			DIDUpdateNonceTTL: e.getEnvAsDuration("DID_UPDATE_NONCE_TTL", 5*time.Minute),
+
+			DIDWebResolution: e.getEnvAsBool("DID_WEB_RESOLUTION", true),
+			DIDWebTimeout:    e.getEnvAsDuration("DID_WEB_TIMEOUT", 5*time.Second),
+
+			WebhookMaxAttempts: e.getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 5),
+			WebhookTimeout:     e.getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+
+			ReceiptKeyPath: e.getEnv("RECEIPT_KEY_PATH", ""),
+
+			CommitmentKeysFile: e.getEnv("COMMITMENT_KEYS_FILE", ""),
+			CommitmentKeys:     e.getEnv("COMMITMENT_KEYS", ""),
+
+			IdempotencyFilePath:  e.getEnv("IDEMPOTENCY_FILE_PATH", ""),
+			IdempotencyRetention: e.getEnvAsDuration("IDEMPOTENCY_RETENTION", 24*time.Hour),
+
+			AuditLogPath:  e.getEnv("AUDIT_LOG_PATH", ""),
+			AuditMaxBytes: int64(e.getEnvAsInt("AUDIT_MAX_BYTES", 10<<20)),
+			AuditMaxFiles: e.getEnvAsInt("AUDIT_MAX_FILES", 10),
+			AuditStrict:   e.getEnvAsBool("AUDIT_STRICT", false),
+
+			CORSAllowedOrigins:   e.getEnvAsList("CORS_ALLOWED_ORIGINS"),
+			CORSAllowedMethods:   e.getEnvAsList("CORS_ALLOWED_METHODS"),
+			CORSAllowedHeaders:   e.getEnvAsList("CORS_ALLOWED_HEADERS"),
+			CORSAllowCredentials: e.getEnvAsBool("CORS_ALLOW_CREDENTIALS", false),
+			CORSMaxAge:           e.getEnvAsDuration("CORS_MAX_AGE", 10*time.Minute),
+
+			RateLimitReadRPS:    e.getEnvAsFloat("RATE_LIMIT_READ_RPS", 50),
+			RateLimitReadBurst:  e.getEnvAsInt("RATE_LIMIT_READ_BURST", 100),
+			RateLimitWriteRPS:   e.getEnvAsFloat("RATE_LIMIT_WRITE_RPS", 10),
+			RateLimitWriteBurst: e.getEnvAsInt("RATE_LIMIT_WRITE_BURST", 20),
+			RateLimitIdleTTL:    e.getEnvAsDuration("RATE_LIMIT_IDLE_TTL", 10*time.Minute),
+
+			RequestMaxBytes:      int64(e.getEnvAsInt("REQUEST_MAX_BYTES", 1<<20)),
+			RequestBatchMaxBytes: int64(e.getEnvAsInt("REQUEST_BATCH_MAX_BYTES", 8<<20)),
+			RequestTimeout:       e.getEnvAsDuration("REQUEST_TIMEOUT", 10*time.Second),
+			RequestBatchTimeout:  e.getEnvAsDuration("REQUEST_BATCH_TIMEOUT", 0),
+			RequestStrictJSON:    e.getEnvAsBool("REQUEST_STRICT_JSON", false),
+
+			DebugPprof:       e.getEnvAsBool("DEBUG_PPROF", false),
+			DebugPprofAddr:   e.getEnv("DEBUG_PPROF_ADDR", "localhost:6060"),
+			DebugPprofShared: e.getEnvAsBool("DEBUG_PPROF_SHARED", false),
 		},
+		Ledger: fabric.LoadConfig(e.lookup),
+	}
+	cfg.Server.LogLevel = e.getEnvAsLogLevel("LOG_LEVEL", slog.LevelInfo)
+
+	// Probes are left out by default; ACCESS_LOG_EXCLUDE=none logs them too
+	switch exclude := cfg.Server.AccessLogExclude; {
+	case len(exclude) == 0:
+		cfg.Server.AccessLogExclude = []string{"/health", "/livez", "/readyz", "/metrics"}
+	case len(exclude) == 1 && exclude[0] == "none":
+		cfg.Server.AccessLogExclude = nil
+	}
+
+	// fabric.LoadConfig reads the ledger's strings; its numbers are parsed here to report them
+	cfg.Ledger.RetryMaxAttempts = e.getEnvAsInt("LEDGER_RETRY_MAX_ATTEMPTS", 0)
+	cfg.Ledger.ReapInterval = e.getEnvAsDuration("LEDGER_REAP_INTERVAL", time.Minute)
+	cfg.Ledger.OpenAttempts = e.getEnvAsInt("LEDGER_OPEN_ATTEMPTS", 5)
+	cfg.Ledger.OpenRetryDelay = e.getEnvAsDuration("LEDGER_OPEN_RETRY_DELAY", 2*time.Second)
+
+	ledgerPath := cfg.Ledger.FilePath
+	if ledgerPath == "" {
+		ledgerPath = "data/ledger.json"
+	}
+	if cfg.Server.IdempotencyFilePath == "" {
+		cfg.Server.IdempotencyFilePath = filepath.Join(filepath.Dir(ledgerPath), "idempotency.json")
+	}
+	if cfg.Server.ReceiptKeyPath == "" {
+		cfg.Server.ReceiptKeyPath = filepath.Join(filepath.Dir(ledgerPath), "receipt-key.pem")
+	}
+	switch cfg.Server.AuditLogPath {
+	case "":
+		cfg.Server.AuditLogPath = filepath.Join(filepath.Dir(ledgerPath), "audit.log")
+	case "none":
+		cfg.Server.AuditLogPath = ""
 	}
 
-	if err := cfg.validate(); err != nil {
-		return nil, err
+	if errs := append(e.errs, cfg.validate()...); len(errs) > 0 {
+		return nil, errs
 	}
 
 	return cfg, nil
 }
 
-func (c *Config) validate() error {
+// Errors lists every malformed or invalid setting found by Load.
+type Errors []error
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d configuration errors: %s", len(e), strings.Join(msgs, "; "))
+}
+
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// redacted replaces the secrets in Redacted copies.
+const redacted = "[REDACTED]"
+
+// Redacted returns a copy of c that is safe to log: the admin token, API keys
+// and commitment keys are masked. Paths to files holding secrets are kept.
+func (c *Config) Redacted() Config {
+	r := *c
+	for _, secret := range []*string{&r.Server.AdminToken, &r.Server.APIKeys, &r.Server.CommitmentKeys} {
+		if *secret != "" {
+			*secret = redacted
+		}
+	}
+	r.Ledger.Logger = nil
+	return r
+}
+
+// validate reports every invalid setting.
+func (c *Config) validate() Errors {
+	var errs Errors
 	if c.Server.Port <= 0 || c.Server.Port > 65535 {
-		return fmt.Errorf("invalid server port: %d", c.Server.Port)
+		errs = append(errs, fmt.Errorf("invalid server port: %d", c.Server.Port))
+	}
+	if _, _, err := c.Server.ListenAddr(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Server.GRPCPort < 0 || c.Server.GRPCPort > 65535 {
+		errs = append(errs, fmt.Errorf("invalid gRPC port: %d", c.Server.GRPCPort))
+	}
+	if c.Server.ReadHeaderTimeout <= 0 || c.Server.IdleTimeout < 0 {
+		errs = append(errs, fmt.Errorf("invalid connection timeouts: %s to read headers, %s idle", c.Server.ReadHeaderTimeout, c.Server.IdleTimeout))
+	}
+	if c.Server.MaxHeaderBytes <= 0 {
+		errs = append(errs, fmt.Errorf("invalid max header bytes: %d", c.Server.MaxHeaderBytes))
+	}
+	if c.Server.GRPCPort == c.Server.Port {
+		errs = append(errs, fmt.Errorf("gRPC port %d is already used by the HTTP server", c.Server.GRPCPort))
+	}
+	if _, err := c.Server.TLSConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.Server.validateJWT(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Server.AccessLogSample < 0 {
+		errs = append(errs, fmt.Errorf("invalid access log sample rate: %d", c.Server.AccessLogSample))
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid shutdown timeout: %s", c.Server.ShutdownTimeout))
+	}
+	if c.Server.ReadOnlyRetryAfter <= 0 {
+		errs = append(errs, fmt.Errorf("invalid read-only retry after: %s", c.Server.ReadOnlyRetryAfter))
+	}
+	if c.Server.DIDMaxVerificationMethods <= 0 {
+		errs = append(errs, fmt.Errorf("invalid DID verification method limit: %d", c.Server.DIDMaxVerificationMethods))
+	}
+	if c.Server.DIDUpdateNonceTTL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid DID update nonce TTL: %s", c.Server.DIDUpdateNonceTTL))
+	}
+	if c.Server.IdempotencyRetention <= 0 {
+		errs = append(errs, fmt.Errorf("invalid idempotency retention: %s", c.Server.IdempotencyRetention))
+	}
+	if c.Server.AuditMaxBytes <= 0 {
+		errs = append(errs, fmt.Errorf("invalid audit log max bytes: %d", c.Server.AuditMaxBytes))
+	}
+	if c.Server.AuditMaxFiles < 0 {
+		errs = append(errs, fmt.Errorf("invalid audit log max files: %d", c.Server.AuditMaxFiles))
+	}
+	if c.Server.AuditStrict && c.Server.AuditLogPath == "" {
+		errs = append(errs, fmt.Errorf("AUDIT_STRICT needs an audit log, but AUDIT_LOG_PATH is none"))
+	}
+	if err := c.Server.validateCORS(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Server.RateLimitReadRPS < 0 || (c.Server.RateLimitReadRPS > 0 && c.Server.RateLimitReadBurst < 1) {
+		errs = append(errs, fmt.Errorf("invalid read rate limit: %g/s with burst %d", c.Server.RateLimitReadRPS, c.Server.RateLimitReadBurst))
+	}
+	if c.Server.RateLimitWriteRPS < 0 || (c.Server.RateLimitWriteRPS > 0 && c.Server.RateLimitWriteBurst < 1) {
+		errs = append(errs, fmt.Errorf("invalid write rate limit: %g/s with burst %d", c.Server.RateLimitWriteRPS, c.Server.RateLimitWriteBurst))
+	}
+	if c.Server.RateLimitIdleTTL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid rate limit idle TTL: %s", c.Server.RateLimitIdleTTL))
+	}
+	if c.Server.RequestMaxBytes <= 0 || c.Server.RequestBatchMaxBytes <= 0 {
+		errs = append(errs, fmt.Errorf("invalid request body limits: %d and %d bytes for batches", c.Server.RequestMaxBytes, c.Server.RequestBatchMaxBytes))
+	}
+	if c.Server.RequestTimeout <= 0 || c.Server.RequestBatchTimeout < 0 {
+		errs = append(errs, fmt.Errorf("invalid request timeouts: %s and %s for batches", c.Server.RequestTimeout, c.Server.RequestBatchTimeout))
+	}
+	// Past the write timeout the connection is closed before the 504 can be sent
+	if timeout := max(c.Server.RequestTimeout, c.Server.RequestBatchTimeout); c.Server.WriteTimeout > 0 && timeout >= c.Server.WriteTimeout {
+		errs = append(errs, fmt.Errorf("request timeout %s must be shorter than the write timeout %s", timeout, c.Server.WriteTimeout))
+	}
+	if c.Server.DebugPprof && !c.Server.DebugPprofShared {
+		if _, port, err := net.SplitHostPort(c.Server.DebugPprofAddr); err != nil || port == "" {
+			errs = append(errs, fmt.Errorf("invalid pprof address: %q", c.Server.DebugPprofAddr))
+		}
+	}
+
+	errs = append(errs, c.validateTenants()...)
+
+	if c.Ledger.OpenAttempts < 1 || c.Ledger.OpenRetryDelay < 0 {
+		errs = append(errs, fmt.Errorf("invalid ledger open retries: %d attempts %s apart", c.Ledger.OpenAttempts, c.Ledger.OpenRetryDelay))
 	}
 
-	if c.Fabric.ChannelID == "" {
-		return fmt.Errorf("fabric channel ID is required")
+	// Fabric connection settings are only required when the Fabric backend is selected
+	if err := c.Ledger.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+func (c *ServerConfig) validateJWT() error {
+	if c.JWTJWKSURL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.JWTJWKSURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid JWKS URL: %q", c.JWTJWKSURL)
 	}
+	if c.JWTIssuer == "" || c.JWTAudience == "" {
+		return fmt.Errorf("JWT_ISSUER and JWT_AUDIENCE are required with JWT_JWKS_URL")
+	}
+	if c.JWTJWKSRefresh <= 0 {
+		return fmt.Errorf("invalid JWKS refresh interval: %s", c.JWTJWKSRefresh)
+	}
+	if c.JWTLeeway < 0 {
+		return fmt.Errorf("invalid JWT leeway: %s", c.JWTLeeway)
+	}
+	return nil
+}
 
-	if c.Fabric.ChaincodeName == "" {
-		return fmt.Errorf("fabric chaincode name is required")
+func (c *Config) validateTenants() Errors {
+	var errs Errors
+	tenants := make(map[string]bool, len(c.Server.Tenants))
+	for _, tenant := range c.Server.Tenants {
+		if !fabric.ValidTenantID(tenant) {
+			errs = append(errs, fmt.Errorf("invalid tenant ID %q: expected letters, digits, - and _", tenant))
+		}
+		tenants[tenant] = true
+	}
+	for keyID, tenant := range c.Server.TenantAPIKeys {
+		// Without TENANTS the keys only bind callers to their commitment key
+		if len(tenants) > 0 && !tenants[tenant] {
+			errs = append(errs, fmt.Errorf("API key %s is assigned to tenant %q, which is not in TENANTS", keyID, tenant))
+		}
 	}
+	if len(tenants) > 0 && c.Ledger.Mode != "" && c.Ledger.Mode != "file" {
+		errs = append(errs, fmt.Errorf("TENANTS needs LEDGER_MODE=file, not %s", c.Ledger.Mode))
+	}
+	return errs
+}
 
+func (c *ServerConfig) validateCORS() error {
+	for _, origin := range c.CORSAllowedOrigins {
+		if origin == "*" {
+			if c.CORSAllowCredentials {
+				return fmt.Errorf("CORS_ALLOWED_ORIGINS=* cannot be combined with CORS_ALLOW_CREDENTIALS")
+			}
+			continue
+		}
+		scheme, host, hasScheme := strings.Cut(strings.TrimSuffix(origin, "/"), "://")
+		if !hasScheme {
+			scheme, host = "", origin
+		}
+		if (hasScheme && scheme == "") || strings.TrimPrefix(host, "*.") == "" || strings.ContainsAny(host, "/?#") {
+			return fmt.Errorf("invalid CORS origin: %q", origin)
+		}
+		if !hasScheme && !strings.HasPrefix(host, "*.") {
+			return fmt.Errorf("invalid CORS origin %q: exact origins need a scheme, e.g. https://%s", origin, origin)
+		}
+		if strings.Contains(strings.TrimPrefix(host, "*."), "*") {
+			return fmt.Errorf("invalid CORS origin %q: only a leading *. wildcard is supported", origin)
+		}
+	}
+	if c.CORSMaxAge < 0 {
+		return fmt.Errorf("invalid CORS max age: %s", c.CORSMaxAge)
+	}
 	return nil
 }
 
-func getEnv(key, defaultValue string) string {
+// env looks settings up by environment variable name: among the overrides
+// first, then in the environment, then among the values of the config file.
+type env struct {
+	overrides map[string]string
+	file      map[string]string
+	// lenient falls back to the default on malformed values instead of reporting them
+	lenient bool
+	errs    Errors
+}
+
+// malformed records that key holds a value that does not parse as expected.
+func (e *env) malformed(key, value, expected string) {
+	if !e.lenient {
+		e.errs = append(e.errs, fmt.Errorf("invalid %s %q: expected %s", key, value, expected))
+	}
+}
+
+func (e *env) lookup(key string) string {
+	if value := e.overrides[key]; value != "" {
+		return value
+	}
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
+	return e.file[key]
+}
+
+// getEnvAsLogLevel parses debug, info, warn or error, in any case. Invalid values
+// are reported even when lenient, as a typo would silently hide or flood logs.
+func (e *env) getEnvAsLogLevel(key string, defaultValue slog.Level) slog.Level {
+	valueStr := e.lookup(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(valueStr)); err != nil {
+		e.errs = append(e.errs, fmt.Errorf("invalid %s %q: use debug, info, warn or error", key, valueStr))
+		return defaultValue
+	}
+	return level
+}
+
+func (e *env) getEnv(key, defaultValue string) string {
+	if value := e.lookup(key); value != "" {
+		return value
+	}
 	return defaultValue
 }
 
-func getEnvAsInt(key string, defaultValue int) int {
-	valueStr := os.Getenv(key)
+func (e *env) getEnvAsInt(key string, defaultValue int) int {
+	valueStr := e.lookup(key)
 	if valueStr == "" {
 		return defaultValue
 	}
 
 	value, err := strconv.Atoi(valueStr)
 	if err != nil {
+		e.malformed(key, valueStr, "an integer")
+		return defaultValue
+	}
+
+	return value
+}
+
+func (e *env) getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := e.lookup(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		e.malformed(key, valueStr, "a number")
 		return defaultValue
 	}
 
 	return value
 }
 
-func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
-	valueStr := os.Getenv(key)
+func (e *env) getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := e.lookup(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		e.malformed(key, valueStr, "true or false")
+		return defaultValue
+	}
+
+	return value
+}
+
+// getEnvAsFileMode parses permission bits written in octal, as in 0660.
+func (e *env) getEnvAsFileMode(key string, defaultValue os.FileMode) os.FileMode {
+	valueStr := e.lookup(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseUint(valueStr, 8, 32)
+	if err != nil || value > 0o777 {
+		e.malformed(key, valueStr, "octal permissions such as 0660")
+		return defaultValue
+	}
+
+	return os.FileMode(value)
+}
+
+// getEnvAsList splits a comma-separated variable, dropping empty entries.
+func (e *env) getEnvAsList(key string) []string {
+	var values []string
+	for _, v := range strings.Split(e.lookup(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// getEnvAsMap splits a comma-separated variable of key=value pairs.
+func (e *env) getEnvAsMap(key string) map[string]string {
+	var values map[string]string
+	for _, pair := range e.getEnvAsList(key) {
+		k, v, ok := strings.Cut(pair, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" || v == "" {
+			e.malformed(key, pair, "comma-separated key=value pairs")
+			continue
+		}
+		if values == nil {
+			values = make(map[string]string)
+		}
+		values[k] = v
+	}
+	return values
+}
+
+func (e *env) getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	valueStr := e.lookup(key)
 	if valueStr == "" {
 		return defaultValue
 	}
 
 	value, err := time.ParseDuration(valueStr)
 	if err != nil {
+		e.malformed(key, valueStr, "a duration such as 30s or 5m")
 		return defaultValue
 	}
 
@@ -0,0 +1,752 @@
+package config
+
+import (
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var fabricEnvVars = []string{
+	"FABRIC_PEER_ENDPOINT",
+	"FABRIC_TLS_CA_CERT_PATH",
+	"FABRIC_MSP_ID",
+	"FABRIC_CERT_PATH",
+	"FABRIC_KEY_PATH",
+	"FABRIC_CHANNEL_ID",
+	"FABRIC_CHAINCODE_NAME",
+}
+
+func clearFabricEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range fabricEnvVars {
+		t.Setenv(key, "")
+	}
+}
+
+func TestLoad_FileModeIgnoresFabricFields(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected file mode to load without Fabric settings, got %v", err)
+	}
+	if cfg.Ledger.Mode != "file" {
+		t.Errorf("expected mode file, got %q", cfg.Ledger.Mode)
+	}
+}
+
+func TestLoad_FabricModeReportsMissingFields(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "fabric")
+	t.Setenv("FABRIC_PEER_ENDPOINT", "peer0.org1.example.com:7051")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for incomplete Fabric configuration")
+	}
+
+	msg := err.Error()
+	for _, want := range []string{"TLSCACertPath (FABRIC_TLS_CA_CERT_PATH)", "CertPath (FABRIC_CERT_PATH)", "KeyPath (FABRIC_KEY_PATH)"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected error to mention %q, got %q", want, msg)
+		}
+	}
+	if strings.Contains(msg, "PeerEndpoint") {
+		t.Errorf("PeerEndpoint is set and must not be reported missing: %q", msg)
+	}
+	// Channel, chaincode and MSP have defaults
+	if strings.Contains(msg, "ChannelID") || strings.Contains(msg, "MSPID") {
+		t.Errorf("defaulted fields must not be reported missing: %q", msg)
+	}
+}
+
+func TestLoad_FabricModeComplete(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "fabric")
+	t.Setenv("FABRIC_PEER_ENDPOINT", "peer0.org1.example.com:7051")
+	t.Setenv("FABRIC_TLS_CA_CERT_PATH", "/crypto/ca.pem")
+	t.Setenv("FABRIC_CERT_PATH", "/crypto/cert.pem")
+	t.Setenv("FABRIC_KEY_PATH", "/crypto/key.pem")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected complete Fabric configuration to load, got %v", err)
+	}
+	if cfg.Ledger.ChannelID != "mychannel" || cfg.Ledger.ChaincodeName != "verifiable-credentials" {
+		t.Errorf("unexpected defaults: %+v", cfg.Ledger)
+	}
+}
+
+func TestLoad_InvalidLedgerMode(t *testing.T) {
+	t.Setenv("LEDGER_MODE", "postgres")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for invalid ledger mode")
+	}
+}
+
+func TestLoad_DIDSettings(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+	t.Setenv("DID_ALLOWED_METHODS", "ewallet, web,")
+	t.Setenv("DID_WEB_RESOLUTION", "false")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := strings.Join(cfg.Server.DIDMethods, ","); got != "ewallet,web" {
+		t.Errorf("unexpected DID methods %q", got)
+	}
+	if cfg.Server.DIDWebResolution {
+		t.Error("expected did:web resolution to be disabled")
+	}
+	if cfg.Server.DIDMaxVerificationMethods != 20 {
+		t.Errorf("expected a default of 20 verification methods, got %d", cfg.Server.DIDMaxVerificationMethods)
+	}
+
+	t.Setenv("DID_MAX_VERIFICATION_METHODS", "0")
+	if _, err := Load(); err == nil {
+		t.Error("expected a verification method limit of 0 to be rejected")
+	}
+}
+
+func TestLoad_AnchorLimits(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.AnchorMetadataMaxBytes != 4096 {
+		t.Errorf("expected default of 4096, got %d", cfg.Server.AnchorMetadataMaxBytes)
+	}
+	if cfg.Server.AnchorVerifyBatchMax != 256 {
+		t.Errorf("expected verify batch default of 256, got %d", cfg.Server.AnchorVerifyBatchMax)
+	}
+	if cfg.Server.AnchorDocumentMaxBytes != 1<<20 {
+		t.Errorf("expected document default of 1 MiB, got %d", cfg.Server.AnchorDocumentMaxBytes)
+	}
+
+	t.Setenv("ANCHOR_METADATA_MAX_BYTES", "1024")
+	if cfg, _ = Load(); cfg.Server.AnchorMetadataMaxBytes != 1024 {
+		t.Errorf("expected 1024, got %d", cfg.Server.AnchorMetadataMaxBytes)
+	}
+}
+
+func TestLoad_GRPCPort(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.GRPCPort != 0 {
+		t.Errorf("expected gRPC to be off by default, got port %d", cfg.Server.GRPCPort)
+	}
+
+	t.Setenv("GRPC_PORT", "8080")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error when gRPC and HTTP share a port")
+	}
+
+	t.Setenv("GRPC_PORT", "9090")
+	if cfg, err = Load(); err != nil || cfg.Server.GRPCPort != 9090 {
+		t.Errorf("expected gRPC on port 9090, got %v, %v", cfg, err)
+	}
+}
+
+func TestLoad_Listen(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+
+	for listen, want := range map[string]string{
+		"":                              "tcp :8080",
+		"tcp://0.0.0.0:8080":            "tcp 0.0.0.0:8080",
+		"unix:///var/run/resolver.sock": "unix /var/run/resolver.sock",
+	} {
+		t.Setenv("SERVER_LISTEN", listen)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("%q: Load failed: %v", listen, err)
+		}
+		if network, address, _ := cfg.Server.ListenAddr(); network+" "+address != want {
+			t.Errorf("%q: expected %s, got %s %s", listen, want, network, address)
+		}
+		if cfg.Server.ListenSocketMode != 0o660 {
+			t.Errorf("expected socket mode 0660 by default, got %o", cfg.Server.ListenSocketMode)
+		}
+	}
+
+	for _, listen := range []string{"0.0.0.0:8080", "tcp://8080", "unix://relative.sock", "udp://:53"} {
+		t.Setenv("SERVER_LISTEN", listen)
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "invalid SERVER_LISTEN") {
+			t.Errorf("%q: expected an invalid listener, got %v", listen, err)
+		}
+	}
+
+	t.Setenv("SERVER_LISTEN", "")
+	t.Setenv("SERVER_LISTEN_SOCKET_MODE", "0600")
+	if cfg, err := Load(); err != nil || cfg.Server.ListenSocketMode != 0o600 {
+		t.Errorf("expected socket mode 0600, got %v", err)
+	}
+	t.Setenv("SERVER_LISTEN_SOCKET_MODE", "rw-rw----")
+	if _, err := Load(); err == nil {
+		t.Error("expected a socket mode that is not octal to be rejected")
+	}
+}
+
+func TestLoad_Tenants(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+	t.Setenv("TENANTS", "acme, globex")
+	t.Setenv("TENANT_API_KEYS", "acme-ci=acme")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if strings.Join(cfg.Server.Tenants, ",") != "acme,globex" || cfg.Server.TenantAPIKeys["acme-ci"] != "acme" {
+		t.Errorf("expected the tenants and their keys, got %v and %v", cfg.Server.Tenants, cfg.Server.TenantAPIKeys)
+	}
+
+	for key, value := range map[string]string{
+		"TENANTS":         "acme,../globex",
+		"TENANT_API_KEYS": "globex-ci=initech",
+		"LEDGER_MODE":     "fabric",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), "tenant") && !strings.Contains(err.Error(), "TENANTS") {
+				t.Errorf("%s=%s: expected a tenancy error, got %v", key, value, err)
+			}
+		})
+	}
+
+	t.Setenv("TENANT_API_KEYS", "acme-ci")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "TENANT_API_KEYS") {
+		t.Errorf("expected a pair without a tenant to be rejected, got %v", err)
+	}
+
+	// Without TENANTS the keys only bind callers to a commitment key
+	t.Setenv("TENANTS", "")
+	t.Setenv("TENANT_API_KEYS", "initech-ci=initech")
+	if cfg, err := Load(); err != nil || cfg.Server.TenantAPIKeys["initech-ci"] != "initech" {
+		t.Errorf("expected commitment tenants without TENANTS, got %v, %v", cfg, err)
+	}
+}
+
+func TestLoad_ShutdownTimeout(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.ShutdownTimeout != 30*time.Second {
+		t.Errorf("expected a default shutdown timeout of 30s, got %s", cfg.Server.ShutdownTimeout)
+	}
+
+	t.Setenv("SERVER_SHUTDOWN_TIMEOUT", "0s")
+	if _, err := Load(); err == nil {
+		t.Error("expected a shutdown timeout of 0 to be rejected")
+	}
+}
+
+func TestLoad_ReadOnly(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.ReadOnly || cfg.Server.ReadOnlyRetryAfter != time.Minute {
+		t.Errorf("expected writes allowed and a retry after 1m by default, got %v and %s", cfg.Server.ReadOnly, cfg.Server.ReadOnlyRetryAfter)
+	}
+
+	t.Setenv("READ_ONLY", "true")
+	t.Setenv("READ_ONLY_RETRY_AFTER", "5m")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !cfg.Server.ReadOnly || cfg.Server.ReadOnlyRetryAfter != 5*time.Minute {
+		t.Errorf("expected read-only mode with a retry after 5m, got %v and %s", cfg.Server.ReadOnly, cfg.Server.ReadOnlyRetryAfter)
+	}
+	t.Setenv("READ_ONLY_RETRY_AFTER", "0s")
+	if _, err := Load(); err == nil {
+		t.Error("expected a retry after of 0 to be rejected")
+	}
+}
+
+func TestLoad_Audit(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+	t.Setenv("LEDGER_FILE_PATH", "/var/lib/resolver/ledger.json")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.AuditLogPath != "/var/lib/resolver/audit.log" || cfg.Server.AuditMaxBytes != 10<<20 || cfg.Server.AuditMaxFiles != 10 || cfg.Server.AuditStrict {
+		t.Errorf("unexpected audit defaults: %q, %d bytes, %d files, strict %v",
+			cfg.Server.AuditLogPath, cfg.Server.AuditMaxBytes, cfg.Server.AuditMaxFiles, cfg.Server.AuditStrict)
+	}
+
+	t.Setenv("AUDIT_LOG_PATH", "none")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.AuditLogPath != "" {
+		t.Errorf("expected none to disable the audit log, got %q", cfg.Server.AuditLogPath)
+	}
+	t.Setenv("AUDIT_STRICT", "true")
+	if _, err := Load(); err == nil {
+		t.Error("expected strict mode without an audit log to be rejected")
+	}
+
+	t.Setenv("AUDIT_LOG_PATH", "")
+	t.Setenv("AUDIT_MAX_BYTES", "0")
+	if _, err := Load(); err == nil {
+		t.Error("expected an audit max bytes of 0 to be rejected")
+	}
+}
+
+func TestLoad_TLSSettings(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"cert without key", map[string]string{"SERVER_TLS_CERT_FILE": missing}, "must be set together"},
+		{"key without cert", map[string]string{"SERVER_TLS_KEY_FILE": missing}, "must be set together"},
+		{"client CA without cert", map[string]string{"SERVER_TLS_CLIENT_CA": missing}, "requires"},
+		{"missing files", map[string]string{"SERVER_TLS_CERT_FILE": missing, "SERVER_TLS_KEY_FILE": missing}, "failed to load TLS certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.TLSEnabled() {
+		t.Error("expected TLS to be off by default")
+	}
+}
+
+func TestLoad_JWTSettings(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"relative URL", map[string]string{"JWT_JWKS_URL": "/jwks.json", "JWT_ISSUER": "i", "JWT_AUDIENCE": "a"}, "invalid JWKS URL"},
+		{"missing issuer", map[string]string{"JWT_JWKS_URL": "https://idp.example.com/jwks.json", "JWT_AUDIENCE": "a"}, "JWT_ISSUER and JWT_AUDIENCE"},
+		{"missing audience", map[string]string{"JWT_JWKS_URL": "https://idp.example.com/jwks.json", "JWT_ISSUER": "i"}, "JWT_ISSUER and JWT_AUDIENCE"},
+		{"zero refresh", map[string]string{"JWT_JWKS_URL": "https://idp.example.com/jwks.json", "JWT_ISSUER": "i", "JWT_AUDIENCE": "a", "JWT_JWKS_REFRESH": "0s"}, "refresh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Setenv("JWT_JWKS_URL", "https://idp.example.com/jwks.json")
+	t.Setenv("JWT_ISSUER", "https://idp.example.com")
+	t.Setenv("JWT_AUDIENCE", "fabric-resolver")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.JWTJWKSRefresh != 10*time.Minute || cfg.Server.JWTLeeway != 30*time.Second {
+		t.Errorf("expected default refresh and leeway, got %s and %s", cfg.Server.JWTJWKSRefresh, cfg.Server.JWTLeeway)
+	}
+}
+
+func TestLoad_RateLimits(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.RateLimitReadRPS != 50 || cfg.Server.RateLimitReadBurst != 100 ||
+		cfg.Server.RateLimitWriteRPS != 10 || cfg.Server.RateLimitWriteBurst != 20 {
+		t.Errorf("unexpected default limits %+v", cfg.Server)
+	}
+
+	t.Setenv("RATE_LIMIT_WRITE_RPS", "0.5")
+	t.Setenv("RATE_LIMIT_READ_RPS", "0")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.RateLimitWriteRPS != 0.5 || cfg.Server.RateLimitReadRPS != 0 {
+		t.Errorf("expected fractional and disabled rates, got %g and %g", cfg.Server.RateLimitWriteRPS, cfg.Server.RateLimitReadRPS)
+	}
+
+	for key, value := range map[string]string{"RATE_LIMIT_WRITE_BURST": "0", "RATE_LIMIT_IDLE_TTL": "0s"} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			if _, err := Load(); err == nil {
+				t.Errorf("expected %s=%s to be rejected", key, value)
+			}
+		})
+	}
+}
+
+func TestLoad_LogLevel(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.LogLevel != slog.LevelInfo {
+		t.Errorf("expected info by default, got %s", cfg.Server.LogLevel)
+	}
+
+	for value, want := range map[string]slog.Level{"debug": slog.LevelDebug, "WARN": slog.LevelWarn, "error": slog.LevelError} {
+		t.Setenv("LOG_LEVEL", value)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("LOG_LEVEL=%s: Load failed: %v", value, err)
+		}
+		if cfg.Server.LogLevel != want {
+			t.Errorf("LOG_LEVEL=%s: expected %s, got %s", value, want, cfg.Server.LogLevel)
+		}
+	}
+
+	t.Setenv("LOG_LEVEL", "verbose")
+	if _, err := Load(); err == nil {
+		t.Error("expected an unknown level to be rejected")
+	}
+}
+
+func TestLoad_CORSOrigins(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://wallet.example.com, *.example.org,https://*.example.net")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.Server.CORSAllowedOrigins) != 3 || cfg.Server.CORSMaxAge != 10*time.Minute {
+		t.Errorf("unexpected CORS settings %v, %s", cfg.Server.CORSAllowedOrigins, cfg.Server.CORSMaxAge)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"bare host", map[string]string{"CORS_ALLOWED_ORIGINS": "wallet.example.com"}},
+		{"path", map[string]string{"CORS_ALLOWED_ORIGINS": "https://wallet.example.com/app"}},
+		{"inner wildcard", map[string]string{"CORS_ALLOWED_ORIGINS": "https://api.*.example.com"}},
+		{"empty wildcard", map[string]string{"CORS_ALLOWED_ORIGINS": "*."}},
+		{"any origin with credentials", map[string]string{"CORS_ALLOWED_ORIGINS": "*", "CORS_ALLOW_CREDENTIALS": "true"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if _, err := Load(); err == nil {
+				t.Errorf("expected %v to be rejected", tt.env)
+			}
+		})
+	}
+}
+
+func TestLoad_IdempotencyStore(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+	t.Setenv("LEDGER_FILE_PATH", "/var/lib/resolver/ledger.json")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.IdempotencyFilePath != "/var/lib/resolver/idempotency.json" {
+		t.Errorf("expected the store next to the ledger, got %q", cfg.Server.IdempotencyFilePath)
+	}
+	if cfg.Server.IdempotencyRetention != 24*time.Hour {
+		t.Errorf("expected 24h retention, got %s", cfg.Server.IdempotencyRetention)
+	}
+
+	t.Setenv("IDEMPOTENCY_RETENTION", "-1h")
+	if _, err := Load(); err == nil {
+		t.Error("expected an error for a negative retention")
+	}
+}
+
+func TestLoad_ReceiptKeyPath(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+	t.Setenv("LEDGER_FILE_PATH", "/var/lib/resolver/ledger.json")
+	t.Setenv("RECEIPT_KEY_PATH", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.ReceiptKeyPath != "/var/lib/resolver/receipt-key.pem" {
+		t.Errorf("expected the key next to the ledger, got %q", cfg.Server.ReceiptKeyPath)
+	}
+
+	t.Setenv("RECEIPT_KEY_PATH", "/run/secrets/receipt.pem")
+	if cfg, _ = Load(); cfg.Server.ReceiptKeyPath != "/run/secrets/receipt.pem" {
+		t.Errorf("expected the configured path, got %q", cfg.Server.ReceiptKeyPath)
+	}
+}
+
+func TestLoad_DebugPprof(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.DebugPprof || cfg.Server.DebugPprofAddr != "localhost:6060" {
+		t.Errorf("expected pprof off with a localhost default address, got %v at %q", cfg.Server.DebugPprof, cfg.Server.DebugPprofAddr)
+	}
+
+	t.Setenv("DEBUG_PPROF", "true")
+	t.Setenv("DEBUG_PPROF_ADDR", "not-an-address")
+	if _, err := Load(); err == nil {
+		t.Error("expected an invalid pprof address to be rejected")
+	}
+
+	// The address is unused when pprof shares the main listener
+	t.Setenv("DEBUG_PPROF_SHARED", "true")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !cfg.Server.DebugPprof || !cfg.Server.DebugPprofShared {
+		t.Errorf("expected shared pprof, got %+v", cfg.Server)
+	}
+}
+
+func TestLoad_RequestLimits(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.RequestMaxBytes != 1<<20 || cfg.Server.RequestBatchMaxBytes != 8<<20 || cfg.Server.RequestStrictJSON {
+		t.Errorf("unexpected defaults: %d, %d, strict %v", cfg.Server.RequestMaxBytes, cfg.Server.RequestBatchMaxBytes, cfg.Server.RequestStrictJSON)
+	}
+
+	t.Setenv("REQUEST_MAX_BYTES", "2048")
+	t.Setenv("REQUEST_STRICT_JSON", "true")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.RequestMaxBytes != 2048 || !cfg.Server.RequestStrictJSON {
+		t.Errorf("expected the configured limit and strict mode, got %d and %v", cfg.Server.RequestMaxBytes, cfg.Server.RequestStrictJSON)
+	}
+
+	t.Setenv("REQUEST_BATCH_MAX_BYTES", "-1")
+	if _, err := Load(); err == nil {
+		t.Error("expected a negative batch limit to be rejected")
+	}
+}
+
+func TestLoad_ReportsEveryMalformedValue(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+	t.Setenv("CONFIG_LENIENT", "")
+	t.Setenv("SERVER_PORT", "808O")
+	t.Setenv("SERVER_READ_TIMEOUT", "15")
+	t.Setenv("RATE_LIMIT_READ_RPS", "fast")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "yes please")
+	t.Setenv("LEDGER_REAP_INTERVAL", "hourly")
+	t.Setenv("SERVER_SHUTDOWN_TIMEOUT", "-1s")
+
+	_, err := Load()
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected configuration Errors, got %v", err)
+	}
+	if len(errs) != 6 {
+		t.Errorf("expected 6 errors, got %d: %v", len(errs), err)
+	}
+	for _, want := range []string{
+		`SERVER_PORT "808O": expected an integer`,
+		`SERVER_READ_TIMEOUT "15": expected a duration`,
+		`RATE_LIMIT_READ_RPS "fast": expected a number`,
+		`CORS_ALLOW_CREDENTIALS "yes please": expected true or false`,
+		`LEDGER_REAP_INTERVAL "hourly"`,
+		"invalid shutdown timeout: -1s",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected the error to mention %q, got %q", want, err)
+		}
+	}
+}
+
+func TestLoad_LedgerOpenRetries(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+	t.Setenv("LEDGER_OPEN_ATTEMPTS", "")
+	t.Setenv("LEDGER_OPEN_RETRY_DELAY", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Ledger.OpenAttempts != 5 || cfg.Ledger.OpenRetryDelay != 2*time.Second {
+		t.Errorf("expected 5 attempts 2s apart by default, got %d and %s", cfg.Ledger.OpenAttempts, cfg.Ledger.OpenRetryDelay)
+	}
+
+	t.Setenv("LEDGER_OPEN_ATTEMPTS", "0")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "invalid ledger open retries") {
+		t.Errorf("expected zero attempts to be rejected, got %v", err)
+	}
+}
+
+func TestLoad_Lenient(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+	t.Setenv("CONFIG_LENIENT", "true")
+	t.Setenv("SERVER_PORT", "808O")
+	t.Setenv("SERVER_READ_TIMEOUT", "15")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected malformed values to fall back to defaults, got %v", err)
+	}
+	if cfg.Server.Port != 8080 || cfg.Server.ReadTimeout != 15*time.Second {
+		t.Errorf("expected the defaults, got port %d and read timeout %s", cfg.Server.Port, cfg.Server.ReadTimeout)
+	}
+
+	t.Setenv("SERVER_SHUTDOWN_TIMEOUT", "-1s")
+	t.Setenv("LOG_LEVEL", "verbose")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "2 configuration errors") {
+		t.Errorf("expected invalid settings to still be reported, got %v", err)
+	}
+}
+
+func TestLoad_RequestTimeouts(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+	t.Setenv("SERVER_WRITE_TIMEOUT", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.RequestTimeout != 10*time.Second || cfg.Server.RequestBatchTimeout != 0 {
+		t.Errorf("unexpected defaults: %s and %s for batches", cfg.Server.RequestTimeout, cfg.Server.RequestBatchTimeout)
+	}
+
+	t.Setenv("REQUEST_TIMEOUT", "5s")
+	t.Setenv("REQUEST_BATCH_TIMEOUT", "12s")
+	if cfg, err = Load(); err != nil || cfg.Server.RequestTimeout != 5*time.Second || cfg.Server.RequestBatchTimeout != 12*time.Second {
+		t.Errorf("expected the configured timeouts, got %+v, %v", cfg, err)
+	}
+
+	// The server's write timeout would close the connection before the 504
+	t.Setenv("REQUEST_BATCH_TIMEOUT", "20s")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "write timeout") {
+		t.Errorf("expected a timeout past SERVER_WRITE_TIMEOUT to be rejected, got %v", err)
+	}
+
+	t.Setenv("REQUEST_TIMEOUT", "0s")
+	if _, err := Load(); err == nil {
+		t.Error("expected a zero request timeout to be rejected")
+	}
+}
+
+func TestLoad_AccessLog(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+	t.Setenv("ACCESS_LOG_EXCLUDE", "")
+	t.Setenv("ACCESS_LOG_SAMPLE", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if strings.Join(cfg.Server.AccessLogExclude, ",") != "/health,/livez,/readyz,/metrics" || cfg.Server.AccessLogSample != 0 {
+		t.Errorf("unexpected defaults: %v sampled 1 in %d", cfg.Server.AccessLogExclude, cfg.Server.AccessLogSample)
+	}
+
+	t.Setenv("ACCESS_LOG_EXCLUDE", "/health, /debug/")
+	t.Setenv("ACCESS_LOG_SAMPLE", "100")
+	if cfg, err = Load(); err != nil || strings.Join(cfg.Server.AccessLogExclude, ",") != "/health,/debug/" || cfg.Server.AccessLogSample != 100 {
+		t.Errorf("expected the configured exclusions and rate, got %+v, %v", cfg, err)
+	}
+
+	t.Setenv("ACCESS_LOG_EXCLUDE", "none")
+	if cfg, err = Load(); err != nil || cfg.Server.AccessLogExclude != nil {
+		t.Errorf("expected none to exclude nothing, got %v, %v", cfg.Server.AccessLogExclude, err)
+	}
+
+	t.Setenv("ACCESS_LOG_SAMPLE", "-1")
+	if _, err := Load(); err == nil {
+		t.Error("expected a negative sample rate to be rejected")
+	}
+}
+
+func TestLoad_ConnectionSettings(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.ReadHeaderTimeout != 5*time.Second || cfg.Server.MaxHeaderBytes != 1<<20 || cfg.Server.DisableHTTP2 {
+		t.Errorf("unexpected defaults: %s, %d bytes, HTTP/2 disabled %v", cfg.Server.ReadHeaderTimeout, cfg.Server.MaxHeaderBytes, cfg.Server.DisableHTTP2)
+	}
+
+	t.Setenv("SERVER_READ_HEADER_TIMEOUT", "2s")
+	t.Setenv("SERVER_MAX_HEADER_BYTES", "16384")
+	t.Setenv("SERVER_DISABLE_HTTP2", "true")
+	if cfg, err = Load(); err != nil || cfg.Server.ReadHeaderTimeout != 2*time.Second || cfg.Server.MaxHeaderBytes != 16384 || !cfg.Server.DisableHTTP2 {
+		t.Errorf("expected the configured settings, got %+v, %v", cfg, err)
+	}
+
+	t.Setenv("SERVER_MAX_HEADER_BYTES", "0")
+	t.Setenv("SERVER_READ_HEADER_TIMEOUT", "0s")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "2 configuration errors") {
+		t.Errorf("expected both settings to be rejected, got %v", err)
+	}
+}
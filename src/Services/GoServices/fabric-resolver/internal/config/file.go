@@ -0,0 +1,188 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// fileKeys maps the keys of the config file, by section, to the environment
+// variable each one stands in for. Values follow the syntax of the variable;
+// lists may also be written as YAML or JSON arrays.
+var fileKeys = map[string]map[string]string{
+	"server": {
+		"logLevel":             "LOG_LEVEL",
+		"accessLogExclude":     "ACCESS_LOG_EXCLUDE",
+		"accessLogSample":      "ACCESS_LOG_SAMPLE",
+		"listen":               "SERVER_LISTEN",
+		"listenSocketMode":     "SERVER_LISTEN_SOCKET_MODE",
+		"port":                 "SERVER_PORT",
+		"grpcPort":             "GRPC_PORT",
+		"readTimeout":          "SERVER_READ_TIMEOUT",
+		"writeTimeout":         "SERVER_WRITE_TIMEOUT",
+		"idleTimeout":          "SERVER_IDLE_TIMEOUT",
+		"readHeaderTimeout":    "SERVER_READ_HEADER_TIMEOUT",
+		"maxHeaderBytes":       "SERVER_MAX_HEADER_BYTES",
+		"disableHTTP2":         "SERVER_DISABLE_HTTP2",
+		"shutdownTimeout":      "SERVER_SHUTDOWN_TIMEOUT",
+		"readOnly":             "READ_ONLY",
+		"readOnlyRetryAfter":   "READ_ONLY_RETRY_AFTER",
+		"tlsCertFile":          "SERVER_TLS_CERT_FILE",
+		"tlsKeyFile":           "SERVER_TLS_KEY_FILE",
+		"tlsClientCA":          "SERVER_TLS_CLIENT_CA",
+		"requestMaxBytes":      "REQUEST_MAX_BYTES",
+		"requestBatchMaxBytes": "REQUEST_BATCH_MAX_BYTES",
+		"requestTimeout":       "REQUEST_TIMEOUT",
+		"requestBatchTimeout":  "REQUEST_BATCH_TIMEOUT",
+		"requestStrictJSON":    "REQUEST_STRICT_JSON",
+		"debugPprof":           "DEBUG_PPROF",
+		"debugPprofAddr":       "DEBUG_PPROF_ADDR",
+		"debugPprofShared":     "DEBUG_PPROF_SHARED",
+	},
+	"auth": {
+		"adminToken":          "ADMIN_TOKEN",
+		"apiKeysFile":         "API_KEYS_FILE",
+		"apiKeys":             "API_KEYS",
+		"apiKeysProtectReads": "API_KEYS_PROTECT_READS",
+		"tenants":             "TENANTS",
+		"tenantApiKeys":       "TENANT_API_KEYS",
+		"jwtJwksUrl":          "JWT_JWKS_URL",
+		"jwtIssuer":           "JWT_ISSUER",
+		"jwtAudience":         "JWT_AUDIENCE",
+		"jwtJwksRefresh":      "JWT_JWKS_REFRESH",
+		"jwtLeeway":           "JWT_LEEWAY",
+	},
+	"cors": {
+		"allowedOrigins":   "CORS_ALLOWED_ORIGINS",
+		"allowedMethods":   "CORS_ALLOWED_METHODS",
+		"allowedHeaders":   "CORS_ALLOWED_HEADERS",
+		"allowCredentials": "CORS_ALLOW_CREDENTIALS",
+		"maxAge":           "CORS_MAX_AGE",
+	},
+	"rateLimit": {
+		"readRps":    "RATE_LIMIT_READ_RPS",
+		"readBurst":  "RATE_LIMIT_READ_BURST",
+		"writeRps":   "RATE_LIMIT_WRITE_RPS",
+		"writeBurst": "RATE_LIMIT_WRITE_BURST",
+		"idleTTL":    "RATE_LIMIT_IDLE_TTL",
+	},
+	"dids": {
+		"allowedMethods":         "DID_ALLOWED_METHODS",
+		"maxVerificationMethods": "DID_MAX_VERIFICATION_METHODS",
+		"updateNonceTTL":         "DID_UPDATE_NONCE_TTL",
+		"webResolution":          "DID_WEB_RESOLUTION",
+		"webTimeout":             "DID_WEB_TIMEOUT",
+	},
+	"anchors": {
+		"metadataMaxBytes":     "ANCHOR_METADATA_MAX_BYTES",
+		"verifyBatchMax":       "ANCHOR_VERIFY_BATCH_MAX",
+		"documentMaxBytes":     "ANCHOR_DOCUMENT_MAX_BYTES",
+		"receiptKeyPath":       "RECEIPT_KEY_PATH",
+		"commitmentKeysFile":   "COMMITMENT_KEYS_FILE",
+		"commitmentKeys":       "COMMITMENT_KEYS",
+		"idempotencyFilePath":  "IDEMPOTENCY_FILE_PATH",
+		"idempotencyRetention": "IDEMPOTENCY_RETENTION",
+	},
+	"audit": {
+		"logPath":  "AUDIT_LOG_PATH",
+		"maxBytes": "AUDIT_MAX_BYTES",
+		"maxFiles": "AUDIT_MAX_FILES",
+		"strict":   "AUDIT_STRICT",
+	},
+	"webhooks": {
+		"maxAttempts": "WEBHOOK_MAX_ATTEMPTS",
+		"timeout":     "WEBHOOK_TIMEOUT",
+	},
+	"ledger": {
+		"mode":             "LEDGER_MODE",
+		"filePath":         "LEDGER_FILE_PATH",
+		"retryMaxAttempts": "LEDGER_RETRY_MAX_ATTEMPTS",
+		"reapInterval":     "LEDGER_REAP_INTERVAL",
+		"openAttempts":     "LEDGER_OPEN_ATTEMPTS",
+		"openRetryDelay":   "LEDGER_OPEN_RETRY_DELAY",
+	},
+	"fabric": {
+		"peerEndpoint":  "FABRIC_PEER_ENDPOINT",
+		"tlsCACertPath": "FABRIC_TLS_CA_CERT_PATH",
+		"mspId":         "FABRIC_MSP_ID",
+		"certPath":      "FABRIC_CERT_PATH",
+		"keyPath":       "FABRIC_KEY_PATH",
+		"channelId":     "FABRIC_CHANNEL_ID",
+		"chaincodeName": "FABRIC_CHAINCODE_NAME",
+	},
+}
+
+// readFile reads a config file, JSON if its extension is .json and YAML
+// otherwise, into values keyed by environment variable name. Keys that are not
+// in fileKeys are reported as errors, so a misspelled setting does not go unnoticed.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var sections map[string]map[string]interface{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &sections)
+	} else {
+		err = yaml.Unmarshal(data, &sections)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	var unknown []string
+	for section, settings := range sections {
+		for key, value := range settings {
+			name, ok := fileKeys[section][key]
+			if !ok {
+				unknown = append(unknown, section+"."+key)
+				continue
+			}
+			s, err := fileValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid config file %s: %s.%s: %w", path, section, key, err)
+			}
+			values[name] = s
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown keys in config file %s: %s", path, strings.Join(unknown, ", "))
+	}
+	return values, nil
+}
+
+// fileValue formats a decoded value the way it would be written in the environment.
+func fileValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := fileValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", fmt.Errorf("expected a value or a list, got %T", value)
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile_Precedence(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "")
+	t.Setenv("GRPC_PORT", "")
+	t.Setenv("SERVER_PORT", "9500")
+
+	path := writeConfigFile(t, "resolver.yaml", `
+server:
+  port: 9000
+  grpcPort: 9100
+  logLevel: debug
+cors:
+  allowedOrigins: [https://wallet.example.com, "*.example.org"]
+  maxAge: 1m
+ledger:
+  mode: file
+fabric:
+  channelId: vcchannel
+`)
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+
+	if cfg.Server.Port != 9500 {
+		t.Errorf("expected the environment to override the file, got port %d", cfg.Server.Port)
+	}
+	if cfg.Server.GRPCPort != 9100 || cfg.Server.CORSMaxAge != time.Minute || cfg.Ledger.ChannelID != "vcchannel" {
+		t.Errorf("expected file values where the environment is unset, got %+v", cfg)
+	}
+	if !slices.Equal(cfg.Server.CORSAllowedOrigins, []string{"https://wallet.example.com", "*.example.org"}) {
+		t.Errorf("expected the YAML list to be read, got %v", cfg.Server.CORSAllowedOrigins)
+	}
+	if cfg.Server.ReadTimeout != 15*time.Second || cfg.Ledger.ChaincodeName != "verifiable-credentials" {
+		t.Errorf("expected defaults for settings set nowhere, got %s and %q", cfg.Server.ReadTimeout, cfg.Ledger.ChaincodeName)
+	}
+	if cfg.Server.LogLevel.String() != "DEBUG" {
+		t.Errorf("expected the file's log level, got %s", cfg.Server.LogLevel)
+	}
+}
+
+func TestLoadWithOverrides_OverrideEnvironmentAndFile(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("SERVER_PORT", "9500")
+	t.Setenv("LEDGER_FILE_PATH", "")
+
+	path := writeConfigFile(t, "resolver.yaml", "server:\n  port: 9000\n  grpcPort: 9100\n")
+	cfg, err := LoadWithOverrides(path, map[string]string{
+		"SERVER_PORT":      "9600",
+		"GRPC_PORT":        "",
+		"LEDGER_FILE_PATH": "/srv/ledger/ledger.json",
+	})
+	if err != nil {
+		t.Fatalf("LoadWithOverrides failed: %v", err)
+	}
+	if cfg.Server.Port != 9600 || cfg.Server.GRPCPort != 9100 {
+		t.Errorf("expected overrides to win and empty ones to be ignored, got ports %d and %d", cfg.Server.Port, cfg.Server.GRPCPort)
+	}
+	// Paths derived from the ledger's follow the overridden one
+	if cfg.Ledger.FilePath != "/srv/ledger/ledger.json" || cfg.Server.ReceiptKeyPath != "/srv/ledger/receipt-key.pem" {
+		t.Errorf("expected the ledger path override to apply, got %q and %q", cfg.Ledger.FilePath, cfg.Server.ReceiptKeyPath)
+	}
+
+	if _, err := LoadWithOverrides("", map[string]string{"SERVER_PORT": "eighty"}); err == nil || !strings.Contains(err.Error(), "SERVER_PORT") {
+		t.Errorf("expected a malformed override to be reported, got %v", err)
+	}
+}
+
+func TestLoadFile_JSON(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+	t.Setenv("SERVER_PORT", "")
+
+	path := writeConfigFile(t, "resolver.json", `{
+		"server": {"port": 8181, "requestStrictJSON": true},
+		"rateLimit": {"writeRps": 0.5},
+		"dids": {"allowedMethods": ["key", "web"]}
+	}`)
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.Server.Port != 8181 || !cfg.Server.RequestStrictJSON || cfg.Server.RateLimitWriteRPS != 0.5 ||
+		!slices.Equal(cfg.Server.DIDMethods, []string{"key", "web"}) {
+		t.Errorf("unexpected config from JSON: %+v", cfg.Server)
+	}
+}
+
+func TestLoad_ConfigFileFromEnv(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "")
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, "resolver.yml", "webhooks:\n  maxAttempts: 2\n"))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.WebhookMaxAttempts != 2 {
+		t.Errorf("expected CONFIG_FILE to be read, got %d attempts", cfg.Server.WebhookMaxAttempts)
+	}
+}
+
+func TestLoadFile_Errors(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+
+	tests := []struct {
+		name, file, content, want string
+	}{
+		{"malformed YAML", "bad.yaml", "server:\n  port: [8080\n", "invalid config file"},
+		{"malformed JSON", "bad.json", `{"server": {"port": 8080,}}`, "invalid config file"},
+		{"section not a map", "section.yaml", "server: 8080\n", "invalid config file"},
+		{"nested value", "nested.yaml", "server:\n  port:\n    value: 8080\n", "server.port"},
+		{"unknown keys", "unknown.yaml", "server:\n  prot: 8080\nlogging:\n  level: debug\n", "logging.level, server.prot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFile(writeConfigFile(t, tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected an error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected a missing config file to be an error")
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	clearFabricEnv(t)
+	t.Setenv("LEDGER_MODE", "file")
+	t.Setenv("ADMIN_TOKEN", "admin-secret")
+	t.Setenv("API_KEYS", "issuer-a=key-secret-0123456789")
+	t.Setenv("COMMITMENT_KEYS", "tenant=c2VjcmV0")
+	t.Setenv("API_KEYS_FILE", "/run/secrets/api-keys.json")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	logged, err := json.Marshal(cfg.Redacted())
+	if err != nil {
+		t.Fatalf("expected the redacted config to be loggable, got %v", err)
+	}
+	for _, secret := range []string{"admin-secret", "key-secret", "c2VjcmV0"} {
+		if strings.Contains(string(logged), secret) {
+			t.Errorf("expected %q to be masked in %s", secret, logged)
+		}
+	}
+	if !strings.Contains(string(logged), "/run/secrets/api-keys.json") {
+		t.Error("expected paths to be kept")
+	}
+	if cfg.Server.AdminToken != "admin-secret" {
+		t.Error("Redacted must not change the config it copies")
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ListenAddr returns the network and address the HTTP server listens on: a
+// unix socket path for unix:///path, a host and port for tcp://host:port, and
+// every interface on Port when Listen is empty.
+func (c *ServerConfig) ListenAddr() (network, address string, err error) {
+	switch {
+	case c.Listen == "":
+		return "tcp", fmt.Sprintf(":%d", c.Port), nil
+	case strings.HasPrefix(c.Listen, "unix://"):
+		path := strings.TrimPrefix(c.Listen, "unix://")
+		if !strings.HasPrefix(path, "/") {
+			return "", "", fmt.Errorf("invalid SERVER_LISTEN %q: the socket path must be absolute, as in unix:///var/run/resolver.sock", c.Listen)
+		}
+		return "unix", path, nil
+	case strings.HasPrefix(c.Listen, "tcp://"):
+		address := strings.TrimPrefix(c.Listen, "tcp://")
+		if _, port, err := net.SplitHostPort(address); err != nil || port == "" {
+			return "", "", fmt.Errorf("invalid SERVER_LISTEN %q: expected tcp://host:port", c.Listen)
+		}
+		return "tcp", address, nil
+	default:
+		return "", "", fmt.Errorf("invalid SERVER_LISTEN %q: expected tcp://host:port or unix:///path", c.Listen)
+	}
+}
@@ -1,15 +1,45 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Anchor represents a hash anchored on the blockchain
 type Anchor struct {
 	Hash        string    `json:"hash"`
+	Algorithm   string    `json:"algorithm,omitempty"` // HashSHA256 when empty
 	IssuerDID   string    `json:"issuerDid"`
 	Timestamp   time.Time `json:"timestamp"`
 	BlockNumber uint64    `json:"blockNumber"`
 	TxID        string    `json:"txId"`
-	Metadata    string    `json:"metadata,omitempty"`
+
+	// Metadata is canonical JSON and MetadataHash its SHA-256 (hex). Records written
+	// before metadata was structured hold a plain string, which loads as a JSON string.
+	Metadata     json.RawMessage `json:"metadata,omitempty"`
+	MetadataHash string          `json:"metadataHash,omitempty"`
+
+	// SignatureVerified is set when the issuer's signature over the hash was checked on creation
+	SignatureVerified bool `json:"signatureVerified,omitempty"`
+
+	// ExpiresAt is optional; anchors without it never expire
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// Revoked anchors still exist but no longer vouch for the hashed credential
+	Revoked          bool       `json:"revoked,omitempty"`
+	RevokedAt        *time.Time `json:"revokedAt,omitempty"`
+	RevocationReason string     `json:"revocationReason,omitempty"`
+
+	// Tombstoned anchors have had their metadata and issuer erased by an administrator.
+	// Hash, TxID and BlockNumber are kept as evidence that the hash was anchored.
+	Tombstoned      bool       `json:"tombstoned,omitempty"`
+	TombstonedAt    *time.Time `json:"tombstonedAt,omitempty"`
+	TombstoneReason string     `json:"tombstoneReason,omitempty"`
+}
+
+// IsExpired reports whether the anchor has an expiry time at or before now.
+func (a *Anchor) IsExpired(now time.Time) bool {
+	return a.ExpiresAt != nil && !now.Before(*a.ExpiresAt)
 }
 
 // DIDDocument represents a DID document (for future use)
@@ -23,15 +53,24 @@ type DIDDocument struct {
 	Service            []Service            `json:"service,omitempty"`
 	Created            time.Time            `json:"created"`
 	Updated            time.Time            `json:"updated"`
+
+	// VersionID starts at 1 on create and is incremented by every update or deactivation.
+	// Records written before versioning have 0, which is reported as version 1.
+	VersionID uint64 `json:"versionId,omitempty"`
+
+	// Deactivated DIDs still resolve, but their keys must no longer be trusted
+	Deactivated   bool       `json:"deactivated,omitempty"`
+	DeactivatedAt *time.Time `json:"deactivatedAt,omitempty"`
 }
 
 // VerificationMethod represents a public key for verification
 type VerificationMethod struct {
-	ID              string `json:"id"`
-	Type            string `json:"type"`
-	Controller      string `json:"controller"`
-	PublicKeyJwk    string `json:"publicKeyJwk,omitempty"`
-	PublicKeyBase58 string `json:"publicKeyBase58,omitempty"`
+	ID                 string `json:"id"`
+	Type               string `json:"type"`
+	Controller         string `json:"controller"`
+	PublicKeyJwk       *JWK   `json:"publicKeyJwk,omitempty"`
+	PublicKeyBase58    string `json:"publicKeyBase58,omitempty"`
+	PublicKeyMultibase string `json:"publicKeyMultibase,omitempty"`
 }
 
 // Service represents a service endpoint in a DID document
@@ -0,0 +1,256 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"fabric-resolver/internal/pkg/multibase"
+)
+
+// ErrInvalidDID is returned (wrapped) when a DID or its verification methods fail validation.
+var ErrInvalidDID = errors.New("invalid DID")
+
+// FieldError attributes a validation failure to a field of a DID document,
+// such as "verificationMethod[1]".
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string { return e.Field + ": " + e.Err.Error() }
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// DefaultDIDMethods are the DID methods accepted when no allow-list is configured.
+var DefaultDIDMethods = []string{"ewallet", "key", "web"}
+
+// SupportedVerificationMethodTypes lists the verification method types a DID document may use.
+var SupportedVerificationMethodTypes = []string{
+	"Ed25519VerificationKey2018",
+	"Ed25519VerificationKey2020",
+	"JsonWebKey2020",
+	"EcdsaSecp256k1VerificationKey2019",
+	"X25519KeyAgreementKey2019",
+}
+
+// DefaultMaxVerificationMethods caps the verification methods of a document when no limit is configured.
+const DefaultMaxVerificationMethods = 20
+
+// DIDValidator checks DIDs against the DID Core syntax and an allow-list of methods.
+type DIDValidator struct {
+	methods    map[string]bool
+	maxMethods int
+}
+
+// NewDIDValidator returns a validator accepting the given methods (without the "did:" prefix).
+// An empty list falls back to DefaultDIDMethods.
+func NewDIDValidator(methods []string) *DIDValidator {
+	if len(methods) == 0 {
+		methods = DefaultDIDMethods
+	}
+	v := &DIDValidator{methods: make(map[string]bool, len(methods)), maxMethods: DefaultMaxVerificationMethods}
+	for _, m := range methods {
+		v.methods[strings.TrimPrefix(strings.TrimSpace(m), "did:")] = true
+	}
+	return v
+}
+
+// WithMaxVerificationMethods limits documents to n verification methods and returns v.
+// n <= 0 keeps DefaultMaxVerificationMethods.
+func (v *DIDValidator) WithMaxVerificationMethods(n int) *DIDValidator {
+	if n > 0 {
+		v.maxMethods = n
+	}
+	return v
+}
+
+// ValidateDID checks the syntax of did and that its method is allowed.
+func (v *DIDValidator) ValidateDID(did string) error {
+	method, _, err := ParseDID(did)
+	if err != nil {
+		return err
+	}
+	if !v.methods[method] {
+		return fmt.Errorf("%w: method %q is not supported", ErrInvalidDID, method)
+	}
+	return nil
+}
+
+// ValidateDocument checks the document's DID and each of its verification methods.
+func (v *DIDValidator) ValidateDocument(doc *DIDDocument) error {
+	if err := v.ValidateDID(doc.ID); err != nil {
+		return err
+	}
+	if len(doc.VerificationMethod) > v.maxMethods {
+		return &FieldError{Field: "verificationMethod", Err: fmt.Errorf("%w: at most %d verification methods are allowed, got %d",
+			ErrInvalidDID, v.maxMethods, len(doc.VerificationMethod))}
+	}
+	for i := range doc.VerificationMethod {
+		if err := ValidateVerificationMethod(&doc.VerificationMethod[i]); err != nil {
+			return &FieldError{Field: fmt.Sprintf("verificationMethod[%d]", i), Err: err}
+		}
+	}
+	for i := range doc.Service {
+		if err := ValidateService(&doc.Service[i]); err != nil {
+			return &FieldError{Field: fmt.Sprintf("service[%d]", i), Err: err}
+		}
+	}
+	return nil
+}
+
+// ParseDID splits a DID into its method and method-specific id, following the DID Core ABNF:
+//
+//	did                = "did:" method-name ":" method-specific-id
+//	method-name        = 1*method-char
+//	method-char        = %x61-7A / DIGIT
+//	method-specific-id = *( *idchar ":" ) 1*idchar
+//	idchar             = ALPHA / DIGIT / "." / "-" / "_" / pct-encoded
+func ParseDID(did string) (method, id string, err error) {
+	rest, ok := strings.CutPrefix(did, "did:")
+	if !ok {
+		return "", "", fmt.Errorf("%w: scheme must be \"did:\"", ErrInvalidDID)
+	}
+
+	method, id, ok = strings.Cut(rest, ":")
+	if !ok {
+		return "", "", fmt.Errorf("%w: missing method-specific-id", ErrInvalidDID)
+	}
+	if method == "" {
+		return "", "", fmt.Errorf("%w: method-name is empty", ErrInvalidDID)
+	}
+	for i := 0; i < len(method); i++ {
+		if c := method[i]; !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9') {
+			return "", "", fmt.Errorf("%w: method-name %q must contain only lowercase letters and digits", ErrInvalidDID, method)
+		}
+	}
+
+	if id == "" || strings.HasSuffix(id, ":") {
+		return "", "", fmt.Errorf("%w: method-specific-id must not be empty or end with \":\"", ErrInvalidDID)
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case isIDChar(c) || c == ':':
+		case c == '%':
+			if i+2 >= len(id) || !isHex(id[i+1]) || !isHex(id[i+2]) {
+				return "", "", fmt.Errorf("%w: method-specific-id %q has a malformed percent-encoding at offset %d", ErrInvalidDID, id, i)
+			}
+			i += 2
+		default:
+			return "", "", fmt.Errorf("%w: method-specific-id %q contains invalid character %q", ErrInvalidDID, id, c)
+		}
+	}
+
+	return method, id, nil
+}
+
+// NormalizeDID returns the form DIDs are stored and looked up under: the "did"
+// scheme and the method name in lowercase, percent-encodings in uppercase hex
+// (RFC 3986 section 6.2.2.1) and no trailing slash. The method-specific id keeps
+// its case, since methods may treat it as case-sensitive.
+func NormalizeDID(did string) string {
+	did = strings.TrimRight(did, "/")
+
+	if len(did) >= 4 && strings.EqualFold(did[:4], "did:") {
+		if end := strings.IndexByte(did[4:], ':'); end >= 0 {
+			did = strings.ToLower(did[:4+end]) + did[4+end:]
+		}
+	}
+
+	b := []byte(did)
+	for i := 0; i+2 < len(b); i++ {
+		if b[i] == '%' && isHex(b[i+1]) && isHex(b[i+2]) {
+			b[i+1], b[i+2] = upperHex(b[i+1]), upperHex(b[i+2])
+			i += 2
+		}
+	}
+	return string(b)
+}
+
+func upperHex(c byte) byte {
+	if c >= 'a' && c <= 'f' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+// ValidateVerificationMethod checks that vm has a supported type and exactly one
+// well-formed key material field.
+func ValidateVerificationMethod(vm *VerificationMethod) error {
+	if vm.Type == "" {
+		return fmt.Errorf("%w: type is required", ErrInvalidDID)
+	}
+	if !slices.Contains(SupportedVerificationMethodTypes, vm.Type) {
+		return fmt.Errorf("%w: type %q is not supported", ErrInvalidDID, vm.Type)
+	}
+
+	keys := 0
+	for _, present := range []bool{vm.PublicKeyJwk != nil, vm.PublicKeyBase58 != "", vm.PublicKeyMultibase != ""} {
+		if present {
+			keys++
+		}
+	}
+	switch {
+	case keys == 0:
+		return fmt.Errorf("%w: key material is required (publicKeyJwk, publicKeyBase58 or publicKeyMultibase)", ErrInvalidDID)
+	case keys > 1:
+		return fmt.Errorf("%w: only one of publicKeyJwk, publicKeyBase58 or publicKeyMultibase may be set", ErrInvalidDID)
+	}
+
+	if vm.PublicKeyJwk != nil {
+		return vm.PublicKeyJwk.Validate()
+	}
+	if vm.PublicKeyMultibase != "" {
+		return validateMultibaseKey(vm.Type, vm.PublicKeyMultibase)
+	}
+	if _, err := multibase.Decode(string(multibase.Base58BTC) + vm.PublicKeyBase58); err != nil {
+		return fmt.Errorf("%w: publicKeyBase58 is not valid base58", ErrInvalidDID)
+	}
+	return nil
+}
+
+// ed25519MulticodecPrefix is the multicodec header for an Ed25519 public key
+// (0xed, varint-encoded), which Ed25519VerificationKey2020 keys carry.
+var ed25519MulticodecPrefix = []byte{0xed, 0x01}
+
+// validateMultibaseKey checks that key decodes and, for Ed25519 types, that it
+// holds a 32-byte public key (with or without the multicodec header).
+func validateMultibaseKey(keyType, key string) error {
+	raw, err := multibase.Decode(key)
+	if err != nil {
+		return fmt.Errorf("%w: publicKeyMultibase: %v", ErrInvalidDID, err)
+	}
+	if !strings.HasPrefix(keyType, "Ed25519") {
+		return nil
+	}
+	raw = bytes.TrimPrefix(raw, ed25519MulticodecPrefix)
+	if len(raw) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: publicKeyMultibase must decode to a %d-byte Ed25519 key, got %d bytes", ErrInvalidDID, ed25519.PublicKeySize, len(raw))
+	}
+	return nil
+}
+
+// ValidateService checks that svc has a type and an absolute serviceEndpoint URI.
+func ValidateService(svc *Service) error {
+	if svc.Type == "" {
+		return fmt.Errorf("%w: type is required", ErrInvalidDID)
+	}
+	u, err := url.Parse(svc.ServiceEndpoint)
+	if err != nil || !u.IsAbs() || (u.Host == "" && u.Opaque == "") {
+		return fmt.Errorf("%w: serviceEndpoint %q must be an absolute URI", ErrInvalidDID, svc.ServiceEndpoint)
+	}
+	return nil
+}
+
+func isIDChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '.' || c == '-' || c == '_'
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/pkg/multibase"
+)
+
+func TestValidateDID(t *testing.T) {
+	v := NewDIDValidator(nil)
+
+	tests := []struct {
+		name    string
+		did     string
+		wantErr string // substring naming the offending part; empty means valid
+	}{
+		{"ewallet", "did:ewallet:123", ""},
+		{"key", "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", ""},
+		{"web with port", "did:web:example.com%3A8443", ""},
+		{"web with path", "did:web:example.com:user:alice", ""},
+		{"empty inner segment", "did:ewallet::x", ""},
+		{"id chars", "did:ewallet:A-b_c.9", ""},
+		{"not a did", "banana", "scheme"},
+		{"uppercase scheme", "DID:ewallet:123", "scheme"},
+		{"no method-specific-id", "did:ewallet", "method-specific-id"},
+		{"empty method", "did::123", "method-name"},
+		{"uppercase method", "did:eWallet:123", "method-name"},
+		{"method with dash", "did:e-wallet:123", "method-name"},
+		{"empty method-specific-id", "did:ewallet:", "method-specific-id"},
+		{"trailing colon", "did:web:example.com:", "method-specific-id"},
+		{"space in id", "did:ewallet:a b", "method-specific-id"},
+		{"slash in id", "did:web:example.com/path", "method-specific-id"},
+		{"truncated percent", "did:web:example.com%3", "percent-encoding"},
+		{"non-hex percent", "did:web:example.com%zz", "percent-encoding"},
+		{"lone percent", "did:ewallet:%", "percent-encoding"},
+		{"method not allowed", "did:example:123", "method \"example\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.ValidateDID(tt.did)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected %q to be valid, got %v", tt.did, err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidDID) {
+				t.Fatalf("expected ErrInvalidDID for %q, got %v", tt.did, err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeDID(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"did:ewallet:123", "did:ewallet:123"},
+		{"DID:EWallet:AbC", "did:ewallet:AbC"},
+		{"did:ewallet:123/", "did:ewallet:123"},
+		{"did:web:example.com%3a8443", "did:web:example.com%3A8443"},
+		{"did:web:example.com:user%2falice", "did:web:example.com:user%2Falice"},
+		{"did:web:example.com%zz", "did:web:example.com%zz"},
+		{"banana", "banana"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeDID(tt.in); got != tt.want {
+			t.Errorf("NormalizeDID(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewDIDValidator_AllowList(t *testing.T) {
+	v := NewDIDValidator([]string{"did:example", " ion "})
+
+	if err := v.ValidateDID("did:example:123"); err != nil {
+		t.Errorf("configured method rejected: %v", err)
+	}
+	if err := v.ValidateDID("did:ion:abc"); err != nil {
+		t.Errorf("configured method rejected: %v", err)
+	}
+	if err := v.ValidateDID("did:ewallet:123"); !errors.Is(err, ErrInvalidDID) {
+		t.Errorf("default method should not be allowed when a list is configured, got %v", err)
+	}
+}
+
+// ed25519Multibase is the did:key example key from the did:key spec: multicodec 0xed01 + 32 bytes.
+const ed25519Multibase = "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+
+func TestValidateVerificationMethod(t *testing.T) {
+	tests := []struct {
+		name    string
+		vm      VerificationMethod
+		wantErr string
+	}{
+		{"base58", VerificationMethod{Type: "Ed25519VerificationKey2018", PublicKeyBase58: "abc"}, ""},
+		{"jwk", VerificationMethod{Type: "JsonWebKey2020", PublicKeyJwk: &JWK{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}}, ""},
+		{"incomplete jwk", VerificationMethod{Type: "JsonWebKey2020", PublicKeyJwk: &JWK{Kty: "EC", Crv: "P-256"}}, "requires crv, x and y"},
+		{"missing type", VerificationMethod{PublicKeyBase58: "abc"}, "type is required"},
+		{"unknown type", VerificationMethod{Type: "RsaSignature2018", PublicKeyBase58: "abc"}, "not supported"},
+		{"no key material", VerificationMethod{Type: "Ed25519VerificationKey2020"}, "key material"},
+		{"two key fields", VerificationMethod{Type: "Ed25519VerificationKey2018", PublicKeyBase58: "abc", PublicKeyMultibase: ed25519Multibase}, "only one of"},
+		{"multibase raw key", VerificationMethod{Type: "Ed25519VerificationKey2020", PublicKeyMultibase: multibase.Encode(make([]byte, 32))}, ""},
+		{"multibase with multicodec", VerificationMethod{Type: "Ed25519VerificationKey2020", PublicKeyMultibase: ed25519Multibase}, ""},
+		{"multibase bad prefix", VerificationMethod{Type: "Ed25519VerificationKey2020", PublicKeyMultibase: "x6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}, "unsupported prefix"},
+		{"multibase bad character", VerificationMethod{Type: "Ed25519VerificationKey2020", PublicKeyMultibase: "z0OIl"}, "invalid encoding"},
+		{"multibase short key", VerificationMethod{Type: "Ed25519VerificationKey2020", PublicKeyMultibase: multibase.Encode(make([]byte, 31))}, "32-byte"},
+		{"multibase long key", VerificationMethod{Type: "Ed25519VerificationKey2018", PublicKeyMultibase: multibase.Encode(make([]byte, 33))}, "32-byte"},
+		{"base58 bad character", VerificationMethod{Type: "Ed25519VerificationKey2018", PublicKeyBase58: "0OIl"}, "not valid base58"},
+		{"empty jwk", VerificationMethod{Type: "JsonWebKey2020", PublicKeyJwk: &JWK{}}, "kty is required"},
+		{"multibase non-Ed25519", VerificationMethod{Type: "EcdsaSecp256k1VerificationKey2019", PublicKeyMultibase: multibase.Encode(make([]byte, 33))}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateVerificationMethod(&tt.vm)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateDocument_NamesMethodIndex(t *testing.T) {
+	doc := &DIDDocument{
+		ID: "did:ewallet:doc",
+		VerificationMethod: []VerificationMethod{
+			{Type: "Ed25519VerificationKey2018", PublicKeyBase58: "abc"},
+			{Type: "Ed25519VerificationKey2018"},
+		},
+	}
+
+	err := NewDIDValidator(nil).ValidateDocument(doc)
+	if err == nil || !strings.HasPrefix(err.Error(), "verificationMethod[1]") {
+		t.Errorf("expected error for verificationMethod[1], got %v", err)
+	}
+}
+
+func TestValidateDocument_MaxVerificationMethods(t *testing.T) {
+	doc := &DIDDocument{ID: "did:ewallet:doc"}
+	for range 3 {
+		doc.VerificationMethod = append(doc.VerificationMethod, VerificationMethod{Type: "Ed25519VerificationKey2018", PublicKeyBase58: "abc"})
+	}
+
+	if err := NewDIDValidator(nil).WithMaxVerificationMethods(3).ValidateDocument(doc); err != nil {
+		t.Fatalf("expected 3 methods to be within a limit of 3, got %v", err)
+	}
+	err := NewDIDValidator(nil).WithMaxVerificationMethods(2).ValidateDocument(doc)
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "verificationMethod" || !strings.Contains(err.Error(), "at most 2") {
+		t.Errorf("expected a verificationMethod limit error, got %v", err)
+	}
+}
+
+func TestValidateService(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		valid    bool
+	}{
+		{"https", "https://wallet.example.com/didcomm", true},
+		{"urn", "urn:uuid:6e8bc430-9c3a-11d9-9669-0800200c9a66", true},
+		{"relative path", "/didcomm", false},
+		{"no scheme", "wallet.example.com", false},
+		{"empty", "", false},
+		{"scheme only", "https://", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateService(&Service{Type: "DIDCommMessaging", ServiceEndpoint: tt.endpoint})
+			if tt.valid && err != nil {
+				t.Errorf("expected %q to be valid, got %v", tt.endpoint, err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidDID) {
+				t.Errorf("expected %q to be rejected, got %v", tt.endpoint, err)
+			}
+		})
+	}
+
+	if err := ValidateService(&Service{ServiceEndpoint: "https://example.com"}); err == nil {
+		t.Error("expected an error for a service without a type")
+	}
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Hash algorithms accepted for anchored hashes
+const (
+	HashSHA256     = "sha256"
+	HashSHA512     = "sha512"
+	HashBlake2b256 = "blake2b-256"
+)
+
+// DefaultHashAlgorithm is assumed when an anchor does not name its algorithm.
+const DefaultHashAlgorithm = HashSHA256
+
+// hashHexLengths is the hex-encoded digest length of each supported algorithm.
+var hashHexLengths = map[string]int{
+	HashSHA256:     64,
+	HashSHA512:     128,
+	HashBlake2b256: 64,
+}
+
+// HashFormatError describes why a hash does not match its algorithm.
+// ExpectedLength is zero when the algorithm itself is unsupported.
+type HashFormatError struct {
+	Algorithm      string
+	ExpectedLength int
+	Reason         string
+}
+
+func (e *HashFormatError) Error() string {
+	return e.Reason
+}
+
+// ValidateHash checks that hash is lowercase hex of the length produced by algorithm.
+// An empty algorithm means DefaultHashAlgorithm. It returns the algorithm in effect.
+func ValidateHash(hash, algorithm string) (string, error) {
+	if algorithm == "" {
+		algorithm = DefaultHashAlgorithm
+	}
+	length, ok := hashHexLengths[algorithm]
+	if !ok {
+		return "", &HashFormatError{
+			Algorithm: algorithm,
+			Reason:    fmt.Sprintf("algorithm %q is not supported (use %s, %s or %s)", algorithm, HashSHA256, HashSHA512, HashBlake2b256),
+		}
+	}
+
+	for i := 0; i < len(hash); i++ {
+		if c := hash[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return "", &HashFormatError{
+				Algorithm:      algorithm,
+				ExpectedLength: length,
+				Reason:         fmt.Sprintf("hash must be lowercase hex, found %q at offset %d", c, i),
+			}
+		}
+	}
+	if len(hash) != length {
+		return "", &HashFormatError{
+			Algorithm:      algorithm,
+			ExpectedLength: length,
+			Reason:         fmt.Sprintf("a %s hash must be %d hex characters, got %d", algorithm, length, len(hash)),
+		}
+	}
+	return algorithm, nil
+}
+
+// NormalizeHash returns the form hashes are stored under, so lookups are case-insensitive.
+func NormalizeHash(hash string) string {
+	return strings.ToLower(hash)
+}
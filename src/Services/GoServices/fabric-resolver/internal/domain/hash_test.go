@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateHash(t *testing.T) {
+	sha256Hex := strings.Repeat("ab", 32)
+	sha512Hex := strings.Repeat("cd", 64)
+
+	tests := []struct {
+		name      string
+		hash      string
+		algorithm string
+		want      string // algorithm in effect; empty when invalid
+		wantLen   int    // ExpectedLength of the error
+	}{
+		{"sha256 default", sha256Hex, "", HashSHA256, 0},
+		{"sha256 explicit", sha256Hex, HashSHA256, HashSHA256, 0},
+		{"sha512", sha512Hex, HashSHA512, HashSHA512, 0},
+		{"blake2b-256", sha256Hex, HashBlake2b256, HashBlake2b256, 0},
+		{"sha512 hash labelled sha256", sha512Hex, "", "", 64},
+		{"sha256 hash labelled sha512", sha256Hex, HashSHA512, "", 128},
+		{"too short", "abcd", HashSHA256, "", 64},
+		{"empty", "", HashSHA256, "", 64},
+		{"not hex", "hello", "", "", 64},
+		{"uppercase", strings.ToUpper(sha256Hex), "", "", 64},
+		{"unsupported algorithm", sha256Hex, "md5", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateHash(tt.hash, tt.algorithm)
+			if tt.want != "" {
+				if err != nil || got != tt.want {
+					t.Fatalf("expected %q, got %q (%v)", tt.want, got, err)
+				}
+				return
+			}
+
+			var formatErr *HashFormatError
+			if !errors.As(err, &formatErr) {
+				t.Fatalf("expected HashFormatError, got %v", err)
+			}
+			if formatErr.ExpectedLength != tt.wantLen {
+				t.Errorf("expected length %d, got %d (%v)", tt.wantLen, formatErr.ExpectedLength, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JWK is a public JSON Web Key (RFC 7517). Private members such as "d" are
+// deliberately not modelled, so they are dropped if a caller sends them.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+}
+
+// jwkFields has JWK's fields without its methods, to avoid recursing into UnmarshalJSON.
+type jwkFields JWK
+
+// UnmarshalJSON accepts a JWK object, or the legacy form where the JWK
+// was stored as a JSON-encoded string. Legacy records are upgraded to the
+// object form the next time they are written.
+func (j *JWK) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var encoded string
+		if err := json.Unmarshal(data, &encoded); err != nil {
+			return err
+		}
+		if encoded == "" {
+			*j = JWK{}
+			return nil
+		}
+		data = []byte(encoded)
+	}
+
+	var fields jwkFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("publicKeyJwk: %w", err)
+	}
+	*j = JWK(fields)
+	return nil
+}
+
+// Validate checks that the members required by the key type are present.
+func (j *JWK) Validate() error {
+	switch j.Kty {
+	case "":
+		return fmt.Errorf("%w: publicKeyJwk kty is required", ErrInvalidDID)
+	case "EC":
+		if j.Crv == "" || j.X == "" || j.Y == "" {
+			return fmt.Errorf("%w: publicKeyJwk of kty EC requires crv, x and y", ErrInvalidDID)
+		}
+	case "OKP":
+		if j.Crv == "" || j.X == "" {
+			return fmt.Errorf("%w: publicKeyJwk of kty OKP requires crv and x", ErrInvalidDID)
+		}
+	default:
+		return fmt.Errorf("%w: publicKeyJwk kty %q is not supported", ErrInvalidDID, j.Kty)
+	}
+	return nil
+}
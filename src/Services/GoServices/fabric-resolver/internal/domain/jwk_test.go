@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const (
+	p256JWK = `{"kty":"EC","crv":"P-256","x":"f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU","y":"x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0","kid":"key-1"}`
+	okpJWK  = `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+)
+
+func TestJWKUnmarshal(t *testing.T) {
+	legacy, _ := json.Marshal(okpJWK)
+
+	tests := []struct {
+		name string
+		data string
+		want JWK
+	}{
+		{"EC P-256 object", p256JWK, JWK{Kty: "EC", Crv: "P-256", X: "f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU", Y: "x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0", Kid: "key-1"}},
+		{"OKP object", okpJWK, JWK{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}},
+		{"legacy string", string(legacy), JWK{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}},
+		{"legacy empty string", `""`, JWK{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got JWK
+			if err := json.Unmarshal([]byte(tt.data), &got); err != nil {
+				t.Fatalf("unmarshal failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	var bad JWK
+	if err := json.Unmarshal([]byte(`"not json"`), &bad); err == nil {
+		t.Error("expected an error for a legacy string that is not a JWK")
+	}
+}
+
+func TestJWKDropsPrivateKey(t *testing.T) {
+	var jwk JWK
+	if err := json.Unmarshal([]byte(`{"kty":"OKP","crv":"Ed25519","x":"abc","d":"secret"}`), &jwk); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	out, _ := json.Marshal(&jwk)
+	if strings.Contains(string(out), "secret") {
+		t.Errorf("private key member survived: %s", out)
+	}
+}
+
+func TestJWKValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		jwk   JWK
+		valid bool
+	}{
+		{"EC P-256", JWK{Kty: "EC", Crv: "P-256", X: "x", Y: "y"}, true},
+		{"OKP Ed25519", JWK{Kty: "OKP", Crv: "Ed25519", X: "x"}, true},
+		{"missing kty", JWK{Crv: "Ed25519", X: "x"}, false},
+		{"EC without y", JWK{Kty: "EC", Crv: "P-256", X: "x"}, false},
+		{"OKP without x", JWK{Kty: "OKP", Crv: "Ed25519"}, false},
+		{"RSA", JWK{Kty: "RSA"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.jwk.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("expected valid, got %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidDID) {
+				t.Errorf("expected ErrInvalidDID, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"fabric-resolver/internal/pkg/multibase"
+)
+
+var (
+	// ErrSignatureInvalid is returned when a signature does not verify against the key.
+	ErrSignatureInvalid = errors.New("signature verification failed")
+	// ErrUnsupportedKey is returned for key material signatures cannot be checked with.
+	ErrUnsupportedKey = errors.New("unsupported verification key")
+)
+
+// PublicKey decodes the key material of vm into an ed25519.PublicKey or *ecdsa.PublicKey.
+func (vm *VerificationMethod) PublicKey() (interface{}, error) {
+	switch {
+	case vm.PublicKeyJwk != nil:
+		return vm.PublicKeyJwk.PublicKey()
+	case !strings.HasPrefix(vm.Type, "Ed25519"):
+		// Only Ed25519 keys are encoded as multibase or base58 here
+		return nil, fmt.Errorf("%w: type %q", ErrUnsupportedKey, vm.Type)
+	case vm.PublicKeyMultibase != "":
+		raw, err := multibase.Decode(vm.PublicKeyMultibase)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedKey, err)
+		}
+		return ed25519Key(bytes.TrimPrefix(raw, ed25519MulticodecPrefix))
+	case vm.PublicKeyBase58 != "":
+		raw, err := multibase.Decode(string(multibase.Base58BTC) + vm.PublicKeyBase58)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedKey, err)
+		}
+		return ed25519Key(raw)
+	}
+	return nil, fmt.Errorf("%w: no key material", ErrUnsupportedKey)
+}
+
+// PublicKey decodes an OKP Ed25519 or EC P-256 JWK.
+func (j *JWK) PublicKey() (interface{}, error) {
+	x, err := base64.RawURLEncoding.DecodeString(j.X)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid JWK x: %v", ErrUnsupportedKey, err)
+	}
+
+	switch {
+	case j.Kty == "OKP" && j.Crv == "Ed25519":
+		return ed25519Key(x)
+	case j.Kty == "EC" && j.Crv == "P-256":
+		y, err := base64.RawURLEncoding.DecodeString(j.Y)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid JWK y: %v", ErrUnsupportedKey, err)
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if len(x) != 32 || len(y) != 32 || !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("%w: JWK is not a P-256 point", ErrUnsupportedKey)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: JWK kty %q crv %q", ErrUnsupportedKey, j.Kty, j.Crv)
+}
+
+// VerifySignature checks signature over message with the key of vm.
+// Ed25519 signatures are verified over message directly; ECDSA P-256 signatures
+// (raw r||s as in JWS ES256, or ASN.1 DER) over its SHA-256 digest.
+func (vm *VerificationMethod) VerifySignature(message, signature []byte) error {
+	key, err := vm.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	var ok bool
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(k, message, signature)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		if len(signature) == 64 {
+			r := new(big.Int).SetBytes(signature[:32])
+			s := new(big.Int).SetBytes(signature[32:])
+			ok = ecdsa.Verify(k, digest[:], r, s)
+		} else {
+			ok = ecdsa.VerifyASN1(k, digest[:], signature)
+		}
+	}
+	if !ok {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+func ed25519Key(raw []byte) (ed25519.PublicKey, error) {
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: Ed25519 key must be %d bytes, got %d", ErrUnsupportedKey, ed25519.PublicKeySize, len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"fabric-resolver/internal/pkg/multibase"
+)
+
+func TestVerifySignature_Ed25519Encodings(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	msg := []byte("abc123")
+	sig := ed25519.Sign(priv, msg)
+
+	methods := map[string]VerificationMethod{
+		"base58":    {Type: "Ed25519VerificationKey2018", PublicKeyBase58: multibase.Encode(pub)[1:]},
+		"multibase": {Type: "Ed25519VerificationKey2020", PublicKeyMultibase: multibase.Encode(append([]byte{0xed, 0x01}, pub...))},
+		"jwk":       {Type: "JsonWebKey2020", PublicKeyJwk: &JWK{Kty: "OKP", Crv: "Ed25519", X: b64(pub)}},
+	}
+
+	for name, vm := range methods {
+		t.Run(name, func(t *testing.T) {
+			if err := vm.VerifySignature(msg, sig); err != nil {
+				t.Errorf("valid signature rejected: %v", err)
+			}
+			if err := vm.VerifySignature([]byte("other"), sig); !errors.Is(err, ErrSignatureInvalid) {
+				t.Errorf("expected ErrSignatureInvalid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifySignature_UnsupportedKeys(t *testing.T) {
+	methods := map[string]VerificationMethod{
+		"no key":         {Type: "Ed25519VerificationKey2018"},
+		"secp256k1":      {Type: "EcdsaSecp256k1VerificationKey2019", PublicKeyBase58: "abc"},
+		"short ed25519":  {Type: "Ed25519VerificationKey2018", PublicKeyBase58: multibase.Encode(make([]byte, 16))[1:]},
+		"EC off curve":   {Type: "JsonWebKey2020", PublicKeyJwk: &JWK{Kty: "EC", Crv: "P-256", X: b64(make([]byte, 32)), Y: b64(make([]byte, 32))}},
+		"unsupported EC": {Type: "JsonWebKey2020", PublicKeyJwk: &JWK{Kty: "EC", Crv: "P-384", X: "AA", Y: "AA"}},
+	}
+
+	for name, vm := range methods {
+		if err := vm.VerifySignature([]byte("m"), []byte("s")); !errors.Is(err, ErrUnsupportedKey) {
+			t.Errorf("%s: expected ErrUnsupportedKey, got %v", name, err)
+		}
+	}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// StatusList is a Status List 2021 list kept on the ledger. Credentials reference
+// an index into it; the bit at that index records whether the status applies.
+type StatusList struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`           // where the list document is served; its credential id
+	Purpose     string    `json:"statusPurpose"` // "revocation" or "suspension"
+	EncodedList string    `json:"encodedList"`   // GZIP-compressed bitstring, base64url
+	NextIndex   int       `json:"nextIndex"`     // first index not yet allocated
+	Updated     time.Time `json:"updated"`       // last change to the bitstring
+
+	// AnchorHash is the hash of the list document as last anchored
+	AnchorHash string `json:"anchorHash,omitempty"`
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Webhook event types.
+const (
+	EventAnchorCreated = "anchor.created"
+	EventAnchorRevoked = "anchor.revoked"
+	EventDIDCreated    = "did.created"
+)
+
+// WebhookEvents lists every event type a webhook can subscribe to.
+var WebhookEvents = []string{EventAnchorCreated, EventAnchorRevoked, EventDIDCreated}
+
+// Webhook is a registered receiver of event notifications.
+type Webhook struct {
+	ID      string    `json:"id"`
+	URL     string    `json:"url"`
+	Secret  string    `json:"secret,omitempty"` // HMAC-SHA256 key for the X-Signature header; empty sends unsigned events
+	Events  []string  `json:"events,omitempty"` // empty subscribes to every event
+	Created time.Time `json:"created"`
+}
+
+// Wants reports whether the webhook subscribes to event.
+func (w *Webhook) Wants(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
@@ -0,0 +1,125 @@
+package grpcapi
+
+import (
+	"time"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/domain"
+	resolverv1 "fabric-resolver/proto/resolver/v1"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// timestamp converts an optional time; nil and zero times stay unset.
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func toProtoAnchor(anchor *domain.Anchor) *resolverv1.Anchor {
+	return &resolverv1.Anchor{
+		Hash:              anchor.Hash,
+		Algorithm:         anchor.Algorithm,
+		IssuerDid:         anchor.IssuerDID,
+		Timestamp:         timestamp(&anchor.Timestamp),
+		BlockNumber:       anchor.BlockNumber,
+		TxId:              anchor.TxID,
+		MetadataJson:      string(anchor.Metadata),
+		MetadataHash:      anchor.MetadataHash,
+		SignatureVerified: anchor.SignatureVerified,
+		ExpiresAt:         timestamp(anchor.ExpiresAt),
+		Revoked:           anchor.Revoked,
+		RevokedAt:         timestamp(anchor.RevokedAt),
+		RevocationReason:  anchor.RevocationReason,
+		Tombstoned:        anchor.Tombstoned,
+		TombstonedAt:      timestamp(anchor.TombstonedAt),
+		TombstoneReason:   anchor.TombstoneReason,
+	}
+}
+
+// fromProtoCreateDid converts the request to its HTTP counterpart so both share ToDIDDocument.
+func fromProtoCreateDid(req *resolverv1.CreateDidRequest) handlers.CreateDidRequest {
+	didReq := handlers.CreateDidRequest{
+		Context:    req.GetContext(),
+		Did:        req.GetDid(),
+		Controller: req.GetController(),
+	}
+	for _, vm := range req.GetVerificationMethod() {
+		didReq.VerificationMethod = append(didReq.VerificationMethod, handlers.VerificationMethodRequest{
+			Type:               vm.GetType(),
+			PublicKeyJwk:       fromProtoJwk(vm.GetPublicKeyJwk()),
+			PublicKeyBase58:    vm.GetPublicKeyBase58(),
+			PublicKeyMultibase: vm.GetPublicKeyMultibase(),
+		})
+	}
+	for _, svc := range req.GetService() {
+		didReq.Service = append(didReq.Service, handlers.ServiceRequest{
+			ID:              svc.GetId(),
+			Type:            svc.GetType(),
+			ServiceEndpoint: svc.GetServiceEndpoint(),
+		})
+	}
+	for _, idx := range req.GetAuthentication() {
+		didReq.Authentication = append(didReq.Authentication, int(idx))
+	}
+	for _, idx := range req.GetAssertionMethod() {
+		didReq.AssertionMethod = append(didReq.AssertionMethod, int(idx))
+	}
+	return didReq
+}
+
+func fromProtoJwk(jwk *resolverv1.Jwk) *domain.JWK {
+	if jwk == nil {
+		return nil
+	}
+	return &domain.JWK{Kty: jwk.GetKty(), Crv: jwk.GetCrv(), X: jwk.GetX(), Y: jwk.GetY(), Kid: jwk.GetKid(), Alg: jwk.GetAlg(), Use: jwk.GetUse()}
+}
+
+func toProtoJwk(jwk *domain.JWK) *resolverv1.Jwk {
+	if jwk == nil {
+		return nil
+	}
+	return &resolverv1.Jwk{Kty: jwk.Kty, Crv: jwk.Crv, X: jwk.X, Y: jwk.Y, Kid: jwk.Kid, Alg: jwk.Alg, Use: jwk.Use}
+}
+
+func toProtoDidDocument(didDoc *domain.DIDDocument) *resolverv1.DidDocument {
+	doc := &resolverv1.DidDocument{
+		Context:         didDoc.Context,
+		Id:              didDoc.ID,
+		Controller:      didDoc.Controller,
+		Authentication:  didDoc.Authentication,
+		AssertionMethod: didDoc.AssertionMethod,
+		Created:         timestamp(&didDoc.Created),
+		Updated:         timestamp(&didDoc.Updated),
+		Deactivated:     didDoc.Deactivated,
+		DeactivatedAt:   timestamp(didDoc.DeactivatedAt),
+		VersionId:       max(didDoc.VersionID, 1),
+	}
+
+	allKeys := make([]string, 0, len(didDoc.VerificationMethod))
+	for _, vm := range didDoc.VerificationMethod {
+		doc.VerificationMethod = append(doc.VerificationMethod, &resolverv1.VerificationMethod{
+			Id:                 vm.ID,
+			Type:               vm.Type,
+			Controller:         vm.Controller,
+			PublicKeyJwk:       toProtoJwk(vm.PublicKeyJwk),
+			PublicKeyBase58:    vm.PublicKeyBase58,
+			PublicKeyMultibase: vm.PublicKeyMultibase,
+		})
+		allKeys = append(allKeys, vm.ID)
+	}
+	// Documents stored without relationships (older records) use every key for both
+	if len(doc.Authentication) == 0 {
+		doc.Authentication = allKeys
+	}
+	if len(doc.AssertionMethod) == 0 {
+		doc.AssertionMethod = allKeys
+	}
+
+	for _, svc := range didDoc.Service {
+		doc.Service = append(doc.Service, &resolverv1.Service{Id: svc.ID, Type: svc.Type, ServiceEndpoint: svc.ServiceEndpoint})
+	}
+	return doc
+}
@@ -0,0 +1,47 @@
+package grpcapi
+
+import (
+	"errors"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/infrastructure/fabric"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusFromLedger maps a ledger error to its canonical gRPC status. msg prefixes
+// the error text for codes that do not already say what went wrong.
+func statusFromLedger(err error, msg string) error {
+	switch {
+	case errors.Is(err, fabric.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, fabric.ErrExpired):
+		// gRPC has no Gone; an expired record no longer exists for the caller
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, fabric.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, fabric.ErrValidation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, fabric.ErrDeactivated):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, fabric.ErrClientClosed), errors.Is(err, fabric.ErrTransient), errors.Is(err, fabric.ErrReadOnly):
+		return status.Error(codes.Unavailable, msg+": "+err.Error())
+	}
+	return status.Error(codes.Internal, msg+": "+err.Error())
+}
+
+// statusFromLookup maps a failed handlers.LookupDid as GET /dids/{did} does: a
+// malformed did:key is the caller's error, and a did:web host that cannot be
+// reached leaves the DID unresolved rather than missing.
+func statusFromLookup(source string, err error) error {
+	switch {
+	case source == handlers.SourceDidKey:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case source == handlers.SourceDidWeb && !handlers.IsDidNotFound(err):
+		return status.Error(codes.Unavailable, "failed to resolve did:web: "+err.Error())
+	case handlers.IsDidNotFound(err):
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return statusFromLedger(err, "failed to resolve DID")
+}
@@ -0,0 +1,378 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"fabric-resolver/internal/api"
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/apikeys"
+	"fabric-resolver/internal/audit"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/jwtauth"
+	"fabric-resolver/internal/ratelimit"
+	"fabric-resolver/internal/requestid"
+	resolverv1 "fabric-resolver/proto/resolver/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys of the credentials and request id of a call, named after the HTTP
+// headers that carry them.
+const (
+	apiKeyMetadata        = "x-api-key"
+	authorizationMetadata = "authorization"
+	requestIDMetadata     = "x-request-id"
+	tenantMetadata        = "x-tenant-id"
+)
+
+// writeScopes maps the methods that write to the bearer token scope their HTTP
+// routes require.
+var writeScopes = map[string]string{
+	resolverv1.ResolverService_CreateAnchor_FullMethodName: api.ScopeAnchorsWrite,
+	resolverv1.ResolverService_CreateDid_FullMethodName:    api.ScopeDIDsWrite,
+}
+
+func isWrite(method string) bool {
+	_, ok := writeScopes[method]
+	return ok
+}
+
+// caller collects who made a call as the interceptors establish it, for its audit
+// entry.
+type caller struct {
+	apiKeyID     string
+	tokenSubject string
+	tenant       string
+}
+
+type callerKey struct{}
+
+// interceptors returns the unary interceptors of opts in the order the HTTP router
+// applies the same checks: request id and client certificate, audit, failed
+// authentication throttling, authentication, rate limiting, then the tenant.
+func interceptors(opts Options) []grpc.UnaryServerInterceptor {
+	chain := []grpc.UnaryServerInterceptor{callContext}
+	if opts.Audit != nil {
+		logger := opts.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		chain = append(chain, auditInterceptor(opts.Audit, opts.AuditStrict, logger))
+	}
+	limited := opts.RateLimitReads != nil || opts.RateLimitWrites != nil
+	if opts.APIKeys.Len() > 0 || opts.JWT != nil {
+		if limited {
+			chain = append(chain, authFailureInterceptor(opts.RateLimitReads, opts.RateLimitWrites))
+		}
+		chain = append(chain, authInterceptor(opts.APIKeys, opts.APIKeysProtectReads, opts.JWT))
+	}
+	if limited {
+		chain = append(chain, rateLimitInterceptor(opts.RateLimitReads, opts.RateLimitWrites))
+	}
+	if opts.Tenants != nil {
+		chain = append(chain, tenantInterceptor(opts.Tenants, opts.TenantAPIKeys))
+	}
+	return chain
+}
+
+// callContext adopts the caller's request id, or generates one, and exposes the
+// subject of a verified client certificate through handlers.ClientSubject.
+func callContext(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id := firstMetadata(ctx, requestIDMetadata)
+	if !requestid.Valid(id) {
+		id = requestid.New()
+	}
+	ctx = requestid.WithID(ctx, id)
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			chains := info.State.VerifiedChains
+			if len(chains) > 0 && len(chains[0]) > 0 {
+				ctx = handlers.WithClientSubject(ctx, chains[0][0].Subject.String())
+			}
+		}
+	}
+	return handler(context.WithValue(ctx, callerKey{}, &caller{}), req)
+}
+
+// authInterceptor requires a valid API key in x-api-key on writes, and on reads
+// too when protectReads is set, and verifies a JWT bearer token in authorization;
+// writes then need their scope, as over HTTP. The key id and token claims are
+// attached to the context.
+func authInterceptor(keys *apikeys.Keys, protectReads bool, verifier *jwtauth.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		who, _ := ctx.Value(callerKey{}).(*caller)
+		if who == nil {
+			who = &caller{}
+		}
+
+		if keys.Len() > 0 && (isWrite(info.FullMethod) || protectReads) {
+			presented := firstMetadata(ctx, apiKeyMetadata)
+			id, ok := keys.Lookup(presented)
+			if !ok {
+				if presented == "" {
+					return nil, status.Error(codes.Unauthenticated, "API key required")
+				}
+				return nil, status.Error(codes.Unauthenticated, "invalid API key")
+			}
+			who.apiKeyID = id
+			ctx = handlers.WithAPIKeyID(ctx, id)
+		}
+
+		if verifier != nil {
+			token, ok := strings.CutPrefix(firstMetadata(ctx, authorizationMetadata), "Bearer ")
+			if ok && jwtauth.IsJWT(token) {
+				claims, err := verifier.Verify(ctx, token)
+				if err != nil {
+					return nil, tokenError(err)
+				}
+				who.tokenSubject = claims.Subject
+				ctx = handlers.WithTokenClaims(ctx, claims)
+			}
+
+			if scope, ok := writeScopes[info.FullMethod]; ok {
+				claims := handlers.TokenClaims(ctx)
+				if claims == nil {
+					return nil, status.Error(codes.Unauthenticated, "bearer token required")
+				}
+				if !claims.HasScope(scope) && !claims.HasScope(api.ScopeAdmin) {
+					return nil, status.Error(codes.PermissionDenied, "token lacks the "+scope+" scope")
+				}
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func tokenError(err error) error {
+	switch {
+	case errors.Is(err, jwtauth.ErrExpired):
+		return status.Error(codes.Unauthenticated, "token expired")
+	case errors.Is(err, jwtauth.ErrInvalidAudience):
+		return status.Error(codes.Unauthenticated, "token was issued for another audience")
+	}
+	return status.Error(codes.Unauthenticated, "invalid token: "+strings.TrimPrefix(err.Error(), jwtauth.ErrInvalidToken.Error()+": "))
+}
+
+// authFailureInterceptor throttles failed authentication by peer IP, as its HTTP
+// counterpart does: each call failing with Unauthenticated takes a token from the
+// bucket of its IP, and an IP whose bucket is empty is refused before its
+// credentials are looked at.
+func authFailureInterceptor(reads, writes *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		limiter := limiterFor(info.FullMethod, reads, writes)
+		if limiter == nil {
+			return handler(ctx, req)
+		}
+
+		key := "auth-failures:ip:" + peerIP(ctx)
+		if ok, wait := limiter.Peek(key); !ok {
+			return nil, rateLimited(wait)
+		}
+		resp, err := handler(ctx, req)
+		if status.Code(err) == codes.Unauthenticated {
+			limiter.Allow(key)
+		}
+		return resp, err
+	}
+}
+
+// rateLimitInterceptor applies the reads or writes limiter to each call, keyed by
+// client as over HTTP; a nil limiter leaves its class unlimited.
+func rateLimitInterceptor(reads, writes *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		limiter := limiterFor(info.FullMethod, reads, writes)
+		if limiter == nil {
+			return handler(ctx, req)
+		}
+		if ok, wait := limiter.Allow(rateLimitKey(ctx)); !ok {
+			return nil, rateLimited(wait)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// limiterFor returns the limiter of the class of method, nil when it is unlimited.
+func limiterFor(method string, reads, writes *ratelimit.Limiter) *ratelimit.Limiter {
+	if isWrite(method) {
+		return writes
+	}
+	return reads
+}
+
+func rateLimited(wait time.Duration) error {
+	return status.Errorf(codes.ResourceExhausted, "rate limit exceeded; retry after %d seconds", max(1, int(math.Ceil(wait.Seconds()))))
+}
+
+// rateLimitKey identifies the client of a call by its API key, else its bearer
+// token subject, else the IP of its peer.
+func rateLimitKey(ctx context.Context) string {
+	if id := handlers.APIKeyID(ctx); id != "" {
+		return "key:" + id
+	}
+	if claims := handlers.TokenClaims(ctx); claims != nil && claims.Subject != "" {
+		return "sub:" + claims.Subject
+	}
+	return "ip:" + peerIP(ctx)
+}
+
+// tenantInterceptor serves each call from the ledger of its tenant: the tenant
+// its API key is assigned to, or else the one named in x-tenant-id metadata, as
+// over HTTP. Calls naming no tenant fail with InvalidArgument, and those naming
+// a tenant that is not hosted, or not the one of their API key, with
+// PermissionDenied.
+func tenantInterceptor(ledgers *fabric.TenantLedgerProvider, apiKeys map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		tenant := firstMetadata(ctx, tenantMetadata)
+		if keyTenant, ok := apiKeys[handlers.APIKeyID(ctx)]; ok {
+			if tenant != "" && tenant != keyTenant {
+				return nil, status.Error(codes.PermissionDenied, "API key is not valid for tenant "+tenant)
+			}
+			tenant = keyTenant
+		}
+		if tenant == "" {
+			return nil, status.Error(codes.InvalidArgument, tenantMetadata+" metadata required")
+		}
+
+		ledger, err := ledgers.Ledger(ctx, tenant)
+		switch {
+		case errors.Is(err, fabric.ErrUnknownTenant):
+			return nil, status.Error(codes.PermissionDenied, "unknown tenant")
+		case err != nil:
+			return nil, status.Error(codes.Unavailable, "ledger unavailable: "+err.Error())
+		}
+
+		if who, ok := ctx.Value(callerKey{}).(*caller); ok {
+			who.tenant = tenant
+		}
+		return handler(handlers.WithLedger(handlers.WithTenant(ctx, tenant), ledger), req)
+	}
+}
+
+// auditInterceptor appends an entry to log for every write call once its outcome
+// is known; calls refused by authentication are recorded too. In strict mode an
+// attempt entry is appended first, and a call whose attempt or result cannot be
+// recorded fails with Unavailable, as its HTTP counterpart is answered 503; only
+// a failed attempt keeps the write from being made.
+func auditInterceptor(log *audit.Log, strict bool, logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !isWrite(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		record := &handlers.AuditRecord{}
+		appendEntry := func(entry audit.Entry) error {
+			err := log.Append(entry)
+			if err != nil {
+				logger.Error("Failed to append audit entry", "err", err, "strict", strict, "result", entry.Result,
+					"method", info.FullMethod, "request_id", requestid.FromContext(ctx))
+			}
+			return err
+		}
+		unavailable := status.Error(codes.Unavailable, "the audit log cannot be written")
+		if strict {
+			attempt := auditEntry(ctx, info.FullMethod, record, 0)
+			attempt.Result = audit.ResultAttempt
+			if err := appendEntry(attempt); err != nil {
+				return nil, unavailable
+			}
+		}
+
+		resp, err := handler(handlers.WithAuditRecord(ctx, record), req)
+		if appendErr := appendEntry(auditEntry(ctx, info.FullMethod, record, httpStatus(status.Code(err)))); appendErr != nil && strict {
+			return nil, unavailable
+		}
+		return resp, err
+	}
+}
+
+// auditEntry builds the entry of the call to method answered with the HTTP
+// equivalent of its status. gRPC calls are HTTP/2 POSTs to the method's path.
+func auditEntry(ctx context.Context, method string, record *handlers.AuditRecord, status int) audit.Entry {
+	entry := audit.Entry{
+		RequestID: requestid.FromContext(ctx),
+		Principal: "anonymous",
+		Admin:     record.Admin,
+		Method:    http.MethodPost,
+		Route:     method,
+		Resource:  record.Resource,
+		Status:    status,
+		Result:    audit.ResultOf(status),
+		SourceIP:  peerIP(ctx),
+	}
+
+	who, _ := ctx.Value(callerKey{}).(*caller)
+	if who == nil {
+		who = &caller{}
+	}
+	entry.Tenant = who.tenant
+	switch subject := handlers.ClientSubject(ctx); {
+	case who.tokenSubject != "":
+		entry.Principal = "token:" + who.tokenSubject
+	case who.apiKeyID != "":
+		entry.Principal = "key:" + who.apiKeyID
+	case subject != "":
+		entry.Principal = "cert:" + subject
+	}
+	return entry
+}
+
+// httpStatus returns the HTTP status the handlers answer the same outcome with, so
+// audit entries of both transports classify alike.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.FailedPrecondition:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// firstMetadata returns the first value of key in the incoming metadata of ctx.
+func firstMetadata(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// peerIP returns the IP of the peer of a call, or its address when it has none.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,248 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/apikeys"
+	"fabric-resolver/internal/audit"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/jwtauth/jwttest"
+	"fabric-resolver/internal/ratelimit"
+	resolverv1 "fabric-resolver/proto/resolver/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+const testAPIKey = "0123456789abcdef0123"
+
+func testKeys(t *testing.T) *apikeys.Keys {
+	t.Helper()
+	keys, err := apikeys.NewKeys(map[string]string{"issuer-a": testAPIKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func withMetadata(ctx context.Context, kv ...string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+func TestAuth_APIKeys(t *testing.T) {
+	client, _ := newTestClientWith(t, Options{APIKeys: testKeys(t)}, insecure.NewCredentials())
+	ctx := t.Context()
+	req := &resolverv1.CreateAnchorRequest{Hash: hexHash("doc-1")}
+
+	_, err := client.CreateAnchor(ctx, req)
+	wantCode(t, err, codes.Unauthenticated)
+	_, err = client.CreateAnchor(withMetadata(ctx, apiKeyMetadata, testAPIKey+"x"), req)
+	wantCode(t, err, codes.Unauthenticated)
+	_, err = client.CreateDid(ctx, createDidRequest("did:ewallet:issuer"))
+	wantCode(t, err, codes.Unauthenticated)
+
+	if _, err := client.CreateAnchor(withMetadata(ctx, apiKeyMetadata, testAPIKey), req); err != nil {
+		t.Fatalf("CreateAnchor with a key failed: %v", err)
+	}
+	// Reads stay open
+	if _, err := client.GetAnchor(ctx, &resolverv1.GetAnchorRequest{Hash: req.GetHash()}); err != nil {
+		t.Errorf("expected reads without a key to be served, got %v", err)
+	}
+
+	protected, _ := newTestClientWith(t, Options{APIKeys: testKeys(t), APIKeysProtectReads: true}, insecure.NewCredentials())
+	_, err = protected.Stats(ctx, &resolverv1.StatsRequest{})
+	wantCode(t, err, codes.Unauthenticated)
+	if _, err := protected.Stats(withMetadata(ctx, apiKeyMetadata, testAPIKey), &resolverv1.StatsRequest{}); err != nil {
+		t.Errorf("expected reads with a key to be served, got %v", err)
+	}
+}
+
+func TestAuth_JWTScopes(t *testing.T) {
+	idp := jwttest.NewIssuer()
+	client, _ := newTestClientWith(t, Options{JWT: idp.Verifier()}, insecure.NewCredentials())
+	ctx := t.Context()
+	bearer := func(scopes ...string) context.Context {
+		return withMetadata(ctx, authorizationMetadata, "Bearer "+idp.Token(scopes...))
+	}
+
+	_, err := client.CreateDid(ctx, createDidRequest("did:ewallet:issuer"))
+	wantCode(t, err, codes.Unauthenticated)
+	_, err = client.CreateDid(withMetadata(ctx, authorizationMetadata, "Bearer a.b.c"), createDidRequest("did:ewallet:issuer"))
+	wantCode(t, err, codes.Unauthenticated)
+	_, err = client.CreateDid(bearer("anchors:write"), createDidRequest("did:ewallet:issuer"))
+	wantCode(t, err, codes.PermissionDenied)
+
+	if _, err := client.CreateDid(bearer("dids:write"), createDidRequest("did:ewallet:issuer")); err != nil {
+		t.Errorf("CreateDid with dids:write failed: %v", err)
+	}
+	if _, err := client.CreateAnchor(bearer("admin"), &resolverv1.CreateAnchorRequest{Hash: hexHash("doc-1")}); err != nil {
+		t.Errorf("expected admin to imply anchors:write, got %v", err)
+	}
+	if _, err := client.ResolveDid(ctx, &resolverv1.ResolveDidRequest{Did: "did:ewallet:issuer"}); err != nil {
+		t.Errorf("expected reads without a token to be served, got %v", err)
+	}
+}
+
+func TestRateLimit_KeyedByAPIKey(t *testing.T) {
+	client, _ := newTestClientWith(t, Options{
+		APIKeys:         testKeys(t),
+		RateLimitWrites: ratelimit.New(ratelimit.Limit{Rate: 0.001, Burst: 1}, 0),
+	}, insecure.NewCredentials())
+	ctx := withMetadata(t.Context(), apiKeyMetadata, testAPIKey)
+
+	if _, err := client.CreateAnchor(ctx, &resolverv1.CreateAnchorRequest{Hash: hexHash("doc-1")}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	_, err := client.CreateAnchor(ctx, &resolverv1.CreateAnchorRequest{Hash: hexHash("doc-2")})
+	wantCode(t, err, codes.ResourceExhausted)
+
+	// Reads are a separate, here unlimited, class
+	if _, err := client.GetAnchor(ctx, &resolverv1.GetAnchorRequest{Hash: hexHash("doc-1")}); err != nil {
+		t.Errorf("expected reads to be unaffected, got %v", err)
+	}
+}
+
+func TestAudit_RecordsWrites(t *testing.T) {
+	log, err := audit.Open(filepath.Join(t.TempDir(), "audit.log"), audit.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { log.Close() })
+	client, _ := newTestClientWith(t, Options{APIKeys: testKeys(t), Audit: log}, insecure.NewCredentials())
+	ctx := t.Context()
+	hash := hexHash("doc-1")
+
+	_, err = client.CreateAnchor(ctx, &resolverv1.CreateAnchorRequest{Hash: hash})
+	wantCode(t, err, codes.Unauthenticated)
+	if _, err := client.CreateAnchor(withMetadata(ctx, apiKeyMetadata, testAPIKey, requestIDMetadata, "req-1"), &resolverv1.CreateAnchorRequest{Hash: hash}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	if _, err := client.GetAnchor(ctx, &resolverv1.GetAnchorRequest{Hash: hash}); err != nil {
+		t.Fatalf("GetAnchor failed: %v", err)
+	}
+
+	entries, _, err := log.Read(time.Time{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected an entry per write, got %+v", entries)
+	}
+	if denied := entries[0]; denied.Principal != "anonymous" || denied.Status != 401 || denied.Result != audit.ResultDenied {
+		t.Errorf("unexpected entry of the refused call: %+v", denied)
+	}
+	want := audit.Entry{
+		Time:      entries[1].Time,
+		RequestID: "req-1",
+		Principal: "key:issuer-a",
+		Method:    "POST",
+		Route:     resolverv1.ResolverService_CreateAnchor_FullMethodName,
+		Resource:  hash,
+		Status:    200,
+		Result:    audit.ResultSuccess,
+		SourceIP:  entries[1].SourceIP,
+	}
+	if entries[1] != want {
+		t.Errorf("expected %+v, got %+v", want, entries[1])
+	}
+}
+
+func TestAudit_StrictFailsUnrecordedWrites(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		log, err := audit.Open(filepath.Join(t.TempDir(), "audit.log"), audit.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		log.Close()
+		client, ledger := newTestClientWith(t, Options{Audit: log, AuditStrict: strict}, insecure.NewCredentials())
+
+		_, err = client.CreateAnchor(t.Context(), &resolverv1.CreateAnchorRequest{Hash: hexHash("doc-1")})
+		if strict {
+			wantCode(t, err, codes.Unavailable)
+			// Refused before it reaches the ledger
+			if _, err := ledger.GetAnchor(t.Context(), hexHash("doc-1")); !errors.Is(err, fabric.ErrNotFound) {
+				t.Errorf("expected the unrecorded write not to be made, got %v", err)
+			}
+		} else if err != nil {
+			t.Errorf("expected the write to succeed without strict auditing, got %v", err)
+		}
+	}
+}
+
+func TestAudit_StrictRecordsAttemptFirst(t *testing.T) {
+	log, err := audit.Open(filepath.Join(t.TempDir(), "audit.log"), audit.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { log.Close() })
+	client, _ := newTestClientWith(t, Options{Audit: log, AuditStrict: true}, insecure.NewCredentials())
+
+	if _, err := client.CreateDid(t.Context(), createDidRequest("did:ewallet:issuer")); err != nil {
+		t.Fatalf("CreateDid failed: %v", err)
+	}
+	entries, _, err := log.Read(time.Time{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Result != audit.ResultAttempt || entries[1].Result != audit.ResultSuccess ||
+		entries[0].RequestID != entries[1].RequestID || entries[1].Resource != "did:ewallet:issuer" {
+		t.Errorf("expected an attempt entry, then the result, got %+v", entries)
+	}
+}
+
+func TestRateLimit_FailedAuthByIP(t *testing.T) {
+	client, _ := newTestClientWith(t, Options{
+		APIKeys:         testKeys(t),
+		RateLimitWrites: ratelimit.New(ratelimit.Limit{Rate: 0.001, Burst: 2}, 0),
+	}, insecure.NewCredentials())
+	ctx := t.Context()
+	req := &resolverv1.CreateAnchorRequest{Hash: hexHash("doc-1")}
+
+	for range 2 {
+		_, err := client.CreateAnchor(withMetadata(ctx, apiKeyMetadata, "wrong-key-0123456789"), req)
+		wantCode(t, err, codes.Unauthenticated)
+	}
+	// Refused before the key is checked, even a valid one
+	_, err := client.CreateAnchor(withMetadata(ctx, apiKeyMetadata, testAPIKey), req)
+	wantCode(t, err, codes.ResourceExhausted)
+}
+
+func TestTenants(t *testing.T) {
+	tenants, err := fabric.NewTenantLedgerProvider(fabric.Config{FilePath: filepath.Join(t.TempDir(), "ledger.json")}, []string{"acme", "globex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tenants.Close() })
+	client, shared := newTestClientWith(t, Options{
+		APIKeys:       testKeys(t),
+		Tenants:       tenants,
+		TenantAPIKeys: map[string]string{"issuer-a": "globex"},
+	}, insecure.NewCredentials())
+	ctx := t.Context()
+	hash := hexHash("doc-1")
+
+	_, err = client.GetAnchor(ctx, &resolverv1.GetAnchorRequest{Hash: hash})
+	wantCode(t, err, codes.InvalidArgument)
+	_, err = client.GetAnchor(withMetadata(ctx, tenantMetadata, "initech"), &resolverv1.GetAnchorRequest{Hash: hash})
+	wantCode(t, err, codes.PermissionDenied)
+	// The key of globex cannot name another tenant
+	_, err = client.CreateAnchor(withMetadata(ctx, apiKeyMetadata, testAPIKey, tenantMetadata, "acme"), &resolverv1.CreateAnchorRequest{Hash: hash})
+	wantCode(t, err, codes.PermissionDenied)
+
+	if _, err := client.CreateAnchor(withMetadata(ctx, apiKeyMetadata, testAPIKey), &resolverv1.CreateAnchorRequest{Hash: hash}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	if _, err := client.GetAnchor(withMetadata(ctx, tenantMetadata, "globex"), &resolverv1.GetAnchorRequest{Hash: hash}); err != nil {
+		t.Errorf("expected the anchor on the ledger of globex, got %v", err)
+	}
+	_, err = client.GetAnchor(withMetadata(ctx, tenantMetadata, "acme"), &resolverv1.GetAnchorRequest{Hash: hash})
+	wantCode(t, err, codes.NotFound)
+	if _, err := shared.GetAnchor(ctx, hash); !errors.Is(err, fabric.ErrNotFound) {
+		t.Errorf("expected the shared ledger to be untouched, got %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+// Package grpcapi serves the resolver.v1 gRPC API. It is a thin adapter over
+// fabric.LedgerClient that reuses the request validation of the HTTP handlers,
+// so both transports accept and store the same data.
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"fabric-resolver/internal/api/handlers"
+	"fabric-resolver/internal/apikeys"
+	"fabric-resolver/internal/audit"
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/jwtauth"
+	"fabric-resolver/internal/pkg/didweb"
+	"fabric-resolver/internal/ratelimit"
+	"fabric-resolver/internal/webhooks"
+	resolverv1 "fabric-resolver/proto/resolver/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// Options holds the settings shared with the HTTP router.
+type Options struct {
+	// MaxMetadataBytes caps the canonical size of anchor metadata.
+	// Zero uses handlers.DefaultMaxMetadataBytes.
+	MaxMetadataBytes int
+
+	// DIDMethods is the allow-list of DID methods accepted by CreateDid.
+	// Empty uses domain.DefaultDIDMethods.
+	DIDMethods []string
+
+	// MaxVerificationMethods caps the verification methods of a created DID document.
+	// Zero uses domain.DefaultMaxVerificationMethods.
+	MaxVerificationMethods int
+
+	// DIDWebResolver resolves did:web DIDs that are not on the ledger. Nil disables outbound resolution.
+	DIDWebResolver *didweb.Resolver
+
+	// Webhooks is notified of created DIDs. Nil sends no notifications.
+	Webhooks *webhooks.Dispatcher
+
+	// TLS serves the API over TLS, with client certificates if it requires them, as
+	// the HTTP server does. Nil serves plaintext.
+	TLS *tls.Config
+
+	// APIKeys are required in x-api-key metadata on CreateAnchor and CreateDid, and
+	// on every call when APIKeysProtectReads is set. An empty set leaves calls open.
+	APIKeys             *apikeys.Keys
+	APIKeysProtectReads bool
+
+	// JWT verifies bearer tokens in authorization metadata; writes then need the
+	// anchors:write or dids:write scope. Nil turns JWT authentication off.
+	JWT *jwtauth.Verifier
+
+	// RateLimitReads and RateLimitWrites limit each client, shared with the HTTP
+	// router so both transports draw on one budget. Nil leaves a class unlimited.
+	RateLimitReads  *ratelimit.Limiter
+	RateLimitWrites *ratelimit.Limiter
+
+	// Audit records every write call; AuditStrict fails calls whose entry cannot be
+	// appended with Unavailable. Nil records nothing.
+	Audit       *audit.Log
+	AuditStrict bool
+
+	// Tenants serves each call from the ledger of its tenant, named in x-tenant-id
+	// metadata or by TenantAPIKeys, which maps API key ids to the tenant their calls
+	// are held to. Nil serves the ledger of the server to every caller.
+	Tenants       *fabric.TenantLedgerProvider
+	TenantAPIKeys map[string]string
+
+	// Logger reports failed audit appends. Nil uses slog.Default.
+	Logger *slog.Logger
+}
+
+// Server implements resolverv1.ResolverServiceServer.
+type Server struct {
+	resolverv1.UnimplementedResolverServiceServer
+
+	ledgerClient     fabric.LedgerClient
+	maxMetadataBytes int
+	validator        *domain.DIDValidator
+	webResolver      *didweb.Resolver
+	webhooks         *webhooks.Dispatcher
+}
+
+func NewServer(ledgerClient fabric.LedgerClient, opts Options) *Server {
+	if opts.MaxMetadataBytes <= 0 {
+		opts.MaxMetadataBytes = handlers.DefaultMaxMetadataBytes
+	}
+	return &Server{
+		ledgerClient:     ledgerClient,
+		maxMetadataBytes: opts.MaxMetadataBytes,
+		validator:        domain.NewDIDValidator(opts.DIDMethods).WithMaxVerificationMethods(opts.MaxVerificationMethods),
+		webResolver:      opts.DIDWebResolver,
+		webhooks:         opts.Webhooks,
+	}
+}
+
+// NewGRPCServer returns a grpc.Server with the resolver service registered behind
+// the authentication, rate limiting and audit of opts.
+func NewGRPCServer(ledgerClient fabric.LedgerClient, opts Options) *grpc.Server {
+	serverOpts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(interceptors(opts)...)}
+	if opts.TLS != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(opts.TLS)))
+	}
+	s := grpc.NewServer(serverOpts...)
+	resolverv1.RegisterResolverServiceServer(s, NewServer(ledgerClient, opts))
+	return s
+}
+
+// ledger returns the ledger of the call's tenant, or the server's without tenants.
+func (s *Server) ledger(ctx context.Context) fabric.LedgerClient {
+	return handlers.LedgerFrom(ctx, s.ledgerClient)
+}
+
+func (s *Server) CreateAnchor(ctx context.Context, req *resolverv1.CreateAnchorRequest) (*resolverv1.Anchor, error) {
+	anchorReq := handlers.CreateAnchorRequest{
+		Hash:      req.GetHash(),
+		Algorithm: req.GetAlgorithm(),
+		IssuerDID: req.GetIssuerDid(),
+	}
+	if req.GetMetadataJson() != "" {
+		anchorReq.Metadata = json.RawMessage(req.GetMetadataJson())
+	}
+	if req.GetExpiresAt() != nil {
+		anchorReq.ExpiresAt = req.GetExpiresAt().AsTime().Format(time.RFC3339)
+	}
+
+	anchor, err := anchorReq.ToAnchor(s.maxMetadataBytes)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	handlers.SetAuditResource(ctx, anchor.Hash)
+
+	txID, blockNumber, err := s.ledger(ctx).CreateAnchor(ctx, anchor)
+	if err != nil {
+		return nil, statusFromLedger(err, "failed to create anchor")
+	}
+
+	// Read back so an existing anchor is reported as stored
+	stored, err := s.ledger(ctx).GetAnchor(ctx, anchor.Hash)
+	if err != nil {
+		anchor.TxID, anchor.BlockNumber = txID, blockNumber
+		return toProtoAnchor(anchor), nil
+	}
+	return toProtoAnchor(stored), nil
+}
+
+func (s *Server) GetAnchor(ctx context.Context, req *resolverv1.GetAnchorRequest) (*resolverv1.Anchor, error) {
+	hash := domain.NormalizeHash(req.GetHash())
+	if hash == "" {
+		return nil, status.Error(codes.InvalidArgument, "hash is required")
+	}
+
+	anchor, err := s.ledger(ctx).GetAnchor(ctx, hash)
+	if err != nil {
+		return nil, statusFromLedger(err, "failed to get anchor")
+	}
+	return toProtoAnchor(anchor), nil
+}
+
+func (s *Server) VerifyAnchor(ctx context.Context, req *resolverv1.VerifyAnchorRequest) (*resolverv1.VerifyAnchorResponse, error) {
+	hash := domain.NormalizeHash(req.GetHash())
+	if hash == "" {
+		return nil, status.Error(codes.InvalidArgument, "hash is required")
+	}
+
+	resp := &resolverv1.VerifyAnchorResponse{Hash: hash}
+	if !s.ledger(ctx).VerifyAnchor(ctx, hash) {
+		return resp, nil
+	}
+	anchor, err := s.ledger(ctx).GetAnchor(ctx, hash)
+	if err != nil {
+		// Expired or removed between the two reads
+		return resp, nil
+	}
+
+	resp.Exists = true
+	resp.Revoked = anchor.Revoked
+	resp.Valid = !anchor.Revoked
+	resp.SignatureVerified = anchor.SignatureVerified
+	resp.Timestamp = timestamp(&anchor.Timestamp)
+	return resp, nil
+}
+
+func (s *Server) CreateDid(ctx context.Context, req *resolverv1.CreateDidRequest) (*resolverv1.DidDocument, error) {
+	if req.GetDid() == "" {
+		return nil, status.Error(codes.InvalidArgument, "did is required")
+	}
+
+	handlers.SetAuditResource(ctx, domain.NormalizeDID(req.GetDid()))
+	didDoc, err := handlers.NewDIDDocument(s.validator, fromProtoCreateDid(req))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.ledger(ctx).CreateDid(ctx, didDoc); err != nil {
+		return nil, statusFromLedger(err, "failed to create DID")
+	}
+	s.webhooks.DIDCreated(ctx, handlers.Tenant(ctx), didDoc)
+
+	stored, err := s.ledger(ctx).GetDid(ctx, didDoc.ID)
+	if err != nil {
+		return toProtoDidDocument(didDoc), nil
+	}
+	return toProtoDidDocument(stored), nil
+}
+
+func (s *Server) ResolveDid(ctx context.Context, req *resolverv1.ResolveDidRequest) (*resolverv1.DidDocument, error) {
+	if req.GetDid() == "" {
+		return nil, status.Error(codes.InvalidArgument, "did is required")
+	}
+
+	// did:key documents are derived from the key, never read from the ledger
+	didDoc, source, err := handlers.LookupDid(ctx, s.ledger(ctx), s.webResolver, domain.NormalizeDID(req.GetDid()))
+	if err != nil {
+		return nil, statusFromLookup(source, err)
+	}
+	return toProtoDidDocument(didDoc), nil
+}
+
+func (s *Server) Stats(ctx context.Context, _ *resolverv1.StatsRequest) (*resolverv1.StatsResponse, error) {
+	stats := s.ledger(ctx).GetStats()
+
+	resp := &resolverv1.StatsResponse{
+		Anchors:       int64(stats.Anchors),
+		Dids:          int64(stats.DIDs),
+		NextBlock:     stats.NextBlock,
+		Mode:          stats.Mode,
+		Path:          stats.Path,
+		FileSizeBytes: stats.FileSizeBytes,
+		LastWriteTime: timestamp(stats.LastWriteTime),
+		Pruned:        stats.Pruned,
+	}
+	if len(stats.DocTypes) > 0 {
+		resp.DocTypes = make(map[string]int64, len(stats.DocTypes))
+		for docType, n := range stats.DocTypes {
+			resp.DocTypes[docType] = int64(n)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,356 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/infrastructure/fabric"
+	resolverv1 "fabric-resolver/proto/resolver/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func init() {
+	log.SetOutput(io.Discard)
+}
+
+func hexHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// newTestClient serves the resolver over an in-memory listener backed by a file ledger.
+func newTestClient(t *testing.T) (resolverv1.ResolverServiceClient, fabric.LedgerClient) {
+	t.Helper()
+	return newTestClientWith(t, Options{MaxMetadataBytes: 64}, insecure.NewCredentials())
+}
+
+// newTestClientWith serves the resolver with opts, dialled with creds.
+func newTestClientWith(t *testing.T, opts Options, creds credentials.TransportCredentials) (resolverv1.ResolverServiceClient, fabric.LedgerClient) {
+	t.Helper()
+	ledger, err := fabric.NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	if err != nil {
+		t.Fatalf("failed to create ledger: %v", err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	server := NewGRPCServer(ledger, opts)
+	go server.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(creds),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+		ledger.Close()
+	})
+	return resolverv1.NewResolverServiceClient(conn), ledger
+}
+
+func wantCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Errorf("expected %v, got %v (%v)", want, got, err)
+	}
+}
+
+func TestCreateAnchor(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := t.Context()
+	hash := hexHash("doc-1")
+
+	anchor, err := client.CreateAnchor(ctx, &resolverv1.CreateAnchorRequest{
+		Hash:         hash,
+		IssuerDid:    "did:ewallet:issuer",
+		MetadataJson: `{ "b": 1, "a": 2 }`,
+		ExpiresAt:    timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	if err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	if anchor.GetHash() != hash || anchor.GetTxId() == "" || anchor.GetTimestamp() == nil || anchor.GetExpiresAt() == nil {
+		t.Errorf("unexpected anchor: %v", anchor)
+	}
+	if anchor.GetMetadataJson() != `{"a":2,"b":1}` || anchor.GetMetadataHash() == "" {
+		t.Errorf("metadata was not canonicalized: %q %q", anchor.GetMetadataJson(), anchor.GetMetadataHash())
+	}
+
+	again, err := client.CreateAnchor(ctx, &resolverv1.CreateAnchorRequest{Hash: hash})
+	if err != nil {
+		t.Fatalf("repeated CreateAnchor failed: %v", err)
+	}
+	if again.GetTxId() != anchor.GetTxId() || again.GetIssuerDid() != "did:ewallet:issuer" {
+		t.Errorf("expected the stored anchor back, got %v", again)
+	}
+
+	tests := []struct {
+		name string
+		req  *resolverv1.CreateAnchorRequest
+	}{
+		{"missing hash", &resolverv1.CreateAnchorRequest{}},
+		{"not hex", &resolverv1.CreateAnchorRequest{Hash: "not-a-hash"}},
+		{"unknown algorithm", &resolverv1.CreateAnchorRequest{Hash: hash, Algorithm: "md5"}},
+		{"invalid metadata", &resolverv1.CreateAnchorRequest{Hash: hexHash("doc-2"), MetadataJson: "{"}},
+		{"metadata too large", &resolverv1.CreateAnchorRequest{Hash: hexHash("doc-2"), MetadataJson: `"` + string(make([]byte, 100)) + `"`}},
+		{"expired", &resolverv1.CreateAnchorRequest{Hash: hexHash("doc-2"), ExpiresAt: timestamppb.New(time.Now().Add(-time.Hour))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.CreateAnchor(ctx, tt.req)
+			wantCode(t, err, codes.InvalidArgument)
+		})
+	}
+}
+
+func TestGetAnchor(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := t.Context()
+	hash := hexHash("doc-1")
+
+	_, err := client.GetAnchor(ctx, &resolverv1.GetAnchorRequest{Hash: hash})
+	wantCode(t, err, codes.NotFound)
+	_, err = client.GetAnchor(ctx, &resolverv1.GetAnchorRequest{})
+	wantCode(t, err, codes.InvalidArgument)
+
+	created, err := client.CreateAnchor(ctx, &resolverv1.CreateAnchorRequest{Hash: hash})
+	if err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	got, err := client.GetAnchor(ctx, &resolverv1.GetAnchorRequest{Hash: strings.ToUpper(hash)})
+	if err != nil {
+		t.Fatalf("GetAnchor failed: %v", err)
+	}
+	if got.GetTxId() != created.GetTxId() || got.GetBlockNumber() != created.GetBlockNumber() {
+		t.Errorf("expected %v, got %v", created, got)
+	}
+}
+
+func TestVerifyAnchor(t *testing.T) {
+	client, ledger := newTestClient(t)
+	ctx := t.Context()
+	hash := hexHash("doc-1")
+
+	resp, err := client.VerifyAnchor(ctx, &resolverv1.VerifyAnchorRequest{Hash: hash})
+	if err != nil {
+		t.Fatalf("VerifyAnchor failed: %v", err)
+	}
+	if resp.GetExists() || resp.GetValid() {
+		t.Errorf("unknown hash should not verify: %v", resp)
+	}
+
+	if _, err := client.CreateAnchor(ctx, &resolverv1.CreateAnchorRequest{Hash: hash}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	if resp, _ = client.VerifyAnchor(ctx, &resolverv1.VerifyAnchorRequest{Hash: hash}); !resp.GetExists() || !resp.GetValid() || resp.GetTimestamp() == nil {
+		t.Errorf("expected a valid anchor: %v", resp)
+	}
+
+	if err := ledger.RevokeAnchor(ctx, hash, "superseded"); err != nil {
+		t.Fatalf("RevokeAnchor failed: %v", err)
+	}
+	if resp, _ = client.VerifyAnchor(ctx, &resolverv1.VerifyAnchorRequest{Hash: hash}); !resp.GetExists() || resp.GetValid() || !resp.GetRevoked() {
+		t.Errorf("expected a revoked anchor: %v", resp)
+	}
+
+	_, err = client.VerifyAnchor(ctx, &resolverv1.VerifyAnchorRequest{})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func createDidRequest(did string) *resolverv1.CreateDidRequest {
+	return &resolverv1.CreateDidRequest{
+		Did: did,
+		VerificationMethod: []*resolverv1.VerificationMethodInput{{
+			Type:         "JsonWebKey2020",
+			PublicKeyJwk: &resolverv1.Jwk{Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+		}},
+		Service: []*resolverv1.ServiceInput{{Type: "LinkedDomains", ServiceEndpoint: "https://issuer.example"}},
+	}
+}
+
+func TestCreateDid(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := t.Context()
+
+	doc, err := client.CreateDid(ctx, createDidRequest("did:ewallet:issuer"))
+	if err != nil {
+		t.Fatalf("CreateDid failed: %v", err)
+	}
+	if doc.GetId() != "did:ewallet:issuer" || len(doc.GetVerificationMethod()) != 1 ||
+		doc.GetVerificationMethod()[0].GetId() != "did:ewallet:issuer#key-1" ||
+		doc.GetService()[0].GetId() != "did:ewallet:issuer#service-1" || doc.GetCreated() == nil {
+		t.Errorf("unexpected document: %v", doc)
+	}
+
+	_, err = client.CreateDid(ctx, createDidRequest("did:ewallet:issuer"))
+	wantCode(t, err, codes.AlreadyExists)
+
+	_, err = client.CreateDid(ctx, createDidRequest("did:unknown:issuer"))
+	wantCode(t, err, codes.InvalidArgument)
+
+	bad := createDidRequest("did:ewallet:other")
+	bad.Authentication = []int32{3}
+	_, err = client.CreateDid(ctx, bad)
+	wantCode(t, err, codes.InvalidArgument)
+
+	// DIDs are normalized as over HTTP
+	if doc, err := client.CreateDid(ctx, createDidRequest("DID:ewallet:upper")); err != nil || doc.GetId() != "did:ewallet:upper" {
+		t.Errorf("expected a normalized DID, got %v, %v", doc, err)
+	}
+}
+
+func TestCreateDid_RejectsDidKey(t *testing.T) {
+	client, ledger := newTestClient(t)
+	const did = "did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"
+
+	// Storing a document under someone's did:key would let its keys be chosen by the caller
+	_, err := client.CreateDid(t.Context(), createDidRequest(did))
+	wantCode(t, err, codes.InvalidArgument)
+	if _, err := ledger.GetDid(t.Context(), did); err == nil {
+		t.Fatal("did:key document was stored")
+	}
+
+	// Resolving derives the document from the key instead
+	doc, err := client.ResolveDid(t.Context(), &resolverv1.ResolveDidRequest{Did: did})
+	if err != nil {
+		t.Fatalf("ResolveDid failed: %v", err)
+	}
+	if doc.GetId() != did || len(doc.GetVerificationMethod()) != 1 || doc.GetVerificationMethod()[0].GetController() != did {
+		t.Errorf("unexpected derived document: %v", doc)
+	}
+	_, err = client.ResolveDid(t.Context(), &resolverv1.ResolveDidRequest{Did: "did:key:zNotAKey"})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestResolveDid(t *testing.T) {
+	client, ledger := newTestClient(t)
+	ctx := t.Context()
+
+	_, err := client.ResolveDid(ctx, &resolverv1.ResolveDidRequest{Did: "did:ewallet:issuer"})
+	wantCode(t, err, codes.NotFound)
+	_, err = client.ResolveDid(ctx, &resolverv1.ResolveDidRequest{})
+	wantCode(t, err, codes.InvalidArgument)
+
+	if _, err := client.CreateDid(ctx, createDidRequest("did:ewallet:issuer")); err != nil {
+		t.Fatalf("CreateDid failed: %v", err)
+	}
+	if err := ledger.DeactivateDid(ctx, "did:ewallet:issuer", 1); err != nil {
+		t.Fatalf("DeactivateDid failed: %v", err)
+	}
+
+	doc, err := client.ResolveDid(ctx, &resolverv1.ResolveDidRequest{Did: "did:ewallet:issuer"})
+	if err != nil {
+		t.Fatalf("ResolveDid failed: %v", err)
+	}
+	if !doc.GetDeactivated() || doc.GetDeactivatedAt() == nil || doc.GetVerificationMethod()[0].GetPublicKeyJwk().GetCrv() != "Ed25519" {
+		t.Errorf("unexpected document: %v", doc)
+	}
+	if len(doc.GetAuthentication()) != 1 || doc.GetVersionId() < 2 {
+		t.Errorf("unexpected relationships or version: %v", doc)
+	}
+}
+
+func TestStats(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := t.Context()
+
+	if _, err := client.CreateAnchor(ctx, &resolverv1.CreateAnchorRequest{Hash: hexHash("doc-1")}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	if _, err := client.CreateDid(ctx, createDidRequest("did:ewallet:issuer")); err != nil {
+		t.Fatalf("CreateDid failed: %v", err)
+	}
+
+	stats, err := client.Stats(ctx, &resolverv1.StatsRequest{})
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.GetAnchors() != 1 || stats.GetDids() != 1 || stats.GetMode() != "file-persistent" || stats.GetDocTypes()["anchor"] != 1 {
+		t.Errorf("unexpected stats: %v", stats)
+	}
+}
+
+func TestStatusFromLedger(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{fabric.ErrNotFound, codes.NotFound},
+		{fabric.ErrExpired, codes.NotFound},
+		{fabric.ErrAlreadyExists, codes.AlreadyExists},
+		{fabric.ErrValidation, codes.InvalidArgument},
+		{fabric.ErrDeactivated, codes.FailedPrecondition},
+		{fabric.ErrClientClosed, codes.Unavailable},
+		{fabric.ErrReadOnly, codes.Unavailable},
+		{io.ErrUnexpectedEOF, codes.Internal},
+	}
+	for _, tt := range tests {
+		wantCode(t, statusFromLedger(tt.err, "failed"), tt.want)
+	}
+}
+
+func TestTLS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "resolver"},
+		DNSNames:     []string{"resolver"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverTLS := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	client, _ := newTestClientWith(t, Options{TLS: serverTLS}, credentials.NewTLS(&tls.Config{RootCAs: roots, ServerName: "resolver"}))
+	if _, err := client.Stats(t.Context(), &resolverv1.StatsRequest{}); err != nil {
+		t.Fatalf("expected the TLS handshake to succeed, got %v", err)
+	}
+
+	plain, _ := newTestClientWith(t, Options{TLS: serverTLS}, insecure.NewCredentials())
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+	_, err = plain.Stats(ctx, &resolverv1.StatsRequest{})
+	wantCode(t, err, codes.Unavailable)
+}
@@ -0,0 +1,54 @@
+// Package health probes what the resolver needs to serve requests. The same checks
+// answer the readiness endpoint and the --check mode of the binary, so a deployment
+// that passes one passes the other.
+package health
+
+import (
+	"context"
+	"time"
+
+	"fabric-resolver/internal/infrastructure/fabric"
+)
+
+// Check probes one dependency, named in results and error details.
+type Check struct {
+	Name  string
+	Probe func(ctx context.Context) error
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+// Ledger checks that client can serve reads and writes: a write-read-delete probe
+// of the ledger directory in file mode, a query through the gateway in fabric mode.
+func Ledger(client fabric.LedgerClient) Check {
+	return Check{Name: "ledger", Probe: client.Ping}
+}
+
+// Run runs checks in order, each bounded by timeout, and returns their results.
+func Run(ctx context.Context, timeout time.Duration, checks ...Check) []Result {
+	results := make([]Result, len(checks))
+	for i, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		start := time.Now()
+		err := check.Probe(checkCtx)
+		cancel()
+		results[i] = Result{Name: check.Name, Err: err, Duration: time.Since(start)}
+	}
+	return results
+}
+
+// Failed returns the results of the checks that failed.
+func Failed(results []Result) []Result {
+	var failed []Result
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,30 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRun_BoundsEachCheckAndReportsFailures(t *testing.T) {
+	hang := Check{Name: "hang", Probe: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	ok := Check{Name: "ok", Probe: func(context.Context) error { return nil }}
+	broken := Check{Name: "broken", Probe: func(context.Context) error { return errors.New("unreachable") }}
+
+	results := Run(t.Context(), 20*time.Millisecond, hang, ok, broken)
+	if len(results) != 3 || results[0].Name != "hang" || results[1].Name != "ok" || results[2].Name != "broken" {
+		t.Fatalf("expected a result per check in order, got %+v", results)
+	}
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) || results[0].Duration < 20*time.Millisecond {
+		t.Errorf("expected the hanging check to be cut off at the timeout, got %+v", results[0])
+	}
+
+	failed := Failed(results)
+	if len(failed) != 2 || failed[0].Name != "hang" || failed[1].Name != "broken" {
+		t.Errorf("expected hang and broken to fail, got %+v", failed)
+	}
+}
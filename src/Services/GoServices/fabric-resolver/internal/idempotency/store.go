@@ -0,0 +1,172 @@
+// Package idempotency remembers the responses to requests sent with an
+// Idempotency-Key header, so a client retrying after a timeout gets the original
+// response instead of a second write.
+package idempotency
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MaxKeyLength bounds the Idempotency-Key header.
+const MaxKeyLength = 255
+
+var (
+	// ErrConflict means the key was used before with a different request body.
+	ErrConflict = errors.New("idempotency key reused with a different request")
+	// ErrInProgress means a request with the key has not completed yet.
+	ErrInProgress = errors.New("idempotency key in use by a request in progress")
+)
+
+// Record is a stored response.
+type Record struct {
+	RequestHash string          `json:"requestHash"`
+	Status      int             `json:"status"`
+	ContentType string          `json:"contentType,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	Created     time.Time       `json:"created"`
+}
+
+// storeFile is the on-disk format of the store.
+type storeFile struct {
+	Records map[string]Record `json:"records"`
+}
+
+// Store keeps records for the retention window. Records are persisted to a JSON
+// file written atomically after every change; an empty path keeps them in memory.
+type Store struct {
+	path      string
+	retention time.Duration
+	now       func() time.Time // replaced in tests
+
+	mu       sync.Mutex
+	records  map[string]Record
+	inFlight map[string]string // key -> request hash
+}
+
+// NewStore loads the store at path, dropping records older than retention.
+func NewStore(path string, retention time.Duration) (*Store, error) {
+	s := &Store{
+		path:      path,
+		retention: retention,
+		now:       time.Now,
+		records:   make(map[string]Record),
+		inFlight:  make(map[string]string),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create idempotency store directory: %w", err)
+		}
+		return s, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read idempotency store: %w", err)
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse idempotency store: %w", err)
+	}
+	for key, rec := range file.Records {
+		s.records[key] = rec
+	}
+	s.pruneLocked()
+	return s, nil
+}
+
+// Begin claims key for a request whose body hashes to requestHash.
+// It returns the stored record if the request was already answered, ErrConflict
+// if the key was used with another body, or ErrInProgress if the first request is
+// still running. On (nil, nil) the caller must call Complete or Release.
+func (s *Store) Begin(key, requestHash string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rec, ok := s.records[key]; ok && !s.expired(rec) {
+		if rec.RequestHash != requestHash {
+			return nil, ErrConflict
+		}
+		return &rec, nil
+	}
+	if hash, ok := s.inFlight[key]; ok {
+		if hash != requestHash {
+			return nil, ErrConflict
+		}
+		return nil, ErrInProgress
+	}
+	s.inFlight[key] = requestHash
+	return nil, nil
+}
+
+// Complete stores the response to the request that claimed key.
+func (s *Store) Complete(key string, status int, contentType string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := s.inFlight[key]
+	delete(s.inFlight, key)
+	s.records[key] = Record{
+		RequestHash: hash,
+		Status:      status,
+		ContentType: contentType,
+		Body:        append(json.RawMessage(nil), body...),
+		Created:     s.now().UTC(),
+	}
+	s.pruneLocked()
+	return s.persistLocked()
+}
+
+// Release gives up a claim without storing a response, so the key can be retried.
+func (s *Store) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inFlight, key)
+}
+
+// Len returns the number of unexpired records.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked()
+	return len(s.records)
+}
+
+func (s *Store) expired(rec Record) bool {
+	return s.now().Sub(rec.Created) > s.retention
+}
+
+func (s *Store) pruneLocked() {
+	for key, rec := range s.records {
+		if s.expired(rec) {
+			delete(s.records, key)
+		}
+	}
+}
+
+func (s *Store) persistLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(storeFile{Records: s.records})
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency store: %w", err)
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write idempotency store: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to rename idempotency store: %w", err)
+	}
+	return nil
+}
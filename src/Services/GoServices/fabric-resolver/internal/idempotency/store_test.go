@@ -0,0 +1,80 @@
+package idempotency
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore_ReplaysAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency.json")
+	s, err := NewStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	if rec, err := s.Begin("k1", "hash-a"); rec != nil || err != nil {
+		t.Fatalf("expected a fresh claim, got %v, %v", rec, err)
+	}
+	if _, err := s.Begin("k1", "hash-a"); !errors.Is(err, ErrInProgress) {
+		t.Errorf("expected ErrInProgress, got %v", err)
+	}
+	if err := s.Complete("k1", 201, "application/json", []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	reopened, err := NewStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	rec, err := reopened.Begin("k1", "hash-a")
+	if err != nil || rec == nil || rec.Status != 201 || string(rec.Body) != `{"ok":true}` {
+		t.Fatalf("expected the stored response, got %+v, %v", rec, err)
+	}
+	if _, err := reopened.Begin("k1", "hash-b"); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestStore_Release(t *testing.T) {
+	s, _ := NewStore("", time.Hour)
+	s.Begin("k1", "hash-a")
+	s.Release("k1")
+
+	if rec, err := s.Begin("k1", "hash-b"); rec != nil || err != nil {
+		t.Errorf("expected a released key to be claimable, got %v, %v", rec, err)
+	}
+}
+
+func TestStore_Expiry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idempotency.json")
+	s, _ := NewStore(path, time.Hour)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	s.Begin("k1", "hash-a")
+	s.Complete("k1", 201, "", nil)
+
+	now = now.Add(59 * time.Minute)
+	if rec, _ := s.Begin("k1", "hash-a"); rec == nil {
+		t.Fatal("expected the record within the retention window")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if rec, err := s.Begin("k1", "hash-b"); rec != nil || err != nil {
+		t.Errorf("expected an expired key to be claimable, got %v, %v", rec, err)
+	}
+	if n := s.Len(); n != 0 {
+		t.Errorf("expected expired records to be pruned, got %d", n)
+	}
+
+	// Records expired while the server was down are dropped on load
+	reopened, err := NewStore(path, time.Nanosecond)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if n := reopened.Len(); n != 0 {
+		t.Errorf("expected no records after reload, got %d", n)
+	}
+}
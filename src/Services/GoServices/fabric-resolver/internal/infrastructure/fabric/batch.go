@@ -0,0 +1,82 @@
+package fabric
+
+import (
+	"fmt"
+	"time"
+
+	"fabric-resolver/internal/domain"
+)
+
+// MaxAnchorBatch is the largest number of anchors accepted by CreateAnchors.
+const MaxAnchorBatch = 1000
+
+// AnchorStatus is the outcome of one anchor in a CreateAnchors batch.
+type AnchorStatus string
+
+const (
+	AnchorCreated AnchorStatus = "created"
+	AnchorExisted AnchorStatus = "already-existed"
+	AnchorFailed  AnchorStatus = "error"
+)
+
+// AnchorResult reports what happened to one anchor in a CreateAnchors batch.
+// TxID and BlockNumber are set for created and already existing anchors; Err is set on failure.
+type AnchorResult struct {
+	Hash        string
+	Status      AnchorStatus
+	TxID        string
+	BlockNumber uint64
+	Err         error
+}
+
+// AnchorVerification is the state of one hash looked up by VerifyAnchors.
+// BlockNumber and Timestamp are zero when the anchor does not exist.
+type AnchorVerification struct {
+	Exists      bool
+	Revoked     bool
+	BlockNumber uint64
+	Timestamp   time.Time
+}
+
+// verificationOf reports a live anchor as existing; expired anchors are treated as absent.
+func verificationOf(anchor *domain.Anchor, now time.Time) AnchorVerification {
+	if anchor == nil || anchor.IsExpired(now) {
+		return AnchorVerification{}
+	}
+	return AnchorVerification{
+		Exists:      true,
+		Revoked:     anchor.Revoked,
+		BlockNumber: anchor.BlockNumber,
+		Timestamp:   anchor.Timestamp,
+	}
+}
+
+// validateAnchorBatch checks the batch as a whole and returns a result slot per anchor.
+// Anchors that fail on their own (missing hash, repeated earlier in the batch) are marked
+// AnchorFailed; the others are left with an empty status for the backend to fill in.
+func validateAnchorBatch(anchors []*domain.Anchor) ([]AnchorResult, error) {
+	if len(anchors) == 0 {
+		return nil, fmt.Errorf("batch is empty: %w", ErrValidation)
+	}
+	if len(anchors) > MaxAnchorBatch {
+		return nil, fmt.Errorf("batch of %d anchors exceeds the maximum of %d: %w", len(anchors), MaxAnchorBatch, ErrValidation)
+	}
+
+	results := make([]AnchorResult, len(anchors))
+	first := make(map[string]int, len(anchors))
+	for i, anchor := range anchors {
+		results[i].Hash = anchor.Hash
+		if anchor.Hash == "" {
+			results[i].Status = AnchorFailed
+			results[i].Err = fmt.Errorf("hash is required: %w", ErrValidation)
+			continue
+		}
+		if j, dup := first[anchor.Hash]; dup {
+			results[i].Status = AnchorFailed
+			results[i].Err = fmt.Errorf("duplicate of batch item %d: %w", j, ErrValidation)
+			continue
+		}
+		first[anchor.Hash] = i
+	}
+	return results, nil
+}
@@ -2,8 +2,15 @@ package fabric
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -12,6 +19,9 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// discardLogger keeps benchmarks and noisy tests from flooding the output.
+var discardLogger = slog.New(slog.DiscardHandler)
+
 func TestNewFileLedgerClient(t *testing.T) {
 	tmpDir := t.TempDir()
 	ledgerPath := filepath.Join(tmpDir, "ledger.json")
@@ -105,8 +115,8 @@ func TestConcurrency(t *testing.T) {
 	}
 
 	stats := client2.GetStats()
-	if stats["anchors"].(int) != count {
-		t.Errorf("Stats mismatch: expected %d anchors, got %d", count, stats["anchors"])
+	if stats.Anchors != count {
+		t.Errorf("Stats mismatch: expected %d anchors, got %d", count, stats.Anchors)
 	}
 }
 
@@ -116,7 +126,7 @@ func TestIdempotency(t *testing.T) {
 	client, _ := NewFileLedgerClient(ledgerPath)
 	ctx := context.Background()
 
-	anchor := &domain.Anchor{Hash: "idempotent-hash", Metadata: "original"}
+	anchor := &domain.Anchor{Hash: "idempotent-hash", Metadata: json.RawMessage(`"original"`)}
 
 	// 1. First Write
 	txID1, _, err := client.CreateAnchor(ctx, anchor)
@@ -130,7 +140,7 @@ func TestIdempotency(t *testing.T) {
 	time.Sleep(10 * time.Millisecond)
 
 	// 3. Duplicate Write using same Hash but different Metadata (attempted mutation)
-	anchorDup := &domain.Anchor{Hash: "idempotent-hash", Metadata: "mutated"}
+	anchorDup := &domain.Anchor{Hash: "idempotent-hash", Metadata: json.RawMessage(`"mutated"`)}
 	txID2, _, err := client.CreateAnchor(ctx, anchorDup)
 	if err != nil {
 		t.Fatalf("Duplicate write failed: %v", err)
@@ -143,7 +153,7 @@ func TestIdempotency(t *testing.T) {
 
 	// 5. Verify Record NOT mutated
 	retrieved, _ := client.GetAnchor(ctx, "idempotent-hash")
-	if retrieved.Metadata != "original" {
+	if string(retrieved.Metadata) != `"original"` {
 		t.Error("Idempotency violation: Metadata was mutated")
 	}
 	if !retrieved.Timestamp.Equal(timestamp1) {
@@ -160,3 +170,1337 @@ func TestGetAnchorNotFound(t *testing.T) {
 		t.Error("Expected error for missing anchor")
 	}
 }
+
+func TestSubscribeAnchors(t *testing.T) {
+	tmpDir := t.TempDir()
+	client, _ := NewFileLedgerClient(filepath.Join(tmpDir, "ledger.json"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub, err := client.SubscribeAnchors(ctx)
+	if err != nil {
+		t.Fatalf("SubscribeAnchors failed: %v", err)
+	}
+
+	for _, hash := range []string{"sub-1", "sub-2"} {
+		if _, _, err := client.CreateAnchor(context.Background(), &domain.Anchor{Hash: hash}); err != nil {
+			t.Fatalf("CreateAnchor failed: %v", err)
+		}
+	}
+	// Idempotent re-create must not emit a second event
+	client.CreateAnchor(context.Background(), &domain.Anchor{Hash: "sub-1"})
+
+	for i, want := range []string{"sub-1", "sub-2"} {
+		select {
+		case got := <-sub:
+			if got.Hash != want || got.BlockNumber != uint64(i+1) || got.TxID == "" {
+				t.Errorf("event %d: unexpected anchor %+v", i, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event %d", i)
+		}
+	}
+
+	cancel()
+	select {
+	case a, ok := <-sub:
+		if ok {
+			t.Errorf("expected channel closed after cancel, got %+v", a)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+}
+
+func TestNewLedgerClient_FabricModeFailsFast(t *testing.T) {
+	_, err := NewLedgerClient(Config{Mode: "fabric", PeerEndpoint: "peer0:7051", ChannelID: "mychannel"})
+	if err == nil {
+		t.Fatal("expected error for incomplete Fabric config")
+	}
+
+	want := "TLSCACertPath (FABRIC_TLS_CA_CERT_PATH), MSPID (FABRIC_MSP_ID), CertPath (FABRIC_CERT_PATH), KeyPath (FABRIC_KEY_PATH), ChaincodeName (FABRIC_CHAINCODE_NAME)"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("expected missing fields %q in error, got %q", want, err.Error())
+	}
+}
+
+func TestPersistenceFailureRollsBack(t *testing.T) {
+	tmpDir := t.TempDir()
+	ledgerPath := filepath.Join(tmpDir, "ledger.json")
+	client, _ := NewFileLedgerClient(ledgerPath)
+	defer client.Close()
+	ctx := context.Background()
+
+	// A directory in place of the tmp file makes the atomic save fail
+	if err := os.Mkdir(ledgerPath+".tmp", 0755); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	_, _, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: "unpersisted"})
+	if !errors.Is(err, ErrTransient) {
+		t.Fatalf("expected transient persistence error, got %v", err)
+	}
+
+	if client.VerifyAnchor(ctx, "unpersisted") {
+		t.Error("unpersisted anchor must not be visible to readers")
+	}
+	if _, err := client.GetAnchor(ctx, "unpersisted"); err == nil {
+		t.Error("GetAnchor returned an unpersisted anchor")
+	}
+
+	// Once the disk recovers the same write succeeds and gets the first block
+	os.Remove(ledgerPath + ".tmp")
+	_, block, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: "unpersisted"})
+	if err != nil {
+		t.Fatalf("retry after recovery failed: %v", err)
+	}
+	if block != 1 {
+		t.Errorf("expected failed write not to consume a block, got block %d", block)
+	}
+}
+
+func BenchmarkCreateAnchor_Sequential(b *testing.B) {
+	client, _ := NewFileLedgerClientWithLogger(filepath.Join(b.TempDir(), "ledger.json"), discardLogger)
+	defer client.Close()
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		client.CreateAnchor(ctx, &domain.Anchor{Hash: fmt.Sprintf("seq-%d", i)})
+	}
+}
+
+// BenchmarkCreateAnchor_32Writers shows the effect of group commit: 32 concurrent
+// writers share file writes instead of serializing one fsync each.
+func BenchmarkCreateAnchor_32Writers(b *testing.B) {
+	const writers = 32
+
+	client, _ := NewFileLedgerClientWithLogger(filepath.Join(b.TempDir(), "ledger.json"), discardLogger)
+	defer client.Close()
+	ctx := context.Background()
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+
+	b.ResetTimer()
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := next.Add(1)
+				if i > int64(b.N) {
+					return
+				}
+				client.CreateAnchor(ctx, &domain.Anchor{Hash: fmt.Sprintf("par-%d", i)})
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// BenchmarkCreateAnchor_500Singles and BenchmarkCreateAnchors_Batch500 anchor the same
+// 500 hashes per iteration; the batch pays for one file write instead of 500.
+func BenchmarkCreateAnchor_500Singles(b *testing.B) {
+	client, _ := NewFileLedgerClientWithLogger(filepath.Join(b.TempDir(), "ledger.json"), discardLogger)
+	defer client.Close()
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 500; j++ {
+			client.CreateAnchor(ctx, &domain.Anchor{Hash: fmt.Sprintf("single-%d-%d", i, j)})
+		}
+	}
+}
+
+func BenchmarkCreateAnchors_Batch500(b *testing.B) {
+	client, _ := NewFileLedgerClientWithLogger(filepath.Join(b.TempDir(), "ledger.json"), discardLogger)
+	defer client.Close()
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		batch := make([]*domain.Anchor, 500)
+		for j := range batch {
+			batch[j] = &domain.Anchor{Hash: fmt.Sprintf("batch-%d-%d", i, j)}
+		}
+		if _, err := client.CreateAnchors(ctx, batch); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestAnchorExpiry(t *testing.T) {
+	client, _ := NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	defer client.Close()
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(50 * time.Millisecond)
+	if _, _, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: "short-lived", ExpiresAt: &expiresAt}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	if _, _, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: "forever"}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+
+	if !client.VerifyAnchor(ctx, "short-lived") {
+		t.Error("anchor should verify before expiry")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if client.VerifyAnchor(ctx, "short-lived") {
+		t.Error("expired anchor must not verify")
+	}
+	if _, err := client.GetAnchor(ctx, "short-lived"); !errors.Is(err, ErrExpired) {
+		t.Errorf("expected ErrExpired, got %v", err)
+	}
+	if !client.VerifyAnchor(ctx, "forever") {
+		t.Error("anchor without ExpiresAt must keep verifying")
+	}
+
+	n, err := client.PruneExpired()
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 pruned anchor, got %d (%v)", n, err)
+	}
+	if stats := client.GetStats(); stats.Pruned != 1 || stats.Anchors != 1 {
+		t.Errorf("unexpected stats after prune: %v", stats)
+	}
+	if _, err := client.GetAnchor(ctx, "short-lived"); errors.Is(err, ErrExpired) || err == nil {
+		t.Errorf("pruned anchor should be not found, got %v", err)
+	}
+}
+
+func TestReaperPrunesInBackground(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	client, _ := NewFileLedgerClient(ledgerPath)
+	defer client.Close()
+	client.StartReaper(10 * time.Millisecond)
+
+	expiresAt := time.Now().Add(20 * time.Millisecond)
+	client.CreateAnchor(context.Background(), &domain.Anchor{Hash: "reaped", ExpiresAt: &expiresAt})
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if client.GetStats().Pruned == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if client.GetStats().Pruned != 1 {
+		t.Fatal("reaper did not prune expired anchor")
+	}
+
+	// The prune must be persisted
+	reopened, _ := NewFileLedgerClient(ledgerPath)
+	defer reopened.Close()
+	if reopened.GetStats().Anchors != 0 {
+		t.Error("pruned anchor still present on disk")
+	}
+}
+
+func TestGetStats(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	client, _ := NewFileLedgerClient(ledgerPath)
+	defer client.Close()
+	ctx := context.Background()
+
+	empty := client.GetStats()
+	if empty.FileSizeBytes != 0 || empty.LastWriteTime != nil {
+		t.Errorf("expected no file metrics before first write, got %+v", empty)
+	}
+
+	client.CreateAnchor(ctx, &domain.Anchor{Hash: "a1"})
+	client.CreateAnchor(ctx, &domain.Anchor{Hash: "a2"})
+	client.CreateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:stats"})
+
+	stats := client.GetStats()
+	if stats.Anchors != 2 || stats.DIDs != 1 || stats.NextBlock != 3 {
+		t.Errorf("unexpected counts: %+v", stats)
+	}
+	if stats.DocTypes["anchor"] != 2 || stats.DocTypes["did"] != 1 {
+		t.Errorf("unexpected docType counts: %v", stats.DocTypes)
+	}
+	if stats.Mode != "file-persistent" || stats.Path != ledgerPath {
+		t.Errorf("unexpected mode/path: %s %s", stats.Mode, stats.Path)
+	}
+	if stats.FileSizeBytes <= 0 || stats.LastWriteTime == nil {
+		t.Errorf("expected file size and last write time, got %+v", stats)
+	}
+	if len(stats.LastWrites) != 2 || stats.LastWrites["did"].Before(stats.LastWrites["anchor"]) {
+		t.Errorf("expected the last anchor and DID writes, got %v", stats.LastWrites)
+	}
+	if empty.LastWrites != nil || stats.LastCompaction != nil {
+		t.Errorf("expected no writes before the first and no compaction yet, got %v and %v", empty.LastWrites, stats.LastCompaction)
+	}
+
+	before := time.Now()
+	if _, err := client.PruneExpired(); err != nil {
+		t.Fatalf("PruneExpired failed: %v", err)
+	}
+	if stats = client.GetStats(); stats.LastCompaction == nil || stats.LastCompaction.Before(before) {
+		t.Errorf("expected the compaction to be recorded, got %v", stats.LastCompaction)
+	}
+}
+
+func TestPing(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	client, err := NewFileLedgerClient(filepath.Join(dir, "ledger.json"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("expected a healthy ledger, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected Ping to leave no files behind, got %v", entries)
+	}
+
+	// A lost ledger directory is reported, not only on the next write
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Ping(ctx); !errors.Is(err, ErrTransient) {
+		t.Errorf("expected a transient error without the ledger directory, got %v", err)
+	}
+
+	client.Close()
+	if err := client.Ping(ctx); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed, got %v", err)
+	}
+}
+
+func TestUseAfterClose(t *testing.T) {
+	client, _ := NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	ctx := context.Background()
+
+	client.CreateAnchor(ctx, &domain.Anchor{Hash: "before-close"})
+	sub, _ := client.SubscribeAnchors(ctx)
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if _, _, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: "after-close"}); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("CreateAnchor: expected ErrClientClosed, got %v", err)
+	}
+	if _, _, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: "before-close"}); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("idempotent CreateAnchor: expected ErrClientClosed, got %v", err)
+	}
+	if _, err := client.GetAnchor(ctx, "before-close"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("GetAnchor: expected ErrClientClosed, got %v", err)
+	}
+	if client.VerifyAnchor(ctx, "before-close") {
+		t.Error("VerifyAnchor must return false after Close")
+	}
+	if err := client.CreateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:closed"}); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("CreateDid: expected ErrClientClosed, got %v", err)
+	}
+	if _, err := client.GetDid(ctx, "did:ewallet:closed"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("GetDid: expected ErrClientClosed, got %v", err)
+	}
+	if _, err := client.SubscribeAnchors(ctx); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("SubscribeAnchors: expected ErrClientClosed, got %v", err)
+	}
+	if _, ok := <-sub; ok {
+		t.Error("existing subscription should be closed by Close")
+	}
+}
+
+func TestWritesHonorContext(t *testing.T) {
+	client, err := NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	if err != nil {
+		t.Fatalf("NewFileLedgerClient failed: %v", err)
+	}
+	defer client.Close()
+
+	// Hold the writer, as a hung fsync would
+	release := make(chan struct{})
+	blocked := make(chan struct{})
+	go client.submit(context.Background(), "Block", func(*LedgerState) (bool, error) {
+		close(blocked)
+		<-release
+		return false, nil
+	})
+	<-blocked
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, err = client.CreateAnchor(ctx, &domain.Anchor{Hash: "late-hash"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline error while the writer is busy, got %v", err)
+	}
+	if waited := time.Since(start); waited > time.Second {
+		t.Errorf("expected CreateAnchor to give up at the deadline, waited %s", waited)
+	}
+
+	// Once the writer is free, the abandoned write is skipped rather than committed
+	close(release)
+	if _, _, err := client.CreateAnchor(context.Background(), &domain.Anchor{Hash: "next-hash"}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	if _, err := client.GetAnchor(context.Background(), "late-hash"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the timed-out anchor not to be committed, got %v", err)
+	}
+}
+
+func TestDoubleClose(t *testing.T) {
+	client, _ := NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("first Close failed: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("second Close failed: %v", err)
+	}
+}
+
+func TestCloseFlushesInFlightWrites(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	client, _ := NewFileLedgerClient(ledgerPath)
+	ctx := context.Background()
+
+	var g errgroup.Group
+	for i := 0; i < 20; i++ {
+		i := i
+		g.Go(func() error {
+			_, _, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: fmt.Sprintf("flush-%d", i)})
+			if errors.Is(err, ErrClientClosed) {
+				return nil
+			}
+			return err
+		})
+	}
+	client.Close()
+	if err := g.Wait(); err != nil {
+		t.Fatalf("write failed during close: %v", err)
+	}
+
+	// Every write that was accepted must be on disk
+	reopened, _ := NewFileLedgerClient(ledgerPath)
+	defer reopened.Close()
+	if got := reopened.GetStats().Anchors; got != client.GetStats().Anchors {
+		t.Errorf("expected %d anchors on disk, got %d", client.GetStats().Anchors, got)
+	}
+}
+
+func TestIssuerDIDRoundTripAcrossRestart(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	ctx := context.Background()
+
+	client1, _ := NewFileLedgerClient(ledgerPath)
+	created := &domain.Anchor{Hash: "issued-hash", IssuerDID: "did:ewallet:issuer-1", Metadata: json.RawMessage(`"m"`)}
+	if _, _, err := client1.CreateAnchor(ctx, created); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	client1.Close()
+
+	client2, _ := NewFileLedgerClient(ledgerPath)
+	defer client2.Close()
+
+	got, err := client2.GetAnchor(ctx, "issued-hash")
+	if err != nil {
+		t.Fatalf("GetAnchor failed: %v", err)
+	}
+	if got.IssuerDID != created.IssuerDID || got.TxID != created.TxID || string(got.Metadata) != string(created.Metadata) ||
+		got.BlockNumber != created.BlockNumber || !got.Timestamp.Equal(created.Timestamp) {
+		t.Errorf("round trip mismatch:\n created %+v\n got     %+v", created, got)
+	}
+}
+
+func TestLoadLegacyLedgerWithoutIssuerDID(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	legacy := `{
+  "records": {
+    "legacy-hash": {
+      "commitment": "legacy-hash",
+      "txId": "tx-1700000000000000000",
+      "blockNumber": 1,
+      "timestamp": "2025-01-01T00:00:00Z",
+      "docType": "anchor"
+    }
+  },
+  "nextBlock": 2
+}`
+	if err := os.WriteFile(ledgerPath, []byte(legacy), 0644); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	client, err := NewFileLedgerClient(ledgerPath)
+	if err != nil {
+		t.Fatalf("failed to load legacy ledger: %v", err)
+	}
+	defer client.Close()
+
+	got, err := client.GetAnchor(context.Background(), "legacy-hash")
+	if err != nil {
+		t.Fatalf("GetAnchor failed: %v", err)
+	}
+	if got.IssuerDID != "" || got.TxID != "tx-1700000000000000000" {
+		t.Errorf("unexpected legacy anchor: %+v", got)
+	}
+	if client.state.Version != currentLedgerVersion {
+		t.Errorf("expected state migrated to version %d, got %d", currentLedgerVersion, client.state.Version)
+	}
+
+	// The next write persists the upgraded format
+	client.CreateAnchor(context.Background(), &domain.Anchor{Hash: "new-hash", IssuerDID: "did:ewallet:x"})
+	data, _ := os.ReadFile(ledgerPath)
+	if !strings.Contains(string(data), `"version": 1`) || !strings.Contains(string(data), `"issuerDid": "did:ewallet:x"`) {
+		t.Errorf("upgraded file missing version or issuerDid:\n%s", data)
+	}
+}
+
+func TestTxIDsUniqueInTightLoop(t *testing.T) {
+	client, _ := NewFileLedgerClientWithLogger(filepath.Join(t.TempDir(), "ledger.json"), discardLogger)
+	defer client.Close()
+	ctx := context.Background()
+
+	seen := make(map[string]string, 1000)
+	for i := 0; i < 1000; i++ {
+		hash := fmt.Sprintf("loop-%d", i)
+		txID, _, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: hash})
+		if err != nil {
+			t.Fatalf("CreateAnchor %d failed: %v", i, err)
+		}
+		if other, dup := seen[txID]; dup {
+			t.Fatalf("txID %s reused for %s and %s", txID, other, hash)
+		}
+		seen[txID] = hash
+	}
+}
+
+func TestGetAnchorByTxID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	client, _ := NewFileLedgerClient(path)
+	ctx := context.Background()
+
+	txID, _, _ := client.CreateAnchor(ctx, &domain.Anchor{Hash: "by-tx", IssuerDID: "did:ewallet:i"})
+
+	got, err := client.GetAnchorByTxID(ctx, txID)
+	if err != nil {
+		t.Fatalf("GetAnchorByTxID failed: %v", err)
+	}
+	if got.Hash != "by-tx" || got.IssuerDID != "did:ewallet:i" {
+		t.Errorf("unexpected anchor: %+v", got)
+	}
+
+	if _, err := client.GetAnchorByTxID(ctx, "unknown-tx"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown txID, got %v", err)
+	}
+
+	// The txID index is rebuilt from the records when the file is loaded again
+	client.Close()
+	reopened, err := NewFileLedgerClient(path)
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	defer reopened.Close()
+
+	got, err = reopened.GetAnchorByTxID(ctx, txID)
+	if err != nil {
+		t.Fatalf("GetAnchorByTxID after restart failed: %v", err)
+	}
+	if got.Hash != "by-tx" || got.TxID != txID {
+		t.Errorf("unexpected anchor after restart: %+v", got)
+	}
+}
+
+func TestListDids(t *testing.T) {
+	client, _ := NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	defer client.Close()
+	ctx := context.Background()
+
+	empty, err := client.ListDids(ctx, DidListOptions{ListOptions: ListOptions{Limit: 10}})
+	if err != nil {
+		t.Fatalf("ListDids on empty ledger failed: %v", err)
+	}
+	if len(empty.Items) != 0 || empty.Total != 0 || empty.NextCursor != "" {
+		t.Errorf("expected empty page, got %+v", empty)
+	}
+
+	for i := 1; i <= 5; i++ {
+		controller := "did:ewallet:alice"
+		if i%2 == 0 {
+			controller = "did:ewallet:bob"
+		}
+		doc := &domain.DIDDocument{ID: fmt.Sprintf("did:ewallet:%d", i), Controller: controller}
+		if err := client.CreateDid(ctx, doc); err != nil {
+			t.Fatalf("CreateDid failed: %v", err)
+		}
+	}
+	// Anchors share the record map and must not show up in DID listings
+	client.CreateAnchor(ctx, &domain.Anchor{Hash: "not-a-did"})
+
+	var ids []string
+	opts := DidListOptions{ListOptions: ListOptions{Limit: 2}}
+	for {
+		page, err := client.ListDids(ctx, opts)
+		if err != nil {
+			t.Fatalf("ListDids failed: %v", err)
+		}
+		if page.Total != 5 {
+			t.Errorf("expected total 5, got %d", page.Total)
+		}
+		for _, doc := range page.Items {
+			ids = append(ids, doc.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	if got := strings.Join(ids, ","); got != "did:ewallet:1,did:ewallet:2,did:ewallet:3,did:ewallet:4,did:ewallet:5" {
+		t.Errorf("unexpected order across pages: %s", got)
+	}
+
+	bob, err := client.ListDids(ctx, DidListOptions{ListOptions: ListOptions{Limit: 10}, Controller: "did:ewallet:bob"})
+	if err != nil {
+		t.Fatalf("ListDids with controller failed: %v", err)
+	}
+	if bob.Total != 2 || len(bob.Items) != 2 || bob.Items[0].ID != "did:ewallet:2" || bob.Items[1].ID != "did:ewallet:4" {
+		t.Errorf("unexpected controller filter result: %+v", bob)
+	}
+
+	if _, err := client.ListDids(ctx, DidListOptions{ListOptions: ListOptions{Limit: 2, Cursor: "garbage"}}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation for bad cursor, got %v", err)
+	}
+}
+
+func TestGetAnchorsByIssuer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	client, _ := NewFileLedgerClient(path)
+	ctx := context.Background()
+
+	// Interleave two issuers so their blocks alternate
+	for i := 1; i <= 3; i++ {
+		client.CreateAnchor(ctx, &domain.Anchor{Hash: fmt.Sprintf("a-%d", i), IssuerDID: "did:ewallet:a"})
+		client.CreateAnchor(ctx, &domain.Anchor{Hash: fmt.Sprintf("b-%d", i), IssuerDID: "did:ewallet:b"})
+	}
+	client.CreateAnchor(ctx, &domain.Anchor{Hash: "no-issuer"})
+
+	hashes := func(c *FileLedgerClient, issuer string, limit int) []string {
+		t.Helper()
+		var got []string
+		opts := ListOptions{Limit: limit}
+		for {
+			page, err := c.GetAnchorsByIssuer(ctx, issuer, opts)
+			if err != nil {
+				t.Fatalf("GetAnchorsByIssuer(%s) failed: %v", issuer, err)
+			}
+			for _, a := range page.Items {
+				if a.IssuerDID != issuer {
+					t.Errorf("anchor %s has issuer %s, want %s", a.Hash, a.IssuerDID, issuer)
+				}
+				got = append(got, a.Hash)
+			}
+			if page.NextCursor == "" {
+				return got
+			}
+			opts.Cursor = page.NextCursor
+		}
+	}
+
+	if got := strings.Join(hashes(client, "did:ewallet:a", 2), ","); got != "a-1,a-2,a-3" {
+		t.Errorf("issuer a: got %s", got)
+	}
+	if got := strings.Join(hashes(client, "did:ewallet:b", 1), ","); got != "b-1,b-2,b-3" {
+		t.Errorf("issuer b: got %s", got)
+	}
+
+	none, err := client.GetAnchorsByIssuer(ctx, "did:ewallet:nobody", ListOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("GetAnchorsByIssuer for unknown issuer failed: %v", err)
+	}
+	if none.Items == nil || len(none.Items) != 0 || none.Total != 0 {
+		t.Errorf("expected empty page, got %+v", none)
+	}
+
+	if _, err := client.GetAnchorsByIssuer(ctx, "", ListOptions{Limit: 10}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation for empty issuer, got %v", err)
+	}
+
+	// Returned anchors are copies
+	page, _ := client.GetAnchorsByIssuer(ctx, "did:ewallet:a", ListOptions{Limit: 1})
+	page.Items[0].Hash = "mutated"
+	if got := hashes(client, "did:ewallet:a", 10)[0]; got != "a-1" {
+		t.Errorf("mutating a result changed the ledger: %s", got)
+	}
+
+	// The index is rebuilt from the file on restart
+	client.Close()
+	reopened, err := NewFileLedgerClient(path)
+	if err != nil {
+		t.Fatalf("failed to reopen: %v", err)
+	}
+	defer reopened.Close()
+	if got := strings.Join(hashes(reopened, "did:ewallet:b", 10), ","); got != "b-1,b-2,b-3" {
+		t.Errorf("issuer b after restart: got %s", got)
+	}
+}
+
+func TestGetAnchorsByIssuerAfterPrune(t *testing.T) {
+	client, _ := NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	defer client.Close()
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Minute)
+	client.CreateAnchor(ctx, &domain.Anchor{Hash: "expired", IssuerDID: "did:ewallet:a", ExpiresAt: &past})
+	client.CreateAnchor(ctx, &domain.Anchor{Hash: "live", IssuerDID: "did:ewallet:a"})
+
+	if _, err := client.PruneExpired(); err != nil {
+		t.Fatalf("PruneExpired failed: %v", err)
+	}
+
+	client.mu.RLock()
+	entries := len(client.index.byIssuer["did:ewallet:a"])
+	client.mu.RUnlock()
+	if entries != 1 {
+		t.Errorf("expected pruned anchor to leave the index, got %d entries", entries)
+	}
+
+	page, _ := client.GetAnchorsByIssuer(ctx, "did:ewallet:a", ListOptions{Limit: 10})
+	if page.Total != 1 || page.Items[0].Hash != "live" {
+		t.Errorf("unexpected page after prune: %+v", page)
+	}
+}
+
+func TestQueryAnchors(t *testing.T) {
+	client, _ := NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	defer client.Close()
+	ctx := context.Background()
+
+	// times[i] is the commit timestamp of the anchor in block i
+	times := make([]time.Time, 6)
+	for i := 1; i <= 5; i++ {
+		anchor := &domain.Anchor{Hash: fmt.Sprintf("h-%d", i)}
+		if _, _, err := client.CreateAnchor(ctx, anchor); err != nil {
+			t.Fatalf("CreateAnchor failed: %v", err)
+		}
+		times[anchor.BlockNumber] = anchor.Timestamp
+	}
+
+	blocks := func(filter AnchorFilter) string {
+		t.Helper()
+		anchors, err := client.QueryAnchors(ctx, filter)
+		if err != nil {
+			t.Fatalf("QueryAnchors(%+v) failed: %v", filter, err)
+		}
+		var got []string
+		for _, a := range anchors {
+			got = append(got, fmt.Sprint(a.BlockNumber))
+		}
+		return strings.Join(got, ",")
+	}
+
+	tests := []struct {
+		name   string
+		filter AnchorFilter
+		want   string
+	}{
+		{"unbounded", AnchorFilter{}, "1,2,3,4,5"},
+		{"inclusive block range", AnchorFilter{FromBlock: 2, ToBlock: 4}, "2,3,4"},
+		{"single block", AnchorFilter{FromBlock: 3, ToBlock: 3}, "3"},
+		{"open upper block", AnchorFilter{FromBlock: 4}, "4,5"},
+		{"beyond last block", AnchorFilter{FromBlock: 6}, ""},
+		{"inclusive time range", AnchorFilter{FromTime: times[2], ToTime: times[3]}, "2,3"},
+		{"combined block and time", AnchorFilter{FromBlock: 3, ToBlock: 5, ToTime: times[4]}, "3,4"},
+		{"disjoint block and time", AnchorFilter{FromBlock: 4, ToTime: times[2]}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blocks(tt.filter); got != tt.want {
+				t.Errorf("got blocks %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, inverted := range []AnchorFilter{
+		{FromBlock: 5, ToBlock: 2},
+		{FromTime: times[3], ToTime: times[1]},
+	} {
+		if _, err := client.QueryAnchors(ctx, inverted); !errors.Is(err, ErrValidation) {
+			t.Errorf("expected ErrValidation for %+v, got %v", inverted, err)
+		}
+	}
+}
+
+func TestTombstoneAnchor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	client, _ := NewFileLedgerClient(path)
+	defer client.Close()
+	ctx := context.Background()
+
+	anchor := &domain.Anchor{Hash: "erase-me", IssuerDID: "did:ewallet:issuer", Metadata: json.RawMessage(`"personal-data-123"`)}
+	txID, block, _ := client.CreateAnchor(ctx, anchor)
+
+	if err := client.TombstoneAnchor(ctx, "erase-me", ""); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation without reason, got %v", err)
+	}
+	if err := client.TombstoneAnchor(ctx, "unknown", "gdpr"); !errors.Is(err, ErrNotFound) {
+		t.Error("expected ErrNotFound for unknown anchor")
+	}
+
+	if err := client.TombstoneAnchor(ctx, "erase-me", "GDPR art. 17 request"); err != nil {
+		t.Fatalf("TombstoneAnchor failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read ledger file: %v", err)
+	}
+	for _, erased := range []string{"personal-data-123", "did:ewallet:issuer"} {
+		if strings.Contains(string(data), erased) {
+			t.Errorf("ledger file still contains %q after tombstoning", erased)
+		}
+	}
+	if !strings.Contains(string(data), txID) {
+		t.Error("ledger file lost the txID of the tombstoned anchor")
+	}
+
+	got, err := client.GetAnchor(ctx, "erase-me")
+	if err != nil {
+		t.Fatalf("GetAnchor failed: %v", err)
+	}
+	if !got.Tombstoned || got.TombstonedAt == nil || got.TombstoneReason != "GDPR art. 17 request" {
+		t.Errorf("tombstone not surfaced: %+v", got)
+	}
+	if got.Metadata != nil || got.IssuerDID != "" || got.TxID != txID || got.BlockNumber != block {
+		t.Errorf("unexpected tombstoned anchor: %+v", got)
+	}
+	if !client.VerifyAnchor(ctx, "erase-me") {
+		t.Error("tombstoned anchor should still verify")
+	}
+
+	page, _ := client.GetAnchorsByIssuer(ctx, "did:ewallet:issuer", ListOptions{Limit: 10})
+	if page.Total != 0 {
+		t.Errorf("tombstoned anchor still listed under its issuer: %+v", page)
+	}
+
+	// Tombstoning twice keeps the original tombstone
+	if err := client.TombstoneAnchor(ctx, "erase-me", "second"); err != nil {
+		t.Fatalf("second TombstoneAnchor failed: %v", err)
+	}
+	if again, _ := client.GetAnchor(ctx, "erase-me"); again.TombstoneReason != "GDPR art. 17 request" {
+		t.Errorf("second tombstone overwrote the reason: %q", again.TombstoneReason)
+	}
+}
+
+func TestRevokeAnchor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	client, _ := NewFileLedgerClient(path)
+	ctx := context.Background()
+
+	client.CreateAnchor(ctx, &domain.Anchor{Hash: "revoke-me", IssuerDID: "did:ewallet:issuer"})
+
+	if err := client.RevokeAnchor(ctx, "unknown", "x"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown anchor, got %v", err)
+	}
+	if err := client.RevokeAnchor(ctx, "revoke-me", "key compromised"); err != nil {
+		t.Fatalf("RevokeAnchor failed: %v", err)
+	}
+	// Revoking twice keeps the first revocation
+	if err := client.RevokeAnchor(ctx, "revoke-me", "second"); err != nil {
+		t.Fatalf("second RevokeAnchor failed: %v", err)
+	}
+	client.Close()
+
+	reopened, _ := NewFileLedgerClient(path)
+	defer reopened.Close()
+	got, err := reopened.GetAnchor(ctx, "revoke-me")
+	if err != nil {
+		t.Fatalf("GetAnchor failed: %v", err)
+	}
+	if !got.Revoked || got.RevokedAt == nil || got.RevocationReason != "key compromised" {
+		t.Errorf("revocation not persisted: %+v", got)
+	}
+	if got.IssuerDID != "did:ewallet:issuer" || !reopened.VerifyAnchor(ctx, "revoke-me") {
+		t.Errorf("revoked anchor should keep its issuer and still exist: %+v", got)
+	}
+}
+
+func TestStatusListRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	client, _ := NewFileLedgerClient(path)
+	ctx := context.Background()
+
+	if _, err := client.GetStatusList(ctx, "creds"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	list := &domain.StatusList{ID: "creds", Purpose: "revocation", EncodedList: "H4sI", NextIndex: 3}
+	if err := client.SaveStatusList(ctx, list); err != nil {
+		t.Fatalf("SaveStatusList failed: %v", err)
+	}
+	list.NextIndex = 4
+	client.SaveStatusList(ctx, list)
+	client.Close()
+
+	reopened, _ := NewFileLedgerClient(path)
+	defer reopened.Close()
+	got, err := reopened.GetStatusList(ctx, "creds")
+	if err != nil || got.NextIndex != 4 || got.EncodedList != "H4sI" {
+		t.Errorf("unexpected status list %+v (%v)", got, err)
+	}
+	if stats := reopened.GetStats(); stats.DocTypes["statusList"] != 1 || stats.Anchors != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestVerifyAnchors(t *testing.T) {
+	client, _ := NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	defer client.Close()
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Minute)
+	client.CreateAnchor(ctx, &domain.Anchor{Hash: "live"})
+	client.CreateAnchor(ctx, &domain.Anchor{Hash: "expired", ExpiresAt: &past})
+	client.CreateAnchor(ctx, &domain.Anchor{Hash: "revoked"})
+	client.RevokeAnchor(ctx, "revoked", "")
+	client.CreateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:not-an-anchor"})
+
+	results, err := client.VerifyAnchors(ctx, []string{"live", "expired", "revoked", "missing", "did:ewallet:not-an-anchor"})
+	if err != nil {
+		t.Fatalf("VerifyAnchors failed: %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("expected a result per hash, got %+v", results)
+	}
+	if got := results["live"]; !got.Exists || got.Revoked || got.BlockNumber != 1 || got.Timestamp.IsZero() {
+		t.Errorf("unexpected live result: %+v", got)
+	}
+	if got := results["revoked"]; !got.Exists || !got.Revoked {
+		t.Errorf("unexpected revoked result: %+v", got)
+	}
+	for _, hash := range []string{"expired", "missing", "did:ewallet:not-an-anchor"} {
+		if results[hash].Exists {
+			t.Errorf("%s should not exist", hash)
+		}
+	}
+}
+
+func TestFindAnchorsByPrefix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	client, _ := NewFileLedgerClient(path)
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Minute)
+	for _, hash := range []string{"aa10", "aa11", "aa1", "ab10", "aa12"} {
+		client.CreateAnchor(ctx, &domain.Anchor{Hash: hash})
+	}
+	client.CreateAnchor(ctx, &domain.Anchor{Hash: "aa13", ExpiresAt: &past})
+
+	if _, err := client.FindAnchorsByPrefix(ctx, "aa1", 10); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation for a short prefix, got %v", err)
+	}
+
+	hashesOf := func(anchors []domain.Anchor) string {
+		hashes := make([]string, len(anchors))
+		for i, a := range anchors {
+			hashes[i] = a.Hash
+		}
+		return strings.Join(hashes, ",")
+	}
+
+	got, _ := client.FindAnchorsByPrefix(ctx, "aa10", 10)
+	if hashesOf(got) != "aa10" {
+		t.Errorf("unexpected exact-prefix result: %s", hashesOf(got))
+	}
+	client.Close()
+
+	// The index is rebuilt on load
+	reopened, _ := NewFileLedgerClient(path)
+	defer reopened.Close()
+	for _, hash := range []string{"aa100", "aa101"} {
+		reopened.CreateAnchor(ctx, &domain.Anchor{Hash: hash})
+	}
+
+	got, _ = reopened.FindAnchorsByPrefix(ctx, "aa10", 10)
+	if hashesOf(got) != "aa10,aa100,aa101" {
+		t.Errorf("unexpected overlapping-prefix result: %s", hashesOf(got))
+	}
+	got, _ = reopened.FindAnchorsByPrefix(ctx, "aa10", 2)
+	if hashesOf(got) != "aa10,aa100" {
+		t.Errorf("limit not applied in block order: %s", hashesOf(got))
+	}
+}
+
+func TestCreateAnchors(t *testing.T) {
+	client, _ := NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	defer client.Close()
+	ctx := context.Background()
+
+	existingTx, existingBlock, _ := client.CreateAnchor(ctx, &domain.Anchor{Hash: "existing"})
+
+	sub, _ := client.SubscribeAnchors(ctx)
+
+	batch := []*domain.Anchor{
+		{Hash: "new-1", IssuerDID: "did:ewallet:i"},
+		{Hash: "existing"},
+		{Hash: "new-2"},
+		{Hash: "new-1"}, // duplicate inside the batch
+		{Hash: ""},
+	}
+	results, err := client.CreateAnchors(ctx, batch)
+	if err != nil {
+		t.Fatalf("CreateAnchors failed: %v", err)
+	}
+
+	wantStatus := []AnchorStatus{AnchorCreated, AnchorExisted, AnchorCreated, AnchorFailed, AnchorFailed}
+	for i, want := range wantStatus {
+		if results[i].Status != want {
+			t.Errorf("item %d: status %s, want %s (err: %v)", i, results[i].Status, want, results[i].Err)
+		}
+	}
+	if !errors.Is(results[3].Err, ErrValidation) || !errors.Is(results[4].Err, ErrValidation) {
+		t.Errorf("expected validation errors for duplicate and empty hash, got %v / %v", results[3].Err, results[4].Err)
+	}
+	if results[1].TxID != existingTx || results[1].BlockNumber != existingBlock {
+		t.Errorf("existing anchor should report its original tx/block, got %+v", results[1])
+	}
+	if results[0].BlockNumber == results[2].BlockNumber || results[0].TxID == results[2].TxID {
+		t.Errorf("created anchors must get distinct blocks and txIDs: %+v %+v", results[0], results[2])
+	}
+	if batch[0].TxID != results[0].TxID {
+		t.Error("domain anchors not updated with their txID")
+	}
+
+	got, err := client.GetAnchor(ctx, "new-1")
+	if err != nil || got.IssuerDID != "did:ewallet:i" {
+		t.Errorf("batched anchor not readable: %+v, %v", got, err)
+	}
+
+	for _, want := range []string{"new-1", "new-2"} {
+		select {
+		case a := <-sub:
+			if a.Hash != want {
+				t.Errorf("expected event for %s, got %s", want, a.Hash)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no event for %s", want)
+		}
+	}
+
+	if _, err := client.CreateAnchors(ctx, nil); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation for empty batch, got %v", err)
+	}
+}
+
+func TestCreateAnchorsPersistenceFailure(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	client, _ := NewFileLedgerClient(ledgerPath)
+	defer client.Close()
+	ctx := context.Background()
+
+	if err := os.Mkdir(ledgerPath+".tmp", 0755); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	_, err := client.CreateAnchors(ctx, []*domain.Anchor{{Hash: "a"}, {Hash: "b"}})
+	if !errors.Is(err, ErrTransient) {
+		t.Fatalf("expected transient persistence error, got %v", err)
+	}
+	if client.VerifyAnchor(ctx, "a") || client.VerifyAnchor(ctx, "b") {
+		t.Error("no anchor of a failed batch may become visible")
+	}
+}
+
+func TestUpdateDid(t *testing.T) {
+	client, _ := NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	defer client.Close()
+	ctx := context.Background()
+
+	err := client.UpdateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:missing"}, 1)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	original := &domain.DIDDocument{
+		ID:                 "did:ewallet:u",
+		VerificationMethod: []domain.VerificationMethod{{ID: "did:ewallet:u#key-1", PublicKeyBase58: "old"}},
+	}
+	client.CreateDid(ctx, original)
+
+	time.Sleep(time.Millisecond)
+	update := &domain.DIDDocument{
+		ID:                 "did:ewallet:u",
+		VerificationMethod: []domain.VerificationMethod{{ID: "did:ewallet:u#key-1", PublicKeyBase58: "new"}},
+		Service:            []domain.Service{{ID: "did:ewallet:u#service-1", Type: "DIDCommMessaging", ServiceEndpoint: "https://example.com"}},
+	}
+	if err := client.UpdateDid(ctx, update, 1); err != nil {
+		t.Fatalf("UpdateDid failed: %v", err)
+	}
+
+	got, _ := client.GetDid(ctx, "did:ewallet:u")
+	if got.VerificationMethod[0].PublicKeyBase58 != "new" || len(got.Service) != 1 {
+		t.Errorf("document not replaced: %+v", got)
+	}
+	if !got.Created.Equal(original.Created) {
+		t.Errorf("Created changed from %v to %v", original.Created, got.Created)
+	}
+	if !got.Updated.After(got.Created) || !update.Updated.Equal(got.Updated) {
+		t.Errorf("Updated not bumped: created %v, updated %v", got.Created, got.Updated)
+	}
+}
+
+func TestDeactivateDid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	client, _ := NewFileLedgerClient(path)
+	ctx := context.Background()
+
+	if err := client.DeactivateDid(ctx, "did:ewallet:missing", 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	client.CreateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:d"})
+	if err := client.DeactivateDid(ctx, "did:ewallet:d", 1); err != nil {
+		t.Fatalf("DeactivateDid failed: %v", err)
+	}
+	if err := client.DeactivateDid(ctx, "did:ewallet:d", 1); !errors.Is(err, ErrDeactivated) {
+		t.Errorf("expected ErrDeactivated on second deactivation, got %v", err)
+	}
+	if err := client.UpdateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:d"}, 2); !errors.Is(err, ErrDeactivated) {
+		t.Errorf("expected ErrDeactivated on update, got %v", err)
+	}
+
+	// Deactivation is persisted
+	client.Close()
+	reopened, _ := NewFileLedgerClient(path)
+	defer reopened.Close()
+
+	got, err := reopened.GetDid(ctx, "did:ewallet:d")
+	if err != nil {
+		t.Fatalf("GetDid failed: %v", err)
+	}
+	if !got.Deactivated || got.DeactivatedAt == nil {
+		t.Errorf("deactivation not persisted: %+v", got)
+	}
+}
+
+func TestLoadLegacyStringJWK(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	legacy := `{
+  "version": 1,
+  "records": {
+    "did:ewallet:jwk": {
+      "commitment": "did:ewallet:jwk",
+      "txId": "tx-1",
+      "blockNumber": 1,
+      "timestamp": "2025-01-01T00:00:00Z",
+      "docType": "did",
+      "didDoc": {
+        "id": "did:ewallet:jwk",
+        "verificationMethod": [{
+          "id": "did:ewallet:jwk#key-1",
+          "type": "JsonWebKey2020",
+          "controller": "did:ewallet:jwk",
+          "publicKeyJwk": "{\"kty\":\"OKP\",\"crv\":\"Ed25519\",\"x\":\"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo\"}"
+        }],
+        "created": "2025-01-01T00:00:00Z",
+        "updated": "2025-01-01T00:00:00Z"
+      }
+    }
+  },
+  "nextBlock": 2
+}`
+	if err := os.WriteFile(ledgerPath, []byte(legacy), 0644); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	client, err := NewFileLedgerClient(ledgerPath)
+	if err != nil {
+		t.Fatalf("failed to load ledger with string JWK: %v", err)
+	}
+	defer client.Close()
+
+	doc, err := client.GetDid(context.Background(), "did:ewallet:jwk")
+	if err != nil {
+		t.Fatalf("GetDid failed: %v", err)
+	}
+	jwk := doc.VerificationMethod[0].PublicKeyJwk
+	if jwk == nil || jwk.Kty != "OKP" || jwk.Crv != "Ed25519" {
+		t.Fatalf("string JWK not upgraded: %+v", jwk)
+	}
+
+	// The next write persists the JWK as an object
+	client.CreateAnchor(context.Background(), &domain.Anchor{Hash: "trigger-write"})
+	data, _ := os.ReadFile(ledgerPath)
+	if !strings.Contains(string(data), `"publicKeyJwk": {`) {
+		t.Errorf("JWK not rewritten as an object:\n%s", data)
+	}
+}
+
+func TestLoadLegacyStringMetadata(t *testing.T) {
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.json")
+	legacy := `{
+  "version": 1,
+  "records": {
+    "legacy-hash": {
+      "commitment": "legacy-hash",
+      "txId": "tx-1",
+      "blockNumber": 1,
+      "timestamp": "2025-01-01T00:00:00Z",
+      "metadata": "{\"course\":\"math\"}",
+      "docType": "anchor"
+    }
+  },
+  "nextBlock": 2
+}`
+	if err := os.WriteFile(ledgerPath, []byte(legacy), 0644); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	client, err := NewFileLedgerClient(ledgerPath)
+	if err != nil {
+		t.Fatalf("failed to load ledger with string metadata: %v", err)
+	}
+	defer client.Close()
+
+	anchor, err := client.GetAnchor(context.Background(), "legacy-hash")
+	if err != nil {
+		t.Fatalf("GetAnchor failed: %v", err)
+	}
+	// The legacy string is kept verbatim as a JSON string value
+	var metadata string
+	if err := json.Unmarshal(anchor.Metadata, &metadata); err != nil || metadata != `{"course":"math"}` {
+		t.Errorf("unexpected legacy metadata %s (%v)", anchor.Metadata, err)
+	}
+	if anchor.MetadataHash != "" {
+		t.Errorf("legacy record should have no metadata hash, got %q", anchor.MetadataHash)
+	}
+}
+
+func TestDidVersionID(t *testing.T) {
+	client, _ := NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	defer client.Close()
+	ctx := context.Background()
+
+	version := func() uint64 {
+		t.Helper()
+		doc, err := client.GetDid(ctx, "did:ewallet:v")
+		if err != nil {
+			t.Fatalf("GetDid failed: %v", err)
+		}
+		return doc.VersionID
+	}
+
+	doc := &domain.DIDDocument{ID: "did:ewallet:v"}
+	client.CreateDid(ctx, doc)
+	if doc.VersionID != 1 || version() != 1 {
+		t.Fatalf("expected version 1 after create, got %d/%d", doc.VersionID, version())
+	}
+
+	update := &domain.DIDDocument{ID: "did:ewallet:v"}
+	client.UpdateDid(ctx, update, 1)
+	if update.VersionID != 2 || version() != 2 {
+		t.Fatalf("expected version 2 after update, got %d/%d", update.VersionID, version())
+	}
+
+	// Changes authorized against an older version are refused
+	if err := client.UpdateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:v", Controller: "did:ewallet:stale"}, 1); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists for a stale update, got %v", err)
+	}
+	if err := client.DeactivateDid(ctx, "did:ewallet:v", 1); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists for a stale deactivation, got %v", err)
+	}
+	if got := version(); got != 2 {
+		t.Fatalf("stale changes moved the version to %d", got)
+	}
+
+	client.DeactivateDid(ctx, "did:ewallet:v", 2)
+	if got := version(); got != 3 {
+		t.Errorf("expected version 3 after deactivation, got %d", got)
+	}
+
+	if _, err := client.GetDid(ctx, "did:ewallet:missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown DID, got %v", err)
+	}
+}
+
+func TestWebhookRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	client, _ := NewFileLedgerClient(path)
+	ctx := context.Background()
+
+	created := time.Now().UTC()
+	for i, id := range []string{"wh_b", "wh_a"} {
+		hook := &domain.Webhook{ID: id, URL: "https://example.com/" + id, Created: created.Add(time.Duration(i) * time.Second)}
+		if err := client.SaveWebhook(ctx, hook); err != nil {
+			t.Fatalf("SaveWebhook failed: %v", err)
+		}
+	}
+	client.Close()
+
+	reopened, _ := NewFileLedgerClient(path)
+	defer reopened.Close()
+	hooks, err := reopened.ListWebhooks(ctx)
+	if err != nil || len(hooks) != 2 || hooks[0].ID != "wh_b" || hooks[1].ID != "wh_a" {
+		t.Fatalf("expected both webhooks in creation order, got %+v (%v)", hooks, err)
+	}
+
+	if err := reopened.DeleteWebhook(ctx, "wh_b"); err != nil {
+		t.Fatalf("DeleteWebhook failed: %v", err)
+	}
+	if err := reopened.DeleteWebhook(ctx, "wh_b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+	if hooks, _ := reopened.ListWebhooks(ctx); len(hooks) != 1 || hooks[0].ID != "wh_a" {
+		t.Errorf("unexpected webhooks after delete: %+v", hooks)
+	}
+	if stats := reopened.GetStats(); stats.DocTypes["webhook"] != 1 || stats.Anchors != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	client, err := NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	if err != nil {
+		t.Fatalf("NewFileLedgerClient failed: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	if err := client.CreateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:dup"}); err != nil {
+		t.Fatalf("CreateDid failed: %v", err)
+	}
+	if err := client.CreateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:dup"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists for a duplicate DID, got %v", err)
+	}
+	if _, err := client.GetAnchor(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown anchor, got %v", err)
+	}
+	if err := client.UpdateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:missing"}, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown DID, got %v", err)
+	}
+	if _, err := client.CreateAnchors(ctx, nil); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation for an empty batch, got %v", err)
+	}
+}
+
+func TestNewLedgerClient_LocksFileLedger(t *testing.T) {
+	cfg := Config{Mode: "file", FilePath: filepath.Join(t.TempDir(), "ledger.json")}
+	first, err := NewLedgerClient(cfg)
+	if err != nil {
+		t.Fatalf("NewLedgerClient failed: %v", err)
+	}
+
+	if _, err := NewLedgerClient(cfg); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked while the ledger is open, got %v", err)
+	}
+	// Clients within the process share the file, as tests do
+	shared, err := NewFileLedgerClient(cfg.FilePath)
+	if err != nil {
+		t.Fatalf("expected an unlocked client to open the file, got %v", err)
+	}
+	shared.Close()
+
+	first.Close()
+	second, err := NewLedgerClient(cfg)
+	if err != nil {
+		t.Fatalf("expected the lock to be released by Close, got %v", err)
+	}
+	second.Close()
+}
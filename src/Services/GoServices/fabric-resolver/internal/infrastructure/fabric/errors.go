@@ -0,0 +1,57 @@
+package fabric
+
+import (
+	"context"
+	"errors"
+)
+
+// Sentinel errors returned (wrapped) by LedgerClient implementations.
+// Callers should use errors.Is to classify them.
+var (
+	// ErrTransient marks a failure that may succeed if the operation is retried
+	// (file system blips, endorsement timeouts, ...).
+	ErrTransient = errors.New("transient ledger error")
+
+	// ErrAlreadyExists is returned when a record with the same key already exists.
+	ErrAlreadyExists = errors.New("already exists")
+
+	// ErrNotFound is returned when the record an operation targets does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrValidation is returned when the input is rejected by the ledger.
+	ErrValidation = errors.New("validation failed")
+
+	// ErrClientClosed is returned by every operation after Close has been called.
+	ErrClientClosed = errors.New("ledger client is closed")
+
+	// ErrExpired is returned when a record exists but its ExpiresAt has passed.
+	ErrExpired = errors.New("expired")
+
+	// ErrDeactivated is returned when modifying a DID that has been deactivated.
+	ErrDeactivated = errors.New("deactivated")
+
+	// ErrReadOnly is returned by writes while the ledger is in read-only mode.
+	ErrReadOnly = errors.New("ledger is read-only")
+
+	// ErrUnknownTenant is returned for a tenant the resolver does not host.
+	ErrUnknownTenant = errors.New("unknown tenant")
+
+	// ErrLocked is returned when opening a file ledger that another process has open.
+	ErrLocked = errors.New("ledger is locked by another process")
+)
+
+// IsTransient reports whether err is classified as transient and therefore safe to retry.
+// Context cancellation and deadline errors are never transient.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrAlreadyExists) || errors.Is(err, ErrNotFound) || errors.Is(err, ErrValidation) ||
+		errors.Is(err, ErrDeactivated) {
+		return false
+	}
+	return errors.Is(err, ErrTransient)
+}
@@ -3,45 +3,192 @@ package fabric
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io/fs"
+	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
 	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/tracing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Record represents a single immutable entry in the ledger
 type Record struct {
 	Commitment  string              `json:"commitment"` // The hash/commitment
+	Algorithm   string              `json:"algorithm,omitempty"`
+	IssuerDID   string              `json:"issuerDid,omitempty"`
 	TxID        string              `json:"txId"`
 	BlockNumber uint64              `json:"blockNumber"`
 	Timestamp   time.Time           `json:"timestamp"`
-	Metadata    string              `json:"metadata,omitempty"`
-	DocType     string              `json:"docType"` // "anchor" or "did"
+	Metadata    json.RawMessage     `json:"metadata,omitempty"`
+	DocType     string              `json:"docType"` // "anchor", "did", "statusList" or "webhook"
 	DIDDoc      *domain.DIDDocument `json:"didDoc,omitempty"`
+	StatusList  *domain.StatusList  `json:"statusList,omitempty"`
+	Webhook     *domain.Webhook     `json:"webhook,omitempty"`
+	ExpiresAt   *time.Time          `json:"expiresAt,omitempty"`
+
+	MetadataHash      string `json:"metadataHash,omitempty"`
+	SignatureVerified bool   `json:"signatureVerified,omitempty"`
+
+	Revoked          bool       `json:"revoked,omitempty"`
+	RevokedAt        *time.Time `json:"revokedAt,omitempty"`
+	RevocationReason string     `json:"revocationReason,omitempty"`
+
+	Tombstoned      bool       `json:"tombstoned,omitempty"`
+	TombstonedAt    *time.Time `json:"tombstonedAt,omitempty"`
+	TombstoneReason string     `json:"tombstoneReason,omitempty"`
+}
+
+// toAnchor converts an anchor record to its domain representation.
+func (r Record) toAnchor() *domain.Anchor {
+	return &domain.Anchor{
+		Hash:        r.Commitment,
+		Algorithm:   r.Algorithm,
+		IssuerDID:   r.IssuerDID,
+		TxID:        r.TxID,
+		BlockNumber: r.BlockNumber,
+		Timestamp:   r.Timestamp,
+		Metadata:    r.Metadata,
+		ExpiresAt:   r.ExpiresAt,
+
+		MetadataHash:      r.MetadataHash,
+		SignatureVerified: r.SignatureVerified,
+
+		Revoked:          r.Revoked,
+		RevokedAt:        r.RevokedAt,
+		RevocationReason: r.RevocationReason,
+
+		Tombstoned:      r.Tombstoned,
+		TombstonedAt:    r.TombstonedAt,
+		TombstoneReason: r.TombstoneReason,
+	}
+}
+
+// isExpired reports whether the record has an expiry time at or before now.
+func (r Record) isExpired(now time.Time) bool {
+	return r.ExpiresAt != nil && !now.Before(*r.ExpiresAt)
 }
 
 // LedgerState represents the persisted state of the ledger
 type LedgerState struct {
+	Version   int               `json:"version"`
 	Records   map[string]Record `json:"records"` // Keyed by commitment/hash/DID
 	NextBlock uint64            `json:"nextBlock"`
+
+	// changed collects the keys written through put/remove while a batch is staged
+	changed map[string]struct{}
+}
+
+// put stores a record under key and marks the key as changed.
+func (s *LedgerState) put(key string, record Record) {
+	s.Records[key] = record
+	s.markChanged(key)
+}
+
+// remove deletes the record under key and marks the key as changed.
+func (s *LedgerState) remove(key string) {
+	delete(s.Records, key)
+	s.markChanged(key)
 }
 
+func (s *LedgerState) markChanged(key string) {
+	if s.changed == nil {
+		s.changed = make(map[string]struct{})
+	}
+	s.changed[key] = struct{}{}
+}
+
+// currentLedgerVersion is the on-disk format version written by this client.
+// Version history:
+//
+//	0: initial format (no version field, anchors without issuerDid)
+//	1: anchors carry issuerDid
+const currentLedgerVersion = 1
+
 // FileLedgerClient is a local file-based implementation of LedgerClient.
 // It uses atomic writes (write-tmp-sync-rename) to ensure data integrity.
+//
+// Mutations are staged and handed to a single writer goroutine, which applies them
+// to a copy of the state, persists the copy and only then publishes it to readers.
+// Concurrent writers are group-committed into one file write, and the mutex is only
+// held while swapping in the persisted state, never across disk I/O.
 type FileLedgerClient struct {
 	mu     sync.RWMutex
 	path   string
+	lock   *os.File // held until Close so one process at a time writes path; nil when unlocked
 	state  LedgerState
-	logger *log.Logger
+	index  *ledgerIndex // secondary indexes over state, guarded by mu
+	logger *slog.Logger
+
+	anchors *anchorBroadcaster
+	txIDs   txIDGenerator
+	pruned  uint64 // expired anchors removed by the reaper, guarded by mu
+
+	lastWrites     map[string]time.Time // last commit touching each docType, guarded by mu
+	lastCompaction time.Time            // last run of PruneExpired, guarded by mu
+	readOnly       *ReadOnlySwitch      // holds off the reaper while on; set before StartReaper
+
+	closed   bool           // guarded by mu
+	inflight sync.WaitGroup // writes accepted before Close
+
+	writes     chan *stagedWrite
+	stop       chan struct{}
+	writerDone chan struct{}
+}
+
+// maxGroupCommit bounds how many staged writes are persisted in one file write.
+const maxGroupCommit = 256
+
+// stagedWrite is a mutation waiting to be persisted by the writer goroutine.
+// apply mutates the staged copy of the state and reports whether anything changed;
+// it must validate before mutating so a failed apply leaves the copy untouched, and
+// write through LedgerState.put/remove so the indexes see the change.
+//
+// ctx carries the span of the write; the writer records the time it spent queued
+// and the marshal and persist phases of its commit as children of that span.
+type stagedWrite struct {
+	ctx    context.Context
+	apply  func(state *LedgerState) (bool, error)
+	done   chan struct{}
+	err    error
+	queued time.Time
+}
+
+// commitPhases are the times at which the phases of one group commit started and ended.
+// marshalled and persisted stay zero when nothing was written.
+type commitPhases struct {
+	started    time.Time
+	marshalled time.Time
+	persisted  time.Time
 }
 
 // NewFileLedgerClient creates a new client backed by a local JSON file.
 // It ensures the directory exists and loads existing state.
 func NewFileLedgerClient(path string) (*FileLedgerClient, error) {
+	return NewFileLedgerClientWithLogger(path, nil)
+}
+
+// NewFileLedgerClientWithLogger is NewFileLedgerClient logging to logger; nil uses slog.Default().
+func NewFileLedgerClientWithLogger(path string, logger *slog.Logger) (*FileLedgerClient, error) {
+	return newFileLedgerClient(path, logger, false)
+}
+
+// newFileLedgerClient creates the client of path. With lock set the client locks
+// the file until Close, failing with ErrLocked while another process has it open;
+// clients in one process, as in tests, may share the file unlocked.
+func newFileLedgerClient(path string, logger *slog.Logger, lock bool) (*FileLedgerClient, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
 	if path == "" {
 		path = "data/ledger.json"
 	}
@@ -50,21 +197,41 @@ func NewFileLedgerClient(path string) (*FileLedgerClient, error) {
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
+	// Locked beside the file, which is replaced by every write
+	var lockFile *os.File
+	if lock {
+		var err error
+		if lockFile, err = lockLedger(path + ".lock"); err != nil {
+			return nil, err
+		}
+	}
 
 	client := &FileLedgerClient{
-		path:   path,
-		logger: log.Default(),
+		path:    path,
+		lock:    lockFile,
+		logger:  logger,
+		anchors: newAnchorBroadcaster(),
 		state: LedgerState{
+			Version:   currentLedgerVersion,
 			Records:   make(map[string]Record),
 			NextBlock: 1,
 		},
+		writes:     make(chan *stagedWrite),
+		stop:       make(chan struct{}),
+		writerDone: make(chan struct{}),
 	}
 
 	if err := client.load(); err != nil {
+		if lockFile != nil {
+			lockFile.Close()
+		}
 		return nil, err
 	}
+	client.index = newLedgerIndex(client.state.Records)
 
-	client.logger.Printf("FileLedgerClient initialized at %s", path)
+	go client.runWriter()
+
+	client.logger.Info("FileLedgerClient initialized", "path", path)
 	return client, nil
 }
 
@@ -90,10 +257,13 @@ func (c *FileLedgerClient) load() error {
 		return nil // Start fresh
 	}
 
+	// Decode into a fresh state so a missing version field reads as 0
+	var state LedgerState
 	decoder := json.NewDecoder(f)
-	if err := decoder.Decode(&c.state); err != nil {
+	if err := decoder.Decode(&state); err != nil {
 		return fmt.Errorf("ledger file is corrupt: %w", err)
 	}
+	c.state = state
 
 	// Ensure map is initialized if nil in file
 	if c.state.Records == nil {
@@ -103,6 +273,22 @@ func (c *FileLedgerClient) load() error {
 		c.state.NextBlock = 1
 	}
 
+	return c.migrate()
+}
+
+// migrate upgrades state loaded from an older file format in memory.
+// The upgraded format is written back with the next persisted write.
+func (c *FileLedgerClient) migrate() error {
+	if c.state.Version > currentLedgerVersion {
+		return fmt.Errorf("ledger file version %d is newer than supported version %d", c.state.Version, currentLedgerVersion)
+	}
+
+	if c.state.Version < 1 {
+		// v0 files never stored the issuer, so it stays empty for existing anchors
+		c.logger.Info("Migrating ledger file", "path", c.path, "from_version", c.state.Version, "to_version", 1)
+		c.state.Version = 1
+	}
+
 	return nil
 }
 
@@ -136,83 +322,579 @@ func saveAtomic(stateBytes []byte, path string) error {
 	return nil
 }
 
+// runWriter is the single goroutine that persists staged writes.
+func (c *FileLedgerClient) runWriter() {
+	defer close(c.writerDone)
+
+	for {
+		var batch []*stagedWrite
+		select {
+		case w := <-c.writes:
+			batch = append(batch, w)
+		case <-c.stop:
+			return
+		}
+
+		// Group commit: pick up every writer that is already waiting
+	drain:
+		for len(batch) < maxGroupCommit {
+			select {
+			case w := <-c.writes:
+				batch = append(batch, w)
+			default:
+				break drain
+			}
+		}
+
+		c.commit(batch)
+	}
+}
+
+// commit applies a batch of staged writes to a copy of the state, persists it and
+// publishes it to readers together with the updated indexes. If persistence fails, every write in the batch fails and
+// the published state is left unchanged, so readers never observe unpersisted records.
+func (c *FileLedgerClient) commit(batch []*stagedWrite) {
+	// Only the writer goroutine replaces c.state, so reading it here needs no lock
+	staged := LedgerState{
+		Version:   c.state.Version,
+		Records:   make(map[string]Record, len(c.state.Records)+len(batch)),
+		NextBlock: c.state.NextBlock,
+	}
+	for k, v := range c.state.Records {
+		staged.Records[k] = v
+	}
+
+	phases := commitPhases{started: time.Now()}
+	changed := false
+	for _, w := range batch {
+		if err := w.ctx.Err(); err != nil {
+			w.err = err
+			continue
+		}
+		ok, err := w.apply(&staged)
+		w.err = err
+		changed = changed || ok
+	}
+
+	if changed {
+		if err := c.persist(staged, &phases); err != nil {
+			for _, w := range batch {
+				if w.err == nil {
+					w.err = err
+				}
+			}
+		} else {
+			c.mu.Lock()
+			c.noteWrites(staged.Records, staged.changed)
+			c.index.update(c.state.Records, staged.Records, staged.changed)
+			staged.changed = nil
+			c.state = staged
+			c.mu.Unlock()
+		}
+	}
+
+	for _, w := range batch {
+		w.trace(phases)
+		close(w.done)
+	}
+}
+
+// noteWrites records the docTypes of the changed keys as written now. Removed
+// records are looked up in the state being replaced. Callers hold c.mu.
+func (c *FileLedgerClient) noteWrites(records map[string]Record, changed map[string]struct{}) {
+	now := time.Now().UTC()
+	for key := range changed {
+		record, ok := records[key]
+		if !ok {
+			record = c.state.Records[key]
+		}
+		if record.DocType == "" {
+			continue
+		}
+		if c.lastWrites == nil {
+			c.lastWrites = make(map[string]time.Time)
+		}
+		c.lastWrites[record.DocType] = now
+	}
+}
+
+// trace records the phases of the commit that handled w as children of its span:
+// waiting for the writer (the ledger's lock), marshalling and persisting the state.
+// Group-committed writes share the marshal and persist phases.
+func (w *stagedWrite) trace(phases commitPhases) {
+	if !trace.SpanFromContext(w.ctx).IsRecording() {
+		return
+	}
+	record := func(name string, start, end time.Time) {
+		_, span := tracing.Tracer().Start(w.ctx, name, trace.WithTimestamp(start))
+		span.End(trace.WithTimestamp(end))
+	}
+	record("ledger.lock_wait", w.queued, phases.started)
+	if !phases.marshalled.IsZero() {
+		record("ledger.marshal", phases.started, phases.marshalled)
+	}
+	if !phases.persisted.IsZero() {
+		record("ledger.persist", phases.marshalled, phases.persisted)
+	}
+}
+
+// persist marshals and atomically writes the given state, noting when each phase ends.
+func (c *FileLedgerClient) persist(state LedgerState, phases *commitPhases) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ledger state: %w", err)
+	}
+	phases.marshalled = time.Now()
+
+	if err := saveAtomic(data, c.path); err != nil {
+		return fmt.Errorf("%w: %w", ErrTransient, err)
+	}
+	phases.persisted = time.Now()
+	return nil
+}
+
+// submit hands a mutation to the writer and waits until it is durable (or failed).
+// A successful return implies the change has been fsynced to disk. The wait is
+// traced as a ledger.<op> span of ctx, and ends early with ctx's error when ctx is done.
+func (c *FileLedgerClient) submit(ctx context.Context, op string, apply func(state *LedgerState) (bool, error)) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "ledger."+op)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return ErrClientClosed
+	}
+	c.inflight.Add(1)
+	c.mu.RUnlock()
+	defer c.inflight.Done()
+
+	// The writer keeps running until every in-flight write has been handed over
+	w := &stagedWrite{ctx: ctx, apply: apply, done: make(chan struct{}), queued: time.Now()}
+	select {
+	case c.writes <- w:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// The writer skips the write if ctx is done before its turn; once applied it
+	// is persisted with its group, and giving up leaves its outcome unknown
+	select {
+	case <-w.done:
+		return w.err
+	case <-ctx.Done():
+		return fmt.Errorf("%w; the write may still be committed", ctx.Err())
+	}
+}
+
 func (c *FileLedgerClient) CreateAnchor(ctx context.Context, anchor *domain.Anchor) (string, uint64, error) {
 	if err := ctx.Err(); err != nil {
 		return "", 0, fmt.Errorf("context cancelled: %w", err)
 	}
 
-	c.mu.Lock()
-
-	// Idempotency check
-	if record, exists := c.state.Records[anchor.Hash]; exists {
-		c.mu.Unlock()
-		return record.TxID, record.BlockNumber, nil
+	// Idempotency fast path: already committed records need no write.
+	// Expired anchors are replaced rather than returned.
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return "", 0, ErrClientClosed
 	}
+	record, exists := c.state.Records[anchor.Hash]
+	c.mu.RUnlock()
+	exists = exists && !record.isExpired(time.Now())
 
-	now := time.Now().UTC()
-	txID := fmt.Sprintf("tx-%d", now.UnixNano())
-	blockNum := c.state.NextBlock
+	if !exists {
+		created := false
+		err := c.submit(ctx, "CreateAnchor", func(state *LedgerState) (bool, error) {
+			// Re-check against the staged state; a concurrent writer may have won
+			now := time.Now().UTC()
+			if existing, ok := state.Records[anchor.Hash]; ok && !existing.isExpired(now) {
+				record = existing
+				return false, nil
+			}
 
-	// Update domain object to match returned data
-	anchor.TxID = txID
-	anchor.BlockNumber = blockNum
-	anchor.Timestamp = now
+			record = Record{
+				Commitment:  anchor.Hash,
+				Algorithm:   anchor.Algorithm,
+				IssuerDID:   anchor.IssuerDID,
+				TxID:        c.txIDs.next(),
+				BlockNumber: state.NextBlock,
+				Timestamp:   now,
+				Metadata:    anchor.Metadata,
+				DocType:     "anchor",
+				ExpiresAt:   anchor.ExpiresAt,
+
+				MetadataHash:      anchor.MetadataHash,
+				SignatureVerified: anchor.SignatureVerified,
+			}
+			state.put(anchor.Hash, record)
+			state.NextBlock++
+			created = true
+			return true, nil
+		})
+		if errors.Is(err, ErrClientClosed) {
+			return "", 0, err
+		}
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to persist anchor: %w", err)
+		}
 
-	record := Record{
-		Commitment:  anchor.Hash,
-		TxID:        txID,
-		BlockNumber: blockNum,
-		Timestamp:   now,
-		Metadata:    anchor.Metadata,
-		DocType:     "anchor",
+		if created {
+			anchor.TxID = record.TxID
+			anchor.BlockNumber = record.BlockNumber
+			anchor.Timestamp = record.Timestamp
+			c.anchors.publish(*anchor)
+			c.logger.Info("Anchor created", "hash", anchor.Hash, "block", record.BlockNumber)
+			return record.TxID, record.BlockNumber, nil
+		}
 	}
 
-	c.state.Records[anchor.Hash] = record
-	c.state.NextBlock++
+	// Update domain object to match returned data
+	anchor.TxID = record.TxID
+	anchor.BlockNumber = record.BlockNumber
+	anchor.Timestamp = record.Timestamp
+	anchor.IssuerDID = record.IssuerDID
+	anchor.ExpiresAt = record.ExpiresAt
+	return record.TxID, record.BlockNumber, nil
+}
+
+// CreateAnchors creates a batch of anchors with a single file write.
+// Every anchor gets its own block number; anchors that already exist are reported
+// as such and left untouched. If the write fails, no anchor in the batch is created.
+func (c *FileLedgerClient) CreateAnchors(ctx context.Context, anchors []*domain.Anchor) ([]AnchorResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context cancelled: %w", err)
+	}
 
-	// Marshal state
-	data, err := json.MarshalIndent(c.state, "", "  ")
+	results, err := validateAnchorBatch(anchors)
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to marshal ledger state: %w", err)
+		return nil, err
 	}
 
-	// Persist atomically (must hold lock to ensure sequential writes and avoid file contention)
-	if err := saveAtomic(data, c.path); err != nil {
-		c.mu.Unlock()
-		return "", 0, fmt.Errorf("failed to persist anchor: %w", err)
+	records := make([]Record, len(anchors))
+	err = c.submit(ctx, "CreateAnchors", func(state *LedgerState) (bool, error) {
+		now := time.Now().UTC()
+		changed := false
+		for i, anchor := range anchors {
+			if results[i].Status == AnchorFailed {
+				continue
+			}
+
+			if existing, ok := state.Records[anchor.Hash]; ok && !existing.isExpired(now) {
+				records[i] = existing
+				results[i].Status = AnchorExisted
+				continue
+			}
+
+			records[i] = Record{
+				Commitment:  anchor.Hash,
+				Algorithm:   anchor.Algorithm,
+				IssuerDID:   anchor.IssuerDID,
+				TxID:        c.txIDs.next(),
+				BlockNumber: state.NextBlock,
+				Timestamp:   now,
+				Metadata:    anchor.Metadata,
+				DocType:     "anchor",
+				ExpiresAt:   anchor.ExpiresAt,
+
+				MetadataHash:      anchor.MetadataHash,
+				SignatureVerified: anchor.SignatureVerified,
+			}
+			state.put(anchor.Hash, records[i])
+			state.NextBlock++
+			results[i].Status = AnchorCreated
+			changed = true
+		}
+		return changed, nil
+	})
+	if errors.Is(err, ErrClientClosed) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to persist anchor batch: %w", err)
 	}
 
-	c.mu.Unlock()
+	created := 0
+	for i, anchor := range anchors {
+		if results[i].Status == AnchorFailed {
+			continue
+		}
+		record := records[i]
+		results[i].TxID = record.TxID
+		results[i].BlockNumber = record.BlockNumber
+
+		anchor.TxID = record.TxID
+		anchor.BlockNumber = record.BlockNumber
+		anchor.Timestamp = record.Timestamp
+		if results[i].Status == AnchorCreated {
+			c.anchors.publish(*anchor)
+			created++
+		} else {
+			anchor.IssuerDID = record.IssuerDID
+			anchor.ExpiresAt = record.ExpiresAt
+		}
+	}
 
-	c.logger.Printf("Anchor created: %s (block: %d)", anchor.Hash, blockNum)
-	return txID, blockNum, nil
+	c.logger.Info("Anchor batch committed", "created", created, "submitted", len(anchors))
+	return results, nil
 }
 
 func (c *FileLedgerClient) GetAnchor(ctx context.Context, hash string) (*domain.Anchor, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	if c.closed {
+		return nil, ErrClientClosed
+	}
+
 	record, exists := c.state.Records[hash]
 	if !exists || record.DocType != "anchor" {
-		return nil, fmt.Errorf("anchor not found: %s", hash)
+		return nil, fmt.Errorf("anchor %w: %s", ErrNotFound, hash)
+	}
+	if record.isExpired(time.Now()) {
+		return nil, fmt.Errorf("anchor %w: %s", ErrExpired, hash)
 	}
 
-	return &domain.Anchor{
-		Hash:        record.Commitment,
-		TxID:        record.TxID,
-		BlockNumber: record.BlockNumber,
-		Timestamp:   record.Timestamp,
-		Metadata:    record.Metadata,
-		IssuerDID:   "",
-	}, nil
+	return record.toAnchor(), nil
+}
+
+// GetAnchorByTxID looks up an anchor by the transaction ID returned from CreateAnchor.
+func (c *FileLedgerClient) GetAnchorByTxID(ctx context.Context, txID string) (*domain.Anchor, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, ErrClientClosed
+	}
+
+	hash, exists := c.index.byTxID[txID]
+	if !exists {
+		return nil, fmt.Errorf("anchor %w for transaction: %s", ErrNotFound, txID)
+	}
+	record := c.state.Records[hash]
+	if record.isExpired(time.Now()) {
+		return nil, fmt.Errorf("anchor %w: %s", ErrExpired, hash)
+	}
+	return record.toAnchor(), nil
 }
 
 func (c *FileLedgerClient) VerifyAnchor(ctx context.Context, hash string) bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	if c.closed {
+		return false
+	}
+
 	record, exists := c.state.Records[hash]
-	return exists && record.DocType == "anchor"
+	return exists && record.DocType == "anchor" && !record.isExpired(time.Now())
+}
+
+// VerifyAnchors looks up all hashes under a single read lock, so the result is one consistent snapshot.
+func (c *FileLedgerClient) VerifyAnchors(ctx context.Context, hashes []string) (map[string]AnchorVerification, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, ErrClientClosed
+	}
+
+	now := time.Now()
+	results := make(map[string]AnchorVerification, len(hashes))
+	for _, hash := range hashes {
+		var anchor *domain.Anchor
+		if record, exists := c.state.Records[hash]; exists && record.DocType == "anchor" {
+			anchor = record.toAnchor()
+		}
+		results[hash] = verificationOf(anchor, now)
+	}
+	return results, nil
+}
+
+// ListAnchors returns copies of the live (non-expired) anchors ordered by block number.
+func (c *FileLedgerClient) ListAnchors(ctx context.Context, opts ListOptions) (*AnchorPage, error) {
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return nil, ErrClientClosed
+	}
+
+	now := time.Now()
+	anchors := make([]domain.Anchor, 0, len(c.index.byBlock))
+	for _, entry := range c.index.byBlock {
+		if record := c.state.Records[entry.hash]; !record.isExpired(now) {
+			anchors = append(anchors, *record.toAnchor())
+		}
+	}
+	c.mu.RUnlock()
+
+	return pageAnchors(anchors, opts)
+}
+
+// QueryAnchors returns copies of the live anchors matching filter, ordered by block number.
+// The block range is resolved against the block index; the time range is checked per anchor.
+func (c *FileLedgerClient) QueryAnchors(ctx context.Context, filter AnchorFilter) ([]domain.Anchor, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, ErrClientClosed
+	}
+
+	now := time.Now()
+	anchors := []domain.Anchor{}
+	for _, entry := range c.index.blockRange(filter.FromBlock, filter.ToBlock) {
+		record := c.state.Records[entry.hash]
+		if record.isExpired(now) || !filter.matchesTime(record.Timestamp) {
+			continue
+		}
+		anchors = append(anchors, *record.toAnchor())
+	}
+	return anchors, nil
+}
+
+// GetAnchorsByIssuer returns copies of the live anchors created by issuerDID, ordered by block number.
+func (c *FileLedgerClient) GetAnchorsByIssuer(ctx context.Context, issuerDID string, opts ListOptions) (*AnchorPage, error) {
+	if issuerDID == "" {
+		return nil, fmt.Errorf("issuer DID is required: %w", ErrValidation)
+	}
+
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return nil, ErrClientClosed
+	}
+
+	now := time.Now()
+	entries := c.index.byIssuer[issuerDID]
+	anchors := make([]domain.Anchor, 0, len(entries))
+	for _, entry := range entries {
+		if record := c.state.Records[entry.hash]; !record.isExpired(now) {
+			anchors = append(anchors, *record.toAnchor())
+		}
+	}
+	c.mu.RUnlock()
+
+	return pageAnchors(anchors, opts)
+}
+
+// FindAnchorsByPrefix scans the range of the sorted hash index that starts with prefix.
+func (c *FileLedgerClient) FindAnchorsByPrefix(ctx context.Context, prefix string, limit int) ([]domain.Anchor, error) {
+	if err := validatePrefixQuery(prefix, limit); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return nil, ErrClientClosed
+	}
+	now := time.Now()
+	anchors := make([]domain.Anchor, 0)
+	for _, hash := range c.index.prefixRange(prefix) {
+		if record := c.state.Records[hash]; !record.isExpired(now) {
+			anchors = append(anchors, *record.toAnchor())
+		}
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(anchors, func(i, j int) bool {
+		return anchors[i].BlockNumber < anchors[j].BlockNumber
+	})
+	if len(anchors) > limit {
+		anchors = anchors[:limit]
+	}
+	return anchors, nil
+}
+
+// TombstoneAnchor erases the metadata and issuer of an anchor while keeping its hash,
+// txID and block number. The erased values are gone from the file once this returns.
+// Tombstoning an already tombstoned anchor is a no-op.
+func (c *FileLedgerClient) TombstoneAnchor(ctx context.Context, hash, reason string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+	if reason == "" {
+		return fmt.Errorf("tombstone reason is required: %w", ErrValidation)
+	}
+
+	err := c.submit(ctx, "TombstoneAnchor", func(state *LedgerState) (bool, error) {
+		record, exists := state.Records[hash]
+		if !exists || record.DocType != "anchor" {
+			return false, fmt.Errorf("anchor %w: %s", ErrNotFound, hash)
+		}
+		if record.Tombstoned {
+			return false, nil
+		}
+
+		now := time.Now().UTC()
+		record.Metadata = nil
+		record.MetadataHash = ""
+		record.IssuerDID = ""
+		record.Tombstoned = true
+		record.TombstonedAt = &now
+		record.TombstoneReason = reason
+		state.put(hash, record)
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("Anchor tombstoned", "hash", hash, "reason", reason)
+	return nil
+}
+
+// RevokeAnchor marks an anchor as revoked. The reason is optional.
+// Revoking an already revoked anchor is a no-op.
+func (c *FileLedgerClient) RevokeAnchor(ctx context.Context, hash, reason string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	err := c.submit(ctx, "RevokeAnchor", func(state *LedgerState) (bool, error) {
+		record, exists := state.Records[hash]
+		if !exists || record.DocType != "anchor" {
+			return false, fmt.Errorf("anchor %w: %s", ErrNotFound, hash)
+		}
+		if record.Revoked {
+			return false, nil
+		}
+
+		now := time.Now().UTC()
+		record.Revoked = true
+		record.RevokedAt = &now
+		record.RevocationReason = reason
+		state.put(hash, record)
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("Anchor revoked", "hash", hash)
+	return nil
+}
+
+// SubscribeAnchors emulates Fabric block events by streaming anchors created through this client.
+func (c *FileLedgerClient) SubscribeAnchors(ctx context.Context) (<-chan domain.Anchor, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, ErrClientClosed
+	}
+	return c.anchors.subscribe(ctx), nil
 }
 
 func (c *FileLedgerClient) CreateDid(ctx context.Context, didDoc *domain.DIDDocument) error {
@@ -220,49 +902,164 @@ func (c *FileLedgerClient) CreateDid(ctx context.Context, didDoc *domain.DIDDocu
 		return fmt.Errorf("context cancelled: %w", err)
 	}
 
-	c.mu.Lock()
-	if _, exists := c.state.Records[didDoc.ID]; exists {
-		c.mu.Unlock()
-		return fmt.Errorf("DID already exists: %s", didDoc.ID)
+	var now time.Time
+	err := c.submit(ctx, "CreateDid", func(state *LedgerState) (bool, error) {
+		if _, exists := state.Records[didDoc.ID]; exists {
+			return false, fmt.Errorf("DID %w: %s", ErrAlreadyExists, didDoc.ID)
+		}
+
+		now = time.Now().UTC()
+		doc := *didDoc
+		doc.Created = now
+		doc.Updated = now
+		doc.VersionID = 1
+		doc.Deactivated = false
+		doc.DeactivatedAt = nil
+
+		state.put(didDoc.ID, Record{
+			Commitment: didDoc.ID,
+			Timestamp:  now,
+			DocType:    "did",
+			DIDDoc:     &doc,
+		})
+		return true, nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrAlreadyExists) || errors.Is(err, ErrClientClosed) {
+			return err
+		}
+		return fmt.Errorf("failed to persist DID: %w", err)
 	}
 
-	now := time.Now().UTC()
 	didDoc.Created = now
 	didDoc.Updated = now
+	didDoc.VersionID = 1
+
+	c.logger.Info("DID created", "did", didDoc.ID)
+	return nil
+}
 
-	record := Record{
-		Commitment: didDoc.ID,
-		Timestamp:  now,
-		DocType:    "did",
-		DIDDoc:     didDoc,
+// UpdateDid replaces the document of an existing DID, keeping its original Created time.
+// It returns an ErrNotFound error if the DID has not been created, and an
+// ErrAlreadyExists error if it is no longer at expectedVersion.
+func (c *FileLedgerClient) UpdateDid(ctx context.Context, didDoc *domain.DIDDocument, expectedVersion uint64) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
 	}
 
-	c.state.Records[didDoc.ID] = record
+	var created, now time.Time
+	var version uint64
+	err := c.submit(ctx, "UpdateDid", func(state *LedgerState) (bool, error) {
+		record, exists := state.Records[didDoc.ID]
+		if !exists || record.DocType != "did" {
+			return false, fmt.Errorf("DID %w: %s", ErrNotFound, didDoc.ID)
+		}
+		if record.DIDDoc.Deactivated {
+			return false, fmt.Errorf("DID %w: %s", ErrDeactivated, didDoc.ID)
+		}
+		if err := checkVersion(record.DIDDoc, expectedVersion); err != nil {
+			return false, err
+		}
+
+		now = time.Now().UTC()
+		created = record.DIDDoc.Created
+		version = nextVersion(record.DIDDoc)
+		doc := *didDoc
+		doc.Created = created
+		doc.Updated = now
+		doc.VersionID = version
+		doc.Deactivated = false // only DeactivateDid may deactivate
+		doc.DeactivatedAt = nil
 
-	data, err := json.MarshalIndent(c.state, "", "  ")
+		record.DIDDoc = &doc
+		record.Timestamp = now
+		state.put(didDoc.ID, record)
+		return true, nil
+	})
 	if err != nil {
-		c.mu.Unlock()
-		return fmt.Errorf("failed to marshal ledger state: %w", err)
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrDeactivated) || errors.Is(err, ErrAlreadyExists) || errors.Is(err, ErrClientClosed) {
+			return err
+		}
+		return fmt.Errorf("failed to persist DID: %w", err)
 	}
 
-	if err := saveAtomic(data, c.path); err != nil {
-		c.mu.Unlock()
+	didDoc.Created = created
+	didDoc.Updated = now
+	didDoc.VersionID = version
+
+	c.logger.Info("DID updated", "did", didDoc.ID)
+	return nil
+}
+
+// DeactivateDid marks a DID as deactivated. The document keeps resolving, but it can no
+// longer be updated. Deactivating twice returns an ErrDeactivated error, and a DID no
+// longer at expectedVersion an ErrAlreadyExists error.
+func (c *FileLedgerClient) DeactivateDid(ctx context.Context, did string, expectedVersion uint64) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	err := c.submit(ctx, "DeactivateDid", func(state *LedgerState) (bool, error) {
+		record, exists := state.Records[did]
+		if !exists || record.DocType != "did" {
+			return false, fmt.Errorf("DID %w: %s", ErrNotFound, did)
+		}
+		if record.DIDDoc.Deactivated {
+			return false, fmt.Errorf("DID already %w: %s", ErrDeactivated, did)
+		}
+		if err := checkVersion(record.DIDDoc, expectedVersion); err != nil {
+			return false, err
+		}
+
+		now := time.Now().UTC()
+		doc := *record.DIDDoc
+		doc.Deactivated = true
+		doc.DeactivatedAt = &now
+		doc.Updated = now
+		doc.VersionID = nextVersion(record.DIDDoc)
+
+		record.DIDDoc = &doc
+		record.Timestamp = now
+		state.put(did, record)
+		return true, nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrDeactivated) || errors.Is(err, ErrAlreadyExists) || errors.Is(err, ErrClientClosed) {
+			return err
+		}
 		return fmt.Errorf("failed to persist DID: %w", err)
 	}
 
-	c.mu.Unlock()
+	c.logger.Info("DID deactivated", "did", did)
+	return nil
+}
 
-	c.logger.Printf("DID created: %s", didDoc.ID)
+// nextVersion returns the versionId for the next change to doc.
+// Documents stored before versioning count as version 1.
+// checkVersion fails with ErrAlreadyExists when doc has moved past expected, the
+// version a change was authorized against: a newer version was written meanwhile.
+func checkVersion(doc *domain.DIDDocument, expected uint64) error {
+	if doc.VersionID != expected {
+		return fmt.Errorf("DID %s version %d %w; the change was made against version %d", doc.ID, doc.VersionID, ErrAlreadyExists, expected)
+	}
 	return nil
 }
 
+func nextVersion(doc *domain.DIDDocument) uint64 {
+	return max(doc.VersionID, 1) + 1
+}
+
 func (c *FileLedgerClient) GetDid(ctx context.Context, did string) (*domain.DIDDocument, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	if c.closed {
+		return nil, ErrClientClosed
+	}
+
 	record, exists := c.state.Records[did]
 	if !exists || record.DocType != "did" {
-		return nil, fmt.Errorf("DID not found: %s", did)
+		return nil, fmt.Errorf("DID %w: %s", ErrNotFound, did)
 	}
 
 	// Return copy
@@ -270,29 +1067,314 @@ func (c *FileLedgerClient) GetDid(ctx context.Context, did string) (*domain.DIDD
 	return &doc, nil
 }
 
-func (c *FileLedgerClient) GetStats() map[string]interface{} {
+// ListDids returns copies of the DID documents ordered by creation time,
+// optionally restricted to a single controller.
+func (c *FileLedgerClient) ListDids(ctx context.Context, opts DidListOptions) (*DidPage, error) {
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return nil, ErrClientClosed
+	}
+
+	docs := make([]domain.DIDDocument, 0)
+	for _, record := range c.state.Records {
+		if record.DocType != "did" || record.DIDDoc == nil {
+			continue
+		}
+		if opts.Controller != "" && record.DIDDoc.Controller != opts.Controller {
+			continue
+		}
+		docs = append(docs, *record.DIDDoc)
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(docs, func(i, j int) bool {
+		return didBefore(&docs[i], &docs[j])
+	})
+
+	return pageDids(docs, opts)
+}
+
+// statusListKey is the record key of a status list, kept apart from anchor hashes and DIDs.
+func statusListKey(id string) string {
+	return "statuslist:" + id
+}
+
+func (c *FileLedgerClient) GetStatusList(ctx context.Context, id string) (*domain.StatusList, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	anchors := 0
-	dids := 0
+	if c.closed {
+		return nil, ErrClientClosed
+	}
+
+	record, exists := c.state.Records[statusListKey(id)]
+	if !exists || record.DocType != "statusList" {
+		return nil, fmt.Errorf("status list %w: %s", ErrNotFound, id)
+	}
+
+	list := *record.StatusList
+	return &list, nil
+}
+
+// SaveStatusList creates or replaces a status list. Callers serialize their
+// read-modify-write cycles; the last save wins.
+func (c *FileLedgerClient) SaveStatusList(ctx context.Context, list *domain.StatusList) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+	if list.ID == "" {
+		return fmt.Errorf("status list id is required: %w", ErrValidation)
+	}
+
+	saved := *list
+	err := c.submit(ctx, "SaveStatusList", func(state *LedgerState) (bool, error) {
+		key := statusListKey(saved.ID)
+		state.put(key, Record{
+			Commitment: key,
+			Timestamp:  time.Now().UTC(),
+			DocType:    "statusList",
+			StatusList: &saved,
+		})
+		return true, nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrClientClosed) {
+			return err
+		}
+		return fmt.Errorf("failed to persist status list: %w", err)
+	}
+	return nil
+}
+
+// webhookKey is the record key of a webhook registration.
+func webhookKey(id string) string {
+	return "webhook:" + id
+}
+
+// SaveWebhook creates or replaces a webhook registration.
+func (c *FileLedgerClient) SaveWebhook(ctx context.Context, hook *domain.Webhook) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+	if hook.ID == "" {
+		return fmt.Errorf("webhook id is required: %w", ErrValidation)
+	}
+
+	saved := *hook
+	err := c.submit(ctx, "SaveWebhook", func(state *LedgerState) (bool, error) {
+		key := webhookKey(saved.ID)
+		state.put(key, Record{
+			Commitment: key,
+			Timestamp:  time.Now().UTC(),
+			DocType:    "webhook",
+			Webhook:    &saved,
+		})
+		return true, nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrClientClosed) {
+			return err
+		}
+		return fmt.Errorf("failed to persist webhook: %w", err)
+	}
+	return nil
+}
+
+func (c *FileLedgerClient) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, ErrClientClosed
+	}
+
+	var hooks []domain.Webhook
+	for _, record := range c.state.Records {
+		if record.DocType == "webhook" && record.Webhook != nil {
+			hooks = append(hooks, *record.Webhook)
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool {
+		if !hooks[i].Created.Equal(hooks[j].Created) {
+			return hooks[i].Created.Before(hooks[j].Created)
+		}
+		return hooks[i].ID < hooks[j].ID
+	})
+	return hooks, nil
+}
+
+func (c *FileLedgerClient) DeleteWebhook(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	err := c.submit(ctx, "DeleteWebhook", func(state *LedgerState) (bool, error) {
+		key := webhookKey(id)
+		if record, exists := state.Records[key]; !exists || record.DocType != "webhook" {
+			return false, fmt.Errorf("webhook %w: %s", ErrNotFound, id)
+		}
+		state.remove(key)
+		return true, nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrClientClosed) {
+			return err
+		}
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+func (c *FileLedgerClient) GetStats() Stats {
+	c.mu.RLock()
+	stats := Stats{
+		NextBlock: c.state.NextBlock,
+		Mode:      "file-persistent",
+		Path:      c.path,
+		DocTypes:  make(map[string]int),
+		Pruned:    c.pruned,
+	}
 	for _, r := range c.state.Records {
-		if r.DocType == "anchor" {
-			anchors++
-		} else if r.DocType == "did" {
-			dids++
+		stats.DocTypes[r.DocType]++
+	}
+	if len(c.lastWrites) > 0 {
+		stats.LastWrites = maps.Clone(c.lastWrites)
+	}
+	if !c.lastCompaction.IsZero() {
+		lastCompaction := c.lastCompaction
+		stats.LastCompaction = &lastCompaction
+	}
+	c.mu.RUnlock()
+
+	stats.Anchors = stats.DocTypes["anchor"]
+	stats.DIDs = stats.DocTypes["did"]
+
+	if info, err := os.Stat(c.path); err == nil {
+		modTime := info.ModTime().UTC()
+		stats.FileSizeBytes = info.Size()
+		stats.LastWriteTime = &modTime
+	}
+
+	return stats
+}
+
+// Ping checks that the ledger can still be persisted: the client is open, the
+// ledger file can be stat'ed and a file written to its directory reads back and
+// can be removed, as every write renames a temporary file into place.
+func (c *FileLedgerClient) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+	c.mu.RLock()
+	closed := c.closed
+	c.mu.RUnlock()
+	if closed {
+		return ErrClientClosed
+	}
+
+	if _, err := os.Stat(c.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: ledger file: %w", ErrTransient, err)
+	}
+	probe, err := os.CreateTemp(filepath.Dir(c.path), ".ping-*")
+	if err != nil {
+		return fmt.Errorf("%w: ledger directory not writable: %w", ErrTransient, err)
+	}
+	name := probe.Name()
+	_, err = probe.WriteString(name)
+	if closeErr := probe.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		var data []byte
+		if data, err = os.ReadFile(name); err == nil && string(data) != name {
+			err = errors.New("probe file read back differently")
 		}
 	}
+	if removeErr := os.Remove(name); removeErr != nil {
+		return fmt.Errorf("%w: ledger directory: %w", ErrTransient, removeErr)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: ledger directory: %w", ErrTransient, err)
+	}
+	return nil
+}
+
+// StartReaper prunes expired anchors every interval until the client is closed,
+// skipping intervals in read-only mode. A non-positive interval disables the reaper.
+func (c *FileLedgerClient) StartReaper(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if c.readOnly.On() {
+					continue
+				}
+				if n, err := c.PruneExpired(); err != nil {
+					c.logger.Warn("Failed to prune expired anchors", "err", err)
+				} else if n > 0 {
+					c.logger.Info("Pruned expired anchors", "count", n)
+				}
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+}
 
-	return map[string]interface{}{
-		"anchors":   anchors,
-		"dids":      dids,
-		"nextBlock": c.state.NextBlock,
-		"mode":      "file-persistent",
-		"path":      c.path,
+// PruneExpired removes all expired anchors from the ledger and returns how many were removed.
+func (c *FileLedgerClient) PruneExpired() (int, error) {
+	removed := 0
+	err := c.submit(context.Background(), "PruneExpired", func(state *LedgerState) (bool, error) {
+		now := time.Now()
+		removed = 0
+		for key, record := range state.Records {
+			if record.DocType == "anchor" && record.isExpired(now) {
+				state.remove(key)
+				removed++
+			}
+		}
+		return removed > 0, nil
+	})
+	if err != nil {
+		return 0, err
 	}
+
+	c.mu.Lock()
+	c.pruned += uint64(removed)
+	c.lastCompaction = time.Now().UTC()
+	c.mu.Unlock()
+
+	return removed, nil
 }
 
+// Close rejects new operations with ErrClientClosed, waits until writes accepted
+// before the call are persisted, then stops the writer, the reaper and all anchor
+// subscriptions. Calling Close more than once is a no-op.
 func (c *FileLedgerClient) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	c.inflight.Wait()
+	close(c.stop)
+	<-c.writerDone
+	c.anchors.closeAll()
+	if c.lock != nil {
+		c.lock.Close()
+	}
+
+	c.logger.Info("FileLedgerClient closed", "path", c.path)
 	return nil
 }
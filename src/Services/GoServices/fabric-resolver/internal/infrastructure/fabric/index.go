@@ -0,0 +1,149 @@
+package fabric
+
+import (
+	"sort"
+	"strings"
+)
+
+// indexEntry points at an anchor record by hash, remembering its block number for ordering.
+type indexEntry struct {
+	block uint64
+	hash  string
+}
+
+// ledgerIndex holds secondary in-memory indexes over the published ledger state.
+// It is built when the ledger is loaded and kept up to date by the writer goroutine
+// as it publishes each committed batch, so it is guarded by the client's mu.
+type ledgerIndex struct {
+	byBlock  []indexEntry            // all anchors ordered by block number
+	byHash   []string                // all anchor hashes in lexical order, for prefix scans
+	byIssuer map[string][]indexEntry // issuer DID -> anchors ordered by block number
+	byTxID   map[string]string       // transaction ID -> anchor hash
+}
+
+// newLedgerIndex builds the indexes from scratch for the given records.
+func newLedgerIndex(records map[string]Record) *ledgerIndex {
+	ix := &ledgerIndex{
+		byIssuer: make(map[string][]indexEntry),
+		byTxID:   make(map[string]string),
+	}
+	for _, record := range records {
+		ix.add(record)
+	}
+	return ix
+}
+
+// add indexes an anchor record. Anchors without an issuer are left out of byIssuer.
+func (ix *ledgerIndex) add(record Record) {
+	if record.DocType != "anchor" {
+		return
+	}
+
+	entry := indexEntry{block: record.BlockNumber, hash: record.Commitment}
+	ix.byBlock = insertEntry(ix.byBlock, entry)
+	ix.byHash = insertHash(ix.byHash, record.Commitment)
+	if record.TxID != "" {
+		ix.byTxID[record.TxID] = record.Commitment
+	}
+	if record.IssuerDID != "" {
+		ix.byIssuer[record.IssuerDID] = insertEntry(ix.byIssuer[record.IssuerDID], entry)
+	}
+}
+
+// remove drops an anchor record from the indexes.
+func (ix *ledgerIndex) remove(record Record) {
+	if record.DocType != "anchor" {
+		return
+	}
+
+	entry := indexEntry{block: record.BlockNumber, hash: record.Commitment}
+	ix.byBlock = removeEntry(ix.byBlock, entry)
+	ix.byHash = removeHash(ix.byHash, record.Commitment)
+	if ix.byTxID[record.TxID] == record.Commitment {
+		delete(ix.byTxID, record.TxID)
+	}
+	if record.IssuerDID != "" {
+		if entries := removeEntry(ix.byIssuer[record.IssuerDID], entry); len(entries) > 0 {
+			ix.byIssuer[record.IssuerDID] = entries
+		} else {
+			delete(ix.byIssuer, record.IssuerDID)
+		}
+	}
+}
+
+// update reconciles the indexes with the keys that changed between the old and new records.
+func (ix *ledgerIndex) update(old, new map[string]Record, changed map[string]struct{}) {
+	for key := range changed {
+		if record, ok := old[key]; ok {
+			ix.remove(record)
+		}
+		if record, ok := new[key]; ok {
+			ix.add(record)
+		}
+	}
+}
+
+// blockRange returns the entries with fromBlock <= block <= toBlock; toBlock 0 means no upper bound.
+func (ix *ledgerIndex) blockRange(fromBlock, toBlock uint64) []indexEntry {
+	start := sort.Search(len(ix.byBlock), func(i int) bool { return ix.byBlock[i].block >= fromBlock })
+	end := len(ix.byBlock)
+	if toBlock != 0 {
+		end = sort.Search(len(ix.byBlock), func(i int) bool { return ix.byBlock[i].block > toBlock })
+	}
+	if start >= end {
+		return nil
+	}
+	return ix.byBlock[start:end]
+}
+
+// prefixRange returns the hashes starting with prefix, found by binary search.
+func (ix *ledgerIndex) prefixRange(prefix string) []string {
+	start := sort.SearchStrings(ix.byHash, prefix)
+	end := start
+	for end < len(ix.byHash) && strings.HasPrefix(ix.byHash[end], prefix) {
+		end++
+	}
+	return ix.byHash[start:end]
+}
+
+// insertHash inserts hash into the sorted hashes unless it is already present.
+func insertHash(hashes []string, hash string) []string {
+	i := sort.SearchStrings(hashes, hash)
+	if i < len(hashes) && hashes[i] == hash {
+		return hashes
+	}
+	hashes = append(hashes, "")
+	copy(hashes[i+1:], hashes[i:])
+	hashes[i] = hash
+	return hashes
+}
+
+// removeHash removes hash from the sorted hashes.
+func removeHash(hashes []string, hash string) []string {
+	i := sort.SearchStrings(hashes, hash)
+	if i < len(hashes) && hashes[i] == hash {
+		return append(hashes[:i], hashes[i+1:]...)
+	}
+	return hashes
+}
+
+// insertEntry inserts entry into entries, keeping them ordered by block number.
+// New anchors get the highest block number, so this is an append in the common case.
+func insertEntry(entries []indexEntry, entry indexEntry) []indexEntry {
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].block > entry.block })
+	entries = append(entries, indexEntry{})
+	copy(entries[i+1:], entries[i:])
+	entries[i] = entry
+	return entries
+}
+
+// removeEntry removes entry from entries ordered by block number.
+func removeEntry(entries []indexEntry, entry indexEntry) []indexEntry {
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].block >= entry.block })
+	for ; i < len(entries) && entries[i].block == entry.block; i++ {
+		if entries[i].hash == entry.hash {
+			return append(entries[:i], entries[i+1:]...)
+		}
+	}
+	return entries
+}
@@ -2,6 +2,8 @@ package fabric
 
 import (
 	"context"
+	"time"
+
 	"fabric-resolver/internal/domain"
 )
 
@@ -9,11 +11,93 @@ import (
 type LedgerClient interface {
 	CreateAnchor(ctx context.Context, anchor *domain.Anchor) (string, uint64, error)
 	GetAnchor(ctx context.Context, hash string) (*domain.Anchor, error)
+
+	// CreateAnchors creates up to MaxAnchorBatch anchors in one ledger write and
+	// reports a result per anchor, in input order.
+	CreateAnchors(ctx context.Context, anchors []*domain.Anchor) ([]AnchorResult, error)
+
+	GetAnchorByTxID(ctx context.Context, txID string) (*domain.Anchor, error)
 	VerifyAnchor(ctx context.Context, hash string) bool
 
+	// VerifyAnchors looks up many hashes at once and returns an entry for every hash given.
+	VerifyAnchors(ctx context.Context, hashes []string) (map[string]AnchorVerification, error)
+
+	// ListAnchors returns anchors ordered by block number, one page at a time.
+	ListAnchors(ctx context.Context, opts ListOptions) (*AnchorPage, error)
+
+	// QueryAnchors returns all anchors matching filter ordered by block number.
+	QueryAnchors(ctx context.Context, filter AnchorFilter) ([]domain.Anchor, error)
+
+	// FindAnchorsByPrefix returns up to limit anchors whose hash starts with prefix,
+	// ordered by block number. The prefix must be at least MinAnchorPrefix characters.
+	FindAnchorsByPrefix(ctx context.Context, prefix string, limit int) ([]domain.Anchor, error)
+
+	// GetAnchorsByIssuer returns the anchors created by issuerDID ordered by block number, one page at a time.
+	GetAnchorsByIssuer(ctx context.Context, issuerDID string, opts ListOptions) (*AnchorPage, error)
+
+	// TombstoneAnchor erases an anchor's metadata and issuer, keeping the evidence that it was anchored.
+	TombstoneAnchor(ctx context.Context, hash, reason string) error
+
+	// RevokeAnchor marks an anchor as revoked by its issuer. Revoking twice keeps the first revocation.
+	RevokeAnchor(ctx context.Context, hash, reason string) error
+
+	// SubscribeAnchors streams anchors as they are committed to the ledger.
+	// The channel is closed when ctx is done.
+	SubscribeAnchors(ctx context.Context) (<-chan domain.Anchor, error)
+
 	CreateDid(ctx context.Context, didDoc *domain.DIDDocument) error
 	GetDid(ctx context.Context, did string) (*domain.DIDDocument, error)
 
-	GetStats() map[string]interface{}
+	// UpdateDid replaces the document of an existing DID; Created is kept and Updated bumped.
+	// It fails with ErrAlreadyExists unless the stored document is still at expectedVersion,
+	// the VersionID of the document the update was authorized against.
+	UpdateDid(ctx context.Context, didDoc *domain.DIDDocument, expectedVersion uint64) error
+
+	// DeactivateDid marks a DID as deactivated; it still resolves but can no longer be updated.
+	// Like UpdateDid it fails with ErrAlreadyExists unless the DID is at expectedVersion.
+	DeactivateDid(ctx context.Context, did string, expectedVersion uint64) error
+
+	// ListDids returns DID documents ordered by creation time, one page at a time.
+	ListDids(ctx context.Context, opts DidListOptions) (*DidPage, error)
+
+	// GetStatusList returns a status list, or an ErrNotFound error.
+	GetStatusList(ctx context.Context, id string) (*domain.StatusList, error)
+
+	// SaveStatusList creates or replaces a status list.
+	SaveStatusList(ctx context.Context, list *domain.StatusList) error
+
+	// SaveWebhook creates or replaces a webhook registration.
+	SaveWebhook(ctx context.Context, hook *domain.Webhook) error
+
+	// ListWebhooks returns every webhook registration ordered by creation time.
+	ListWebhooks(ctx context.Context) ([]domain.Webhook, error)
+
+	// DeleteWebhook removes a webhook registration, or returns an ErrNotFound error.
+	DeleteWebhook(ctx context.Context, id string) error
+
+	// Ping checks that the backend can serve reads and writes, cheaply enough
+	// for a readiness probe.
+	Ping(ctx context.Context) error
+
+	GetStats() Stats
 	Close() error
 }
+
+// Stats summarizes the contents and state of a ledger backend.
+// JSON tags keep the /stats response shape stable.
+type Stats struct {
+	Anchors       int            `json:"anchors"`
+	DIDs          int            `json:"dids"`
+	NextBlock     uint64         `json:"nextBlock"`
+	Mode          string         `json:"mode"`
+	Path          string         `json:"path,omitempty"`
+	FileSizeBytes int64          `json:"fileSizeBytes,omitempty"`
+	LastWriteTime *time.Time     `json:"lastWriteTime,omitempty"`
+	DocTypes      map[string]int `json:"docTypes,omitempty"` // Record count per docType
+	Pruned        uint64         `json:"pruned"`             // Expired anchors removed by the reaper
+
+	// LastWrites is when records of each docType were last written by this process
+	LastWrites map[string]time.Time `json:"lastWrites,omitempty"`
+	// LastCompaction is when the reaper last pruned expired records from the file
+	LastCompaction *time.Time `json:"lastCompaction,omitempty"`
+}
@@ -0,0 +1,146 @@
+package fabric
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fabric-resolver/internal/domain"
+)
+
+// ListOptions controls pagination of list queries.
+type ListOptions struct {
+	Limit  int    // Maximum number of items to return (must be > 0)
+	Cursor string // Cursor from a previous page's NextCursor; empty starts at the beginning
+}
+
+// AnchorPage is one page of anchors ordered by block number.
+type AnchorPage struct {
+	Items      []domain.Anchor
+	NextCursor string // Empty when there are no more items
+	Total      int    // Total number of anchors matching the query
+}
+
+// DidListOptions controls pagination and filtering of DID listings.
+type DidListOptions struct {
+	ListOptions
+	Controller string // Only return DIDs with this controller; empty returns all
+}
+
+// DidPage is one page of DID documents ordered by creation time.
+type DidPage struct {
+	Items      []domain.DIDDocument
+	NextCursor string // Empty when there are no more items
+	Total      int    // Total number of DIDs matching the query
+}
+
+// parseBlockCursor decodes a cursor holding the block number of the last item of the previous page.
+func parseBlockCursor(cursor string) (uint64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	block, err := strconv.ParseUint(cursor, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor %q: %w", cursor, ErrValidation)
+	}
+	return block, nil
+}
+
+// blockCursor encodes the block number of the last item on a page as a cursor.
+func blockCursor(block uint64) string {
+	return strconv.FormatUint(block, 10)
+}
+
+// validateListOptions rejects non-positive limits.
+func validateListOptions(opts ListOptions) error {
+	if opts.Limit <= 0 {
+		return fmt.Errorf("limit must be positive: %w", ErrValidation)
+	}
+	return nil
+}
+
+// pageAnchors slices anchors (already sorted by block number) into the page after the cursor.
+func pageAnchors(sorted []domain.Anchor, opts ListOptions) (*AnchorPage, error) {
+	if err := validateListOptions(opts); err != nil {
+		return nil, err
+	}
+	after, err := parseBlockCursor(opts.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &AnchorPage{Items: []domain.Anchor{}, Total: len(sorted)}
+
+	start := 0
+	for start < len(sorted) && sorted[start].BlockNumber <= after {
+		start++
+	}
+	end := start + opts.Limit
+	if end > len(sorted) {
+		end = len(sorted)
+	}
+
+	page.Items = append(page.Items, sorted[start:end]...)
+	if end < len(sorted) {
+		page.NextCursor = blockCursor(sorted[end-1].BlockNumber)
+	}
+	return page, nil
+}
+
+// didCursor encodes the position of the last DID on a page. The ID breaks ties
+// between documents created in the same nanosecond.
+func didCursor(doc *domain.DIDDocument) string {
+	return strconv.FormatInt(doc.Created.UnixNano(), 10) + ":" + doc.ID
+}
+
+// parseDidCursor decodes a cursor produced by didCursor.
+func parseDidCursor(cursor string) (time.Time, string, error) {
+	nanos, id, ok := strings.Cut(cursor, ":")
+	if !ok {
+		return time.Time{}, "", fmt.Errorf("invalid cursor %q: %w", cursor, ErrValidation)
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor %q: %w", cursor, ErrValidation)
+	}
+	return time.Unix(0, n), id, nil
+}
+
+// didBefore orders DID documents by creation time, then by ID.
+func didBefore(a, b *domain.DIDDocument) bool {
+	if !a.Created.Equal(b.Created) {
+		return a.Created.Before(b.Created)
+	}
+	return a.ID < b.ID
+}
+
+// pageDids slices docs (already filtered and sorted with didBefore) into the page after the cursor.
+func pageDids(sorted []domain.DIDDocument, opts DidListOptions) (*DidPage, error) {
+	if err := validateListOptions(opts.ListOptions); err != nil {
+		return nil, err
+	}
+
+	start := 0
+	if opts.Cursor != "" {
+		created, id, err := parseDidCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		last := &domain.DIDDocument{ID: id, Created: created}
+		for start < len(sorted) && !didBefore(last, &sorted[start]) {
+			start++
+		}
+	}
+	end := start + opts.Limit
+	if end > len(sorted) {
+		end = len(sorted)
+	}
+
+	page := &DidPage{Items: []domain.DIDDocument{}, Total: len(sorted)}
+	page.Items = append(page.Items, sorted[start:end]...)
+	if end < len(sorted) {
+		page.NextCursor = didCursor(&sorted[end-1])
+	}
+	return page, nil
+}
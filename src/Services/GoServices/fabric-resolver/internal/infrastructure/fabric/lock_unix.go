@@ -0,0 +1,28 @@
+//go:build unix
+
+package fabric
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockLedger takes an exclusive lock on path for the life of the returned file,
+// failing with ErrLocked while another process holds it. The kernel releases
+// the lock when the process dies, so a crash leaves no stale lock behind.
+func lockLedger(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ledger lock: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+		}
+		return nil, fmt.Errorf("failed to lock ledger: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,291 @@
+package fabric
+
+import (
+	"context"
+	"errors"
+
+	"fabric-resolver/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsLedgerClient decorates a LedgerClient with Prometheus metrics, so the same
+// series are exported whichever backend is configured. It is a prometheus.Collector;
+// register it to export the metrics. The record and file size gauges are read from
+// GetStats when scraped.
+type MetricsLedgerClient struct {
+	inner LedgerClient
+
+	anchorsCreated prometheus.Counter
+	didsCreated    prometheus.Counter
+	verifications  *prometheus.CounterVec
+	errors         *prometheus.CounterVec
+	records        *prometheus.Desc
+	fileSize       *prometheus.Desc
+}
+
+// NewMetricsLedgerClient wraps inner with metrics.
+func NewMetricsLedgerClient(inner LedgerClient) *MetricsLedgerClient {
+	return newMetricsLedgerClient(inner, nil)
+}
+
+// NewTenantMetricsLedgerClient wraps the ledger of tenant with metrics labelled
+// tenant="<tenant>". Series of one name must share their labels, so a resolver
+// with tenants wraps its shared ledger with tenant "" too.
+func NewTenantMetricsLedgerClient(inner LedgerClient, tenant string) *MetricsLedgerClient {
+	return newMetricsLedgerClient(inner, prometheus.Labels{"tenant": tenant})
+}
+
+func newMetricsLedgerClient(inner LedgerClient, labels prometheus.Labels) *MetricsLedgerClient {
+	return &MetricsLedgerClient{
+		inner: inner,
+		anchorsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "fabric_resolver_anchors_created_total",
+			Help:        "Anchors created on the ledger, singly or in batches.",
+			ConstLabels: labels,
+		}),
+		didsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "fabric_resolver_dids_created_total",
+			Help:        "DIDs created on the ledger.",
+			ConstLabels: labels,
+		}),
+		verifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "fabric_resolver_anchor_verifications_total",
+			Help:        "Hashes verified against the ledger, by whether a live anchor was found (hit) or not (miss).",
+			ConstLabels: labels,
+		}, []string{"result"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "fabric_resolver_ledger_errors_total",
+			Help:        "Failed ledger operations by operation and error type.",
+			ConstLabels: labels,
+		}, []string{"operation", "type"}),
+		records: prometheus.NewDesc("fabric_resolver_ledger_records",
+			"Records on the ledger: anchors, DIDs, status lists and webhooks.", nil, labels),
+		fileSize: prometheus.NewDesc("fabric_resolver_ledger_file_size_bytes",
+			"Size of the ledger file; only exported by the file backend.", nil, labels),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *MetricsLedgerClient) Describe(ch chan<- *prometheus.Desc) {
+	c.anchorsCreated.Describe(ch)
+	c.didsCreated.Describe(ch)
+	c.verifications.Describe(ch)
+	c.errors.Describe(ch)
+	ch <- c.records
+	ch <- c.fileSize
+}
+
+// Collect implements prometheus.Collector.
+func (c *MetricsLedgerClient) Collect(ch chan<- prometheus.Metric) {
+	c.anchorsCreated.Collect(ch)
+	c.didsCreated.Collect(ch)
+	c.verifications.Collect(ch)
+	c.errors.Collect(ch)
+
+	stats := c.inner.GetStats()
+	records := stats.Anchors + stats.DIDs
+	if len(stats.DocTypes) > 0 {
+		records = 0
+		for _, n := range stats.DocTypes {
+			records += n
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(c.records, prometheus.GaugeValue, float64(records))
+	if stats.Path != "" {
+		ch <- prometheus.MustNewConstMetric(c.fileSize, prometheus.GaugeValue, float64(stats.FileSizeBytes))
+	}
+}
+
+// observe counts err, if any, under operation and returns it.
+func (c *MetricsLedgerClient) observe(operation string, err error) error {
+	if err != nil {
+		c.errors.WithLabelValues(operation, errorType(err)).Inc()
+	}
+	return err
+}
+
+// errorType names the class of a ledger error for the errors metric.
+func errorType(err error) string {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrAlreadyExists):
+		return "already_exists"
+	case errors.Is(err, ErrValidation):
+		return "validation"
+	case errors.Is(err, ErrExpired):
+		return "expired"
+	case errors.Is(err, ErrDeactivated):
+		return "deactivated"
+	case errors.Is(err, ErrClientClosed):
+		return "closed"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	case errors.Is(err, ErrTransient):
+		return "transient"
+	default:
+		return "other"
+	}
+}
+
+func (c *MetricsLedgerClient) verified(found bool) {
+	if found {
+		c.verifications.WithLabelValues("hit").Inc()
+	} else {
+		c.verifications.WithLabelValues("miss").Inc()
+	}
+}
+
+// CreateAnchor counts the anchor as created unless it already existed. Backends
+// return existing anchors without an error, so the hash is looked up first; two
+// concurrent requests for a new hash may both be counted.
+func (c *MetricsLedgerClient) CreateAnchor(ctx context.Context, anchor *domain.Anchor) (string, uint64, error) {
+	existed := c.inner.VerifyAnchor(ctx, anchor.Hash)
+	txID, blockNum, err := c.inner.CreateAnchor(ctx, anchor)
+	if c.observe("CreateAnchor", err) == nil && !existed {
+		c.anchorsCreated.Inc()
+	}
+	return txID, blockNum, err
+}
+
+func (c *MetricsLedgerClient) CreateAnchors(ctx context.Context, anchors []*domain.Anchor) ([]AnchorResult, error) {
+	results, err := c.inner.CreateAnchors(ctx, anchors)
+	if c.observe("CreateAnchors", err) != nil {
+		return results, err
+	}
+	for _, result := range results {
+		switch result.Status {
+		case AnchorCreated:
+			c.anchorsCreated.Inc()
+		case AnchorFailed:
+			c.observe("CreateAnchors", result.Err)
+		}
+	}
+	return results, nil
+}
+
+func (c *MetricsLedgerClient) GetAnchor(ctx context.Context, hash string) (*domain.Anchor, error) {
+	anchor, err := c.inner.GetAnchor(ctx, hash)
+	return anchor, c.observe("GetAnchor", err)
+}
+
+func (c *MetricsLedgerClient) GetAnchorByTxID(ctx context.Context, txID string) (*domain.Anchor, error) {
+	anchor, err := c.inner.GetAnchorByTxID(ctx, txID)
+	return anchor, c.observe("GetAnchorByTxID", err)
+}
+
+func (c *MetricsLedgerClient) VerifyAnchor(ctx context.Context, hash string) bool {
+	found := c.inner.VerifyAnchor(ctx, hash)
+	c.verified(found)
+	return found
+}
+
+func (c *MetricsLedgerClient) VerifyAnchors(ctx context.Context, hashes []string) (map[string]AnchorVerification, error) {
+	found, err := c.inner.VerifyAnchors(ctx, hashes)
+	if c.observe("VerifyAnchors", err) != nil {
+		return found, err
+	}
+	for _, v := range found {
+		c.verified(v.Exists)
+	}
+	return found, nil
+}
+
+func (c *MetricsLedgerClient) ListAnchors(ctx context.Context, opts ListOptions) (*AnchorPage, error) {
+	page, err := c.inner.ListAnchors(ctx, opts)
+	return page, c.observe("ListAnchors", err)
+}
+
+func (c *MetricsLedgerClient) QueryAnchors(ctx context.Context, filter AnchorFilter) ([]domain.Anchor, error) {
+	anchors, err := c.inner.QueryAnchors(ctx, filter)
+	return anchors, c.observe("QueryAnchors", err)
+}
+
+func (c *MetricsLedgerClient) FindAnchorsByPrefix(ctx context.Context, prefix string, limit int) ([]domain.Anchor, error) {
+	anchors, err := c.inner.FindAnchorsByPrefix(ctx, prefix, limit)
+	return anchors, c.observe("FindAnchorsByPrefix", err)
+}
+
+func (c *MetricsLedgerClient) GetAnchorsByIssuer(ctx context.Context, issuerDID string, opts ListOptions) (*AnchorPage, error) {
+	page, err := c.inner.GetAnchorsByIssuer(ctx, issuerDID, opts)
+	return page, c.observe("GetAnchorsByIssuer", err)
+}
+
+func (c *MetricsLedgerClient) TombstoneAnchor(ctx context.Context, hash, reason string) error {
+	return c.observe("TombstoneAnchor", c.inner.TombstoneAnchor(ctx, hash, reason))
+}
+
+func (c *MetricsLedgerClient) RevokeAnchor(ctx context.Context, hash, reason string) error {
+	return c.observe("RevokeAnchor", c.inner.RevokeAnchor(ctx, hash, reason))
+}
+
+func (c *MetricsLedgerClient) SubscribeAnchors(ctx context.Context) (<-chan domain.Anchor, error) {
+	anchors, err := c.inner.SubscribeAnchors(ctx)
+	return anchors, c.observe("SubscribeAnchors", err)
+}
+
+func (c *MetricsLedgerClient) CreateDid(ctx context.Context, didDoc *domain.DIDDocument) error {
+	err := c.inner.CreateDid(ctx, didDoc)
+	if c.observe("CreateDid", err) == nil {
+		c.didsCreated.Inc()
+	}
+	return err
+}
+
+func (c *MetricsLedgerClient) GetDid(ctx context.Context, did string) (*domain.DIDDocument, error) {
+	didDoc, err := c.inner.GetDid(ctx, did)
+	return didDoc, c.observe("GetDid", err)
+}
+
+func (c *MetricsLedgerClient) UpdateDid(ctx context.Context, didDoc *domain.DIDDocument, expectedVersion uint64) error {
+	return c.observe("UpdateDid", c.inner.UpdateDid(ctx, didDoc, expectedVersion))
+}
+
+func (c *MetricsLedgerClient) DeactivateDid(ctx context.Context, did string, expectedVersion uint64) error {
+	return c.observe("DeactivateDid", c.inner.DeactivateDid(ctx, did, expectedVersion))
+}
+
+func (c *MetricsLedgerClient) ListDids(ctx context.Context, opts DidListOptions) (*DidPage, error) {
+	page, err := c.inner.ListDids(ctx, opts)
+	return page, c.observe("ListDids", err)
+}
+
+func (c *MetricsLedgerClient) GetStatusList(ctx context.Context, id string) (*domain.StatusList, error) {
+	list, err := c.inner.GetStatusList(ctx, id)
+	return list, c.observe("GetStatusList", err)
+}
+
+func (c *MetricsLedgerClient) SaveStatusList(ctx context.Context, list *domain.StatusList) error {
+	return c.observe("SaveStatusList", c.inner.SaveStatusList(ctx, list))
+}
+
+func (c *MetricsLedgerClient) SaveWebhook(ctx context.Context, hook *domain.Webhook) error {
+	return c.observe("SaveWebhook", c.inner.SaveWebhook(ctx, hook))
+}
+
+func (c *MetricsLedgerClient) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	hooks, err := c.inner.ListWebhooks(ctx)
+	return hooks, c.observe("ListWebhooks", err)
+}
+
+func (c *MetricsLedgerClient) DeleteWebhook(ctx context.Context, id string) error {
+	return c.observe("DeleteWebhook", c.inner.DeleteWebhook(ctx, id))
+}
+
+func (c *MetricsLedgerClient) Ping(ctx context.Context) error {
+	return c.observe("Ping", c.inner.Ping(ctx))
+}
+
+func (c *MetricsLedgerClient) GetStats() Stats {
+	return c.inner.GetStats()
+}
+
+func (c *MetricsLedgerClient) Close() error {
+	return c.inner.Close()
+}
+
+// Unwrap returns the client wrapped by c.
+func (c *MetricsLedgerClient) Unwrap() LedgerClient {
+	return c.inner
+}
@@ -0,0 +1,83 @@
+package fabric
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"fabric-resolver/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// gather returns the registry's samples keyed by name and label values, e.g.
+// "fabric_resolver_anchor_verifications_total{hit}".
+func gather(t *testing.T, reg *prometheus.Registry) map[string]float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather failed: %v", err)
+	}
+	samples := make(map[string]float64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			key := family.GetName()
+			if len(m.GetLabel()) > 0 {
+				key += "{"
+				for i, label := range m.GetLabel() {
+					if i > 0 {
+						key += ","
+					}
+					key += label.GetValue()
+				}
+				key += "}"
+			}
+			samples[key] = m.GetCounter().GetValue() + m.GetGauge().GetValue()
+		}
+	}
+	return samples
+}
+
+func TestMetricsLedgerClient(t *testing.T) {
+	inner, err := NewFileLedgerClientWithLogger(filepath.Join(t.TempDir(), "ledger.json"), discardLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewMetricsLedgerClient(inner)
+	defer client.Close()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(client)
+	ctx := context.Background()
+
+	client.CreateAnchor(ctx, &domain.Anchor{Hash: "h1"})
+	// Creating an existing anchor returns it and is not counted again
+	client.CreateAnchor(ctx, &domain.Anchor{Hash: "h1"})
+	client.CreateAnchors(ctx, []*domain.Anchor{{Hash: "h2"}, {Hash: "h3"}, {Hash: "h1"}})
+	client.CreateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:m"})
+	if err := client.CreateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:m"}); err == nil {
+		t.Fatal("expected a duplicate DID to fail")
+	}
+	client.VerifyAnchor(ctx, "h1")
+	client.VerifyAnchor(ctx, "missing")
+	client.VerifyAnchors(ctx, []string{"h2", "gone"})
+	client.GetAnchor(ctx, "missing")
+
+	samples := gather(t, reg)
+	want := map[string]float64{
+		"fabric_resolver_anchors_created_total":                         3,
+		"fabric_resolver_dids_created_total":                            1,
+		"fabric_resolver_anchor_verifications_total{hit}":               2,
+		"fabric_resolver_anchor_verifications_total{miss}":              2,
+		"fabric_resolver_ledger_errors_total{CreateDid,already_exists}": 1,
+		"fabric_resolver_ledger_errors_total{GetAnchor,not_found}":      1,
+		"fabric_resolver_ledger_records":                                4,
+	}
+	for key, value := range want {
+		if samples[key] != value {
+			t.Errorf("expected %s = %g, got %g", key, value, samples[key])
+		}
+	}
+	if samples["fabric_resolver_ledger_file_size_bytes"] <= 0 {
+		t.Errorf("expected the ledger file size, got %v", samples)
+	}
+}
@@ -1,41 +1,176 @@
 package fabric
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
+	"log/slog"
+	"strings"
+	"time"
 )
 
 // Config holds configuration for the ledger client
 type Config struct {
 	Mode     string // "file" or "fabric"
 	FilePath string // For file mode (default: data/ledger.json)
-	// Add Fabric specific config fields here later (CCP, MSP, etc.)
+
+	// RetryMaxAttempts enables retries of transient write failures when > 1
+	RetryMaxAttempts int
+
+	// ReapInterval is how often expired anchors are pruned (<= 0 disables pruning)
+	ReapInterval time.Duration
+
+	// OpenAttempts bounds how often the server tries to open a file ledger that
+	// another process has locked, OpenRetryDelay apart, before giving up (<= 1 tries once)
+	OpenAttempts   int
+	OpenRetryDelay time.Duration
+
+	// Fabric Gateway connection (required when Mode is "fabric")
+	PeerEndpoint  string // host:port of the gateway peer
+	TLSCACertPath string // CA certificate used to verify the peer's TLS certificate
+	MSPID         string // Membership service provider ID of the client identity
+	CertPath      string // Client identity certificate (PEM)
+	KeyPath       string // Client identity private key (PEM)
+	ChannelID     string
+	ChaincodeName string
+
+	// ReadOnly refuses writes with ErrReadOnly while it is on, and holds off pruning
+	// expired anchors; nil never refuses them
+	ReadOnly *ReadOnlySwitch
+
+	// Logger receives the ledger client's logs; nil uses slog.Default()
+	Logger *slog.Logger
+}
+
+// MissingFabricFields returns the names of the Fabric fields that are empty,
+// formatted as "Field (ENV_VAR)" so operators know what to set.
+func (c Config) MissingFabricFields() []string {
+	fields := []struct {
+		name, env, value string
+	}{
+		{"PeerEndpoint", "FABRIC_PEER_ENDPOINT", c.PeerEndpoint},
+		{"TLSCACertPath", "FABRIC_TLS_CA_CERT_PATH", c.TLSCACertPath},
+		{"MSPID", "FABRIC_MSP_ID", c.MSPID},
+		{"CertPath", "FABRIC_CERT_PATH", c.CertPath},
+		{"KeyPath", "FABRIC_KEY_PATH", c.KeyPath},
+		{"ChannelID", "FABRIC_CHANNEL_ID", c.ChannelID},
+		{"ChaincodeName", "FABRIC_CHAINCODE_NAME", c.ChaincodeName},
+	}
+
+	var missing []string
+	for _, f := range fields {
+		if strings.TrimSpace(f.value) == "" {
+			missing = append(missing, fmt.Sprintf("%s (%s)", f.name, f.env))
+		}
+	}
+	return missing
+}
+
+// Validate checks that the configuration is usable for the selected mode.
+func (c Config) Validate() error {
+	switch c.Mode {
+	case "", "file":
+		return nil
+	case "fabric":
+		if missing := c.MissingFabricFields(); len(missing) > 0 {
+			return fmt.Errorf("ledger mode 'fabric' is missing required fields: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid ledger mode: %s (supported: file, fabric)", c.Mode)
+	}
 }
 
 // NewLedgerClient creates a new LedgerClient based on configuration.
-// Defaults to FileLedgerClient if Mode is empty or "file".
+// Defaults to FileLedgerClient if Mode is empty or "file", which is locked for
+// this process; ErrLocked reports that another process has it open.
 func NewLedgerClient(cfg Config) (LedgerClient, error) {
 	if cfg.Mode == "" {
 		cfg.Mode = "file"
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var client LedgerClient
+	var err error
+
 	switch cfg.Mode {
 	case "file":
 		if cfg.FilePath == "" {
 			cfg.FilePath = "data/ledger.json"
 		}
-		return NewFileLedgerClient(cfg.FilePath)
+		var fileClient *FileLedgerClient
+		fileClient, err = newFileLedgerClient(cfg.FilePath, cfg.Logger, true)
+		if err == nil {
+			fileClient.readOnly = cfg.ReadOnly
+			fileClient.StartReaper(cfg.ReapInterval)
+			client = fileClient
+		}
 	case "fabric":
-		return NewRealClient(cfg)
-	default:
-		return nil, fmt.Errorf("invalid ledger mode: %s (supported: file, fabric)", cfg.Mode)
+		client, err = NewRealClient(cfg)
+	}
+	if err != nil {
+		return nil, err
 	}
+
+	if cfg.RetryMaxAttempts > 1 {
+		client = NewRetryingLedgerClient(client, DefaultRetryConfig(cfg.RetryMaxAttempts), cfg.Logger)
+	}
+	if cfg.ReadOnly != nil {
+		client = NewReadOnlyLedgerClient(client, cfg.ReadOnly)
+	}
+
+	return client, nil
 }
 
-// LoadConfigFromEnv helper to load common env vars
-func LoadConfigFromEnv() Config {
+// OpenLedgerClient creates the client of cfg as NewLedgerClient does, waiting for
+// a file ledger that another process holds, as the old instance of a rolling
+// update does until it has shut down: it tries cfg.OpenAttempts times,
+// cfg.OpenRetryDelay apart. Other failures are returned at once.
+func OpenLedgerClient(ctx context.Context, cfg Config) (LedgerClient, error) {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	attempts := max(cfg.OpenAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		client, err := NewLedgerClient(cfg)
+		if err == nil || !errors.Is(err, ErrLocked) || attempt == attempts {
+			return client, err
+		}
+		logger.Warn("Ledger is locked, retrying", "attempt", attempt, "of", attempts, "delay", cfg.OpenRetryDelay, "err", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(cfg.OpenRetryDelay):
+		}
+	}
+}
+
+// LoadConfig loads the textual ledger settings from getenv, which looks up a
+// setting by its environment variable name; empty values use the defaults.
+// Numeric settings are left to the caller, which parses and reports them with
+// the rest of its configuration.
+func LoadConfig(getenv func(string) string) Config {
 	return Config{
-		Mode:     os.Getenv("LEDGER_MODE"),
-		FilePath: os.Getenv("LEDGER_FILE_PATH"),
+		Mode:     getenv("LEDGER_MODE"),
+		FilePath: getenv("LEDGER_FILE_PATH"),
+
+		PeerEndpoint:  getenv("FABRIC_PEER_ENDPOINT"),
+		TLSCACertPath: getenv("FABRIC_TLS_CA_CERT_PATH"),
+		MSPID:         orDefault(getenv("FABRIC_MSP_ID"), "Org1MSP"),
+		CertPath:      getenv("FABRIC_CERT_PATH"),
+		KeyPath:       getenv("FABRIC_KEY_PATH"),
+		ChannelID:     orDefault(getenv("FABRIC_CHANNEL_ID"), "mychannel"),
+		ChaincodeName: orDefault(getenv("FABRIC_CHAINCODE_NAME"), "verifiable-credentials"),
+	}
+}
+
+func orDefault(value, defaultValue string) string {
+	if value != "" {
+		return value
 	}
+	return defaultValue
 }
@@ -0,0 +1,51 @@
+package fabric
+
+import (
+	"fmt"
+	"time"
+)
+
+// MinAnchorPrefix is the shortest prefix FindAnchorsByPrefix accepts, to avoid scanning most of the ledger.
+const MinAnchorPrefix = 4
+
+// validatePrefixQuery rejects prefixes shorter than MinAnchorPrefix and non-positive limits.
+func validatePrefixQuery(prefix string, limit int) error {
+	if len(prefix) < MinAnchorPrefix {
+		return fmt.Errorf("prefix must be at least %d characters: %w", MinAnchorPrefix, ErrValidation)
+	}
+	if limit <= 0 {
+		return fmt.Errorf("limit must be positive: %w", ErrValidation)
+	}
+	return nil
+}
+
+// AnchorFilter selects anchors by block and time range. All bounds are inclusive
+// and zero values leave that side of the range open.
+type AnchorFilter struct {
+	FromBlock uint64
+	ToBlock   uint64
+	FromTime  time.Time
+	ToTime    time.Time
+}
+
+// Validate rejects inverted ranges.
+func (f AnchorFilter) Validate() error {
+	if f.ToBlock != 0 && f.FromBlock > f.ToBlock {
+		return fmt.Errorf("fromBlock %d is after toBlock %d: %w", f.FromBlock, f.ToBlock, ErrValidation)
+	}
+	if !f.FromTime.IsZero() && !f.ToTime.IsZero() && f.FromTime.After(f.ToTime) {
+		return fmt.Errorf("from %s is after to %s: %w", f.FromTime.Format(time.RFC3339), f.ToTime.Format(time.RFC3339), ErrValidation)
+	}
+	return nil
+}
+
+// matchesTime reports whether t lies within the filter's time range.
+func (f AnchorFilter) matchesTime(t time.Time) bool {
+	if !f.FromTime.IsZero() && t.Before(f.FromTime) {
+		return false
+	}
+	if !f.ToTime.IsZero() && t.After(f.ToTime) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,192 @@
+package fabric
+
+import (
+	"context"
+	"sync/atomic"
+
+	"fabric-resolver/internal/domain"
+)
+
+// ReadOnlySwitch puts the ledger clients it guards into read-only mode, e.g. while
+// the ledger is migrated, and back. The zero value allows writes; a nil switch
+// never refuses them.
+type ReadOnlySwitch struct {
+	on atomic.Bool
+}
+
+// NewReadOnlySwitch returns a switch that starts in read-only mode when on is set.
+func NewReadOnlySwitch(on bool) *ReadOnlySwitch {
+	s := &ReadOnlySwitch{}
+	s.on.Store(on)
+	return s
+}
+
+// Set turns read-only mode on or off.
+func (s *ReadOnlySwitch) Set(on bool) {
+	s.on.Store(on)
+}
+
+// On reports whether writes are refused.
+func (s *ReadOnlySwitch) On() bool {
+	return s != nil && s.on.Load()
+}
+
+// ReadOnlyLedgerClient decorates a LedgerClient and refuses write operations with
+// ErrReadOnly while its switch is on, whoever the caller. Reads are passed
+// straight through to the inner client.
+type ReadOnlyLedgerClient struct {
+	inner LedgerClient
+	mode  *ReadOnlySwitch
+}
+
+// NewReadOnlyLedgerClient wraps inner with the read-only guard of mode.
+func NewReadOnlyLedgerClient(inner LedgerClient, mode *ReadOnlySwitch) *ReadOnlyLedgerClient {
+	return &ReadOnlyLedgerClient{inner: inner, mode: mode}
+}
+
+// guard returns ErrReadOnly while writes are refused.
+func (c *ReadOnlyLedgerClient) guard() error {
+	if c.mode.On() {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+func (c *ReadOnlyLedgerClient) CreateAnchor(ctx context.Context, anchor *domain.Anchor) (string, uint64, error) {
+	if err := c.guard(); err != nil {
+		return "", 0, err
+	}
+	return c.inner.CreateAnchor(ctx, anchor)
+}
+
+func (c *ReadOnlyLedgerClient) CreateAnchors(ctx context.Context, anchors []*domain.Anchor) ([]AnchorResult, error) {
+	if err := c.guard(); err != nil {
+		return nil, err
+	}
+	return c.inner.CreateAnchors(ctx, anchors)
+}
+
+func (c *ReadOnlyLedgerClient) GetAnchor(ctx context.Context, hash string) (*domain.Anchor, error) {
+	return c.inner.GetAnchor(ctx, hash)
+}
+
+func (c *ReadOnlyLedgerClient) GetAnchorByTxID(ctx context.Context, txID string) (*domain.Anchor, error) {
+	return c.inner.GetAnchorByTxID(ctx, txID)
+}
+
+func (c *ReadOnlyLedgerClient) VerifyAnchor(ctx context.Context, hash string) bool {
+	return c.inner.VerifyAnchor(ctx, hash)
+}
+
+func (c *ReadOnlyLedgerClient) VerifyAnchors(ctx context.Context, hashes []string) (map[string]AnchorVerification, error) {
+	return c.inner.VerifyAnchors(ctx, hashes)
+}
+
+func (c *ReadOnlyLedgerClient) ListAnchors(ctx context.Context, opts ListOptions) (*AnchorPage, error) {
+	return c.inner.ListAnchors(ctx, opts)
+}
+
+func (c *ReadOnlyLedgerClient) QueryAnchors(ctx context.Context, filter AnchorFilter) ([]domain.Anchor, error) {
+	return c.inner.QueryAnchors(ctx, filter)
+}
+
+func (c *ReadOnlyLedgerClient) FindAnchorsByPrefix(ctx context.Context, prefix string, limit int) ([]domain.Anchor, error) {
+	return c.inner.FindAnchorsByPrefix(ctx, prefix, limit)
+}
+
+func (c *ReadOnlyLedgerClient) GetAnchorsByIssuer(ctx context.Context, issuerDID string, opts ListOptions) (*AnchorPage, error) {
+	return c.inner.GetAnchorsByIssuer(ctx, issuerDID, opts)
+}
+
+func (c *ReadOnlyLedgerClient) TombstoneAnchor(ctx context.Context, hash, reason string) error {
+	if err := c.guard(); err != nil {
+		return err
+	}
+	return c.inner.TombstoneAnchor(ctx, hash, reason)
+}
+
+func (c *ReadOnlyLedgerClient) RevokeAnchor(ctx context.Context, hash, reason string) error {
+	if err := c.guard(); err != nil {
+		return err
+	}
+	return c.inner.RevokeAnchor(ctx, hash, reason)
+}
+
+func (c *ReadOnlyLedgerClient) SubscribeAnchors(ctx context.Context) (<-chan domain.Anchor, error) {
+	return c.inner.SubscribeAnchors(ctx)
+}
+
+func (c *ReadOnlyLedgerClient) CreateDid(ctx context.Context, didDoc *domain.DIDDocument) error {
+	if err := c.guard(); err != nil {
+		return err
+	}
+	return c.inner.CreateDid(ctx, didDoc)
+}
+
+func (c *ReadOnlyLedgerClient) UpdateDid(ctx context.Context, didDoc *domain.DIDDocument, expectedVersion uint64) error {
+	if err := c.guard(); err != nil {
+		return err
+	}
+	return c.inner.UpdateDid(ctx, didDoc, expectedVersion)
+}
+
+func (c *ReadOnlyLedgerClient) DeactivateDid(ctx context.Context, did string, expectedVersion uint64) error {
+	if err := c.guard(); err != nil {
+		return err
+	}
+	return c.inner.DeactivateDid(ctx, did, expectedVersion)
+}
+
+func (c *ReadOnlyLedgerClient) GetDid(ctx context.Context, did string) (*domain.DIDDocument, error) {
+	return c.inner.GetDid(ctx, did)
+}
+
+func (c *ReadOnlyLedgerClient) ListDids(ctx context.Context, opts DidListOptions) (*DidPage, error) {
+	return c.inner.ListDids(ctx, opts)
+}
+
+func (c *ReadOnlyLedgerClient) GetStatusList(ctx context.Context, id string) (*domain.StatusList, error) {
+	return c.inner.GetStatusList(ctx, id)
+}
+
+func (c *ReadOnlyLedgerClient) SaveStatusList(ctx context.Context, list *domain.StatusList) error {
+	if err := c.guard(); err != nil {
+		return err
+	}
+	return c.inner.SaveStatusList(ctx, list)
+}
+
+func (c *ReadOnlyLedgerClient) SaveWebhook(ctx context.Context, hook *domain.Webhook) error {
+	if err := c.guard(); err != nil {
+		return err
+	}
+	return c.inner.SaveWebhook(ctx, hook)
+}
+
+func (c *ReadOnlyLedgerClient) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	return c.inner.ListWebhooks(ctx)
+}
+
+func (c *ReadOnlyLedgerClient) DeleteWebhook(ctx context.Context, id string) error {
+	if err := c.guard(); err != nil {
+		return err
+	}
+	return c.inner.DeleteWebhook(ctx, id)
+}
+
+func (c *ReadOnlyLedgerClient) Ping(ctx context.Context) error {
+	return c.inner.Ping(ctx)
+}
+
+func (c *ReadOnlyLedgerClient) GetStats() Stats {
+	return c.inner.GetStats()
+}
+
+func (c *ReadOnlyLedgerClient) Close() error {
+	return c.inner.Close()
+}
+
+// Unwrap returns the client wrapped by c.
+func (c *ReadOnlyLedgerClient) Unwrap() LedgerClient {
+	return c.inner
+}
@@ -0,0 +1,58 @@
+package fabric
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/domain"
+)
+
+func TestReadOnlyLedgerClient_RefusesWritesWhileOn(t *testing.T) {
+	mode := NewReadOnlySwitch(false)
+	client, err := NewLedgerClient(Config{FilePath: filepath.Join(t.TempDir(), "ledger.json"), ReadOnly: mode})
+	if err != nil {
+		t.Fatalf("NewLedgerClient failed: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+	hash := strings.Repeat("ab", 32)
+	if _, _, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: hash}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+
+	mode.Set(true)
+	writes := map[string]error{
+		"CreateAnchor": func() error {
+			_, _, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: strings.Repeat("cd", 32)})
+			return err
+		}(),
+		"CreateAnchors": func() error {
+			_, err := client.CreateAnchors(ctx, []*domain.Anchor{{Hash: strings.Repeat("cd", 32)}})
+			return err
+		}(),
+		"RevokeAnchor":    client.RevokeAnchor(ctx, hash, "test"),
+		"TombstoneAnchor": client.TombstoneAnchor(ctx, hash, "test"),
+		"CreateDid":       client.CreateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:test"}),
+		"UpdateDid":       client.UpdateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:test"}, 1),
+		"DeactivateDid":   client.DeactivateDid(ctx, "did:ewallet:test", 1),
+		"SaveStatusList":  client.SaveStatusList(ctx, &domain.StatusList{ID: "list"}),
+		"SaveWebhook":     client.SaveWebhook(ctx, &domain.Webhook{ID: "hook"}),
+		"DeleteWebhook":   client.DeleteWebhook(ctx, "hook"),
+	}
+	for op, err := range writes {
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s: expected ErrReadOnly, got %v", op, err)
+		}
+	}
+	if _, err := client.GetAnchor(ctx, hash); err != nil {
+		t.Errorf("expected reads to pass, got %v", err)
+	}
+
+	mode.Set(false)
+	if err := client.RevokeAnchor(ctx, hash, "test"); err != nil {
+		t.Errorf("expected writes once read-only mode is off, got %v", err)
+	}
+}
@@ -4,44 +4,711 @@ package fabric
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
 	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
-// RealFabricClient implementation (skeleton)
+// defaultCommitTimeout bounds how long CreateAnchor/CreateDid wait for a block commit
+// when the caller's context has no earlier deadline.
+const defaultCommitTimeout = 30 * time.Second
+
+// anchorCreatedEvent is the chaincode event name emitted for new anchors.
+const anchorCreatedEvent = "AnchorCreated"
+
+// fabricContract abstracts the subset of the Fabric Gateway contract API used by the client,
+// so the commit/event handling can be tested without a running network.
+type fabricContract interface {
+	SubmitAsync(name string, args ...string) ([]byte, fabricCommit, error)
+	EvaluateTransaction(name string, args ...string) ([]byte, error)
+	ChaincodeEvents(ctx context.Context) (<-chan *chaincodeEvent, error)
+}
+
+// fabricCommit is a submitted transaction awaiting its commit status.
+type fabricCommit interface {
+	TransactionID() string
+	Status(ctx context.Context) (*commitStatus, error)
+}
+
+// commitStatus is the outcome of a transaction once it has been validated into a block.
+type commitStatus struct {
+	BlockNumber uint64
+	Successful  bool
+	Code        int32
+}
+
+// chaincodeEvent is an event emitted by the chaincode in a committed block.
+type chaincodeEvent struct {
+	BlockNumber   uint64
+	TransactionID string
+	EventName     string
+	Payload       []byte
+}
+
+// RealFabricClient talks to Hyperledger Fabric through the Gateway API.
 type RealFabricClient struct {
-	// Fabric SDK client fields would go here
+	contract      fabricContract
+	commitTimeout time.Duration
+	logger        *slog.Logger
+	closed        atomic.Bool
 }
 
 func NewRealClient(cfg Config) (LedgerClient, error) {
-	// Here we would initialize the real Fabric SDK key
-	return nil, errors.New("Real Fabric client is not yet configured (missing connection profile/crypto)")
+	if missing := cfg.MissingFabricFields(); len(missing) > 0 {
+		return nil, fmt.Errorf("ledger mode 'fabric' is missing required fields: %s", strings.Join(missing, ", "))
+	}
+
+	// Fail fast on unreadable crypto material before attempting to connect
+	for _, path := range []string{cfg.TLSCACertPath, cfg.CertPath, cfg.KeyPath} {
+		if _, err := os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read Fabric crypto material: %w", err)
+		}
+	}
+
+	// Here we would dial cfg.PeerEndpoint and obtain the contract for cfg.ChannelID/cfg.ChaincodeName
+	return nil, fmt.Errorf("Real Fabric client is not yet able to connect to %s (gateway SDK not linked)", cfg.PeerEndpoint)
+}
+
+// newRealClientWithContract creates a client on top of an already connected contract.
+func newRealClientWithContract(contract fabricContract) *RealFabricClient {
+	return &RealFabricClient{
+		contract:      contract,
+		commitTimeout: defaultCommitTimeout,
+		logger:        slog.Default(),
+	}
+}
+
+// submitAndWait submits a transaction and blocks until it is committed to a block.
+// The wait is bounded by ctx and the client's commit timeout, whichever is shorter.
+func (c *RealFabricClient) submitAndWait(ctx context.Context, name string, args ...string) (string, uint64, error) {
+	_, txID, blockNum, err := c.submitAndWaitResult(ctx, name, args...)
+	return txID, blockNum, err
+}
+
+// submitAndWaitResult is submitAndWait that also returns the chaincode's response payload.
+// It is traced as a ledger.<name> span with ledger.endorse and ledger.commit_wait children.
+func (c *RealFabricClient) submitAndWaitResult(ctx context.Context, name string, args ...string) (_ []byte, _ string, _ uint64, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "ledger."+name)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	if c.closed.Load() {
+		return nil, "", 0, ErrClientClosed
+	}
+	// Endorsement is bounded by the gateway's own timeout; do not start one for a request already given up
+	if err := ctx.Err(); err != nil {
+		return nil, "", 0, err
+	}
+
+	_, endorse := tracing.Tracer().Start(ctx, "ledger.endorse")
+	result, commit, err := c.contract.SubmitAsync(name, args...)
+	endorse.End()
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("failed to submit %s: %w", name, classifyChaincodeError(err))
+	}
+	span.SetAttributes(attribute.String("ledger.tx_id", commit.TransactionID()))
+
+	waitCtx, cancel := context.WithTimeout(ctx, c.commitTimeout)
+	defer cancel()
+
+	_, commitWait := tracing.Tracer().Start(waitCtx, "ledger.commit_wait")
+	status, err := commit.Status(waitCtx)
+	commitWait.End()
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, "", 0, fmt.Errorf("timed out waiting for commit of %s after %v: %w", commit.TransactionID(), c.commitTimeout, ErrTransient)
+		}
+		return nil, "", 0, fmt.Errorf("failed to get commit status of %s: %w", commit.TransactionID(), err)
+	}
+	if !status.Successful {
+		return nil, "", 0, fmt.Errorf("transaction %s failed to commit with status code %d", commit.TransactionID(), status.Code)
+	}
+
+	return result, commit.TransactionID(), status.BlockNumber, nil
+}
+
+// classifyChaincodeError wraps a chaincode error in the sentinel its message names.
+// The chaincode reports duplicates and missing keys only in the message text, as
+// "... already exists" and "... does not exist".
+func classifyChaincodeError(err error) error {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "already exists"):
+		return fmt.Errorf("%w: %w", ErrAlreadyExists, err)
+	case strings.Contains(msg, "does not exist"), strings.Contains(msg, "not found"):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case strings.Contains(msg, "invalid"):
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	return err
 }
 
 func (c *RealFabricClient) CreateAnchor(ctx context.Context, anchor *domain.Anchor) (string, uint64, error) {
-	return "", 0, errors.New("not implemented")
+	now := time.Now().UTC()
+
+	expiresAt := ""
+	if anchor.ExpiresAt != nil {
+		expiresAt = anchor.ExpiresAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	txID, blockNum, err := c.submitAndWait(ctx, "CreateAnchor",
+		anchor.Hash, anchor.IssuerDID, string(anchor.Metadata), now.Format(time.RFC3339Nano), expiresAt,
+		strconv.FormatBool(anchor.SignatureVerified), anchor.MetadataHash, anchor.Algorithm)
+	if err != nil {
+		return "", 0, err
+	}
+
+	anchor.TxID = txID
+	anchor.BlockNumber = blockNum
+	anchor.Timestamp = now
+
+	c.logger.Info("Anchor committed", "hash", anchor.Hash, "block", blockNum, "tx_id", txID)
+	return txID, blockNum, nil
+}
+
+// batchAnchorArg is the per-anchor payload of the CreateAnchors chaincode transaction.
+type batchAnchorArg struct {
+	Hash      string          `json:"hash"`
+	Algorithm string          `json:"algorithm,omitempty"`
+	IssuerDID string          `json:"issuerDid,omitempty"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+	ExpiresAt string          `json:"expiresAt,omitempty"`
+
+	MetadataHash      string `json:"metadataHash,omitempty"`
+	SignatureVerified bool   `json:"signatureVerified,omitempty"`
+}
+
+// CreateAnchors submits the batch as one CreateAnchors transaction, so every created
+// anchor shares its txID and block. The chaincode responds with the hashes that already existed.
+func (c *RealFabricClient) CreateAnchors(ctx context.Context, anchors []*domain.Anchor) ([]AnchorResult, error) {
+	results, err := validateAnchorBatch(anchors)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]batchAnchorArg, 0, len(anchors))
+	for i, anchor := range anchors {
+		if results[i].Status == AnchorFailed {
+			continue
+		}
+		arg := batchAnchorArg{
+			Hash:              anchor.Hash,
+			Algorithm:         anchor.Algorithm,
+			IssuerDID:         anchor.IssuerDID,
+			Metadata:          anchor.Metadata,
+			MetadataHash:      anchor.MetadataHash,
+			SignatureVerified: anchor.SignatureVerified,
+		}
+		if anchor.ExpiresAt != nil {
+			arg.ExpiresAt = anchor.ExpiresAt.UTC().Format(time.RFC3339Nano)
+		}
+		args = append(args, arg)
+	}
+	if len(args) == 0 {
+		return results, nil
+	}
+
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal anchor batch: %w", err)
+	}
+
+	now := time.Now().UTC()
+	result, txID, blockNum, err := c.submitAndWaitResult(ctx, "CreateAnchors", string(payload), now.Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []string
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &existing); err != nil {
+			return nil, fmt.Errorf("failed to decode CreateAnchors response: %w", err)
+		}
+	}
+	existed := make(map[string]bool, len(existing))
+	for _, hash := range existing {
+		existed[hash] = true
+	}
+
+	for i, anchor := range anchors {
+		if results[i].Status == AnchorFailed {
+			continue
+		}
+		if existed[anchor.Hash] {
+			// The original txID/block are not part of the response; GetAnchor has them
+			results[i].Status = AnchorExisted
+			continue
+		}
+		results[i].Status = AnchorCreated
+		results[i].TxID = txID
+		results[i].BlockNumber = blockNum
+		anchor.TxID = txID
+		anchor.BlockNumber = blockNum
+		anchor.Timestamp = now
+	}
+
+	c.logger.Info("Anchor batch committed", "created", len(args)-len(existing), "block", blockNum, "tx_id", txID)
+	return results, nil
 }
 
 func (c *RealFabricClient) GetAnchor(ctx context.Context, hash string) (*domain.Anchor, error) {
-	return nil, errors.New("not implemented")
+	return c.evaluateAnchor("GetAnchor", hash)
+}
+
+// GetAnchorByTxID looks up an anchor by the Fabric transaction ID that created it.
+func (c *RealFabricClient) GetAnchorByTxID(ctx context.Context, txID string) (*domain.Anchor, error) {
+	return c.evaluateAnchor("GetAnchorByTxID", txID)
+}
+
+// evaluateAnchor runs a read-only anchor query against the chaincode.
+func (c *RealFabricClient) evaluateAnchor(name, key string) (*domain.Anchor, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+
+	result, err := c.contract.EvaluateTransaction(name, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anchor %s: %w", key, classifyChaincodeError(err))
+	}
+
+	var anchor domain.Anchor
+	if err := json.Unmarshal(result, &anchor); err != nil {
+		return nil, fmt.Errorf("failed to decode anchor: %w", err)
+	}
+	if anchor.IsExpired(time.Now()) {
+		return nil, fmt.Errorf("anchor %w: %s", ErrExpired, anchor.Hash)
+	}
+	return &anchor, nil
+}
+
+// FindAnchorsByPrefix queries the chaincode for anchors whose hash starts with prefix.
+func (c *RealFabricClient) FindAnchorsByPrefix(ctx context.Context, prefix string, limit int) ([]domain.Anchor, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+	if err := validatePrefixQuery(prefix, limit); err != nil {
+		return nil, err
+	}
+
+	result, err := c.contract.EvaluateTransaction("FindAnchorsByPrefix", prefix, strconv.Itoa(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find anchors: %w", err)
+	}
+
+	var anchors []domain.Anchor
+	if err := json.Unmarshal(result, &anchors); err != nil {
+		return nil, fmt.Errorf("failed to decode anchors: %w", err)
+	}
+	if anchors == nil {
+		anchors = []domain.Anchor{}
+	}
+	return anchors, nil
+}
+
+// VerifyAnchors fetches all hashes with a single GetAnchors query; the chaincode
+// returns the anchors it found and omits the others.
+func (c *RealFabricClient) VerifyAnchors(ctx context.Context, hashes []string) (map[string]AnchorVerification, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+
+	payload, err := json.Marshal(hashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal hashes: %w", err)
+	}
+	result, err := c.contract.EvaluateTransaction("GetAnchors", string(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify anchors: %w", err)
+	}
+
+	var found []domain.Anchor
+	if err := json.Unmarshal(result, &found); err != nil {
+		return nil, fmt.Errorf("failed to decode anchors: %w", err)
+	}
+
+	now := time.Now()
+	results := make(map[string]AnchorVerification, len(hashes))
+	for _, hash := range hashes {
+		results[hash] = AnchorVerification{}
+	}
+	for i := range found {
+		if _, requested := results[found[i].Hash]; requested {
+			results[found[i].Hash] = verificationOf(&found[i], now)
+		}
+	}
+	return results, nil
+}
+
+// ListAnchors queries one page of anchors ordered by block number from the chaincode.
+func (c *RealFabricClient) ListAnchors(ctx context.Context, opts ListOptions) (*AnchorPage, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+	if err := validateListOptions(opts); err != nil {
+		return nil, err
+	}
+
+	return c.evaluateAnchorPage("ListAnchors", strconv.Itoa(opts.Limit), opts.Cursor)
+}
+
+// QueryAnchors queries the anchors matching filter from the chaincode.
+// Open bounds are passed as empty strings.
+func (c *RealFabricClient) QueryAnchors(ctx context.Context, filter AnchorFilter) ([]domain.Anchor, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 4)
+	if filter.FromBlock != 0 {
+		args[0] = strconv.FormatUint(filter.FromBlock, 10)
+	}
+	if filter.ToBlock != 0 {
+		args[1] = strconv.FormatUint(filter.ToBlock, 10)
+	}
+	if !filter.FromTime.IsZero() {
+		args[2] = filter.FromTime.UTC().Format(time.RFC3339Nano)
+	}
+	if !filter.ToTime.IsZero() {
+		args[3] = filter.ToTime.UTC().Format(time.RFC3339Nano)
+	}
+
+	result, err := c.contract.EvaluateTransaction("QueryAnchors", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query anchors: %w", err)
+	}
+
+	anchors := []domain.Anchor{}
+	if err := json.Unmarshal(result, &anchors); err != nil {
+		return nil, fmt.Errorf("failed to decode anchors: %w", err)
+	}
+	if anchors == nil {
+		anchors = []domain.Anchor{}
+	}
+	return anchors, nil
+}
+
+// GetAnchorsByIssuer queries one page of the anchors created by issuerDID from the chaincode.
+func (c *RealFabricClient) GetAnchorsByIssuer(ctx context.Context, issuerDID string, opts ListOptions) (*AnchorPage, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+	if issuerDID == "" {
+		return nil, fmt.Errorf("issuer DID is required: %w", ErrValidation)
+	}
+	if err := validateListOptions(opts); err != nil {
+		return nil, err
+	}
+
+	return c.evaluateAnchorPage("GetAnchorsByIssuer", issuerDID, strconv.Itoa(opts.Limit), opts.Cursor)
+}
+
+// evaluateAnchorPage runs a read-only query returning a page of anchors.
+func (c *RealFabricClient) evaluateAnchorPage(name string, args ...string) (*AnchorPage, error) {
+	result, err := c.contract.EvaluateTransaction(name, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", name, err)
+	}
+
+	var page AnchorPage
+	if err := json.Unmarshal(result, &page); err != nil {
+		return nil, fmt.Errorf("failed to decode anchor page: %w", err)
+	}
+	if page.Items == nil {
+		page.Items = []domain.Anchor{}
+	}
+	return &page, nil
 }
 
 func (c *RealFabricClient) VerifyAnchor(ctx context.Context, hash string) bool {
-	return false
+	_, err := c.GetAnchor(ctx, hash)
+	return err == nil
+}
+
+// TombstoneAnchor submits the erasure of an anchor's metadata and issuer and waits for the commit.
+func (c *RealFabricClient) TombstoneAnchor(ctx context.Context, hash, reason string) error {
+	if reason == "" {
+		return fmt.Errorf("tombstone reason is required: %w", ErrValidation)
+	}
+
+	txID, blockNum, err := c.submitAndWait(ctx, "TombstoneAnchor", hash, reason, time.Now().UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("Anchor tombstoned", "hash", hash, "block", blockNum, "tx_id", txID)
+	return nil
+}
+
+// RevokeAnchor submits the revocation of an anchor and waits for the commit.
+func (c *RealFabricClient) RevokeAnchor(ctx context.Context, hash, reason string) error {
+	txID, blockNum, err := c.submitAndWait(ctx, "RevokeAnchor", hash, reason, time.Now().UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("Anchor revoked", "hash", hash, "block", blockNum, "tx_id", txID)
+	return nil
+}
+
+// SubscribeAnchors streams anchors from AnchorCreated chaincode events, including writes
+// made by other clients. Block number and transaction ID are taken from the event.
+func (c *RealFabricClient) SubscribeAnchors(ctx context.Context) (<-chan domain.Anchor, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+
+	events, err := c.contract.ChaincodeEvents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to chaincode events: %w", err)
+	}
+
+	out := make(chan domain.Anchor, anchorSubscriberBuffer)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if event.EventName != anchorCreatedEvent {
+					continue
+				}
+
+				var anchor domain.Anchor
+				if err := json.Unmarshal(event.Payload, &anchor); err != nil {
+					c.logger.Warn("Ignoring malformed chaincode event", "event", event.EventName, "tx_id", event.TransactionID, "err", err)
+					continue
+				}
+				anchor.BlockNumber = event.BlockNumber
+				anchor.TxID = event.TransactionID
+
+				select {
+				case out <- anchor:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
 }
 
 func (c *RealFabricClient) CreateDid(ctx context.Context, didDoc *domain.DIDDocument) error {
-	return errors.New("not implemented")
+	now := time.Now().UTC()
+	didDoc.Created = now
+	didDoc.Updated = now
+
+	payload, err := json.Marshal(didDoc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal DID document: %w", err)
+	}
+
+	if _, _, err := c.submitAndWait(ctx, "CreateDid", didDoc.ID, string(payload)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateDid submits a replacement document for an existing DID. The chaincode keeps
+// the original Created time, and refuses the update as already existing once the DID
+// has moved past expectedVersion; Updated is set here so it matches the submission.
+func (c *RealFabricClient) UpdateDid(ctx context.Context, didDoc *domain.DIDDocument, expectedVersion uint64) error {
+	didDoc.Updated = time.Now().UTC()
+
+	payload, err := json.Marshal(didDoc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal DID document: %w", err)
+	}
+
+	if _, _, err := c.submitAndWait(ctx, "UpdateDid", didDoc.ID, string(payload), strconv.FormatUint(expectedVersion, 10)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeactivateDid submits the deactivation of a DID at expectedVersion and waits for the commit.
+func (c *RealFabricClient) DeactivateDid(ctx context.Context, did string, expectedVersion uint64) error {
+	txID, blockNum, err := c.submitAndWait(ctx, "DeactivateDid", did, time.Now().UTC().Format(time.RFC3339Nano), strconv.FormatUint(expectedVersion, 10))
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("DID deactivated", "did", did, "block", blockNum, "tx_id", txID)
+	return nil
+}
+
+func (c *RealFabricClient) GetStatusList(ctx context.Context, id string) (*domain.StatusList, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+
+	result, err := c.contract.EvaluateTransaction("GetStatusList", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status list %s: %w", id, classifyChaincodeError(err))
+	}
+
+	var list domain.StatusList
+	if err := json.Unmarshal(result, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode status list: %w", err)
+	}
+	return &list, nil
+}
+
+// SaveStatusList submits the status list and waits for the commit.
+func (c *RealFabricClient) SaveStatusList(ctx context.Context, list *domain.StatusList) error {
+	payload, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to marshal status list: %w", err)
+	}
+
+	txID, blockNum, err := c.submitAndWait(ctx, "SaveStatusList", list.ID, string(payload))
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("Status list saved", "id", list.ID, "block", blockNum, "tx_id", txID)
+	return nil
+}
+
+// SaveWebhook submits the webhook registration and waits for the commit.
+func (c *RealFabricClient) SaveWebhook(ctx context.Context, hook *domain.Webhook) error {
+	payload, err := json.Marshal(hook)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook: %w", err)
+	}
+
+	txID, blockNum, err := c.submitAndWait(ctx, "SaveWebhook", hook.ID, string(payload))
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("Webhook saved", "id", hook.ID, "block", blockNum, "tx_id", txID)
+	return nil
+}
+
+func (c *RealFabricClient) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+
+	result, err := c.contract.EvaluateTransaction("ListWebhooks")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	var hooks []domain.Webhook
+	if err := json.Unmarshal(result, &hooks); err != nil {
+		return nil, fmt.Errorf("failed to decode webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// DeleteWebhook submits the removal of a webhook registration and waits for the commit.
+func (c *RealFabricClient) DeleteWebhook(ctx context.Context, id string) error {
+	txID, blockNum, err := c.submitAndWait(ctx, "DeleteWebhook", id)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("Webhook deleted", "id", id, "block", blockNum, "tx_id", txID)
+	return nil
 }
 
 func (c *RealFabricClient) GetDid(ctx context.Context, did string) (*domain.DIDDocument, error) {
-	return nil, errors.New("not implemented")
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+
+	result, err := c.contract.EvaluateTransaction("GetDid", did)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DID %s: %w", did, classifyChaincodeError(err))
+	}
+
+	var doc domain.DIDDocument
+	if err := json.Unmarshal(result, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode DID document: %w", err)
+	}
+	return &doc, nil
+}
+
+// ListDids queries one page of DID documents ordered by creation time from the chaincode.
+func (c *RealFabricClient) ListDids(ctx context.Context, opts DidListOptions) (*DidPage, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+	if err := validateListOptions(opts.ListOptions); err != nil {
+		return nil, err
+	}
+
+	result, err := c.contract.EvaluateTransaction("ListDids", strconv.Itoa(opts.Limit), opts.Cursor, opts.Controller)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DIDs: %w", err)
+	}
+
+	var page DidPage
+	if err := json.Unmarshal(result, &page); err != nil {
+		return nil, fmt.Errorf("failed to decode DID page: %w", err)
+	}
+	if page.Items == nil {
+		page.Items = []domain.DIDDocument{}
+	}
+	return &page, nil
+}
+
+// Ping evaluates a one-item DID listing, which needs the gateway, a peer and the
+// chaincode to answer. Evaluations take no context, so a hung gateway is
+// abandoned when ctx is done.
+func (c *RealFabricClient) Ping(ctx context.Context) error {
+	if c.closed.Load() {
+		return ErrClientClosed
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.contract.EvaluateTransaction("ListDids", "1", "", "")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("%w: gateway: %w", ErrTransient, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: gateway did not answer: %w", ErrTransient, ctx.Err())
+	}
 }
 
-func (c *RealFabricClient) GetStats() map[string]interface{} {
-	return map[string]interface{}{"mode": "fabric-real"}
+func (c *RealFabricClient) GetStats() Stats {
+	return Stats{Mode: "fabric-real"}
 }
 
+// Close marks the client closed; subsequent operations return ErrClientClosed.
 func (c *RealFabricClient) Close() error {
+	c.closed.Store(true)
 	return nil
 }
@@ -0,0 +1,377 @@
+//go:build fabric
+
+package fabric
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/domain"
+)
+
+type fakeCommit struct {
+	txID   string
+	status *commitStatus
+	delay  time.Duration
+}
+
+func (f *fakeCommit) TransactionID() string { return f.txID }
+
+func (f *fakeCommit) Status(ctx context.Context) (*commitStatus, error) {
+	select {
+	case <-time.After(f.delay):
+		return f.status, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type fakeContract struct {
+	commit    *fakeCommit
+	result    []byte
+	submitted []string
+	events    chan *chaincodeEvent
+	evaluate  func(name string, args ...string) ([]byte, error)
+	submitErr error // endorsement failure returned by SubmitAsync
+}
+
+func (f *fakeContract) SubmitAsync(name string, args ...string) ([]byte, fabricCommit, error) {
+	f.submitted = append(f.submitted, name)
+	if f.submitErr != nil {
+		return nil, nil, f.submitErr
+	}
+	return f.result, f.commit, nil
+}
+
+func (f *fakeContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	if f.evaluate != nil {
+		return f.evaluate(name, args...)
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeContract) ChaincodeEvents(ctx context.Context) (<-chan *chaincodeEvent, error) {
+	return f.events, nil
+}
+
+func TestRealClient_CreateAnchorWaitsForCommit(t *testing.T) {
+	contract := &fakeContract{commit: &fakeCommit{
+		txID:   "fabric-tx-1",
+		status: &commitStatus{BlockNumber: 4711, Successful: true},
+		delay:  10 * time.Millisecond,
+	}}
+	client := newRealClientWithContract(contract)
+
+	anchor := &domain.Anchor{Hash: "abc"}
+	txID, block, err := client.CreateAnchor(context.Background(), anchor)
+	if err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	if txID != "fabric-tx-1" || block != 4711 {
+		t.Errorf("expected tx fabric-tx-1 in block 4711, got %s in %d", txID, block)
+	}
+	if anchor.BlockNumber != 4711 {
+		t.Errorf("anchor block number not populated from commit: %d", anchor.BlockNumber)
+	}
+}
+
+func TestRealClient_CreateAnchorFailedCommit(t *testing.T) {
+	contract := &fakeContract{commit: &fakeCommit{
+		txID:   "fabric-tx-2",
+		status: &commitStatus{Successful: false, Code: 11},
+	}}
+	client := newRealClientWithContract(contract)
+
+	if _, _, err := client.CreateAnchor(context.Background(), &domain.Anchor{Hash: "abc"}); err == nil {
+		t.Fatal("expected error for unsuccessful commit")
+	}
+}
+
+func TestRealClient_CommitTimeout(t *testing.T) {
+	contract := &fakeContract{commit: &fakeCommit{
+		txID:   "fabric-tx-3",
+		status: &commitStatus{Successful: true},
+		delay:  time.Hour,
+	}}
+	client := newRealClientWithContract(contract)
+	client.commitTimeout = 20 * time.Millisecond
+
+	_, _, err := client.CreateAnchor(context.Background(), &domain.Anchor{Hash: "abc"})
+	if !IsTransient(err) {
+		t.Fatalf("expected transient timeout error, got %v", err)
+	}
+}
+
+func TestRealClient_SubscribeAnchors(t *testing.T) {
+	events := make(chan *chaincodeEvent, 3)
+	client := newRealClientWithContract(&fakeContract{events: events})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub, err := client.SubscribeAnchors(ctx)
+	if err != nil {
+		t.Fatalf("SubscribeAnchors failed: %v", err)
+	}
+
+	payload, _ := json.Marshal(domain.Anchor{Hash: "external"})
+	events <- &chaincodeEvent{EventName: "DidCreated", Payload: []byte(`{}`)}
+	events <- &chaincodeEvent{EventName: anchorCreatedEvent, BlockNumber: 99, TransactionID: "ext-tx", Payload: payload}
+
+	select {
+	case got := <-sub:
+		if got.Hash != "external" || got.BlockNumber != 99 || got.TxID != "ext-tx" {
+			t.Errorf("unexpected anchor %+v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for anchor event")
+	}
+}
+
+func TestRealClient_UseAfterClose(t *testing.T) {
+	client := newRealClientWithContract(&fakeContract{commit: &fakeCommit{status: &commitStatus{Successful: true}}})
+
+	client.Close()
+	client.Close()
+
+	if _, _, err := client.CreateAnchor(context.Background(), &domain.Anchor{Hash: "abc"}); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed, got %v", err)
+	}
+	if _, err := client.GetDid(context.Background(), "did:ewallet:1"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed, got %v", err)
+	}
+}
+
+func TestRealClient_Ping(t *testing.T) {
+	var evaluate func(name string, args ...string) ([]byte, error)
+	client := newRealClientWithContract(&fakeContract{evaluate: func(name string, args ...string) ([]byte, error) {
+		return evaluate(name, args...)
+	}})
+
+	evaluate = func(name string, args ...string) ([]byte, error) {
+		return []byte(`{"Items":[],"Total":0}`), nil
+	}
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("expected a healthy gateway, got %v", err)
+	}
+
+	evaluate = func(name string, args ...string) ([]byte, error) {
+		return nil, errors.New("connection refused")
+	}
+	if err := client.Ping(context.Background()); !errors.Is(err, ErrTransient) {
+		t.Errorf("expected a transient error, got %v", err)
+	}
+
+	// A hung gateway is abandoned when the probe times out
+	release := make(chan struct{})
+	defer close(release)
+	evaluate = func(name string, args ...string) ([]byte, error) {
+		<-release
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := client.Ping(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the probe deadline, got %v", err)
+	}
+}
+
+func TestRealClient_ListDids(t *testing.T) {
+	var gotArgs []string
+	contract := &fakeContract{evaluate: func(name string, args ...string) ([]byte, error) {
+		if name != "ListDids" {
+			t.Fatalf("unexpected transaction %s", name)
+		}
+		gotArgs = args
+		if args[2] == "did:ewallet:nobody" {
+			return []byte(`{"Items":null,"Total":0}`), nil
+		}
+		return json.Marshal(DidPage{
+			Items:      []domain.DIDDocument{{ID: "did:ewallet:1", Controller: args[2]}},
+			NextCursor: "next",
+			Total:      3,
+		})
+	}}
+	client := newRealClientWithContract(contract)
+	ctx := context.Background()
+
+	page, err := client.ListDids(ctx, DidListOptions{ListOptions: ListOptions{Limit: 1, Cursor: "c"}, Controller: "did:ewallet:alice"})
+	if err != nil {
+		t.Fatalf("ListDids failed: %v", err)
+	}
+	if strings.Join(gotArgs, "|") != "1|c|did:ewallet:alice" {
+		t.Errorf("unexpected chaincode args %q", gotArgs)
+	}
+	if len(page.Items) != 1 || page.Items[0].Controller != "did:ewallet:alice" || page.NextCursor != "next" || page.Total != 3 {
+		t.Errorf("unexpected page %+v", page)
+	}
+
+	empty, err := client.ListDids(ctx, DidListOptions{ListOptions: ListOptions{Limit: 1}, Controller: "did:ewallet:nobody"})
+	if err != nil {
+		t.Fatalf("ListDids failed: %v", err)
+	}
+	if empty.Items == nil || len(empty.Items) != 0 {
+		t.Errorf("expected non-nil empty items, got %#v", empty.Items)
+	}
+
+	if _, err := client.ListDids(ctx, DidListOptions{}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation for zero limit, got %v", err)
+	}
+}
+
+func TestRealClient_GetAnchorsByIssuer(t *testing.T) {
+	var gotArgs []string
+	contract := &fakeContract{evaluate: func(name string, args ...string) ([]byte, error) {
+		if name != "GetAnchorsByIssuer" {
+			t.Fatalf("unexpected transaction %s", name)
+		}
+		gotArgs = args
+		if args[0] == "did:ewallet:nobody" {
+			return []byte(`{"Items":null,"Total":0}`), nil
+		}
+		return json.Marshal(AnchorPage{Items: []domain.Anchor{{Hash: "h1", IssuerDID: args[0], BlockNumber: 7}}, Total: 1})
+	}}
+	client := newRealClientWithContract(contract)
+	ctx := context.Background()
+
+	page, err := client.GetAnchorsByIssuer(ctx, "did:ewallet:a", ListOptions{Limit: 5, Cursor: "3"})
+	if err != nil {
+		t.Fatalf("GetAnchorsByIssuer failed: %v", err)
+	}
+	if strings.Join(gotArgs, "|") != "did:ewallet:a|5|3" {
+		t.Errorf("unexpected chaincode args %q", gotArgs)
+	}
+	if len(page.Items) != 1 || page.Items[0].Hash != "h1" || page.Items[0].BlockNumber != 7 {
+		t.Errorf("unexpected page %+v", page)
+	}
+
+	empty, err := client.GetAnchorsByIssuer(ctx, "did:ewallet:nobody", ListOptions{Limit: 5})
+	if err != nil {
+		t.Fatalf("GetAnchorsByIssuer failed: %v", err)
+	}
+	if empty.Items == nil || len(empty.Items) != 0 {
+		t.Errorf("expected non-nil empty items, got %#v", empty.Items)
+	}
+
+	if _, err := client.GetAnchorsByIssuer(ctx, "", ListOptions{Limit: 5}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation for empty issuer, got %v", err)
+	}
+}
+
+func TestRealClient_QueryAnchors(t *testing.T) {
+	var gotArgs []string
+	contract := &fakeContract{evaluate: func(name string, args ...string) ([]byte, error) {
+		gotArgs = args
+		return []byte(`[{"hash":"h2","blockNumber":2}]`), nil
+	}}
+	client := newRealClientWithContract(contract)
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	anchors, err := client.QueryAnchors(context.Background(), AnchorFilter{FromBlock: 2, FromTime: from})
+	if err != nil {
+		t.Fatalf("QueryAnchors failed: %v", err)
+	}
+	if strings.Join(gotArgs, "|") != "2||2024-03-01T00:00:00Z|" {
+		t.Errorf("unexpected chaincode args %q", gotArgs)
+	}
+	if len(anchors) != 1 || anchors[0].Hash != "h2" {
+		t.Errorf("unexpected anchors %+v", anchors)
+	}
+
+	if _, err := client.QueryAnchors(context.Background(), AnchorFilter{FromBlock: 3, ToBlock: 1}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation for inverted range, got %v", err)
+	}
+}
+
+func TestRealClient_CreateAnchors(t *testing.T) {
+	contract := &fakeContract{
+		commit: &fakeCommit{txID: "batch-tx", status: &commitStatus{BlockNumber: 12, Successful: true}},
+		result: []byte(`["old"]`),
+	}
+	client := newRealClientWithContract(contract)
+
+	results, err := client.CreateAnchors(context.Background(), []*domain.Anchor{
+		{Hash: "new"}, {Hash: "old"}, {Hash: "new"},
+	})
+	if err != nil {
+		t.Fatalf("CreateAnchors failed: %v", err)
+	}
+	if len(contract.submitted) != 1 || contract.submitted[0] != "CreateAnchors" {
+		t.Errorf("expected one CreateAnchors submission, got %v", contract.submitted)
+	}
+	if results[0].Status != AnchorCreated || results[0].TxID != "batch-tx" || results[0].BlockNumber != 12 {
+		t.Errorf("unexpected result for new anchor: %+v", results[0])
+	}
+	if results[1].Status != AnchorExisted {
+		t.Errorf("expected existing anchor to be reported, got %+v", results[1])
+	}
+	if results[2].Status != AnchorFailed || !errors.Is(results[2].Err, ErrValidation) {
+		t.Errorf("expected duplicate to fail validation, got %+v", results[2])
+	}
+}
+
+func TestRealClient_DeactivateDid(t *testing.T) {
+	contract := &fakeContract{commit: &fakeCommit{txID: "deact-tx", status: &commitStatus{BlockNumber: 3, Successful: true}}}
+	client := newRealClientWithContract(contract)
+
+	if err := client.DeactivateDid(context.Background(), "did:ewallet:d", 1); err != nil {
+		t.Fatalf("DeactivateDid failed: %v", err)
+	}
+	if len(contract.submitted) != 1 || contract.submitted[0] != "DeactivateDid" {
+		t.Errorf("expected a DeactivateDid submission, got %v", contract.submitted)
+	}
+
+	client.Close()
+	if err := client.DeactivateDid(context.Background(), "did:ewallet:d", 1); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed, got %v", err)
+	}
+}
+
+func TestRealClient_ClassifiesChaincodeErrors(t *testing.T) {
+	ctx := context.Background()
+
+	contract := &fakeContract{submitErr: errors.New("chaincode response 500, the DID did:ewallet:a already exists")}
+	client := newRealClientWithContract(contract)
+	if err := client.CreateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:a"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists, got %v", err)
+	}
+
+	contract.submitErr = errors.New("chaincode response 500, the anchor abc already exists")
+	if _, _, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: "abc"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists, got %v", err)
+	}
+
+	contract.submitErr = errors.New("chaincode response 500, invalid hash: xyz")
+	if _, _, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: "xyz"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+
+	contract.evaluate = func(name string, args ...string) ([]byte, error) {
+		return nil, errors.New("the asset " + args[0] + " does not exist")
+	}
+	if _, err := client.GetAnchor(ctx, "abc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetAnchor: expected ErrNotFound, got %v", err)
+	}
+	if _, err := client.GetDid(ctx, "did:ewallet:a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetDid: expected ErrNotFound, got %v", err)
+	}
+	if _, err := client.GetStatusList(ctx, "creds"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetStatusList: expected ErrNotFound, got %v", err)
+	}
+
+	// Anything else stays unclassified
+	contract.submitErr = errors.New("connection refused")
+	if _, _, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: "abc"}); errors.Is(err, ErrAlreadyExists) || errors.Is(err, ErrNotFound) || errors.Is(err, ErrValidation) {
+		t.Errorf("expected an unclassified error, got %v", err)
+	}
+	contract.evaluate = func(name string, args ...string) ([]byte, error) {
+		return nil, errors.New("connection refused")
+	}
+	if _, err := client.GetStatusList(ctx, "creds"); errors.Is(err, ErrNotFound) {
+		t.Errorf("GetStatusList: expected an unclassified error, got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package fabric
+
+import (
+	"context"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"fabric-resolver/internal/domain"
+)
+
+// RetryConfig controls how RetryingLedgerClient retries write operations.
+type RetryConfig struct {
+	MaxAttempts int           // Total attempts including the first one (<= 1 disables retries)
+	BaseDelay   time.Duration // Delay before the first retry; doubled for every subsequent retry
+	MaxDelay    time.Duration // Upper bound for a single backoff delay
+}
+
+// DefaultRetryConfig returns the backoff settings used when only MaxAttempts is configured.
+func DefaultRetryConfig(maxAttempts int) RetryConfig {
+	return RetryConfig{
+		MaxAttempts: maxAttempts,
+		BaseDelay:   50 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+	}
+}
+
+// RetryingLedgerClient decorates a LedgerClient and retries write operations
+// that fail with a transient error (see IsTransient), using exponential backoff with jitter.
+// Reads are passed straight through to the inner client.
+type RetryingLedgerClient struct {
+	inner  LedgerClient
+	cfg    RetryConfig
+	logger *slog.Logger
+}
+
+// NewRetryingLedgerClient wraps inner with retry behavior described by cfg.
+// Retries are logged to logger; nil uses slog.Default().
+func NewRetryingLedgerClient(inner LedgerClient, cfg RetryConfig, logger *slog.Logger) *RetryingLedgerClient {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = DefaultRetryConfig(cfg.MaxAttempts).BaseDelay
+	}
+	if cfg.MaxDelay < cfg.BaseDelay {
+		cfg.MaxDelay = cfg.BaseDelay
+	}
+
+	return &RetryingLedgerClient{
+		inner:  inner,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// retry runs op until it succeeds, fails with a non-transient error,
+// runs out of attempts or ctx is cancelled while waiting between attempts.
+func (c *RetryingLedgerClient) retry(ctx context.Context, name string, op func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = op()
+		if err == nil || !IsTransient(err) || attempt >= c.cfg.MaxAttempts {
+			return err
+		}
+
+		delay := c.backoff(attempt)
+		c.logger.Warn("Ledger operation failed, retrying", "op", name, "attempt", attempt, "max_attempts", c.cfg.MaxAttempts, "delay", delay, "err", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt using "full jitter":
+// a random duration in [0, min(MaxDelay, BaseDelay*2^(attempt-1))].
+func (c *RetryingLedgerClient) backoff(attempt int) time.Duration {
+	ceiling := c.cfg.BaseDelay
+	for i := 1; i < attempt && ceiling < c.cfg.MaxDelay; i++ {
+		ceiling *= 2
+	}
+	if ceiling > c.cfg.MaxDelay {
+		ceiling = c.cfg.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+func (c *RetryingLedgerClient) CreateAnchor(ctx context.Context, anchor *domain.Anchor) (string, uint64, error) {
+	var txID string
+	var blockNum uint64
+	err := c.retry(ctx, "CreateAnchor", func() error {
+		var err error
+		txID, blockNum, err = c.inner.CreateAnchor(ctx, anchor)
+		return err
+	})
+	return txID, blockNum, err
+}
+
+func (c *RetryingLedgerClient) CreateAnchors(ctx context.Context, anchors []*domain.Anchor) ([]AnchorResult, error) {
+	var results []AnchorResult
+	err := c.retry(ctx, "CreateAnchors", func() error {
+		var err error
+		results, err = c.inner.CreateAnchors(ctx, anchors)
+		return err
+	})
+	return results, err
+}
+
+func (c *RetryingLedgerClient) GetAnchor(ctx context.Context, hash string) (*domain.Anchor, error) {
+	return c.inner.GetAnchor(ctx, hash)
+}
+
+func (c *RetryingLedgerClient) GetAnchorByTxID(ctx context.Context, txID string) (*domain.Anchor, error) {
+	return c.inner.GetAnchorByTxID(ctx, txID)
+}
+
+func (c *RetryingLedgerClient) VerifyAnchor(ctx context.Context, hash string) bool {
+	return c.inner.VerifyAnchor(ctx, hash)
+}
+
+func (c *RetryingLedgerClient) VerifyAnchors(ctx context.Context, hashes []string) (map[string]AnchorVerification, error) {
+	return c.inner.VerifyAnchors(ctx, hashes)
+}
+
+func (c *RetryingLedgerClient) ListAnchors(ctx context.Context, opts ListOptions) (*AnchorPage, error) {
+	return c.inner.ListAnchors(ctx, opts)
+}
+
+func (c *RetryingLedgerClient) QueryAnchors(ctx context.Context, filter AnchorFilter) ([]domain.Anchor, error) {
+	return c.inner.QueryAnchors(ctx, filter)
+}
+
+func (c *RetryingLedgerClient) FindAnchorsByPrefix(ctx context.Context, prefix string, limit int) ([]domain.Anchor, error) {
+	return c.inner.FindAnchorsByPrefix(ctx, prefix, limit)
+}
+
+func (c *RetryingLedgerClient) GetAnchorsByIssuer(ctx context.Context, issuerDID string, opts ListOptions) (*AnchorPage, error) {
+	return c.inner.GetAnchorsByIssuer(ctx, issuerDID, opts)
+}
+
+func (c *RetryingLedgerClient) TombstoneAnchor(ctx context.Context, hash, reason string) error {
+	return c.retry(ctx, "TombstoneAnchor", func() error {
+		return c.inner.TombstoneAnchor(ctx, hash, reason)
+	})
+}
+
+func (c *RetryingLedgerClient) RevokeAnchor(ctx context.Context, hash, reason string) error {
+	return c.retry(ctx, "RevokeAnchor", func() error {
+		return c.inner.RevokeAnchor(ctx, hash, reason)
+	})
+}
+
+func (c *RetryingLedgerClient) SubscribeAnchors(ctx context.Context) (<-chan domain.Anchor, error) {
+	return c.inner.SubscribeAnchors(ctx)
+}
+
+func (c *RetryingLedgerClient) CreateDid(ctx context.Context, didDoc *domain.DIDDocument) error {
+	return c.retry(ctx, "CreateDid", func() error {
+		return c.inner.CreateDid(ctx, didDoc)
+	})
+}
+
+func (c *RetryingLedgerClient) UpdateDid(ctx context.Context, didDoc *domain.DIDDocument, expectedVersion uint64) error {
+	return c.retry(ctx, "UpdateDid", func() error {
+		return c.inner.UpdateDid(ctx, didDoc, expectedVersion)
+	})
+}
+
+func (c *RetryingLedgerClient) DeactivateDid(ctx context.Context, did string, expectedVersion uint64) error {
+	return c.retry(ctx, "DeactivateDid", func() error {
+		return c.inner.DeactivateDid(ctx, did, expectedVersion)
+	})
+}
+
+func (c *RetryingLedgerClient) GetDid(ctx context.Context, did string) (*domain.DIDDocument, error) {
+	return c.inner.GetDid(ctx, did)
+}
+
+func (c *RetryingLedgerClient) ListDids(ctx context.Context, opts DidListOptions) (*DidPage, error) {
+	return c.inner.ListDids(ctx, opts)
+}
+
+func (c *RetryingLedgerClient) GetStatusList(ctx context.Context, id string) (*domain.StatusList, error) {
+	return c.inner.GetStatusList(ctx, id)
+}
+
+func (c *RetryingLedgerClient) SaveStatusList(ctx context.Context, list *domain.StatusList) error {
+	return c.retry(ctx, "SaveStatusList", func() error {
+		return c.inner.SaveStatusList(ctx, list)
+	})
+}
+
+func (c *RetryingLedgerClient) SaveWebhook(ctx context.Context, hook *domain.Webhook) error {
+	return c.retry(ctx, "SaveWebhook", func() error {
+		return c.inner.SaveWebhook(ctx, hook)
+	})
+}
+
+func (c *RetryingLedgerClient) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	return c.inner.ListWebhooks(ctx)
+}
+
+func (c *RetryingLedgerClient) DeleteWebhook(ctx context.Context, id string) error {
+	return c.retry(ctx, "DeleteWebhook", func() error {
+		return c.inner.DeleteWebhook(ctx, id)
+	})
+}
+
+// Ping is not retried, so probes report a failing backend promptly.
+func (c *RetryingLedgerClient) Ping(ctx context.Context) error {
+	return c.inner.Ping(ctx)
+}
+
+func (c *RetryingLedgerClient) GetStats() Stats {
+	return c.inner.GetStats()
+}
+
+func (c *RetryingLedgerClient) Close() error {
+	return c.inner.Close()
+}
+
+// Unwrap returns the client wrapped by c.
+func (c *RetryingLedgerClient) Unwrap() LedgerClient {
+	return c.inner
+}
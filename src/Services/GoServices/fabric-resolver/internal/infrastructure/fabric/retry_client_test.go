@@ -0,0 +1,123 @@
+package fabric
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/domain"
+)
+
+// flakyLedgerClient fails the first `failures` writes with err, then succeeds.
+type flakyLedgerClient struct {
+	LedgerClient
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *flakyLedgerClient) CreateAnchor(ctx context.Context, anchor *domain.Anchor) (string, uint64, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return "", 0, f.err
+	}
+	return "tx-ok", 1, nil
+}
+
+func (f *flakyLedgerClient) CreateDid(ctx context.Context, didDoc *domain.DIDDocument) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func testRetryConfig(maxAttempts int) RetryConfig {
+	return RetryConfig{MaxAttempts: maxAttempts, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain", errors.New("boom"), false},
+		{"transient", fmt.Errorf("persist: %w", ErrTransient), true},
+		{"already exists", fmt.Errorf("DID %w", ErrAlreadyExists), false},
+		{"not found", fmt.Errorf("DID %w", ErrNotFound), false},
+		{"validation", fmt.Errorf("bad: %w", ErrValidation), false},
+		{"cancelled", fmt.Errorf("%w: %w", ErrTransient, context.Canceled), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryingClient_RetriesTransientErrors(t *testing.T) {
+	inner := &flakyLedgerClient{failures: 2, err: fmt.Errorf("disk: %w", ErrTransient)}
+	client := NewRetryingLedgerClient(inner, testRetryConfig(3), discardLogger)
+
+	txID, _, err := client.CreateAnchor(context.Background(), &domain.Anchor{Hash: "h"})
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if txID != "tx-ok" {
+		t.Errorf("expected tx-ok, got %s", txID)
+	}
+	if inner.calls != 3 {
+		t.Errorf("expected 3 calls, got %d", inner.calls)
+	}
+}
+
+func TestRetryingClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	inner := &flakyLedgerClient{failures: 10, err: fmt.Errorf("disk: %w", ErrTransient)}
+	client := NewRetryingLedgerClient(inner, testRetryConfig(3), discardLogger)
+
+	err := client.CreateDid(context.Background(), &domain.DIDDocument{ID: "did:ewallet:1"})
+	if !errors.Is(err, ErrTransient) {
+		t.Fatalf("expected transient error, got %v", err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("expected 3 calls, got %d", inner.calls)
+	}
+}
+
+func TestRetryingClient_DoesNotRetryPermanentErrors(t *testing.T) {
+	for _, sentinel := range []error{ErrAlreadyExists, ErrValidation} {
+		inner := &flakyLedgerClient{failures: 10, err: fmt.Errorf("op: %w", sentinel)}
+		client := NewRetryingLedgerClient(inner, testRetryConfig(5), discardLogger)
+
+		err := client.CreateDid(context.Background(), &domain.DIDDocument{ID: "did:ewallet:1"})
+		if !errors.Is(err, sentinel) {
+			t.Fatalf("expected %v, got %v", sentinel, err)
+		}
+		if inner.calls != 1 {
+			t.Errorf("%v: expected 1 call, got %d", sentinel, inner.calls)
+		}
+	}
+}
+
+func TestRetryingClient_StopsOnContextCancel(t *testing.T) {
+	inner := &flakyLedgerClient{failures: 10, err: fmt.Errorf("disk: %w", ErrTransient)}
+	client := NewRetryingLedgerClient(inner, RetryConfig{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}, discardLogger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: "h"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if inner.calls != 1 {
+		t.Errorf("expected 1 call before cancellation, got %d", inner.calls)
+	}
+}
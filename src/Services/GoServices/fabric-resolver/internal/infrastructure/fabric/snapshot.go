@@ -0,0 +1,178 @@
+package fabric
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Snapshotter is implemented by ledger clients whose whole contents can be dumped,
+// restored and checked offline. Only the file ledger is: a Fabric ledger belongs to
+// the channel, and its peers keep it consistent.
+type Snapshotter interface {
+	// Export writes every record of the ledger to w, in the ledger file's format,
+	// and returns how many it wrote.
+	Export(ctx context.Context, w io.Writer) (int, error)
+
+	// Import loads a snapshot written by Export and returns how many records it held.
+	// The ledger must be empty unless replace is set, which discards its records first.
+	Import(ctx context.Context, r io.Reader, replace bool) (int, error)
+
+	// Verify checks that the records of the ledger are consistent with each other,
+	// reporting every inconsistency found.
+	Verify(ctx context.Context) error
+}
+
+// SnapshotterOf returns the Snapshotter behind client and the clients wrapping it.
+func SnapshotterOf(client LedgerClient) (Snapshotter, bool) {
+	for {
+		if s, ok := client.(Snapshotter); ok {
+			return s, true
+		}
+		wrapper, ok := client.(interface{ Unwrap() LedgerClient })
+		if !ok {
+			return nil, false
+		}
+		client = wrapper.Unwrap()
+	}
+}
+
+// Export writes the ledger as it is persisted, without the records of writes still queued.
+func (c *FileLedgerClient) Export(ctx context.Context, w io.Writer) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("context cancelled: %w", err)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return 0, ErrClientClosed
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(c.state); err != nil {
+		return 0, fmt.Errorf("failed to export ledger: %w", err)
+	}
+	return len(c.state.Records), nil
+}
+
+// Import replaces the records of the ledger with those of a snapshot in one write.
+// Snapshots of older formats are upgraded as the ledger file would be; inconsistent
+// snapshots are rejected whole. Imported anchors are not streamed to subscribers.
+func (c *FileLedgerClient) Import(ctx context.Context, r io.Reader, replace bool) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("context cancelled: %w", err)
+	}
+
+	var snapshot LedgerState
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return 0, fmt.Errorf("snapshot is not a ledger: %w: %w", err, ErrValidation)
+	}
+	if snapshot.Version > currentLedgerVersion {
+		return 0, fmt.Errorf("snapshot version %d is newer than supported version %d: %w", snapshot.Version, currentLedgerVersion, ErrValidation)
+	}
+	if snapshot.NextBlock == 0 {
+		snapshot.NextBlock = 1
+	}
+	if err := checkState(&snapshot); err != nil {
+		return 0, fmt.Errorf("snapshot is inconsistent: %w: %w", err, ErrValidation)
+	}
+
+	err := c.submit(ctx, "Import", func(state *LedgerState) (bool, error) {
+		if len(state.Records) > 0 && !replace {
+			return false, fmt.Errorf("ledger holds %d records: %w", len(state.Records), ErrAlreadyExists)
+		}
+		for key := range state.Records {
+			state.remove(key)
+		}
+		for key, record := range snapshot.Records {
+			state.put(key, record)
+		}
+		state.Version = currentLedgerVersion
+		state.NextBlock = max(state.NextBlock, snapshot.NextBlock)
+		return true, nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrAlreadyExists) || errors.Is(err, ErrClientClosed) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("failed to persist snapshot: %w", err)
+	}
+
+	c.logger.Info("Ledger imported", "records", len(snapshot.Records), "replaced", replace)
+	return len(snapshot.Records), nil
+}
+
+// Verify checks the persisted records of the ledger.
+func (c *FileLedgerClient) Verify(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return ErrClientClosed
+	}
+	return checkState(&c.state)
+}
+
+// checkState reports every record that contradicts its key, the ledger's counters
+// or another record: records stored under a key other than their own, unknown
+// docTypes, missing documents, and anchors sharing a block or transaction or
+// numbered at or past NextBlock. Problems are joined in key order.
+func checkState(state *LedgerState) error {
+	keys := make([]string, 0, len(state.Records))
+	for key := range state.Records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []error
+	report := func(key, format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf("record %q: %s", key, fmt.Sprintf(format, args...)))
+	}
+	blocks := make(map[uint64]string)
+	txIDs := make(map[string]string)
+	for _, key := range keys {
+		record := state.Records[key]
+		if record.Commitment != key {
+			report(key, "stored under another key than its commitment %q", record.Commitment)
+		}
+
+		switch record.DocType {
+		case "anchor":
+			if record.BlockNumber == 0 || record.BlockNumber >= state.NextBlock {
+				report(key, "block %d is outside the ledger's blocks 1 to %d", record.BlockNumber, state.NextBlock-1)
+			} else if other, ok := blocks[record.BlockNumber]; ok {
+				report(key, "shares block %d with %q", record.BlockNumber, other)
+			} else {
+				blocks[record.BlockNumber] = key
+			}
+			if record.TxID == "" {
+				report(key, "has no transaction id")
+			} else if other, ok := txIDs[record.TxID]; ok {
+				report(key, "shares transaction %s with %q", record.TxID, other)
+			} else {
+				txIDs[record.TxID] = key
+			}
+		case "did":
+			if record.DIDDoc == nil || record.DIDDoc.ID != key {
+				report(key, "has no DID document with its id")
+			}
+		case "statusList":
+			if record.StatusList == nil || statusListKey(record.StatusList.ID) != key {
+				report(key, "has no status list with its id")
+			}
+		case "webhook":
+			if record.Webhook == nil || webhookKey(record.Webhook.ID) != key {
+				report(key, "has no webhook with its id")
+			}
+		default:
+			report(key, "has unknown docType %q", record.DocType)
+		}
+	}
+	return errors.Join(problems...)
+}
@@ -0,0 +1,174 @@
+package fabric
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/domain"
+)
+
+func newSnapshotClient(t *testing.T) (*FileLedgerClient, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	client, err := NewFileLedgerClientWithLogger(path, discardLogger)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, path
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source, _ := newSnapshotClient(t)
+	hashes := []string{strings.Repeat("a", 64), strings.Repeat("b", 64)}
+	for _, hash := range hashes {
+		if _, _, err := source.CreateAnchor(ctx, &domain.Anchor{Hash: hash, IssuerDID: "did:ewallet:issuer"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := source.CreateDid(ctx, &domain.DIDDocument{ID: "did:ewallet:holder"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.SaveStatusList(ctx, &domain.StatusList{ID: "creds"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var snapshot bytes.Buffer
+	if n, err := source.Export(ctx, &snapshot); err != nil || n != 4 {
+		t.Fatalf("expected 4 records exported, got %d (%v)", n, err)
+	}
+
+	target, path := newSnapshotClient(t)
+	if n, err := target.Import(ctx, bytes.NewReader(snapshot.Bytes()), false); err != nil || n != 4 {
+		t.Fatalf("expected 4 records imported, got %d (%v)", n, err)
+	}
+	for _, hash := range hashes {
+		want, _ := source.GetAnchor(ctx, hash)
+		got, err := target.GetAnchor(ctx, hash)
+		if err != nil || got.TxID != want.TxID || got.BlockNumber != want.BlockNumber || got.IssuerDID != want.IssuerDID {
+			t.Errorf("expected anchor %s as exported, got %+v (%v)", hash, got, err)
+		}
+	}
+	if _, err := target.GetDid(ctx, "did:ewallet:holder"); err != nil {
+		t.Errorf("expected the DID to be imported: %v", err)
+	}
+	if err := target.Verify(ctx); err != nil {
+		t.Errorf("expected the imported ledger to verify: %v", err)
+	}
+
+	// New anchors continue after the imported blocks, and the import is on disk
+	_, block, err := target.CreateAnchor(ctx, &domain.Anchor{Hash: strings.Repeat("c", 64)})
+	if err != nil || block != 3 {
+		t.Errorf("expected the next anchor in block 3, got %d (%v)", block, err)
+	}
+	target.Close()
+	reopened, err := NewFileLedgerClientWithLogger(path, discardLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if stats := reopened.GetStats(); stats.Anchors != 3 || stats.DIDs != 1 {
+		t.Errorf("expected the import to be persisted, got %+v", stats)
+	}
+}
+
+func TestImport_RefusesNonEmptyLedgerUnlessReplacing(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newSnapshotClient(t)
+	if _, _, err := client.CreateAnchor(ctx, &domain.Anchor{Hash: strings.Repeat("d", 64)}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := `{"version":1,"nextBlock":2,"records":{"` + strings.Repeat("e", 64) + `":{"commitment":"` + strings.Repeat("e", 64) + `","docType":"anchor","blockNumber":1,"txId":"tx-1"}}}`
+
+	if _, err := client.Import(ctx, strings.NewReader(snapshot), false); !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("expected ErrAlreadyExists, got %v", err)
+	}
+	if _, err := client.GetAnchor(ctx, strings.Repeat("d", 64)); err != nil {
+		t.Fatalf("expected the refused import to leave the ledger alone: %v", err)
+	}
+
+	if _, err := client.Import(ctx, strings.NewReader(snapshot), true); err != nil {
+		t.Fatalf("expected the replacing import to succeed: %v", err)
+	}
+	if _, err := client.GetAnchor(ctx, strings.Repeat("d", 64)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the replaced anchor to be gone, got %v", err)
+	}
+	if got, err := client.GetAnchorByTxID(ctx, "tx-1"); err != nil || got.Hash != strings.Repeat("e", 64) {
+		t.Errorf("expected the imported anchor to be indexed by transaction, got %+v (%v)", got, err)
+	}
+}
+
+func TestImport_RejectsInconsistentSnapshots(t *testing.T) {
+	client, _ := newSnapshotClient(t)
+	for name, snapshot := range map[string]string{
+		"not JSON":      `anchors`,
+		"newer version": `{"version":99,"records":{}}`,
+		"shared block":  `{"version":1,"nextBlock":3,"records":{"a":{"commitment":"a","docType":"anchor","blockNumber":1,"txId":"t1"},"b":{"commitment":"b","docType":"anchor","blockNumber":1,"txId":"t2"}}}`,
+	} {
+		if _, err := client.Import(context.Background(), strings.NewReader(snapshot), true); !errors.Is(err, ErrValidation) {
+			t.Errorf("%s: expected ErrValidation, got %v", name, err)
+		}
+	}
+	if stats := client.GetStats(); stats.Anchors != 0 {
+		t.Errorf("expected nothing imported, got %d anchors", stats.Anchors)
+	}
+}
+
+func TestVerify_ReportsEveryInconsistency(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	ledger := `{"version":1,"nextBlock":3,"records":{
+		"a":{"commitment":"a","docType":"anchor","blockNumber":1,"txId":"t1"},
+		"b":{"commitment":"b","docType":"anchor","blockNumber":1,"txId":"t1"},
+		"c":{"commitment":"c","docType":"anchor","blockNumber":7,"txId":"t3"},
+		"d":{"commitment":"other","docType":"anchor","blockNumber":2,"txId":"t4"},
+		"did:ewallet:x":{"commitment":"did:ewallet:x","docType":"did"},
+		"e":{"commitment":"e","docType":"receipt"}
+	}}`
+	if err := os.WriteFile(path, []byte(ledger), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewFileLedgerClientWithLogger(path, discardLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	err = client.Verify(context.Background())
+	if err == nil {
+		t.Fatal("expected inconsistencies to be reported")
+	}
+	for _, want := range []string{
+		`record "b": shares block 1 with "a"`,
+		`record "b": shares transaction t1 with "a"`,
+		`record "c": block 7 is outside the ledger's blocks 1 to 2`,
+		`record "d": stored under another key than its commitment "other"`,
+		`record "did:ewallet:x": has no DID document with its id`,
+		`record "e": has unknown docType "receipt"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q among:\n%v", want, err)
+		}
+	}
+	if n := len(strings.Split(err.Error(), "\n")); n != 6 {
+		t.Errorf("expected 6 problems, got %d", n)
+	}
+}
+
+func TestSnapshotterOf_UnwrapsClients(t *testing.T) {
+	client, _ := newSnapshotClient(t)
+	wrapped := NewMetricsLedgerClient(NewRetryingLedgerClient(client, DefaultRetryConfig(3), discardLogger))
+
+	if s, ok := SnapshotterOf(wrapped); !ok || s != client {
+		t.Errorf("expected the file ledger behind the wrappers, got %v", s)
+	}
+	// A client that does not say what it wraps hides the file ledger
+	if _, ok := SnapshotterOf(struct{ LedgerClient }{client}); ok {
+		t.Error("expected no Snapshotter behind an opaque client")
+	}
+}
@@ -0,0 +1,69 @@
+package fabric
+
+import (
+	"context"
+	"sync"
+
+	"fabric-resolver/internal/domain"
+)
+
+// anchorSubscriberBuffer is the channel buffer per subscriber. Slow subscribers
+// that fall further behind than this miss events rather than blocking writers.
+const anchorSubscriberBuffer = 64
+
+// anchorBroadcaster fans out newly created anchors to subscribers.
+// It is used by ledger backends that emulate commit events from their own writes.
+type anchorBroadcaster struct {
+	mu   sync.Mutex
+	subs map[chan domain.Anchor]struct{}
+}
+
+func newAnchorBroadcaster() *anchorBroadcaster {
+	return &anchorBroadcaster{subs: make(map[chan domain.Anchor]struct{})}
+}
+
+// subscribe registers a new subscriber. The returned channel is closed when ctx is done.
+func (b *anchorBroadcaster) subscribe(ctx context.Context) <-chan domain.Anchor {
+	ch := make(chan domain.Anchor, anchorSubscriberBuffer)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+		b.mu.Unlock()
+	}()
+
+	return ch
+}
+
+// publish delivers a copy of anchor to every subscriber without blocking.
+func (b *anchorBroadcaster) publish(anchor domain.Anchor) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- anchor:
+		default:
+			// Subscriber is not keeping up; drop the event for it
+		}
+	}
+}
+
+// closeAll closes every subscriber channel, e.g. when the ledger client shuts down.
+func (b *anchorBroadcaster) closeAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
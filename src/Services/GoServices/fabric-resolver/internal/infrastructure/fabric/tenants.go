@@ -0,0 +1,195 @@
+package fabric
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// tenantIDPattern keeps tenant IDs usable as a directory name.
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// ValidTenantID reports whether id can name a tenant: up to 64 letters, digits,
+// '-' and '_', starting with a letter or digit.
+func ValidTenantID(id string) bool {
+	return tenantIDPattern.MatchString(id)
+}
+
+// TenantLedgerProvider opens the ledger of each tenant of a shared resolver on
+// first use, so tenants never see each other's records. A tenant's ledger is a
+// file ledger in tenants/<id>/ beside the file of the base configuration, with
+// the base's other settings, and exports the metrics of MetricsLedgerClient
+// labelled with the tenant. The provider is their prometheus.Collector.
+type TenantLedgerProvider struct {
+	base    Config
+	tenants map[string]bool
+	onOpen  func(tenant string, client LedgerClient)
+
+	mu      sync.Mutex
+	clients map[string]*MetricsLedgerClient // opened so far, guarded by mu
+	opening map[string]*sync.Mutex          // held while a tenant's ledger is opened, guarded by mu
+	closed  bool                            // guarded by mu
+}
+
+// NewTenantLedgerProvider returns the provider of the ledgers of tenants, which
+// need a file ledger.
+func NewTenantLedgerProvider(base Config, tenants []string) (*TenantLedgerProvider, error) {
+	if base.Mode != "" && base.Mode != "file" {
+		return nil, fmt.Errorf("tenant ledgers need LEDGER_MODE=file, not %s", base.Mode)
+	}
+	if base.FilePath == "" {
+		base.FilePath = "data/ledger.json"
+	}
+	p := &TenantLedgerProvider{
+		base:    base,
+		tenants: make(map[string]bool, len(tenants)),
+		clients: make(map[string]*MetricsLedgerClient),
+		opening: make(map[string]*sync.Mutex),
+	}
+	for _, tenant := range tenants {
+		if !ValidTenantID(tenant) {
+			return nil, fmt.Errorf("invalid tenant ID %q", tenant)
+		}
+		p.tenants[tenant] = true
+	}
+	return p, nil
+}
+
+// Hosts reports whether tenant is one of the provider's tenants.
+func (p *TenantLedgerProvider) Hosts(tenant string) bool {
+	return p.tenants[tenant]
+}
+
+// Path returns the ledger file of tenant.
+func (p *TenantLedgerProvider) Path(tenant string) string {
+	return filepath.Join(filepath.Dir(p.base.FilePath), "tenants", tenant, filepath.Base(p.base.FilePath))
+}
+
+// OnOpen has fn called with the ledger of each tenant once it is opened, e.g. to
+// subscribe to its events. It must be set before Ledger is first called.
+func (p *TenantLedgerProvider) OnOpen(fn func(tenant string, client LedgerClient)) {
+	p.onOpen = fn
+}
+
+// Ledger returns the ledger of tenant, opening it on first use. A ledger locked
+// by another process is waited for as OpenLedgerClient does, until ctx is done;
+// other tenants are served meanwhile. It fails with ErrUnknownTenant for a
+// tenant the provider does not host.
+func (p *TenantLedgerProvider) Ledger(ctx context.Context, tenant string) (LedgerClient, error) {
+	if !p.tenants[tenant] {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTenant, tenant)
+	}
+
+	client, opening, err := p.opened(tenant)
+	if client != nil || err != nil {
+		return client, err
+	}
+	opening.Lock()
+	defer opening.Unlock()
+	// Another request may have opened it while this one waited
+	if client, _, err := p.opened(tenant); client != nil || err != nil {
+		return client, err
+	}
+
+	cfg := p.base
+	cfg.Mode = "file"
+	cfg.FilePath = p.Path(tenant)
+	if cfg.Logger != nil {
+		cfg.Logger = cfg.Logger.With("tenant", tenant)
+	}
+	inner, err := OpenLedgerClient(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the ledger of tenant %s: %w", tenant, err)
+	}
+	metrics := NewTenantMetricsLedgerClient(inner, tenant)
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		metrics.Close()
+		return nil, ErrClientClosed
+	}
+	p.clients[tenant] = metrics
+	p.mu.Unlock()
+
+	if p.onOpen != nil {
+		p.onOpen(tenant, metrics)
+	}
+	return metrics, nil
+}
+
+// opened returns the ledger of tenant if it is open, or else the lock to hold
+// while opening it.
+func (p *TenantLedgerProvider) opened(tenant string) (LedgerClient, *sync.Mutex, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, nil, ErrClientClosed
+	}
+	if client, ok := p.clients[tenant]; ok {
+		return client, nil, nil
+	}
+	opening, ok := p.opening[tenant]
+	if !ok {
+		opening = &sync.Mutex{}
+		p.opening[tenant] = opening
+	}
+	return nil, opening, nil
+}
+
+// Describe implements prometheus.Collector. It describes nothing, which makes the
+// provider an unchecked collector, since tenants' series appear as their ledgers
+// are opened.
+func (p *TenantLedgerProvider) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector with the metrics of the ledgers opened
+// so far.
+func (p *TenantLedgerProvider) Collect(ch chan<- prometheus.Metric) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, client := range p.clients {
+		client.Collect(ch)
+	}
+}
+
+// Stats returns the stats of the ledgers opened so far, by tenant.
+func (p *TenantLedgerProvider) Stats() map[string]Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make(map[string]Stats, len(p.clients))
+	for tenant, client := range p.clients {
+		stats[tenant] = client.GetStats()
+	}
+	return stats
+}
+
+// Tenants returns the IDs of the provider's tenants, sorted.
+func (p *TenantLedgerProvider) Tenants() []string {
+	tenants := make([]string, 0, len(p.tenants))
+	for tenant := range p.tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// Close closes every ledger opened; Ledger fails with ErrClientClosed afterwards.
+func (p *TenantLedgerProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	var errs []error
+	for tenant, client := range p.clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenant, err))
+		}
+	}
+	p.clients = nil
+	return errors.Join(errs...)
+}
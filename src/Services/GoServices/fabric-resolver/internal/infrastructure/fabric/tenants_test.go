@@ -0,0 +1,144 @@
+package fabric
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestTenantLedgerProvider_OpensALedgerPerTenant(t *testing.T) {
+	dir := t.TempDir()
+	p, err := NewTenantLedgerProvider(Config{FilePath: filepath.Join(dir, "ledger.json")}, []string{"acme", "globex"})
+	if err != nil {
+		t.Fatalf("NewTenantLedgerProvider failed: %v", err)
+	}
+	defer p.Close()
+
+	acme, err := p.Ledger(t.Context(), "acme")
+	if err != nil {
+		t.Fatalf("Ledger failed: %v", err)
+	}
+	if again, _ := p.Ledger(t.Context(), "acme"); again != acme {
+		t.Error("expected the ledger to be opened once")
+	}
+	hash := strings.Repeat("ab", 32)
+	if _, _, err := acme.CreateAnchor(context.Background(), &domain.Anchor{Hash: hash}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tenants", "acme", "ledger.json")); err != nil {
+		t.Errorf("expected the ledger of acme under tenants/acme: %v", err)
+	}
+
+	globex, err := p.Ledger(t.Context(), "globex")
+	if err != nil {
+		t.Fatalf("Ledger failed: %v", err)
+	}
+	if _, err := globex.GetAnchor(context.Background(), hash); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the anchor of acme to be unknown to globex, got %v", err)
+	}
+	if stats := p.Stats(); stats["acme"].Anchors != 1 || stats["globex"].Anchors != 0 {
+		t.Errorf("expected stats per tenant, got %+v", stats)
+	}
+
+	if _, err := p.Ledger(t.Context(), "initech"); !errors.Is(err, ErrUnknownTenant) {
+		t.Errorf("expected ErrUnknownTenant, got %v", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := p.Ledger(t.Context(), "acme"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed after Close, got %v", err)
+	}
+}
+
+func TestTenantLedgerProvider_MetricsAndOnOpen(t *testing.T) {
+	base := Config{FilePath: filepath.Join(t.TempDir(), "ledger.json"), Logger: discardLogger}
+	p, err := NewTenantLedgerProvider(base, []string{"acme", "globex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	var opened []string
+	p.OnOpen(func(tenant string, _ LedgerClient) { opened = append(opened, tenant) })
+
+	// The shared ledger is labelled tenant "" alongside the tenants'
+	inner, err := NewFileLedgerClientWithLogger(filepath.Join(t.TempDir(), "shared.json"), discardLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared := NewTenantMetricsLedgerClient(inner, "")
+	defer shared.Close()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(shared, p)
+
+	acme, err := p.Ledger(t.Context(), "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Ledger(t.Context(), "acme")
+	acme.CreateAnchor(context.Background(), &domain.Anchor{Hash: "h1"})
+	shared.CreateAnchor(context.Background(), &domain.Anchor{Hash: "h1"})
+	shared.CreateAnchor(context.Background(), &domain.Anchor{Hash: "h2"})
+
+	samples := gather(t, reg)
+	if samples["fabric_resolver_anchors_created_total{acme}"] != 1 || samples["fabric_resolver_anchors_created_total{}"] != 2 {
+		t.Errorf("expected anchors counted by tenant, got %v", samples)
+	}
+	if _, ok := samples["fabric_resolver_ledger_records{globex}"]; ok {
+		t.Error("expected no series for a tenant whose ledger is not open")
+	}
+	if len(opened) != 1 || opened[0] != "acme" {
+		t.Errorf("expected OnOpen once for acme, got %v", opened)
+	}
+}
+
+func TestTenantLedgerProvider_WaitsForLockedLedger(t *testing.T) {
+	base := Config{FilePath: filepath.Join(t.TempDir(), "ledger.json"), OpenAttempts: 50, OpenRetryDelay: 10 * time.Millisecond, Logger: discardLogger}
+	p, err := NewTenantLedgerProvider(base, []string{"acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	// Another process, as the old instance of a rolling update, holds the ledger for a while
+	holder, err := NewLedgerClient(Config{Mode: "file", FilePath: p.Path("acme")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(50*time.Millisecond, func() { holder.Close() })
+
+	if _, err := p.Ledger(t.Context(), "acme"); err != nil {
+		t.Fatalf("expected the ledger to open once released, got %v", err)
+	}
+
+	// Waiting ends with the request
+	p2, _ := NewTenantLedgerProvider(Config{FilePath: filepath.Join(t.TempDir(), "ledger.json"), OpenAttempts: 50, OpenRetryDelay: time.Hour, Logger: discardLogger}, []string{"acme"})
+	defer p2.Close()
+	holder2, err := NewLedgerClient(Config{Mode: "file", FilePath: p2.Path("acme")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer holder2.Close()
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := p2.Ledger(ctx, "acme"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the wait to end with the context, got %v", err)
+	}
+}
+
+func TestNewTenantLedgerProvider_RejectsInvalidTenants(t *testing.T) {
+	if _, err := NewTenantLedgerProvider(Config{}, []string{"acme", "../etc"}); err == nil {
+		t.Error("expected a tenant ID that is not a plain name to be rejected")
+	}
+	if _, err := NewTenantLedgerProvider(Config{Mode: "fabric"}, []string{"acme"}); err == nil {
+		t.Error("expected tenants to need a file ledger")
+	}
+}
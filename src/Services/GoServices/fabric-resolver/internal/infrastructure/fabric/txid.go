@@ -0,0 +1,62 @@
+package fabric
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// txIDGenerator produces UUIDv7 transaction IDs (RFC 9562).
+// The 48-bit millisecond timestamp keeps IDs roughly time-ordered, and the 12-bit
+// rand_a field is used as a counter within the same millisecond so IDs from one
+// generator are strictly increasing. The remaining 62 bits are random, which keeps
+// IDs from different clients or processes from colliding.
+type txIDGenerator struct {
+	mu     sync.Mutex
+	lastMs int64
+	seq    uint16
+}
+
+func (g *txIDGenerator) next() string {
+	g.mu.Lock()
+	ms := time.Now().UnixMilli()
+	if ms <= g.lastMs {
+		// Same (or earlier, if the clock stepped back) millisecond: bump the counter
+		g.seq++
+		if g.seq > 0x0fff {
+			g.lastMs++
+			g.seq = 0
+		}
+		ms = g.lastMs
+	} else {
+		g.lastMs = ms
+		g.seq = 0
+	}
+	seq := g.seq
+	g.mu.Unlock()
+
+	var b [16]byte
+	if _, err := rand.Read(b[8:]); err != nil {
+		panic("crypto/rand failed: " + err.Error())
+	}
+
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(ms))
+	copy(b[0:6], ts[2:8])
+	binary.BigEndian.PutUint16(b[6:8], 0x7000|seq) // version 7 + counter
+	b[8] = (b[8] & 0x3f) | 0x80                    // RFC 9562 variant
+
+	var out [36]byte
+	hex.Encode(out[0:8], b[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], b[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], b[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], b[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:36], b[10:16])
+	return string(out[:])
+}
@@ -0,0 +1,25 @@
+package fabric
+
+import (
+	"regexp"
+	"testing"
+)
+
+var uuidV7Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestTxIDGenerator_FormatAndOrdering(t *testing.T) {
+	var g txIDGenerator
+
+	prev := ""
+	for i := 0; i < 10000; i++ {
+		id := g.next()
+		if !uuidV7Pattern.MatchString(id) {
+			t.Fatalf("not a UUIDv7: %s", id)
+		}
+		// Timestamp + counter prefix must be strictly increasing within one generator
+		if prev != "" && id[:18] <= prev[:18] {
+			t.Fatalf("IDs not monotonic: %s after %s", id, prev)
+		}
+		prev = id
+	}
+}
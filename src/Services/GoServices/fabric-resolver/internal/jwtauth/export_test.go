@@ -0,0 +1,8 @@
+package jwtauth
+
+import "time"
+
+// SetClock replaces the clock of j, so tests can step past refresh intervals.
+func (j *JWKS) SetClock(now func() time.Time) {
+	j.now = now
+}
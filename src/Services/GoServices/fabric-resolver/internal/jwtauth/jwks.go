@@ -0,0 +1,197 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultJWKSRefresh is how long fetched keys are used before the JWKS is fetched again.
+	DefaultJWKSRefresh = 10 * time.Minute
+
+	// minRefetchInterval limits fetches triggered by unknown kids, so tokens with
+	// made-up kids cannot make the resolver hammer the identity provider.
+	minRefetchInterval = 30 * time.Second
+
+	// maxJWKSBytes bounds the JWKS response read into memory.
+	maxJWKSBytes = 1 << 20
+)
+
+// JWKS is a KeySource backed by a JSON Web Key Set URL (RFC 7517). Keys are cached
+// for the refresh interval; a token with an unknown kid triggers an early fetch so
+// key rotations at the provider are picked up, at most once per minRefetchInterval.
+// If a fetch fails, the previously fetched keys stay in use.
+type JWKS struct {
+	url     string
+	client  *http.Client
+	refresh time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	now       func() time.Time // replaced in tests
+}
+
+// NewJWKS returns a key source for the JWKS at url. A nil client uses one with a
+// 10 second timeout; a refresh of zero uses DefaultJWKSRefresh.
+func NewJWKS(url string, client *http.Client, refresh time.Duration) *JWKS {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if refresh <= 0 {
+		refresh = DefaultJWKSRefresh
+	}
+	return &JWKS{url: url, client: client, refresh: refresh, now: time.Now}
+}
+
+// Key returns the key with id kid, fetching the JWKS when the cache is stale or
+// does not know kid.
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.now()
+	stale := j.keys == nil || now.Sub(j.fetchedAt) >= j.refresh
+	_, known := j.keys[kid]
+	if stale || (!known && now.Sub(j.fetchedAt) >= minRefetchInterval) {
+		keys, err := j.fetch(ctx)
+		if err != nil {
+			if j.keys == nil {
+				return nil, err
+			}
+			slog.Warn("JWKS refresh failed, keeping cached keys", "url", j.url, "err", err)
+		} else {
+			j.keys = keys
+		}
+		// A failed fetch also waits out the interval before the next attempt
+		j.fetchedAt = now
+	}
+
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+func (j *JWKS) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("JWKS request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS: %s returned %d", j.url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSBytes))
+	if err != nil {
+		return nil, fmt.Errorf("read JWKS: %w", err)
+	}
+	return ParseJWKS(data)
+}
+
+// jwk is the subset of RFC 7517/7518 key parameters used for RS256 and ES256.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// ParseJWKS returns the signing keys of a JWKS document by kid. Keys for other
+// uses or of unsupported types are skipped, so a provider can publish them
+// alongside; a set with no usable key is an error.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse JWKS: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			slog.Warn("Skipping JWKS key", "kid", k.Kid, "err", err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("parse JWKS: no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("n: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("e: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("e is out of range")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("curve %q is not supported", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if _, err := pub.ECDH(); err != nil {
+			return nil, errors.New("point is not on P-256")
+		}
+		return pub, nil
+	default:
+		return nil, fmt.Errorf("kty %q is not supported", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.New("missing")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("must be base64url encoded")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,135 @@
+package jwtauth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/jwtauth"
+	"fabric-resolver/internal/jwtauth/jwttest"
+)
+
+// jwksServer serves the keys of the current issuer and counts fetches; failing makes it return 503.
+type jwksServer struct {
+	issuer  atomic.Pointer[jwttest.Issuer]
+	fetches atomic.Int32
+	failing atomic.Bool
+}
+
+func newJWKSServer(t *testing.T, issuer *jwttest.Issuer) (*jwksServer, *httptest.Server) {
+	t.Helper()
+	s := &jwksServer{}
+	s.issuer.Store(issuer)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+		if s.failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		s.issuer.Load().JWKS().ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+func TestJWKS_VerifiesBothKeyTypes(t *testing.T) {
+	_, srv := newJWKSServer(t, issuer)
+	v := jwtauth.NewVerifier(jwtauth.NewJWKS(srv.URL, nil, 0), jwtauth.Options{Issuer: jwttest.DefaultIssuer, Audience: jwttest.DefaultAudience})
+
+	for _, alg := range []string{"ES256", "RS256"} {
+		if _, err := v.Verify(context.Background(), issuer.Sign(alg, jwttest.Claims())); err != nil {
+			t.Errorf("%s: expected the token to verify against the JWKS, got %v", alg, err)
+		}
+	}
+}
+
+func TestJWKS_CachesAndRefreshes(t *testing.T) {
+	server, srv := newJWKSServer(t, issuer)
+	jwks := jwtauth.NewJWKS(srv.URL, nil, time.Hour)
+	now := time.Now()
+	jwks.SetClock(func() time.Time { return now })
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := jwks.Key(ctx, jwttest.ES256KeyID); err != nil {
+			t.Fatalf("Key failed: %v", err)
+		}
+	}
+	if n := server.fetches.Load(); n != 1 {
+		t.Errorf("expected the JWKS to be fetched once, got %d", n)
+	}
+
+	// An unknown kid refetches, but not more than once per interval
+	jwks.Key(ctx, "rotated")
+	now = now.Add(time.Minute)
+	jwks.Key(ctx, "rotated")
+	jwks.Key(ctx, "rotated")
+	if n := server.fetches.Load(); n != 2 {
+		t.Errorf("expected one refetch for the unknown kid, got %d fetches", n)
+	}
+
+	// A failing provider keeps the cached keys in use
+	server.failing.Store(true)
+	now = now.Add(2 * time.Hour)
+	if _, err := jwks.Key(ctx, jwttest.ES256KeyID); err != nil {
+		t.Errorf("expected cached keys after a failed refresh, got %v", err)
+	}
+	if n := server.fetches.Load(); n != 3 {
+		t.Errorf("expected the stale cache to be refreshed, got %d fetches", n)
+	}
+}
+
+func TestJWKS_PicksUpRotatedKeys(t *testing.T) {
+	server, srv := newJWKSServer(t, issuer)
+	jwks := jwtauth.NewJWKS(srv.URL, nil, time.Hour)
+	now := time.Now()
+	jwks.SetClock(func() time.Time { return now })
+	v := jwtauth.NewVerifier(jwks, jwtauth.Options{})
+	if _, err := v.Verify(context.Background(), issuer.Token()); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	// The new keys share kids with the old ones, so only the hourly refresh sees them
+	rotated := jwttest.NewIssuer()
+	server.issuer.Store(rotated)
+	if _, err := v.Verify(context.Background(), rotated.Token()); !errors.Is(err, jwtauth.ErrInvalidToken) {
+		t.Errorf("expected the cached key to reject the rotated signer, got %v", err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := v.Verify(context.Background(), rotated.Token()); err != nil {
+		t.Errorf("expected the refreshed keys to verify, got %v", err)
+	}
+}
+
+func TestJWKS_UnreachableProvider(t *testing.T) {
+	server, srv := newJWKSServer(t, issuer)
+	server.failing.Store(true)
+	v := jwtauth.NewVerifier(jwtauth.NewJWKS(srv.URL, nil, 0), jwtauth.Options{})
+	if _, err := v.Verify(context.Background(), issuer.Token()); !errors.Is(err, jwtauth.ErrInvalidToken) {
+		t.Errorf("expected an invalid token without keys, got %v", err)
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	keys, err := jwtauth.ParseJWKS([]byte(`{"keys":[
+		{"kty":"EC","crv":"P-256","kid":"off-curve","x":"AQ","y":"AQ"},
+		{"kty":"EC","crv":"P-384","kid":"p384","x":"AQ","y":"AQ"},
+		{"kty":"oct","kid":"hmac","k":"c2VjcmV0"},
+		{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"},
+		{"kty":"RSA","kid":"sig","n":"AQAB","e":"AQAB"}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseJWKS failed: %v", err)
+	}
+	if len(keys) != 1 || keys["sig"] == nil {
+		t.Errorf("expected only the RSA signing key, got %v", keys)
+	}
+
+	if _, err := jwtauth.ParseJWKS([]byte(`{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`)); err == nil {
+		t.Error("expected a set without usable keys to be rejected")
+	}
+}
@@ -0,0 +1,234 @@
+// Package jwtauth authenticates requests with JWT bearer tokens (RFC 7519) from an
+// OpenID Connect provider. Tokens must be signed with RS256 or ES256 by a key from
+// the provider's JWKS, carry the configured issuer and audience, and be unexpired.
+//
+// Scopes come from the space-separated "scope" claim or the "scp" array.
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidToken means the token is malformed, not signed by a known key, or from another issuer.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrExpired means the token's exp, or nbf, is outside the allowed clock skew.
+	ErrExpired = errors.New("token expired")
+	// ErrInvalidAudience means the token was issued for another service.
+	ErrInvalidAudience = errors.New("token audience does not match")
+	// ErrUnknownKey means the key source has no key with the token's kid.
+	ErrUnknownKey = errors.New("unknown signing key")
+)
+
+// DefaultLeeway is the clock skew allowed on exp and nbf when none is configured.
+const DefaultLeeway = 30 * time.Second
+
+// minRSABits rejects RSA keys too short to be safe.
+const minRSABits = 2048
+
+// Claims are the verified claims of a token.
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	Scopes    []string
+}
+
+// HasScope reports whether the token grants scope.
+func (c *Claims) HasScope(scope string) bool {
+	return c != nil && slices.Contains(c.Scopes, scope)
+}
+
+// KeySource returns the public key a token header names.
+type KeySource interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// StaticKeys is a fixed KeySource, for tests and deployments without a JWKS endpoint.
+type StaticKeys map[string]crypto.PublicKey
+
+// Key returns the key with id kid.
+func (s StaticKeys) Key(_ context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok := s[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// Options configures a Verifier.
+type Options struct {
+	// Issuer is the required iss claim. Empty accepts any issuer.
+	Issuer string
+
+	// Audience must be among the aud claim. Empty accepts any audience.
+	Audience string
+
+	// Leeway is the clock skew allowed on exp and nbf. Zero uses DefaultLeeway.
+	Leeway time.Duration
+}
+
+// Verifier checks tokens against a key source and the configured claims.
+type Verifier struct {
+	keys KeySource
+	opts Options
+	now  func() time.Time // replaced in tests
+}
+
+// NewVerifier returns a verifier of tokens signed by keys.
+func NewVerifier(keys KeySource, opts Options) *Verifier {
+	if opts.Leeway <= 0 {
+		opts.Leeway = DefaultLeeway
+	}
+	return &Verifier{keys: keys, opts: opts, now: time.Now}
+}
+
+// IsJWT reports whether token has the three-part compact form of a JWT, so callers
+// can tell tokens apart from other bearer credentials such as a static admin token.
+func IsJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// Verify checks the signature of token and then its exp, nbf, iss and aud claims.
+// Failures wrap ErrInvalidToken, ErrExpired or ErrInvalidAudience.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: not a compact JWT", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg  string   `json:"alg"`
+		Kid  string   `json:"kid"`
+		Crit []string `json:"crit"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	if len(header.Crit) > 0 {
+		return nil, fmt.Errorf("%w: critical header parameters are not supported", ErrInvalidToken)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature must be base64url encoded", ErrInvalidToken)
+	}
+
+	key, err := v.keys.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if err := verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var raw struct {
+		Iss   string          `json:"iss"`
+		Sub   string          `json:"sub"`
+		Aud   json.RawMessage `json:"aud"`
+		Exp   *float64        `json:"exp"`
+		Nbf   *float64        `json:"nbf"`
+		Scope string          `json:"scope"`
+		Scp   []string        `json:"scp"`
+	}
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	claims := &Claims{Issuer: raw.Iss, Subject: raw.Sub, Scopes: append(strings.Fields(raw.Scope), raw.Scp...)}
+	if claims.Audience, err = parseAudience(raw.Aud); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	now := v.now()
+	if raw.Exp == nil {
+		return nil, fmt.Errorf("%w: exp is required", ErrInvalidToken)
+	}
+	claims.ExpiresAt = time.Unix(int64(*raw.Exp), 0)
+	if !now.Before(claims.ExpiresAt.Add(v.opts.Leeway)) {
+		return nil, fmt.Errorf("%w at %s", ErrExpired, claims.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if raw.Nbf != nil && now.Add(v.opts.Leeway).Before(time.Unix(int64(*raw.Nbf), 0)) {
+		return nil, fmt.Errorf("%w: not valid yet", ErrExpired)
+	}
+	if v.opts.Issuer != "" && claims.Issuer != v.opts.Issuer {
+		return nil, fmt.Errorf("%w: issuer %q is not trusted", ErrInvalidToken, claims.Issuer)
+	}
+	if v.opts.Audience != "" && !slices.Contains(claims.Audience, v.opts.Audience) {
+		return nil, fmt.Errorf("%w: expected %q", ErrInvalidAudience, v.opts.Audience)
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("must be base64url encoded")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.New("must be a JSON object")
+	}
+	return nil
+}
+
+// parseAudience accepts aud as a single string or an array of strings.
+func parseAudience(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}, nil
+	}
+	var many []string
+	if err := json.Unmarshal(raw, &many); err != nil {
+		return nil, errors.New("aud must be a string or an array of strings")
+	}
+	return many, nil
+}
+
+// verifySignature checks signature over signingInput with key, which must be of
+// the type alg names. Only RS256 and ES256 are accepted, never "none" or HMAC.
+func verifySignature(alg string, key crypto.PublicKey, signingInput, signature []byte) error {
+	digest := sha256.Sum256(signingInput)
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("RS256 requires an RSA key")
+		}
+		if pub.N.BitLen() < minRSABits {
+			return fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+		}
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) != nil {
+			return errors.New("signature does not verify")
+		}
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != elliptic.P256() {
+			return errors.New("ES256 requires a P-256 key")
+		}
+		if len(signature) != 64 {
+			return errors.New("ES256 signature must be 64 bytes")
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return errors.New("signature does not verify")
+		}
+	default:
+		return fmt.Errorf("alg %q is not accepted; use RS256 or ES256", alg)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package jwtauth_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/jwtauth"
+	"fabric-resolver/internal/jwtauth/jwttest"
+)
+
+var issuer = jwttest.NewIssuer()
+
+// claimsWith returns valid claims granting anchors:write after change adjusts them.
+func claimsWith(change func(map[string]interface{})) map[string]interface{} {
+	claims := jwttest.Claims("anchors:write")
+	change(claims)
+	return claims
+}
+
+func TestVerify_AcceptsES256AndRS256(t *testing.T) {
+	v := issuer.Verifier()
+	for _, alg := range []string{"ES256", "RS256"} {
+		claims := claimsWith(func(c map[string]interface{}) {
+			c["scp"] = []string{"dids:write"}
+			c["aud"] = []string{"other", jwttest.DefaultAudience}
+		})
+
+		got, err := v.Verify(context.Background(), issuer.Sign(alg, claims))
+		if err != nil {
+			t.Fatalf("%s: expected the token to verify, got %v", alg, err)
+		}
+		if got.Subject != "test-client" || got.Issuer != jwttest.DefaultIssuer {
+			t.Errorf("%s: unexpected claims %+v", alg, got)
+		}
+		if !got.HasScope("anchors:write") || !got.HasScope("dids:write") || got.HasScope("admin") {
+			t.Errorf("%s: expected scopes from scope and scp, got %v", alg, got.Scopes)
+		}
+	}
+}
+
+func TestVerify_Rejections(t *testing.T) {
+	v := issuer.Verifier()
+	parts := strings.Split(issuer.Token("anchors:write"), ".")
+	adminClaims := strings.Split(issuer.Token("admin"), ".")[1]
+	noneHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"` + jwttest.ES256KeyID + `"}`))
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"expired", issuer.Sign("ES256", claimsWith(func(c map[string]interface{}) { c["exp"] = time.Now().Add(-time.Hour).Unix() })), jwtauth.ErrExpired},
+		{"not yet valid", issuer.Sign("ES256", claimsWith(func(c map[string]interface{}) { c["nbf"] = time.Now().Add(time.Hour).Unix() })), jwtauth.ErrExpired},
+		{"wrong audience", issuer.Sign("ES256", claimsWith(func(c map[string]interface{}) { c["aud"] = "another-service" })), jwtauth.ErrInvalidAudience},
+		{"missing audience", issuer.Sign("ES256", claimsWith(func(c map[string]interface{}) { delete(c, "aud") })), jwtauth.ErrInvalidAudience},
+		{"wrong issuer", issuer.Sign("ES256", claimsWith(func(c map[string]interface{}) { c["iss"] = "https://evil.test" })), jwtauth.ErrInvalidToken},
+		{"missing exp", issuer.Sign("ES256", claimsWith(func(c map[string]interface{}) { delete(c, "exp") })), jwtauth.ErrInvalidToken},
+		{"other signer", jwttest.NewIssuer().Token("anchors:write"), jwtauth.ErrInvalidToken},
+		{"tampered claims", parts[0] + "." + adminClaims + "." + parts[2], jwtauth.ErrInvalidToken},
+		{"alg none", noneHeader + "." + parts[1] + ".", jwtauth.ErrInvalidToken},
+		{"not a JWT", "s3cret", jwtauth.ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), tt.token)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestVerify_Leeway(t *testing.T) {
+	v := jwtauth.NewVerifier(issuer.Keys(), jwtauth.Options{Leeway: time.Minute})
+	token := issuer.Sign("ES256", claimsWith(func(c map[string]interface{}) { c["exp"] = time.Now().Add(-10 * time.Second).Unix() }))
+	if _, err := v.Verify(context.Background(), token); err != nil {
+		t.Errorf("expected a token expired within the leeway to verify, got %v", err)
+	}
+}
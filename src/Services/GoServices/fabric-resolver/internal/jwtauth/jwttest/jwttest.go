@@ -0,0 +1,136 @@
+// Package jwttest mints JWTs with locally generated keys, so tests of code behind
+// jwtauth need no identity provider. Pair Issuer.Keys with jwtauth.NewVerifier,
+// or serve Issuer.JWKS to exercise a JWKS URL.
+package jwttest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"fabric-resolver/internal/jwtauth"
+)
+
+const (
+	// DefaultIssuer and DefaultAudience are set on tokens that do not override them.
+	DefaultIssuer   = "https://idp.test"
+	DefaultAudience = "fabric-resolver"
+)
+
+// Issuer signs tokens with one ES256 and one RS256 key.
+type Issuer struct {
+	ec  *ecdsa.PrivateKey
+	rsa *rsa.PrivateKey
+}
+
+// Key ids of the Issuer's keys.
+const (
+	ES256KeyID = "test-es256"
+	RS256KeyID = "test-rs256"
+)
+
+// NewIssuer generates fresh keys. It panics if key generation fails.
+func NewIssuer() *Issuer {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return &Issuer{ec: ecKey, rsa: rsaKey}
+}
+
+// Keys returns the public keys as a static key source.
+func (i *Issuer) Keys() jwtauth.StaticKeys {
+	return jwtauth.StaticKeys{ES256KeyID: &i.ec.PublicKey, RS256KeyID: &i.rsa.PublicKey}
+}
+
+// Verifier returns a verifier of this issuer's tokens for DefaultIssuer and DefaultAudience.
+func (i *Issuer) Verifier() *jwtauth.Verifier {
+	return jwtauth.NewVerifier(i.Keys(), jwtauth.Options{Issuer: DefaultIssuer, Audience: DefaultAudience})
+}
+
+// JWKS serves the public keys as a JSON Web Key Set.
+func (i *Issuer) JWKS() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{
+			{
+				"kty": "EC", "crv": "P-256", "kid": ES256KeyID, "use": "sig", "alg": "ES256",
+				"x": b64(i.ec.X.FillBytes(make([]byte, 32))),
+				"y": b64(i.ec.Y.FillBytes(make([]byte, 32))),
+			},
+			{
+				"kty": "RSA", "kid": RS256KeyID, "use": "sig", "alg": "RS256",
+				"n": b64(i.rsa.N.Bytes()),
+				"e": b64(big.NewInt(int64(i.rsa.E)).Bytes()),
+			},
+		}})
+	})
+}
+
+// Token returns an ES256 token granting scopes, valid for an hour.
+func (i *Issuer) Token(scopes ...string) string {
+	return i.Sign("ES256", Claims(scopes...))
+}
+
+// Claims returns the claims of a valid token granting scopes, for tests to adjust.
+func Claims(scopes ...string) map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"iss":   DefaultIssuer,
+		"aud":   DefaultAudience,
+		"sub":   "test-client",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"scope": strings.Join(scopes, " "),
+	}
+}
+
+// Sign returns a token with claims, signed with alg "ES256" or "RS256".
+func (i *Issuer) Sign(alg string, claims map[string]interface{}) string {
+	kid := ES256KeyID
+	if alg == "RS256" {
+		kid = RS256KeyID
+	}
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT", "kid": kid})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		panic(err)
+	}
+	signingInput := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch alg {
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, i.ec, digest[:])
+		if err != nil {
+			panic(err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case "RS256":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, i.rsa, crypto.SHA256, digest[:])
+		if err != nil {
+			panic(err)
+		}
+	default:
+		panic("jwttest: unsupported alg " + alg)
+	}
+	return signingInput + "." + b64(signature)
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
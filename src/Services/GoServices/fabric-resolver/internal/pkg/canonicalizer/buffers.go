@@ -0,0 +1,140 @@
+package canonicalizer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// maxPooledBuffer is the largest encode buffer returned to bufferPool, so that one
+// large document does not keep its buffer alive for every later small one.
+const maxPooledBuffer = 1 << 20
+
+var bufferPool = sync.Pool{
+	New: func() any { return new([]byte) },
+}
+
+func getBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
+
+// putBuffer returns buf, grown to b, to bufferPool.
+func putBuffer(buf *[]byte, b []byte) {
+	if cap(b) > maxPooledBuffer {
+		return
+	}
+	*buf = b[:0]
+	bufferPool.Put(buf)
+}
+
+// CanonicalizeJSONTo appends the canonical form of raw JSON bytes, per CanonicalizeJSON,
+// to dst and returns the extended buffer.
+func CanonicalizeJSONTo(dst []byte, raw []byte) ([]byte, error) {
+	v, err := decodeJSON(raw, Options{})
+	if err != nil {
+		return nil, err
+	}
+	return appendCanonical(dst, v)
+}
+
+// CanonicalizeAndHashJSONTo appends the hex SHA-256 hash of CanonicalizeAndHashJSON to
+// dst and returns the extended buffer. With a dst of spare capacity nothing but the
+// decoded document is allocated.
+func CanonicalizeAndHashJSONTo(dst []byte, raw []byte) ([]byte, error) {
+	var sum [sha256.Size]byte
+	if err := canonicalizeJSONPooled(raw, Options{}, func(canonicalBytes []byte) {
+		sum = sha256.Sum256(canonicalBytes)
+	}); err != nil {
+		return nil, err
+	}
+	return hex.AppendEncode(dst, sum[:]), nil
+}
+
+// CanonicalizeAndCommitJSONTo appends the hex HMAC-SHA256 commitment of
+// CanonicalizeAndCommitJSON to dst and returns the extended buffer.
+func CanonicalizeAndCommitJSONTo(dst []byte, raw []byte, key []byte) ([]byte, error) {
+	if len(key) < MinHMACKeyLen {
+		return nil, ErrKeyTooShort
+	}
+
+	var sum []byte
+	if err := canonicalizeJSONPooled(raw, Options{}, func(canonicalBytes []byte) {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(canonicalBytes)
+		sum = mac.Sum(nil)
+	}); err != nil {
+		return nil, err
+	}
+	return hex.AppendEncode(dst, sum), nil
+}
+
+// canonicalizeJSONPooled calls use with the canonical form of raw under opts, held in
+// a pooled buffer that use must not retain.
+func canonicalizeJSONPooled(raw []byte, opts Options, use func(canonicalBytes []byte)) error {
+	v, err := decodeJSON(raw, opts)
+	if err != nil {
+		return err
+	}
+
+	buf := getBuffer()
+	b, err := appendCanonical(*buf, v)
+	if err != nil {
+		putBuffer(buf, *buf)
+		return err
+	}
+	use(b)
+	putBuffer(buf, b)
+	return nil
+}
+
+// appendCanonical appends the canonical form of v, decoded by decodeJSON, to dst: what
+// Canonicalize writes for it, without going through encoding/json.
+func appendCanonical(dst []byte, v interface{}) ([]byte, error) {
+	var err error
+	switch v := v.(type) {
+	case nil:
+		return append(dst, "null"...), nil
+	case bool:
+		return strconv.AppendBool(dst, v), nil
+	case string:
+		return appendString(dst, v), nil
+	case json.Number:
+		return append(dst, v...), nil
+	case []interface{}:
+		dst = append(dst, '[')
+		for i, elem := range v {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			if dst, err = appendCanonical(dst, elem); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, ']'), nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		dst = append(dst, '{')
+		for i, key := range keys {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendString(dst, key)
+			dst = append(dst, ':')
+			if dst, err = appendCanonical(dst, v[key]); err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, '}'), nil
+	}
+	return nil, fmt.Errorf("unexpected JSON value of type %T", v)
+}
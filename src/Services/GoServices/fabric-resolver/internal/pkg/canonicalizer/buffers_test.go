@@ -0,0 +1,159 @@
+package canonicalizer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+// smallDocument is a credential of about 200 bytes, as anchored one at a time.
+var smallDocument = []byte(`{"id":"urn:uuid:3f6c1d2e-8a4b-4c7e-9f10-2b3c4d5e6f70","type":["VerifiableCredential","AgeCredential"],` +
+	`"issuer":"did:web:issuer.example","credentialSubject":{"over18":true,"age":42,"score":1.50}}`)
+
+// largeDocument returns a batch of credentials of about 200 KB.
+func largeDocument() []byte {
+	var b bytes.Buffer
+	b.WriteString(`{"batch":[`)
+	for i := 0; b.Len() < 200<<10; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"type":["VerifiableCredential"],"id":"urn:example:%d","issuer":"did:web:issuer.example",`+
+			`"credentialSubject":{"name":"Holder <%d>","score":%d.5,"tags":["a","b","c"],"active":%t,"note":null}}`,
+			i, i, i, i%2 == 0)
+	}
+	b.WriteString(`],"count":1.0}`)
+	return b.Bytes()
+}
+
+// Canonical forms recorded before the encode buffers were pooled: outputs must stay
+// byte-identical, or anchored hashes stop verifying.
+func TestCanonicalizeJSON_Golden(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []byte
+		want string // hex SHA-256 of the canonical form
+	}{
+		{"small", smallDocument, "25a381b57853180d401fbb669f21ea2d0f76d8142bd33c776e84a138a2d25c0a"},
+		{"large", largeDocument(), "2b6d485334c38881466d3f88ffc3faecf43431e6f1af2b5369c84eac1e777667"},
+	}
+	for _, tt := range tests {
+		canonicalBytes, err := CanonicalizeJSON(tt.raw)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		sum := sha256.Sum256(canonicalBytes)
+		if got := hex.EncodeToString(sum[:]); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	// The digest of the canonical forms of random documents, of each kind of value,
+	// escape and number form randomJSON writes
+	rng := rand.New(rand.NewPCG(3, 4))
+	all := sha256.New()
+	for i := 0; i < 1000; i++ {
+		var b bytes.Buffer
+		randomJSON(rng, &b, 0)
+		canonicalBytes, err := CanonicalizeJSON(b.Bytes())
+		if err != nil {
+			t.Fatalf("%s: %v", b.Bytes(), err)
+		}
+		all.Write(canonicalBytes)
+		all.Write([]byte{0})
+	}
+	if got := hex.EncodeToString(all.Sum(nil)); got != "5261358d6a8a2c820ca4769e3e336af4ca565b106a66212b35de6467a611bf1f" {
+		t.Errorf("random documents: got %s", got)
+	}
+}
+
+func TestCanonicalizeAndHashJSONTo(t *testing.T) {
+	key := []byte("this-is-a-32-byte-secret-key-123")
+	for _, raw := range [][]byte{smallDocument, largeDocument()} {
+		want, _ := CanonicalizeAndHashJSON(raw)
+		dst := []byte("sha256:")
+		got, err := CanonicalizeAndHashJSONTo(dst, raw)
+		if err != nil || string(got) != "sha256:"+want {
+			t.Errorf("got %s %v, want sha256:%s", got, err, want)
+		}
+
+		want, _ = CanonicalizeAndCommitJSON(raw, key)
+		if got, err := CanonicalizeAndCommitJSONTo(nil, raw, key); err != nil || string(got) != want {
+			t.Errorf("got %s %v, want %s", got, err, want)
+		}
+
+		canonicalBytes, _ := CanonicalizeJSON(raw)
+		if got, err := CanonicalizeJSONTo(make([]byte, 0, 8), raw); err != nil || !bytes.Equal(got, canonicalBytes) {
+			t.Errorf("got %.40s %v", got, err)
+		}
+	}
+
+	if _, err := CanonicalizeAndHashJSONTo(nil, []byte(`{"a":1,"a":2}`)); !errors.Is(err, ErrDuplicateKey) {
+		t.Error("expected a duplicate key to be rejected")
+	}
+	if _, err := CanonicalizeAndCommitJSONTo(nil, smallDocument, key[:31]); !errors.Is(err, ErrKeyTooShort) {
+		t.Error("expected a short key to be rejected")
+	}
+}
+
+// Results do not share the pooled buffers they were encoded in.
+func TestCanonicalize_ResultsOutliveBuffers(t *testing.T) {
+	first, _ := CanonicalizeJSON([]byte(`{"b":1,"a":2}`))
+	value, _ := Canonicalize(map[string]int{"z": 1})
+	for i := 0; i < 100; i++ {
+		CanonicalizeJSON([]byte(`["overwrite","the","buffer"]`))
+		Canonicalize([]string{"overwrite", "the", "buffer"})
+	}
+	if string(first) != `{"a":2,"b":1}` || string(value) != `{"z":1}` {
+		t.Errorf("results changed: %s %s", first, value)
+	}
+}
+
+func BenchmarkCanonicalizeAndHashJSON(b *testing.B) {
+	for _, doc := range []struct {
+		name string
+		raw  []byte
+	}{{"200B", smallDocument}, {"200KB", largeDocument()}} {
+		b.Run(doc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(doc.raw)))
+			for i := 0; i < b.N; i++ {
+				if _, err := CanonicalizeAndHashJSON(doc.raw); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCanonicalizeAndHashJSONTo(b *testing.B) {
+	for _, doc := range []struct {
+		name string
+		raw  []byte
+	}{{"200B", smallDocument}, {"200KB", largeDocument()}} {
+		b.Run(doc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(doc.raw)))
+			dst := make([]byte, 0, 64)
+			for i := 0; i < b.N; i++ {
+				if _, err := CanonicalizeAndHashJSONTo(dst[:0], doc.raw); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCanonicalize(b *testing.B) {
+	v := map[string]interface{}{"id": "urn:example:1", "tags": []string{"a", "b"}, "score": 1.5, "active": true}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Canonicalize(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -7,23 +7,64 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"io"
 )
 
 const MinHMACKeyLen = 32
 
-// CanonicalizeAndHashJSON takes raw JSON bytes, canonicalizes them, and returns a SHA-256 hash.
+// ErrKeyTooShort is returned for HMAC keys, and master keys, shorter than MinHMACKeyLen.
+var ErrKeyTooShort = errors.New("HMAC key size too short (min 32 bytes)")
+
+// CanonicalizeJSON returns the canonical form of raw JSON bytes.
 // Policy:
 // - Uses json.Decoder.UseNumber() to preserve number representation (1 vs 1.0).
 // - Re-encodes using SetEscapeHTML(false) to preserve < and > as characters.
 // - Trims trailing newline added by encoder.
-// - Ensures no trailing garbage tokens exist after the first valid JSON value.
-func CanonicalizeAndHashJSON(raw []byte) (string, error) {
-	canonicalBytes, err := canonicalizeJSON(raw)
+// - Rejects trailing garbage tokens after the first valid JSON value with ErrTrailingData.
+// - Rejects input that is not JSON with ErrInvalidJSON.
+// - Rejects objects naming a member twice with ErrDuplicateKey.
+// - Rejects input beyond the default limits of Options with ErrTooDeep or ErrTooLarge.
+func CanonicalizeJSON(raw []byte) ([]byte, error) {
+	return CanonicalizeJSONWithOptions(raw, Options{})
+}
+
+// CanonicalizeJSONString is CanonicalizeJSON returning a string.
+func CanonicalizeJSONString(raw []byte) (string, error) {
+	canonicalBytes, err := CanonicalizeJSON(raw)
 	if err != nil {
 		return "", err
 	}
-	return hash(canonicalBytes), nil
+	return string(canonicalBytes), nil
+}
+
+// Canonicalize returns the canonical form of a Go value: its JSON encoding with
+// object keys sorted, < > & unescaped and no trailing newline. See CanonicalizeAndHash
+// on numbers.
+func Canonicalize(v interface{}) ([]byte, error) {
+	pooled := getBuffer()
+	buf := bytes.NewBuffer(*pooled)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false) // Crucial: do not escape <, >, &
+
+	if err := enc.Encode(v); err != nil {
+		putBuffer(pooled, buf.Bytes())
+		return nil, err
+	}
+
+	b := buf.Bytes()
+	// json.Encoder.Encode always appends a newline. We must remove it for canonicalization stability.
+	if len(b) > 0 && b[len(b)-1] == '\n' {
+		b = b[:len(b)-1]
+	}
+
+	canonicalBytes := bytes.Clone(b)
+	putBuffer(pooled, b)
+	return canonicalBytes, nil
+}
+
+// CanonicalizeAndHashJSON takes raw JSON bytes, canonicalizes them per CanonicalizeJSON,
+// and returns a SHA-256 hash.
+func CanonicalizeAndHashJSON(raw []byte) (string, error) {
+	return CanonicalizeAndHashJSONWithOptions(raw, Options{})
 }
 
 // CanonicalizeAndHash takes a Go value, canonicalizes it, and returns a SHA-256 hash.
@@ -32,7 +73,7 @@ func CanonicalizeAndHashJSON(raw []byte) (string, error) {
 // may have been normalized to the same value by Go before calling this.
 // Use CanonicalizeAndHashJSON if you need strict preservation of raw number formatting.
 func CanonicalizeAndHash(v interface{}) (string, error) {
-	canonicalBytes, err := canonicalize(v)
+	canonicalBytes, err := Canonicalize(v)
 	if err != nil {
 		return "", err
 	}
@@ -42,26 +83,17 @@ func CanonicalizeAndHash(v interface{}) (string, error) {
 // CanonicalizeAndCommitJSON canonicalizes raw JSON bytes and returns an HMAC-SHA256 commitment.
 // Requires a key of at least 32 bytes.
 func CanonicalizeAndCommitJSON(raw []byte, key []byte) (string, error) {
-	if len(key) < MinHMACKeyLen {
-		return "", errors.New("HMAC key size too short (min 32 bytes)")
-	}
-
-	canonicalBytes, err := canonicalizeJSON(raw)
-	if err != nil {
-		return "", err
-	}
-
-	return commit(canonicalBytes, key), nil
+	return CanonicalizeAndCommitJSONWithOptions(raw, key, Options{})
 }
 
 // CanonicalizeAndCommit canonicalizes a Go value and returns an HMAC-SHA256 commitment.
 // Requires a key of at least 32 bytes.
 func CanonicalizeAndCommit(v interface{}, key []byte) (string, error) {
 	if len(key) < MinHMACKeyLen {
-		return "", errors.New("HMAC key size too short (min 32 bytes)")
+		return "", ErrKeyTooShort
 	}
 
-	canonicalBytes, err := canonicalize(v)
+	canonicalBytes, err := Canonicalize(v)
 	if err != nil {
 		return "", err
 	}
@@ -73,43 +105,6 @@ func CanonicalizeAndCommit(v interface{}, key []byte) (string, error) {
 // Internal Helpers
 // ---------------------------------------------------------------------
 
-func canonicalizeJSON(raw []byte) ([]byte, error) {
-	dec := json.NewDecoder(bytes.NewReader(raw))
-	dec.UseNumber()
-
-	var v interface{}
-	if err := dec.Decode(&v); err != nil {
-		return nil, err
-	}
-
-	// Check for trailing garbage
-	var extra interface{}
-	err := dec.Decode(&extra)
-	if err != io.EOF {
-		return nil, errors.New("input contains extra data after JSON value")
-	}
-
-	return canonicalize(v)
-}
-
-func canonicalize(v interface{}) ([]byte, error) {
-	var buf bytes.Buffer
-	enc := json.NewEncoder(&buf)
-	enc.SetEscapeHTML(false) // Crucial: do not escape <, >, &
-
-	if err := enc.Encode(v); err != nil {
-		return nil, err
-	}
-
-	b := buf.Bytes()
-	// json.Encoder.Encode always appends a newline. We must remove it for canonicalization stability.
-	if len(b) > 0 && b[len(b)-1] == '\n' {
-		b = b[:len(b)-1]
-	}
-
-	return b, nil
-}
-
 func hash(data []byte) string {
 	sum := sha256.Sum256(data)
 	return hex.EncodeToString(sum[:])
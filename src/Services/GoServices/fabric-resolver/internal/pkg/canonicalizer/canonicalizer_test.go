@@ -1,6 +1,7 @@
 package canonicalizer
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -95,40 +96,50 @@ func TestCanonicalizeAndHashJSON_NestedStructures(t *testing.T) {
 	}
 }
 
-func TestCanonicalizeAndHashJSON_HTMLEscaping(t *testing.T) {
-	// Start with valid JSON strings containing special chars
-	// Input: {"t": "<script>"}
-	raw := []byte(`{"t": "<script>"}`)
-
-	// We can't check the intermediate canonical string easily without exposing it,
-	// but we can check stability against a manual expectation if we knew the hash.
-	// OR: we can verify that if we change it to the escaped version, it HASHes differently.
-	// Escaped input: {"t": "\u003cscript\u003e"}
-	// (Note: json.Unmarshal will decode "\u003c" to "<", so these MIGHT collapse to same object
-	// if we are not careful about how we treat raw inputs.
-	// Actually:
-	// 1. `{"t": "<script>"}` -> decodes to `t="<script>"`
-	// 2. `{"t": "\u003cscript\u003e"}` -> decodes to `t="<script>"`
-	// So they SHOULD hash to the same thing if we decode+re-encode correctly with SetEscapeHTML(false).
-	//
-	// WAIT. The user requirement is: "Test that canonical bytes contain < and & as characters, not \u003c".
-	// Since we hash the output, we can't inspect the bytes directly from the public API.
-	// But we can implicitly test it:
-	// Go's default marshal escapes <.
-	// If our canonicalizer also escaped, it would match a standard `json.Marshal` hash.
-	// Let's rely on checking that the output hash is deterministic.
-
-	// Better verification:
-	// Let's just ensure it DOES NOT fail and produces a consistent hash.
-	hash1, err := CanonicalizeAndHashJSON(raw)
+func TestCanonicalizeJSON_HTMLEscaping(t *testing.T) {
+	// The escaped and literal forms decode to the same string, which is written literally
+	for _, raw := range []string{`{"t": "<script>&</script>"}`, `{"t": "\u003cscript\u003e\u0026\u003c/script\u003e"}`} {
+		canonical, err := CanonicalizeJSON([]byte(raw))
+		if err != nil {
+			t.Fatalf("Failed to canonicalize HTML content: %v", err)
+		}
+		if want := `{"t":"<script>&</script>"}`; string(canonical) != want {
+			t.Errorf("Expected %s, got %s", want, canonical)
+		}
+
+		got, _ := CanonicalizeAndHashJSON([]byte(raw))
+		if got != hash(canonical) {
+			t.Errorf("Expected the hash of the canonical bytes, got %s", got)
+		}
+	}
+}
+
+func TestCanonicalizeJSON_MatchesCanonicalizeAndString(t *testing.T) {
+	raw := []byte(`{"b": [1.0, "x"], "a": {"d": null, "c": true}}`)
+	want := `{"a":{"c":true,"d":null},"b":[1.0,"x"]}`
+
+	canonical, err := CanonicalizeJSON(raw)
 	if err != nil {
-		t.Fatalf("Failed to hash HTML content: %v", err)
+		t.Fatalf("CanonicalizeJSON failed: %v", err)
+	}
+	if string(canonical) != want {
+		t.Errorf("Expected %s, got %s", want, canonical)
+	}
+	if s, _ := CanonicalizeJSONString(raw); s != want {
+		t.Errorf("Expected CanonicalizeJSONString to return %s, got %s", want, s)
 	}
 
-	// Stability check
-	hash2, _ := CanonicalizeAndHashJSON(raw)
-	if hash1 != hash2 {
-		t.Error("Hash not stable for HTML content")
+	// Go values lose the number's lexical form, and get no trailing newline either
+	fromValue, err := Canonicalize(map[string]interface{}{"b": []interface{}{1.0, "x"}, "a": map[string]interface{}{"d": nil, "c": true}})
+	if err != nil {
+		t.Fatalf("Canonicalize failed: %v", err)
+	}
+	if want := `{"a":{"c":true,"d":null},"b":[1,"x"]}`; string(fromValue) != want {
+		t.Errorf("Expected %s, got %s", want, fromValue)
+	}
+
+	if _, err := CanonicalizeJSONString([]byte(`{"a": 1} {}`)); !errors.Is(err, ErrTrailingData) {
+		t.Error("Expected CanonicalizeJSONString to reject trailing data")
 	}
 }
 
@@ -141,8 +152,8 @@ func TestCanonicalizeAndHashJSON_TrailingGarbage(t *testing.T) {
 
 	for _, c := range cases {
 		_, err := CanonicalizeAndHashJSON(c)
-		if err == nil {
-			t.Errorf("Expected error for trailing garbage: %s", string(c))
+		if !errors.Is(err, ErrTrailingData) {
+			t.Errorf("Expected ErrTrailingData for trailing garbage: %s, got %v", string(c), err)
 		}
 	}
 }
@@ -183,14 +194,14 @@ func TestCanonicalizeAndCommitJSON_MissingKey_ReturnsError(t *testing.T) {
 
 	// Case 1: Nil key
 	_, err := CanonicalizeAndCommitJSON(input, nil)
-	if err == nil {
-		t.Error("Expected error for nil key, got nil")
+	if !errors.Is(err, ErrKeyTooShort) {
+		t.Errorf("Expected ErrKeyTooShort for nil key, got %v", err)
 	}
 
 	// Case 2: Empty key
 	_, err = CanonicalizeAndCommitJSON(input, []byte{})
-	if err == nil {
-		t.Error("Expected error for empty key, got nil")
+	if !errors.Is(err, ErrKeyTooShort) {
+		t.Errorf("Expected ErrKeyTooShort for empty key, got %v", err)
 	}
 }
 
@@ -199,8 +210,8 @@ func TestCanonicalizeAndCommitJSON_ShortKey_ReturnsError(t *testing.T) {
 	shortKey := make([]byte, 31) // 31 bytes < 32 bytes (MinHMACKeyLen)
 
 	_, err := CanonicalizeAndCommitJSON(input, shortKey)
-	if err == nil {
-		t.Error("Expected error for short key (< 32 bytes), got nil")
+	if !errors.Is(err, ErrKeyTooShort) {
+		t.Errorf("Expected ErrKeyTooShort for short key (< 32 bytes), got %v", err)
 	}
 }
 
@@ -0,0 +1,530 @@
+package canonicalizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CBOR major types.
+const (
+	cborUnsigned byte = iota
+	cborNegative
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+// Tags of unsigned and negative bignums.
+const (
+	cborTagBignum    = 2
+	cborTagNegBignum = 3
+)
+
+// cborBreak ends the items of indefinite-length strings, arrays and maps.
+const cborBreak = 0xff
+
+// ErrInvalidCBOR is returned for input that is not a well-formed, valid CBOR item.
+var ErrInvalidCBOR = errors.New("invalid CBOR")
+
+// errCBORTruncated is returned for CBOR items that end before their declared length.
+var errCBORTruncated = fmt.Errorf("%w: unexpected end of data", ErrInvalidCBOR)
+
+// CanonicalizeCBOR returns the deterministic encoding of a single CBOR item, per the
+// core requirements of RFC 8949 §4.2.1:
+// - Integers, lengths and tags use the shortest form of their argument.
+// - Floats use the shortest of half, single or double precision keeping their value.
+// - NaN is always f97e00, the half-precision quiet NaN.
+// - Bignums have no leading zero bytes, and are integers if they fit in 64 bits.
+// - Indefinite-length strings, arrays and maps are rewritten with definite lengths.
+// - Map entries are sorted by the bytes of the deterministic encoding of their keys.
+// Keys that encode alike are duplicates, rejected with ErrDuplicateKey. So are text
+// strings that are not valid UTF-8, reserved encodings and trailing bytes. Input is
+// bounded by the default limits of Options.
+func CanonicalizeCBOR(raw []byte) ([]byte, error) {
+	opts := Options{}
+	if maxBytes := opts.maxBytes(); maxBytes > 0 && len(raw) > maxBytes {
+		return nil, fmt.Errorf("%w: input exceeds the limit of %d bytes", ErrTooLarge, maxBytes)
+	}
+	if len(raw) == 0 {
+		return nil, errCBORTruncated
+	}
+
+	d := &cborDecoder{data: raw, maxDepth: opts.maxDepth()}
+	canonicalBytes, err := d.item(make([]byte, 0, len(raw)), 0)
+	if err != nil {
+		return nil, err
+	}
+	if d.off != len(raw) {
+		return nil, fmt.Errorf("%w after CBOR item", ErrTrailingData)
+	}
+	return canonicalBytes, nil
+}
+
+// CanonicalizeAndHashCBOR canonicalizes a CBOR item per CanonicalizeCBOR and returns
+// a SHA-256 hash.
+func CanonicalizeAndHashCBOR(raw []byte) (string, error) {
+	canonicalBytes, err := CanonicalizeCBOR(raw)
+	if err != nil {
+		return "", err
+	}
+	return hash(canonicalBytes), nil
+}
+
+// JSONToCanonicalCBOR converts raw JSON bytes to CBOR as RFC 8949 §6.2 describes, in
+// the deterministic encoding of CanonicalizeCBOR, so that a document can be anchored
+// alike whichever of the two formats it is held in:
+// - Objects are maps with text string keys, arrays are arrays and strings text strings.
+// - Numbers written without a fraction or exponent are integers, bignums past 64 bits.
+// - Other numbers are floats, so 1 and 1.0 differ as they do in CanonicalizeJSON.
+// - true, false and null are the simple values of the same name.
+// Input is decoded as for CanonicalizeJSON.
+func JSONToCanonicalCBOR(raw []byte) ([]byte, error) {
+	v, err := decodeJSON(raw, Options{})
+	if err != nil {
+		return nil, err
+	}
+	return appendJSONAsCBOR(nil, v)
+}
+
+// JSONToCanonicalCBORHash converts raw JSON bytes to CBOR per JSONToCanonicalCBOR and
+// returns a SHA-256 hash, that of CanonicalizeAndHashCBOR for the same document in CBOR.
+func JSONToCanonicalCBORHash(raw []byte) (string, error) {
+	canonicalBytes, err := JSONToCanonicalCBOR(raw)
+	if err != nil {
+		return "", err
+	}
+	return hash(canonicalBytes), nil
+}
+
+// cborDecoder reads the items of data, writing their deterministic encoding.
+type cborDecoder struct {
+	data     []byte
+	off      int
+	maxDepth int
+}
+
+// cborEntry is a map entry, key and value in deterministic encoding.
+type cborEntry struct {
+	key, value []byte
+}
+
+// head reads the initial byte of an item and its argument. For indefinite lengths
+// info is 31 and arg 0.
+func (d *cborDecoder) head() (major, info byte, arg uint64, err error) {
+	if d.off >= len(d.data) {
+		return 0, 0, 0, errCBORTruncated
+	}
+	b := d.data[d.off]
+	d.off++
+	major, info = b>>5, b&0x1f
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		n := 1 << (info - 24)
+		if len(d.data)-d.off < n {
+			return 0, 0, 0, errCBORTruncated
+		}
+		for _, c := range d.data[d.off : d.off+n] {
+			arg = arg<<8 | uint64(c)
+		}
+		d.off += n
+		return major, info, arg, nil
+	case info == 31:
+		if major == cborUnsigned || major == cborNegative || major == cborTag {
+			return 0, 0, 0, fmt.Errorf("%w: indefinite length for major type %d", ErrInvalidCBOR, major)
+		}
+		return major, info, 0, nil
+	}
+	return 0, 0, 0, fmt.Errorf("%w: reserved additional information %d", ErrInvalidCBOR, info)
+}
+
+// item appends the deterministic encoding of the next item to dst. depth is the
+// number of enclosing arrays, maps and tags.
+func (d *cborDecoder) item(dst []byte, depth int) ([]byte, error) {
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if major >= cborArray && major <= cborTag && d.maxDepth > 0 && depth >= d.maxDepth {
+		return nil, fmt.Errorf("%w: more than %d levels", ErrTooDeep, d.maxDepth)
+	}
+
+	switch major {
+	case cborUnsigned, cborNegative:
+		return appendCBORHead(dst, major, arg), nil
+	case cborBytes, cborText:
+		s, err := d.str(major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		dst = appendCBORHead(dst, major, uint64(len(s)))
+		return append(dst, s...), nil
+	case cborArray:
+		return d.array(dst, info, arg, depth)
+	case cborMap:
+		return d.cborMap(dst, info, arg, depth)
+	case cborTag:
+		return d.tag(dst, arg, depth)
+	}
+
+	switch info {
+	case 24:
+		if arg < 32 {
+			return nil, fmt.Errorf("%w: two-byte encoding of simple value %d", ErrInvalidCBOR, arg)
+		}
+		return appendCBORHead(dst, cborSimple, arg), nil
+	case 25:
+		return appendCBORFloat(dst, float16ToFloat64(uint16(arg))), nil
+	case 26:
+		return appendCBORFloat(dst, float64(math.Float32frombits(uint32(arg)))), nil
+	case 27:
+		return appendCBORFloat(dst, math.Float64frombits(arg)), nil
+	case 31:
+		return nil, fmt.Errorf("%w: unexpected break", ErrInvalidCBOR)
+	}
+	return appendCBORHead(dst, cborSimple, arg), nil
+}
+
+// str returns the content of a byte or text string, joining the chunks of an
+// indefinite-length one.
+func (d *cborDecoder) str(major, info byte, arg uint64) ([]byte, error) {
+	if info != 31 {
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, errCBORTruncated
+		}
+		s := d.data[d.off : d.off+int(arg)]
+		d.off += int(arg)
+		if major == cborText && !utf8.Valid(s) {
+			return nil, fmt.Errorf("%w: text string is not valid UTF-8", ErrInvalidCBOR)
+		}
+		return s, nil
+	}
+
+	var s []byte
+	for {
+		if d.off < len(d.data) && d.data[d.off] == cborBreak {
+			d.off++
+			return s, nil
+		}
+		chunkMajor, chunkInfo, chunkArg, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == 31 {
+			return nil, fmt.Errorf("%w: chunk of another type in an indefinite-length string of major type %d", ErrInvalidCBOR, major)
+		}
+		chunk, err := d.str(major, chunkInfo, chunkArg)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, chunk...)
+	}
+}
+
+func (d *cborDecoder) array(dst []byte, info byte, arg uint64, depth int) ([]byte, error) {
+	var err error
+	if info != 31 {
+		// Every item takes a byte at least
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, errCBORTruncated
+		}
+		dst = appendCBORHead(dst, cborArray, arg)
+		for i := uint64(0); i < arg; i++ {
+			if dst, err = d.item(dst, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	}
+
+	var items []byte
+	n := uint64(0)
+	for ; ; n++ {
+		if d.off < len(d.data) && d.data[d.off] == cborBreak {
+			d.off++
+			break
+		}
+		if items, err = d.item(items, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	dst = appendCBORHead(dst, cborArray, n)
+	return append(dst, items...), nil
+}
+
+func (d *cborDecoder) cborMap(dst []byte, info byte, arg uint64, depth int) ([]byte, error) {
+	if info != 31 && arg > uint64(len(d.data)-d.off)/2 {
+		return nil, errCBORTruncated
+	}
+
+	var entries []cborEntry
+	for i := uint64(0); info == 31 || i < arg; i++ {
+		if info == 31 && d.off < len(d.data) && d.data[d.off] == cborBreak {
+			d.off++
+			break
+		}
+		key, err := d.item(nil, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.item(nil, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, cborEntry{key: key, value: value})
+	}
+	return appendCBORMap(dst, entries)
+}
+
+func (d *cborDecoder) tag(dst []byte, number uint64, depth int) ([]byte, error) {
+	content, err := d.item(nil, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	if number != cborTagBignum && number != cborTagNegBignum {
+		dst = appendCBORHead(dst, cborTag, number)
+		return append(dst, content...), nil
+	}
+
+	// content is a deterministic byte string, so its head is the shortest one
+	if content[0]>>5 != cborBytes {
+		return nil, fmt.Errorf("%w: bignum tag %d does not enclose a byte string", ErrInvalidCBOR, number)
+	}
+	magnitude := content[cborHeadLen(content[0]&0x1f):]
+	major := cborUnsigned
+	if number == cborTagNegBignum {
+		major = cborNegative
+	}
+	return appendCBORBignum(dst, major, magnitude), nil
+}
+
+// appendJSONAsCBOR appends the deterministic CBOR encoding of v, decoded by decodeJSON.
+func appendJSONAsCBOR(dst []byte, v interface{}) ([]byte, error) {
+	var err error
+	switch v := v.(type) {
+	case nil:
+		return append(dst, 0xf6), nil
+	case bool:
+		if v {
+			return append(dst, 0xf5), nil
+		}
+		return append(dst, 0xf4), nil
+	case string:
+		dst = appendCBORHead(dst, cborText, uint64(len(v)))
+		return append(dst, v...), nil
+	case json.Number:
+		return appendJSONNumberAsCBOR(dst, v)
+	case []interface{}:
+		dst = appendCBORHead(dst, cborArray, uint64(len(v)))
+		for _, elem := range v {
+			if dst, err = appendJSONAsCBOR(dst, elem); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case map[string]interface{}:
+		entries := make([]cborEntry, 0, len(v))
+		for key, member := range v {
+			value, err := appendJSONAsCBOR(nil, member)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, cborEntry{key: appendJSONAsCBORKey(key), value: value})
+		}
+		return appendCBORMap(dst, entries)
+	}
+	return nil, fmt.Errorf("unexpected JSON value of type %T", v)
+}
+
+func appendJSONAsCBORKey(key string) []byte {
+	b := appendCBORHead(make([]byte, 0, len(key)+9), cborText, uint64(len(key)))
+	return append(b, key...)
+}
+
+func appendJSONNumberAsCBOR(dst []byte, n json.Number) ([]byte, error) {
+	s := string(n)
+	if strings.ContainsAny(s, ".eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("number %s is out of range for a double", s)
+		}
+		return appendCBORFloat(dst, f), nil
+	}
+
+	digits, major := s, cborUnsigned
+	if strings.HasPrefix(s, "-") {
+		digits, major = s[1:], cborNegative
+	}
+	if len(digits) > maxIntegerDigits {
+		return nil, fmt.Errorf("%w: integer of more than %d digits", ErrTooLarge, maxIntegerDigits)
+	}
+	if u, err := strconv.ParseUint(digits, 10, 64); err == nil {
+		switch {
+		case major == cborUnsigned:
+			return appendCBORHead(dst, cborUnsigned, u), nil
+		case u == 0: // -0 is 0
+			return append(dst, 0x00), nil
+		}
+		// -1 - arg, as major type 1 encodes
+		return appendCBORHead(dst, cborNegative, u-1), nil
+	}
+
+	magnitude, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: number %s", ErrInvalidJSON, s)
+	}
+	if major == cborNegative {
+		magnitude.Sub(magnitude, big.NewInt(1))
+	}
+	return appendCBORBignum(dst, major, magnitude.Bytes()), nil
+}
+
+// appendCBORMap appends a map of entries, sorted by the bytes of their keys.
+func appendCBORMap(dst []byte, entries []cborEntry) ([]byte, error) {
+	slices.SortFunc(entries, func(a, b cborEntry) int { return bytes.Compare(a.key, b.key) })
+	for i := 1; i < len(entries); i++ {
+		if bytes.Equal(entries[i-1].key, entries[i].key) {
+			return nil, fmt.Errorf("%w in CBOR map: %x", ErrDuplicateKey, entries[i].key)
+		}
+	}
+
+	dst = appendCBORHead(dst, cborMap, uint64(len(entries)))
+	for _, e := range entries {
+		dst = append(dst, e.key...)
+		dst = append(dst, e.value...)
+	}
+	return dst, nil
+}
+
+// appendCBORHead appends the initial byte of an item of major type major with the
+// shortest encoding of arg.
+func appendCBORHead(dst []byte, major byte, arg uint64) []byte {
+	major <<= 5
+	switch {
+	case arg < 24:
+		return append(dst, major|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(dst, major|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, major|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, major|26), uint32(arg))
+	}
+	return binary.BigEndian.AppendUint64(append(dst, major|27), arg)
+}
+
+// cborHeadLen returns the length of a head with additional information info.
+func cborHeadLen(info byte) int {
+	if info < 24 {
+		return 1
+	}
+	return 1 + 1<<(info-24)
+}
+
+// appendCBORBignum appends the unsigned or negative integer of big-endian magnitude,
+// as an integer of major type major if it fits in 64 bits and otherwise as a bignum.
+func appendCBORBignum(dst []byte, major byte, magnitude []byte) []byte {
+	magnitude = bytes.TrimLeft(magnitude, "\x00")
+	if len(magnitude) <= 8 {
+		var arg uint64
+		for _, c := range magnitude {
+			arg = arg<<8 | uint64(c)
+		}
+		return appendCBORHead(dst, major, arg)
+	}
+
+	number := uint64(cborTagBignum)
+	if major == cborNegative {
+		number = cborTagNegBignum
+	}
+	dst = appendCBORHead(dst, cborTag, number)
+	dst = appendCBORHead(dst, cborBytes, uint64(len(magnitude)))
+	return append(dst, magnitude...)
+}
+
+// appendCBORFloat appends f in the shortest precision that keeps its value.
+func appendCBORFloat(dst []byte, f float64) []byte {
+	if math.IsNaN(f) {
+		return append(dst, 0xf9, 0x7e, 0x00)
+	}
+	f32 := float32(f)
+	if float64(f32) != f {
+		return binary.BigEndian.AppendUint64(append(dst, 0xfb), math.Float64bits(f))
+	}
+	if h, ok := float32ToFloat16(f32); ok {
+		return binary.BigEndian.AppendUint16(append(dst, 0xf9), h)
+	}
+	return binary.BigEndian.AppendUint32(append(dst, 0xfa), math.Float32bits(f32))
+}
+
+// float32ToFloat16 returns the IEEE 754 half-precision bits of f, if f has them.
+func float32ToFloat16(f float32) (uint16, bool) {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+
+	switch {
+	case exp == 0xff: // infinities; NaN is handled by the caller
+		return sign | 0x7c00, mant == 0
+	case exp == 0 && mant == 0:
+		return sign, true
+	case exp == 0: // single-precision subnormals are below the smallest half
+		return 0, false
+	}
+
+	e := exp - 127
+	switch {
+	case e >= -14 && e <= 15:
+		if mant&0x1fff != 0 {
+			return 0, false
+		}
+		return sign | uint16(e+15)<<10 | uint16(mant>>13), true
+	case e >= -24 && e < -14:
+		// Half-precision subnormals are multiples of 2^-24
+		full := mant | 1<<23
+		shift := uint(-(e + 1))
+		if full&(1<<shift-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(full>>shift), true
+	}
+	return 0, false
+}
+
+// float16ToFloat64 returns the value of IEEE 754 half-precision bits h.
+func float16ToFloat64(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant != 0 {
+			return math.NaN()
+		}
+		f = math.Inf(1)
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
@@ -0,0 +1,192 @@
+package canonicalizer
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Items of RFC 8949 Appendix A, all in deterministic encoding already, and their
+// non-preferred and indefinite-length encodings from the same appendix.
+func TestCanonicalizeCBOR_RFCVectors(t *testing.T) {
+	deterministic := []string{
+		// Integers and bignums
+		"00", "01", "0a", "17", "1818", "1819", "1864", "1903e8", "1a000f4240",
+		"1b000000e8d4a51000", "1bffffffffffffffff", "c249010000000000000000",
+		"3bffffffffffffffff", "c349010000000000000000", "20", "29", "3863", "3903e7",
+		// Floats
+		"f90000", "f98000", "f93c00", "fb3ff199999999999a", "f93e00", "f97bff", "fa47c35000",
+		"fa7f7fffff", "fb7e37e43c8800759c", "f90001", "f90400", "f9c400", "fbc010666666666666",
+		"f97c00", "f97e00", "f9fc00",
+		// Simple values
+		"f4", "f5", "f6", "f7", "f0", "f8ff",
+		// Tags
+		"c074323031332d30332d32315432303a30343a30305a", "c11a514b67b0", "c1fb41d452d9ec200000",
+		"d74401020304", "d818456449455446", "d82076687474703a2f2f7777772e6578616d706c652e636f6d",
+		// Strings
+		"40", "4401020304", "60", "6161", "6449455446", "62225c", "62c3bc", "63e6b0b4", "64f0908591",
+		// Arrays and maps
+		"80", "83010203", "8301820203820405",
+		"98190102030405060708090a0b0c0d0e0f101112131415161718181819",
+		"a0", "a201020304", "a26161016162820203", "826161a161626163",
+		"a56161614161626142616361436164614461656145",
+	}
+	for _, item := range deterministic {
+		raw, _ := hex.DecodeString(item)
+		got, err := CanonicalizeCBOR(raw)
+		if err != nil {
+			t.Errorf("%s: %v", item, err)
+			continue
+		}
+		if hex.EncodeToString(got) != item {
+			t.Errorf("%s: expected it unchanged, got %x", item, got)
+		}
+	}
+
+	rewritten := []struct{ raw, want string }{
+		// Floats in more precision than their value needs
+		{"fa7f800000", "f97c00"},
+		{"fa7fc00000", "f97e00"},
+		{"faff800000", "f9fc00"},
+		{"fb7ff0000000000000", "f97c00"},
+		{"fb7ff8000000000000", "f97e00"},
+		{"fbfff0000000000000", "f9fc00"},
+		{"fb3ff8000000000000", "f93e00"},
+		// Indefinite lengths
+		{"5f42010243030405ff", "450102030405"},
+		{"7f657374726561646d696e67ff", "6973747265616d696e67"},
+		{"9fff", "80"},
+		{"9f018202039f0405ffff", "8301820203820405"},
+		{"9f01820203820405ff", "8301820203820405"},
+		{"83018202039f0405ff", "8301820203820405"},
+		{"83019f0203ff820405", "8301820203820405"},
+		{"9f0102030405060708090a0b0c0d0e0f101112131415161718181819ff", "98190102030405060708090a0b0c0d0e0f101112131415161718181819"},
+		{"bf61610161629f0203ffff", "a26161016162820203"},
+		{"826161bf61626163ff", "826161a161626163"},
+		{"bf6346756ef563416d7421ff", "a263416d74216346756ef5"},
+		// Arguments longer than needed, and bignums that fit in 64 bits
+		{"1b0000000000000001", "01"},
+		{"3a000003e7", "3903e7"},
+		{"d900014401020304", "c14401020304"},
+		{"c24900ffffffffffffffff", "1bffffffffffffffff"},
+		{"c240", "00"},
+		{"c34100", "20"},
+		// Map keys sorted by their encoding: shorter first, then bytewise
+		{"a3626161016161006162f6", "a3616100 6162f6 62616101"},
+		{"a2616101190100f4", "a2190100f4616101"},
+	}
+	for _, tt := range rewritten {
+		raw, _ := hex.DecodeString(tt.raw)
+		got, err := CanonicalizeCBOR(raw)
+		if err != nil {
+			t.Errorf("%s: %v", tt.raw, err)
+			continue
+		}
+		if want := strings.ReplaceAll(tt.want, " ", ""); hex.EncodeToString(got) != want {
+			t.Errorf("%s: got %x, want %s", tt.raw, got, want)
+		}
+
+		// Both encodings hash alike
+		h1, _ := CanonicalizeAndHashCBOR(raw)
+		h2, _ := CanonicalizeAndHashCBOR(got)
+		if h1 != h2 {
+			t.Errorf("%s: got hashes %s and %s", tt.raw, h1, h2)
+		}
+	}
+}
+
+func TestCanonicalizeCBOR_Errors(t *testing.T) {
+	tests := []struct {
+		name, raw string
+		want      error
+	}{
+		{"duplicate key", "a201020103", ErrDuplicateKey},
+		{"duplicate key in another encoding", "a21801020103", ErrDuplicateKey},
+		{"duplicate text key in chunks", "a2626162017f61616162ff03", ErrDuplicateKey},
+		{"nested duplicate key", "81a1616aa2616201616202", ErrDuplicateKey},
+		{"too deep", strings.Repeat("81", 200) + "00", ErrTooDeep},
+		{"trailing bytes", "0100", ErrTrailingData},
+		{"empty", "", ErrInvalidCBOR},
+		{"truncated argument", "1903", ErrInvalidCBOR},
+		{"truncated string", "6461", ErrInvalidCBOR},
+		{"truncated array", "830102", ErrInvalidCBOR},
+		{"unterminated indefinite array", "9f0102", ErrInvalidCBOR},
+		{"reserved additional information", "1c", ErrInvalidCBOR},
+		{"indefinite integer", "1f", ErrInvalidCBOR},
+		{"stray break", "ff", ErrInvalidCBOR},
+		{"two-byte simple value below 32", "f818", ErrInvalidCBOR},
+		{"invalid UTF-8", "62c328", ErrInvalidCBOR},
+		{"nested indefinite chunk", "5f5f4101ffff", ErrInvalidCBOR},
+		{"chunk of another type", "5f6161ff", ErrInvalidCBOR},
+		{"bignum of a text string", "c26161", ErrInvalidCBOR},
+		{"huge declared length", "9bffffffffffffffff", ErrInvalidCBOR},
+	}
+	for _, tt := range tests {
+		raw, _ := hex.DecodeString(tt.raw)
+		_, err := CanonicalizeAndHashCBOR(raw)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+// JSON converts to the CBOR of the same data model, which hashes alike.
+func TestJSONToCanonicalCBOR(t *testing.T) {
+	tests := []struct{ json, cbor string }{
+		{`{"b": [2, 3], "a": 1}`, "a26161016162820203"},
+		{`["a", {"b": "c"}]`, "826161a161626163"},
+		{`{"e":"E","d":"D","c":"C","b":"B","a":"A"}`, "a56161614161626142616361436164614461656145"},
+		{`{"aa": 0, "b": 1}`, "a261620162616100"},
+		{`[true, false, null, "ü"]`, "84f5f4f662c3bc"},
+		{`[0, -0, 1000000, -1000, 18446744073709551615]`, "8500001a000f42403903e71bffffffffffffffff"},
+		{`[18446744073709551616, -18446744073709551616, -18446744073709551617]`, "83c2490100000000000000003bffffffffffffffffc349010000000000000000"},
+		{`[1.0, 1.5, -4.0, 100000.0, 1e300, -4.1, 1.1]`, "87f93c00f93e00f9c400fa47c35000fb7e37e43c8800759cfbc010666666666666fb3ff199999999999a"},
+	}
+	for _, tt := range tests {
+		got, err := JSONToCanonicalCBOR([]byte(tt.json))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.json, err)
+		}
+		if hex.EncodeToString(got) != tt.cbor {
+			t.Errorf("%s: got %x, want %s", tt.json, got, tt.cbor)
+		}
+
+		raw, _ := hex.DecodeString(tt.cbor)
+		want, _ := CanonicalizeAndHashCBOR(raw)
+		if got, _ := JSONToCanonicalCBORHash([]byte(tt.json)); got != want {
+			t.Errorf("%s: got hash %s, want %s", tt.json, got, want)
+		}
+	}
+
+	for raw, want := range map[string]error{
+		`{"a":1,"a":2}`:                       ErrDuplicateKey,
+		`[1] [2]`:                             ErrTrailingData,
+		`[1`:                                  ErrInvalidJSON,
+		`[` + strings.Repeat("9", 1001) + `]`: nil,
+		`[1e400]`:                             nil,
+	} {
+		_, err := JSONToCanonicalCBORHash([]byte(raw))
+		if err == nil || want != nil && !errors.Is(err, want) {
+			t.Errorf("%.40s: expected %v, got %v", raw, want, err)
+		}
+	}
+}
+
+// The deterministic encoding of an item is its own deterministic encoding.
+func FuzzCanonicalizeCBOR(f *testing.F) {
+	for _, seed := range []string{"a26161016162820203", "bf6346756ef563416d7421ff", "fa7f800000", "c24900ffffffffffffffff", "5f42010243030405ff"} {
+		raw, _ := hex.DecodeString(seed)
+		f.Add(raw)
+	}
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		canonical, err := CanonicalizeCBOR(raw)
+		if err != nil {
+			return
+		}
+		again, err := CanonicalizeCBOR(canonical)
+		if err != nil || hex.EncodeToString(again) != hex.EncodeToString(canonical) {
+			t.Fatalf("%x: canonical form %x gave %x, %v", raw, canonical, again, err)
+		}
+	})
+}
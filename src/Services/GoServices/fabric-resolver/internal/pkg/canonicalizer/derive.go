@@ -0,0 +1,42 @@
+package canonicalizer
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// commitKeyLabel starts the HKDF info of derived commitment keys, separating them
+// from any other use of the master key.
+const commitKeyLabel = "fabric-resolver/commit-key/v1"
+
+// DeriveCommitKey derives the 32-byte commitment key of context, such as a tenant or
+// credential type, from master with HKDF-SHA256. Commitments under keys of different
+// contexts are unrelated, so a leaked or rotated context key leaves the others intact.
+// The info parameter is the label followed by the big-endian uint32 length of context
+// and context itself, so that no two contexts share an info string.
+func DeriveCommitKey(master []byte, context string) ([]byte, error) {
+	if len(master) < MinHMACKeyLen {
+		return nil, ErrKeyTooShort
+	}
+	if context == "" {
+		return nil, errors.New("key derivation context is required")
+	}
+
+	info := make([]byte, 0, len(commitKeyLabel)+4+len(context))
+	info = append(info, commitKeyLabel...)
+	info = binary.BigEndian.AppendUint32(info, uint32(len(context)))
+	info = append(info, context...)
+	return hkdf.Key(sha256.New, master, nil, string(info), sha256.Size)
+}
+
+// CanonicalizeAndCommitJSONDerived is CanonicalizeAndCommitJSON under the key
+// DeriveCommitKey derives from master for context.
+func CanonicalizeAndCommitJSONDerived(raw []byte, master []byte, context string) (string, error) {
+	key, err := DeriveCommitKey(master, context)
+	if err != nil {
+		return "", err
+	}
+	return CanonicalizeAndCommitJSON(raw, key)
+}
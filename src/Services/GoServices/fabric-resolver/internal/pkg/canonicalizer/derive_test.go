@@ -0,0 +1,57 @@
+package canonicalizer
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// Derived keys of the master key 00 01 ... 1f, computed independently with Python's
+// hmac module per RFC 5869. A change here invalidates every derived commitment.
+func TestDeriveCommitKey_Vectors(t *testing.T) {
+	master := make([]byte, 32)
+	for i := range master {
+		master[i] = byte(i)
+	}
+
+	tests := []struct {
+		context, key, commitment string
+	}{
+		{"tenant-a", "327ac84e84adc652cc33bef9d012403776e35e7d3bd41248175dd87bcac4d1c6", "bdc7ea0cd2a92bc91e8bc2d1618a76b15c1abc0474f4db0b02d0d235f5f6ae76"},
+		{"VerifiableID", "dd7541b859df96c5e2819ff1aedc193a7211d53c63c0f7f6c1667a0d9cab6576", "f80299886338316ec39b106fd427c5412e1574b376001b4372e718bb57ee1460"},
+		{"tenant-a\x00", "cd12f23f0442da3bd272aef33b8b3a2ad650c2be95f6cc16e33a079e61c94940", "fdee3b2ae22e71c630251b66a8bb409ef891d6e4606ee838aa14d6e488bd44a5"},
+	}
+	for _, tt := range tests {
+		key, err := DeriveCommitKey(master, tt.context)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.context, err)
+		}
+		if got := hex.EncodeToString(key); got != tt.key {
+			t.Errorf("%q: got key %s, want %s", tt.context, got, tt.key)
+		}
+
+		commitment, err := CanonicalizeAndCommitJSONDerived([]byte(`{"b":2,"a":1}`), master, tt.context)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.context, err)
+		}
+		if commitment != tt.commitment {
+			t.Errorf("%q: got commitment %s, want %s", tt.context, commitment, tt.commitment)
+		}
+	}
+}
+
+func TestDeriveCommitKey_Errors(t *testing.T) {
+	master := []byte("this-is-a-32-byte-secret-key-123")
+	if _, err := DeriveCommitKey(master[:31], "tenant-a"); !errors.Is(err, ErrKeyTooShort) {
+		t.Error("expected a short master key to be rejected")
+	}
+	if _, err := DeriveCommitKey(master, ""); err == nil {
+		t.Error("expected an empty context to be rejected")
+	}
+	if _, err := CanonicalizeAndCommitJSONDerived([]byte(`{}`), master[:31], "tenant-a"); !errors.Is(err, ErrKeyTooShort) {
+		t.Error("expected a short master key to be rejected")
+	}
+	if _, err := CanonicalizeAndCommitJSONDerived([]byte(`{} x`), master, "tenant-a"); !errors.Is(err, ErrTrailingData) {
+		t.Error("expected trailing data to be rejected")
+	}
+}
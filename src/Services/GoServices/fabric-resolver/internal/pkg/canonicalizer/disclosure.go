@@ -0,0 +1,157 @@
+package canonicalizer
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Digest commits to each field of a JSON object separately, so that one field can
+// be disclosed and verified against Root without revealing the others.
+//
+// The digest of a field is HMAC-SHA256(key, len(path) || path || len(value) || value || salt),
+// where path is the field's JSON Pointer, value its canonical form per CanonicalizeJSON,
+// lengths are big-endian uint32 and salt is SaltLen random bytes of its own. Root is the
+// SHA-256 of the field digests, sorted, concatenated as bytes.
+type Digest struct {
+	// Root is the hex hash to anchor.
+	Root string `json:"root"`
+
+	// Fields are the hex field digests, sorted. They reveal nothing without the salts
+	// and are given to verifiers along with Root.
+	Fields []string `json:"fields"`
+
+	// Salts holds the salt of each field by path. They stay with the holder of the
+	// document, who discloses a field by giving its path, value and salt.
+	Salts map[string][]byte `json:"salts"`
+}
+
+// DisclosureDigest returns the Digest of the members of the JSON object raw, under an
+// HMAC key of at least MinHMACKeyLen bytes.
+func DisclosureDigest(raw []byte, key []byte) (*Digest, error) {
+	return DisclosureDigestWithDepth(raw, key, 1)
+}
+
+// DisclosureDigestWithDepth is DisclosureDigest with the members of objects nested up
+// to depth levels as fields: at depth 2, {"address":{"city":"Aarhus"}} has the field
+// /address/city rather than /address. Arrays, and empty objects, are always fields whole.
+func DisclosureDigestWithDepth(raw []byte, key []byte, depth int) (*Digest, error) {
+	if len(key) < MinHMACKeyLen {
+		return nil, ErrKeyTooShort
+	}
+	if depth < 1 {
+		return nil, fmt.Errorf("disclosure depth must be at least 1, got %d", depth)
+	}
+	v, err := decodeJSON(raw, Options{})
+	if err != nil {
+		return nil, err
+	}
+	object, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("disclosure digests are of JSON objects")
+	}
+
+	d := &Digest{Salts: map[string][]byte{}}
+	var digests [][]byte
+	var addFields func(object map[string]interface{}, prefix string, level int) error
+	addFields = func(object map[string]interface{}, prefix string, level int) error {
+		for name, value := range object {
+			path := prefix + "/" + escapePointer(name)
+			if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 && level < depth {
+				if err := addFields(nested, path, level+1); err != nil {
+					return err
+				}
+				continue
+			}
+
+			canonicalBytes, err := Canonicalize(value)
+			if err != nil {
+				return err
+			}
+			salt := make([]byte, SaltLen)
+			if _, err := rand.Read(salt); err != nil {
+				return fmt.Errorf("failed to generate salt: %w", err)
+			}
+			d.Salts[path] = salt
+			digests = append(digests, fieldDigest(key, path, canonicalBytes, salt))
+		}
+		return nil
+	}
+	if err := addFields(object, "", 1); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(digests, bytes.Compare)
+	d.Fields = make([]string, len(digests))
+	for i, digest := range digests {
+		d.Fields[i] = hex.EncodeToString(digest)
+	}
+	d.Root = hex.EncodeToString(disclosureRoot(digests))
+	return d, nil
+}
+
+// VerifyDisclosure reports whether the field at path, with value and salt, is one of
+// fields and fields are those of root. Verifiers learn nothing of the other fields.
+// Malformed input does not verify.
+func VerifyDisclosure(root string, fields []string, path string, value json.RawMessage, salt, key []byte) bool {
+	if len(key) < MinHMACKeyLen || len(salt) != SaltLen {
+		return false
+	}
+	expectedRoot, err := hex.DecodeString(root)
+	if err != nil {
+		return false
+	}
+	digests := make([][]byte, len(fields))
+	for i, field := range fields {
+		if digests[i], err = hex.DecodeString(field); err != nil {
+			return false
+		}
+	}
+	slices.SortFunc(digests, bytes.Compare)
+	if !hmac.Equal(disclosureRoot(digests), expectedRoot) {
+		return false
+	}
+
+	canonicalBytes, err := CanonicalizeJSON(value)
+	if err != nil {
+		return false
+	}
+	disclosed := fieldDigest(key, path, canonicalBytes, salt)
+	found := false
+	for _, digest := range digests {
+		// Compare with every digest, so that timing does not tell which field it is
+		if hmac.Equal(digest, disclosed) {
+			found = true
+		}
+	}
+	return found
+}
+
+func fieldDigest(key []byte, path string, canonicalValue, salt []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(path)))
+	mac.Write(length[:])
+	mac.Write([]byte(path))
+	binary.BigEndian.PutUint32(length[:], uint32(len(canonicalValue)))
+	mac.Write(length[:])
+	mac.Write(canonicalValue)
+	mac.Write(salt)
+	return mac.Sum(nil)
+}
+
+// disclosureRoot hashes sorted field digests.
+func disclosureRoot(digests [][]byte) []byte {
+	h := sha256.New()
+	for _, digest := range digests {
+		h.Write(digest)
+	}
+	return h.Sum(nil)
+}
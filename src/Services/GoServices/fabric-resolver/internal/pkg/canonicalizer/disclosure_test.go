@@ -0,0 +1,127 @@
+package canonicalizer
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var disclosureKey = []byte("this-is-a-32-byte-secret-key-123")
+
+const disclosureDoc = `{"name":"Alice","dob":"1990-01-01","address":{"city":"Aarhus","zip":"8000"},"degrees":["BSc","MSc"],"a/b":true}`
+
+func TestDisclosureDigest_DisclosedFieldVerifies(t *testing.T) {
+	d, err := DisclosureDigest([]byte(disclosureDoc), disclosureKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Fields) != 5 || len(d.Salts) != 5 || len(d.Root) != 64 {
+		t.Fatalf("unexpected digest %+v", d)
+	}
+	if !slices.IsSorted(d.Fields) {
+		t.Error("expected sorted field digests")
+	}
+
+	// Each field verifies on its own, values in any JSON form
+	disclosures := map[string]string{
+		"/name":    `"Alice"`,
+		"/dob":     `"1990-01-01"`,
+		"/address": `{ "zip": "8000", "city": "Aarhus" }`,
+		"/degrees": `["BSc","MSc"]`,
+		"/a~1b":    `true`,
+	}
+	for path, value := range disclosures {
+		if !VerifyDisclosure(d.Root, d.Fields, path, json.RawMessage(value), d.Salts[path], disclosureKey) {
+			t.Errorf("%s: expected the disclosure to verify", path)
+		}
+	}
+
+	// Undisclosed fields stay hidden: nothing published contains their values
+	published, _ := json.Marshal(struct {
+		Root   string
+		Fields []string
+	}{d.Root, d.Fields})
+	for _, secret := range []string{"Alice", "1990-01-01", "Aarhus", "MSc"} {
+		if strings.Contains(string(published), secret) {
+			t.Errorf("expected %s not to be published", secret)
+		}
+	}
+	// and a correct guess does not verify without the field's salt
+	if VerifyDisclosure(d.Root, d.Fields, "/dob", json.RawMessage(`"1990-01-01"`), d.Salts["/name"], disclosureKey) {
+		t.Error("expected another field's salt not to verify")
+	}
+
+	// Salts are fresh per digest, so the same document has another root
+	d2, _ := DisclosureDigest([]byte(disclosureDoc), disclosureKey)
+	if d2.Root == d.Root || slices.Equal(d2.Salts["/name"], d.Salts["/name"]) {
+		t.Error("expected fresh salts and root")
+	}
+}
+
+func TestVerifyDisclosure_TamperingFails(t *testing.T) {
+	d, err := DisclosureDigest([]byte(disclosureDoc), disclosureKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt := d.Salts["/dob"]
+	value := json.RawMessage(`"1990-01-01"`)
+
+	otherKey := []byte("this-is-another-32-byte-key-4567")
+	otherFields := slices.Clone(d.Fields)
+	otherFields[0] = strings.Repeat("0", 64)
+
+	tests := map[string]bool{
+		"value":      VerifyDisclosure(d.Root, d.Fields, "/dob", json.RawMessage(`"1990-01-02"`), salt, disclosureKey),
+		"path":       VerifyDisclosure(d.Root, d.Fields, "/name", value, salt, disclosureKey),
+		"salt":       VerifyDisclosure(d.Root, d.Fields, "/dob", value, append([]byte{salt[0] ^ 1}, salt[1:]...), disclosureKey),
+		"key":        VerifyDisclosure(d.Root, d.Fields, "/dob", value, salt, otherKey),
+		"fields":     VerifyDisclosure(d.Root, otherFields, "/dob", value, salt, disclosureKey),
+		"root":       VerifyDisclosure(strings.Repeat("0", 64), d.Fields, "/dob", value, salt, disclosureKey),
+		"short salt": VerifyDisclosure(d.Root, d.Fields, "/dob", value, salt[:8], disclosureKey),
+		"not hex":    VerifyDisclosure("zz"+d.Root[2:], d.Fields, "/dob", value, salt, disclosureKey),
+		"not JSON":   VerifyDisclosure(d.Root, d.Fields, "/dob", json.RawMessage(`"1990`), salt, disclosureKey),
+	}
+	for name, verified := range tests {
+		if verified {
+			t.Errorf("tampered %s: expected the disclosure not to verify", name)
+		}
+	}
+}
+
+func TestDisclosureDigestWithDepth(t *testing.T) {
+	d, err := DisclosureDigestWithDepth([]byte(`{"name":"Alice","address":{"city":"Aarhus","geo":{"lat":56}},"empty":{}}`), disclosureKey, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for path := range d.Salts {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	if want := []string{"/address/city", "/address/geo", "/empty", "/name"}; !slices.Equal(paths, want) {
+		t.Errorf("got fields %v, want %v", paths, want)
+	}
+	if !VerifyDisclosure(d.Root, d.Fields, "/address/city", json.RawMessage(`"Aarhus"`), d.Salts["/address/city"], disclosureKey) {
+		t.Error("expected a nested field to verify")
+	}
+	if !VerifyDisclosure(d.Root, d.Fields, "/address/geo", json.RawMessage(`{"lat":56}`), d.Salts["/address/geo"], disclosureKey) {
+		t.Error("expected the object below the depth to verify whole")
+	}
+}
+
+func TestDisclosureDigest_Errors(t *testing.T) {
+	if _, err := DisclosureDigest([]byte(`["not","an","object"]`), disclosureKey); err == nil {
+		t.Error("expected an array to be rejected")
+	}
+	if _, err := DisclosureDigest([]byte(disclosureDoc), disclosureKey[:31]); !errors.Is(err, ErrKeyTooShort) {
+		t.Error("expected a short key to be rejected")
+	}
+	if _, err := DisclosureDigestWithDepth([]byte(disclosureDoc), disclosureKey, 0); err == nil {
+		t.Error("expected depth 0 to be rejected")
+	}
+	if _, err := DisclosureDigest([]byte(`{"a":1,"a":2}`), disclosureKey); !errors.Is(err, ErrDuplicateKey) {
+		t.Error("expected duplicate keys to be rejected")
+	}
+}
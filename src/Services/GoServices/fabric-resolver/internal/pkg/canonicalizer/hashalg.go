@@ -0,0 +1,107 @@
+package canonicalizer
+
+import (
+	"crypto"
+	_ "crypto/sha256" // registers crypto.SHA256
+	_ "crypto/sha3"   // registers crypto.SHA3_256
+	_ "crypto/sha512" // registers crypto.SHA512
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	_ "golang.org/x/crypto/blake2b" // registers crypto.BLAKE2b_256
+)
+
+// HashAlg names a digest algorithm applied to the canonical form. The name is
+// also the prefix of self-describing hashes, as in "sha256:<hex>".
+type HashAlg string
+
+// The algorithms known without registration.
+const (
+	SHA256     HashAlg = "sha256"
+	SHA512     HashAlg = "sha512"
+	SHA3_256   HashAlg = "sha3-256"
+	BLAKE2b256 HashAlg = "blake2b-256"
+)
+
+var (
+	hashAlgsMu sync.RWMutex
+	hashAlgs   = map[HashAlg]crypto.Hash{
+		SHA256:     crypto.SHA256,
+		SHA512:     crypto.SHA512,
+		SHA3_256:   crypto.SHA3_256,
+		BLAKE2b256: crypto.BLAKE2b_256,
+	}
+)
+
+// RegisterHashAlg makes h available under alg, e.g. RegisterHashAlg("sha384", crypto.SHA384).
+// The package implementing h must be linked into the binary. Names may not contain
+// ":" and may not be registered twice.
+func RegisterHashAlg(alg HashAlg, h crypto.Hash) error {
+	if alg == "" || strings.Contains(string(alg), ":") {
+		return fmt.Errorf("invalid hash algorithm name %q", alg)
+	}
+	if !h.Available() {
+		return fmt.Errorf("hash function %v of %s is not linked into the binary", h, alg)
+	}
+
+	hashAlgsMu.Lock()
+	defer hashAlgsMu.Unlock()
+	if _, ok := hashAlgs[alg]; ok {
+		return fmt.Errorf("hash algorithm %s is already registered", alg)
+	}
+	hashAlgs[alg] = h
+	return nil
+}
+
+// CanonicalizeAndHashJSONWith canonicalizes raw JSON bytes per CanonicalizeJSON and
+// returns their hex digest under alg. CanonicalizeAndHashJSON is the SHA256 shortcut.
+func CanonicalizeAndHashJSONWith(raw []byte, alg HashAlg) (string, error) {
+	canonicalBytes, err := CanonicalizeJSON(raw)
+	if err != nil {
+		return "", err
+	}
+	return hashWith(canonicalBytes, alg)
+}
+
+// CanonicalizeAndHashWith canonicalizes a Go value per Canonicalize and returns its
+// hex digest under alg. CanonicalizeAndHash is the SHA256 shortcut.
+func CanonicalizeAndHashWith(v interface{}, alg HashAlg) (string, error) {
+	canonicalBytes, err := Canonicalize(v)
+	if err != nil {
+		return "", err
+	}
+	return hashWith(canonicalBytes, alg)
+}
+
+// CanonicalizeAndHashJSONPrefixed is CanonicalizeAndHashJSONWith with the algorithm
+// prefixed, "<alg>:<hex>", so that stored hashes say how they were computed.
+func CanonicalizeAndHashJSONPrefixed(raw []byte, alg HashAlg) (string, error) {
+	digest, err := CanonicalizeAndHashJSONWith(raw, alg)
+	if err != nil {
+		return "", err
+	}
+	return string(alg) + ":" + digest, nil
+}
+
+func hashWith(data []byte, alg HashAlg) (string, error) {
+	digest, err := digestWith(data, alg)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest), nil
+}
+
+func digestWith(data []byte, alg HashAlg) ([]byte, error) {
+	hashAlgsMu.RLock()
+	h, ok := hashAlgs[alg]
+	hashAlgsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown hash algorithm %q", alg)
+	}
+
+	hasher := h.New()
+	hasher.Write(data)
+	return hasher.Sum(nil), nil
+}
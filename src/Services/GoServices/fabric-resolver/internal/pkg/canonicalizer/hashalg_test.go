@@ -0,0 +1,89 @@
+package canonicalizer
+
+import (
+	"crypto"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Digests of the canonical form {"a":1,"b":2}, computed independently with Python's hashlib.
+var knownDigests = map[HashAlg]string{
+	SHA256:     "43258cff783fe7036d8a43033f830adfc60ec037382473548ac742b888292777",
+	SHA512:     "b5da773f945631ed9943f66ab28641439d8895e350fb1fb9e21377bc63cd546bb68a5db808c57f846ddb195def323b315fe8917213aa34f996edebfa8f9653aa",
+	SHA3_256:   "8d7e099adfa6c36d94857146f8eeb916ad3cbfd6cb24f6e6be4ecc36d366431c",
+	BLAKE2b256: "a4087aa088066afe11bd73001c258d05a231590b9b1ea02424770d2d203c9589",
+}
+
+func TestCanonicalizeAndHashJSONWith_KnownDigests(t *testing.T) {
+	raw := []byte(`{"b": 2, "a": 1}`)
+	for alg, want := range knownDigests {
+		got, err := CanonicalizeAndHashJSONWith(raw, alg)
+		if err != nil {
+			t.Errorf("%s: %v", alg, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s: got %s, want %s", alg, got, want)
+		}
+
+		fromValue, _ := CanonicalizeAndHashWith(map[string]int{"a": 1, "b": 2}, alg)
+		if fromValue != want {
+			t.Errorf("%s: expected the Go value to hash alike, got %s", alg, fromValue)
+		}
+	}
+
+	// The existing functions are the SHA-256 shortcuts
+	if got, _ := CanonicalizeAndHashJSON(raw); got != knownDigests[SHA256] {
+		t.Errorf("expected CanonicalizeAndHashJSON to be SHA-256, got %s", got)
+	}
+}
+
+func TestCanonicalizeAndHashJSONPrefixed(t *testing.T) {
+	got, err := CanonicalizeAndHashJSONPrefixed([]byte(`{"a":1,"b":2}`), SHA3_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "sha3-256:" + knownDigests[SHA3_256]; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	if _, err := CanonicalizeAndHashJSONPrefixed([]byte(`{"a":1,"b":2}`), "md4"); err == nil {
+		t.Error("expected an unknown algorithm to be rejected")
+	}
+	if _, err := CanonicalizeAndHashJSONWith([]byte(`{"a":1} x`), SHA256); !errors.Is(err, ErrTrailingData) {
+		t.Error("expected trailing data to be rejected")
+	}
+}
+
+func TestRegisterHashAlg(t *testing.T) {
+	const sha384 HashAlg = "test-sha384"
+	if err := RegisterHashAlg(sha384, crypto.SHA384); err != nil {
+		t.Fatalf("RegisterHashAlg failed: %v", err)
+	}
+	got, err := CanonicalizeAndHashJSONWith([]byte(`{"a":1,"b":2}`), sha384)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "5b5061937d9429347654a4a661c91ebd23a83dd2233309e3d1a9eaab2085f2399ddfaee0fccfb405324e6bb5e008400b"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	tests := []struct {
+		alg  HashAlg
+		h    crypto.Hash
+		want string
+	}{
+		{sha384, crypto.SHA384, "already registered"},
+		{SHA256, crypto.SHA256, "already registered"},
+		{"", crypto.SHA384, "invalid"},
+		{"sha:384", crypto.SHA384, "invalid"},
+		{"test-md4", crypto.MD4, "not linked"},
+	}
+	for _, tt := range tests {
+		err := RegisterHashAlg(tt.alg, tt.h)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("RegisterHashAlg(%q): expected an error containing %q, got %v", tt.alg, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package canonicalizer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// CanonicalizeJCS canonicalizes raw JSON bytes per RFC 8785, the JSON Canonicalization
+// Scheme, which other platforms implement as well. Unlike the policy of
+// CanonicalizeAndHashJSON it does not keep numbers as written:
+// - Numbers are IEEE 754 doubles, serialized as ECMAScript does (1.0 is 1, 1E30 is 1e+30, -0 is 0).
+// - Object members are sorted by the UTF-16 code units of their names.
+// - Strings escape only ", \ and control characters; everything else is written as UTF-8.
+// - Input must be valid UTF-8, numbers finite doubles, and object member names unique.
+// - Strings must not escape unpaired surrogates, such as a lone "\ud800".
+func CanonicalizeJCS(raw []byte) ([]byte, error) {
+	return CanonicalizeJCSWithOptions(raw, Options{})
+}
+
+// CanonicalizeJCSWithOptions is CanonicalizeJCS under the limits of opts.
+func CanonicalizeJCSWithOptions(raw []byte, opts Options) ([]byte, error) {
+	if !utf8.Valid(raw) {
+		return nil, fmt.Errorf("%w: input is not valid UTF-8", ErrInvalidJSON)
+	}
+	if err := checkSurrogateEscapes(raw); err != nil {
+		return nil, err
+	}
+	v, err := decodeJSON(raw, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeJCS(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CanonicalizeAndHashJCS canonicalizes raw JSON bytes per RFC 8785 and returns a SHA-256 hash.
+func CanonicalizeAndHashJCS(raw []byte) (string, error) {
+	return CanonicalizeAndHashJCSWithOptions(raw, Options{})
+}
+
+// CanonicalizeAndHashJCSWithOptions is CanonicalizeAndHashJCS under the limits of opts.
+func CanonicalizeAndHashJCSWithOptions(raw []byte, opts Options) (string, error) {
+	canonicalBytes, err := CanonicalizeJCSWithOptions(raw, opts)
+	if err != nil {
+		return "", err
+	}
+	return hash(canonicalBytes), nil
+}
+
+// CanonicalizeAndCommitJCS canonicalizes raw JSON bytes per RFC 8785 and returns an
+// HMAC-SHA256 commitment. Requires a key of at least 32 bytes.
+func CanonicalizeAndCommitJCS(raw []byte, key []byte) (string, error) {
+	return CanonicalizeAndCommitJCSWithOptions(raw, key, Options{})
+}
+
+// CanonicalizeAndCommitJCSWithOptions is CanonicalizeAndCommitJCS under the limits of opts.
+func CanonicalizeAndCommitJCSWithOptions(raw []byte, key []byte, opts Options) (string, error) {
+	if len(key) < MinHMACKeyLen {
+		return "", ErrKeyTooShort
+	}
+
+	canonicalBytes, err := CanonicalizeJCSWithOptions(raw, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return commit(canonicalBytes, key), nil
+}
+
+// checkSurrogateEscapes rejects \u escapes of surrogates that are not a high one
+// directly followed by a low one, which encoding/json would silently replace with
+// U+FFFD; RFC 8785 requires such strings to be rejected. Backslashes only occur
+// in strings of valid JSON, and other malformed escapes are left to the decoder.
+func checkSurrogateEscapes(raw []byte) error {
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' {
+			continue
+		}
+		r, ok := escapedRune(raw, i)
+		i++ // past the escaped character
+		if !ok || !utf16.IsSurrogate(r) {
+			continue
+		}
+		if r < 0xDC00 {
+			if low, ok := escapedRune(raw, i+5); ok && low >= 0xDC00 && low <= 0xDFFF {
+				i += 9 // onto the last digit of the low escape
+				continue
+			}
+		}
+		return fmt.Errorf("%w: unpaired surrogate \\u%04x in string", ErrInvalidJSON, r)
+	}
+	return nil
+}
+
+// escapedRune returns the code unit of the \uXXXX escape at raw[i], if there is one.
+func escapedRune(raw []byte, i int) (rune, bool) {
+	if i+6 > len(raw) || raw[i] != '\\' || raw[i+1] != 'u' {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(string(raw[i+2:i+6]), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(n), true
+}
+
+func writeJCS(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return fmt.Errorf("number %s is not representable as a double", v)
+		}
+		s, err := es6Number(f)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case string:
+		writeJCSString(buf, v)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJCS(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.SortFunc(keys, compareUTF16)
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJCSString(buf, k)
+			buf.WriteByte(':')
+			if err := writeJCS(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unexpected JSON value of type %T", v)
+	}
+	return nil
+}
+
+// es6Number serializes f as ECMAScript's Number.prototype.toString does: the
+// shortest digits that round-trip, in exponent form below 1e-6 and from 1e21.
+func es6Number(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", errors.New("NaN and Infinity are not valid JSON numbers")
+	}
+	if f == 0 {
+		return "0", nil // -0 included
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs < 1e-6 || abs >= 1e21 {
+		format = 'e'
+	}
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if format == 'e' {
+		// Go pads the exponent to two digits: 1e-07 is 1e-7 in ECMAScript
+		if n := len(s); n >= 4 && s[n-4] == 'e' && s[n-2] == '0' {
+			s = s[:n-2] + s[n-1:]
+		}
+	}
+	return s, nil
+}
+
+// writeJCSString writes s quoted, escaping only what RFC 8785 requires.
+func writeJCSString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xf])
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// compareUTF16 orders strings by their UTF-16 code units, as RFC 8785 sorts member
+// names; it differs from byte order for characters outside the Basic Multilingual Plane.
+func compareUTF16(a, b string) int {
+	return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+}
@@ -0,0 +1,207 @@
+package canonicalizer
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+// The input and output test vectors of RFC 8785 (github.com/cyberphone/json-canonicalization).
+func TestCanonicalizeJCS_TestVectors(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{
+			name:  "arrays",
+			input: `[56, { "d": true, "10": null, "1": [ ] } ]`,
+			want:  `[56,{"1":[],"10":null,"d":true}]`,
+		},
+		{
+			name:  "french",
+			input: `{ "peach": "This sorting order", "péché": "is wrong according to French", "pêche": "but canonicalization MUST", "sin": "ignore locale" }`,
+			want:  `{"peach":"This sorting order","péché":"is wrong according to French","pêche":"but canonicalization MUST","sin":"ignore locale"}`,
+		},
+		{
+			name:  "structures",
+			input: `{ "1": {"f": {"f": "hi","F": 5} ,"\n": 56.0}, "10": { }, "": "empty", "a": { }, "111": [ {"e": "yes","E": "no" } ], "A": { } }`,
+			want:  `{"":"empty","1":{"\n":56,"f":{"F":5,"f":"hi"}},"10":{},"111":[{"E":"no","e":"yes"}],"A":{},"a":{}}`,
+		},
+		{
+			name:  "values",
+			input: `{ "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001], "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/", "literals": [null, true, false] }`,
+			want:  `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			name:  "weird",
+			input: `{ "\u20ac": "Euro Sign", "\r": "Carriage Return", "\u000a": "Newline", "1": "One", "\u0080": "Control\u007f", "\ud83d\ude02": "Smiley", "\u00f6": "Latin Small Letter O With Diaeresis", "\ufb33": "Hebrew Letter Dalet With Dagesh", "</script>": "Browser Challenge" }`,
+			want:  "{\"\\n\":\"Newline\",\"\\r\":\"Carriage Return\",\"1\":\"One\",\"</script>\":\"Browser Challenge\",\"\u0080\":\"Control\u007f\",\"ö\":\"Latin Small Letter O With Diaeresis\",\"€\":\"Euro Sign\",\"😂\":\"Smiley\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalizeJCS([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("CanonicalizeJCS failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// The number serialization samples of RFC 8785 Appendix B, as IEEE 754 bit patterns.
+func TestES6Number_RFC8785Samples(t *testing.T) {
+	tests := []struct {
+		bits uint64
+		want string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"}, // -0
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+
+	for _, tt := range tests {
+		got, err := es6Number(math.Float64frombits(tt.bits))
+		if err != nil {
+			t.Errorf("%016x: %v", tt.bits, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%016x: got %s, want %s", tt.bits, got, tt.want)
+		}
+	}
+
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := es6Number(f); err == nil {
+			t.Errorf("expected %v to be rejected", f)
+		}
+	}
+}
+
+func TestCanonicalizeJCS_Numbers(t *testing.T) {
+	tests := map[string]string{
+		"1e+30":    "1e+30",
+		"1E30":     "1e+30",
+		"-0":       "0",
+		"-0.0":     "0",
+		"0.000001": "0.000001",
+		"1.0":      "1",
+		"1e0":      "1",
+		"1e-7":     "1e-7",
+		"100":      "100",
+	}
+	for input, want := range tests {
+		got, err := CanonicalizeJCS([]byte(input))
+		if err != nil {
+			t.Errorf("%s: %v", input, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s: got %s, want %s", input, got, want)
+		}
+	}
+
+	if _, err := CanonicalizeJCS([]byte("1e400")); err == nil {
+		t.Error("expected a number beyond the double range to be rejected")
+	}
+}
+
+func TestCanonicalizeJCS_RejectsInvalidInput(t *testing.T) {
+	for input, want := range map[string]error{`{"a":1} {}`: ErrTrailingData, `{"a":`: ErrInvalidJSON, "\"\xff\"": ErrInvalidJSON} {
+		if _, err := CanonicalizeJCS([]byte(input)); !errors.Is(err, want) {
+			t.Errorf("expected %q to be rejected with %v, got %v", input, want, err)
+		}
+	}
+}
+
+func TestCanonicalizeJCS_RejectsUnpairedSurrogates(t *testing.T) {
+	for _, input := range []string{
+		`"\ud800"`,
+		`"\uDC00"`,
+		`"\ud800\u0041"`,
+		`"\udc00\ud800"`,
+		`{"\ud83d": 1}`,
+		`["\ud83d\ude02\ud800"]`,
+	} {
+		if _, err := CanonicalizeJCS([]byte(input)); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("expected %s to be rejected with ErrInvalidJSON, got %v", input, err)
+		}
+	}
+
+	// Pairs, and text that only looks like an escape, are kept
+	for input, want := range map[string]string{
+		`"\ud83d\ude02\n"`: "\"😂\\n\"",
+		`"\\ud800"`:        `"\\ud800"`,
+	} {
+		got, err := CanonicalizeJCS([]byte(input))
+		if err != nil || string(got) != want {
+			t.Errorf("expected %s to canonicalize to %s, got %s, %v", input, want, got, err)
+		}
+	}
+}
+
+// JCS and the default policy differ where the default keeps numbers as written.
+func TestCanonicalizeAndHashJCS_DiffersFromDefaultPolicy(t *testing.T) {
+	raw := []byte(`{"n":1.0}`)
+	jcsHash, err := CanonicalizeAndHashJCS(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultHash, err := CanonicalizeAndHashJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jcsHash == defaultHash {
+		t.Error("expected 1.0 to hash differently under JCS and the default policy")
+	}
+
+	same, _ := CanonicalizeAndHashJCS([]byte(`{ "n" : 1 }`))
+	if jcsHash != same {
+		t.Errorf("expected 1.0 and 1 to hash alike under JCS, got %s and %s", jcsHash, same)
+	}
+}
+
+func TestCanonicalizeAndCommitJCS(t *testing.T) {
+	key := []byte("this-is-a-32-byte-secret-key-123")
+	raw := []byte(`{"b":2,"a":1.50}`)
+
+	c1, err := CanonicalizeAndCommitJCS(raw, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, _ := CanonicalizeJCS(raw)
+	if want := commit(canonical, key); c1 != want || string(canonical) != `{"a":1.5,"b":2}` {
+		t.Errorf("expected the HMAC of %s, got %s", canonical, c1)
+	}
+
+	if _, err := CanonicalizeAndCommitJCS(raw, key[:31]); !errors.Is(err, ErrKeyTooShort) {
+		t.Error("expected a short key to be rejected")
+	}
+	if len(c1) != 2*32 {
+		t.Errorf("expected a hex SHA-256 HMAC, got %s", strconv.Quote(c1))
+	}
+}
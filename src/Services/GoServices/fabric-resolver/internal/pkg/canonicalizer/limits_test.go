@@ -0,0 +1,134 @@
+package canonicalizer
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func nestedArrays(depth int) []byte {
+	return []byte(strings.Repeat("[", depth) + strings.Repeat("]", depth))
+}
+
+func TestCanonicalizeJSON_MaxDepth(t *testing.T) {
+	if _, err := CanonicalizeJSON(nestedArrays(DefaultMaxDepth)); err != nil {
+		t.Errorf("expected %d levels to be accepted, got %v", DefaultMaxDepth, err)
+	}
+	if _, err := CanonicalizeJSON(nestedArrays(DefaultMaxDepth + 1)); !errors.Is(err, ErrTooDeep) {
+		t.Errorf("expected ErrTooDeep, got %v", err)
+	}
+
+	deepObject := []byte(strings.Repeat(`{"a":`, 5) + "1" + strings.Repeat("}", 5))
+	if _, err := CanonicalizeJSONWithOptions(deepObject, Options{MaxDepth: 4}); !errors.Is(err, ErrTooDeep) {
+		t.Errorf("expected ErrTooDeep under MaxDepth 4, got %v", err)
+	}
+	if _, err := CanonicalizeJSONWithOptions(deepObject, Options{MaxDepth: 5}); err != nil {
+		t.Errorf("expected 5 levels to be accepted under MaxDepth 5, got %v", err)
+	}
+	if _, err := CanonicalizeJCS(nestedArrays(DefaultMaxDepth + 1)); !errors.Is(err, ErrTooDeep) {
+		t.Errorf("expected JCS to apply the default depth, got %v", err)
+	}
+}
+
+// Regression: deep documents are rejected by the token scan, before the
+// recursive decode and encode, and never overflow the stack.
+func TestCanonicalizeJSON_TenThousandDeep(t *testing.T) {
+	for _, raw := range [][]byte{
+		nestedArrays(10000),
+		[]byte(strings.Repeat(`{"a":`, 10000) + "null" + strings.Repeat("}", 10000)),
+		[]byte(strings.Repeat("[", 100000)), // unterminated
+	} {
+		if _, err := CanonicalizeAndHashJSON(raw); !errors.Is(err, ErrTooDeep) {
+			t.Errorf("expected ErrTooDeep, got %v", err)
+		}
+		if _, err := CanonicalizeJCS(raw); !errors.Is(err, ErrTooDeep) {
+			t.Errorf("expected JCS to return ErrTooDeep, got %v", err)
+		}
+	}
+
+	// Without a limit encoding/json's own bound of 10000 levels still applies
+	if _, err := CanonicalizeJSONWithOptions(nestedArrays(10001), Options{MaxDepth: -1}); !errors.Is(err, ErrInvalidJSON) {
+		t.Error("expected encoding/json to reject 10001 levels")
+	}
+	if _, err := CanonicalizeJSONWithOptions(nestedArrays(5000), Options{MaxDepth: -1}); err != nil {
+		t.Errorf("expected 5000 levels to canonicalize without a limit, got %v", err)
+	}
+}
+
+func TestCanonicalizeJSON_MaxBytes(t *testing.T) {
+	big := []byte(`"` + strings.Repeat("x", DefaultMaxBytes) + `"`)
+	if _, err := CanonicalizeJSON(big); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+	if _, err := CanonicalizeJSONWithOptions(big, Options{MaxBytes: len(big)}); err != nil {
+		t.Errorf("expected a raised limit to accept the input, got %v", err)
+	}
+	if _, err := CanonicalizeJSONWithOptions(big, Options{MaxBytes: -1}); err != nil {
+		t.Errorf("expected no limit to accept the input, got %v", err)
+	}
+	if _, err := CanonicalizeAndHashJCSWithOptions([]byte(`{"a":1}`), Options{MaxBytes: 6}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestCanonicalizeJSON_MaxKeys(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString(`{"outer":[{`)
+	for i := range 4 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(`"k` + strconv.Itoa(i) + `":` + strconv.Itoa(i))
+	}
+	b.WriteString(`}]}`)
+	raw := b.Bytes()
+
+	if _, err := CanonicalizeJSON(raw); err != nil {
+		t.Errorf("expected no member limit by default, got %v", err)
+	}
+	if _, err := CanonicalizeJSONWithOptions(raw, Options{MaxKeys: 4}); err != nil {
+		t.Errorf("expected 4 members to be accepted, got %v", err)
+	}
+	_, err := CanonicalizeJSONWithOptions(raw, Options{MaxKeys: 3})
+	if !errors.Is(err, ErrTooLarge) || !strings.Contains(err.Error(), `"/outer/0"`) {
+		t.Errorf("expected ErrTooLarge naming /outer/0, got %v", err)
+	}
+	// Duplicates count as members when they are allowed
+	if _, err := CanonicalizeJSONWithOptions([]byte(`{"a":1,"a":2}`), Options{MaxKeys: 1, AllowDuplicateKeys: true}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+
+	key := []byte("this-is-a-32-byte-secret-key-123")
+	if _, err := CanonicalizeAndCommitJCSWithOptions(raw, key, Options{MaxKeys: 3}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func FuzzCanonicalizeJSON(f *testing.F) {
+	for _, seed := range []string{
+		`{"b":2,"a":[1,2.50,{"c":null}]}`,
+		`[[[[[[[[]]]]]]]]`,
+		`{"a":1,"a":2}`,
+		`"é<>&"`,
+		`{"a":` + strings.Repeat("[", 200),
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		canonical, err := CanonicalizeJSONWithOptions(raw, Options{MaxDepth: 32})
+		if err != nil {
+			return
+		}
+		// The canonical form is a fixed point
+		again, err := CanonicalizeJSON(canonical)
+		if err != nil {
+			t.Fatalf("canonical form %s does not canonicalize: %v", canonical, err)
+		}
+		if !bytes.Equal(canonical, again) {
+			t.Fatalf("canonical form is not stable: %s != %s", canonical, again)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package canonicalizer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"fabric-resolver/internal/pkg/merkle"
+)
+
+// ProofStep is one sibling on the path from an array element to the Merkle root.
+type ProofStep = merkle.ProofStep
+
+// MerkleizeJSONArray builds a Merkle tree over the elements of the JSON array raw
+// and returns its hex root, to anchor the batch, and the hex leaf hash of each
+// element, to prove it was in the batch.
+//
+// The tree is that of package merkle:
+//   - The leaf hash of an element is the SHA-256 of its canonical form per
+//     CanonicalizeJSON, as CanonicalizeAndHashJSON would give for it alone.
+//   - Tree leaves are SHA-256(0x00 || leaf hash) and interior nodes
+//     SHA-256(0x01 || left || right), so a node cannot pass for a leaf.
+//   - A node without a sibling is promoted to the next level unchanged, so a
+//     single-element array has the root SHA-256(0x00 || leaf hash) and an empty proof.
+//   - Elements must be unique, so that each has one position and one proof; an
+//     empty array or a repeated element is an error.
+func MerkleizeJSONArray(raw []byte) (root string, leaves []string, err error) {
+	tree, leafHashes, err := merkleizeJSONArray(raw)
+	if err != nil {
+		return "", nil, err
+	}
+	leaves = make([]string, len(leafHashes))
+	for i, leaf := range leafHashes {
+		leaves[i] = hex.EncodeToString(leaf)
+	}
+	return hex.EncodeToString(tree.Root()), leaves, nil
+}
+
+// BuildInclusionProof returns the proof that the element at index of the JSON array
+// raw is in the tree MerkleizeJSONArray builds.
+func BuildInclusionProof(raw []byte, index int) ([]ProofStep, error) {
+	tree, _, err := merkleizeJSONArray(raw)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Proof(index)
+}
+
+// VerifyInclusionProof reports whether proof links the hex leaf hash of an element to
+// the hex root of MerkleizeJSONArray. Malformed hashes do not verify.
+func VerifyInclusionProof(root string, leafHash string, proof []ProofStep) bool {
+	rootBytes, err := hex.DecodeString(root)
+	if err != nil || len(rootBytes) != sha256.Size {
+		return false
+	}
+	leaf, err := hex.DecodeString(leafHash)
+	if err != nil || len(leaf) != sha256.Size {
+		return false
+	}
+
+	return merkle.Verify(leaf, proof, rootBytes)
+}
+
+func merkleizeJSONArray(raw []byte) (*merkle.Tree, [][]byte, error) {
+	v, err := decodeJSON(raw, Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+	elements, ok := v.([]interface{})
+	if !ok {
+		return nil, nil, errors.New("a Merkle tree is built over a JSON array")
+	}
+
+	leaves := make([][]byte, len(elements))
+	for i, elem := range elements {
+		canonicalBytes, err := Canonicalize(elem)
+		if err != nil {
+			return nil, nil, err
+		}
+		sum := sha256.Sum256(canonicalBytes)
+		leaves[i] = sum[:]
+	}
+	tree, err := merkle.New(leaves)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tree, leaves, nil
+}
@@ -0,0 +1,93 @@
+package canonicalizer
+
+import (
+	"errors"
+	"testing"
+
+	"fabric-resolver/internal/pkg/merkle"
+)
+
+// Roots computed independently with Python's hashlib per the documented scheme.
+// A change here means anchored batch roots no longer verify.
+func TestMerkleizeJSONArray_Vectors(t *testing.T) {
+	tests := []struct {
+		name, raw, root, firstLeaf string
+	}{
+		{"single", `[{"a": 1}]`, "99f1dca178a56705d03d47e75719615122fb66199487917faa038d7bb5e791b8", "015abd7f5cc57a2dd94b7590f04ad8084273905ee33ec5cebeae62276a97f862"},
+		{"two", `[{"id":1},{"id":2}]`, "1ce2dc95f334de52dfdb23235444280b920304371edd257c13ddb6ae3715e8c1", "037c9214eef74cc3887f3a4f085b4e17d76280dafd273b0ee160c09c4ba1cfd4"},
+		{"odd", `[{"id":1}, {"id":2}, {"id":3}, {"id":4}, {"id":5}]`, "fb81979751076c9096ea2024577ee87c5fea970273ada6650ee6e98edd83c8a2", "037c9214eef74cc3887f3a4f085b4e17d76280dafd273b0ee160c09c4ba1cfd4"},
+	}
+
+	for _, tt := range tests {
+		root, leaves, err := MerkleizeJSONArray([]byte(tt.raw))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if root != tt.root || leaves[0] != tt.firstLeaf {
+			t.Errorf("%s: got root %s, first leaf %s", tt.name, root, leaves[0])
+		}
+
+		// Leaves are the hashes of the elements on their own
+		for i, leaf := range leaves {
+			proof, err := BuildInclusionProof([]byte(tt.raw), i)
+			if err != nil {
+				t.Fatalf("%s: proof %d: %v", tt.name, i, err)
+			}
+			if !VerifyInclusionProof(root, leaf, proof) {
+				t.Errorf("%s: expected element %d to verify", tt.name, i)
+			}
+		}
+		if tt.name == "single" {
+			if proof, _ := BuildInclusionProof([]byte(tt.raw), 0); len(proof) != 0 {
+				t.Errorf("expected an empty proof for a single element, got %d steps", len(proof))
+			}
+		}
+	}
+
+	// Element hashes are canonical: key order and whitespace do not matter
+	_, leaves, _ := MerkleizeJSONArray([]byte(`[{"b":2,"a":1}]`))
+	if want, _ := CanonicalizeAndHashJSON([]byte(`{"a": 1, "b": 2}`)); leaves[0] != want {
+		t.Errorf("expected the element hash %s, got %s", want, leaves[0])
+	}
+}
+
+func TestVerifyInclusionProof_RejectsTampering(t *testing.T) {
+	raw := []byte(`["a","b","c"]`)
+	root, leaves, err := MerkleizeJSONArray(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, _ := BuildInclusionProof(raw, 2)
+
+	if VerifyInclusionProof(root, leaves[1], proof) {
+		t.Error("expected another leaf not to verify with the proof")
+	}
+	if VerifyInclusionProof(leaves[0], leaves[2], proof) {
+		t.Error("expected another root not to verify")
+	}
+	if VerifyInclusionProof(root, leaves[2], nil) {
+		t.Error("expected a missing proof not to verify")
+	}
+	flipped := []ProofStep{{Hash: proof[0].Hash, Left: !proof[0].Left}}
+	if VerifyInclusionProof(root, leaves[2], flipped) {
+		t.Error("expected a flipped step not to verify")
+	}
+	if VerifyInclusionProof("zz"+root[2:], leaves[2], proof) || VerifyInclusionProof(root, leaves[2][:10], proof) {
+		t.Error("expected malformed hashes not to verify")
+	}
+}
+
+func TestMerkleizeJSONArray_Errors(t *testing.T) {
+	if _, _, err := MerkleizeJSONArray([]byte(`[]`)); !errors.Is(err, merkle.ErrNoLeaves) {
+		t.Errorf("expected ErrNoLeaves, got %v", err)
+	}
+	if _, _, err := MerkleizeJSONArray([]byte(`[{"a":1},{ "a" : 1 }]`)); !errors.Is(err, merkle.ErrDuplicateLeaf) {
+		t.Errorf("expected canonically equal elements to be duplicates, got %v", err)
+	}
+	if _, _, err := MerkleizeJSONArray([]byte(`{"a":[1]}`)); err == nil {
+		t.Error("expected an object to be rejected")
+	}
+	if _, err := BuildInclusionProof([]byte(`[1,2]`), 2); !errors.Is(err, merkle.ErrLeafIndex) {
+		t.Errorf("expected ErrLeafIndex, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package canonicalizer
+
+import (
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"fabric-resolver/internal/pkg/multibase"
+)
+
+// Multihash codes (https://github.com/multiformats/multicodec) of the built-in
+// hash algorithms.
+const (
+	MultihashSHA256     uint64 = 0x12
+	MultihashSHA512     uint64 = 0x13
+	MultihashSHA3_256   uint64 = 0x16
+	MultihashBLAKE2b256 uint64 = 0xb220
+)
+
+var multihashAlgs = map[uint64]HashAlg{
+	MultihashSHA256:     SHA256,
+	MultihashSHA512:     SHA512,
+	MultihashSHA3_256:   SHA3_256,
+	MultihashBLAKE2b256: BLAKE2b256,
+}
+
+// Multihash is a parsed multihash: a digest and the code of its algorithm.
+type Multihash struct {
+	Code   uint64
+	Digest []byte
+}
+
+// CanonicalizeAndMultihashJSON canonicalizes raw JSON bytes per CanonicalizeJSON and
+// returns their digest under the algorithm of code as a base58btc multibase
+// multihash, e.g. "zQm..." for SHA-256.
+func CanonicalizeAndMultihashJSON(raw []byte, code uint64) (string, error) {
+	return CanonicalizeAndMultihashJSONAs(raw, code, multibase.Base58BTC)
+}
+
+// CanonicalizeAndMultihashJSONAs is CanonicalizeAndMultihashJSON in the multibase
+// encoding named by base, e.g. multibase.Base64URL for "uEiB..." SHA-256 values.
+func CanonicalizeAndMultihashJSONAs(raw []byte, code uint64, base byte) (string, error) {
+	alg, ok := multihashAlgs[code]
+	if !ok {
+		return "", fmt.Errorf("unsupported multihash code 0x%x", code)
+	}
+	canonicalBytes, err := CanonicalizeJSON(raw)
+	if err != nil {
+		return "", err
+	}
+	digest, err := digestWith(canonicalBytes, alg)
+	if err != nil {
+		return "", err
+	}
+	return multibase.EncodeAs(base, Multihash{Code: code, Digest: digest}.Bytes())
+}
+
+// ParseMultihash decodes a multibase multihash such as CanonicalizeAndMultihashJSON returns.
+// Any code is accepted; MatchesJSON tells whether the algorithm is supported.
+func ParseMultihash(s string) (Multihash, error) {
+	data, err := multibase.Decode(s)
+	if err != nil {
+		return Multihash{}, err
+	}
+
+	code, n := binary.Uvarint(data)
+	if n <= 0 {
+		return Multihash{}, errors.New("multihash: invalid code")
+	}
+	data = data[n:]
+	length, n := binary.Uvarint(data)
+	if n <= 0 {
+		return Multihash{}, errors.New("multihash: invalid length")
+	}
+	data = data[n:]
+	if uint64(len(data)) != length {
+		return Multihash{}, fmt.Errorf("multihash: length %d does not match a %d byte digest", length, len(data))
+	}
+	return Multihash{Code: code, Digest: data}, nil
+}
+
+// Bytes returns the binary multihash: the varint code, the varint digest length
+// and the digest.
+func (m Multihash) Bytes() []byte {
+	b := binary.AppendUvarint(nil, m.Code)
+	b = binary.AppendUvarint(b, uint64(len(m.Digest)))
+	return append(b, m.Digest...)
+}
+
+// MatchesJSON reports whether m is the digest of the canonical form of raw JSON
+// bytes. Truncated digests are not supported.
+func (m Multihash) MatchesJSON(raw []byte) (bool, error) {
+	alg, ok := multihashAlgs[m.Code]
+	if !ok {
+		return false, fmt.Errorf("unsupported multihash code 0x%x", m.Code)
+	}
+	canonicalBytes, err := CanonicalizeJSON(raw)
+	if err != nil {
+		return false, err
+	}
+	digest, err := digestWith(canonicalBytes, alg)
+	if err != nil {
+		return false, err
+	}
+	if len(m.Digest) != len(digest) {
+		return false, fmt.Errorf("multihash: %d byte digest, %s digests are %d bytes", len(m.Digest), alg, len(digest))
+	}
+	return hmac.Equal(m.Digest, digest), nil
+}
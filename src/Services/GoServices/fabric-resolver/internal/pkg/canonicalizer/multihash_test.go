@@ -0,0 +1,107 @@
+package canonicalizer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"fabric-resolver/internal/pkg/multibase"
+)
+
+// The SHA-256 multihash of "multihash" from the multihash specification.
+func TestMultihash_SpecVector(t *testing.T) {
+	const encoded = "zQmYtUc4iTCbbfVSDNKvtQqrfyezPPnFvE33wFmutw9PBBk"
+	digest, _ := hex.DecodeString("9cbc07c3f991725836a3aa2a581ca2029198aa420b9d99bc0e131d9f3e2cbe47")
+
+	m, err := ParseMultihash(encoded)
+	if err != nil {
+		t.Fatalf("ParseMultihash failed: %v", err)
+	}
+	if m.Code != MultihashSHA256 || !bytes.Equal(m.Digest, digest) {
+		t.Errorf("got code 0x%x digest %x", m.Code, m.Digest)
+	}
+	if got := multibase.Encode(m.Bytes()); got != encoded {
+		t.Errorf("expected %s to round-trip, got %s", encoded, got)
+	}
+}
+
+// Multihashes of the canonical form {"a":1,"b":2}, computed independently with
+// Python's hashlib and the varint and base encodings of the multiformats specifications.
+func TestCanonicalizeAndMultihashJSON_Vectors(t *testing.T) {
+	raw := []byte(`{"b": 2, "a": 1}`)
+	tests := []struct {
+		code              uint64
+		base58, base64url string
+	}{
+		{MultihashSHA256, "zQmSrmEc5VfnNKpex35VKc7t2RYKyFxonuPMj4iiN7QjAaz", "uEiBDJYz_eD_nA22KQwM_gwrfxg7ANzgkc1SKx0K4iCkndw"},
+		{MultihashSHA512, "z8VwRQueGykeiL8iiFxKupWUgKyTpnjKqBcyK4ag6VzHWUekE8JBqqJsFuVskwhB44Wjf14D2j9DwaNeh7gToeThW5o", "uE0C12nc_lFYx7ZlD9mqyhkFDnYiV41D7H7niE3e8Y81Ua7aKXbgIxX-EbdsZXe8yOzFf6JFyE6o0-Zbt6_qPllOq"},
+		{MultihashSHA3_256, "zW1iyKBgpd8ZsiFvh3h8yHx4uw7tpVf7cMoGmhmqLHesHP1", "uFiCNfgma36bDbZSFcUb47rkWrTy_1ssk9ua-Tsw202ZDHA"},
+		{MultihashBLAKE2b256, "z2DrjgbFJD5csh61cC1XiDhPZZ7oS4cYZc4WPtm9trwBy2EDKy2", "uoOQCIKQIeqCIBmr-Eb1zABwljQWiMVkLmx6gJCR3DS0gPJWJ"},
+	}
+
+	for _, tt := range tests {
+		got, err := CanonicalizeAndMultihashJSON(raw, tt.code)
+		if err != nil {
+			t.Fatalf("0x%x: %v", tt.code, err)
+		}
+		if got != tt.base58 {
+			t.Errorf("0x%x: got %s, want %s", tt.code, got, tt.base58)
+		}
+		got, _ = CanonicalizeAndMultihashJSONAs(raw, tt.code, multibase.Base64URL)
+		if got != tt.base64url {
+			t.Errorf("0x%x: got %s, want %s", tt.code, got, tt.base64url)
+		}
+
+		// Round trip: both encodings parse back to the digest of the document
+		for _, encoded := range []string{tt.base58, tt.base64url} {
+			m, err := ParseMultihash(encoded)
+			if err != nil {
+				t.Fatalf("ParseMultihash(%s): %v", encoded, err)
+			}
+			if ok, err := m.MatchesJSON(raw); !ok || err != nil {
+				t.Errorf("%s: expected a match, got %v %v", encoded, ok, err)
+			}
+			if ok, _ := m.MatchesJSON([]byte(`{"a":1,"b":3}`)); ok {
+				t.Errorf("%s: expected another document not to match", encoded)
+			}
+		}
+	}
+
+	if _, err := CanonicalizeAndMultihashJSON(raw, 0x11); err == nil {
+		t.Error("expected an unsupported code (sha1) to be rejected")
+	}
+}
+
+func TestParseMultihash_Errors(t *testing.T) {
+	sha256Digest := make([]byte, 32)
+	truncated := Multihash{Code: MultihashSHA256, Digest: sha256Digest[:20]}.Bytes()
+	badLength := append(Multihash{Code: MultihashSHA256, Digest: sha256Digest}.Bytes(), 0)
+
+	tests := []struct {
+		name, encoded, want string
+	}{
+		{"not multibase", "QmYtUc4iTCbbfVSDNKvtQqrfyezPPnFvE33wFmutw9PBBk", "unsupported prefix"},
+		{"empty", "z", "invalid"},
+		{"length mismatch", multibase.Encode(badLength), "does not match"},
+		{"unterminated varint", multibase.Encode([]byte{0x80}), "invalid code"},
+	}
+	for _, tt := range tests {
+		_, err := ParseMultihash(tt.encoded)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	// Truncated digests parse, but are not matched
+	m, err := ParseMultihash(multibase.Encode(truncated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.MatchesJSON([]byte(`{}`)); err == nil {
+		t.Error("expected a truncated digest to be rejected")
+	}
+	if _, err := (Multihash{Code: 0x11, Digest: sha256Digest[:20]}).MatchesJSON([]byte(`{}`)); err == nil {
+		t.Error("expected an unsupported code to be rejected")
+	}
+}
@@ -0,0 +1,95 @@
+package canonicalizer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const (
+	composed   = "Jos\u00e9"  // e with acute as one code point
+	decomposed = "Jose\u0301" // e and a combining acute accent
+)
+
+func TestUnicodeNFC_ComposedAndDecomposedHashAlike(t *testing.T) {
+	a := []byte(`{"name":"` + composed + `","tags":["` + composed + `"],"` + composed + `":1}`)
+	b := []byte(`{"name":"` + decomposed + `","tags":["Jose\u0301"],"` + decomposed + `":1}`)
+	nfc := Options{}.WithUnicodeNFC()
+
+	hashA, err := CanonicalizeAndHashJSONWithOptions(a, nfc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashB, err := CanonicalizeAndHashJSONWithOptions(b, nfc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashA != hashB {
+		t.Errorf("expected NFC and NFD input to hash alike with UnicodeNFC, got %s and %s", hashA, hashB)
+	}
+	if got, _ := CanonicalizeAndHashReader(strings.NewReader(string(b)), nfc); got != hashA {
+		t.Errorf("expected the stream hash to match, got %s", got)
+	}
+
+	// Off by default, so existing hashes do not change
+	plainA, _ := CanonicalizeAndHashJSON(a)
+	plainB, _ := CanonicalizeAndHashJSON(b)
+	if plainA == plainB {
+		t.Error("expected NFC and NFD input to hash differently by default")
+	}
+	if plainA != hashA {
+		t.Errorf("expected input already in NFC to hash as before, got %s and %s", plainA, hashA)
+	}
+
+	jcsA, _ := CanonicalizeJCSWithOptions(a, nfc)
+	jcsB, _ := CanonicalizeJCSWithOptions(b, nfc)
+	if string(jcsA) != string(jcsB) {
+		t.Errorf("expected JCS to normalize too, got %s and %s", jcsA, jcsB)
+	}
+}
+
+// Members are sorted after normalization: the decomposed name sorts after "Josf"
+// as written, before it once composed.
+func TestUnicodeNFC_SortsNormalizedNames(t *testing.T) {
+	raw := []byte(`{"Josf":1,"` + decomposed + `":2}`)
+	got, err := CanonicalizeJSONWithOptions(raw, Options{UnicodeNFC: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Josf":1,"` + composed + `":2}`; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	plain, _ := CanonicalizeJSON(raw)
+	if want := `{"` + decomposed + `":2,"Josf":1}`; string(plain) != want {
+		t.Errorf("got %s, want %s", plain, want)
+	}
+}
+
+func TestUnicodeNFC_NamesNormalizingAlikeAreDuplicates(t *testing.T) {
+	raw := []byte(`{"a":{"` + composed + `":1,"` + decomposed + `":2}}`)
+	nfc := Options{UnicodeNFC: true}
+
+	if _, err := CanonicalizeJSON(raw); err != nil {
+		t.Errorf("expected distinct names without UnicodeNFC, got %v", err)
+	}
+	_, err := CanonicalizeJSONWithOptions(raw, nfc)
+	if !errors.Is(err, ErrDuplicateKey) || !strings.HasSuffix(err.Error(), "/a/"+composed) {
+		t.Errorf("expected ErrDuplicateKey at /a/%s, got %v", composed, err)
+	}
+
+	// Neither comes last, so allowing duplicates does not help
+	nfc.AllowDuplicateKeys = true
+	if _, err := CanonicalizeJSONWithOptions(raw, nfc); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("expected ErrDuplicateKey, got %v", err)
+	}
+	if _, err := CanonicalizeAndHashReader(strings.NewReader(string(raw)), nfc); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("expected ErrDuplicateKey from the stream, got %v", err)
+	}
+
+	// Identical names are still allowed, the last winning
+	got, err := CanonicalizeJSONWithOptions([]byte(`{"`+decomposed+`":1,"`+decomposed+`":2}`), nfc)
+	if err != nil || string(got) != `{"`+composed+`":2}` {
+		t.Errorf("got %s %v", got, err)
+	}
+}
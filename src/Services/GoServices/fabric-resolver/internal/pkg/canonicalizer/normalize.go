@@ -0,0 +1,57 @@
+package canonicalizer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalize applies the NumberPolicy and UnicodeNFC options to a decoded value, in
+// place where it can. Names that only normalize alike are ErrDuplicateKey.
+func normalize(v interface{}, opts Options) (interface{}, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return opts.NumberPolicy.apply(v)
+	case string:
+		if opts.UnicodeNFC {
+			return norm.NFC.String(v), nil
+		}
+		return v, nil
+	case []interface{}:
+		for i, elem := range v {
+			normalized, err := normalize(elem, opts)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = normalized
+		}
+		return v, nil
+	case map[string]interface{}:
+		if !opts.UnicodeNFC {
+			for key, member := range v {
+				normalized, err := normalize(member, opts)
+				if err != nil {
+					return nil, err
+				}
+				v[key] = normalized
+			}
+			return v, nil
+		}
+
+		out := make(map[string]interface{}, len(v))
+		for key, member := range v {
+			normalizedKey := norm.NFC.String(key)
+			if _, dup := out[normalizedKey]; dup {
+				return nil, fmt.Errorf("%w %q after NFC normalization", ErrDuplicateKey, normalizedKey)
+			}
+			normalized, err := normalize(member, opts)
+			if err != nil {
+				return nil, err
+			}
+			out[normalizedKey] = normalized
+		}
+		return out, nil
+	}
+	return v, nil
+}
@@ -0,0 +1,106 @@
+package canonicalizer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NumberPolicy says how numbers are written in the canonical form.
+type NumberPolicy int
+
+const (
+	// PreserveLexical keeps numbers as written in raw JSON, so 1, 1.0 and 1e0 differ.
+	// Go values are first encoded by encoding/json, which writes float64(1.0) as 1.
+	PreserveLexical NumberPolicy = iota
+
+	// ES6Numeric reads numbers as IEEE 754 doubles and writes them as ECMAScript
+	// does, as RFC 8785 requires: 1, 1.0 and 1e0 are all 1. Numbers beyond the range
+	// of a double are rejected, and integers beyond 2^53 may be rounded.
+	ES6Numeric
+
+	// IntegerOnly accepts integers only, in any form, and writes them as plain
+	// decimal digits: 1, 1.0 and 1e0 are all 1, while 1.5 is ErrNonInteger.
+	// Integers are not rounded, but may not exceed maxIntegerDigits digits.
+	IntegerOnly
+)
+
+// maxIntegerDigits bounds the digits of an integer under IntegerOnly, so that
+// 1e1000000000 cannot make a gigabyte of zeros.
+const maxIntegerDigits = 1000
+
+// ErrNonInteger is returned, wrapped with the number, for numbers with a fractional
+// part under IntegerOnly.
+var ErrNonInteger = errors.New("number is not an integer")
+
+func (p NumberPolicy) String() string {
+	switch p {
+	case PreserveLexical:
+		return "PreserveLexical"
+	case ES6Numeric:
+		return "ES6Numeric"
+	case IntegerOnly:
+		return "IntegerOnly"
+	}
+	return "NumberPolicy(" + strconv.Itoa(int(p)) + ")"
+}
+
+// apply returns n written per p.
+func (p NumberPolicy) apply(n json.Number) (json.Number, error) {
+	switch p {
+	case PreserveLexical:
+		return n, nil
+	case ES6Numeric:
+		f, err := strconv.ParseFloat(string(n), 64)
+		if err != nil {
+			return "", fmt.Errorf("number %s is not representable as a double", n)
+		}
+		s, err := es6Number(f)
+		return json.Number(s), err
+	case IntegerOnly:
+		return integerDigits(n)
+	}
+	return "", fmt.Errorf("unknown number policy %v", p)
+}
+
+// integerDigits returns the valid JSON number n as plain decimal digits, or
+// ErrNonInteger if it has a fractional part.
+func integerDigits(n json.Number) (json.Number, error) {
+	s := string(n)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		// Exponents beyond 32 bits are clamped, which still tells the result
+		e, _ := strconv.ParseInt(strings.TrimPrefix(s[i+1:], "+"), 10, 32)
+		mantissa, exponent = s[:i], int(e)
+	}
+	digits := mantissa
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		digits = mantissa[:i] + mantissa[i+1:]
+		exponent -= len(mantissa) - i - 1
+	}
+
+	digits = strings.TrimLeft(digits, "0")
+	for len(digits) > 0 && digits[len(digits)-1] == '0' && exponent < 0 {
+		digits = digits[:len(digits)-1]
+		exponent++
+	}
+	switch {
+	case digits == "":
+		return "0", nil // -0 included
+	case exponent < 0:
+		return "", fmt.Errorf("%w: %s", ErrNonInteger, n)
+	case len(digits)+exponent > maxIntegerDigits:
+		return "", fmt.Errorf("%w: %s has more than %d digits", ErrTooLarge, n, maxIntegerDigits)
+	}
+
+	digits += strings.Repeat("0", exponent)
+	if negative {
+		digits = "-" + digits
+	}
+	return json.Number(digits), nil
+}
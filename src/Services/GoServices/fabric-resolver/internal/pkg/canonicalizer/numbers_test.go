@@ -0,0 +1,132 @@
+package canonicalizer
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNumberPolicy_OneVariants(t *testing.T) {
+	tests := []struct {
+		policy NumberPolicy
+		want   []string // canonical forms of 1, 1.0 and 1e0
+	}{
+		{PreserveLexical, []string{"1", "1.0", "1e0"}},
+		{ES6Numeric, []string{"1", "1", "1"}},
+		{IntegerOnly, []string{"1", "1", "1"}},
+	}
+
+	for _, tt := range tests {
+		opts := Options{NumberPolicy: tt.policy}
+		for i, input := range []string{"1", "1.0", "1e0"} {
+			raw := []byte(`{"n":` + input + `}`)
+			want := `{"n":` + tt.want[i] + `}`
+
+			got, err := CanonicalizeJSONWithOptions(raw, opts)
+			if err != nil || string(got) != want {
+				t.Errorf("%v %s: got %s %v, want %s", tt.policy, input, got, err, want)
+			}
+
+			// The stream applies the policy alike
+			streamHash, err := CanonicalizeAndHashReader(strings.NewReader(string(raw)), opts)
+			if err != nil || streamHash != hash([]byte(want)) {
+				t.Errorf("%v %s: stream hash %s %v", tt.policy, input, streamHash, err)
+			}
+
+			// And so do Go values, whose json.Number values keep their form
+			got, err = CanonicalizeWithOptions(map[string]interface{}{"n": json.Number(input)}, opts)
+			if err != nil || string(got) != want {
+				t.Errorf("%v Go %s: got %s %v, want %s", tt.policy, input, got, err, want)
+			}
+		}
+
+		// encoding/json writes both Go ones as 1 under every policy
+		h1, _ := CanonicalizeAndHashWithOptions(map[string]interface{}{"n": 1}, opts)
+		h2, _ := CanonicalizeAndHashWithOptions(map[string]interface{}{"n": 1.0}, opts)
+		if h1 != h2 || h1 != hash([]byte(`{"n":1}`)) {
+			t.Errorf("%v: expected Go 1 and 1.0 to hash as {\"n\":1}, got %s and %s", tt.policy, h1, h2)
+		}
+	}
+}
+
+func TestNumberPolicy_ES6Numeric(t *testing.T) {
+	opts := Options{NumberPolicy: ES6Numeric}
+	got, err := CanonicalizeJSONWithOptions([]byte(`[-0, 1E30, 4.50, 2e-3, 0.000000000000000000000000001, 333333333.33333329]`), opts)
+	if want := `[0,1e+30,4.5,0.002,1e-27,333333333.3333333]`; err != nil || string(got) != want {
+		t.Errorf("got %s %v, want %s", got, err, want)
+	}
+	if _, err := CanonicalizeJSONWithOptions([]byte(`1e400`), opts); err == nil {
+		t.Error("expected a number beyond the double range to be rejected")
+	}
+
+	// The default policy hashes as before, the ES6 policy as JCS does
+	raw := []byte(`{"b":1.50,"a":[1e2]}`)
+	es6, _ := CanonicalizeAndHashJSONWithOptions(raw, opts)
+	jcs, _ := CanonicalizeAndHashJCS(raw)
+	plain, _ := CanonicalizeAndHashJSON(raw)
+	if es6 != jcs || es6 == plain {
+		t.Errorf("expected the ES6 hash to be the JCS hash and not the default one, got %s, %s and %s", es6, jcs, plain)
+	}
+}
+
+func TestNumberPolicy_IntegerOnly(t *testing.T) {
+	opts := Options{NumberPolicy: IntegerOnly}
+	tests := map[string]string{
+		"-0":                         "0",
+		"0.0e5":                      "0",
+		"-12":                        "-12",
+		"1.5e1":                      "15",
+		"1200e-2":                    "12",
+		"12.000":                     "12",
+		"1E+3":                       "1000",
+		"9007199254740993":           "9007199254740993", // not rounded to a double
+		"123456789012345678901234.0": "123456789012345678901234",
+		"0e-2147483649":              "0",
+	}
+	for input, want := range tests {
+		got, err := CanonicalizeJSONWithOptions([]byte(input), opts)
+		if err != nil || string(got) != want {
+			t.Errorf("%s: got %s %v, want %s", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"1.5", "-0.1", "1e-1", "15e-1", "1e-2147483649"} {
+		if _, err := CanonicalizeJSONWithOptions([]byte(`{"a":[`+input+`]}`), opts); !errors.Is(err, ErrNonInteger) {
+			t.Errorf("%s: expected ErrNonInteger, got %v", input, err)
+		}
+	}
+	for _, input := range []string{"1e1000", "1e2147483648"} {
+		if _, err := CanonicalizeJSONWithOptions([]byte(input), opts); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%s: expected ErrTooLarge, got %v", input, err)
+		}
+	}
+
+	// Go values honor the policy too
+	if _, err := CanonicalizeAndHashWithOptions(map[string]float64{"n": 1.5}, opts); !errors.Is(err, ErrNonInteger) {
+		t.Errorf("expected ErrNonInteger for a Go 1.5, got %v", err)
+	}
+	key := []byte("this-is-a-32-byte-secret-key-123")
+	if _, err := CanonicalizeAndCommitWithOptions(struct{ N float64 }{2.5}, key, opts); !errors.Is(err, ErrNonInteger) {
+		t.Errorf("expected ErrNonInteger, got %v", err)
+	}
+	if _, err := CanonicalizeJCSWithOptions([]byte(`[1.5]`), opts); !errors.Is(err, ErrNonInteger) {
+		t.Errorf("expected JCS to reject 1.5 under IntegerOnly, got %v", err)
+	}
+	if _, err := CanonicalizeAndHashReader(strings.NewReader(`[1.5]`), opts); !errors.Is(err, ErrNonInteger) {
+		t.Errorf("expected the stream to reject 1.5, got %v", err)
+	}
+}
+
+func TestCanonicalizeWithOptions_MatchesCanonicalize(t *testing.T) {
+	v := map[string]interface{}{"b": []interface{}{1, 2.5, "<&>"}, "a": json.Number("1.0"), "c": nil}
+	want, _ := Canonicalize(v)
+	got, err := CanonicalizeWithOptions(v, Options{})
+	if err != nil || string(got) != string(want) {
+		t.Errorf("got %s %v, want %s", got, err, want)
+	}
+	key := []byte("this-is-a-32-byte-secret-key-123")
+	if _, err := CanonicalizeAndCommitWithOptions(v, key[:31], Options{}); !errors.Is(err, ErrKeyTooShort) {
+		t.Error("expected a short key to be rejected")
+	}
+}
@@ -0,0 +1,153 @@
+package canonicalizer
+
+import (
+	"bytes"
+)
+
+// Default limits of Options.
+const (
+	DefaultMaxDepth = 128
+	DefaultMaxBytes = 4 << 20
+)
+
+// Options tunes the canonicalization of raw JSON. The zero value is the default
+// policy of CanonicalizeJSON; functions without a WithOptions variant apply it.
+type Options struct {
+	// AllowDuplicateKeys accepts objects naming a member more than once and keeps
+	// the last, as encoding/json does. Only for legacy callers whose stored hashes
+	// were computed that way: two different documents then share a canonical form.
+	AllowDuplicateKeys bool
+
+	// MaxDepth bounds the nesting of arrays and objects (ErrTooDeep). Zero is
+	// DefaultMaxDepth and a negative value no limit.
+	MaxDepth int
+
+	// MaxBytes bounds the length of the input (ErrTooLarge). Zero is DefaultMaxBytes
+	// and a negative value no limit.
+	MaxBytes int
+
+	// MaxKeys, if positive, bounds the members of each object (ErrTooLarge).
+	MaxKeys int
+
+	// UnicodeNFC normalizes member names and string values to Unicode Normalization
+	// Form C before encoding, so that "José" hashes alike whether é is written as one
+	// code point or as e and a combining accent. Members are sorted by their normalized
+	// names; names that only normalize alike are duplicates, rejected even with
+	// AllowDuplicateKeys since neither can be said to come last. Off by default, as it
+	// changes the hashes of documents that are not already in NFC.
+	UnicodeNFC bool
+
+	// NumberPolicy says how numbers are written; PreserveLexical by default. JCS
+	// functions always write numbers as ES6Numeric does, though IntegerOnly still
+	// rejects non-integers there.
+	NumberPolicy NumberPolicy
+
+	// AllStructFields encodes the Go values of the WithOptions functions field by field
+	// rather than with encoding/json, so that their hashes do not depend on omitempty:
+	// every exported field is emitted, zero or not. json tags still name and skip
+	// fields, and embedded structs are promoted as encoding/json does. time.Time values
+	// are RFC 3339 in UTC, so one instant hashes alike in every zone.
+	AllStructFields bool
+
+	// VerifyStableEncoding checks that the encoding of a Go value decodes into a new
+	// value of its type that encodes alike, failing with ErrUnstableEncoding if not:
+	// interface fields holding integers past 2^53, say, or marshalers that do not
+	// round-trip.
+	VerifyStableEncoding bool
+}
+
+// WithUnicodeNFC returns o with UnicodeNFC set.
+func (o Options) WithUnicodeNFC() Options {
+	o.UnicodeNFC = true
+	return o
+}
+
+func (o Options) maxDepth() int {
+	if o.MaxDepth == 0 {
+		return DefaultMaxDepth
+	}
+	return o.MaxDepth
+}
+
+func (o Options) maxBytes() int {
+	if o.MaxBytes == 0 {
+		return DefaultMaxBytes
+	}
+	return o.MaxBytes
+}
+
+// CanonicalizeJSONWithOptions is CanonicalizeJSON under opts.
+func CanonicalizeJSONWithOptions(raw []byte, opts Options) ([]byte, error) {
+	var canonicalBytes []byte
+	if err := canonicalizeJSONPooled(raw, opts, func(b []byte) {
+		canonicalBytes = bytes.Clone(b)
+	}); err != nil {
+		return nil, err
+	}
+	return canonicalBytes, nil
+}
+
+// CanonicalizeWithOptions is Canonicalize under opts: the JSON encoding of v is
+// canonicalized as raw JSON would be, so that its numbers follow opts.NumberPolicy.
+// Go numbers are encoded first, float64(1.0) as 1; json.Number values as they are.
+func CanonicalizeWithOptions(v interface{}, opts Options) ([]byte, error) {
+	encoded, err := encodeValue(v, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.VerifyStableEncoding {
+		if err := verifyStable(v, encoded, opts); err != nil {
+			return nil, err
+		}
+	}
+	return CanonicalizeJSONWithOptions(encoded, opts)
+}
+
+// CanonicalizeAndHashWithOptions is CanonicalizeAndHash under opts.
+func CanonicalizeAndHashWithOptions(v interface{}, opts Options) (string, error) {
+	canonicalBytes, err := CanonicalizeWithOptions(v, opts)
+	if err != nil {
+		return "", err
+	}
+	return hash(canonicalBytes), nil
+}
+
+// CanonicalizeAndCommitWithOptions is CanonicalizeAndCommit under opts.
+func CanonicalizeAndCommitWithOptions(v interface{}, key []byte, opts Options) (string, error) {
+	if len(key) < MinHMACKeyLen {
+		return "", ErrKeyTooShort
+	}
+
+	canonicalBytes, err := CanonicalizeWithOptions(v, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return commit(canonicalBytes, key), nil
+}
+
+// CanonicalizeAndHashJSONWithOptions is CanonicalizeAndHashJSON under opts.
+func CanonicalizeAndHashJSONWithOptions(raw []byte, opts Options) (string, error) {
+	var digest string
+	if err := canonicalizeJSONPooled(raw, opts, func(canonicalBytes []byte) {
+		digest = hash(canonicalBytes)
+	}); err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// CanonicalizeAndCommitJSONWithOptions is CanonicalizeAndCommitJSON under opts.
+func CanonicalizeAndCommitJSONWithOptions(raw []byte, key []byte, opts Options) (string, error) {
+	if len(key) < MinHMACKeyLen {
+		return "", ErrKeyTooShort
+	}
+
+	var commitment string
+	if err := canonicalizeJSONPooled(raw, opts, func(canonicalBytes []byte) {
+		commitment = commit(canonicalBytes, key)
+	}); err != nil {
+		return "", err
+	}
+	return commitment, nil
+}
@@ -0,0 +1,192 @@
+package canonicalizer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPointer is returned, wrapped with the pointer, for redaction pointers
+// that are not RFC 6901 JSON Pointers or that may not be redacted.
+var ErrInvalidPointer = errors.New("invalid JSON pointer")
+
+// ErrPointerNotFound is returned by CanonicalizeAndHashJSONRedactedStrict, wrapped
+// with the pointer, for pointers that do not resolve in the document.
+var ErrPointerNotFound = errors.New("JSON pointer not found")
+
+// CanonicalizeAndHashJSONRedacted removes the members and array elements that pointers
+// reference from raw JSON bytes, and returns the SHA-256 of the canonical form of
+// what is left, as CanonicalizeAndHashJSON does, with the pointers that were removed.
+//
+// Redaction is deterministic:
+//   - Pointers are RFC 6901 JSON Pointers, "/credentialSubject/ssn", with ~1 for "/"
+//     and ~0 for "~" in member names. The root, "", cannot be redacted.
+//   - All pointers are resolved against the original document before anything is
+//     removed, so their order does not matter and array indices are not shifted by
+//     earlier removals. A pointer within a redacted member, and a repeated pointer,
+//     are still reported as removed.
+//   - A pointer may end at an array element, which is removed whole, but may not
+//     continue into one: "/items/0" is valid, "/items/0/ssn" is ErrInvalidPointer.
+//   - Pointers that do not resolve are skipped and not reported.
+func CanonicalizeAndHashJSONRedacted(raw []byte, pointers []string) (string, []string, error) {
+	return redactAndHash(raw, pointers, false)
+}
+
+// CanonicalizeAndHashJSONRedactedStrict is CanonicalizeAndHashJSONRedacted failing
+// with ErrPointerNotFound for pointers that do not resolve.
+func CanonicalizeAndHashJSONRedactedStrict(raw []byte, pointers []string) (string, []string, error) {
+	return redactAndHash(raw, pointers, true)
+}
+
+// redactedValue marks members and elements to remove until they are compacted away.
+type redactedValue struct{}
+
+var redacted = &redactedValue{}
+
+func redactAndHash(raw []byte, pointers []string, strict bool) (string, []string, error) {
+	v, err := decodeJSON(raw, Options{})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var targets []redactTarget
+	var removed []string
+	seen := map[string]bool{}
+	for _, pointer := range pointers {
+		t, err := resolveRedaction(v, pointer)
+		if err != nil {
+			return "", nil, err
+		}
+		if t == nil {
+			if strict {
+				return "", nil, fmt.Errorf("%w: %s", ErrPointerNotFound, pointer)
+			}
+			continue
+		}
+		targets = append(targets, *t)
+		if !seen[pointer] {
+			seen[pointer] = true
+			removed = append(removed, pointer)
+		}
+	}
+
+	for _, t := range targets {
+		if t.object != nil {
+			t.object[t.key] = redacted
+		} else {
+			t.array[t.index] = redacted
+		}
+	}
+
+	canonicalBytes, err := Canonicalize(compactRedacted(v))
+	if err != nil {
+		return "", nil, err
+	}
+	return hash(canonicalBytes), removed, nil
+}
+
+// redactTarget is a member or an array element to redact.
+type redactTarget struct {
+	object map[string]interface{}
+	key    string
+	array  []interface{}
+	index  int
+}
+
+// resolveRedaction returns what pointer references in v, or nil if it does not resolve.
+func resolveRedaction(v interface{}, pointer string) (*redactTarget, error) {
+	segments, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	parent := v
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			member, ok := p[segment]
+			if !ok {
+				return nil, nil
+			}
+			if last {
+				return &redactTarget{object: p, key: segment}, nil
+			}
+			parent = member
+		case []interface{}:
+			if !last {
+				return nil, fmt.Errorf("%w: %s continues into an array element", ErrInvalidPointer, pointer)
+			}
+			index, ok := arrayIndex(segment)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s does not end at an array index", ErrInvalidPointer, pointer)
+			}
+			if index >= len(p) {
+				return nil, nil
+			}
+			return &redactTarget{array: p, index: index}, nil
+		default:
+			return nil, nil // into a string, number, boolean or null
+		}
+	}
+	return nil, nil
+}
+
+// parsePointer returns the unescaped reference tokens of a non-empty JSON Pointer.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, fmt.Errorf("%w: the whole document cannot be redacted", ErrInvalidPointer)
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: %q does not start with /", ErrInvalidPointer, pointer)
+	}
+
+	segments := strings.Split(pointer[1:], "/")
+	for i, segment := range segments {
+		for j := 0; j < len(segment); j++ {
+			if segment[j] == '~' && (j+1 == len(segment) || (segment[j+1] != '0' && segment[j+1] != '1')) {
+				return nil, fmt.Errorf("%w: %q has a ~ not followed by 0 or 1", ErrInvalidPointer, pointer)
+			}
+		}
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+	}
+	return segments, nil
+}
+
+// arrayIndex parses an RFC 6901 array index: decimal digits without leading zeros.
+func arrayIndex(segment string) (int, bool) {
+	if segment == "" || (len(segment) > 1 && segment[0] == '0') {
+		return 0, false
+	}
+	for _, c := range segment {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	index, err := strconv.Atoi(segment)
+	return index, err == nil
+}
+
+// compactRedacted drops the members and elements marked redacted from v.
+func compactRedacted(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, member := range v {
+			if member == redacted {
+				delete(v, key)
+				continue
+			}
+			v[key] = compactRedacted(member)
+		}
+	case []interface{}:
+		kept := v[:0]
+		for _, elem := range v {
+			if elem != redacted {
+				kept = append(kept, compactRedacted(elem))
+			}
+		}
+		return kept
+	}
+	return v
+}
@@ -0,0 +1,137 @@
+package canonicalizer
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+const credential = `{
+	"id": "urn:uuid:1",
+	"credentialSubject": {
+		"name": "Alice",
+		"ssn": "123-45-6789",
+		"address": {"street": "Main St 1", "city": "Aarhus"},
+		"phones": ["+45 1111", "+45 2222", "+45 3333"]
+	},
+	"a/b": {"~x": 1, "y": 2},
+	"proof": {"jws": "..."}
+}`
+
+func TestCanonicalizeAndHashJSONRedacted_NestedRemovals(t *testing.T) {
+	got, removed, err := CanonicalizeAndHashJSONRedacted([]byte(credential), []string{
+		"/credentialSubject/ssn",
+		"/credentialSubject/address/street",
+		"/credentialSubject/phones/1",
+		"/credentialSubject/phones/0", // indices refer to the original array
+		"/proof",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := CanonicalizeAndHashJSON([]byte(`{
+		"id": "urn:uuid:1",
+		"credentialSubject": {"name": "Alice", "address": {"city": "Aarhus"}, "phones": ["+45 3333"]},
+		"a/b": {"~x": 1, "y": 2}
+	}`))
+	if got != want {
+		t.Errorf("got %s, want the hash of the redacted document %s", got, want)
+	}
+	if len(removed) != 5 {
+		t.Errorf("expected all five pointers to be reported, got %v", removed)
+	}
+
+	// Order does not matter
+	again, _, _ := CanonicalizeAndHashJSONRedacted([]byte(credential), []string{
+		"/proof", "/credentialSubject/phones/0", "/credentialSubject/address/street",
+		"/credentialSubject/phones/1", "/credentialSubject/ssn",
+	})
+	if again != got {
+		t.Errorf("expected the same hash in any order, got %s", again)
+	}
+
+	// No pointers is the plain hash
+	plain, removed, _ := CanonicalizeAndHashJSONRedacted([]byte(credential), nil)
+	if want, _ := CanonicalizeAndHashJSON([]byte(credential)); plain != want || len(removed) != 0 {
+		t.Errorf("expected the plain hash, got %s %v", plain, removed)
+	}
+}
+
+func TestCanonicalizeAndHashJSONRedacted_Escaping(t *testing.T) {
+	got, removed, err := CanonicalizeAndHashJSONRedactedStrict([]byte(credential), []string{"/a~1b/~0x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(removed, []string{"/a~1b/~0x"}) {
+		t.Errorf("unexpected removed pointers %v", removed)
+	}
+	want, _, _ := CanonicalizeAndHashJSONRedacted([]byte(credential), []string{"/a~1b/~0x", "/a~1b/~0x"})
+	if got != want {
+		t.Errorf("expected a repeated pointer to be removed once, got %s and %s", got, want)
+	}
+
+	// ~01 is "~1", not "/"
+	doc := []byte(`{"~1":1,"/":2}`)
+	got, _, _ = CanonicalizeAndHashJSONRedactedStrict(doc, []string{"/~01"})
+	if want, _ := CanonicalizeAndHashJSON([]byte(`{"/":2}`)); got != want {
+		t.Errorf("expected /~01 to remove \"~1\", got %s", got)
+	}
+	got, _, _ = CanonicalizeAndHashJSONRedactedStrict(doc, []string{"/~1"})
+	if want, _ := CanonicalizeAndHashJSON([]byte(`{"~1":1}`)); got != want {
+		t.Errorf("expected /~1 to remove \"/\", got %s", got)
+	}
+}
+
+func TestCanonicalizeAndHashJSONRedacted_StrictAndLenient(t *testing.T) {
+	missing := []string{
+		"/credentialSubject/dob",
+		"/credentialSubject/phones/3",
+		"/credentialSubject/name/first", // into a string
+		"/nothing/here",
+	}
+	for _, pointer := range missing {
+		pointers := []string{"/credentialSubject/ssn", pointer}
+
+		got, removed, err := CanonicalizeAndHashJSONRedacted([]byte(credential), pointers)
+		if err != nil {
+			t.Errorf("%s: expected lenient redaction to skip it, got %v", pointer, err)
+			continue
+		}
+		if !slices.Equal(removed, []string{"/credentialSubject/ssn"}) {
+			t.Errorf("%s: expected only the resolved pointer to be reported, got %v", pointer, removed)
+		}
+		if want, _, _ := CanonicalizeAndHashJSONRedacted([]byte(credential), pointers[:1]); got != want {
+			t.Errorf("%s: expected the hash without the missing pointer", pointer)
+		}
+
+		if _, _, err := CanonicalizeAndHashJSONRedactedStrict([]byte(credential), pointers); !errors.Is(err, ErrPointerNotFound) {
+			t.Errorf("%s: expected ErrPointerNotFound, got %v", pointer, err)
+		}
+	}
+}
+
+func TestCanonicalizeAndHashJSONRedacted_InvalidPointers(t *testing.T) {
+	for _, pointer := range []string{
+		"",
+		"credentialSubject/ssn",
+		"/credentialSubject/phones/0/number", // into an array element
+		"/credentialSubject/phones/01",
+		"/credentialSubject/phones/-",
+		"/credentialSubject/phones/x",
+		"/a~2b",
+		"/a~",
+	} {
+		for _, redact := range []func([]byte, []string) (string, []string, error){
+			CanonicalizeAndHashJSONRedacted, CanonicalizeAndHashJSONRedactedStrict,
+		} {
+			if _, _, err := redact([]byte(credential), []string{pointer}); !errors.Is(err, ErrInvalidPointer) {
+				t.Errorf("%q: expected ErrInvalidPointer, got %v", pointer, err)
+			}
+		}
+	}
+
+	if _, _, err := CanonicalizeAndHashJSONRedacted([]byte(`{"a":1,"a":2}`), []string{"/a"}); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("expected the document to be checked as usual, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package canonicalizer
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// SaltLen is the length in bytes of the salts of salted commitments.
+const SaltLen = 16
+
+// CanonicalizeAndSaltedCommitJSON canonicalizes raw JSON bytes per CanonicalizeJSON
+// and returns the hex HMAC-SHA256 under key of a fresh random salt followed by the
+// canonical bytes, with the hex salt. Unlike CanonicalizeAndCommitJSON, the same
+// document commits to a new value on every call, so a holder of the key cannot test
+// guesses of a low-entropy document against a commitment without its salt.
+func CanonicalizeAndSaltedCommitJSON(raw []byte, key []byte) (commitment string, salt string, err error) {
+	if len(key) < MinHMACKeyLen {
+		return "", "", ErrKeyTooShort
+	}
+
+	canonicalBytes, err := CanonicalizeJSON(raw)
+	if err != nil {
+		return "", "", err
+	}
+
+	saltBytes := make([]byte, SaltLen)
+	if _, err := rand.Read(saltBytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	return hex.EncodeToString(saltedCommit(canonicalBytes, key, saltBytes)), hex.EncodeToString(saltBytes), nil
+}
+
+// VerifySaltedCommitJSON reports whether commitment is the salted commitment to raw
+// JSON bytes under key and the hex salt, as CanonicalizeAndSaltedCommitJSON returns
+// them. It compares like VerifyCommitJSON; a salt that is not SaltLen hex bytes is an error.
+func VerifySaltedCommitJSON(raw []byte, key []byte, salt string, commitment string) (bool, error) {
+	if len(key) < MinHMACKeyLen {
+		return false, ErrKeyTooShort
+	}
+	if len(salt) != 2*SaltLen {
+		return false, fmt.Errorf("expected a %d byte hex salt, got %d characters", SaltLen, len(salt))
+	}
+	saltBytes, err := hex.DecodeString(salt)
+	if err != nil {
+		return false, fmt.Errorf("invalid hex salt: %w", err)
+	}
+	expected, err := decodeDigestHex(commitment)
+	if err != nil {
+		return false, err
+	}
+
+	canonicalBytes, err := CanonicalizeJSON(raw)
+	if err != nil {
+		return false, err
+	}
+
+	return hmac.Equal(saltedCommit(canonicalBytes, key, saltBytes), expected), nil
+}
+
+func saltedCommit(data []byte, key []byte, salt []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(salt)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,83 @@
+package canonicalizer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalizeAndSaltedCommitJSON(t *testing.T) {
+	key := []byte("this-is-a-32-byte-secret-key-123")
+	raw := []byte(`{"b":2,"a":1}`)
+
+	c1, salt1, err := CanonicalizeAndSaltedCommitJSON(raw, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, salt2, err := CanonicalizeAndSaltedCommitJSON(raw, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c1 == c2 || salt1 == salt2 {
+		t.Errorf("expected fresh salts and commitments, got %s/%s and %s/%s", c1, salt1, c2, salt2)
+	}
+	if len(salt1) != 2*SaltLen || len(c1) != 2*sha256.Size {
+		t.Errorf("unexpected lengths: salt %s, commitment %s", salt1, c1)
+	}
+	if plain, _ := CanonicalizeAndCommitJSON(raw, key); c1 == plain {
+		t.Error("expected the salted commitment to differ from the unsalted one")
+	}
+
+	// HMAC(key, salt || canonical bytes)
+	saltBytes, _ := hex.DecodeString(salt1)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(append(saltBytes, `{"a":1,"b":2}`...))
+	if want := hex.EncodeToString(mac.Sum(nil)); c1 != want {
+		t.Errorf("got %s, want %s", c1, want)
+	}
+
+	if _, _, err := CanonicalizeAndSaltedCommitJSON(raw, key[:31]); !errors.Is(err, ErrKeyTooShort) {
+		t.Error("expected a short key to be rejected")
+	}
+}
+
+func TestVerifySaltedCommitJSON(t *testing.T) {
+	key := []byte("this-is-a-32-byte-secret-key-123")
+	raw := []byte(`{"b":2,"a":1}`)
+	commitment, salt, err := CanonicalizeAndSaltedCommitJSON(raw, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := VerifySaltedCommitJSON([]byte(`{"a":1,"b":2}`), key, strings.ToUpper(salt), commitment); !ok || err != nil {
+		t.Errorf("expected the commitment to verify with its salt, got %v %v", ok, err)
+	}
+	_, otherSalt, _ := CanonicalizeAndSaltedCommitJSON(raw, key)
+	if ok, err := VerifySaltedCommitJSON(raw, key, otherSalt, commitment); ok || err != nil {
+		t.Errorf("expected another salt not to verify, got %v %v", ok, err)
+	}
+	if ok, err := VerifySaltedCommitJSON([]byte(`{"a":1,"b":3}`), key, salt, commitment); ok || err != nil {
+		t.Errorf("expected another document not to verify, got %v %v", ok, err)
+	}
+
+	tests := []struct {
+		name, salt, commitment, want string
+	}{
+		{"short salt", salt[:30], commitment, "16 byte hex salt"},
+		{"long salt", salt + "00", commitment, "16 byte hex salt"},
+		{"non-hex salt", "zz" + salt[2:], commitment, "invalid hex salt"},
+		{"short commitment", salt, commitment[:62], "expected 64 hex characters"},
+	}
+	for _, tt := range tests {
+		ok, err := VerifySaltedCommitJSON(raw, key, tt.salt, tt.commitment)
+		if ok || err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v %v", tt.name, tt.want, ok, err)
+		}
+	}
+	if _, err := VerifySaltedCommitJSON(raw, key[:31], salt, commitment); !errors.Is(err, ErrKeyTooShort) {
+		t.Error("expected a short key to be rejected")
+	}
+}
@@ -0,0 +1,253 @@
+package canonicalizer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrDuplicateKey is returned, wrapped with the JSON Pointer of the member, for
+// objects that name a member more than once. encoding/json would keep the last, so
+// {"a":1,"a":2} and {"a":2} would canonicalize alike while a reader keeping the
+// first sees different documents.
+var ErrDuplicateKey = errors.New("duplicate object key")
+
+// ErrTooDeep is returned for input nesting arrays and objects beyond Options.MaxDepth.
+var ErrTooDeep = errors.New("JSON nesting too deep")
+
+// ErrTooLarge is returned for input longer than Options.MaxBytes, or with an object
+// of more than Options.MaxKeys members.
+var ErrTooLarge = errors.New("JSON input too large")
+
+// ErrTrailingData is returned for input that continues after its JSON value, or
+// after its CBOR item.
+var ErrTrailingData = errors.New("input contains extra data")
+
+// ErrInvalidJSON is returned for input that is not JSON, wrapping the error of
+// encoding/json: a *json.SyntaxError, or io.ErrUnexpectedEOF for truncated input.
+var ErrInvalidJSON = errors.New("invalid JSON")
+
+// linearKeySearch is the number of members up to which decodeJSON looks for a
+// duplicate among them one by one rather than in a map.
+const linearKeySearch = 16
+
+// maxPooledEntries bounds the scratch space of a treeDecoder returned to its pool.
+const maxPooledEntries = 1 << 14
+
+// decodeFrame is an object or array open during decodeJSON.
+type decodeFrame struct {
+	object    bool
+	start     int                 // index of its first member or element in treeDecoder.entries
+	keys      map[string]struct{} // member names seen: with UnicodeNFC, or past linearKeySearch
+	members   int
+	key       string // member being read, for objects
+	name      string // key, normalized with UnicodeNFC
+	expectKey bool
+}
+
+// decodeEntry is a member, or an element if key is unset, of an open object or array.
+type decodeEntry struct {
+	key   string
+	value interface{}
+}
+
+// treeDecoder builds the value of a token stream.
+type treeDecoder struct {
+	opts  Options
+	stack []decodeFrame
+	// entries holds the completed members and elements of the open objects and
+	// arrays, so that each map and slice is made once at its final size
+	entries []decodeEntry
+}
+
+// treeDecoderPool holds treeDecoders, whose stack and entries are kept across calls.
+// Unlike encoding/json decoders they hold no input and are safe to reuse.
+var treeDecoderPool = sync.Pool{
+	New: func() any { return &treeDecoder{entries: make([]decodeEntry, 0, 32)} },
+}
+
+// release returns d to treeDecoderPool, unless a large document grew it.
+func (d *treeDecoder) release() {
+	if cap(d.entries) > maxPooledEntries || cap(d.stack) > maxPooledEntries {
+		return
+	}
+	clear(d.entries)
+	clear(d.stack)
+	d.stack, d.entries = d.stack[:0], d.entries[:0]
+	treeDecoderPool.Put(d)
+}
+
+// decodeJSON decodes the single JSON value of raw, numbers as json.Number, under the
+// limits of opts. It builds the value from the tokens of raw rather than recursing
+// through it, so that a document too deep or too wide is rejected before encoding
+// recurses through it, and a single pass reads it: encoding/json decoders cannot be
+// reset, so one is not pooled across calls; the treeDecoder is.
+func decodeJSON(raw []byte, opts Options) (interface{}, error) {
+	if maxBytes := opts.maxBytes(); maxBytes > 0 && len(raw) > maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes exceeds the limit of %d", ErrTooLarge, len(raw), maxBytes)
+	}
+	maxDepth := opts.maxDepth()
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	d := treeDecoderPool.Get().(*treeDecoder)
+	d.opts = opts
+	defer d.release()
+
+	var v interface{}
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF && len(d.stack) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, invalidJSON(err)
+		}
+
+		if len(d.stack) > 0 {
+			if top := &d.stack[len(d.stack)-1]; top.object && top.expectKey {
+				if delim, ok := tok.(json.Delim); !ok || delim != '}' {
+					if err := d.member(tok.(string)); err != nil {
+						return nil, err
+					}
+					continue
+				}
+			}
+		}
+
+		value := tok
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			if maxDepth > 0 && len(d.stack) >= maxDepth {
+				return nil, fmt.Errorf("%w: more than %d levels", ErrTooDeep, maxDepth)
+			}
+			d.stack = append(d.stack, decodeFrame{object: tok == json.Delim('{'), start: len(d.entries), expectKey: true})
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			value = d.close()
+		}
+
+		// A value is complete
+		if len(d.stack) == 0 {
+			v = value
+			break
+		}
+		top := &d.stack[len(d.stack)-1]
+		d.entries = append(d.entries, decodeEntry{key: top.key, value: value})
+		top.expectKey = true
+	}
+
+	// Check for trailing garbage
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("%w after JSON value", ErrTrailingData)
+	}
+
+	if opts.UnicodeNFC || opts.NumberPolicy != PreserveLexical {
+		return normalize(v, opts)
+	}
+	return v, nil
+}
+
+// invalidJSON wraps the errors of json.Decoder.Token about the input in ErrInvalidJSON.
+// Failures to read are returned as they are.
+func invalidJSON(err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+	return err
+}
+
+// member reads the name of the next member of the innermost object.
+func (d *treeDecoder) member(key string) error {
+	f := &d.stack[len(d.stack)-1]
+	members := d.entries[f.start:]
+	name, dup := key, false
+	switch {
+	case d.opts.UnicodeNFC && !d.opts.AllowDuplicateKeys:
+		name = norm.NFC.String(key)
+		if f.keys == nil {
+			f.keys = map[string]struct{}{}
+		}
+		_, dup = f.keys[name]
+		f.keys[name] = struct{}{}
+	case d.opts.AllowDuplicateKeys:
+	case len(members) < linearKeySearch:
+		for _, m := range members {
+			dup = dup || m.key == key
+		}
+	default:
+		if f.keys == nil {
+			f.keys = make(map[string]struct{}, 2*len(members))
+			for _, m := range members {
+				f.keys[m.key] = struct{}{}
+			}
+		}
+		_, dup = f.keys[key]
+		f.keys[key] = struct{}{}
+	}
+	if dup {
+		return fmt.Errorf("%w at %s/%s", ErrDuplicateKey, d.pointer(), escapePointer(name))
+	}
+
+	f.members++
+	if d.opts.MaxKeys > 0 && f.members > d.opts.MaxKeys {
+		return fmt.Errorf("%w: more than %d members in the object at %q", ErrTooLarge, d.opts.MaxKeys, d.pointer())
+	}
+	f.key, f.name = key, name
+	f.expectKey = false
+	return nil
+}
+
+// close pops the innermost object or array and returns its value.
+func (d *treeDecoder) close() interface{} {
+	f := d.stack[len(d.stack)-1]
+	d.stack = d.stack[:len(d.stack)-1]
+	entries := d.entries[f.start:]
+	d.entries = d.entries[:f.start]
+
+	if f.object {
+		// The last of duplicate members wins, as encoding/json decodes them
+		object := make(map[string]interface{}, len(entries))
+		for _, e := range entries {
+			object[e.key] = e.value
+		}
+		clear(entries)
+		return object
+	}
+	array := make([]interface{}, len(entries))
+	for i, e := range entries {
+		array[i] = e.value
+	}
+	clear(entries)
+	return array
+}
+
+// pointer returns the JSON Pointer (RFC 6901) of the innermost open object or array.
+func (d *treeDecoder) pointer() string {
+	var b strings.Builder
+	for i, f := range d.stack[:len(d.stack)-1] {
+		b.WriteByte('/')
+		if f.object {
+			b.WriteString(escapePointer(f.name))
+		} else {
+			// The element being read follows those completed
+			b.WriteString(strconv.Itoa(d.stack[i+1].start - f.start))
+		}
+	}
+	return b.String()
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escapePointer(s string) string {
+	return pointerEscaper.Replace(s)
+}
@@ -0,0 +1,114 @@
+package canonicalizer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalizeJSON_RejectsDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		name, input, path string
+	}{
+		{"top level", `{"a":1,"b":2,"a":2}`, "/a"},
+		{"nested object", `{"a":{"x":1,"y":{"z":true,"z":false}}}`, "/a/y/z"},
+		{"object in array", `{"items":[{"id":1},{"id":2,"id":3}]}`, "/items/1/id"},
+		{"root array", `[[], {"k":null}, {"k":[1,{"k":1}], "k":0}]`, "/2/k"},
+		{"escaped names", `{"a/b":{"~":1,"~":2}}`, "/a~1b/~0"},
+		{"after siblings", `{"a":[1,[2,3],{"b":{}}],"c":{"d":1},"c":0}`, "/c"},
+	}
+
+	for _, tt := range tests {
+		_, err := CanonicalizeJSON([]byte(tt.input))
+		if !errors.Is(err, ErrDuplicateKey) {
+			t.Errorf("%s: expected ErrDuplicateKey, got %v", tt.name, err)
+			continue
+		}
+		if !strings.HasSuffix(err.Error(), " at "+tt.path) {
+			t.Errorf("%s: expected the error to name %s, got %v", tt.name, tt.path, err)
+		}
+	}
+}
+
+func TestDuplicateKeys_AllEntryPoints(t *testing.T) {
+	raw := []byte(`{"a":1,"a":2}`)
+	key := []byte("this-is-a-32-byte-secret-key-123")
+
+	calls := map[string]func() error{
+		"CanonicalizeAndHashJSON": func() error { _, err := CanonicalizeAndHashJSON(raw); return err },
+		"CanonicalizeAndCommitJSON": func() error {
+			_, err := CanonicalizeAndCommitJSON(raw, key)
+			return err
+		},
+		"CanonicalizeAndHashJSONWith": func() error {
+			_, err := CanonicalizeAndHashJSONWith(raw, SHA512)
+			return err
+		},
+		"CanonicalizeJCS": func() error { _, err := CanonicalizeJCS(raw); return err },
+		"CanonicalizeAndMultihashJSON": func() error {
+			_, err := CanonicalizeAndMultihashJSON(raw, MultihashSHA256)
+			return err
+		},
+		"CanonicalizeAndSaltedCommitJSON": func() error {
+			_, _, err := CanonicalizeAndSaltedCommitJSON(raw, key)
+			return err
+		},
+		"CanonicalizeAndCommitJSONDerived": func() error {
+			_, err := CanonicalizeAndCommitJSONDerived(raw, key, "tenant-a")
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, ErrDuplicateKey) {
+			t.Errorf("%s: expected ErrDuplicateKey, got %v", name, err)
+		}
+	}
+}
+
+func TestOptions_AllowDuplicateKeys(t *testing.T) {
+	legacy := Options{AllowDuplicateKeys: true}
+
+	got, err := CanonicalizeJSONWithOptions([]byte(`{"a":1,"b":[{"c":1,"c":2}],"a":2}`), legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":2,"b":[{"c":2}]}`; string(got) != want {
+		t.Errorf("expected the last duplicate to win, got %s, want %s", got, want)
+	}
+
+	h1, _ := CanonicalizeAndHashJSONWithOptions([]byte(`{"a":1,"a":2}`), legacy)
+	h2, _ := CanonicalizeAndHashJSON([]byte(`{"a":2}`))
+	if h1 == "" || h1 != h2 {
+		t.Errorf("expected the legacy hash of the duplicate to be that of the last value, got %s and %s", h1, h2)
+	}
+
+	key := []byte("this-is-a-32-byte-secret-key-123")
+	c1, _ := CanonicalizeAndCommitJSONWithOptions([]byte(`{"a":1,"a":2}`), key, legacy)
+	c2, _ := CanonicalizeAndCommitJSON([]byte(`{"a":2}`), key)
+	if c1 == "" || c1 != c2 {
+		t.Errorf("expected legacy commitments to match, got %s and %s", c1, c2)
+	}
+	if _, err := CanonicalizeAndCommitJSONWithOptions([]byte(`{}`), key[:31], legacy); !errors.Is(err, ErrKeyTooShort) {
+		t.Error("expected a short key to be rejected")
+	}
+
+	// Other syntax errors and trailing data are still rejected
+	for input, want := range map[string]error{`{"a":1,"a":}`: ErrInvalidJSON, `{"a":1} {"a":1}`: ErrTrailingData} {
+		if _, err := CanonicalizeJSONWithOptions([]byte(input), legacy); !errors.Is(err, want) {
+			t.Errorf("expected %s to be rejected with %v, got %v", input, want, err)
+		}
+	}
+}
+
+// Members with the same name in different objects are not duplicates.
+func TestCanonicalizeJSON_SameKeyInSiblingObjects(t *testing.T) {
+	for _, input := range []string{
+		`{"a":{"id":1},"b":{"id":1},"id":2}`,
+		`[{"id":1},{"id":2}]`,
+		`{"a":[{"a":{"a":1}}]}`,
+	} {
+		if _, err := CanonicalizeJSON([]byte(input)); err != nil {
+			t.Errorf("%s: %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package canonicalizer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// streamFlushBytes is how much canonical output CanonicalizeAndHashReader holds
+// before writing it to the hash.
+const streamFlushBytes = 32 << 10
+
+// CanonicalizeAndHashReader returns the same SHA-256 hash as CanonicalizeAndHashJSON
+// under opts, reading the document from r as a token stream. Neither the document
+// nor its canonical form is held in memory: array elements are hashed as they are
+// read, and only the members of the object being read are buffered, to be sorted.
+// A large array of small objects therefore takes memory in proportion to one object.
+func CanonicalizeAndHashReader(r io.Reader, opts Options) (string, error) {
+	if maxBytes := opts.maxBytes(); maxBytes > 0 {
+		r = &limitedReader{r: r, limit: int64(maxBytes)}
+	}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	hasher := sha256.New()
+	e := &streamEncoder{dec: dec, opts: opts, maxDepth: opts.maxDepth(), out: hasher}
+	tok, err := dec.Token()
+	if err != nil {
+		return "", invalidJSON(err)
+	}
+	buf, err := e.value(make([]byte, 0, 512), tok, 0, true)
+	if err != nil {
+		return "", invalidJSON(err)
+	}
+	e.out.Write(buf)
+
+	// Check for trailing garbage
+	if _, err := dec.Token(); err != io.EOF {
+		// Failures to read, unlike syntax errors, are not about the data
+		var syntaxErr *json.SyntaxError
+		if err != nil && !errors.As(err, &syntaxErr) {
+			return "", err
+		}
+		return "", fmt.Errorf("%w after JSON value", ErrTrailingData)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// streamEncoder writes the canonical form of the tokens of dec, as Canonicalize
+// writes that of the decoded value.
+type streamEncoder struct {
+	dec      *json.Decoder
+	opts     Options
+	maxDepth int
+	out      io.Writer
+	path     []pathSegment // position of the value being read
+}
+
+// pathSegment is an object member name or, if index is not negative, an array index.
+type pathSegment struct {
+	key   string
+	index int
+}
+
+// streamMember is a member of the object being read, its value in canonical form.
+type streamMember struct {
+	key    string // normalized if UnicodeNFC is set
+	rawKey string
+	value  []byte
+}
+
+// value appends the canonical form of the value starting with tok to dst. depth is
+// the number of enclosing arrays and objects. If stream is set, dst may be flushed
+// to the hash between array elements; values within objects are never flushed.
+func (e *streamEncoder) value(dst []byte, tok json.Token, depth int, stream bool) ([]byte, error) {
+	switch v := tok.(type) {
+	case json.Delim:
+		if e.maxDepth > 0 && depth >= e.maxDepth {
+			return nil, fmt.Errorf("%w: more than %d levels", ErrTooDeep, e.maxDepth)
+		}
+		if v == '[' {
+			return e.array(dst, depth, stream)
+		}
+		return e.object(dst, depth)
+	case string:
+		if e.opts.UnicodeNFC {
+			v = norm.NFC.String(v)
+		}
+		return appendString(dst, v), nil
+	case json.Number:
+		n, err := e.opts.NumberPolicy.apply(v)
+		if err != nil {
+			return nil, err
+		}
+		return append(dst, n...), nil
+	case bool:
+		return strconv.AppendBool(dst, v), nil
+	case nil:
+		return append(dst, "null"...), nil
+	}
+	return nil, fmt.Errorf("unexpected JSON token %v", tok)
+}
+
+func (e *streamEncoder) array(dst []byte, depth int, stream bool) ([]byte, error) {
+	dst = append(dst, '[')
+	for i := 0; e.dec.More(); i++ {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		tok, err := e.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		e.path = append(e.path, pathSegment{index: i})
+		dst, err = e.value(dst, tok, depth+1, stream)
+		if err != nil {
+			return nil, err
+		}
+		e.path = e.path[:len(e.path)-1]
+
+		if stream && len(dst) >= streamFlushBytes {
+			e.out.Write(dst)
+			dst = dst[:0]
+		}
+	}
+	if _, err := e.dec.Token(); err != nil {
+		return nil, err
+	}
+	return append(dst, ']'), nil
+}
+
+func (e *streamEncoder) object(dst []byte, depth int) ([]byte, error) {
+	var members []streamMember
+	seen := map[string]int{}
+	for e.dec.More() {
+		tok, err := e.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		rawKey, _ := tok.(string)
+		key := rawKey
+		if e.opts.UnicodeNFC {
+			key = norm.NFC.String(rawKey)
+		}
+		i, dup := seen[key]
+		if dup && (!e.opts.AllowDuplicateKeys || members[i].rawKey != rawKey) {
+			return nil, fmt.Errorf("%w at %s", ErrDuplicateKey, e.pointer()+"/"+escapePointer(key))
+		}
+		if e.opts.MaxKeys > 0 && len(members)+1 > e.opts.MaxKeys {
+			return nil, fmt.Errorf("%w: more than %d members in the object at %q", ErrTooLarge, e.opts.MaxKeys, e.pointer())
+		}
+
+		if tok, err = e.dec.Token(); err != nil {
+			return nil, err
+		}
+		e.path = append(e.path, pathSegment{key: key, index: -1})
+		value, err := e.value(nil, tok, depth+1, false)
+		if err != nil {
+			return nil, err
+		}
+		e.path = e.path[:len(e.path)-1]
+
+		// The last of duplicate members wins, as when decoding into a map
+		if dup {
+			members[i].value = value
+			continue
+		}
+		seen[key] = len(members)
+		members = append(members, streamMember{key: key, rawKey: rawKey, value: value})
+	}
+	if _, err := e.dec.Token(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(members, func(a, b streamMember) int { return strings.Compare(a.key, b.key) })
+	dst = append(dst, '{')
+	for i, m := range members {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendString(dst, m.key)
+		dst = append(dst, ':')
+		dst = append(dst, m.value...)
+	}
+	return append(dst, '}'), nil
+}
+
+// pointer returns the JSON Pointer of the value being read, "" for the root.
+func (e *streamEncoder) pointer() string {
+	var b strings.Builder
+	for _, seg := range e.path {
+		b.WriteByte('/')
+		if seg.index < 0 {
+			b.WriteString(escapePointer(seg.key))
+		} else {
+			b.WriteString(strconv.Itoa(seg.index))
+		}
+	}
+	return b.String()
+}
+
+// appendString appends s as encoding/json writes strings with HTML escaping off.
+func appendString(dst []byte, s string) []byte {
+	const hexDigits = "0123456789abcdef"
+
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				dst = append(dst, '\\', b)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, `\ufffd`...)
+		case r == '\u2028' || r == '\u2029':
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
+
+// limitedReader fails with ErrTooLarge once more than limit bytes are read.
+type limitedReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	// Read one byte past the limit to tell input of exactly limit bytes from longer input
+	if remaining := l.limit + 1 - l.read; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		return 0, fmt.Errorf("%w: input exceeds the limit of %d bytes", ErrTooLarge, l.limit)
+	}
+	return n, err
+}
@@ -0,0 +1,279 @@
+package canonicalizer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// randomJSON writes a random JSON value to b, in the many ways one value can be
+// written: varied whitespace, member order, number forms and string escapes.
+func randomJSON(rng *rand.Rand, b *bytes.Buffer, depth int) {
+	space := func() {
+		b.WriteString([]string{"", "", " ", "\n\t", "  "}[rng.IntN(5)])
+	}
+
+	kind := rng.IntN(9)
+	if depth >= 6 && kind >= 7 {
+		kind = rng.IntN(7)
+	}
+	switch kind {
+	case 0:
+		b.WriteString([]string{"null", "true", "false"}[rng.IntN(3)])
+	case 1, 2:
+		b.WriteString(randomNumber(rng))
+	case 3, 4, 5, 6:
+		b.WriteString(randomString(rng))
+	case 7:
+		b.WriteByte('[')
+		n := rng.IntN(5)
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			space()
+			randomJSON(rng, b, depth+1)
+			space()
+		}
+		b.WriteByte(']')
+	case 8:
+		b.WriteByte('{')
+		n := rng.IntN(5)
+		used := map[string]bool{}
+		for i := 0; i < n; i++ {
+			key := randomString(rng)
+			if used[key] {
+				continue
+			}
+			used[key] = true
+			if len(used) > 1 {
+				b.WriteByte(',')
+			}
+			space()
+			b.WriteString(key)
+			space()
+			b.WriteByte(':')
+			space()
+			randomJSON(rng, b, depth+1)
+		}
+		b.WriteByte('}')
+	}
+}
+
+func randomNumber(rng *rand.Rand) string {
+	return []string{
+		"0", "-0", "1", "1.0", "1e0", "1E+2", "-12.500", "0.000001", "1e-7",
+		"123456789012345678901234567890", strconv.Itoa(rng.IntN(1 << 20)),
+		strconv.FormatFloat(rng.NormFloat64()*1e6, 'g', -1, 64),
+	}[rng.IntN(12)]
+}
+
+// randomString returns a JSON string literal, escaped in one of the ways JSON allows.
+func randomString(rng *rand.Rand) string {
+	pieces := []string{
+		"a", "B", "key", "é", "José", "Jose\u0301", "😂", "<", ">", "&", "'", "/", "~",
+		`\"`, `\\`, `\/`, `\n`, `\r`, `\t`, `\b`, `\f`, `\u0000`, `\u001f`, `\u007f`,
+		`\u2028`, `\u2029`, `\u00e9`, `\ud83d\ude02`, `\ud800`, `\u003c`, "\u2028", "\u0080", "\xff",
+	}
+	var s strings.Builder
+	s.WriteByte('"')
+	for n := rng.IntN(6); n > 0; n-- {
+		s.WriteString(pieces[rng.IntN(len(pieces))])
+	}
+	s.WriteByte('"')
+	return s.String()
+}
+
+// Property: the streaming hash equals the hash of the []byte API for any document.
+func TestCanonicalizeAndHashReader_MatchesCanonicalizeAndHashJSON(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 2000; i++ {
+		var b bytes.Buffer
+		randomJSON(rng, &b, 0)
+		raw := b.Bytes()
+
+		want, err := CanonicalizeAndHashJSON(raw)
+		if err != nil {
+			t.Fatalf("%s: %v", raw, err)
+		}
+		got, err := CanonicalizeAndHashReader(bytes.NewReader(raw), Options{})
+		if err != nil {
+			t.Fatalf("%s: %v", raw, err)
+		}
+		if got != want {
+			canonical, _ := CanonicalizeJSON(raw)
+			t.Fatalf("%s: got %s, want %s (canonical %s)", raw, got, want, canonical)
+		}
+
+		// Reads of one byte at a time give the same result
+		if got, _ := CanonicalizeAndHashReader(iotest.OneByteReader(bytes.NewReader(raw)), Options{}); got != want {
+			t.Fatalf("%s: one byte reads gave %s", raw, got)
+		}
+	}
+}
+
+// Arrays longer than the flush threshold are hashed in parts, to the same result.
+func TestCanonicalizeAndHashReader_LargeArray(t *testing.T) {
+	var b bytes.Buffer
+	b.WriteString(`{"batch":[`)
+	for i := 0; i < 5000; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"name":"item-%d","id":%d,"tags":["a","b"]}`, i, i)
+	}
+	b.WriteString(`],"items":[`)
+	for i := 0; i < 5000; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `[%d,{"v":"%d"}]`, i, i)
+	}
+	b.WriteString(`]}`)
+	raw := b.Bytes()
+
+	want, err := CanonicalizeAndHashJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := CanonicalizeAndHashReader(bytes.NewReader(raw), Options{})
+	if err != nil || got != want {
+		t.Errorf("got %s %v, want %s", got, err, want)
+	}
+
+	top := raw[len(`{"batch":`):bytes.Index(raw, []byte(`,"items"`))]
+	want, _ = CanonicalizeAndHashJSON(top)
+	if got, _ := CanonicalizeAndHashReader(bytes.NewReader(top), Options{}); got != want {
+		t.Errorf("top-level array: got %s, want %s", got, want)
+	}
+}
+
+// Invalid input and the limits of Options fail as they do for CanonicalizeJSON.
+func TestCanonicalizeAndHashReader_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  error
+	}{
+		{"duplicate key", `[{"a":{"b":1,"b":2}}]`, Options{}, ErrDuplicateKey},
+		{"too deep", strings.Repeat("[", 10000), Options{}, ErrTooDeep},
+		{"too large", `{"a":"` + strings.Repeat("x", 100) + `"}`, Options{MaxBytes: 64}, ErrTooLarge},
+		{"too many members", `{"a":1,"b":2,"c":3}`, Options{MaxKeys: 2}, ErrTooLarge},
+		{"trailing data", `{"a":1} {"a":1}`, Options{}, ErrTrailingData},
+		{"trailing garbage", `[1] x`, Options{}, ErrTrailingData},
+		{"truncated", `{"a":[1,2`, Options{}, ErrInvalidJSON},
+		{"missing colon", `{"a" 1}`, Options{}, ErrInvalidJSON},
+		{"empty", ``, Options{}, ErrInvalidJSON},
+	}
+	for _, tt := range tests {
+		_, streamErr := CanonicalizeAndHashReader(strings.NewReader(tt.input), tt.opts)
+		_, err := CanonicalizeAndHashJSONWithOptions([]byte(tt.input), tt.opts)
+		if !errors.Is(streamErr, tt.want) || !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v and %v", tt.name, tt.want, streamErr, err)
+		}
+	}
+
+	// The pointer of a duplicate names the same member
+	_, streamErr := CanonicalizeAndHashReader(strings.NewReader(`[{"a":{"":1,"":2}}]`), Options{})
+	_, err := CanonicalizeJSON([]byte(`[{"a":{"":1,"":2}}]`))
+	if streamErr == nil || err == nil || streamErr.Error() != err.Error() {
+		t.Errorf("expected the same error, got %v and %v", streamErr, err)
+	}
+
+	// Read errors are returned as they are, also after the value
+	errRead := errors.New("connection reset")
+	for _, r := range []io.Reader{
+		io.MultiReader(strings.NewReader(`{"a":[1,`), iotest.ErrReader(errRead)),
+		io.MultiReader(strings.NewReader(`{"a":[1]}  `), iotest.ErrReader(errRead)),
+	} {
+		if _, err := CanonicalizeAndHashReader(r, Options{}); !errors.Is(err, errRead) {
+			t.Errorf("expected the read error, got %v", err)
+		}
+	}
+
+	// Input of exactly MaxBytes is accepted, and duplicates may be allowed
+	raw := `{"a":1,"a":2}`
+	got, err := CanonicalizeAndHashReader(strings.NewReader(raw), Options{MaxBytes: len(raw), AllowDuplicateKeys: true})
+	if want, _ := CanonicalizeAndHashJSON([]byte(`{"a":2}`)); err != nil || got != want {
+		t.Errorf("got %s %v, want %s", got, err, want)
+	}
+}
+
+// arrayReader generates [{"id":0,"name":"item-0"},...] of n objects without holding
+// it, and records the peak heap in use while it is read.
+type arrayReader struct {
+	n, next  int
+	pending  []byte
+	done     bool
+	peakHeap uint64
+}
+
+func (r *arrayReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		switch {
+		case r.done:
+			return 0, io.EOF
+		case r.next == r.n:
+			r.pending, r.done = []byte("]"), true
+		default:
+			if r.next%10000 == 0 {
+				var m runtime.MemStats
+				runtime.ReadMemStats(&m)
+				r.peakHeap = max(r.peakHeap, m.HeapInuse)
+			}
+			prefix := ","
+			if r.next == 0 {
+				prefix = "["
+			}
+			r.pending = fmt.Appendf(r.pending[:0], `%s{"name":"item-%d","id":%d}`, prefix, r.next, r.next)
+			r.next++
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// BenchmarkCanonicalizeAndHashReader reports the peak heap in use while hashing
+// arrays of 10k to 1M small objects: it stays flat as the input grows a hundredfold.
+// The []byte API needs the document, its decoded tree and its canonical form at once.
+func BenchmarkCanonicalizeAndHashReader(b *testing.B) {
+	for _, n := range []int{10_000, 100_000, 1_000_000} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			var peak uint64
+			for i := 0; i < b.N; i++ {
+				runtime.GC()
+				r := &arrayReader{n: n}
+				if _, err := CanonicalizeAndHashReader(r, Options{MaxBytes: -1}); err != nil {
+					b.Fatal(err)
+				}
+				peak = max(peak, r.peakHeap)
+			}
+			b.ReportMetric(float64(peak), "peak-heap-B")
+		})
+	}
+}
+
+func BenchmarkCanonicalizeAndHashJSON_LargeArray(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			raw, _ := io.ReadAll(&arrayReader{n: n})
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := CanonicalizeAndHashJSONWithOptions(raw, Options{MaxBytes: -1}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
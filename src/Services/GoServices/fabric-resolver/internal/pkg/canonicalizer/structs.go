@@ -0,0 +1,358 @@
+package canonicalizer
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnstableEncoding is returned under VerifyStableEncoding for Go values whose
+// encoding does not decode back to a value that encodes alike.
+var ErrUnstableEncoding = errors.New("encoding is not stable")
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// encodeValue returns the JSON encoding of v, field by field if AllStructFields is set.
+func encodeValue(v interface{}, opts Options) ([]byte, error) {
+	if !opts.AllStructFields {
+		return Canonicalize(v)
+	}
+	e := &fieldEncoder{maxDepth: opts.maxDepth()}
+	tree, err := e.value(reflect.ValueOf(v), 0)
+	if err != nil {
+		return nil, err
+	}
+	return Canonicalize(tree)
+}
+
+// verifyStable decodes encoded, the encoding of v, into a new value of the type of v
+// and checks that it encodes to the same bytes.
+func verifyStable(v interface{}, encoded []byte, opts Options) error {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil
+	}
+	decoded := reflect.New(t)
+	if err := json.Unmarshal(encoded, decoded.Interface()); err != nil {
+		return fmt.Errorf("%w: %T does not decode from its encoding: %v", ErrUnstableEncoding, v, err)
+	}
+	again, err := encodeValue(decoded.Elem().Interface(), opts)
+	if err != nil {
+		return fmt.Errorf("%w: %T decoded from its encoding does not encode: %v", ErrUnstableEncoding, v, err)
+	}
+	if !bytes.Equal(encoded, again) {
+		return fmt.Errorf("%w: %T decoded from its encoding encodes differently", ErrUnstableEncoding, v)
+	}
+	return nil
+}
+
+// fieldEncoder converts Go values to the values decodeJSON returns, as encoding/json
+// would encode them but for omitempty, which is ignored, and times, which are in UTC.
+type fieldEncoder struct {
+	maxDepth int
+}
+
+func (e *fieldEncoder) value(v reflect.Value, depth int) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	// Bounds cycles through pointers, which encoding/json reports instead
+	if e.maxDepth > 0 && depth >= e.maxDepth {
+		return nil, fmt.Errorf("%w: more than %d levels", ErrTooDeep, e.maxDepth)
+	}
+
+	t := v.Type()
+	if t == timeType {
+		return v.Interface().(time.Time).UTC().Format(time.RFC3339Nano), nil
+	}
+	if (t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, nil
+	}
+	if t.Kind() != reflect.Pointer || t.Elem() != timeType {
+		if m, ok := marshalerOf(v, jsonMarshalerType); ok {
+			b, err := m.(json.Marshaler).MarshalJSON()
+			if err != nil {
+				return nil, fmt.Errorf("json: error calling MarshalJSON for type %s: %w", t, err)
+			}
+			return decodeJSON(b, Options{})
+		}
+		if m, ok := marshalerOf(v, textMarshalerType); ok {
+			b, err := m.(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, fmt.Errorf("json: error calling MarshalText for type %s: %w", t, err)
+			}
+			return string(b), nil
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return e.value(v.Elem(), depth+1)
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(v.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return json.Number(strconv.FormatUint(v.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		// Written as encoding/json writes floats
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		return json.Number(b), nil
+	case reflect.Struct:
+		return e.structFields(v, depth)
+	case reflect.Map:
+		return e.mapMembers(v, depth)
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 && !isMarshaler(t.Elem()) {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+		fallthrough
+	case reflect.Array:
+		elems := make([]interface{}, v.Len())
+		for i := range elems {
+			elem, err := e.value(v.Index(i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = elem
+		}
+		return elems, nil
+	}
+	return nil, &json.UnsupportedTypeError{Type: t}
+}
+
+func (e *fieldEncoder) structFields(v reflect.Value, depth int) (interface{}, error) {
+	members := map[string]interface{}{}
+	for _, f := range cachedStructFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok {
+			continue // within a nil embedded pointer
+		}
+		member, err := e.value(fv, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if f.quoted {
+			member, err = quoteScalar(member)
+			if err != nil {
+				return nil, err
+			}
+		}
+		members[f.name] = member
+	}
+	return members, nil
+}
+
+func (e *fieldEncoder) mapMembers(v reflect.Value, depth int) (interface{}, error) {
+	if v.IsNil() {
+		return nil, nil
+	}
+	members := make(map[string]interface{}, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		member, err := e.value(iter.Value(), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		members[key] = member
+	}
+	return members, nil
+}
+
+// mapKey returns the member name of a map key, as encoding/json writes it.
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if m, ok := marshalerOf(k, textMarshalerType); ok {
+		b, err := m.(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", &json.UnsupportedTypeError{Type: k.Type()}
+}
+
+// quoteScalar applies the ",string" option of a json tag to an encoded member.
+func quoteScalar(member interface{}) (interface{}, error) {
+	switch m := member.(type) {
+	case string:
+		b, err := json.Marshal(m)
+		return string(b), err
+	case bool:
+		return strconv.FormatBool(m), nil
+	case json.Number:
+		return string(m), nil
+	}
+	return member, nil
+}
+
+// marshalerOf returns v, or its address, as an implementation of iface.
+func marshalerOf(v reflect.Value, iface reflect.Type) (interface{}, bool) {
+	if v.Type().Implements(iface) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return nil, false
+		}
+		return v.Interface(), true
+	}
+	if v.Kind() != reflect.Pointer && v.CanAddr() && reflect.PointerTo(v.Type()).Implements(iface) {
+		return v.Addr().Interface(), true
+	}
+	return nil, false
+}
+
+func isMarshaler(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return t.Implements(jsonMarshalerType) || pt.Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || pt.Implements(textMarshalerType)
+}
+
+// fieldByIndex is v.FieldByIndex reporting, rather than panicking on, nil embedded pointers.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// structField is a field encoded by a fieldEncoder.
+type structField struct {
+	name   string
+	index  []int
+	tagged bool
+	quoted bool
+}
+
+var structFieldCache sync.Map // reflect.Type to []structField
+
+func cachedStructFields(t reflect.Type) []structField {
+	if fields, ok := structFieldCache.Load(t); ok {
+		return fields.([]structField)
+	}
+	fields, _ := structFieldCache.LoadOrStore(t, typeFields(t))
+	return fields.([]structField)
+}
+
+// typeFields returns the fields of struct type t that encoding/json encodes, with the
+// fields of embedded structs promoted: of fields of one name, the shallowest wins,
+// then the only tagged one; if none does, none is encoded.
+func typeFields(t reflect.Type) []structField {
+	type candidate struct {
+		structField
+		depth int
+	}
+	var candidates []candidate
+
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+	current := []embedded{{t: t}}
+	visited := map[reflect.Type]bool{}
+	for depth := 0; len(current) > 0; depth++ {
+		var next []embedded
+		for _, s := range current {
+			if visited[s.t] {
+				continue
+			}
+			visited[s.t] = true
+
+			for i := 0; i < s.t.NumField(); i++ {
+				sf := s.t.Field(i)
+				ft := sf.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous {
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, tagOpts, _ := strings.Cut(tag, ",")
+				index := append(slices.Clip(s.index), i)
+
+				if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+					next = append(next, embedded{t: ft, index: index})
+					continue
+				}
+				tagged := name != ""
+				if !tagged {
+					name = sf.Name
+				}
+				quoted := false
+				switch ft.Kind() {
+				case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+					reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+					reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+					quoted = slices.Contains(strings.Split(tagOpts, ","), "string")
+				}
+				candidates = append(candidates, candidate{structField{name, index, tagged, quoted}, depth})
+			}
+		}
+		current = next
+	}
+
+	byName := map[string][]candidate{}
+	for _, c := range candidates {
+		byName[c.name] = append(byName[c.name], c)
+	}
+	var fields []structField
+	for _, cs := range byName {
+		shallowest := slices.MinFunc(cs, func(a, b candidate) int { return a.depth - b.depth }).depth
+		var dominant []candidate
+		for _, c := range cs {
+			if c.depth == shallowest {
+				dominant = append(dominant, c)
+			}
+		}
+		if len(dominant) > 1 {
+			dominant = slices.DeleteFunc(dominant, func(c candidate) bool { return !c.tagged })
+		}
+		if len(dominant) == 1 {
+			fields = append(fields, dominant[0].structField)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,198 @@
+package canonicalizer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type profileV1 struct {
+	Name     string   `json:"name"`
+	Nickname string   `json:"nickname"`
+	Age      int      `json:"age"`
+	Tags     []string `json:"tags"`
+	Manager  *string  `json:"manager"`
+}
+
+// profileV2 is profileV1 after someone added omitempty.
+type profileV2 struct {
+	Name     string   `json:"name"`
+	Nickname string   `json:"nickname,omitempty"`
+	Age      int      `json:"age,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Manager  *string  `json:"manager,omitempty"`
+}
+
+func TestAllStructFields_IgnoresOmitEmpty(t *testing.T) {
+	v1 := profileV1{Name: "Ada"}
+	v2 := profileV2{Name: "Ada"}
+
+	// With encoding/json the hash drifts
+	h1, _ := CanonicalizeAndHashWithOptions(v1, Options{})
+	h2, _ := CanonicalizeAndHashWithOptions(v2, Options{})
+	if h1 == h2 {
+		t.Fatal("expected omitempty to change the hash with encoding/json")
+	}
+
+	opts := Options{AllStructFields: true}
+	got, err := CanonicalizeWithOptions(v2, opts)
+	want := `{"age":0,"manager":null,"name":"Ada","nickname":"","tags":null}`
+	if err != nil || string(got) != want {
+		t.Errorf("got %s %v, want %s", got, err, want)
+	}
+	h1, _ = CanonicalizeAndHashWithOptions(v1, opts)
+	h2, _ = CanonicalizeAndHashWithOptions(&v2, opts)
+	if h1 != h2 {
+		t.Errorf("expected omitempty not to change the hash, got %s and %s", h1, h2)
+	}
+
+	// Set pointers are followed, as encoding/json does
+	manager := "Grace"
+	v1.Manager, v2.Manager = &manager, &manager
+	got, _ = CanonicalizeWithOptions(v2, opts)
+	if want, _ := CanonicalizeWithOptions(v1, Options{}); string(got) != string(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+type audit struct {
+	CreatedBy string `json:"createdBy"`
+	Revision  int    `json:"revision,omitempty"`
+}
+
+type contact struct {
+	Email string `json:"email"`
+}
+
+type internal struct {
+	Note string `json:"note"`
+}
+
+type record struct {
+	audit               // promoted, though unexported
+	*contact            // promoted if set
+	Owner    contact    `json:"owner"` // tagged: nested
+	Revision string     `json:"revision"`
+	Hidden   string     `json:"-"`
+	Count    int64      `json:"count,string"`
+	secret   string     // unexported, never encoded
+	Inner    *internal  `json:"inner,omitempty"`
+	When     time.Time  `json:"when"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	Proof    []byte     `json:"proof"`
+}
+
+func TestAllStructFields_EmbeddedAndSpecialTypes(t *testing.T) {
+	when := time.Date(2024, 3, 1, 12, 30, 0, 500, time.FixedZone("CET", 3600))
+	r := record{
+		audit:    audit{CreatedBy: "ops", Revision: 7},
+		Owner:    contact{Email: "owner@example.com"},
+		Revision: "r2",
+		Hidden:   "x",
+		Count:    42,
+		secret:   "s",
+		When:     when,
+		Proof:    []byte{0xde, 0xad, 0xbe, 0xef},
+	}
+
+	opts := Options{AllStructFields: true}
+	got, err := CanonicalizeWithOptions(r, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The shallower revision wins, the nil *contact adds no email, times are in UTC
+	want := `{"count":"42","createdBy":"ops","expires":null,"inner":null,"owner":{"email":"owner@example.com"},` +
+		`"proof":"3q2+7w==","revision":"r2","when":"2024-03-01T11:30:00.0000005Z"}`
+	if string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	// Once set, the embedded pointer's fields are promoted
+	r.contact = &contact{Email: "me@example.com"}
+	got, _ = CanonicalizeWithOptions(r, opts)
+	if !strings.Contains(string(got), `"email":"me@example.com"`) {
+		t.Errorf("expected the promoted email, got %s", got)
+	}
+
+	// One instant hashes alike in any zone
+	h1, _ := CanonicalizeAndHashWithOptions(r, opts)
+	r.When = when.In(time.UTC)
+	h2, _ := CanonicalizeAndHashWithOptions(r, opts)
+	if h1 != h2 {
+		t.Errorf("expected the zone not to change the hash, got %s and %s", h1, h2)
+	}
+}
+
+// Without omitempty and with times in UTC, both encodings agree.
+func TestAllStructFields_MatchesEncodingJSON(t *testing.T) {
+	type item struct {
+		ID     int               `json:"id"`
+		Price  float64           `json:"price"`
+		Labels map[string]string `json:"labels"`
+		Sizes  [2]uint8          `json:"sizes"`
+		Raw    []byte            `json:"raw"`
+		At     time.Time         `json:"at"`
+		Any    interface{}       `json:"any"`
+		Plain  string
+	}
+	v := []item{
+		{ID: 1, Price: 1.5, Labels: map[string]string{"b": "<2>", "a": "1"}, Sizes: [2]uint8{3, 4}, Raw: []byte("hi"), At: time.Unix(1700000000, 0).UTC(), Any: []interface{}{1e21, "x"}, Plain: "p"},
+		{ID: 2, Price: 1e-7},
+	}
+	want, _ := CanonicalizeWithOptions(v, Options{})
+	got, err := CanonicalizeWithOptions(v, Options{AllStructFields: true})
+	if err != nil || string(got) != string(want) {
+		t.Errorf("got  %s %v\nwant %s", got, err, want)
+	}
+
+	if _, err := CanonicalizeWithOptions(struct{ C chan int }{}, Options{AllStructFields: true}); err == nil {
+		t.Error("expected a channel to be rejected")
+	}
+	type loop struct{ Next *loop }
+	l := &loop{}
+	l.Next = l
+	if _, err := CanonicalizeWithOptions(l, Options{AllStructFields: true}); !errors.Is(err, ErrTooDeep) {
+		t.Errorf("expected a cycle to be ErrTooDeep, got %v", err)
+	}
+}
+
+// lossyVersion marshals to text it cannot unmarshal the same way.
+type lossyVersion struct{ Major, Minor int }
+
+func (v lossyVersion) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"%d.%d"`, v.Major, v.Minor)), nil
+}
+
+func (v *lossyVersion) UnmarshalJSON(b []byte) error {
+	_, err := fmt.Sscanf(string(b), `"%d`, &v.Major) // drops the minor version
+	return err
+}
+
+func TestVerifyStableEncoding(t *testing.T) {
+	opts := Options{VerifyStableEncoding: true}
+
+	stable := profileV2{Name: "Ada", Tags: []string{"a"}}
+	if _, err := CanonicalizeAndHashWithOptions(stable, opts); err != nil {
+		t.Errorf("expected a stable encoding, got %v", err)
+	}
+	withFields := opts
+	withFields.AllStructFields = true
+	if _, err := CanonicalizeAndHashWithOptions(record{When: time.Now()}, withFields); err != nil {
+		t.Errorf("expected a stable encoding, got %v", err)
+	}
+
+	unstable := []interface{}{
+		struct{ Version lossyVersion }{lossyVersion{1, 2}},
+		struct{ ID interface{} }{uint64(1<<63 + 1)}, // decodes as a float64
+		map[string]interface{}{"n": int64(9007199254740993)},
+	}
+	for _, v := range unstable {
+		for _, o := range []Options{opts, withFields} {
+			if _, err := CanonicalizeAndHashWithOptions(v, o); !errors.Is(err, ErrUnstableEncoding) {
+				t.Errorf("%#v: expected ErrUnstableEncoding, got %v", v, err)
+			}
+		}
+	}
+}
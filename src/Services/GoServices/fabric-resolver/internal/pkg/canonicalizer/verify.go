@@ -0,0 +1,61 @@
+package canonicalizer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// VerifyHashJSON reports whether expectedHex is the hex SHA-256 of the canonical form
+// of raw JSON bytes, as CanonicalizeAndHashJSON returns it. Hex digits may be in
+// either case. The digests are compared in constant time; an expected value that is
+// not a hex SHA-256 digest is an error rather than a mismatch.
+func VerifyHashJSON(raw []byte, expectedHex string) (bool, error) {
+	expected, err := decodeDigestHex(expectedHex)
+	if err != nil {
+		return false, err
+	}
+
+	canonicalBytes, err := CanonicalizeJSON(raw)
+	if err != nil {
+		return false, err
+	}
+
+	sum := sha256.Sum256(canonicalBytes)
+	return hmac.Equal(sum[:], expected), nil
+}
+
+// VerifyCommitJSON reports whether expectedHex is the hex HMAC-SHA256 of the canonical
+// form of raw JSON bytes under key, as CanonicalizeAndCommitJSON returns it. It
+// compares like VerifyHashJSON.
+func VerifyCommitJSON(raw []byte, key []byte, expectedHex string) (bool, error) {
+	if len(key) < MinHMACKeyLen {
+		return false, ErrKeyTooShort
+	}
+	expected, err := decodeDigestHex(expectedHex)
+	if err != nil {
+		return false, err
+	}
+
+	canonicalBytes, err := CanonicalizeJSON(raw)
+	if err != nil {
+		return false, err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(canonicalBytes)
+	return hmac.Equal(mac.Sum(nil), expected), nil
+}
+
+// decodeDigestHex decodes a hex SHA-256 digest or HMAC.
+func decodeDigestHex(s string) ([]byte, error) {
+	if len(s) != 2*sha256.Size {
+		return nil, fmt.Errorf("expected %d hex characters, got %d", 2*sha256.Size, len(s))
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex digest: %w", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,80 @@
+package canonicalizer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerifyHashJSON(t *testing.T) {
+	raw := []byte(`{"b": 2, "a": 1}`)
+	digest := knownDigests[SHA256]
+
+	for _, expected := range []string{digest, strings.ToUpper(digest)} {
+		ok, err := VerifyHashJSON(raw, expected)
+		if err != nil || !ok {
+			t.Errorf("%s: expected a match, got %v %v", expected, ok, err)
+		}
+	}
+
+	ok, err := VerifyHashJSON([]byte(`{"a":1,"b":3}`), digest)
+	if err != nil || ok {
+		t.Errorf("expected another document not to match, got %v %v", ok, err)
+	}
+	if _, err := VerifyHashJSON([]byte(`{"a":1} x`), digest); !errors.Is(err, ErrTrailingData) {
+		t.Error("expected trailing data to be rejected")
+	}
+}
+
+func TestVerifyCommitJSON(t *testing.T) {
+	key := []byte("this-is-a-32-byte-secret-key-123")
+	raw := []byte(`{"b":2,"a":1}`)
+	commitment, err := CanonicalizeAndCommitJSON(raw, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{commitment, strings.ToUpper(commitment)} {
+		ok, err := VerifyCommitJSON([]byte(`{"a":1,"b":2}`), key, expected)
+		if err != nil || !ok {
+			t.Errorf("%s: expected a match, got %v %v", expected, ok, err)
+		}
+	}
+
+	otherKey := []byte("this-is-another-32-byte-key-4567")
+	if ok, err := VerifyCommitJSON(raw, otherKey, commitment); err != nil || ok {
+		t.Errorf("expected another key not to match, got %v %v", ok, err)
+	}
+	// The plain hash is not the commitment
+	if ok, _ := VerifyCommitJSON(raw, key, knownDigests[SHA256]); ok {
+		t.Error("expected the plain hash not to match")
+	}
+	if _, err := VerifyCommitJSON(raw, key[:31], commitment); !errors.Is(err, ErrKeyTooShort) {
+		t.Error("expected a short key to be rejected")
+	}
+}
+
+func TestVerify_MalformedExpectedValues(t *testing.T) {
+	raw := []byte(`{"a":1,"b":2}`)
+	key := []byte("this-is-a-32-byte-secret-key-123")
+	digest := knownDigests[SHA256]
+
+	tests := []struct {
+		name, expected, want string
+	}{
+		{"empty", "", "expected 64 hex characters"},
+		{"truncated", digest[:62], "expected 64 hex characters"},
+		{"too long", digest + "00", "expected 64 hex characters"},
+		{"sha512", knownDigests[SHA512], "expected 64 hex characters"},
+		{"prefixed", "sha256:" + digest[:57], "invalid hex"},
+		{"not hex", "zz" + digest[2:], "invalid hex"},
+	}
+	for _, tt := range tests {
+		if ok, err := VerifyHashJSON(raw, tt.expected); ok || err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("VerifyHashJSON %s: expected an error containing %q, got %v %v", tt.name, tt.want, ok, err)
+		}
+		if ok, err := VerifyCommitJSON(raw, key, tt.expected); ok || err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("VerifyCommitJSON %s: expected an error containing %q, got %v %v", tt.name, tt.want, ok, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Package didkey derives DID documents for did:key identifiers
+// (https://w3c-ccg.github.io/did-method-key/). A did:key is the multibase,
+// multicodec-prefixed public key itself, so the document never needs storing.
+//
+// Ed25519 keys become Ed25519VerificationKey2020 methods with publicKeyMultibase;
+// P-256 keys become JsonWebKey2020 methods with an EC publicKeyJwk.
+package didkey
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/pkg/multibase"
+)
+
+var (
+	ErrInvalid        = errors.New("didkey: invalid did:key")
+	ErrUnsupportedKey = errors.New("didkey: unsupported key type")
+)
+
+// Multicodec headers (unsigned varints) of the supported public key types
+var (
+	ed25519Codec = []byte{0xed, 0x01}
+	p256Codec    = []byte{0x80, 0x24}
+)
+
+const (
+	didCoreContext    = "https://www.w3.org/ns/did/v1"
+	ed25519Context    = "https://w3id.org/security/suites/ed25519-2020/v1"
+	jsonWebKeyContext = "https://w3id.org/security/suites/jws-2020/v1"
+)
+
+// IsDIDKey reports whether did uses the did:key method.
+func IsDIDKey(did string) bool {
+	return strings.HasPrefix(did, "did:key:")
+}
+
+// Resolve builds the DID document for a did:key. The single verification method
+// is referenced from both authentication and assertionMethod.
+func Resolve(did string) (*domain.DIDDocument, error) {
+	encoded, ok := strings.CutPrefix(did, "did:key:")
+	if !ok || encoded == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalid, did)
+	}
+	if encoded[0] != multibase.Base58BTC {
+		return nil, fmt.Errorf("%w: key must be base58btc multibase (z prefix)", ErrInvalid)
+	}
+	raw, err := multibase.Decode(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	vm := domain.VerificationMethod{
+		ID:         did + "#" + encoded,
+		Controller: did,
+	}
+	var suiteContext string
+
+	switch {
+	case bytes.HasPrefix(raw, ed25519Codec):
+		key := raw[len(ed25519Codec):]
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: Ed25519 key must be %d bytes, got %d", ErrInvalid, ed25519.PublicKeySize, len(key))
+		}
+		vm.Type = "Ed25519VerificationKey2020"
+		vm.PublicKeyMultibase = encoded
+		suiteContext = ed25519Context
+
+	case bytes.HasPrefix(raw, p256Codec):
+		x, y := elliptic.UnmarshalCompressed(elliptic.P256(), raw[len(p256Codec):])
+		if x == nil {
+			return nil, fmt.Errorf("%w: P-256 key is not a valid compressed point", ErrInvalid)
+		}
+		vm.Type = "JsonWebKey2020"
+		vm.PublicKeyJwk = &domain.JWK{
+			Kty: "EC",
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(x.FillBytes(make([]byte, 32))),
+			Y:   base64.RawURLEncoding.EncodeToString(y.FillBytes(make([]byte, 32))),
+		}
+		suiteContext = jsonWebKeyContext
+
+	default:
+		return nil, fmt.Errorf("%w: multicodec %x", ErrUnsupportedKey, raw[:min(2, len(raw))])
+	}
+
+	return &domain.DIDDocument{
+		Context:            []string{didCoreContext, suiteContext},
+		ID:                 did,
+		VerificationMethod: []domain.VerificationMethod{vm},
+		Authentication:     []string{vm.ID},
+		AssertionMethod:    []string{vm.ID},
+	}, nil
+}
@@ -0,0 +1,69 @@
+package didkey
+
+import (
+	"errors"
+	"testing"
+
+	"fabric-resolver/internal/pkg/multibase"
+)
+
+// Test vectors from the did:key spec (test-vectors/ed25519-x25519.json and nist-curves.json)
+func TestResolve_SpecVectors(t *testing.T) {
+	t.Run("Ed25519", func(t *testing.T) {
+		const did = "did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"
+		doc, err := Resolve(did)
+		if err != nil {
+			t.Fatalf("Resolve failed: %v", err)
+		}
+		vm := doc.VerificationMethod[0]
+		if vm.ID != did+"#z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp" || vm.Type != "Ed25519VerificationKey2020" || vm.Controller != did {
+			t.Errorf("unexpected verification method: %+v", vm)
+		}
+		if vm.PublicKeyMultibase != "z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp" {
+			t.Errorf("unexpected publicKeyMultibase %q", vm.PublicKeyMultibase)
+		}
+		if len(doc.Authentication) != 1 || doc.Authentication[0] != vm.ID || len(doc.AssertionMethod) != 1 || doc.AssertionMethod[0] != vm.ID {
+			t.Errorf("unexpected relationships: %v / %v", doc.Authentication, doc.AssertionMethod)
+		}
+	})
+
+	t.Run("P-256", func(t *testing.T) {
+		const did = "did:key:zDnaerDaTF5BXEavCrfRZEk316dpbLsfPDZ3WJ5hRTPFU2169"
+		doc, err := Resolve(did)
+		if err != nil {
+			t.Fatalf("Resolve failed: %v", err)
+		}
+		jwk := doc.VerificationMethod[0].PublicKeyJwk
+		if jwk == nil || jwk.Kty != "EC" || jwk.Crv != "P-256" ||
+			jwk.X != "fyNYMN0976ci7xqiSdag3buk-ZCwgXU4kz9XNkBlNUI" || jwk.Y != "hW2ojTNfH7Jbi8--CJUo3OCbH3y5n91g-IMA9MLMbTU" {
+			t.Errorf("unexpected JWK: %+v", jwk)
+		}
+		if doc.VerificationMethod[0].Type != "JsonWebKey2020" {
+			t.Errorf("unexpected type %q", doc.VerificationMethod[0].Type)
+		}
+	})
+}
+
+func TestResolve_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		did     string
+		wantErr error
+	}{
+		{"not did:key", "did:web:example.com", ErrInvalid},
+		{"empty key", "did:key:", ErrInvalid},
+		{"not base58btc", "did:key:f" + "ed01" + "00", ErrInvalid},
+		{"bad base58", "did:key:z0OIl", ErrInvalid},
+		{"short Ed25519", "did:key:" + multibase.Encode(append([]byte{0xed, 0x01}, make([]byte, 31)...)), ErrInvalid},
+		{"bad P-256 point", "did:key:" + multibase.Encode(append([]byte{0x80, 0x24}, make([]byte, 33)...)), ErrInvalid},
+		{"secp256k1", "did:key:" + multibase.Encode(append([]byte{0xe7, 0x01}, make([]byte, 33)...)), ErrUnsupportedKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Resolve(tt.did); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
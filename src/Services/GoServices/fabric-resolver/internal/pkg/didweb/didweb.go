@@ -0,0 +1,137 @@
+// Package didweb resolves did:web identifiers (https://w3c-ccg.github.io/did-method-web/)
+// by fetching the DID document from the web server named in the DID.
+package didweb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/requestid"
+	"fabric-resolver/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrNotWebDID  = errors.New("didweb: not a did:web identifier")
+	ErrNotFound   = errors.New("didweb: DID document not found")
+	ErrIDMismatch = errors.New("didweb: document id does not match the DID")
+	ErrTooLarge   = errors.New("didweb: DID document too large")
+)
+
+const (
+	// DefaultTimeout bounds a single fetch when the caller supplies no client.
+	DefaultTimeout = 5 * time.Second
+	// DefaultMaxBytes caps the size of a fetched document.
+	DefaultMaxBytes = 256 << 10
+)
+
+// Resolver fetches did:web documents over HTTPS.
+type Resolver struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+// NewResolver creates a resolver. A nil client gets DefaultTimeout and
+// maxBytes <= 0 uses DefaultMaxBytes.
+func NewResolver(client *http.Client, maxBytes int64) *Resolver {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	return &Resolver{client: client, maxBytes: maxBytes}
+}
+
+// DocumentURL translates a did:web DID to the HTTPS URL of its document:
+//
+//	did:web:example.com               -> https://example.com/.well-known/did.json
+//	did:web:example.com%3A8443        -> https://example.com:8443/.well-known/did.json
+//	did:web:example.com:user:alice    -> https://example.com/user/alice/did.json
+func DocumentURL(did string) (string, error) {
+	id, ok := strings.CutPrefix(did, "did:web:")
+	if !ok || id == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotWebDID, did)
+	}
+
+	segments := strings.Split(id, ":")
+	host, err := url.PathUnescape(segments[0])
+	if err != nil || host == "" || strings.ContainsAny(host, "/?#@") {
+		return "", fmt.Errorf("%w: invalid domain in %s", ErrNotWebDID, did)
+	}
+
+	path := "/.well-known"
+	if len(segments) > 1 {
+		var b strings.Builder
+		for _, seg := range segments[1:] {
+			p, err := url.PathUnescape(seg)
+			if err != nil || p == "" || strings.Contains(p, "/") {
+				return "", fmt.Errorf("%w: invalid path segment in %s", ErrNotWebDID, did)
+			}
+			b.WriteString("/" + url.PathEscape(p))
+		}
+		path = b.String()
+	}
+
+	return "https://" + host + path + "/did.json", nil
+}
+
+// Resolve fetches and decodes the document for did, checking that its id matches.
+func (r *Resolver) Resolve(ctx context.Context, did string) (*domain.DIDDocument, error) {
+	docURL, err := DocumentURL(did)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, "didweb.resolve",
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attribute.String("did", did)))
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, docURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("didweb: build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/did+json, application/json")
+	requestid.Forward(req)
+	tracing.Inject(req)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("didweb: fetch %s: %w", docURL, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, docURL)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("didweb: fetch %s: unexpected status %d", docURL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, r.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("didweb: read %s: %w", docURL, err)
+	}
+	if int64(len(body)) > r.maxBytes {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, r.maxBytes)
+	}
+
+	var doc domain.DIDDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("didweb: decode %s: %w", docURL, err)
+	}
+	if doc.ID != did {
+		return nil, fmt.Errorf("%w: got %q", ErrIDMismatch, doc.ID)
+	}
+	return &doc, nil
+}
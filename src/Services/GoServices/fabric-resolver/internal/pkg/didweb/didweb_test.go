@@ -0,0 +1,145 @@
+package didweb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/requestid"
+)
+
+func TestDocumentURL(t *testing.T) {
+	tests := []struct {
+		did     string
+		want    string
+		wantErr bool
+	}{
+		{"did:web:example.com", "https://example.com/.well-known/did.json", false},
+		{"did:web:example.com%3A8443", "https://example.com:8443/.well-known/did.json", false},
+		{"did:web:example.com:user:alice", "https://example.com/user/alice/did.json", false},
+		{"did:web:example.com%3A3000:user:alice", "https://example.com:3000/user/alice/did.json", false},
+		{"did:web:", "", true},
+		{"did:key:z6Mk", "", true},
+		{"did:web:example.com%2Fevil", "", true},
+		{"did:web:example.com:user%2Falice", "", true},
+		{"did:web:example.com::alice", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := DocumentURL(tt.did)
+		if tt.wantErr {
+			if !errors.Is(err, ErrNotWebDID) {
+				t.Errorf("%s: expected ErrNotWebDID, got %q, %v", tt.did, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: expected %s, got %s (%v)", tt.did, tt.want, got, err)
+		}
+	}
+}
+
+// newServer starts a TLS server with handler and returns a resolver that trusts it,
+// plus the did:web DID naming the server.
+func newServer(t *testing.T, handler http.HandlerFunc) (*Resolver, string) {
+	t.Helper()
+	srv := httptest.NewTLSServer(handler)
+	t.Cleanup(srv.Close)
+
+	host := strings.TrimPrefix(srv.URL, "https://")
+	return NewResolver(srv.Client(), 0), "did:web:" + strings.ReplaceAll(host, ":", "%3A")
+}
+
+func TestResolve(t *testing.T) {
+	var did string
+	resolver, base := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/did.json":
+			w.Write([]byte(`{"@context":["https://www.w3.org/ns/did/v1"],"id":"` + did + `"}`))
+		case "/user/alice/did.json":
+			w.Write([]byte(`{"id":"` + did + `","verificationMethod":[{"id":"` + did + `#key-1","type":"JsonWebKey2020","controller":"` + did + `","publicKeyJwk":{"kty":"OKP","crv":"Ed25519","x":"abc"}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	did = base
+	doc, err := resolver.Resolve(context.Background(), did)
+	if err != nil || doc.ID != did {
+		t.Fatalf("domain-form resolve failed: %+v, %v", doc, err)
+	}
+
+	did = base + ":user:alice"
+	doc, err = resolver.Resolve(context.Background(), did)
+	if err != nil {
+		t.Fatalf("path-form resolve failed: %v", err)
+	}
+	if len(doc.VerificationMethod) != 1 || doc.VerificationMethod[0].PublicKeyJwk == nil {
+		t.Errorf("unexpected path-form document: %+v", doc)
+	}
+
+	if _, err := resolver.Resolve(context.Background(), base+":nobody"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestResolve_ForwardsRequestID(t *testing.T) {
+	var did, got string
+	resolver, base := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(requestid.Header)
+		w.Write([]byte(`{"id":"` + did + `"}`))
+	})
+
+	did = base
+	if _, err := resolver.Resolve(requestid.WithID(context.Background(), "req-42"), did); err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if got != "req-42" {
+		t.Errorf("expected the request id to be forwarded, got %q", got)
+	}
+}
+
+func TestResolve_IDMismatch(t *testing.T) {
+	resolver, did := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"did:web:attacker.example"}`))
+	})
+
+	if _, err := resolver.Resolve(context.Background(), did); !errors.Is(err, ErrIDMismatch) {
+		t.Errorf("expected ErrIDMismatch, got %v", err)
+	}
+}
+
+func TestResolve_TooLarge(t *testing.T) {
+	resolver, did := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"` + strings.Repeat("x", DefaultMaxBytes) + `"}`))
+	})
+
+	if _, err := resolver.Resolve(context.Background(), did); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestResolve_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	resolver, did := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+	resolver.client.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	_, err := resolver.Resolve(context.Background(), did)
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("resolve took %v despite the client timeout", elapsed)
+	}
+}
@@ -0,0 +1,144 @@
+// Package merkle builds SHA-256 Merkle trees over document hashes and produces and
+// checks inclusion proofs, so a batch of documents can be anchored with a single root.
+//
+// Leaves and interior nodes are hashed with distinct prefixes (0x00 and 0x01, as in
+// RFC 6962) so an interior node can never be passed off as a leaf. A node without a
+// sibling is promoted to the next level unchanged instead of being paired with itself.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoLeaves      = errors.New("merkle: at least one leaf is required")
+	ErrDuplicateLeaf = errors.New("merkle: duplicate leaf")
+	ErrLeafIndex     = errors.New("merkle: leaf index out of range")
+)
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// ProofStep is one sibling on the path from a leaf to the root.
+type ProofStep struct {
+	Hash []byte
+	Left bool // true if the sibling is the left operand
+}
+
+// Tree is an immutable Merkle tree. levels[0] holds the hashed leaves and
+// the last level holds the root.
+type Tree struct {
+	levels [][][]byte
+}
+
+// New builds a tree over leaves in the given order. Leaves must be unique, so that
+// every leaf has exactly one position and one proof.
+func New(leaves [][]byte) (*Tree, error) {
+	if len(leaves) == 0 {
+		return nil, ErrNoLeaves
+	}
+
+	seen := make(map[string]int, len(leaves))
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		if j, dup := seen[string(leaf)]; dup {
+			return nil, fmt.Errorf("%w: leaves %d and %d are both %x", ErrDuplicateLeaf, j, i, leaf)
+		}
+		seen[string(leaf)] = i
+		level[i] = HashLeaf(leaf)
+	}
+
+	levels := [][][]byte{level}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, hashNode(level[i], level[i+1]))
+		}
+		levels = append(levels, next)
+		level = next
+	}
+
+	return &Tree{levels: levels}, nil
+}
+
+// Root returns the root hash of the tree.
+func (t *Tree) Root() []byte {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Len returns the number of leaves.
+func (t *Tree) Len() int {
+	return len(t.levels[0])
+}
+
+// Proof returns the inclusion proof for the leaf at index i.
+// A single-leaf tree has an empty proof.
+func (t *Tree) Proof(i int) ([]ProofStep, error) {
+	if i < 0 || i >= t.Len() {
+		return nil, fmt.Errorf("%w: %d", ErrLeafIndex, i)
+	}
+
+	var proof []ProofStep
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := i ^ 1
+		if sibling < len(level) {
+			proof = append(proof, ProofStep{Hash: level[sibling], Left: sibling < i})
+		}
+		i /= 2
+	}
+	return proof, nil
+}
+
+// Verify reports whether proof links leaf to root.
+func Verify(leaf []byte, proof []ProofStep, root []byte) bool {
+	node := HashLeaf(leaf)
+	for _, step := range proof {
+		if step.Left {
+			node = hashNode(step.Hash, node)
+		} else {
+			node = hashNode(node, step.Hash)
+		}
+	}
+	return bytes.Equal(node, root)
+}
+
+// HashLeaf returns the tree hash of a leaf.
+func HashLeaf(leaf []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+func hashNode(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// DecodeHex decodes a hex-encoded hash, accepting an optional 0x prefix.
+func DecodeHex(s string) ([]byte, error) {
+	if len(s) > 1 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		s = s[2:]
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("merkle: invalid hex %q: %w", s, err)
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("merkle: empty hash")
+	}
+	return b, nil
+}
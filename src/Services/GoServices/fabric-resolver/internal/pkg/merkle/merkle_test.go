@@ -0,0 +1,138 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func testLeaves(n int) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("doc-%d", i)))
+		leaves[i] = sum[:]
+	}
+	return leaves
+}
+
+func TestSingleLeaf(t *testing.T) {
+	leaves := testLeaves(1)
+	tree, err := New(leaves)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if !bytes.Equal(tree.Root(), HashLeaf(leaves[0])) {
+		t.Error("root of a single-leaf tree should be the leaf hash")
+	}
+	proof, _ := tree.Proof(0)
+	if len(proof) != 0 {
+		t.Errorf("expected empty proof, got %d steps", len(proof))
+	}
+	if !Verify(leaves[0], proof, tree.Root()) {
+		t.Error("single-leaf proof does not verify")
+	}
+}
+
+func TestTwoLeavesRoot(t *testing.T) {
+	leaves := testLeaves(2)
+	tree, _ := New(leaves)
+
+	want := hashNode(HashLeaf(leaves[0]), HashLeaf(leaves[1]))
+	if !bytes.Equal(tree.Root(), want) {
+		t.Errorf("root %x, want %x", tree.Root(), want)
+	}
+}
+
+func TestAllProofsVerify(t *testing.T) {
+	// Covers odd counts at several levels (3, 5, 7, 9, ...)
+	for n := 1; n <= 17; n++ {
+		leaves := testLeaves(n)
+		tree, err := New(leaves)
+		if err != nil {
+			t.Fatalf("n=%d: New failed: %v", n, err)
+		}
+		for i, leaf := range leaves {
+			proof, err := tree.Proof(i)
+			if err != nil {
+				t.Fatalf("n=%d: Proof(%d) failed: %v", n, i, err)
+			}
+			if !Verify(leaf, proof, tree.Root()) {
+				t.Errorf("n=%d: proof for leaf %d does not verify", n, i)
+			}
+		}
+	}
+}
+
+func TestOddLeafIsPromoted(t *testing.T) {
+	leaves := testLeaves(3)
+	tree, _ := New(leaves)
+
+	want := hashNode(hashNode(HashLeaf(leaves[0]), HashLeaf(leaves[1])), HashLeaf(leaves[2]))
+	if !bytes.Equal(tree.Root(), want) {
+		t.Errorf("root %x, want %x", tree.Root(), want)
+	}
+	proof, _ := tree.Proof(2)
+	if len(proof) != 1 || !proof[0].Left {
+		t.Errorf("expected a single left sibling for the promoted leaf, got %+v", proof)
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	leaves := testLeaves(5)
+	tree, _ := New(leaves)
+	proof, _ := tree.Proof(1)
+
+	if Verify(leaves[2], proof, tree.Root()) {
+		t.Error("proof verified for the wrong leaf")
+	}
+
+	flipped := append([]ProofStep(nil), proof...)
+	flipped[0].Left = !flipped[0].Left
+	if Verify(leaves[1], flipped, tree.Root()) {
+		t.Error("proof verified with a flipped sibling position")
+	}
+
+	otherTree, _ := New(testLeaves(6))
+	if Verify(leaves[1], proof, otherTree.Root()) {
+		t.Error("proof verified against a different root")
+	}
+
+	// An interior node must not be accepted as a leaf
+	interior := hashNode(HashLeaf(leaves[0]), HashLeaf(leaves[1]))
+	upper, _ := tree.Proof(0)
+	if Verify(interior, upper[1:], tree.Root()) {
+		t.Error("interior node verified as a leaf")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := New(nil); !errors.Is(err, ErrNoLeaves) {
+		t.Errorf("expected ErrNoLeaves, got %v", err)
+	}
+
+	leaves := testLeaves(3)
+	if _, err := New(append(leaves, leaves[1])); !errors.Is(err, ErrDuplicateLeaf) {
+		t.Errorf("expected ErrDuplicateLeaf, got %v", err)
+	}
+
+	tree, _ := New(leaves)
+	if _, err := tree.Proof(3); !errors.Is(err, ErrLeafIndex) {
+		t.Errorf("expected ErrLeafIndex, got %v", err)
+	}
+}
+
+func TestDecodeHex(t *testing.T) {
+	for _, in := range []string{"abcd", "0xabcd", "ABCD"} {
+		if b, err := DecodeHex(in); err != nil || !bytes.Equal(b, []byte{0xab, 0xcd}) {
+			t.Errorf("DecodeHex(%q) = %x, %v", in, b, err)
+		}
+	}
+	for _, in := range []string{"", "0x", "xyz", "abc"} {
+		if _, err := DecodeHex(in); err == nil {
+			t.Errorf("DecodeHex(%q) should fail", in)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package multibase encodes and decodes self-describing base-encoded strings
+// (https://github.com/multiformats/multibase) as used by publicKeyMultibase
+// in DID documents. The first character names the encoding.
+//
+// Supported prefixes are "z" (base58btc, the one DID documents use in practice),
+// "f" (lowercase base16) and "u" (unpadded base64url).
+package multibase
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	ErrEmpty             = errors.New("multibase: empty string")
+	ErrUnsupportedPrefix = errors.New("multibase: unsupported prefix")
+	ErrInvalidEncoding   = errors.New("multibase: invalid encoding")
+)
+
+const (
+	Base58BTC = 'z'
+	Base16    = 'f'
+	Base64URL = 'u'
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Index = func() [256]int8 {
+	var idx [256]int8
+	for i := range idx {
+		idx[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		idx[base58Alphabet[i]] = int8(i)
+	}
+	return idx
+}()
+
+// Encode returns data as a base58btc multibase string ("z" prefix).
+func Encode(data []byte) string {
+	return string(Base58BTC) + encodeBase58(data)
+}
+
+// EncodeAs returns data as a multibase string in the encoding named by prefix:
+// Base58BTC, Base16 or Base64URL.
+func EncodeAs(prefix byte, data []byte) (string, error) {
+	switch prefix {
+	case Base58BTC:
+		return Encode(data), nil
+	case Base16:
+		return string(Base16) + hex.EncodeToString(data), nil
+	case Base64URL:
+		return string(Base64URL) + base64.RawURLEncoding.EncodeToString(data), nil
+	}
+	return "", fmt.Errorf("%w %q", ErrUnsupportedPrefix, prefix)
+}
+
+// Decode decodes a multibase string, choosing the encoding from its prefix.
+func Decode(s string) ([]byte, error) {
+	if s == "" {
+		return nil, ErrEmpty
+	}
+
+	body := s[1:]
+	var (
+		data []byte
+		err  error
+	)
+	switch s[0] {
+	case Base58BTC:
+		data, err = decodeBase58(body)
+	case Base16:
+		data, err = hex.DecodeString(body)
+	case Base64URL:
+		data, err = base64.RawURLEncoding.DecodeString(body)
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedPrefix, s[0])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	return data, nil
+}
+
+func encodeBase58(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	n := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func decodeBase58(s string) ([]byte, error) {
+	if s == "" {
+		return nil, errors.New("empty base58 string")
+	}
+
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for i := 0; i < len(s); i++ {
+		v := base58Index[s[i]]
+		if v < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q at offset %d", s[i], i)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(v)))
+	}
+
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
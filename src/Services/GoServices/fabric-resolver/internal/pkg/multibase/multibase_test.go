@@ -0,0 +1,90 @@
+package multibase
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	inputs := [][]byte{
+		{0x00},
+		{0x00, 0x00, 0x01},
+		[]byte("hello world"),
+		bytes.Repeat([]byte{0xff}, 32),
+	}
+
+	for _, in := range inputs {
+		enc := Encode(in)
+		if enc[0] != 'z' {
+			t.Fatalf("expected base58btc prefix, got %q", enc)
+		}
+		out, err := Decode(enc)
+		if err != nil {
+			t.Fatalf("Decode(%q) failed: %v", enc, err)
+		}
+		if !bytes.Equal(in, out) {
+			t.Errorf("round trip mismatch: %x -> %q -> %x", in, enc, out)
+		}
+	}
+}
+
+func TestKnownVectors(t *testing.T) {
+	tests := []struct {
+		encoded string
+		want    []byte
+	}{
+		// From the multibase spec test vectors for "yes mani !"
+		{"z7paNL19xttacUY", []byte("yes mani !")},
+		{"f796573206d616e692021", []byte("yes mani !")},
+		{"ueWVzIG1hbmkgIQ", []byte("yes mani !")},
+		{"z1", []byte{0x00}},
+	}
+
+	for _, tt := range tests {
+		got, err := Decode(tt.encoded)
+		if err != nil {
+			t.Fatalf("Decode(%q) failed: %v", tt.encoded, err)
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("Decode(%q) = %q, want %q", tt.encoded, got, tt.want)
+		}
+	}
+
+	if got := Encode([]byte("yes mani !")); got != "z7paNL19xttacUY" {
+		t.Errorf("Encode = %q", got)
+	}
+	for _, want := range tests[:3] {
+		if got, err := EncodeAs(want.encoded[0], want.want); err != nil || got != want.encoded {
+			t.Errorf("EncodeAs(%q) = %q, %v; want %q", want.encoded[0], got, err, want.encoded)
+		}
+	}
+	if _, err := EncodeAs('m', []byte("yes mani !")); !errors.Is(err, ErrUnsupportedPrefix) {
+		t.Errorf("expected ErrUnsupportedPrefix for plain base64, got %v", err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{"empty", "", ErrEmpty},
+		{"unknown prefix", "x123", ErrUnsupportedPrefix},
+		{"base58btc without prefix", "7paNL19xttacUY", ErrUnsupportedPrefix},
+		{"uppercase base16 prefix", "F796573", ErrUnsupportedPrefix},
+		{"invalid base58 character", "z0OIl", ErrInvalidEncoding},
+		{"prefix only", "z", ErrInvalidEncoding},
+		{"odd base16", "f7", ErrInvalidEncoding},
+		{"padded base64url", "ueWVz=", ErrInvalidEncoding},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.input); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decode(%q): expected %v, got %v", tt.input, tt.wantErr, err)
+			}
+		})
+	}
+}
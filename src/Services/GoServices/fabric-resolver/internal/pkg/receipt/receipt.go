@@ -0,0 +1,173 @@
+// Package receipt signs and verifies anchor receipts: portable statements that
+// the resolver observed a hash on the ledger at a block and time, which can be
+// checked offline with the resolver's Ed25519 public key.
+//
+// The signature is Ed25519 over the ASCII hex SHA-256 of the receipt's canonical
+// JSON (keys sorted, no insignificant whitespace, no HTML escaping), as produced by
+// canonicalizer.CanonicalizeAndHashJSON.
+package receipt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fabric-resolver/internal/pkg/canonicalizer"
+	"fabric-resolver/internal/pkg/multibase"
+)
+
+// ErrInvalidSignature means the receipt was not signed by the key, or was changed after signing.
+var ErrInvalidSignature = errors.New("receipt: invalid signature")
+
+// ed25519Codec is the multicodec header of Ed25519 public keys.
+var ed25519Codec = []byte{0xed, 0x01}
+
+// Receipt is the signed statement.
+type Receipt struct {
+	Hash        string `json:"hash"`
+	Algorithm   string `json:"algorithm,omitempty"`
+	TxID        string `json:"txId"`
+	BlockNumber uint64 `json:"blockNumber"`
+	Timestamp   string `json:"timestamp"` // when the anchor was written, RFC3339
+	IssuerDID   string `json:"issuerDid,omitempty"`
+	IssuedAt    string `json:"issuedAt"` // when the receipt was signed, RFC3339
+}
+
+// SignedReceipt is a receipt with its detached signature.
+type SignedReceipt struct {
+	Receipt   Receipt `json:"receipt"`
+	KeyID     string  `json:"keyId"`     // did:key of the signing key
+	Signature string  `json:"signature"` // base64url, unpadded
+}
+
+// Digest returns the hex SHA-256 of the canonical JSON of r, the message that is signed.
+func Digest(r Receipt) (string, error) {
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	return canonicalizer.CanonicalizeAndHashJSON(raw)
+}
+
+// Signer signs receipts with one Ed25519 key.
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewSigner returns a signer using key.
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{key: key}
+}
+
+// LoadOrCreateKey reads the PKCS#8 PEM Ed25519 key at path. If the file does not
+// exist, it generates a key and writes it there, readable only by the owner.
+func LoadOrCreateKey(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return createKey(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read receipt key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("receipt key %s is not a PEM private key", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse receipt key %s: %w", path, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("receipt key %s is not an Ed25519 key", path)
+	}
+	return NewSigner(key), nil
+}
+
+func createKey(path string) (*Signer, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create receipt key directory: %w", err)
+	}
+	// O_EXCL so two instances starting together cannot overwrite each other's key
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return LoadOrCreateKey(path)
+		}
+		return nil, fmt.Errorf("failed to create receipt key: %w", err)
+	}
+	defer f.Close()
+	if err := pem.Encode(f, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		return nil, fmt.Errorf("failed to write receipt key: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to write receipt key: %w", err)
+	}
+	return NewSigner(key), nil
+}
+
+// PublicKey returns the verification key.
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// KeyID returns the did:key of the verification key.
+func (s *Signer) KeyID() string {
+	return KeyID(s.PublicKey())
+}
+
+// Sign signs r.
+func (s *Signer) Sign(r Receipt) (*SignedReceipt, error) {
+	digest, err := Digest(r)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedReceipt{
+		Receipt:   r,
+		KeyID:     s.KeyID(),
+		Signature: base64.RawURLEncoding.EncodeToString(ed25519.Sign(s.key, []byte(digest))),
+	}, nil
+}
+
+// KeyID returns the did:key of pub.
+func KeyID(pub ed25519.PublicKey) string {
+	return "did:key:" + multibase.Encode(append(append([]byte{}, ed25519Codec...), pub...))
+}
+
+// VerifyReceipt checks that signed carries a signature by pub over its receipt.
+// It does not consult the ledger; a valid receipt proves what the resolver
+// observed when it signed, not that the anchor is still unrevoked.
+func VerifyReceipt(signed *SignedReceipt, pub ed25519.PublicKey) error {
+	if signed == nil || len(pub) != ed25519.PublicKeySize {
+		return ErrInvalidSignature
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(signed.Signature, "="))
+	if err != nil {
+		return fmt.Errorf("%w: signature must be base64url encoded", ErrInvalidSignature)
+	}
+	digest, err := Digest(signed.Receipt)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, []byte(digest), signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
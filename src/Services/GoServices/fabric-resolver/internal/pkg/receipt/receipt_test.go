@@ -0,0 +1,118 @@
+package receipt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testReceipt = Receipt{
+	Hash:        "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	Algorithm:   "sha256",
+	TxID:        "tx-1",
+	BlockNumber: 42,
+	Timestamp:   "2025-01-01T12:00:00Z",
+	IssuerDID:   "did:ewallet:issuer",
+	IssuedAt:    "2025-01-02T08:00:00Z",
+}
+
+func newTestSigner(t *testing.T) *Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSigner(key)
+}
+
+func TestSignVerify_RoundTrip(t *testing.T) {
+	signer := newTestSigner(t)
+	signed, err := signer.Sign(testReceipt)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if !strings.HasPrefix(signed.KeyID, "did:key:z6Mk") {
+		t.Errorf("expected an Ed25519 did:key, got %s", signed.KeyID)
+	}
+	if err := VerifyReceipt(signed, signer.PublicKey()); err != nil {
+		t.Errorf("VerifyReceipt failed: %v", err)
+	}
+
+	// The signature covers the canonical digest, not the Go struct layout
+	digest, _ := Digest(testReceipt)
+	signature, _ := base64.RawURLEncoding.DecodeString(signed.Signature)
+	if !ed25519.Verify(signer.PublicKey(), []byte(digest), signature) {
+		t.Error("expected the signature to be over the canonical digest")
+	}
+}
+
+func TestVerifyReceipt_RejectsTampering(t *testing.T) {
+	signer := newTestSigner(t)
+	signed, _ := signer.Sign(testReceipt)
+
+	tampered := *signed
+	tampered.Receipt.BlockNumber++
+	if err := VerifyReceipt(&tampered, signer.PublicKey()); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected a changed block to be rejected, got %v", err)
+	}
+
+	tampered = *signed
+	tampered.Receipt.Hash = strings.Repeat("0", 64)
+	if err := VerifyReceipt(&tampered, signer.PublicKey()); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected a changed hash to be rejected, got %v", err)
+	}
+
+	if err := VerifyReceipt(signed, newTestSigner(t).PublicKey()); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected another key to be rejected, got %v", err)
+	}
+
+	tampered = *signed
+	tampered.Signature = "!!"
+	if err := VerifyReceipt(&tampered, signer.PublicKey()); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected a malformed signature to be rejected, got %v", err)
+	}
+}
+
+func TestLoadOrCreateKey_PersistsAcrossRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "receipt.pem")
+
+	first, err := LoadOrCreateKey(path)
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("key not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("expected the key to be readable by the owner only, got %v", perm)
+	}
+
+	signed, _ := first.Sign(testReceipt)
+
+	second, err := LoadOrCreateKey(path)
+	if err != nil {
+		t.Fatalf("reloading failed: %v", err)
+	}
+	if second.KeyID() != first.KeyID() {
+		t.Fatalf("expected the same key after a restart, got %s and %s", first.KeyID(), second.KeyID())
+	}
+	if err := VerifyReceipt(signed, second.PublicKey()); err != nil {
+		t.Errorf("receipt signed before the restart no longer verifies: %v", err)
+	}
+}
+
+func TestLoadOrCreateKey_RejectsInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "receipt.pem")
+	if err := os.WriteFile(path, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOrCreateKey(path); err == nil {
+		t.Error("expected an invalid key file to be rejected rather than replaced")
+	}
+}
@@ -0,0 +1,118 @@
+// Package statuslist implements the bitstring of a Status List 2021 credential
+// (https://www.w3.org/TR/2023/WD-vc-status-list-20230427/). Each credential is
+// assigned an index into the list; a set bit means its status (e.g. revoked) applies.
+//
+// Bit 0 is the most significant bit of the first byte. The encoded form is the
+// GZIP-compressed bitstring in unpadded base64url, as carried in encodedList.
+package statuslist
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MinBits is the minimum list size the spec recommends, so that a single
+// list is large enough to give its holders herd privacy (16KB).
+const MinBits = 131072
+
+// MaxBits bounds the size of a decoded list.
+const MaxBits = 64 * MinBits
+
+var (
+	ErrOutOfRange      = errors.New("statuslist: index out of range")
+	ErrInvalidEncoding = errors.New("statuslist: invalid encoded list")
+)
+
+// Bitstring is a fixed-size list of status bits.
+type Bitstring struct {
+	bits []byte
+}
+
+// New returns a bitstring of at least size bits, all clear. Sizes below MinBits are raised to MinBits.
+func New(size int) *Bitstring {
+	return &Bitstring{bits: make([]byte, byteLen(max(size, MinBits)))}
+}
+
+// Decode parses an encodedList value. Padded base64url is accepted as well.
+func Decode(encoded string) (*Bitstring, error) {
+	compressed, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	bits, err := io.ReadAll(io.LimitReader(zr, MaxBits/8+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	if len(bits) > MaxBits/8 {
+		return nil, fmt.Errorf("%w: more than %d bits", ErrInvalidEncoding, MaxBits)
+	}
+	return &Bitstring{bits: bits}, nil
+}
+
+// Encode returns the encodedList value of b.
+func (b *Bitstring) Encode() (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b.bits); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// Len returns the number of bits in b.
+func (b *Bitstring) Len() int {
+	return len(b.bits) * 8
+}
+
+// Get reports whether bit i is set.
+func (b *Bitstring) Get(i int) (bool, error) {
+	if i < 0 || i >= b.Len() {
+		return false, fmt.Errorf("%w: %d", ErrOutOfRange, i)
+	}
+	return b.bits[i/8]&mask(i) != 0, nil
+}
+
+// Set sets or clears bit i.
+func (b *Bitstring) Set(i int, value bool) error {
+	if i < 0 || i >= b.Len() {
+		return fmt.Errorf("%w: %d", ErrOutOfRange, i)
+	}
+	if value {
+		b.bits[i/8] |= mask(i)
+	} else {
+		b.bits[i/8] &^= mask(i)
+	}
+	return nil
+}
+
+// Grow extends b by MinBits at a time until it holds at least size bits.
+// Existing bits keep their indexes.
+func (b *Bitstring) Grow(size int) error {
+	if size > MaxBits {
+		return fmt.Errorf("%w: list cannot exceed %d bits", ErrOutOfRange, MaxBits)
+	}
+	for b.Len() < size {
+		b.bits = append(b.bits, make([]byte, MinBits/8)...)
+	}
+	return nil
+}
+
+func mask(i int) byte {
+	return 0x80 >> (i % 8)
+}
+
+func byteLen(bits int) int {
+	return (bits + 7) / 8
+}
@@ -0,0 +1,91 @@
+package statuslist
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBitPacking(t *testing.T) {
+	b := New(0)
+	if b.Len() != MinBits {
+		t.Fatalf("expected %d bits, got %d", MinBits, b.Len())
+	}
+
+	for _, i := range []int{0, 7, 8, 12345, MinBits - 1} {
+		if err := b.Set(i, true); err != nil {
+			t.Fatalf("Set(%d) failed: %v", i, err)
+		}
+	}
+	// Bit 0 is the most significant bit of the first byte
+	if b.bits[0] != 0x81 || b.bits[1] != 0x80 {
+		t.Errorf("unexpected bit layout: %08b %08b", b.bits[0], b.bits[1])
+	}
+
+	encoded, err := b.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	decoded, err := Decode(encoded)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	for i, want := range map[int]bool{0: true, 1: false, 7: true, 8: true, 9: false, 12345: true, 12346: false, MinBits - 1: true} {
+		if got, _ := decoded.Get(i); got != want {
+			t.Errorf("bit %d: expected %v, got %v", i, want, got)
+		}
+	}
+
+	decoded.Set(7, false)
+	if got, _ := decoded.Get(7); got {
+		t.Error("bit 7 not cleared")
+	}
+}
+
+func TestDecodeSpecExample(t *testing.T) {
+	// encodedList of the Status List 2021 example credential: 16KB of zeros
+	b, err := Decode("H4sIAAAAAAAAA-3BMQEAAADCoPVPbQwfoAAAAAAAAAAAAAAAAAAAAIC3AYbSVKsAQAAA")
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if b.Len() != MinBits {
+		t.Errorf("expected %d bits, got %d", MinBits, b.Len())
+	}
+}
+
+func TestGrow(t *testing.T) {
+	b := New(0)
+	b.Set(42, true)
+
+	if err := b.Grow(MinBits + 1); err != nil {
+		t.Fatalf("Grow failed: %v", err)
+	}
+	if b.Len() != 2*MinBits {
+		t.Errorf("expected growth by MinBits, got %d bits", b.Len())
+	}
+	if set, _ := b.Get(42); !set {
+		t.Error("existing bit lost on growth")
+	}
+	if err := b.Set(MinBits, true); err != nil {
+		t.Errorf("new bit not addressable: %v", err)
+	}
+
+	if err := b.Grow(MaxBits + 1); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("expected ErrOutOfRange beyond MaxBits, got %v", err)
+	}
+}
+
+func TestOutOfRange(t *testing.T) {
+	b := New(0)
+	for _, i := range []int{-1, MinBits} {
+		if err := b.Set(i, true); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("Set(%d): expected ErrOutOfRange, got %v", i, err)
+		}
+		if _, err := b.Get(i); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("Get(%d): expected ErrOutOfRange, got %v", i, err)
+		}
+	}
+
+	if _, err := Decode("!!"); !errors.Is(err, ErrInvalidEncoding) {
+		t.Errorf("expected ErrInvalidEncoding, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+// Package ratelimit limits request rates per client with token buckets, so one
+// misbehaving integration cannot starve the others.
+package ratelimit
+
+import (
+	"hash/maphash"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultIdleTTL is how long a client's bucket is kept after its last request when
+// none is configured.
+const DefaultIdleTTL = 10 * time.Minute
+
+// shardCount spreads clients over independently locked maps so concurrent
+// requests from different clients rarely contend.
+const shardCount = 32
+
+// Limit is a sustained rate in requests per second and the burst allowed above it.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// bucket holds the tokens of one client as of last.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type shard struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// Limiter keeps one token bucket per client key. Buckets idle for longer than the
+// idle TTL are evicted; since a bucket refills completely while idle, eviction
+// never lets a client through sooner than keeping the bucket would.
+type Limiter struct {
+	limit   Limit
+	idleTTL time.Duration
+	seed    maphash.Seed
+	shards  [shardCount]shard
+	now     func() time.Time // replaced in tests
+
+	allowed atomic.Uint64
+	limited atomic.Uint64
+}
+
+// Stats is a snapshot of a limiter.
+type Stats struct {
+	Rate    float64 `json:"rate"`
+	Burst   int     `json:"burst"`
+	Clients int     `json:"clients"`
+	Allowed uint64  `json:"allowed"`
+	Limited uint64  `json:"limited"`
+}
+
+// New returns a limiter allowing each client limit. The idle TTL is raised to the
+// time an empty bucket takes to refill when shorter; zero uses DefaultIdleTTL.
+func New(limit Limit, idleTTL time.Duration) *Limiter {
+	if idleTTL <= 0 {
+		idleTTL = DefaultIdleTTL
+	}
+	if refill := time.Duration(float64(limit.Burst) / limit.Rate * float64(time.Second)); idleTTL < refill {
+		idleTTL = refill
+	}
+	l := &Limiter{limit: limit, idleTTL: idleTTL, seed: maphash.MakeSeed(), now: time.Now}
+	for i := range l.shards {
+		l.shards[i].buckets = make(map[string]*bucket)
+	}
+	return l
+}
+
+// Allow takes a token from the bucket of key. When the bucket is empty it reports
+// false and how long until the next token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	s := &l.shards[maphash.String(l.seed, key)%shardCount]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(s.lastSweep) >= l.idleTTL {
+		s.sweep(now, l.idleTTL)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit.Burst), last: now}
+		s.buckets[key] = b
+	} else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(l.limit.Burst), b.tokens+elapsed*l.limit.Rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		l.allowed.Add(1)
+		return true, 0
+	}
+	l.limited.Add(1)
+	wait := time.Duration((1 - b.tokens) / l.limit.Rate * float64(time.Second))
+	return false, wait
+}
+
+// Peek reports whether the bucket of key holds a token, and when it does not how
+// long until it will, without taking one. Keys without a bucket are not tracked.
+func (l *Limiter) Peek(key string) (bool, time.Duration) {
+	s := &l.shards[maphash.String(l.seed, key)%shardCount]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		return true, 0
+	}
+	tokens := b.tokens
+	if elapsed := l.now().Sub(b.last).Seconds(); elapsed > 0 {
+		tokens = math.Min(float64(l.limit.Burst), tokens+elapsed*l.limit.Rate)
+	}
+	if tokens >= 1 {
+		return true, 0
+	}
+	return false, time.Duration((1 - tokens) / l.limit.Rate * float64(time.Second))
+}
+
+// sweep evicts the buckets idle for longer than ttl. The caller holds s.mu.
+func (s *shard) sweep(now time.Time, ttl time.Duration) {
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > ttl {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// Stats returns the limit, the number of tracked clients and the decisions so far.
+func (l *Limiter) Stats() Stats {
+	clients := 0
+	for i := range l.shards {
+		s := &l.shards[i]
+		s.mu.Lock()
+		clients += len(s.buckets)
+		s.mu.Unlock()
+	}
+	return Stats{
+		Rate:    l.limit.Rate,
+		Burst:   l.limit.Burst,
+		Clients: clients,
+		Allowed: l.allowed.Load(),
+		Limited: l.limited.Load(),
+	}
+}
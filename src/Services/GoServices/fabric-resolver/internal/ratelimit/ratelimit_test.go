@@ -0,0 +1,161 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable clock for a limiter.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func newTestLimiter(limit Limit, idleTTL time.Duration) (*Limiter, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	l := New(limit, idleTTL)
+	l.now = clock.now
+	return l, clock
+}
+
+func TestAllow_BurstThenRecovery(t *testing.T) {
+	l, clock := newTestLimiter(Limit{Rate: 2, Burst: 3}, 0)
+
+	for i := range 3 {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("request %d within the burst was limited", i+1)
+		}
+	}
+	ok, wait := l.Allow("a")
+	if ok {
+		t.Fatal("expected the request after the burst to be limited")
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("expected to wait 500ms for the next token at 2/s, got %s", wait)
+	}
+
+	// Other clients have their own bucket
+	if ok, _ := l.Allow("b"); !ok {
+		t.Error("expected another client to be unaffected")
+	}
+
+	clock.advance(wait)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Error("expected a token after waiting")
+	}
+	if ok, _ := l.Allow("a"); ok {
+		t.Error("expected only one token to have refilled")
+	}
+
+	// Refill is capped at the burst
+	clock.advance(10 * time.Second)
+	allowed := 0
+	for range 10 {
+		if ok, _ := l.Allow("a"); ok {
+			allowed++
+		}
+	}
+	if allowed != 3 {
+		t.Errorf("expected a full bucket to allow the burst of 3, got %d", allowed)
+	}
+
+	stats := l.Stats()
+	if stats.Allowed != 3+1+1+3 || stats.Limited != 1+1+7 || stats.Clients != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestPeek_TakesNoToken(t *testing.T) {
+	l, clock := newTestLimiter(Limit{Rate: 2, Burst: 1}, 0)
+
+	if ok, _ := l.Peek("a"); !ok {
+		t.Fatal("expected an unknown client to have a token")
+	}
+	if n := l.Stats().Clients; n != 0 {
+		t.Errorf("expected Peek not to track clients, got %d", n)
+	}
+
+	l.Allow("a")
+	for range 3 {
+		ok, wait := l.Peek("a")
+		if ok || wait != 500*time.Millisecond {
+			t.Fatalf("expected an empty bucket refilling in 500ms, got %v %s", ok, wait)
+		}
+	}
+	clock.advance(500 * time.Millisecond)
+	if ok, _ := l.Peek("a"); !ok {
+		t.Error("expected the refilled token to be seen")
+	}
+	if ok, _ := l.Allow("a"); !ok {
+		t.Error("expected Peek to have left the token in place")
+	}
+	if stats := l.Stats(); stats.Allowed != 2 || stats.Limited != 0 {
+		t.Errorf("expected only Allow to be counted, got %+v", stats)
+	}
+}
+
+func TestAllow_EvictsIdleClients(t *testing.T) {
+	l, clock := newTestLimiter(Limit{Rate: 10, Burst: 10}, time.Minute)
+	for i := range 100 {
+		l.Allow(fmt.Sprintf("idle-%d", i))
+	}
+	if n := l.Stats().Clients; n != 100 {
+		t.Fatalf("expected 100 tracked clients, got %d", n)
+	}
+
+	// Shards sweep on their next request after the TTL; enough new clients reach every shard
+	clock.advance(2 * time.Minute)
+	for i := range 1000 {
+		l.Allow(fmt.Sprintf("active-%d", i))
+	}
+	if n := l.Stats().Clients; n != 1000 {
+		t.Errorf("expected only the 1000 active clients to be tracked, got %d", n)
+	}
+}
+
+func TestNew_IdleTTLCoversRefill(t *testing.T) {
+	// Evicting before the bucket refills would hand a fresh burst to a client that spent its own
+	l := New(Limit{Rate: 1, Burst: 600}, time.Second)
+	if l.idleTTL != 600*time.Second {
+		t.Errorf("expected the idle TTL to be raised to the refill time, got %s", l.idleTTL)
+	}
+}
+
+func TestAllow_Concurrent(t *testing.T) {
+	l, _ := newTestLimiter(Limit{Rate: 1, Burst: 50}, 0)
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for g := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				if ok, _ := l.Allow("shared"); ok {
+					allowed.Add(1)
+				}
+				l.Allow(fmt.Sprintf("own-%d", g))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := allowed.Load(); n != 50 {
+		t.Errorf("expected exactly the burst of 50 to be allowed with a frozen clock, got %d", n)
+	}
+}
@@ -0,0 +1,61 @@
+// Package requestid correlates a request across the resolver, its callers and the
+// services it calls through the X-Request-ID header. The id of an incoming request
+// is kept in its context and set on the outbound requests made on its behalf.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// Header carries the request id.
+const Header = "X-Request-ID"
+
+// maxLen bounds ids accepted from clients.
+const maxLen = 128
+
+type contextKey struct{}
+
+// New returns a random UUIDv4.
+func New() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Valid reports whether id, as sent by a client, is safe to adopt: 1 to 128
+// visible ASCII characters other than quotes and backslashes, so it can be
+// logged and echoed verbatim.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if c := id[i]; c <= ' ' || c > '~' || c == '"' || c == '\\' {
+			return false
+		}
+	}
+	return true
+}
+
+// WithID returns ctx carrying id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request id in ctx, or "" when there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Forward sets the request id of req's context on req, for outbound calls.
+func Forward(req *http.Request) {
+	if id := FromContext(req.Context()); id != "" {
+		req.Header.Set(Header, id)
+	}
+}
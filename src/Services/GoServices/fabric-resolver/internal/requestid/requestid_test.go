@@ -0,0 +1,56 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNew_IsUUIDv4(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := make(map[string]bool)
+	for range 100 {
+		id := New()
+		if !uuid.MatchString(id) {
+			t.Fatalf("%q is not a UUIDv4", id)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestValid(t *testing.T) {
+	for id, want := range map[string]bool{
+		"gw-7f3c2a":                            true,
+		"0HMVD9K3Q2B1N:00000001":               true,
+		"3fa85f64-5717-4562-b3fc-2c963f66afa6": true,
+		"":                                     false,
+		"has space":                            false,
+		"quote\"d":                             false,
+		"line\nbreak":                          false,
+		"ünicode":                              false,
+		strings.Repeat("a", 129):               false,
+	} {
+		if got := Valid(id); got != want {
+			t.Errorf("Valid(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestForward(t *testing.T) {
+	req, _ := http.NewRequestWithContext(WithID(context.Background(), "req-1"), "GET", "http://example.com", nil)
+	Forward(req)
+	if got := req.Header.Get(Header); got != "req-1" {
+		t.Errorf("expected the id to be forwarded, got %q", got)
+	}
+
+	req, _ = http.NewRequest("GET", "http://example.com", nil)
+	Forward(req)
+	if _, ok := req.Header[Header]; ok {
+		t.Error("expected no header without an id")
+	}
+}
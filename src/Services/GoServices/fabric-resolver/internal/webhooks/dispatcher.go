@@ -0,0 +1,309 @@
+// Package webhooks delivers ledger events to registered webhooks.
+//
+// anchor.created events come from the ledger's anchor subscription, so anchors
+// created through any API (or, on Fabric, by other clients) are reported.
+// Other events are published by the handlers that cause them. Webhooks are
+// registered once for the whole resolver: with tenants they receive the events
+// of every tenant, the anchor.created events of a tenant naming it.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/requestid"
+	"fabric-resolver/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of the body,
+// keyed with the webhook secret.
+const SignatureHeader = "X-Signature"
+
+const (
+	defaultMaxAttempts = 5
+	defaultBackoff     = time.Second
+	defaultQueueSize   = 256
+	defaultTimeout     = 10 * time.Second
+)
+
+// Event is the JSON body of a delivery.
+type Event struct {
+	ID      string      `json:"id"`
+	Type    string      `json:"type"`
+	Created time.Time   `json:"created"`
+	Data    interface{} `json:"data"`
+	// Tenant is the tenant on whose ledger the event happened; empty for the default ledger
+	Tenant string `json:"tenant,omitempty"`
+
+	// RequestID is the X-Request-ID of the API request that caused the event,
+	// forwarded on deliveries. Events from the ledger subscription have none.
+	RequestID string `json:"-"`
+
+	// spanContext is the span that published the event; deliveries are traced under it.
+	spanContext trace.SpanContext
+}
+
+// AnchorData is the data of anchor.created and anchor.revoked events.
+type AnchorData struct {
+	Hash             string     `json:"hash"`
+	Algorithm        string     `json:"algorithm,omitempty"`
+	IssuerDID        string     `json:"issuerDid,omitempty"`
+	BlockNumber      uint64     `json:"blockNumber"`
+	TxID             string     `json:"txId"`
+	Timestamp        time.Time  `json:"timestamp"`
+	RevokedAt        *time.Time `json:"revokedAt,omitempty"`
+	RevocationReason string     `json:"revocationReason,omitempty"`
+}
+
+// DIDData is the data of did.created events.
+type DIDData struct {
+	DID     string    `json:"did"`
+	Created time.Time `json:"created"`
+}
+
+func anchorData(anchor *domain.Anchor) AnchorData {
+	return AnchorData{
+		Hash:             anchor.Hash,
+		Algorithm:        anchor.Algorithm,
+		IssuerDID:        anchor.IssuerDID,
+		BlockNumber:      anchor.BlockNumber,
+		TxID:             anchor.TxID,
+		Timestamp:        anchor.Timestamp,
+		RevokedAt:        anchor.RevokedAt,
+		RevocationReason: anchor.RevocationReason,
+	}
+}
+
+// Options tunes delivery. Zero values use the defaults.
+type Options struct {
+	Client      *http.Client  // defaults to a client with a 10s timeout
+	MaxAttempts int           // deliveries per event and webhook, including the first (default 5)
+	Backoff     time.Duration // wait before the first retry, doubled after each one (default 1s)
+	QueueSize   int           // events buffered for delivery before new ones are dropped (default 256)
+}
+
+// Dispatcher delivers events to the webhooks registered on the ledger.
+// A nil *Dispatcher accepts and discards events, so callers need no checks.
+type Dispatcher struct {
+	ledgerClient fabric.LedgerClient
+	client       *http.Client
+	maxAttempts  int
+	backoff      time.Duration
+	queue        chan Event
+	deliveries   sync.WaitGroup
+}
+
+func NewDispatcher(ledgerClient fabric.LedgerClient, opts Options) *Dispatcher {
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: defaultTimeout}
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = defaultBackoff
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	return &Dispatcher{
+		ledgerClient: ledgerClient,
+		client:       opts.Client,
+		maxAttempts:  opts.MaxAttempts,
+		backoff:      opts.Backoff,
+		queue:        make(chan Event, opts.QueueSize),
+	}
+}
+
+// AnchorRevoked queues an anchor.revoked event. It never blocks.
+// ctx is that of the request that revoked the anchor, on the ledger of tenant.
+func (d *Dispatcher) AnchorRevoked(ctx context.Context, tenant string, anchor *domain.Anchor) {
+	d.publish(ctx, tenant, domain.EventAnchorRevoked, anchorData(anchor))
+}
+
+// DIDCreated queues a did.created event. It never blocks.
+// ctx is that of the request that created the DID, on the ledger of tenant.
+func (d *Dispatcher) DIDCreated(ctx context.Context, tenant string, didDoc *domain.DIDDocument) {
+	d.publish(ctx, tenant, domain.EventDIDCreated, DIDData{DID: didDoc.ID, Created: didDoc.Created})
+}
+
+func (d *Dispatcher) publish(ctx context.Context, tenant, eventType string, data interface{}) {
+	if d == nil {
+		return
+	}
+	event := newEvent(eventType, data)
+	event.Tenant = tenant
+	event.RequestID = requestid.FromContext(ctx)
+	event.spanContext = trace.SpanContextFromContext(ctx)
+	d.enqueue(event)
+}
+
+// enqueue queues event for delivery without blocking, dropping it when the queue is full.
+func (d *Dispatcher) enqueue(event Event) {
+	select {
+	case d.queue <- event:
+	default:
+		slog.Warn("Webhook queue full; dropping event", "event_type", event.Type, "tenant", event.Tenant)
+	}
+}
+
+// Watch reports the anchors created on ledger, the ledger of tenant, as
+// anchor.created events naming the tenant, until ctx is done or the ledger is
+// closed. Run watches the dispatcher's own ledger; Watch adds those of tenants.
+func (d *Dispatcher) Watch(ctx context.Context, ledger fabric.LedgerClient, tenant string) error {
+	if d == nil {
+		return nil
+	}
+	anchors, err := ledger.SubscribeAnchors(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to the anchors of tenant %s: %w", tenant, err)
+	}
+	go func() {
+		for anchor := range anchors {
+			event := newEvent(domain.EventAnchorCreated, anchorData(&anchor))
+			event.Tenant = tenant
+			d.enqueue(event)
+		}
+	}()
+	return nil
+}
+
+func newEvent(eventType string, data interface{}) Event {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return Event{ID: hex.EncodeToString(id), Type: eventType, Created: time.Now().UTC(), Data: data}
+}
+
+// Run delivers events until ctx is done, then waits for deliveries in progress.
+// Deliveries still waiting to retry are abandoned.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	anchors, err := d.ledgerClient.SubscribeAnchors(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to anchors: %w", err)
+	}
+	defer d.deliveries.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case anchor, ok := <-anchors:
+			if !ok {
+				// The ledger was closed
+				return nil
+			}
+			d.dispatch(ctx, newEvent(domain.EventAnchorCreated, anchorData(&anchor)))
+		case event := <-d.queue:
+			d.dispatch(ctx, event)
+		}
+	}
+}
+
+// dispatch starts a delivery to every webhook subscribed to the event.
+func (d *Dispatcher) dispatch(ctx context.Context, event Event) {
+	hooks, err := d.ledgerClient.ListWebhooks(ctx)
+	if err != nil {
+		slog.Error("Failed to load webhooks", "event_type", event.Type, "err", err)
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("Failed to encode event", "event_type", event.Type, "err", err)
+		return
+	}
+
+	for _, hook := range hooks {
+		if !hook.Wants(event.Type) {
+			continue
+		}
+		d.deliveries.Add(1)
+		go func(hook domain.Webhook) {
+			defer d.deliveries.Done()
+			d.deliver(ctx, hook, event, body)
+		}(hook)
+	}
+}
+
+// deliver posts body to the webhook, retrying with exponential backoff on
+// network errors and 5xx responses. Other responses end the delivery.
+func (d *Dispatcher) deliver(ctx context.Context, hook domain.Webhook, event Event, body []byte) {
+	wait := d.backoff
+	for attempt := 1; ; attempt++ {
+		status, err := d.post(ctx, hook, event, body)
+		switch {
+		case err == nil && status < 300:
+			return
+		case err == nil && status < 500:
+			slog.Warn("Webhook rejected event", "webhook", hook.ID, "event_type", event.Type, "event_id", event.ID, "status", status)
+			return
+		}
+
+		if err == nil {
+			err = fmt.Errorf("status %d", status)
+		}
+		if attempt >= d.maxAttempts {
+			slog.Error("Giving up on webhook delivery", "webhook", hook.ID, "event_type", event.Type, "event_id", event.ID, "attempts", attempt, "err", err)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, hook domain.Webhook, event Event, body []byte) (int, error) {
+	ctx, span := tracing.Tracer().Start(trace.ContextWithSpanContext(ctx, event.spanContext), "webhook.deliver",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("webhook.id", hook.ID), attribute.String("webhook.event", event.Type)))
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", event.Type)
+	req.Header.Set("X-Webhook-Delivery", event.ID)
+	if event.RequestID != "" {
+		req.Header.Set(requestid.Header, event.RequestID)
+	}
+	tracing.Inject(req)
+	if hook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// Sign returns the X-Signature value of body for secret. Receivers should compare
+// it with hmac.Equal against the header they got.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
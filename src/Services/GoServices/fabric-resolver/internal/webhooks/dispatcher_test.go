@@ -0,0 +1,264 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fabric-resolver/internal/domain"
+	"fabric-resolver/internal/infrastructure/fabric"
+	"fabric-resolver/internal/requestid"
+)
+
+func init() {
+	log.SetOutput(io.Discard)
+}
+
+// receiver records the deliveries it accepts.
+type receiver struct {
+	mu     sync.Mutex
+	events []Event
+	got    chan Event
+}
+
+func newReceiver() *receiver {
+	return &receiver{got: make(chan Event, 16)}
+}
+
+func (rc *receiver) accept(body []byte) {
+	var event Event
+	json.Unmarshal(body, &event)
+	rc.mu.Lock()
+	rc.events = append(rc.events, event)
+	rc.mu.Unlock()
+	rc.got <- event
+}
+
+func (rc *receiver) wait(t *testing.T) Event {
+	t.Helper()
+	select {
+	case event := <-rc.got:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a delivery")
+		return Event{}
+	}
+}
+
+// startDispatcher runs a dispatcher over a fresh file ledger with the given webhooks.
+func startDispatcher(t *testing.T, opts Options, hooks ...domain.Webhook) (*Dispatcher, fabric.LedgerClient) {
+	t.Helper()
+	ledger, err := fabric.NewFileLedgerClient(filepath.Join(t.TempDir(), "ledger.json"))
+	if err != nil {
+		t.Fatalf("failed to create ledger: %v", err)
+	}
+	for i := range hooks {
+		if err := ledger.SaveWebhook(context.Background(), &hooks[i]); err != nil {
+			t.Fatalf("SaveWebhook failed: %v", err)
+		}
+	}
+
+	d := NewDispatcher(ledger, opts)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		ledger.Close()
+	})
+
+	// Run subscribes asynchronously; wait until anchors reach it
+	time.Sleep(10 * time.Millisecond)
+	return d, ledger
+}
+
+func TestDelivery_SignsBody(t *testing.T) {
+	rc := newReceiver()
+	var signatureOK atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signatureOK.Store(hmac.Equal([]byte(r.Header.Get(SignatureHeader)), []byte(Sign("s3cret", body))) &&
+			r.Header.Get("X-Webhook-Event") == domain.EventAnchorCreated)
+		rc.accept(body)
+	}))
+	defer srv.Close()
+
+	_, ledger := startDispatcher(t, Options{}, domain.Webhook{ID: "wh_1", URL: srv.URL, Secret: "s3cret"})
+	if _, _, err := ledger.CreateAnchor(context.Background(), &domain.Anchor{Hash: "abc123", IssuerDID: "did:ewallet:issuer"}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+
+	event := rc.wait(t)
+	if !signatureOK.Load() {
+		t.Error("X-Signature does not match the HMAC of the body")
+	}
+	data, _ := event.Data.(map[string]interface{})
+	if event.Type != domain.EventAnchorCreated || event.ID == "" || data["hash"] != "abc123" || data["txId"] == "" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+}
+
+func TestWatch_ReportsTenantAnchors(t *testing.T) {
+	rc := newReceiver()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rc.accept(body)
+	}))
+	defer srv.Close()
+
+	d, _ := startDispatcher(t, Options{}, domain.Webhook{ID: "wh_1", URL: srv.URL})
+	tenantLedger, err := fabric.NewFileLedgerClient(filepath.Join(t.TempDir(), "acme.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tenantLedger.Close()
+	if err := d.Watch(t.Context(), tenantLedger, "acme"); err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	if _, _, err := tenantLedger.CreateAnchor(context.Background(), &domain.Anchor{Hash: "abc123"}); err != nil {
+		t.Fatalf("CreateAnchor failed: %v", err)
+	}
+	event := rc.wait(t)
+	if data, _ := event.Data.(map[string]interface{}); event.Type != domain.EventAnchorCreated || event.Tenant != "acme" || data["hash"] != "abc123" {
+		t.Errorf("expected the anchor of acme to be reported to the shared webhooks, got %+v", event)
+	}
+}
+
+func TestDelivery_RetriesServerErrors(t *testing.T) {
+	rc := newReceiver()
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		rc.accept(body)
+	}))
+	defer srv.Close()
+
+	d, _ := startDispatcher(t, Options{Backoff: time.Millisecond}, domain.Webhook{ID: "wh_1", URL: srv.URL})
+	d.DIDCreated(context.Background(), "", &domain.DIDDocument{ID: "did:ewallet:holder"})
+
+	if event := rc.wait(t); event.Type != domain.EventDIDCreated {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if n := attempts.Load(); n != 3 {
+		t.Errorf("expected 3 attempts, got %d", n)
+	}
+}
+
+func TestDelivery_ForwardsRequestID(t *testing.T) {
+	rc := newReceiver()
+	requestIDs := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestIDs <- r.Header.Get(requestid.Header)
+		body, _ := io.ReadAll(r.Body)
+		rc.accept(body)
+	}))
+	defer srv.Close()
+
+	d, _ := startDispatcher(t, Options{}, domain.Webhook{ID: "wh_1", URL: srv.URL})
+	d.DIDCreated(requestid.WithID(context.Background(), "req-42"), "", &domain.DIDDocument{ID: "did:ewallet:holder"})
+
+	rc.wait(t)
+	if got := <-requestIDs; got != "req-42" {
+		t.Errorf("expected the request id to be forwarded, got %q", got)
+	}
+}
+
+func TestDelivery_GivesUp(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	rejected := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(100)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejected.Close()
+
+	d, _ := startDispatcher(t, Options{Backoff: time.Millisecond, MaxAttempts: 4},
+		domain.Webhook{ID: "wh_1", URL: srv.URL}, domain.Webhook{ID: "wh_2", URL: rejected.URL})
+	d.DIDCreated(context.Background(), "", &domain.DIDDocument{ID: "did:ewallet:holder"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for attempts.Load() < 104 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	// 4 attempts at the failing receiver, 1 at the one answering 4xx
+	if n := attempts.Load(); n != 104 {
+		t.Errorf("expected 104, got %d", n)
+	}
+}
+
+func TestDelivery_MatchesFilters(t *testing.T) {
+	anchors, dids := newReceiver(), newReceiver()
+	serve := func(rc *receiver) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			rc.accept(body)
+		}))
+	}
+	anchorSrv, didSrv := serve(anchors), serve(dids)
+	defer anchorSrv.Close()
+	defer didSrv.Close()
+
+	d, ledger := startDispatcher(t, Options{},
+		domain.Webhook{ID: "wh_anchors", URL: anchorSrv.URL, Events: []string{domain.EventAnchorCreated, domain.EventAnchorRevoked}},
+		domain.Webhook{ID: "wh_dids", URL: didSrv.URL, Events: []string{domain.EventDIDCreated}},
+	)
+
+	d.DIDCreated(context.Background(), "", &domain.DIDDocument{ID: "did:ewallet:holder"})
+	ledger.CreateAnchor(context.Background(), &domain.Anchor{Hash: "abc123"})
+	d.AnchorRevoked(context.Background(), "", &domain.Anchor{Hash: "abc123", RevocationReason: "superseded"})
+
+	if event := dids.wait(t); event.Type != domain.EventDIDCreated {
+		t.Errorf("DID webhook got %s", event.Type)
+	}
+	got := map[string]bool{anchors.wait(t).Type: true, anchors.wait(t).Type: true}
+	if !got[domain.EventAnchorCreated] || !got[domain.EventAnchorRevoked] {
+		t.Errorf("anchor webhook got %v", got)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if len(anchors.got) != 0 || len(dids.got) != 0 {
+		t.Error("a webhook received an event it did not subscribe to")
+	}
+}
+
+func TestPublish_NeverBlocks(t *testing.T) {
+	d := NewDispatcher(nil, Options{QueueSize: 1})
+	done := make(chan struct{})
+	go func() {
+		// Nothing drains the queue; the second event is dropped
+		d.DIDCreated(context.Background(), "", &domain.DIDDocument{ID: "did:ewallet:a"})
+		d.DIDCreated(context.Background(), "", &domain.DIDDocument{ID: "did:ewallet:b"})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publishing blocked on a full queue")
+	}
+
+	var nilDispatcher *Dispatcher
+	nilDispatcher.AnchorRevoked(context.Background(), "", &domain.Anchor{Hash: "abc123"})
+}
@@ -0,0 +1,1438 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: proto/resolver/v1/resolver.proto
+
+// Package resolver.v1 is the gRPC API of fabric-resolver. It mirrors the HTTP
+// endpoints of the same name; see internal/api/handlers for their semantics.
+
+package resolverv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateAnchorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`           // lowercase hex digest
+	Algorithm     string                 `protobuf:"bytes,2,opt,name=algorithm,proto3" json:"algorithm,omitempty"` // sha256 (default), sha512 or blake2b-256
+	IssuerDid     string                 `protobuf:"bytes,3,opt,name=issuer_did,json=issuerDid,proto3" json:"issuer_did,omitempty"`
+	MetadataJson  string                 `protobuf:"bytes,4,opt,name=metadata_json,json=metadataJson,proto3" json:"metadata_json,omitempty"` // any JSON value, stored canonicalized
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAnchorRequest) Reset() {
+	*x = CreateAnchorRequest{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAnchorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAnchorRequest) ProtoMessage() {}
+
+func (x *CreateAnchorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAnchorRequest.ProtoReflect.Descriptor instead.
+func (*CreateAnchorRequest) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateAnchorRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *CreateAnchorRequest) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *CreateAnchorRequest) GetIssuerDid() string {
+	if x != nil {
+		return x.IssuerDid
+	}
+	return ""
+}
+
+func (x *CreateAnchorRequest) GetMetadataJson() string {
+	if x != nil {
+		return x.MetadataJson
+	}
+	return ""
+}
+
+func (x *CreateAnchorRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type Anchor struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Hash              string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Algorithm         string                 `protobuf:"bytes,2,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	IssuerDid         string                 `protobuf:"bytes,3,opt,name=issuer_did,json=issuerDid,proto3" json:"issuer_did,omitempty"`
+	Timestamp         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	BlockNumber       uint64                 `protobuf:"varint,5,opt,name=block_number,json=blockNumber,proto3" json:"block_number,omitempty"`
+	TxId              string                 `protobuf:"bytes,6,opt,name=tx_id,json=txId,proto3" json:"tx_id,omitempty"`
+	MetadataJson      string                 `protobuf:"bytes,7,opt,name=metadata_json,json=metadataJson,proto3" json:"metadata_json,omitempty"`
+	MetadataHash      string                 `protobuf:"bytes,8,opt,name=metadata_hash,json=metadataHash,proto3" json:"metadata_hash,omitempty"`
+	SignatureVerified bool                   `protobuf:"varint,9,opt,name=signature_verified,json=signatureVerified,proto3" json:"signature_verified,omitempty"`
+	ExpiresAt         *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Revoked           bool                   `protobuf:"varint,11,opt,name=revoked,proto3" json:"revoked,omitempty"`
+	RevokedAt         *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"`
+	RevocationReason  string                 `protobuf:"bytes,13,opt,name=revocation_reason,json=revocationReason,proto3" json:"revocation_reason,omitempty"`
+	Tombstoned        bool                   `protobuf:"varint,14,opt,name=tombstoned,proto3" json:"tombstoned,omitempty"`
+	TombstonedAt      *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=tombstoned_at,json=tombstonedAt,proto3" json:"tombstoned_at,omitempty"`
+	TombstoneReason   string                 `protobuf:"bytes,16,opt,name=tombstone_reason,json=tombstoneReason,proto3" json:"tombstone_reason,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Anchor) Reset() {
+	*x = Anchor{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Anchor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Anchor) ProtoMessage() {}
+
+func (x *Anchor) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Anchor.ProtoReflect.Descriptor instead.
+func (*Anchor) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Anchor) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Anchor) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *Anchor) GetIssuerDid() string {
+	if x != nil {
+		return x.IssuerDid
+	}
+	return ""
+}
+
+func (x *Anchor) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Anchor) GetBlockNumber() uint64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+func (x *Anchor) GetTxId() string {
+	if x != nil {
+		return x.TxId
+	}
+	return ""
+}
+
+func (x *Anchor) GetMetadataJson() string {
+	if x != nil {
+		return x.MetadataJson
+	}
+	return ""
+}
+
+func (x *Anchor) GetMetadataHash() string {
+	if x != nil {
+		return x.MetadataHash
+	}
+	return ""
+}
+
+func (x *Anchor) GetSignatureVerified() bool {
+	if x != nil {
+		return x.SignatureVerified
+	}
+	return false
+}
+
+func (x *Anchor) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Anchor) GetRevoked() bool {
+	if x != nil {
+		return x.Revoked
+	}
+	return false
+}
+
+func (x *Anchor) GetRevokedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RevokedAt
+	}
+	return nil
+}
+
+func (x *Anchor) GetRevocationReason() string {
+	if x != nil {
+		return x.RevocationReason
+	}
+	return ""
+}
+
+func (x *Anchor) GetTombstoned() bool {
+	if x != nil {
+		return x.Tombstoned
+	}
+	return false
+}
+
+func (x *Anchor) GetTombstonedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TombstonedAt
+	}
+	return nil
+}
+
+func (x *Anchor) GetTombstoneReason() string {
+	if x != nil {
+		return x.TombstoneReason
+	}
+	return ""
+}
+
+type GetAnchorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAnchorRequest) Reset() {
+	*x = GetAnchorRequest{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAnchorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAnchorRequest) ProtoMessage() {}
+
+func (x *GetAnchorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAnchorRequest.ProtoReflect.Descriptor instead.
+func (*GetAnchorRequest) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetAnchorRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+type VerifyAnchorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hash          string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyAnchorRequest) Reset() {
+	*x = VerifyAnchorRequest{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyAnchorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyAnchorRequest) ProtoMessage() {}
+
+func (x *VerifyAnchorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyAnchorRequest.ProtoReflect.Descriptor instead.
+func (*VerifyAnchorRequest) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *VerifyAnchorRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+type VerifyAnchorResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Hash              string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Exists            bool                   `protobuf:"varint,2,opt,name=exists,proto3" json:"exists,omitempty"`
+	Valid             bool                   `protobuf:"varint,3,opt,name=valid,proto3" json:"valid,omitempty"` // exists and not revoked
+	Revoked           bool                   `protobuf:"varint,4,opt,name=revoked,proto3" json:"revoked,omitempty"`
+	SignatureVerified bool                   `protobuf:"varint,5,opt,name=signature_verified,json=signatureVerified,proto3" json:"signature_verified,omitempty"`
+	Timestamp         *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *VerifyAnchorResponse) Reset() {
+	*x = VerifyAnchorResponse{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyAnchorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyAnchorResponse) ProtoMessage() {}
+
+func (x *VerifyAnchorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyAnchorResponse.ProtoReflect.Descriptor instead.
+func (*VerifyAnchorResponse) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *VerifyAnchorResponse) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *VerifyAnchorResponse) GetExists() bool {
+	if x != nil {
+		return x.Exists
+	}
+	return false
+}
+
+func (x *VerifyAnchorResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *VerifyAnchorResponse) GetRevoked() bool {
+	if x != nil {
+		return x.Revoked
+	}
+	return false
+}
+
+func (x *VerifyAnchorResponse) GetSignatureVerified() bool {
+	if x != nil {
+		return x.SignatureVerified
+	}
+	return false
+}
+
+func (x *VerifyAnchorResponse) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+type Jwk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kty           string                 `protobuf:"bytes,1,opt,name=kty,proto3" json:"kty,omitempty"`
+	Crv           string                 `protobuf:"bytes,2,opt,name=crv,proto3" json:"crv,omitempty"`
+	X             string                 `protobuf:"bytes,3,opt,name=x,proto3" json:"x,omitempty"`
+	Y             string                 `protobuf:"bytes,4,opt,name=y,proto3" json:"y,omitempty"`
+	Kid           string                 `protobuf:"bytes,5,opt,name=kid,proto3" json:"kid,omitempty"`
+	Alg           string                 `protobuf:"bytes,6,opt,name=alg,proto3" json:"alg,omitempty"`
+	Use           string                 `protobuf:"bytes,7,opt,name=use,proto3" json:"use,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Jwk) Reset() {
+	*x = Jwk{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Jwk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Jwk) ProtoMessage() {}
+
+func (x *Jwk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Jwk.ProtoReflect.Descriptor instead.
+func (*Jwk) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Jwk) GetKty() string {
+	if x != nil {
+		return x.Kty
+	}
+	return ""
+}
+
+func (x *Jwk) GetCrv() string {
+	if x != nil {
+		return x.Crv
+	}
+	return ""
+}
+
+func (x *Jwk) GetX() string {
+	if x != nil {
+		return x.X
+	}
+	return ""
+}
+
+func (x *Jwk) GetY() string {
+	if x != nil {
+		return x.Y
+	}
+	return ""
+}
+
+func (x *Jwk) GetKid() string {
+	if x != nil {
+		return x.Kid
+	}
+	return ""
+}
+
+func (x *Jwk) GetAlg() string {
+	if x != nil {
+		return x.Alg
+	}
+	return ""
+}
+
+func (x *Jwk) GetUse() string {
+	if x != nil {
+		return x.Use
+	}
+	return ""
+}
+
+type VerificationMethodInput struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Type               string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	PublicKeyJwk       *Jwk                   `protobuf:"bytes,2,opt,name=public_key_jwk,json=publicKeyJwk,proto3" json:"public_key_jwk,omitempty"`
+	PublicKeyBase58    string                 `protobuf:"bytes,3,opt,name=public_key_base58,json=publicKeyBase58,proto3" json:"public_key_base58,omitempty"`
+	PublicKeyMultibase string                 `protobuf:"bytes,4,opt,name=public_key_multibase,json=publicKeyMultibase,proto3" json:"public_key_multibase,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *VerificationMethodInput) Reset() {
+	*x = VerificationMethodInput{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerificationMethodInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerificationMethodInput) ProtoMessage() {}
+
+func (x *VerificationMethodInput) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerificationMethodInput.ProtoReflect.Descriptor instead.
+func (*VerificationMethodInput) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *VerificationMethodInput) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *VerificationMethodInput) GetPublicKeyJwk() *Jwk {
+	if x != nil {
+		return x.PublicKeyJwk
+	}
+	return nil
+}
+
+func (x *VerificationMethodInput) GetPublicKeyBase58() string {
+	if x != nil {
+		return x.PublicKeyBase58
+	}
+	return ""
+}
+
+func (x *VerificationMethodInput) GetPublicKeyMultibase() string {
+	if x != nil {
+		return x.PublicKeyMultibase
+	}
+	return ""
+}
+
+type ServiceInput struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // fragment after "#"; defaults to service-N
+	Type            string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	ServiceEndpoint string                 `protobuf:"bytes,3,opt,name=service_endpoint,json=serviceEndpoint,proto3" json:"service_endpoint,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ServiceInput) Reset() {
+	*x = ServiceInput{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceInput) ProtoMessage() {}
+
+func (x *ServiceInput) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceInput.ProtoReflect.Descriptor instead.
+func (*ServiceInput) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ServiceInput) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ServiceInput) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ServiceInput) GetServiceEndpoint() string {
+	if x != nil {
+		return x.ServiceEndpoint
+	}
+	return ""
+}
+
+type CreateDidRequest struct {
+	state              protoimpl.MessageState     `protogen:"open.v1"`
+	Did                string                     `protobuf:"bytes,1,opt,name=did,proto3" json:"did,omitempty"`
+	Context            []string                   `protobuf:"bytes,2,rep,name=context,proto3" json:"context,omitempty"` // extra JSON-LD contexts
+	Controller         string                     `protobuf:"bytes,3,opt,name=controller,proto3" json:"controller,omitempty"`
+	VerificationMethod []*VerificationMethodInput `protobuf:"bytes,4,rep,name=verification_method,json=verificationMethod,proto3" json:"verification_method,omitempty"`
+	Service            []*ServiceInput            `protobuf:"bytes,5,rep,name=service,proto3" json:"service,omitempty"`
+	// 0-based indices into verification_method; empty uses every key
+	Authentication  []int32 `protobuf:"varint,6,rep,packed,name=authentication,proto3" json:"authentication,omitempty"`
+	AssertionMethod []int32 `protobuf:"varint,7,rep,packed,name=assertion_method,json=assertionMethod,proto3" json:"assertion_method,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateDidRequest) Reset() {
+	*x = CreateDidRequest{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDidRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDidRequest) ProtoMessage() {}
+
+func (x *CreateDidRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDidRequest.ProtoReflect.Descriptor instead.
+func (*CreateDidRequest) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CreateDidRequest) GetDid() string {
+	if x != nil {
+		return x.Did
+	}
+	return ""
+}
+
+func (x *CreateDidRequest) GetContext() []string {
+	if x != nil {
+		return x.Context
+	}
+	return nil
+}
+
+func (x *CreateDidRequest) GetController() string {
+	if x != nil {
+		return x.Controller
+	}
+	return ""
+}
+
+func (x *CreateDidRequest) GetVerificationMethod() []*VerificationMethodInput {
+	if x != nil {
+		return x.VerificationMethod
+	}
+	return nil
+}
+
+func (x *CreateDidRequest) GetService() []*ServiceInput {
+	if x != nil {
+		return x.Service
+	}
+	return nil
+}
+
+func (x *CreateDidRequest) GetAuthentication() []int32 {
+	if x != nil {
+		return x.Authentication
+	}
+	return nil
+}
+
+func (x *CreateDidRequest) GetAssertionMethod() []int32 {
+	if x != nil {
+		return x.AssertionMethod
+	}
+	return nil
+}
+
+type ResolveDidRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Did           string                 `protobuf:"bytes,1,opt,name=did,proto3" json:"did,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveDidRequest) Reset() {
+	*x = ResolveDidRequest{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveDidRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveDidRequest) ProtoMessage() {}
+
+func (x *ResolveDidRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveDidRequest.ProtoReflect.Descriptor instead.
+func (*ResolveDidRequest) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ResolveDidRequest) GetDid() string {
+	if x != nil {
+		return x.Did
+	}
+	return ""
+}
+
+type VerificationMethod struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type               string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Controller         string                 `protobuf:"bytes,3,opt,name=controller,proto3" json:"controller,omitempty"`
+	PublicKeyJwk       *Jwk                   `protobuf:"bytes,4,opt,name=public_key_jwk,json=publicKeyJwk,proto3" json:"public_key_jwk,omitempty"`
+	PublicKeyBase58    string                 `protobuf:"bytes,5,opt,name=public_key_base58,json=publicKeyBase58,proto3" json:"public_key_base58,omitempty"`
+	PublicKeyMultibase string                 `protobuf:"bytes,6,opt,name=public_key_multibase,json=publicKeyMultibase,proto3" json:"public_key_multibase,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *VerificationMethod) Reset() {
+	*x = VerificationMethod{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerificationMethod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerificationMethod) ProtoMessage() {}
+
+func (x *VerificationMethod) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerificationMethod.ProtoReflect.Descriptor instead.
+func (*VerificationMethod) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *VerificationMethod) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *VerificationMethod) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *VerificationMethod) GetController() string {
+	if x != nil {
+		return x.Controller
+	}
+	return ""
+}
+
+func (x *VerificationMethod) GetPublicKeyJwk() *Jwk {
+	if x != nil {
+		return x.PublicKeyJwk
+	}
+	return nil
+}
+
+func (x *VerificationMethod) GetPublicKeyBase58() string {
+	if x != nil {
+		return x.PublicKeyBase58
+	}
+	return ""
+}
+
+func (x *VerificationMethod) GetPublicKeyMultibase() string {
+	if x != nil {
+		return x.PublicKeyMultibase
+	}
+	return ""
+}
+
+type Service struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type            string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	ServiceEndpoint string                 `protobuf:"bytes,3,opt,name=service_endpoint,json=serviceEndpoint,proto3" json:"service_endpoint,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Service) Reset() {
+	*x = Service{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Service) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Service) ProtoMessage() {}
+
+func (x *Service) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Service.ProtoReflect.Descriptor instead.
+func (*Service) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Service) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Service) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Service) GetServiceEndpoint() string {
+	if x != nil {
+		return x.ServiceEndpoint
+	}
+	return ""
+}
+
+type DidDocument struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Context            []string               `protobuf:"bytes,1,rep,name=context,proto3" json:"context,omitempty"`
+	Id                 string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Controller         string                 `protobuf:"bytes,3,opt,name=controller,proto3" json:"controller,omitempty"`
+	VerificationMethod []*VerificationMethod  `protobuf:"bytes,4,rep,name=verification_method,json=verificationMethod,proto3" json:"verification_method,omitempty"`
+	Authentication     []string               `protobuf:"bytes,5,rep,name=authentication,proto3" json:"authentication,omitempty"`
+	AssertionMethod    []string               `protobuf:"bytes,6,rep,name=assertion_method,json=assertionMethod,proto3" json:"assertion_method,omitempty"`
+	Service            []*Service             `protobuf:"bytes,7,rep,name=service,proto3" json:"service,omitempty"`
+	Created            *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created,proto3" json:"created,omitempty"`
+	Updated            *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated,proto3" json:"updated,omitempty"`
+	Deactivated        bool                   `protobuf:"varint,10,opt,name=deactivated,proto3" json:"deactivated,omitempty"`
+	DeactivatedAt      *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=deactivated_at,json=deactivatedAt,proto3" json:"deactivated_at,omitempty"`
+	VersionId          uint64                 `protobuf:"varint,12,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *DidDocument) Reset() {
+	*x = DidDocument{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DidDocument) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DidDocument) ProtoMessage() {}
+
+func (x *DidDocument) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DidDocument.ProtoReflect.Descriptor instead.
+func (*DidDocument) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DidDocument) GetContext() []string {
+	if x != nil {
+		return x.Context
+	}
+	return nil
+}
+
+func (x *DidDocument) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DidDocument) GetController() string {
+	if x != nil {
+		return x.Controller
+	}
+	return ""
+}
+
+func (x *DidDocument) GetVerificationMethod() []*VerificationMethod {
+	if x != nil {
+		return x.VerificationMethod
+	}
+	return nil
+}
+
+func (x *DidDocument) GetAuthentication() []string {
+	if x != nil {
+		return x.Authentication
+	}
+	return nil
+}
+
+func (x *DidDocument) GetAssertionMethod() []string {
+	if x != nil {
+		return x.AssertionMethod
+	}
+	return nil
+}
+
+func (x *DidDocument) GetService() []*Service {
+	if x != nil {
+		return x.Service
+	}
+	return nil
+}
+
+func (x *DidDocument) GetCreated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+func (x *DidDocument) GetUpdated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Updated
+	}
+	return nil
+}
+
+func (x *DidDocument) GetDeactivated() bool {
+	if x != nil {
+		return x.Deactivated
+	}
+	return false
+}
+
+func (x *DidDocument) GetDeactivatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeactivatedAt
+	}
+	return nil
+}
+
+func (x *DidDocument) GetVersionId() uint64 {
+	if x != nil {
+		return x.VersionId
+	}
+	return 0
+}
+
+type StatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsRequest) Reset() {
+	*x = StatsRequest{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsRequest) ProtoMessage() {}
+
+func (x *StatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{13}
+}
+
+type StatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Anchors       int64                  `protobuf:"varint,1,opt,name=anchors,proto3" json:"anchors,omitempty"`
+	Dids          int64                  `protobuf:"varint,2,opt,name=dids,proto3" json:"dids,omitempty"`
+	NextBlock     uint64                 `protobuf:"varint,3,opt,name=next_block,json=nextBlock,proto3" json:"next_block,omitempty"`
+	Mode          string                 `protobuf:"bytes,4,opt,name=mode,proto3" json:"mode,omitempty"`
+	Path          string                 `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`
+	FileSizeBytes int64                  `protobuf:"varint,6,opt,name=file_size_bytes,json=fileSizeBytes,proto3" json:"file_size_bytes,omitempty"`
+	LastWriteTime *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=last_write_time,json=lastWriteTime,proto3" json:"last_write_time,omitempty"`
+	DocTypes      map[string]int64       `protobuf:"bytes,8,rep,name=doc_types,json=docTypes,proto3" json:"doc_types,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Pruned        uint64                 `protobuf:"varint,9,opt,name=pruned,proto3" json:"pruned,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsResponse) Reset() {
+	*x = StatsResponse{}
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsResponse) ProtoMessage() {}
+
+func (x *StatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_resolver_v1_resolver_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
+func (*StatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_resolver_v1_resolver_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StatsResponse) GetAnchors() int64 {
+	if x != nil {
+		return x.Anchors
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetDids() int64 {
+	if x != nil {
+		return x.Dids
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetNextBlock() uint64 {
+	if x != nil {
+		return x.NextBlock
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *StatsResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *StatsResponse) GetFileSizeBytes() int64 {
+	if x != nil {
+		return x.FileSizeBytes
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetLastWriteTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastWriteTime
+	}
+	return nil
+}
+
+func (x *StatsResponse) GetDocTypes() map[string]int64 {
+	if x != nil {
+		return x.DocTypes
+	}
+	return nil
+}
+
+func (x *StatsResponse) GetPruned() uint64 {
+	if x != nil {
+		return x.Pruned
+	}
+	return 0
+}
+
+var File_proto_resolver_v1_resolver_proto protoreflect.FileDescriptor
+
+const file_proto_resolver_v1_resolver_proto_rawDesc = "" +
+	"\n" +
+	" proto/resolver/v1/resolver.proto\x12\vresolver.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc6\x01\n" +
+	"\x13CreateAnchorRequest\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\x12\x1c\n" +
+	"\talgorithm\x18\x02 \x01(\tR\talgorithm\x12\x1d\n" +
+	"\n" +
+	"issuer_did\x18\x03 \x01(\tR\tissuerDid\x12#\n" +
+	"\rmetadata_json\x18\x04 \x01(\tR\fmetadataJson\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\x8d\x05\n" +
+	"\x06Anchor\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\x12\x1c\n" +
+	"\talgorithm\x18\x02 \x01(\tR\talgorithm\x12\x1d\n" +
+	"\n" +
+	"issuer_did\x18\x03 \x01(\tR\tissuerDid\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12!\n" +
+	"\fblock_number\x18\x05 \x01(\x04R\vblockNumber\x12\x13\n" +
+	"\x05tx_id\x18\x06 \x01(\tR\x04txId\x12#\n" +
+	"\rmetadata_json\x18\a \x01(\tR\fmetadataJson\x12#\n" +
+	"\rmetadata_hash\x18\b \x01(\tR\fmetadataHash\x12-\n" +
+	"\x12signature_verified\x18\t \x01(\bR\x11signatureVerified\x129\n" +
+	"\n" +
+	"expires_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x18\n" +
+	"\arevoked\x18\v \x01(\bR\arevoked\x129\n" +
+	"\n" +
+	"revoked_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\trevokedAt\x12+\n" +
+	"\x11revocation_reason\x18\r \x01(\tR\x10revocationReason\x12\x1e\n" +
+	"\n" +
+	"tombstoned\x18\x0e \x01(\bR\n" +
+	"tombstoned\x12?\n" +
+	"\rtombstoned_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\ftombstonedAt\x12)\n" +
+	"\x10tombstone_reason\x18\x10 \x01(\tR\x0ftombstoneReason\"&\n" +
+	"\x10GetAnchorRequest\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\")\n" +
+	"\x13VerifyAnchorRequest\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\"\xdb\x01\n" +
+	"\x14VerifyAnchorResponse\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\x12\x16\n" +
+	"\x06exists\x18\x02 \x01(\bR\x06exists\x12\x14\n" +
+	"\x05valid\x18\x03 \x01(\bR\x05valid\x12\x18\n" +
+	"\arevoked\x18\x04 \x01(\bR\arevoked\x12-\n" +
+	"\x12signature_verified\x18\x05 \x01(\bR\x11signatureVerified\x128\n" +
+	"\ttimestamp\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"{\n" +
+	"\x03Jwk\x12\x10\n" +
+	"\x03kty\x18\x01 \x01(\tR\x03kty\x12\x10\n" +
+	"\x03crv\x18\x02 \x01(\tR\x03crv\x12\f\n" +
+	"\x01x\x18\x03 \x01(\tR\x01x\x12\f\n" +
+	"\x01y\x18\x04 \x01(\tR\x01y\x12\x10\n" +
+	"\x03kid\x18\x05 \x01(\tR\x03kid\x12\x10\n" +
+	"\x03alg\x18\x06 \x01(\tR\x03alg\x12\x10\n" +
+	"\x03use\x18\a \x01(\tR\x03use\"\xc3\x01\n" +
+	"\x17VerificationMethodInput\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x126\n" +
+	"\x0epublic_key_jwk\x18\x02 \x01(\v2\x10.resolver.v1.JwkR\fpublicKeyJwk\x12*\n" +
+	"\x11public_key_base58\x18\x03 \x01(\tR\x0fpublicKeyBase58\x120\n" +
+	"\x14public_key_multibase\x18\x04 \x01(\tR\x12publicKeyMultibase\"]\n" +
+	"\fServiceInput\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12)\n" +
+	"\x10service_endpoint\x18\x03 \x01(\tR\x0fserviceEndpoint\"\xbd\x02\n" +
+	"\x10CreateDidRequest\x12\x10\n" +
+	"\x03did\x18\x01 \x01(\tR\x03did\x12\x18\n" +
+	"\acontext\x18\x02 \x03(\tR\acontext\x12\x1e\n" +
+	"\n" +
+	"controller\x18\x03 \x01(\tR\n" +
+	"controller\x12U\n" +
+	"\x13verification_method\x18\x04 \x03(\v2$.resolver.v1.VerificationMethodInputR\x12verificationMethod\x123\n" +
+	"\aservice\x18\x05 \x03(\v2\x19.resolver.v1.ServiceInputR\aservice\x12&\n" +
+	"\x0eauthentication\x18\x06 \x03(\x05R\x0eauthentication\x12)\n" +
+	"\x10assertion_method\x18\a \x03(\x05R\x0fassertionMethod\"%\n" +
+	"\x11ResolveDidRequest\x12\x10\n" +
+	"\x03did\x18\x01 \x01(\tR\x03did\"\xee\x01\n" +
+	"\x12VerificationMethod\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1e\n" +
+	"\n" +
+	"controller\x18\x03 \x01(\tR\n" +
+	"controller\x126\n" +
+	"\x0epublic_key_jwk\x18\x04 \x01(\v2\x10.resolver.v1.JwkR\fpublicKeyJwk\x12*\n" +
+	"\x11public_key_base58\x18\x05 \x01(\tR\x0fpublicKeyBase58\x120\n" +
+	"\x14public_key_multibase\x18\x06 \x01(\tR\x12publicKeyMultibase\"X\n" +
+	"\aService\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12)\n" +
+	"\x10service_endpoint\x18\x03 \x01(\tR\x0fserviceEndpoint\"\x9c\x04\n" +
+	"\vDidDocument\x12\x18\n" +
+	"\acontext\x18\x01 \x03(\tR\acontext\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x1e\n" +
+	"\n" +
+	"controller\x18\x03 \x01(\tR\n" +
+	"controller\x12P\n" +
+	"\x13verification_method\x18\x04 \x03(\v2\x1f.resolver.v1.VerificationMethodR\x12verificationMethod\x12&\n" +
+	"\x0eauthentication\x18\x05 \x03(\tR\x0eauthentication\x12)\n" +
+	"\x10assertion_method\x18\x06 \x03(\tR\x0fassertionMethod\x12.\n" +
+	"\aservice\x18\a \x03(\v2\x14.resolver.v1.ServiceR\aservice\x124\n" +
+	"\acreated\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\acreated\x124\n" +
+	"\aupdated\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\aupdated\x12 \n" +
+	"\vdeactivated\x18\n" +
+	" \x01(\bR\vdeactivated\x12A\n" +
+	"\x0edeactivated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\rdeactivatedAt\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\f \x01(\x04R\tversionId\"\x0e\n" +
+	"\fStatsRequest\"\x8c\x03\n" +
+	"\rStatsResponse\x12\x18\n" +
+	"\aanchors\x18\x01 \x01(\x03R\aanchors\x12\x12\n" +
+	"\x04dids\x18\x02 \x01(\x03R\x04dids\x12\x1d\n" +
+	"\n" +
+	"next_block\x18\x03 \x01(\x04R\tnextBlock\x12\x12\n" +
+	"\x04mode\x18\x04 \x01(\tR\x04mode\x12\x12\n" +
+	"\x04path\x18\x05 \x01(\tR\x04path\x12&\n" +
+	"\x0ffile_size_bytes\x18\x06 \x01(\x03R\rfileSizeBytes\x12B\n" +
+	"\x0flast_write_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\rlastWriteTime\x12E\n" +
+	"\tdoc_types\x18\b \x03(\v2(.resolver.v1.StatsResponse.DocTypesEntryR\bdocTypes\x12\x16\n" +
+	"\x06pruned\x18\t \x01(\x04R\x06pruned\x1a;\n" +
+	"\rDocTypesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x012\xbc\x03\n" +
+	"\x0fResolverService\x12E\n" +
+	"\fCreateAnchor\x12 .resolver.v1.CreateAnchorRequest\x1a\x13.resolver.v1.Anchor\x12?\n" +
+	"\tGetAnchor\x12\x1d.resolver.v1.GetAnchorRequest\x1a\x13.resolver.v1.Anchor\x12S\n" +
+	"\fVerifyAnchor\x12 .resolver.v1.VerifyAnchorRequest\x1a!.resolver.v1.VerifyAnchorResponse\x12D\n" +
+	"\tCreateDid\x12\x1d.resolver.v1.CreateDidRequest\x1a\x18.resolver.v1.DidDocument\x12F\n" +
+	"\n" +
+	"ResolveDid\x12\x1e.resolver.v1.ResolveDidRequest\x1a\x18.resolver.v1.DidDocument\x12>\n" +
+	"\x05Stats\x12\x19.resolver.v1.StatsRequest\x1a\x1a.resolver.v1.StatsResponseB.Z,fabric-resolver/proto/resolver/v1;resolverv1b\x06proto3"
+
+var (
+	file_proto_resolver_v1_resolver_proto_rawDescOnce sync.Once
+	file_proto_resolver_v1_resolver_proto_rawDescData []byte
+)
+
+func file_proto_resolver_v1_resolver_proto_rawDescGZIP() []byte {
+	file_proto_resolver_v1_resolver_proto_rawDescOnce.Do(func() {
+		file_proto_resolver_v1_resolver_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_resolver_v1_resolver_proto_rawDesc), len(file_proto_resolver_v1_resolver_proto_rawDesc)))
+	})
+	return file_proto_resolver_v1_resolver_proto_rawDescData
+}
+
+var file_proto_resolver_v1_resolver_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_proto_resolver_v1_resolver_proto_goTypes = []any{
+	(*CreateAnchorRequest)(nil),     // 0: resolver.v1.CreateAnchorRequest
+	(*Anchor)(nil),                  // 1: resolver.v1.Anchor
+	(*GetAnchorRequest)(nil),        // 2: resolver.v1.GetAnchorRequest
+	(*VerifyAnchorRequest)(nil),     // 3: resolver.v1.VerifyAnchorRequest
+	(*VerifyAnchorResponse)(nil),    // 4: resolver.v1.VerifyAnchorResponse
+	(*Jwk)(nil),                     // 5: resolver.v1.Jwk
+	(*VerificationMethodInput)(nil), // 6: resolver.v1.VerificationMethodInput
+	(*ServiceInput)(nil),            // 7: resolver.v1.ServiceInput
+	(*CreateDidRequest)(nil),        // 8: resolver.v1.CreateDidRequest
+	(*ResolveDidRequest)(nil),       // 9: resolver.v1.ResolveDidRequest
+	(*VerificationMethod)(nil),      // 10: resolver.v1.VerificationMethod
+	(*Service)(nil),                 // 11: resolver.v1.Service
+	(*DidDocument)(nil),             // 12: resolver.v1.DidDocument
+	(*StatsRequest)(nil),            // 13: resolver.v1.StatsRequest
+	(*StatsResponse)(nil),           // 14: resolver.v1.StatsResponse
+	nil,                             // 15: resolver.v1.StatsResponse.DocTypesEntry
+	(*timestamppb.Timestamp)(nil),   // 16: google.protobuf.Timestamp
+}
+var file_proto_resolver_v1_resolver_proto_depIdxs = []int32{
+	16, // 0: resolver.v1.CreateAnchorRequest.expires_at:type_name -> google.protobuf.Timestamp
+	16, // 1: resolver.v1.Anchor.timestamp:type_name -> google.protobuf.Timestamp
+	16, // 2: resolver.v1.Anchor.expires_at:type_name -> google.protobuf.Timestamp
+	16, // 3: resolver.v1.Anchor.revoked_at:type_name -> google.protobuf.Timestamp
+	16, // 4: resolver.v1.Anchor.tombstoned_at:type_name -> google.protobuf.Timestamp
+	16, // 5: resolver.v1.VerifyAnchorResponse.timestamp:type_name -> google.protobuf.Timestamp
+	5,  // 6: resolver.v1.VerificationMethodInput.public_key_jwk:type_name -> resolver.v1.Jwk
+	6,  // 7: resolver.v1.CreateDidRequest.verification_method:type_name -> resolver.v1.VerificationMethodInput
+	7,  // 8: resolver.v1.CreateDidRequest.service:type_name -> resolver.v1.ServiceInput
+	5,  // 9: resolver.v1.VerificationMethod.public_key_jwk:type_name -> resolver.v1.Jwk
+	10, // 10: resolver.v1.DidDocument.verification_method:type_name -> resolver.v1.VerificationMethod
+	11, // 11: resolver.v1.DidDocument.service:type_name -> resolver.v1.Service
+	16, // 12: resolver.v1.DidDocument.created:type_name -> google.protobuf.Timestamp
+	16, // 13: resolver.v1.DidDocument.updated:type_name -> google.protobuf.Timestamp
+	16, // 14: resolver.v1.DidDocument.deactivated_at:type_name -> google.protobuf.Timestamp
+	16, // 15: resolver.v1.StatsResponse.last_write_time:type_name -> google.protobuf.Timestamp
+	15, // 16: resolver.v1.StatsResponse.doc_types:type_name -> resolver.v1.StatsResponse.DocTypesEntry
+	0,  // 17: resolver.v1.ResolverService.CreateAnchor:input_type -> resolver.v1.CreateAnchorRequest
+	2,  // 18: resolver.v1.ResolverService.GetAnchor:input_type -> resolver.v1.GetAnchorRequest
+	3,  // 19: resolver.v1.ResolverService.VerifyAnchor:input_type -> resolver.v1.VerifyAnchorRequest
+	8,  // 20: resolver.v1.ResolverService.CreateDid:input_type -> resolver.v1.CreateDidRequest
+	9,  // 21: resolver.v1.ResolverService.ResolveDid:input_type -> resolver.v1.ResolveDidRequest
+	13, // 22: resolver.v1.ResolverService.Stats:input_type -> resolver.v1.StatsRequest
+	1,  // 23: resolver.v1.ResolverService.CreateAnchor:output_type -> resolver.v1.Anchor
+	1,  // 24: resolver.v1.ResolverService.GetAnchor:output_type -> resolver.v1.Anchor
+	4,  // 25: resolver.v1.ResolverService.VerifyAnchor:output_type -> resolver.v1.VerifyAnchorResponse
+	12, // 26: resolver.v1.ResolverService.CreateDid:output_type -> resolver.v1.DidDocument
+	12, // 27: resolver.v1.ResolverService.ResolveDid:output_type -> resolver.v1.DidDocument
+	14, // 28: resolver.v1.ResolverService.Stats:output_type -> resolver.v1.StatsResponse
+	23, // [23:29] is the sub-list for method output_type
+	17, // [17:23] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_proto_resolver_v1_resolver_proto_init() }
+func file_proto_resolver_v1_resolver_proto_init() {
+	if File_proto_resolver_v1_resolver_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_resolver_v1_resolver_proto_rawDesc), len(file_proto_resolver_v1_resolver_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_resolver_v1_resolver_proto_goTypes,
+		DependencyIndexes: file_proto_resolver_v1_resolver_proto_depIdxs,
+		MessageInfos:      file_proto_resolver_v1_resolver_proto_msgTypes,
+	}.Build()
+	File_proto_resolver_v1_resolver_proto = out.File
+	file_proto_resolver_v1_resolver_proto_goTypes = nil
+	file_proto_resolver_v1_resolver_proto_depIdxs = nil
+}
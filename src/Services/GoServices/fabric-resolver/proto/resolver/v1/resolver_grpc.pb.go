@@ -0,0 +1,318 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: proto/resolver/v1/resolver.proto
+
+// Package resolver.v1 is the gRPC API of fabric-resolver. It mirrors the HTTP
+// endpoints of the same name; see internal/api/handlers for their semantics.
+
+package resolverv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ResolverService_CreateAnchor_FullMethodName = "/resolver.v1.ResolverService/CreateAnchor"
+	ResolverService_GetAnchor_FullMethodName    = "/resolver.v1.ResolverService/GetAnchor"
+	ResolverService_VerifyAnchor_FullMethodName = "/resolver.v1.ResolverService/VerifyAnchor"
+	ResolverService_CreateDid_FullMethodName    = "/resolver.v1.ResolverService/CreateDid"
+	ResolverService_ResolveDid_FullMethodName   = "/resolver.v1.ResolverService/ResolveDid"
+	ResolverService_Stats_FullMethodName        = "/resolver.v1.ResolverService/Stats"
+)
+
+// ResolverServiceClient is the client API for ResolverService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ResolverServiceClient interface {
+	// CreateAnchor anchors a document hash. Anchoring an existing hash returns the stored anchor.
+	CreateAnchor(ctx context.Context, in *CreateAnchorRequest, opts ...grpc.CallOption) (*Anchor, error)
+	GetAnchor(ctx context.Context, in *GetAnchorRequest, opts ...grpc.CallOption) (*Anchor, error)
+	VerifyAnchor(ctx context.Context, in *VerifyAnchorRequest, opts ...grpc.CallOption) (*VerifyAnchorResponse, error)
+	CreateDid(ctx context.Context, in *CreateDidRequest, opts ...grpc.CallOption) (*DidDocument, error)
+	// ResolveDid returns DID documents stored on the ledger, including deactivated ones.
+	ResolveDid(ctx context.Context, in *ResolveDidRequest, opts ...grpc.CallOption) (*DidDocument, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+}
+
+type resolverServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewResolverServiceClient(cc grpc.ClientConnInterface) ResolverServiceClient {
+	return &resolverServiceClient{cc}
+}
+
+func (c *resolverServiceClient) CreateAnchor(ctx context.Context, in *CreateAnchorRequest, opts ...grpc.CallOption) (*Anchor, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Anchor)
+	err := c.cc.Invoke(ctx, ResolverService_CreateAnchor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resolverServiceClient) GetAnchor(ctx context.Context, in *GetAnchorRequest, opts ...grpc.CallOption) (*Anchor, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Anchor)
+	err := c.cc.Invoke(ctx, ResolverService_GetAnchor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resolverServiceClient) VerifyAnchor(ctx context.Context, in *VerifyAnchorRequest, opts ...grpc.CallOption) (*VerifyAnchorResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyAnchorResponse)
+	err := c.cc.Invoke(ctx, ResolverService_VerifyAnchor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resolverServiceClient) CreateDid(ctx context.Context, in *CreateDidRequest, opts ...grpc.CallOption) (*DidDocument, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DidDocument)
+	err := c.cc.Invoke(ctx, ResolverService_CreateDid_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resolverServiceClient) ResolveDid(ctx context.Context, in *ResolveDidRequest, opts ...grpc.CallOption) (*DidDocument, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DidDocument)
+	err := c.cc.Invoke(ctx, ResolverService_ResolveDid_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *resolverServiceClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, ResolverService_Stats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ResolverServiceServer is the server API for ResolverService service.
+// All implementations must embed UnimplementedResolverServiceServer
+// for forward compatibility.
+type ResolverServiceServer interface {
+	// CreateAnchor anchors a document hash. Anchoring an existing hash returns the stored anchor.
+	CreateAnchor(context.Context, *CreateAnchorRequest) (*Anchor, error)
+	GetAnchor(context.Context, *GetAnchorRequest) (*Anchor, error)
+	VerifyAnchor(context.Context, *VerifyAnchorRequest) (*VerifyAnchorResponse, error)
+	CreateDid(context.Context, *CreateDidRequest) (*DidDocument, error)
+	// ResolveDid returns DID documents stored on the ledger, including deactivated ones.
+	ResolveDid(context.Context, *ResolveDidRequest) (*DidDocument, error)
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	mustEmbedUnimplementedResolverServiceServer()
+}
+
+// UnimplementedResolverServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedResolverServiceServer struct{}
+
+func (UnimplementedResolverServiceServer) CreateAnchor(context.Context, *CreateAnchorRequest) (*Anchor, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAnchor not implemented")
+}
+func (UnimplementedResolverServiceServer) GetAnchor(context.Context, *GetAnchorRequest) (*Anchor, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAnchor not implemented")
+}
+func (UnimplementedResolverServiceServer) VerifyAnchor(context.Context, *VerifyAnchorRequest) (*VerifyAnchorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyAnchor not implemented")
+}
+func (UnimplementedResolverServiceServer) CreateDid(context.Context, *CreateDidRequest) (*DidDocument, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDid not implemented")
+}
+func (UnimplementedResolverServiceServer) ResolveDid(context.Context, *ResolveDidRequest) (*DidDocument, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveDid not implemented")
+}
+func (UnimplementedResolverServiceServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedResolverServiceServer) mustEmbedUnimplementedResolverServiceServer() {}
+func (UnimplementedResolverServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeResolverServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ResolverServiceServer will
+// result in compilation errors.
+type UnsafeResolverServiceServer interface {
+	mustEmbedUnimplementedResolverServiceServer()
+}
+
+func RegisterResolverServiceServer(s grpc.ServiceRegistrar, srv ResolverServiceServer) {
+	// If the following call pancis, it indicates UnimplementedResolverServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ResolverService_ServiceDesc, srv)
+}
+
+func _ResolverService_CreateAnchor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAnchorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResolverServiceServer).CreateAnchor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResolverService_CreateAnchor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResolverServiceServer).CreateAnchor(ctx, req.(*CreateAnchorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResolverService_GetAnchor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAnchorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResolverServiceServer).GetAnchor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResolverService_GetAnchor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResolverServiceServer).GetAnchor(ctx, req.(*GetAnchorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResolverService_VerifyAnchor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyAnchorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResolverServiceServer).VerifyAnchor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResolverService_VerifyAnchor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResolverServiceServer).VerifyAnchor(ctx, req.(*VerifyAnchorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResolverService_CreateDid_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDidRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResolverServiceServer).CreateDid(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResolverService_CreateDid_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResolverServiceServer).CreateDid(ctx, req.(*CreateDidRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResolverService_ResolveDid_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveDidRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResolverServiceServer).ResolveDid(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResolverService_ResolveDid_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResolverServiceServer).ResolveDid(ctx, req.(*ResolveDidRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ResolverService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ResolverServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ResolverService_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ResolverServiceServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ResolverService_ServiceDesc is the grpc.ServiceDesc for ResolverService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ResolverService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "resolver.v1.ResolverService",
+	HandlerType: (*ResolverServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateAnchor",
+			Handler:    _ResolverService_CreateAnchor_Handler,
+		},
+		{
+			MethodName: "GetAnchor",
+			Handler:    _ResolverService_GetAnchor_Handler,
+		},
+		{
+			MethodName: "VerifyAnchor",
+			Handler:    _ResolverService_VerifyAnchor_Handler,
+		},
+		{
+			MethodName: "CreateDid",
+			Handler:    _ResolverService_CreateDid_Handler,
+		},
+		{
+			MethodName: "ResolveDid",
+			Handler:    _ResolverService_ResolveDid_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _ResolverService_Stats_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/resolver/v1/resolver.proto",
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"zkp-service/internal/api"
+	"zkp-service/internal/buildinfo"
 	"zkp-service/internal/keys"
 
 	"github.com/gorilla/mux"
@@ -20,6 +21,7 @@ func main() {
 
 	// Middleware
 	r.Use(loggingMiddleware)
+	r.Use(versionMiddleware)
 
 	// Routes
 	r.HandleFunc("/health", healthHandler).Methods("GET")
@@ -37,13 +39,16 @@ func main() {
 		ReadTimeout:  15 * time.Second,
 	}
 
-	log.Println("ZKP Service running on port 8080...")
+	build := buildinfo.Current()
+	log.Printf("ZKP Service %s (commit %s, built %s) running on port 8080...", build.Version, build.Commit, build.BuildDate)
 	log.Fatal(srv.ListenAndServe())
 }
 
+// healthHandler reports the service up, with the build of the binary as
+// fabric-resolver reports its own.
 func healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "service": "zkp-service"})
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "service": "zkp-service", "build": buildinfo.Current()})
 }
 
 func loggingMiddleware(next http.Handler) http.Handler {
@@ -53,3 +58,12 @@ func loggingMiddleware(next http.Handler) http.Handler {
 		log.Printf("%s %s %s", r.Method, r.RequestURI, time.Since(start))
 	})
 }
+
+// versionMiddleware names the version of the binary on every response.
+func versionMiddleware(next http.Handler) http.Handler {
+	version := buildinfo.Current().Version
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Service-Version", version)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"zkp-service/internal/buildinfo"
+)
+
+func TestHealthHandler_ReportsBuild(t *testing.T) {
+	rec := httptest.NewRecorder()
+	versionMiddleware(http.HandlerFunc(healthHandler)).ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+
+	var body struct {
+		Status string          `json:"status"`
+		Build  buildinfo.Build `json:"build"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected a JSON body, got %q", rec.Body.String())
+	}
+	// Nothing is injected into test binaries, so every field defaults to dev
+	if body.Status != "ok" || body.Build != (buildinfo.Build{Version: "dev", Commit: "dev", BuildDate: "dev"}) {
+		t.Errorf("expected the build to default to dev, got %s", rec.Body.String())
+	}
+	if got := rec.Header().Get("X-Service-Version"); got != "dev" {
+		t.Errorf("expected X-Service-Version: dev, got %q", got)
+	}
+}
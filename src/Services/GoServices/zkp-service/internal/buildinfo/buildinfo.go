@@ -0,0 +1,57 @@
+// Package buildinfo identifies the running binary: its version, the commit it was
+// built from and when it was built.
+package buildinfo
+
+import "runtime/debug"
+
+// Version, Commit and BuildDate are set at build time:
+//
+//	go build -ldflags "-X zkp-service/internal/buildinfo.Version=v1.4.0 \
+//	  -X zkp-service/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X zkp-service/internal/buildinfo.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Without them Commit and BuildDate fall back to the revision and commit time the
+// go command stamps into binaries built from a checkout, and then to "dev".
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Build identifies the binary.
+type Build struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+}
+
+// Current returns the build of the binary.
+func Current() Build {
+	return Build{
+		Version:   orDev(Version),
+		Commit:    orDev(stamped(Commit, "vcs.revision")),
+		BuildDate: orDev(stamped(BuildDate, "vcs.time")),
+	}
+}
+
+// stamped returns value, or the build setting key when value was not set.
+func stamped(value, key string) string {
+	if value != "" {
+		return value
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == key {
+				return setting.Value
+			}
+		}
+	}
+	return ""
+}
+
+func orDev(value string) string {
+	if value == "" {
+		return "dev"
+	}
+	return value
+}